@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// ActionRecord is one line of the --action-log file: a single write or
+// restore performed from the TUI, with enough detail to audit or replay it.
+type ActionRecord struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"` // update, add, remove, replace, restore, delete, revert, metadata, retarget, migrate
+	Package   string    `json:"package,omitempty"`
+	With      string    `json:"with,omitempty"` // replace: the package that took Package's place
+	Version   string    `json:"version,omitempty"`
+	Source    string    `json:"source,omitempty"` // delete: the feed the version was removed from; migrate: the sources moved
+	Framework string    `json:"framework,omitempty"`
+	Files     []string  `json:"files,omitempty"`
+	OK        bool      `json:"ok"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// actionLogger appends ActionRecords as JSON lines. A nil *actionLogger
+// discards records, so callers never need to check whether logging is on.
+type actionLogger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	f   *os.File
+}
+
+// actionLog is set from --action-log in main; nil when disabled.
+var actionLog *actionLogger
+
+// openActionLog opens path for appending so successive sessions accumulate
+// in one file.
+func openActionLog(path string) (*actionLogger, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &actionLogger{enc: json.NewEncoder(f), f: f}, nil
+}
+
+func (l *actionLogger) record(rec ActionRecord, err error) {
+	if l == nil {
+		return
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now().UTC()
+	}
+	rec.OK = err == nil
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.enc.Encode(rec); err != nil {
+		logWarn("Could not write action log: %v", err)
+	}
+}
+
+func (l *actionLogger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// recordAction writes rec to the session's action log, if one is open, and
+// closes the session journal entry for the files it wrote.
+func recordAction(rec ActionRecord, err error) {
+	sessionJournal.commit(rec)
+	actionLog.record(rec, err)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestActionLog_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "actions.jsonl")
+
+	l, err := openActionLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.record(ActionRecord{Action: "update", Package: "Serilog", Version: "4.0.0", Files: []string{"App.csproj"}}, nil)
+	l.Close()
+
+	// A second session appends rather than truncating.
+	l, err = openActionLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.record(ActionRecord{Action: "restore", Files: []string{"App.csproj"}}, errors.New("exit status 1"))
+	l.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var recs []ActionRecord
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var rec ActionRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("line %q is not JSON: %v", sc.Text(), err)
+		}
+		recs = append(recs, rec)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("got %d records, want 2", len(recs))
+	}
+	if !recs[0].OK || recs[0].Package != "Serilog" || recs[0].Time.IsZero() {
+		t.Errorf("first record = %+v", recs[0])
+	}
+	if recs[1].OK || recs[1].Error != "exit status 1" {
+		t.Errorf("second record = %+v", recs[1])
+	}
+}
+
+func TestActionLog_NilDiscards(t *testing.T) {
+	var l *actionLogger
+	l.record(ActionRecord{Action: "update"}, nil) // must not panic
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
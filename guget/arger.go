@@ -28,6 +28,7 @@ type IFlag interface {
 type IParsedFlag interface {
 	GetValue() any
 	GetFlag() IFlag
+	WasGiven() bool
 }
 
 type Flag[T any] struct {
@@ -103,21 +104,23 @@ func (f Flag[T]) parse(value string) (IParsedFlag, error) {
 
 func (f Flag[T]) defaultParsed() IParsedFlag {
 	if f.Default != nil {
-		return ParsedFlag[T]{flag: &f, Value: *f.Default}
+		return ParsedFlag[T]{flag: &f, Value: *f.Default, Defaulted: true}
 	}
 	if f.DefaultFunc != nil {
-		return ParsedFlag[T]{flag: &f, Value: f.DefaultFunc()}
+		return ParsedFlag[T]{flag: &f, Value: f.DefaultFunc(), Defaulted: true}
 	}
 	return nil
 }
 
 type ParsedFlag[T any] struct {
-	flag  *Flag[T]
-	Value T
+	flag      *Flag[T]
+	Value     T
+	Defaulted bool // not on the command line; Value is the flag's default
 }
 
 func (pf ParsedFlag[T]) GetValue() any  { return pf.Value }
 func (pf ParsedFlag[T]) GetFlag() IFlag { return pf.flag }
+func (pf ParsedFlag[T]) WasGiven() bool { return !pf.Defaulted }
 func (pf ParsedFlag[T]) As() T          { return pf.Value }
 
 func StringFlag(name string) Flag[string] {
@@ -236,6 +239,19 @@ func ParseFlags() (map[string]IParsedFlag, []string) {
 			PrintUsage()
 			os.Exit(0)
 		} else if strings.HasPrefix(arg, "--") || strings.HasPrefix(arg, "-") {
+			// --flag=value gives the value inline, which also lets a switch
+			// be turned off, e.g. --prerelease=false.
+			if name, value, ok := strings.Cut(arg, "="); ok {
+				if mapped, exists := aliasToFlag[name]; exists {
+					pf, err := mapped.parse(value)
+					if err != nil {
+						flagError(mapped, "Failed to parse value. %s", err.Error())
+					}
+					parsedFlags[mapped.GetName()] = pf
+					logDebug("Parsed flag %s = %v", mapped.GetName(), pf.GetValue())
+					continue
+				}
+			}
 			if mapped, exists := aliasToFlag[arg]; exists {
 				lastFlag = mapped
 
@@ -402,6 +418,18 @@ func flagError(f IFlag, format string, args ...any) {
 	os.Exit(1)
 }
 
+// GivenFlags returns the names of the flags set on the command line, as
+// opposed to those left at their defaults.
+func GivenFlags(flags map[string]IParsedFlag) Set[string] {
+	given := NewSet[string]()
+	for name, pf := range flags {
+		if pf.WasGiven() {
+			given.Add(name)
+		}
+	}
+	return given
+}
+
 func GetFlag[T any](flags map[string]IParsedFlag, name string) T {
 	pf, exists := flags[name]
 	if !exists {
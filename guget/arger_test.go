@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 )
 
 func parseRegisteredCLIForTest(t *testing.T, args ...string) (BuiltFlags, []string) {
@@ -61,13 +62,21 @@ func TestCLIParseDefaults(t *testing.T) {
 
 	flags, extra := parseRegisteredCLIForTest(t)
 	assertBuiltFlags(t, flags, BuiltFlags{
-		NoColor:    false,
-		Verbosity:  "warn",
-		ProjectDir: cwd,
-		Version:    false,
-		LogFile:    "",
-		Theme:      "auto",
-		SortBy:     "status:asc",
+		NoColor:       false,
+		Verbosity:     "warn",
+		ProjectDir:    cwd,
+		Version:       false,
+		LogFile:       "",
+		LogMaxSize:    10,
+		LogKeep:       3,
+		Timeout:       15 * time.Second,
+		Deadline:      2 * time.Minute,
+		CacheTTL:      time.Hour,
+		Theme:         "auto",
+		SortBy:        "status:asc",
+		Format:        "json",
+		Interval:      24 * time.Hour,
+		WebhookFormat: "json",
 	})
 	if len(extra) != 0 {
 		t.Fatalf("expected no extra args, got %v", extra)
@@ -90,13 +99,21 @@ func TestCLIParseLongAliases(t *testing.T) {
 	)
 
 	assertBuiltFlags(t, flags, BuiltFlags{
-		NoColor:    true,
-		Verbosity:  "debug",
-		ProjectDir: projectPath,
-		Version:    true,
-		LogFile:    logPath,
-		Theme:      "nord",
-		SortBy:     "name:desc",
+		NoColor:       true,
+		Verbosity:     "debug",
+		ProjectDir:    projectPath,
+		Version:       true,
+		LogFile:       logPath,
+		LogMaxSize:    10,
+		LogKeep:       3,
+		Timeout:       15 * time.Second,
+		Deadline:      2 * time.Minute,
+		CacheTTL:      time.Hour,
+		Theme:         "nord",
+		SortBy:        "name:desc",
+		Format:        "json",
+		Interval:      24 * time.Hour,
+		WebhookFormat: "json",
 	})
 	if len(extra) != 0 {
 		t.Fatalf("expected no extra args, got %v", extra)
@@ -119,19 +136,42 @@ func TestCLIParseShortAliases(t *testing.T) {
 	)
 
 	assertBuiltFlags(t, flags, BuiltFlags{
-		NoColor:    true,
-		Verbosity:  "trc",
-		ProjectDir: projectPath,
-		Version:    true,
-		LogFile:    logPath,
-		Theme:      "gruvbox",
-		SortBy:     "current",
+		NoColor:       true,
+		Verbosity:     "trc",
+		ProjectDir:    projectPath,
+		Version:       true,
+		LogFile:       logPath,
+		LogMaxSize:    10,
+		LogKeep:       3,
+		Timeout:       15 * time.Second,
+		Deadline:      2 * time.Minute,
+		CacheTTL:      time.Hour,
+		Theme:         "gruvbox",
+		SortBy:        "current",
+		Format:        "json",
+		Interval:      24 * time.Hour,
+		WebhookFormat: "json",
 	})
 	if len(extra) != 0 {
 		t.Fatalf("expected no extra args, got %v", extra)
 	}
 }
 
+func TestCLIParseQuietCapsLogLevel(t *testing.T) {
+	flags, _ := parseRegisteredCLIForTest(t, "-q", "-v", "debug")
+	if !flags.Quiet {
+		t.Fatal("expected -q to set Quiet")
+	}
+
+	os.Args = []string{"guget", "--quiet", "--verbose", "trace"}
+	registeredFlags = make(map[string]IFlag)
+	aliasToFlag = make(map[string]IFlag)
+	initCLI()
+	if logLevel != LogLevelError {
+		t.Fatalf("expected --quiet to cap verbosity at error, got %v", logLevel)
+	}
+}
+
 func TestCLIParsePreservesStringValues(t *testing.T) {
 	projectPath := `F:\Projects\Clipboard inspector\Clipboard inspector CLI\`
 	logPath := `F:\Projects\Clipboard inspector\logs\guget trace.log`
@@ -327,3 +367,45 @@ func TestCLIParseRejectsInvalidArgumentsHelper(t *testing.T) {
 	ParseFlags()
 	os.Exit(0)
 }
+
+func TestApplySettingDefaults_ExplicitFlagsWin(t *testing.T) {
+	parse := func(args ...string) (BuiltFlags, Set[string]) {
+		resetCLIParserForTest(t)
+		os.Args = append([]string{"guget"}, args...)
+		registerCLIFlags()
+		parsed, _ := ParseFlags()
+		return BuildFlags(parsed), GivenFlags(parsed)
+	}
+	settings := UserConfig{Verbosity: "debug", Theme: "nord", SortBy: "name:asc", WebhookFormat: "slack"}
+
+	flags, given := parse("--verbose", "warn", "--theme", "auto", "--webhook-format", "json")
+	got := applySettingDefaults(flags, given, settings)
+	if got.Verbosity != "warn" || got.Theme != "auto" || got.WebhookFormat != "json" {
+		t.Errorf("explicit flags = %q/%q/%q, want warn/auto/json", got.Verbosity, got.Theme, got.WebhookFormat)
+	}
+	if got.SortBy != "name:asc" {
+		t.Errorf("sort = %q, want the setting for a flag not given", got.SortBy)
+	}
+
+	settings.Prerelease = true
+	flags, given = parse("--prerelease=false")
+	if got := applySettingDefaults(flags, given, settings); got.Prerelease {
+		t.Error("--prerelease=false should override the prerelease setting")
+	}
+
+	flags, given = parse()
+	got = applySettingDefaults(flags, given, settings)
+	if !got.Prerelease {
+		t.Error("prerelease setting not applied")
+	}
+	if got.Verbosity != "debug" || got.Theme != "nord" || got.WebhookFormat != "slack" {
+		t.Errorf("defaults = %q/%q/%q, want the settings", got.Verbosity, got.Theme, got.WebhookFormat)
+	}
+}
+
+func TestCLIParseInlineValues(t *testing.T) {
+	flags, _ := parseRegisteredCLIForTest(t, "--theme=nord", "--prerelease=true", "-v=debug")
+	if flags.Theme != "nord" || !flags.Prerelease || flags.Verbosity != "debug" {
+		t.Errorf("flags = %q/%v/%q, want nord/true/debug", flags.Theme, flags.Prerelease, flags.Verbosity)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// errJournalConflict is returned when a file was modified after the change
+// being reverted, so restoring it would lose the later edits.
+var errJournalConflict = errors.New("changed since")
+
+// fileChange is one file's content before and after a write.
+type fileChange struct {
+	Path   string
+	Before []byte
+	After  []byte
+}
+
+// journalEntry is one user-visible change (an update, add, or remove) and
+// every file it wrote.
+type journalEntry struct {
+	ID       int
+	Time     time.Time
+	Action   string
+	Package  string
+	With     string // replace: the new package
+	Source   string // migrate: the sources whose URLs moved
+	Version  string
+	Files    []fileChange
+	Reverted bool
+}
+
+// summary describes the change in a few words, e.g. "update Foo → 1.2.3".
+func (e journalEntry) summary() string {
+	switch {
+	case e.Action == "migrate":
+		return "migrate " + e.Source
+	case e.Action == "replace":
+		return fmt.Sprintf("replace %s → %s %s", e.Package, e.With, e.Version)
+	case e.Action == "update":
+		return fmt.Sprintf("update %s → %s", e.Package, e.Version)
+	case e.Version != "":
+		return fmt.Sprintf("%s %s %s", e.Action, e.Package, e.Version)
+	}
+	return e.Action + " " + e.Package
+}
+
+// changeJournal remembers every project file write made this session so
+// changes can be reverted. Writes are noted as they happen and grouped into
+// an entry when the action that made them is recorded.
+type changeJournal struct {
+	mu      sync.Mutex
+	pending map[string]*fileChange
+	entries []*journalEntry
+	nextID  int
+}
+
+// sessionJournal holds this session's changes; it is never written to disk.
+var sessionJournal = &changeJournal{}
+
+// noteWrite records that path went from before to after. A file written
+// twice for one action keeps its original before.
+func (j *changeJournal) noteWrite(path string, before, after []byte) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.pending == nil {
+		j.pending = make(map[string]*fileChange)
+	}
+	if fc, ok := j.pending[path]; ok {
+		fc.After = after
+		return
+	}
+	j.pending[path] = &fileChange{Path: path, Before: before, After: after}
+}
+
+// commit groups the pending writes to rec.Files into one entry. Failed
+// actions are committed too, so whatever they managed to write can still be
+// reverted.
+func (j *changeJournal) commit(rec ActionRecord) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var files []fileChange
+	for _, path := range rec.Files {
+		if fc, ok := j.pending[path]; ok {
+			files = append(files, *fc)
+			delete(j.pending, path)
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+	j.nextID++
+	j.entries = append(j.entries, &journalEntry{
+		ID:      j.nextID,
+		Time:    time.Now(),
+		Action:  rec.Action,
+		Package: rec.Package,
+		With:    rec.With,
+		Source:  rec.Source,
+		Version: rec.Version,
+		Files:   files,
+	})
+}
+
+// discard forgets pending writes to paths, for changes that were rolled back.
+func (j *changeJournal) discard(paths []string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, p := range paths {
+		delete(j.pending, p)
+	}
+}
+
+// Entries returns a copy of the journal, newest first.
+func (j *changeJournal) Entries() []journalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	out := make([]journalEntry, len(j.entries))
+	for i, e := range j.entries {
+		out[i] = *e
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].ID > out[b].ID })
+	return out
+}
+
+// lastOpen returns the newest change that has not been reverted.
+func (j *changeJournal) lastOpen() (journalEntry, bool) {
+	for _, e := range j.Entries() {
+		if !e.Reverted {
+			return e, true
+		}
+	}
+	return journalEntry{}, false
+}
+
+// revert restores every file of entry id to its content before the change.
+// Nothing is written unless every file still holds exactly what the change
+// left there.
+func (j *changeJournal) revert(id int) (journalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var entry *journalEntry
+	for _, e := range j.entries {
+		if e.ID == id {
+			entry = e
+		}
+	}
+	if entry == nil {
+		return journalEntry{}, fmt.Errorf("no change #%d in this session", id)
+	}
+	if entry.Reverted {
+		return *entry, fmt.Errorf("%s was already reverted", entry.summary())
+	}
+	for _, fc := range entry.Files {
+		current, err := os.ReadFile(fc.Path)
+		if err != nil {
+			return *entry, err
+		}
+		if !bytes.Equal(current, fc.After) {
+			return *entry, fmt.Errorf("%s %w (later change or edited outside guget)", fc.Path, errJournalConflict)
+		}
+	}
+	for _, fc := range entry.Files {
+		if err := writeFileRetry(fc.Path, fc.Before, 0644); err != nil {
+			return *entry, err
+		}
+	}
+	entry.Reverted = true
+	return *entry, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangeJournal_Revert(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "A.csproj")
+	b := filepath.Join(dir, "Directory.Packages.props")
+	os.WriteFile(a, []byte("a0"), 0644)
+	os.WriteFile(b, []byte("b0"), 0644)
+
+	j := &changeJournal{}
+	write := func(path, before, after string) {
+		os.WriteFile(path, []byte(after), 0644)
+		j.noteWrite(path, []byte(before), []byte(after))
+	}
+
+	// One action writing two files, the CPM file twice.
+	write(b, "b0", "b1")
+	write(a, "a0", "a1")
+	write(b, "b1", "b2")
+	j.commit(ActionRecord{Action: "add", Package: "Foo", Version: "1.0.0", Files: []string{b, a}})
+	write(a, "a1", "a2")
+	j.commit(ActionRecord{Action: "update", Package: "Bar", Version: "2.0.0", Files: []string{a}})
+	j.commit(ActionRecord{Action: "restore", Files: []string{a}}) // no writes: no entry
+
+	entries := j.Entries()
+	if len(entries) != 2 || entries[0].summary() != "update Bar → 2.0.0" || entries[1].summary() != "add Foo 1.0.0" {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	// The add can't be reverted while the update still sits on top of it.
+	if _, err := j.revert(entries[1].ID); !errors.Is(err, errJournalConflict) {
+		t.Fatalf("revert of an overwritten change: got %v, want errJournalConflict", err)
+	}
+	last, ok := j.lastOpen()
+	if !ok || last.ID != entries[0].ID {
+		t.Fatalf("lastOpen = %+v, %v", last, ok)
+	}
+	if _, err := j.revert(last.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.revert(entries[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{a: "a0", b: "b0"} {
+		if got, _ := os.ReadFile(path); string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(path), got, want)
+		}
+	}
+	if _, ok := j.lastOpen(); ok {
+		t.Error("expected nothing left to undo")
+	}
+	if _, err := j.revert(last.ID); err == nil {
+		t.Error("expected an error reverting twice")
+	}
+}
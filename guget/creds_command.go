@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runCredsCommand implements "guget creds test <source>": it shows how
+// credentials for a source would be found — nuget.config entries, proxy,
+// timeout, discovered and pinned providers — then runs each provider in
+// turn with its stderr on the terminal, so sign-in prompts and proxy errors
+// are visible.
+func runCredsCommand(command string, flags BuiltFlags) int {
+	if command != "creds test" || flags.Source == "" {
+		logError("Usage: guget creds test <source name or URL> [-p dir] [--credential-timeout 60s]")
+		return exitError
+	}
+	root, err := filepath.Abs(flags.ProjectDir)
+	if err != nil {
+		logError("Couldn't get absolute path for project directory: %v", err)
+		return exitError
+	}
+	source, err := findCredsSource(root, flags.Source)
+	if err != nil {
+		logError("%v", err)
+		return exitError
+	}
+	return testCredentialProviders(os.Stdout, source)
+}
+
+// findCredsSource maps name to a configured source by name or URL; a URL
+// that is not configured is tested as-is, with the proxy of the project's
+// nuget.config hierarchy.
+func findCredsSource(projectDir, name string) (NugetSource, error) {
+	cfg := DetectSources(projectDir)
+	for _, s := range cfg.Sources {
+		if strings.EqualFold(s.Name, name) || strings.TrimRight(s.URL, "/") == strings.TrimRight(name, "/") {
+			return s, nil
+		}
+	}
+	if strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") {
+		return NugetSource{Name: name, URL: name, Proxy: cfg.Proxy}, nil
+	}
+	return NugetSource{}, fmt.Errorf("unknown source %q: not a configured source name or an http(s) URL", name)
+}
+
+// testCredentialProviders reports the credential setup for source to w and
+// asks each provider that would be tried for credentials. Returns exitOK
+// when one of them supplied some.
+func testCredentialProviders(w io.Writer, source NugetSource) int {
+	fmt.Fprintf(w, "Source:    %s  %s\n", source.Name, source.URL)
+	if source.ConfigPath != "" {
+		fmt.Fprintf(w, "Config:    %s\n", source.ConfigPath)
+	}
+	switch {
+	case source.Username != "" || source.Password != "":
+		fmt.Fprintf(w, "Stored:    username %q, password %d chars (used before any provider)\n", source.Username, len(source.Password))
+	default:
+		fmt.Fprintln(w, "Stored:    none in nuget.config")
+	}
+	if source.Proxy.URL != "" {
+		fmt.Fprintf(w, "Proxy:     %s (http_proxy in nuget.config)\n", source.Proxy.URL)
+	} else if env := proxyFromEnv(); env != "" {
+		fmt.Fprintf(w, "Proxy:     %s (environment)\n", env)
+	} else {
+		fmt.Fprintln(w, "Proxy:     none")
+	}
+	fmt.Fprintf(w, "Timeout:   %s per provider call\n", providerTimeout())
+
+	found := findCredentialProviders()
+	fmt.Fprintf(w, "\nDiscovered %d credential provider(s):\n", len(found))
+	for _, p := range found {
+		how := ""
+		if p.isDLL {
+			how = " (dotnet exec)"
+		}
+		fmt.Fprintf(w, "  %s%s\n", p.path, how)
+	}
+	providers, err := providersFor(found, source.Name)
+	if err != nil {
+		fmt.Fprintf(w, "\n%s %v\n", glyphs.Fail, err)
+		return exitError
+	}
+	if pin := pinnedProviders[strings.ToLower(source.Name)]; pin != "" {
+		fmt.Fprintf(w, "Pinned for %s: %s\n", source.Name, providers[0].path)
+	}
+	if len(providers) == 0 {
+		fmt.Fprintf(w, "\n%s No credential providers to try\n", glyphs.Fail)
+		return exitError
+	}
+
+	req := providerRequest{
+		sourceURL:   source.URL,
+		env:         source.Proxy.environ(os.Environ()),
+		interactive: true,
+		echo:        w,
+	}
+	ok := false
+	for _, p := range providers {
+		name := filepath.Base(p.path)
+		fmt.Fprintf(w, "\nAsking %s...\n", name)
+		start := time.Now()
+		cred, err := invokeProvider(p, req)
+		elapsed := time.Since(start).Round(10 * time.Millisecond)
+		switch {
+		case err != nil:
+			fmt.Fprintf(w, "%s %s failed after %s: %v\n", glyphs.Fail, name, elapsed, err)
+		case cred.Username == "" && cred.Password == "":
+			fmt.Fprintf(w, "%s %s returned no credentials after %s\n", glyphs.Fail, name, elapsed)
+		default:
+			ok = true
+			fmt.Fprintf(w, "%s %s supplied credentials in %s (username %q, password %d chars)\n", glyphs.OK, name, elapsed, cred.Username, len(cred.Password))
+		}
+	}
+	if !ok {
+		return exitError
+	}
+	return exitOK
+}
+
+// proxyFromEnv returns the proxy a provider would pick up from guget's own
+// environment, with any password masked, or "".
+func proxyFromEnv() string {
+	for _, key := range []string{"https_proxy", "HTTPS_PROXY", "http_proxy", "HTTP_PROXY"} {
+		if v := os.Getenv(key); v != "" {
+			if u, err := url.Parse(v); err == nil {
+				return u.Redacted()
+			}
+			return v
+		}
+	}
+	return ""
+}
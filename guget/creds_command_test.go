@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeProvider installs a V1 credential provider script under
+// home/.nuget/plugins/netcore that prints the given output.
+func fakeProvider(t *testing.T, home, name, script string) string {
+	t.Helper()
+	dir := filepath.Join(home, ".nuget", "plugins", "netcore", name)
+	os.MkdirAll(dir, 0755)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProvidersFor(t *testing.T) {
+	t.Cleanup(func() { setPinnedProviders(nil) })
+	providers := []credentialProvider{
+		{path: filepath.FromSlash("/plugins/CredentialProvider.Microsoft/CredentialProvider.Microsoft.dll"), isDLL: true},
+		{path: filepath.FromSlash("/tools/nuget-plugin-corp")},
+	}
+	setPinnedProviders(map[string]string{"Corp": "nuget-plugin-corp", "azure": "CredentialProvider.Microsoft", "other": "missing"})
+
+	if got, err := providersFor(providers, "corp"); err != nil || len(got) != 1 || got[0] != providers[1] {
+		t.Errorf("corp: got %v, %v; want the pinned plugin (source names ignore case)", got, err)
+	}
+	if got, err := providersFor(providers, "azure"); err != nil || len(got) != 1 || got[0] != providers[0] {
+		t.Errorf("azure: got %v, %v; want the DLL matched without its extension", got, err)
+	}
+	if got, _ := providersFor(providers, "nuget.org"); len(got) != 2 {
+		t.Errorf("unpinned source: got %v, want every provider", got)
+	}
+	if _, err := providersFor(providers, "other"); err == nil {
+		t.Error("expected an error for a pinned provider that does not exist")
+	}
+}
+
+func TestTestCredentialProviders(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake providers are shell scripts")
+	}
+	t.Cleanup(func() { setPinnedProviders(nil) })
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", "/bin:/usr/bin")
+	for _, key := range []string{"NUGET_NETCORE_PLUGIN_PATHS", "NUGET_PLUGIN_PATHS", "NUGET_CREDENTIALPROVIDER_PLUGIN_PATHS"} {
+		t.Setenv(key, "")
+	}
+	fakeProvider(t, home, "good-provider", `echo "signing in through $HTTPS_PROXY" >&2
+echo '{"Username":"VssSessionToken","Password":"secret"}'`)
+	fakeProvider(t, home, "broken-provider", `echo "proxy authentication required" >&2
+exit 1`)
+	source := NugetSource{Name: "corp", URL: "https://pkgs.corp.example/v3/index.json", Proxy: nugetProxy{URL: "http://proxy:8080"}}
+
+	var out strings.Builder
+	if code := testCredentialProviders(&out, source); code != exitOK {
+		t.Errorf("exit code = %d, want %d\n%s", code, exitOK, out.String())
+	}
+	for _, want := range []string{
+		"Proxy:     http://proxy:8080 (http_proxy in nuget.config)",
+		"Discovered 2 credential provider(s)",
+		"[good-provider] signing in through http://proxy:8080",
+		`✓ good-provider supplied credentials`,
+		"✗ broken-provider failed",
+		"(stderr: proxy authentication required)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	setPinnedProviders(map[string]string{"corp": "broken-provider"})
+	out.Reset()
+	if code := testCredentialProviders(&out, source); code != exitError {
+		t.Errorf("pinned to the broken provider: exit code = %d, want %d", code, exitError)
+	}
+	if strings.Contains(out.String(), "Asking good-provider") {
+		t.Errorf("pinned source should only ask the pinned provider:\n%s", out.String())
+	}
+}
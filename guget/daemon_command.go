@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// daemonRun loads the workspace afresh, checks every package and writes the
+// report, then posts it to the webhook when one is set, with what changed
+// since prev, the last report announced. Returns the report and the path
+// written.
+func daemonRun(flags BuiltFlags, rules packageRules, prev *packageReport, now time.Time) (*packageReport, string, error) {
+	snap, err := loadWorkspace(flags.ProjectDir, flags.Solution)
+	if err != nil {
+		return nil, "", err
+	}
+	results := fetchResults(snap, flags.Deadline, !flags.NoEnrich)
+	rep := buildReport(snap.ProjectDir, snap.ParsedProjects, results, rules, flags.Prerelease, now)
+	path, err := saveReport(snap.ProjectDir, flags.Output, flags.Format, rep)
+	if err != nil {
+		return nil, "", fmt.Errorf("writing report: %w", err)
+	}
+	if flags.Webhook != "" {
+		if err := announceReport(flags.Webhook, flags.WebhookFormat, announcedReportPath(flags.Output), prev, rep); err != nil {
+			return &rep, path, fmt.Errorf("posting to webhook: %w", err)
+		}
+	}
+	return &rep, path, nil
+}
+
+// runDaemonCommand implements `guget daemon`: a report every --interval
+// until interrupted. A failed run is logged and the next one goes ahead as
+// planned. Returns the process exit code.
+func runDaemonCommand(flags BuiltFlags, settings UserConfig) int {
+	if !slices.Contains(validReportFormats, flags.Format) {
+		logError("Unknown report format %q (expected %s)", flags.Format, strings.Join(validReportFormats, ", "))
+		return exitError
+	}
+	if flags.Interval < time.Minute {
+		logError("--interval must be at least 1m, got %s", flags.Interval)
+		return exitError
+	}
+	if err := validateWebhookURL(flags.Webhook); err != nil {
+		logError("%v", err)
+		return exitError
+	}
+
+	metrics := &daemonMetrics{}
+	if flags.MetricsAddr != "" {
+		srv, err := serveMetrics(flags.MetricsAddr, metrics)
+		if err != nil {
+			logError("Metrics endpoint: %v", err)
+			return exitError
+		}
+		defer srv.Close()
+		if !flags.Quiet {
+			fmt.Printf("Serving metrics on http://%s/metrics\n", srv.Addr)
+		}
+	}
+
+	// The last report the webhook was told about, kept next to --output, is
+	// the baseline so a restart doesn't announce everything again.
+	prev := previousReport(announcedReportPath(flags.Output))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	for {
+		start := time.Now()
+		rep, path, err := daemonRun(flags, settings.Packages, prev, start)
+		metrics.record(rep, start, err)
+		// A run whose webhook post failed still has a report, but what it
+		// found hasn't been announced yet: keep the old baseline so the next
+		// run posts it again.
+		if err == nil {
+			prev = rep
+		}
+		switch {
+		case err != nil:
+			logError("%v", err)
+		case !flags.Quiet:
+			sum := summarizeReport(*rep)
+			fmt.Printf("%s  %d outdated, %d vulnerable, %d deprecated, %d not checked → %s\n",
+				start.Format(time.DateTime), sum.Outdated, sum.Vulnerable, sum.Deprecated, sum.Failed, path)
+		}
+
+		next := start.Add(flags.Interval)
+		select {
+		case <-ctx.Done():
+			return exitOK
+		case <-time.After(time.Until(next)):
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// daemonMetrics holds what the last daemon run found, served in the
+// Prometheus text format on --metrics-addr.
+type daemonMetrics struct {
+	mu       sync.Mutex
+	report   *packageReport // last report written; nil before the first
+	runs     int
+	failures int
+	lastRun  time.Time
+	duration time.Duration
+	lastOK   bool
+}
+
+// record stores the outcome of a run that started at start.
+func (m *daemonMetrics) record(rep *packageReport, start time.Time, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs++
+	m.lastRun, m.duration, m.lastOK = start, time.Since(start), err == nil
+	if err != nil {
+		m.failures++
+	}
+	if rep != nil {
+		m.report = rep
+	}
+}
+
+func (m *daemonMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.write(w)
+}
+
+// projectMetrics are the gauges kept per project.
+type projectMetrics struct {
+	references, outdated, deprecated, unchecked int
+	vulnerable                                  map[string]int // by the most severe advisory
+}
+
+var metricSeverities = []string{"critical", "high", "moderate", "low"}
+
+// write renders the metrics. Every project reports every severity, zero
+// included, so graphs don't break when a count drops to nothing.
+func (m *daemonMetrics) write(w io.Writer) {
+	gauge := func(name, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+	counter := func(name, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	}
+
+	counter("guget_runs_total", "Daemon runs since start.")
+	fmt.Fprintf(w, "guget_runs_total %d\n", m.runs)
+	counter("guget_run_failures_total", "Daemon runs that failed since start.")
+	fmt.Fprintf(w, "guget_run_failures_total %d\n", m.failures)
+	if m.runs > 0 {
+		gauge("guget_last_run_timestamp_seconds", "When the last run started, as a Unix time.")
+		fmt.Fprintf(w, "guget_last_run_timestamp_seconds %d\n", m.lastRun.Unix())
+		gauge("guget_last_run_duration_seconds", "How long the last run took.")
+		fmt.Fprintf(w, "guget_last_run_duration_seconds %.3f\n", m.duration.Seconds())
+		gauge("guget_last_run_success", "1 when the last run succeeded, 0 when it failed.")
+		fmt.Fprintf(w, "guget_last_run_success %d\n", boolMetric(m.lastOK))
+	}
+	if m.report == nil {
+		return
+	}
+
+	projects := make(map[string]*projectMetrics)
+	var order []string
+	for _, p := range m.report.Projects {
+		name := metricProjectName(m.report.Root, p.Path)
+		projects[name] = &projectMetrics{vulnerable: make(map[string]int)}
+		order = append(order, name)
+	}
+	for _, st := range m.report.Packages {
+		pm := projects[metricProjectName(m.report.Root, st.ProjectPath)]
+		if pm == nil {
+			continue
+		}
+		pm.references++
+		if st.Outdated {
+			pm.outdated++
+		}
+		if st.Deprecated {
+			pm.deprecated++
+		}
+		if st.Error != "" {
+			pm.unchecked++
+		}
+		if worst := worstSeverity(st.Advisories); worst != "" {
+			pm.vulnerable[worst]++
+		}
+	}
+	slices.Sort(order)
+
+	perProject := func(name, help string, value func(*projectMetrics) int) {
+		gauge(name, help)
+		for _, p := range order {
+			fmt.Fprintf(w, "%s{project=\"%s\"} %d\n", name, metricLabel(p), value(projects[p]))
+		}
+	}
+	perProject("guget_package_references", "Package references in the project.", func(pm *projectMetrics) int { return pm.references })
+	perProject("guget_outdated_count", "Package references with a newer compatible version.", func(pm *projectMetrics) int { return pm.outdated })
+	perProject("guget_deprecated_count", "Package references deprecated by their author.", func(pm *projectMetrics) int { return pm.deprecated })
+	perProject("guget_unchecked_count", "Package references that could not be checked.", func(pm *projectMetrics) int { return pm.unchecked })
+	gauge("guget_vulnerable_count", "Package references with security advisories, by their most severe one.")
+	for _, p := range order {
+		for _, sev := range metricSeverities {
+			fmt.Fprintf(w, "guget_vulnerable_count{project=\"%s\",severity=\"%s\"} %d\n", metricLabel(p), sev, projects[p].vulnerable[sev])
+		}
+	}
+}
+
+// worstSeverity returns the most severe of advisories, or "" when there are
+// none.
+func worstSeverity(advisories []statusAdvisory) string {
+	worst := -1
+	for _, a := range advisories {
+		if i := slices.Index(metricSeverities, a.Severity); i >= 0 && (worst < 0 || i < worst) {
+			worst = i
+		}
+	}
+	if worst < 0 {
+		return ""
+	}
+	return metricSeverities[worst]
+}
+
+// metricProjectName labels a project by its path under the workspace root,
+// which stays unique when project file names repeat.
+func metricProjectName(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// metricLabel escapes a label value for the Prometheus text format.
+func metricLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func boolMetric(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// serveMetrics listens on addr and serves m at /metrics in the background.
+// The listener is opened before returning so a busy port fails the command
+// straight away.
+func serveMetrics(addr string, m *daemonMetrics) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	srv := &http.Server{Addr: ln.Addr().String(), Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			logError("Metrics endpoint: %v", err)
+		}
+	}()
+	return srv, nil
+}
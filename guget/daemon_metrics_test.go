@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDaemonMetrics(t *testing.T) {
+	m := &daemonMetrics{}
+	srv, err := serveMetrics("127.0.0.1:0", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	scrape := func() string {
+		t.Helper()
+		resp, err := http.Get("http://" + srv.Addr + "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if got := scrape(); !strings.Contains(got, "guget_runs_total 0\n") || strings.Contains(got, "guget_outdated_count") {
+		t.Errorf("before the first run:\n%s", got)
+	}
+
+	rep := reportTestFixture(t)
+	m.record(&rep, time.Now(), nil)
+	got := scrape()
+	for _, want := range []string{
+		"# TYPE guget_outdated_count gauge\n",
+		`guget_outdated_count{project="src/A/A.csproj"} 1` + "\n",
+		`guget_deprecated_count{project="src/A/A.csproj"} 1` + "\n",
+		`guget_vulnerable_count{project="src/A/A.csproj",severity="critical"} 1` + "\n",
+		`guget_vulnerable_count{project="src/A/A.csproj",severity="low"} 0` + "\n",
+		"guget_last_run_success 1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics lack %q:\n%s", want, got)
+		}
+	}
+
+	m.record(nil, time.Now(), errors.New("no reachable NuGet sources found"))
+	got = scrape()
+	if !strings.Contains(got, "guget_last_run_success 0\n") || !strings.Contains(got, "guget_run_failures_total 1\n") ||
+		!strings.Contains(got, `guget_outdated_count{project="src/A/A.csproj"} 1`) {
+		t.Errorf("after a failed run the last report should still be served:\n%s", got)
+	}
+}
+
+func TestMetricLabel(t *testing.T) {
+	if got := metricLabel(`C:\src\"odd"` + "\n"); got != `C:\\src\\\"odd\"\n` {
+		t.Errorf("metricLabel = %s", got)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotDir is where snapshots are stored, relative to the workspace root.
+const snapshotDir = ".guget/snapshots"
+
+// DependencySnapshot is the recorded package state of a workspace at one
+// point in time, written by `guget snapshot`.
+type DependencySnapshot struct {
+	Created  time.Time         `json:"created"`
+	Projects []SnapshotProject `json:"projects"`
+}
+
+// SnapshotProject holds one project's references, keyed by its path relative
+// to the workspace root so snapshots stay comparable across checkouts.
+type SnapshotProject struct {
+	Path       string            `json:"path"`
+	Frameworks []string          `json:"frameworks,omitempty"`
+	Packages   map[string]string `json:"packages"` // package ID → version
+}
+
+// snapshotFromProjects records the references of every parseable project.
+func snapshotFromProjects(root string, projects []*ParsedProject) DependencySnapshot {
+	snap := DependencySnapshot{Created: time.Now().UTC()}
+	for _, p := range projects {
+		if p.LoadErr != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, p.FilePath)
+		if err != nil {
+			rel = p.FilePath
+		}
+		sp := SnapshotProject{Path: filepath.ToSlash(rel), Packages: make(map[string]string)}
+		for fw := range p.TargetFrameworks {
+			sp.Frameworks = append(sp.Frameworks, fw.String())
+		}
+		sort.Strings(sp.Frameworks)
+		for ref := range p.Packages {
+			sp.Packages[ref.Name] = ref.Version.String()
+		}
+		snap.Projects = append(snap.Projects, sp)
+	}
+	sort.Slice(snap.Projects, func(i, j int) bool { return snap.Projects[i].Path < snap.Projects[j].Path })
+	return snap
+}
+
+// captureSnapshot parses the workspace under root, or the projects listed in
+// solution when it is set, without contacting any NuGet source.
+func captureSnapshot(root, solution string) (DependencySnapshot, error) {
+	discovery, err := DiscoverProjects(root, solution)
+	files := discovery.Files
+	if err != nil {
+		return DependencySnapshot{}, fmt.Errorf("finding projects: %w", err)
+	}
+	if len(files) == 0 {
+		return DependencySnapshot{}, fmt.Errorf("no project files (%s) found in: %s", projectFileKinds(), root)
+	}
+	var projects []*ParsedProject
+	for _, file := range files {
+		p, err := ParseCsproj(file)
+		if err != nil {
+			logWarn("Could not parse project %s: %v", file, err)
+			continue
+		}
+		projects = append(projects, p)
+	}
+	return snapshotFromProjects(root, projects), nil
+}
+
+// saveSnapshot writes snap to path, or to a timestamped file under
+// snapshotDir when path is empty. Returns the path written.
+func saveSnapshot(root, path string, snap DependencySnapshot) (string, error) {
+	if path == "" {
+		path = filepath.Join(root, filepath.FromSlash(snapshotDir), snap.Created.Format("20060102-150405")+".json")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func loadSnapshot(path string) (DependencySnapshot, error) {
+	var snap DependencySnapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snap, err
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snap, fmt.Errorf("parsing snapshot %s: %w", path, err)
+	}
+	return snap, nil
+}
+
+// latestSnapshotPath returns the newest snapshot under root's snapshotDir.
+// Names are timestamps, so the lexically greatest is the newest.
+func latestSnapshotPath(root string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(snapshotDir), "*.json"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no snapshots in %s (run `guget snapshot` first)", filepath.Join(root, filepath.FromSlash(snapshotDir)))
+	}
+	sort.Strings(matches)
+	return matches[len(matches)-1], nil
+}
+
+// SnapshotChange is one difference between two snapshots. Old is empty for
+// an added package and New is empty for a removed one.
+type SnapshotChange struct {
+	Project string
+	Package string
+	Old     string
+	New     string
+}
+
+// diffSnapshots lists per-package changes from old to new, ordered by
+// project then package. Whole projects that appear or disappear show up as
+// every package added or removed.
+func diffSnapshots(old, new DependencySnapshot) []SnapshotChange {
+	// Package ids are case-insensitive, so a change of casing alone is not
+	// a removal and an addition.
+	type entry struct{ name, version string }
+	index := func(s DependencySnapshot) map[string]map[string]entry {
+		m := make(map[string]map[string]entry, len(s.Projects))
+		for _, p := range s.Projects {
+			pkgs := make(map[string]entry, len(p.Packages))
+			for name, version := range p.Packages {
+				pkgs[strings.ToLower(name)] = entry{name, version}
+			}
+			m[p.Path] = pkgs
+		}
+		return m
+	}
+	oldIdx, newIdx := index(old), index(new)
+
+	var changes []SnapshotChange
+	for path, oldPkgs := range oldIdx {
+		newPkgs := newIdx[path]
+		for key, o := range oldPkgs {
+			if n, ok := newPkgs[key]; !ok {
+				changes = append(changes, SnapshotChange{Project: path, Package: o.name, Old: o.version})
+			} else if n.version != o.version {
+				changes = append(changes, SnapshotChange{Project: path, Package: n.name, Old: o.version, New: n.version})
+			}
+		}
+	}
+	for path, newPkgs := range newIdx {
+		oldPkgs := oldIdx[path]
+		for key, n := range newPkgs {
+			if _, ok := oldPkgs[key]; !ok {
+				changes = append(changes, SnapshotChange{Project: path, Package: n.name, New: n.version})
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Project != changes[j].Project {
+			return changes[i].Project < changes[j].Project
+		}
+		return strings.ToLower(changes[i].Package) < strings.ToLower(changes[j].Package)
+	})
+	return changes
+}
+
+// writeSnapshotDiff prints changes grouped by project as plain text.
+func writeSnapshotDiff(w io.Writer, changes []SnapshotChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No dependency changes.")
+		return
+	}
+	project := ""
+	for _, c := range changes {
+		if c.Project != project {
+			if project != "" {
+				fmt.Fprintln(w)
+			}
+			project = c.Project
+			fmt.Fprintln(w, project)
+		}
+		switch {
+		case c.Old == "":
+			fmt.Fprintf(w, "  + %s %s\n", c.Package, c.New)
+		case c.New == "":
+			fmt.Fprintf(w, "  - %s %s\n", c.Package, c.Old)
+		default:
+			fmt.Fprintf(w, "  ~ %s %s → %s\n", c.Package, c.Old, c.New)
+		}
+	}
+}
+
+// runSnapshotCommand implements `guget snapshot` and `guget diff-snapshot`.
+// Returns the process exit code.
+func runSnapshotCommand(command string, flags BuiltFlags) int {
+	root, err := filepath.Abs(flags.ProjectDir)
+	if err != nil {
+		logError("Couldn't get absolute path for project directory: %v", err)
+		return 1
+	}
+
+	switch command {
+	case "snapshot":
+		snap, err := captureSnapshot(root, flags.Solution)
+		if err != nil {
+			logError("%v", err)
+			return 1
+		}
+		path, err := saveSnapshot(root, flags.Output, snap)
+		if err != nil {
+			logError("Writing snapshot: %v", err)
+			return 1
+		}
+		if flags.Quiet {
+			fmt.Println(path)
+		} else {
+			fmt.Printf("Saved snapshot of %d project(s) to %s\n", len(snap.Projects), path)
+		}
+
+	case "diff-snapshot":
+		fromPath := flags.From
+		if fromPath == "" {
+			if fromPath, err = latestSnapshotPath(root); err != nil {
+				logError("%v", err)
+				return 1
+			}
+		}
+		from, err := loadSnapshot(fromPath)
+		if err != nil {
+			logError("%v", err)
+			return 1
+		}
+		var to DependencySnapshot
+		if flags.To != "" {
+			to, err = loadSnapshot(flags.To)
+		} else {
+			to, err = captureSnapshot(root, flags.Solution)
+		}
+		if err != nil {
+			logError("%v", err)
+			return 1
+		}
+		writeSnapshotDiff(os.Stdout, diffSnapshots(from, to))
+	}
+	return 0
+}
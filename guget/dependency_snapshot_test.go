@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	old := DependencySnapshot{Projects: []SnapshotProject{
+		{Path: "src/App/App.csproj", Packages: map[string]string{"Serilog": "3.0.0", "Polly": "7.2.4", "Dapper": "2.0.0"}},
+		{Path: "src/Old/Old.csproj", Packages: map[string]string{"Moq": "4.20.0"}},
+	}}
+	new := DependencySnapshot{Projects: []SnapshotProject{
+		{Path: "src/App/App.csproj", Packages: map[string]string{"Serilog": "4.0.0", "Polly": "7.2.4", "MediatR": "12.0.0"}},
+	}}
+
+	got := diffSnapshots(old, new)
+	want := []SnapshotChange{
+		{Project: "src/App/App.csproj", Package: "Dapper", Old: "2.0.0"},
+		{Project: "src/App/App.csproj", Package: "MediatR", New: "12.0.0"},
+		{Project: "src/App/App.csproj", Package: "Serilog", Old: "3.0.0", New: "4.0.0"},
+		{Project: "src/Old/Old.csproj", Package: "Moq", Old: "4.20.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffSnapshots =\n%v\nwant\n%v", got, want)
+	}
+
+	var out strings.Builder
+	writeSnapshotDiff(&out, got)
+	if !strings.Contains(out.String(), "  ~ Serilog 3.0.0 → 4.0.0\n") {
+		t.Errorf("unexpected diff output:\n%s", out.String())
+	}
+}
+
+func TestDiffSnapshots_IgnoresCasing(t *testing.T) {
+	old := DependencySnapshot{Projects: []SnapshotProject{
+		{Path: "App.csproj", Packages: map[string]string{"newtonsoft.json": "13.0.1", "serilog": "3.0.0"}},
+	}}
+	new := DependencySnapshot{Projects: []SnapshotProject{
+		{Path: "App.csproj", Packages: map[string]string{"Newtonsoft.Json": "13.0.1", "Serilog": "4.0.0"}},
+	}}
+
+	got := diffSnapshots(old, new)
+	want := []SnapshotChange{{Project: "App.csproj", Package: "Serilog", Old: "3.0.0", New: "4.0.0"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffSnapshots = %v, want %v", got, want)
+	}
+}
+
+func TestSnapshot_SaveAndLatest(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "App.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup>
+  <ItemGroup><PackageReference Include="Serilog" Version="3.0.0" /></ItemGroup>
+</Project>`), 0644)
+
+	snap, err := captureSnapshot(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Projects) != 1 || snap.Projects[0].Path != "App.csproj" || snap.Projects[0].Packages["Serilog"] != "3.0.0" {
+		t.Fatalf("unexpected snapshot: %+v", snap.Projects)
+	}
+
+	older := snap
+	older.Created = snap.Created.Add(-time.Hour)
+	if _, err := saveSnapshot(root, "", older); err != nil {
+		t.Fatal(err)
+	}
+	newest, err := saveSnapshot(root, "", snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := latestSnapshotPath(root)
+	if err != nil || latest != newest {
+		t.Errorf("latestSnapshotPath = %q, %v; want %q", latest, err, newest)
+	}
+	loaded, err := loadSnapshot(latest)
+	if err != nil || len(diffSnapshots(snap, loaded)) != 0 {
+		t.Errorf("round trip changed the snapshot: %v", err)
+	}
+}
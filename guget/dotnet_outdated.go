@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// formatDotnetOutdated selects dotnet-outdated's JSON report for
+// `guget outdated --format`, so pipelines parsing it can switch to guget.
+const formatDotnetOutdated = "dotnet-outdated"
+
+// dotnetOutdatedReport mirrors the JSON dotnet-outdated writes with
+// --output-format json: outdated dependencies per project and target
+// framework. Projects with nothing outdated are left out, as it does.
+type dotnetOutdatedReport struct {
+	Projects []dotnetOutdatedProject `json:"Projects"`
+}
+
+type dotnetOutdatedProject struct {
+	Name             string                    `json:"Name"`
+	FilePath         string                    `json:"FilePath"`
+	TargetFrameworks []dotnetOutdatedFramework `json:"TargetFrameworks"`
+}
+
+type dotnetOutdatedFramework struct {
+	Name         string                     `json:"Name"`
+	Dependencies []dotnetOutdatedDependency `json:"Dependencies"`
+}
+
+type dotnetOutdatedDependency struct {
+	Name            string `json:"Name"`
+	ResolvedVersion string `json:"ResolvedVersion"`
+	LatestVersion   string `json:"LatestVersion"`
+	UpgradeSeverity string `json:"UpgradeSeverity"`
+}
+
+// upgradeSeverity names the part of the version an upgrade from → to
+// changes, with dotnet-outdated's values: Major, Minor, Patch, or None when
+// only the revision or pre-release label moves.
+func upgradeSeverity(from, to SemVer) string {
+	switch {
+	case from.Major != to.Major:
+		return "Major"
+	case from.Minor != to.Minor:
+		return "Minor"
+	case from.Patch != to.Patch:
+		return "Patch"
+	default:
+		return "None"
+	}
+}
+
+// buildDotnetOutdatedReport groups the outdated statuses by project and
+// target framework. LatestVersion is the latest compatible version, within
+// the package's update rule, which is what --upgrade moves to.
+func buildDotnetOutdatedReport(statuses []packageStatus) dotnetOutdatedReport {
+	rep := dotnetOutdatedReport{Projects: []dotnetOutdatedProject{}}
+	for _, st := range statuses {
+		if !st.Outdated {
+			continue
+		}
+		if n := len(rep.Projects); n == 0 || rep.Projects[n-1].FilePath != st.ProjectPath {
+			rep.Projects = append(rep.Projects, dotnetOutdatedProject{
+				Name:     strings.TrimSuffix(st.Project, filepath.Ext(st.Project)),
+				FilePath: st.ProjectPath,
+			})
+		}
+		proj := &rep.Projects[len(rep.Projects)-1]
+		dep := dotnetOutdatedDependency{
+			Name:            st.Package,
+			ResolvedVersion: st.Installed,
+			LatestVersion:   st.LatestCompatible,
+			UpgradeSeverity: upgradeSeverity(ParseSemVer(st.Installed), ParseSemVer(st.LatestCompatible)),
+		}
+		var frameworks []string
+		for tf := range st.targets {
+			frameworks = append(frameworks, tf.String())
+		}
+		slices.Sort(frameworks)
+		for _, name := range frameworks {
+			i := slices.IndexFunc(proj.TargetFrameworks, func(f dotnetOutdatedFramework) bool { return f.Name == name })
+			if i < 0 {
+				proj.TargetFrameworks = append(proj.TargetFrameworks, dotnetOutdatedFramework{Name: name})
+				i = len(proj.TargetFrameworks) - 1
+			}
+			proj.TargetFrameworks[i].Dependencies = append(proj.TargetFrameworks[i].Dependencies, dep)
+		}
+	}
+	return rep
+}
+
+// writeDotnetOutdated prints the dotnet-outdated report for statuses.
+func writeDotnetOutdated(w io.Writer, statuses []packageStatus) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(buildDotnetOutdatedReport(statuses))
+}
+
+// saveDotnetOutdated writes the dotnet-outdated report for statuses to
+// path, or to stdout when path is empty.
+func saveDotnetOutdated(path string, statuses []packageStatus) error {
+	if path == "" {
+		return writeDotnetOutdated(os.Stdout, statuses)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeDotnetOutdated(f, statuses); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
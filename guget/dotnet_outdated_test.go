@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeSeverity(t *testing.T) {
+	tests := []struct{ from, to, want string }{
+		{"1.2.3", "2.0.0", "Major"},
+		{"1.2.3", "1.3.0", "Minor"},
+		{"1.2.3", "1.2.4", "Patch"},
+		{"1.2.3-beta", "1.2.3", "None"},
+	}
+	for _, tt := range tests {
+		if got := upgradeSeverity(ParseSemVer(tt.from), ParseSemVer(tt.to)); got != tt.want {
+			t.Errorf("upgradeSeverity(%s, %s) = %s, want %s", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestWriteDotnetOutdated(t *testing.T) {
+	targets := NewSet[TargetFramework]()
+	targets.Add(ParseTargetFramework("net8.0"))
+	targets.Add(ParseTargetFramework("net48"))
+	statuses := []packageStatus{
+		{Project: "App.csproj", ProjectPath: "/src/App/App.csproj", Package: "Current", Installed: "1.0.0", LatestCompatible: "1.0.0", targets: targets},
+		{Project: "App.csproj", ProjectPath: "/src/App/App.csproj", Package: "Serilog", Installed: "2.12.0", LatestCompatible: "4.0.1", Outdated: true, targets: targets},
+		{Project: "Lib.csproj", ProjectPath: "/src/Lib/Lib.csproj", Package: "Polly", Installed: "8.2.0", LatestCompatible: "8.2.1", Outdated: true, targets: NewSet[TargetFramework]()},
+	}
+	statuses[2].targets.Add(ParseTargetFramework("netstandard2.0"))
+
+	var buf bytes.Buffer
+	if err := writeDotnetOutdated(&buf, statuses); err != nil {
+		t.Fatal(err)
+	}
+	dep := func(name, from, to, severity string) string {
+		return `{
+              "Name": "` + name + `",
+              "ResolvedVersion": "` + from + `",
+              "LatestVersion": "` + to + `",
+              "UpgradeSeverity": "` + severity + `"
+            }`
+	}
+	want := `{
+  "Projects": [
+    {
+      "Name": "App",
+      "FilePath": "/src/App/App.csproj",
+      "TargetFrameworks": [
+        {
+          "Name": "net48",
+          "Dependencies": [
+            ` + dep("Serilog", "2.12.0", "4.0.1", "Major") + `
+          ]
+        },
+        {
+          "Name": "net8.0",
+          "Dependencies": [
+            ` + dep("Serilog", "2.12.0", "4.0.1", "Major") + `
+          ]
+        }
+      ]
+    },
+    {
+      "Name": "Lib",
+      "FilePath": "/src/Lib/Lib.csproj",
+      "TargetFrameworks": [
+        {
+          "Name": "netstandard2.0",
+          "Dependencies": [
+            ` + dep("Polly", "8.2.0", "8.2.1", "Patch") + `
+          ]
+        }
+      ]
+    }
+  ]
+}`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	writeDotnetOutdated(&buf, statuses[:1])
+	if got := strings.TrimSpace(buf.String()); got != "{\n  \"Projects\": []\n}" {
+		t.Errorf("nothing outdated: got %s", got)
+	}
+}
+
+func TestRemainingAfterUpgrade(t *testing.T) {
+	stale := []packageStatus{
+		{Package: "Serilog", file: "/src/Directory.Packages.props"},
+		{Package: "Polly", file: "/src/Lib/Lib.csproj", Pinned: true},
+	}
+	plan := []plannedUpdate{{File: "/src/Directory.Packages.props", Package: "serilog", From: "2.12.0", To: "4.0.1"}}
+	left := remainingAfterUpgrade(stale, plan)
+	if len(left) != 1 || left[0].Package != "Polly" {
+		t.Errorf("left = %+v, want only Polly", left)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// restoreJobs is how many projects are restored at once when they are
+// restored one by one. Set from --restore-jobs or the restoreParallelism
+// setting via setRestoreJobs.
+var restoreJobs = min(runtime.NumCPU(), 4)
+
+func setRestoreJobs(n int) {
+	if n > 0 {
+		restoreJobs = n
+	}
+}
+
+// restoreOutcome is the result of restoring one project or solution.
+type restoreOutcome struct {
+	Target   string
+	Elapsed  time.Duration
+	Err      error
+	Messages []restoreMessage // NuGet warnings and errors, deduplicated
+}
+
+// restoreMessage is one NuGet warning or error from restore output, e.g.
+//
+//	/src/App/App.csproj : warning NU1603: App depends on Foo (>= 1.0.0) but Foo 1.0.0 was not found. [/src/App.sln]
+type restoreMessage struct {
+	Severity string // "warning" or "error"
+	Code     string // e.g. NU1603
+	Project  string // project file the message is about
+	Package  string // package id the message names, when recognised
+	Text     string
+}
+
+// runRestores calls restore for every target, at most jobs at a time. It
+// returns each target's outcome, timed, in target order, and an error naming
+// each target that failed.
+func runRestores(targets []string, jobs int, restore func(target string) restoreOutcome) ([]restoreOutcome, error) {
+	outcomes := make([]restoreOutcome, len(targets))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(1, jobs))
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			start := time.Now()
+			outcomes[i] = restore(target)
+			outcomes[i].Target = target
+			outcomes[i].Elapsed = time.Since(start)
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	var details []error
+	for _, o := range outcomes {
+		if o.Err != nil {
+			name := filepath.Base(o.Target)
+			failed = append(failed, name)
+			details = append(details, fmt.Errorf("%s: %w", name, o.Err))
+		}
+	}
+	if len(failed) == 0 {
+		return outcomes, nil
+	}
+	return outcomes, fmt.Errorf("%d of %d restores failed (%s)\n%w", len(failed), len(targets), strings.Join(failed, ", "), errors.Join(details...))
+}
+
+// errRestoreCancelled is the outcome of a restore stopped by its context.
+var errRestoreCancelled = errors.New("cancelled")
+
+// dotnetRestore runs dotnet restore on one project or solution, passing
+// each line of its output to onLine as it is written, and records it in the
+// action log. Cancelling ctx kills it; targets not yet started are skipped.
+func dotnetRestore(ctx context.Context, target string, onLine func(string)) restoreOutcome {
+	if ctx.Err() != nil {
+		return restoreOutcome{Err: errRestoreCancelled}
+	}
+	logDebug("dotnet restore: %s", target)
+	cmd := exec.CommandContext(ctx, "dotnet", "restore", target)
+	// MSBuild nodes outlive a killed dotnet and would hold the pipe open.
+	cmd.WaitDelay = 2 * time.Second
+	pr, pw := io.Pipe()
+	cmd.Stdout, cmd.Stderr = pw, pw
+	var out strings.Builder
+	scanned := make(chan struct{})
+	go func() {
+		defer close(scanned)
+		sc := bufio.NewScanner(pr)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		for sc.Scan() {
+			out.WriteString(sc.Text())
+			out.WriteByte('\n')
+			if onLine != nil {
+				onLine(sc.Text())
+			}
+		}
+		io.Copy(io.Discard, pr)
+	}()
+	err := cmd.Run()
+	pw.Close()
+	<-scanned
+
+	if ctx.Err() != nil {
+		logInfo("restore cancelled for %s", filepath.Base(target))
+		recordAction(ActionRecord{Action: "restore", Files: []string{target}}, errRestoreCancelled)
+		return restoreOutcome{Err: errRestoreCancelled}
+	}
+	recordAction(ActionRecord{Action: "restore", Files: []string{target}}, err)
+	text := strings.TrimSpace(out.String())
+	outcome := restoreOutcome{Messages: parseRestoreMessages(text, target)}
+	if err != nil {
+		logWarn("restore failed for %s: %v\n%s", target, err, text)
+		outcome.Err = fmt.Errorf("%w\n%s", err, text)
+		return outcome
+	}
+	logInfo("restore succeeded for %s", filepath.Base(target))
+	return outcome
+}
+
+var (
+	restoreMessageRe = regexp.MustCompile(`^\s*(?:(.+?)\s*:\s*)?(warning|error)\s+(NU\d{4})\s*:\s*(.*?)(?:\s*\[[^\]]*\])?\s*$`)
+
+	// restorePackageRes pick the package id out of the common NuGet
+	// messages, tried in order:
+	//
+	//	NU1701, NU190x  Package 'Foo 1.0.0' was restored using ...
+	//	NU1605          Detected package downgrade: Foo from 2.0.0 to 1.0.0
+	//	NU1603, NU1102  App depends on Foo (>= 1.0.0) but ...
+	//	NU1608          ... A 1.0 requires Foo (>= 2.0.0) but version ...
+	//	NU1101          Unable to find package Foo. No packages exist ...
+	restorePackageRes = []*regexp.Regexp{
+		regexp.MustCompile(`Package '([^'\s]+)`),
+		regexp.MustCompile(`package downgrade: (\S+) from`),
+		regexp.MustCompile(`depends on (\S+) \(`),
+		regexp.MustCompile(`requires (\S+) \(`),
+		regexp.MustCompile(`Unable to find package (\S+?)\.?(?:\s|$)`),
+	}
+)
+
+// parseRestoreMessages extracts the NuGet warnings and errors from restore
+// output. Messages without a project file of their own are attributed to
+// target.
+func parseRestoreMessages(out, target string) []restoreMessage {
+	var msgs []restoreMessage
+	seen := make(map[restoreMessage]bool)
+	for _, line := range strings.Split(strings.ReplaceAll(out, "\r\n", "\n"), "\n") {
+		m := restoreMessageRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		msg := restoreMessage{Severity: m[2], Code: m[3], Project: strings.TrimSpace(m[1]), Text: m[4]}
+		if !isProjectFile(msg.Project) {
+			msg.Project = target
+		}
+		for _, re := range restorePackageRes {
+			if pm := re.FindStringSubmatch(msg.Text); pm != nil {
+				msg.Package = pm[1]
+				break
+			}
+		}
+		if !seen[msg] {
+			seen[msg] = true
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// nugetCodeSummaries explains the NuGet codes restore most often logs
+// against a single package.
+var nugetCodeSummaries = map[string]string{
+	"NU1101": "no source has a package with this id",
+	"NU1102": "no source has the requested version",
+	"NU1103": "only pre-release versions match the requested range",
+	"NU1107": "dependencies ask for conflicting versions",
+	"NU1109": "a central version is lower than a dependency requires",
+	"NU1504": "the package is referenced more than once",
+	"NU1603": "the requested version was not found; a higher one was used",
+	"NU1605": "a dependency needs a higher version than the one referenced",
+	"NU1608": "the resolved version is outside a dependency's range",
+	"NU1701": "restored for a fallback framework; it may not be compatible",
+	"NU1900": "vulnerability data could not be fetched",
+	"NU1901": "low severity vulnerability",
+	"NU1902": "moderate severity vulnerability",
+	"NU1903": "high severity vulnerability",
+	"NU1904": "critical severity vulnerability",
+}
+
+// nugetCodeSummary returns a short explanation of a NuGet code, or "".
+func nugetCodeSummary(code string) string {
+	return nugetCodeSummaries[strings.ToUpper(code)]
+}
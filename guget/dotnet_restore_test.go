@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunRestores_BoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	var restored []string
+	targets := []string{"a/A.csproj", "b/B.csproj", "c/C.csproj", "d/D.csproj", "e/E.csproj"}
+
+	outcomes, err := runRestores(targets, 2, func(target string) restoreOutcome {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		restored = append(restored, target)
+		mu.Unlock()
+		return restoreOutcome{}
+	})
+	if err != nil {
+		t.Fatalf("runRestores: %v", err)
+	}
+	if len(restored) != len(targets) {
+		t.Errorf("restored %v, want every target", restored)
+	}
+	if p := peak.Load(); p != 2 {
+		t.Errorf("peak concurrency = %d, want 2", p)
+	}
+	for i, o := range outcomes {
+		if o.Target != targets[i] || o.Elapsed < 10*time.Millisecond {
+			t.Errorf("outcome %d = %s in %v, want %s timed", i, o.Target, o.Elapsed, targets[i])
+		}
+	}
+}
+
+func TestRunRestores_AggregatesFailures(t *testing.T) {
+	targets := []string{"src/Api/Api.csproj", "src/Core/Core.csproj", "tests/Api.Tests/Api.Tests.csproj"}
+	_, err := runRestores(targets, 3, func(target string) restoreOutcome {
+		if strings.Contains(target, "Core") {
+			return restoreOutcome{}
+		}
+		return restoreOutcome{Err: errors.New("exit status 1\nNU1101: Unable to find package")}
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	first, _, _ := strings.Cut(err.Error(), "\n")
+	if want := "2 of 3 restores failed (Api.csproj, Api.Tests.csproj)"; first != want {
+		t.Errorf("first line = %q, want %q", first, want)
+	}
+	if !strings.Contains(err.Error(), "Api.Tests.csproj: exit status 1") {
+		t.Errorf("error %q should include each failure's output", err)
+	}
+}
+
+func TestParseRestoreMessages(t *testing.T) {
+	out := strings.Join([]string{
+		"  Determining projects to restore...",
+		"/src/App/App.csproj : warning NU1603: App depends on Contoso.Core (>= 1.0.0) but Contoso.Core 1.0.0 was not found. An approximate best match of Contoso.Core 1.0.1 was resolved. [/src/All.sln]",
+		"/src/App/App.csproj : warning NU1603: App depends on Contoso.Core (>= 1.0.0) but Contoso.Core 1.0.0 was not found. An approximate best match of Contoso.Core 1.0.1 was resolved. [/src/All.sln]",
+		"/src/Lib/Lib.csproj : warning NU1701: Package 'Legacy.Widgets 2.1.0' was restored using '.NETFramework,Version=v4.6.1' instead of the project target framework 'net8.0'. [/src/All.sln]",
+		"/src/Lib/Lib.csproj : warning NU1903: Package 'System.Text.Json' 8.0.0 has a known high severity vulnerability, https://github.com/advisories/GHSA-hh2w-p6rv-4g7w [/src/All.sln]",
+		"/src/All.sln : error NU1101: Unable to find package Contoso.Missing. No packages exist with this id in source(s): nuget.org",
+		"  Failed to restore /src/App/App.csproj (in 1.2 sec).",
+	}, "\r\n")
+
+	got := parseRestoreMessages(out, "/src/All.sln")
+	want := []restoreMessage{
+		{Severity: "warning", Code: "NU1603", Project: "/src/App/App.csproj", Package: "Contoso.Core"},
+		{Severity: "warning", Code: "NU1701", Project: "/src/Lib/Lib.csproj", Package: "Legacy.Widgets"},
+		{Severity: "warning", Code: "NU1903", Project: "/src/Lib/Lib.csproj", Package: "System.Text.Json"},
+		{Severity: "error", Code: "NU1101", Project: "/src/All.sln", Package: "Contoso.Missing"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseRestoreMessages = %+v, want %d messages", got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		g.Text = ""
+		if g != w {
+			t.Errorf("message %d = %+v, want %+v", i, g, w)
+		}
+	}
+	if strings.Contains(got[0].Text, "All.sln") || !strings.HasPrefix(got[0].Text, "App depends on") {
+		t.Errorf("text = %q, want the message without the location prefix or solution suffix", got[0].Text)
+	}
+}
+
+func TestDotnetRestore_CancelledBeforeStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	o := dotnetRestore(ctx, "App.csproj", func(string) { called = true })
+	if !errors.Is(o.Err, errRestoreCancelled) || called {
+		t.Errorf("dotnetRestore after cancel = %+v (output seen: %v); want errRestoreCancelled and no run", o, called)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// DotnetSDK is one SDK reported by `dotnet --list-sdks`.
+type DotnetSDK struct {
+	Version SemVer
+	Path    string
+}
+
+// parseListSDKs parses `dotnet --list-sdks` output, one "8.0.100 [/path]"
+// line per SDK. Returned newest first.
+func parseListSDKs(out string) []DotnetSDK {
+	var sdks []DotnetSDK
+	for _, line := range strings.Split(out, "\n") {
+		ver, path, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || ver == "" {
+			continue
+		}
+		sdks = append(sdks, DotnetSDK{
+			Version: ParseSemVer(ver),
+			Path:    strings.Trim(strings.TrimSpace(path), "[]"),
+		})
+	}
+	sort.SliceStable(sdks, func(i, j int) bool { return sdks[i].Version.IsNewerThan(sdks[j].Version) })
+	return sdks
+}
+
+// detectSDKs lists the installed .NET SDKs. Returns an error when dotnet is
+// not on PATH or reports no SDKs, in which case restore and friends cannot
+// run at all.
+func detectSDKs() ([]DotnetSDK, error) {
+	if _, err := exec.LookPath("dotnet"); err != nil {
+		return nil, fmt.Errorf("dotnet not found on PATH")
+	}
+	out, err := exec.Command("dotnet", "--list-sdks").Output()
+	if err != nil {
+		return nil, fmt.Errorf("dotnet --list-sdks: %w", err)
+	}
+	sdks := parseListSDKs(string(out))
+	if len(sdks) == 0 {
+		return nil, fmt.Errorf("no .NET SDKs installed (dotnet --list-sdks is empty)")
+	}
+	return sdks, nil
+}
+
+// sdkSupports reports whether any installed SDK can build tfm. .NET (Core)
+// targets need an SDK of at least the same major.minor; .NET Standard and
+// .NET Framework targets build with any SDK, and unknown targets are assumed
+// fine since they cannot be evaluated.
+func sdkSupports(sdks []DotnetSDK, tfm TargetFramework) bool {
+	if tfm.Family != FamilyNet && tfm.Family != FamilyCoreApp {
+		return true
+	}
+	for _, sdk := range sdks {
+		major, minor := sdk.Version.Major, sdk.Version.Minor
+		if major > tfm.Major || (major == tfm.Major && minor >= tfm.Minor) {
+			return true
+		}
+	}
+	return false
+}
+
+// missingSDKTargets returns the project's target frameworks that no
+// installed SDK can build, sorted for stable output.
+func missingSDKTargets(sdks []DotnetSDK, p *ParsedProject) []string {
+	var missing []string
+	for tfm := range p.TargetFrameworks {
+		if !sdkSupports(sdks, tfm) {
+			missing = append(missing, tfm.String())
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// checkProjectSDKs adds a diagnostic to every project targeting a framework
+// no installed SDK can build.
+func checkProjectSDKs(sdks []DotnetSDK, projects []*ParsedProject) {
+	newest := sdks[0].Version.String()
+	for _, p := range projects {
+		if p.LoadErr != nil || p.Legacy {
+			continue
+		}
+		for _, tfm := range missingSDKTargets(sdks, p) {
+			p.addDiagnostic(DiagMissingSDK, p.FilePath, "%s needs a newer .NET SDK (newest installed: %s)", tfm, newest)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseListSDKs(t *testing.T) {
+	sdks := parseListSDKs(`6.0.428 [/usr/share/dotnet/sdk]
+8.0.404 [/usr/share/dotnet/sdk]
+9.0.100-rc.2.24474.11 [C:\Program Files\dotnet\sdk]
+`)
+	if len(sdks) != 3 {
+		t.Fatalf("got %d SDKs, want 3", len(sdks))
+	}
+	if got := sdks[0].Version.String(); got != "9.0.100-rc.2.24474.11" {
+		t.Errorf("newest = %s, want the 9.0 preview first", got)
+	}
+	if sdks[0].Path != `C:\Program Files\dotnet\sdk` {
+		t.Errorf("path = %q", sdks[0].Path)
+	}
+}
+
+func TestMissingSDKTargets(t *testing.T) {
+	sdks := parseListSDKs("8.0.404 [/sdk]\n")
+	p := &ParsedProject{TargetFrameworks: NewSet[TargetFramework]()}
+	for _, tfm := range []string{"net9.0", "net8.0", "netstandard2.0", "net48", "netcoreapp3.1", "$(Custom)"} {
+		p.TargetFrameworks.Add(ParseTargetFramework(tfm))
+	}
+	missing := missingSDKTargets(sdks, p)
+	if len(missing) != 1 || missing[0] != "net9.0" {
+		t.Errorf("missing = %v, want [net9.0]", missing)
+	}
+
+	checkProjectSDKs(sdks, []*ParsedProject{p})
+	if len(p.Diagnostics) != 1 || p.Diagnostics[0].Kind != DiagMissingSDK {
+		t.Errorf("diagnostics = %+v, want one missing SDK entry", p.Diagnostics)
+	}
+}
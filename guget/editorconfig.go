@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultIndentUnit = "  "
+
+// detectIndentUnit returns one level of indentation as used by lines: a tab
+// if indented lines start with tabs, otherwise the smallest run of leading
+// spaces. Returns "" if no line is indented.
+func detectIndentUnit(lines []string) string {
+	minSpaces := 0
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || trimmed == "\r" || len(trimmed) == len(line) {
+			continue
+		}
+		if line[0] == '\t' {
+			return "\t"
+		}
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if n > 0 && (minSpaces == 0 || n < minSpaces) {
+			minSpaces = n
+		}
+	}
+	return strings.Repeat(" ", minSpaces)
+}
+
+// editorconfigIndent resolves the indent_style / indent_size that applies to
+// filePath from .editorconfig files, walking up until root = true. Closer
+// files and later sections win. Returns "" if nothing applies.
+func editorconfigIndent(filePath string) string {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return ""
+	}
+
+	style, size, tabWidth := "", "", ""
+	dir := filepath.Dir(abs)
+	for {
+		s, sz, tw, root := readEditorconfig(filepath.Join(dir, ".editorconfig"), abs)
+		// Values from closer files take precedence, so only fill gaps.
+		if style == "" {
+			style = s
+		}
+		if size == "" {
+			size = sz
+		}
+		if tabWidth == "" {
+			tabWidth = tw
+		}
+		parent := filepath.Dir(dir)
+		if root || parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	switch strings.ToLower(style) {
+	case "tab":
+		return "\t"
+	case "space":
+		if size == "tab" || size == "" {
+			size = tabWidth
+		}
+		if n, err := strconv.Atoi(size); err == nil && n > 0 {
+			return strings.Repeat(" ", n)
+		}
+		return defaultIndentUnit
+	}
+	return ""
+}
+
+// readEditorconfig returns the indent settings from a single .editorconfig
+// file whose sections match target, and whether it declares root = true.
+func readEditorconfig(path, target string) (style, size, tabWidth string, root bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", "", false
+	}
+	defer f.Close()
+
+	rel, err := filepath.Rel(filepath.Dir(path), target)
+	if err != nil {
+		return "", "", "", false
+	}
+	rel = filepath.ToSlash(rel)
+
+	inPreamble := true
+	matches := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inPreamble = false
+			matches = editorconfigGlobMatch(line[1:len(line)-1], rel)
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		if inPreamble {
+			if key == "root" {
+				root = value == "true"
+			}
+			continue
+		}
+		if !matches {
+			continue
+		}
+		switch key {
+		case "indent_style":
+			style = value
+		case "indent_size":
+			size = value
+		case "tab_width":
+			tabWidth = value
+		}
+	}
+	return style, size, tabWidth, root
+}
+
+// editorconfigGlobMatch reports whether an .editorconfig section pattern
+// matches rel (a slash-separated path relative to the .editorconfig file).
+// Patterns without a slash match the file name in any directory.
+func editorconfigGlobMatch(pattern, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	} else {
+		pattern = strings.TrimPrefix(pattern, "/")
+	}
+
+	var re strings.Builder
+	re.WriteString("(?i)^")
+	inBraces := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			i++
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				i++
+				re.WriteString("(?:.*/)?")
+			} else {
+				re.WriteString(".*")
+			}
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '{':
+			inBraces = true
+			re.WriteString("(?:")
+		case c == '}' && inBraces:
+			inBraces = false
+			re.WriteString(")")
+		case c == ',' && inBraces:
+			re.WriteString("|")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+
+	compiled, err := regexp.Compile(re.String())
+	if err != nil {
+		return false
+	}
+	return compiled.MatchString(rel)
+}
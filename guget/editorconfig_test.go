@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectIndentUnit(t *testing.T) {
+	cases := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"two spaces", []string{"<Project>", "  <ItemGroup>", "    <X />"}, "  "},
+		{"four spaces", []string{"<Project>", "    <ItemGroup>", "        <X />"}, "    "},
+		{"tabs", []string{"<Project>", "\t<ItemGroup>", "\t\t<X />"}, "\t"},
+		{"flat", []string{"<Project>", "</Project>"}, ""},
+	}
+	for _, c := range cases {
+		if got := detectIndentUnit(c.lines); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestEditorconfigGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"*", "src/App/App.csproj", true},
+		{"*.csproj", "src/App/App.csproj", true},
+		{"*.{csproj,props}", "Directory.Build.props", true},
+		{"*.{csproj,props}", "Program.cs", false},
+		{"src/**.csproj", "src/App/App.csproj", true},
+		{"/tests/*.csproj", "src/App/App.csproj", false},
+	}
+	for _, c := range cases {
+		if got := editorconfigGlobMatch(c.pattern, c.rel); got != c.want {
+			t.Errorf("editorconfigGlobMatch(%q, %q) = %v, want %v", c.pattern, c.rel, got, c.want)
+		}
+	}
+}
+
+func TestEditorconfigIndent_NearestWins(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "src")
+	os.MkdirAll(sub, 0755)
+	os.WriteFile(filepath.Join(root, ".editorconfig"), []byte("root = true\n\n[*]\nindent_style = space\nindent_size = 2\n"), 0644)
+	os.WriteFile(filepath.Join(sub, ".editorconfig"), []byte("[*.csproj]\nindent_style = tab\n"), 0644)
+
+	if got := editorconfigIndent(filepath.Join(sub, "App.csproj")); got != "\t" {
+		t.Errorf("csproj: got %q, want tab", got)
+	}
+	if got := editorconfigIndent(filepath.Join(sub, "Directory.Build.props")); got != "  " {
+		t.Errorf("props: got %q, want two spaces", got)
+	}
+}
+
+func TestAddPackageReference_UsesEditorconfigForFlatFile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".editorconfig"), []byte("root = true\n[*.csproj]\nindent_style = space\nindent_size = 4\n"), 0644)
+	tmp := filepath.Join(dir, "Test.csproj")
+	os.WriteFile(tmp, []byte("<Project Sdk=\"Microsoft.NET.Sdk\">\n</Project>\n"), 0644)
+
+	if err := AddPackageReference(tmp, "Polly", "8.5.2"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(tmp)
+	want := "    <ItemGroup>\n        <PackageReference Include=\"Polly\" Version=\"8.5.2\" />\n    </ItemGroup>\n"
+	if !strings.Contains(string(data), want) {
+		t.Fatalf("expected 4-space indented block, got:\n%s", data)
+	}
+}
+
+func TestAddPackageReference_MatchesTabIndentedFile(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, []byte("<Project Sdk=\"Microsoft.NET.Sdk\">\n\t<PropertyGroup>\n\t\t<TargetFramework>net8.0</TargetFramework>\n\t</PropertyGroup>\n</Project>\n"), 0644)
+
+	if err := AddPackageReference(tmp, "Polly", "8.5.2"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(tmp)
+	want := "\t<ItemGroup>\n\t\t<PackageReference Include=\"Polly\" Version=\"8.5.2\" />\n\t</ItemGroup>\n"
+	if !strings.Contains(string(data), want) {
+		t.Fatalf("expected tab indented block, got:\n%s", data)
+	}
+}
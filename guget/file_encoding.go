@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+type textEncoding int
+
+const (
+	encodingUTF8 textEncoding = iota
+	encodingUTF16LE
+	encodingUTF16BE
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// textFile is a project or props file decoded to UTF-8, remembering how it
+// was stored on disk so a rewrite produces the same encoding, BOM and line
+// endings instead of a whole-file diff.
+type textFile struct {
+	Text     string // UTF-8 content without BOM; line endings untouched
+	Encoding textEncoding
+	BOM      bool
+	CRLF     bool // file predominantly uses \r\n line endings
+}
+
+// decodeText detects the BOM/encoding of data and returns it as UTF-8.
+// UTF-16 without a BOM is recognised by the NUL byte pattern of an ASCII
+// first character (as in "<?xml" or "<Project").
+func decodeText(data []byte) *textFile {
+	f := &textFile{}
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		f.BOM = true
+		data = data[len(bomUTF8):]
+	case bytes.HasPrefix(data, bomUTF16LE):
+		f.Encoding, f.BOM = encodingUTF16LE, true
+		data = data[len(bomUTF16LE):]
+	case bytes.HasPrefix(data, bomUTF16BE):
+		f.Encoding, f.BOM = encodingUTF16BE, true
+		data = data[len(bomUTF16BE):]
+	case len(data) >= 2 && data[0] != 0 && data[1] == 0:
+		f.Encoding = encodingUTF16LE
+	case len(data) >= 2 && data[0] == 0 && data[1] != 0:
+		f.Encoding = encodingUTF16BE
+	}
+
+	if f.Encoding == encodingUTF8 {
+		f.Text = string(data)
+	} else {
+		var order binary.ByteOrder = binary.LittleEndian
+		if f.Encoding == encodingUTF16BE {
+			order = binary.BigEndian
+		}
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = order.Uint16(data[2*i:])
+		}
+		f.Text = string(utf16.Decode(units))
+	}
+
+	crlf := strings.Count(f.Text, "\r\n")
+	f.CRLF = crlf > 0 && crlf >= strings.Count(f.Text, "\n")-crlf
+	return f
+}
+
+// encode converts text back to the file's original encoding, with its BOM.
+func (f *textFile) encode(text string) []byte {
+	switch f.Encoding {
+	case encodingUTF16LE, encodingUTF16BE:
+		var order binary.AppendByteOrder = binary.LittleEndian
+		bom := bomUTF16LE
+		if f.Encoding == encodingUTF16BE {
+			order, bom = binary.BigEndian, bomUTF16BE
+		}
+		units := utf16.Encode([]rune(text))
+		out := make([]byte, 0, len(bom)+2*len(units))
+		if f.BOM {
+			out = append(out, bom...)
+		}
+		for _, u := range units {
+			out = order.AppendUint16(out, u)
+		}
+		return out
+	default:
+		if f.BOM {
+			return append(append([]byte{}, bomUTF8...), text...)
+		}
+		return []byte(text)
+	}
+}
+
+// eol returns the suffix to append to a line inserted into Text after it has
+// been split on "\n": "\r" for CRLF files, "" otherwise.
+func (f *textFile) eol() string {
+	if f.CRLF {
+		return "\r"
+	}
+	return ""
+}
+
+// readTextFile reads and decodes path.
+func readTextFile(path string) (*textFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeText(data), nil
+}
+
+// writeTextFile writes text to path using the encoding and BOM of f, and
+// notes the write in the session journal so it can be reverted.
+func writeTextFile(path string, f *textFile, text string) error {
+	before, _ := os.ReadFile(path)
+	data := f.encode(text)
+	if err := writeFileRetry(path, data, 0644); err != nil {
+		return err
+	}
+	sessionJournal.noteWrite(path, before, data)
+	return nil
+}
+
+// unmarshalXMLText decodes data (any supported encoding) and unmarshals it.
+// The content is already UTF-8 by the time the XML decoder sees it, so a
+// declared encoding of utf-16 (common for files saved by older Visual Studio
+// versions) is accepted as-is.
+func unmarshalXMLText(data []byte, v any) error {
+	dec := xml.NewDecoder(strings.NewReader(decodeText(data).Text))
+	dec.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(label) {
+		case "utf-16", "utf-16le", "utf-16be", "unicode":
+			return input, nil
+		}
+		return nil, fmt.Errorf("unsupported encoding %q", label)
+	}
+	return dec.Decode(v)
+}
@@ -23,16 +23,18 @@ type watchedFileState struct {
 
 func isWatchedWorkspaceFile(path string) bool {
 	name := filepath.Base(path)
-	switch strings.ToLower(filepath.Ext(name)) {
-	case ".csproj", ".fsproj", ".vbproj", ".props":
+	if isProjectFile(name) || strings.EqualFold(filepath.Ext(name), ".props") {
 		return true
 	}
-	return strings.EqualFold(name, "nuget.config")
+	return strings.EqualFold(name, "nuget.config") || strings.EqualFold(name, "packages.config") ||
+		isWorkspaceFile(name)
 }
 
-func scanWatchedWorkspaceFiles(rootDir string) (map[string]watchedFileState, error) {
+// scanWatchedWorkspaceFiles lists the watched files under each root; a root
+// may also be a single file.
+func scanWatchedWorkspaceFiles(roots ...string) (map[string]watchedFileState, error) {
 	files := make(map[string]watchedFileState)
-	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+	visit := func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -55,8 +57,13 @@ func scanWatchedWorkspaceFiles(rootDir string) (map[string]watchedFileState, err
 			ModTime: info.ModTime().UnixNano(),
 		}
 		return nil
-	})
-	return files, err
+	}
+	for _, root := range roots {
+		if err := filepath.WalkDir(root, visit); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
 }
 
 func diffWatchedWorkspaceFiles(prev, next map[string]watchedFileState) []string {
@@ -75,11 +82,11 @@ func diffWatchedWorkspaceFiles(prev, next map[string]watchedFileState) []string
 	return changed
 }
 
-// watchWorkspaceFiles polls rootDir and emits reloadRequestedMsg when watched
+// watchWorkspaceFiles polls roots and emits reloadRequestedMsg when watched
 // files change. Returns a stop func that terminates the watcher goroutine.
 // Changes are debounced: bursts within workspaceWatchDebounce coalesce into
 // a single reload so editors that rewrite files rapidly don't thrash.
-func watchWorkspaceFiles(rootDir string, send func(tea.Msg)) func() {
+func watchWorkspaceFiles(roots []string, send func(tea.Msg)) func() {
 	if send == nil {
 		return func() {}
 	}
@@ -87,7 +94,7 @@ func watchWorkspaceFiles(rootDir string, send func(tea.Msg)) func() {
 	stop := make(chan struct{})
 
 	go func() {
-		prev, err := scanWatchedWorkspaceFiles(rootDir)
+		prev, err := scanWatchedWorkspaceFiles(roots...)
 		if err != nil {
 			logWarn("workspace watch init failed: %v", err)
 			prev = make(map[string]watchedFileState)
@@ -104,7 +111,7 @@ func watchWorkspaceFiles(rootDir string, send func(tea.Msg)) func() {
 			case <-stop:
 				return
 			case now := <-ticker.C:
-				next, err := scanWatchedWorkspaceFiles(rootDir)
+				next, err := scanWatchedWorkspaceFiles(roots...)
 				if err != nil {
 					if !os.IsNotExist(err) {
 						logWarn("workspace watch scan failed: %v", err)
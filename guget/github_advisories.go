@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// githubAdvisoryToken authenticates the GitHub Advisory Database lookups
+// that cross-check packages nuget.org has no data for, e.g. internal mirrors
+// of public packages. Empty turns the cross-check off: GitHub's GraphQL API
+// does not answer anonymous requests.
+var githubAdvisoryToken string
+
+func setGitHubAdvisoryToken(token string) { githubAdvisoryToken = token }
+
+// githubGraphQLURL is a variable so tests can point it at a local server.
+var githubGraphQLURL = "https://api.github.com/graphql"
+
+// githubAdvisoryPages caps the pages of 100 vulnerabilities read per
+// package; even the most-advised NuGet packages fit in one.
+const githubAdvisoryPages = 5
+
+const githubVulnerabilitiesQuery = `query($name: String!, $after: String) {
+  securityVulnerabilities(ecosystem: NUGET, package: $name, first: 100, after: $after) {
+    nodes {
+      package { name }
+      vulnerableVersionRange
+      advisory { permalink severity withdrawnAt }
+    }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+// githubVulnerability is one GitHub advisory against a range of a package's
+// versions.
+type githubVulnerability struct {
+	Range    string // e.g. ">= 1.0.0, < 1.2.3"
+	Severity int    // 0=low 1=moderate 2=high 3=critical, as on nuget.org
+	URL      string // https://github.com/advisories/GHSA-…
+}
+
+// githubSeverity maps GitHub's severity names to nuget.org's numbers.
+func githubSeverity(s string) int {
+	switch strings.ToUpper(s) {
+	case "CRITICAL":
+		return 3
+	case "HIGH":
+		return 2
+	case "MODERATE":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// FetchGitHubVulnerabilities returns the advisories the GitHub Advisory
+// Database lists for the NuGet package packageID, without withdrawn ones.
+func FetchGitHubVulnerabilities(packageID string) ([]githubVulnerability, error) {
+	if githubAdvisoryToken == "" {
+		return nil, fmt.Errorf("no GitHub token: set GITHUB_TOKEN or GH_TOKEN")
+	}
+	var vulns []githubVulnerability
+	var after *string
+	for range githubAdvisoryPages {
+		body, err := json.Marshal(map[string]any{
+			"query":     githubVulnerabilitiesQuery,
+			"variables": map[string]any{"name": packageID, "after": after},
+		})
+		if err != nil {
+			return nil, err
+		}
+		logTrace("FetchGitHubVulnerabilities: POST %s for %s", githubGraphQLURL, packageID)
+		req, err := http.NewRequest("POST", githubGraphQLURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+githubAdvisoryToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := githubClient.Do(req)
+		if err != nil {
+			logTrace("FetchGitHubVulnerabilities: fetch error: %v", err)
+			return nil, err
+		}
+		var result struct {
+			Data struct {
+				SecurityVulnerabilities struct {
+					Nodes []struct {
+						Package struct {
+							Name string `json:"name"`
+						} `json:"package"`
+						VulnerableVersionRange string `json:"vulnerableVersionRange"`
+						Advisory               struct {
+							Permalink   string  `json:"permalink"`
+							Severity    string  `json:"severity"`
+							WithdrawnAt *string `json:"withdrawnAt"`
+						} `json:"advisory"`
+					} `json:"nodes"`
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+				} `json:"securityVulnerabilities"`
+			} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		status := resp.StatusCode
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if status != http.StatusOK {
+			logTrace("FetchGitHubVulnerabilities: %s returned HTTP %d", packageID, status)
+			return nil, fmt.Errorf("GitHub API returned %d", status)
+		}
+		if err != nil {
+			logTrace("FetchGitHubVulnerabilities: decode error: %v", err)
+			return nil, err
+		}
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("GitHub API: %s", result.Errors[0].Message)
+		}
+		page := result.Data.SecurityVulnerabilities
+		for _, n := range page.Nodes {
+			// The package filter is not guaranteed to be exact, so names are
+			// compared here as NuGet compares ids.
+			if n.Advisory.WithdrawnAt != nil || !strings.EqualFold(n.Package.Name, packageID) {
+				continue
+			}
+			vulns = append(vulns, githubVulnerability{
+				Range:    n.VulnerableVersionRange,
+				Severity: githubSeverity(n.Advisory.Severity),
+				URL:      n.Advisory.Permalink,
+			})
+		}
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		after = &page.PageInfo.EndCursor
+	}
+	return vulns, nil
+}
+
+// githubRangeContains reports whether v is in a GitHub vulnerable version
+// range: comma-separated constraints such as "= 1.0.0" or ">= 2.0, < 2.1.4",
+// all of which must hold. An unreadable constraint matches nothing.
+func githubRangeContains(versionRange string, v SemVer) bool {
+	matched := false
+	for _, c := range strings.Split(versionRange, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		op := strings.TrimRight(c[:len(c)-len(strings.TrimLeft(c, "<>="))], " ")
+		bound := ParseSemVer(strings.TrimSpace(c[len(op):]))
+		if bound.Raw == "" {
+			return false
+		}
+		newer, older := v.IsNewerThan(bound), bound.IsNewerThan(v)
+		var ok bool
+		switch op {
+		case "=", "":
+			ok = !newer && !older
+		case "<":
+			ok = older
+		case "<=":
+			ok = !newer
+		case ">":
+			ok = newer
+		case ">=":
+			ok = !older
+		default:
+			return false
+		}
+		if !ok {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// applyGitHubAdvisories adds vulns to the versions of info they affect,
+// leaving versions that already carry advisories alone. Reports whether any
+// version gained one.
+func applyGitHubAdvisories(info *PackageInfo, vulns []githubVulnerability) bool {
+	changed := false
+	for i := range info.Versions {
+		v := &info.Versions[i]
+		if len(v.Vulnerabilities) > 0 {
+			continue
+		}
+		for _, gv := range vulns {
+			if githubRangeContains(gv.Range, v.SemVer) {
+				v.Vulnerabilities = append(v.Vulnerabilities, PackageVulnerability{AdvisoryURL: gv.URL, Severity: IntOrString(gv.Severity)})
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// fetchGitHubAdvisories looks each of names up in the GitHub Advisory
+// Database, at most enrichConcurrency at a time, and calls found for every
+// package it has advisories for. found is never called concurrently. Does
+// nothing without a token.
+func fetchGitHubAdvisories(names []string, found func(name string, vulns []githubVulnerability)) {
+	if githubAdvisoryToken == "" || len(names) == 0 {
+		return
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, enrichConcurrency)
+	for _, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer func() { <-sem; wg.Done() }()
+			vulns, err := FetchGitHubVulnerabilities(name)
+			if err != nil {
+				logDebug("GitHub advisories for %s: %v", name, err)
+				return
+			}
+			if len(vulns) == 0 {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			found(name, vulns)
+		}(name)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubRangeContains(t *testing.T) {
+	tests := []struct {
+		rng, version string
+		want         bool
+	}{
+		{"< 1.2.3", "1.2.2", true},
+		{"< 1.2.3", "1.2.3", false},
+		{">= 2.0, < 2.1.4", "2.1.0", true},
+		{">= 2.0, < 2.1.4", "1.9.0", false},
+		{"<= 3.0.0", "3.0.0", true},
+		{"> 1.0.0", "1.0.0-beta", false},
+		{"= 4.0.0", "4.0", true},
+		{"= 4.0.0", "4.0.1", false},
+		{"", "1.0.0", false},
+		{"~> 1.0", "1.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := githubRangeContains(tt.rng, ParseSemVer(tt.version)); got != tt.want {
+			t.Errorf("githubRangeContains(%q, %s) = %v, want %v", tt.rng, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestFetchGitHubVulnerabilities(t *testing.T) {
+	var pages int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Variables struct {
+				Name  string  `json:"name"`
+				After *string `json:"after"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		pages++
+		if req.Variables.After == nil {
+			fmt.Fprintf(w, `{"data":{"securityVulnerabilities":{"nodes":[
+				{"package":{"name":%q},"vulnerableVersionRange":"< 1.2.0","advisory":{"permalink":"https://github.com/advisories/GHSA-aaaa-bbbb-cccc","severity":"HIGH","withdrawnAt":null}},
+				{"package":{"name":"Contoso.JsonExtra"},"vulnerableVersionRange":"< 9.0.0","advisory":{"permalink":"https://github.com/advisories/GHSA-other","severity":"LOW","withdrawnAt":null}}],
+				"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}`, "contoso.json")
+			return
+		}
+		fmt.Fprint(w, `{"data":{"securityVulnerabilities":{"nodes":[
+			{"package":{"name":"Contoso.Json"},"vulnerableVersionRange":"= 1.3.0","advisory":{"permalink":"https://github.com/advisories/GHSA-gone","severity":"CRITICAL","withdrawnAt":"2024-01-01T00:00:00Z"}}],
+			"pageInfo":{"hasNextPage":false,"endCursor":"c2"}}}}`)
+	}))
+	defer srv.Close()
+	defer func(url, token string) { githubGraphQLURL, githubAdvisoryToken = url, token }(githubGraphQLURL, githubAdvisoryToken)
+	githubGraphQLURL = srv.URL
+
+	githubAdvisoryToken = ""
+	if _, err := FetchGitHubVulnerabilities("Contoso.Json"); err == nil {
+		t.Error("expected an error without a token")
+	}
+
+	githubAdvisoryToken = "secret"
+	vulns, err := FetchGitHubVulnerabilities("Contoso.Json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != 2 || len(vulns) != 1 || vulns[0].Severity != 2 || vulns[0].URL != "https://github.com/advisories/GHSA-aaaa-bbbb-cccc" {
+		t.Fatalf("got %+v over %d pages, want the one live HIGH advisory for Contoso.Json", vulns, pages)
+	}
+
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("1.1.0")},
+		{SemVer: ParseSemVer("1.2.0")},
+		{SemVer: ParseSemVer("1.0.0"), Vulnerabilities: []PackageVulnerability{{AdvisoryURL: "nuget.org"}}},
+	}}
+	if !applyGitHubAdvisories(info, vulns) {
+		t.Error("applyGitHubAdvisories reported no change")
+	}
+	if v := info.Versions[0].Vulnerabilities; len(v) != 1 || v[0].SeverityLabel() != "high" {
+		t.Errorf("1.1.0 advisories = %+v", v)
+	}
+	if len(info.Versions[1].Vulnerabilities) != 0 || len(info.Versions[2].Vulnerabilities) != 1 {
+		t.Errorf("advisories added outside the range or over existing data: %+v", info.Versions)
+	}
+}
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Exit codes for the headless commands, so CI can tell "something is out of
+// date" apart from "guget could not check".
+const (
+	exitOK       = 0
+	exitError    = 1 // includes packages that could not be checked
+	exitOutdated = 2 // outdated: at least one package is outdated or vulnerable
+)
+
+// packageStatus is one package reference in one project together with what
+// the package sources report about it. Shared by list, outdated, and update.
+type packageStatus struct {
+	Project          string `json:"project"`
+	ProjectPath      string `json:"projectPath"`
+	Package          string `json:"package"`
+	Installed        string `json:"installed"`
+	Declared         string `json:"declared,omitempty"` // range or floating version, when not a plain version
+	LatestCompatible string `json:"latestCompatible,omitempty"`
+	LatestStable     string `json:"latestStable,omitempty"`
+	Source           string `json:"source,omitempty"`
+	Outdated         bool   `json:"outdated"`
+	Vulnerable       bool   `json:"vulnerable"`
+	Deprecated       bool   `json:"deprecated"`
+	Locked           bool   `json:"locked,omitempty"`
+	Pinned           bool   `json:"pinned,omitempty"` // by a rule in settings
+	Error            string `json:"error,omitempty"`
+
+	Advisories         []statusAdvisory `json:"advisories,omitempty"`
+	DeprecationMessage string           `json:"deprecationMessage,omitempty"`
+	AlternatePackage   string           `json:"alternatePackage,omitempty"`
+
+	file       string // where the version is defined; "" when it can't be written
+	targets    Set[TargetFramework]
+	info       *PackageInfo
+	readOnly   bool        // legacy project, unless listed in packages.config
+	rule       PackageRule // from settings; see applyPackageRules
+	prerelease bool        // latest versions include pre-releases; see includePrereleases
+}
+
+// statusAdvisory is one advisory against the installed version.
+type statusAdvisory struct {
+	Severity string `json:"severity"`
+	URL      string `json:"url"`
+}
+
+// fetchResults loads metadata for every package in the workspace and waits
+// for all of it, reusing the TUI's fetcher and its load deadline. With
+// enrich, packages from other feeds are then looked up on nuget.org; those
+// it doesn't know are cross-checked with the GitHub Advisory Database when
+// a token is set.
+func fetchResults(snap *workspaceSnapshot, deadline time.Duration, enrich bool) map[string]nugetResult {
+	names := distinctPackageNames(snap.ParsedProjects, snap.PropsProjects)
+	ready := make(chan packageReadyMsg, len(names))
+	fetchPackageMetadataAsync(func(msg tea.Msg) {
+		ready <- msg.(packageReadyMsg)
+	}, 0, snap.Scopes, names, deadline, false)
+
+	results := make(map[string]nugetResult, len(names))
+	for range names {
+		msg := <-ready
+		results[msg.name] = msg.result
+	}
+	missing := enrichmentCandidates(results)
+	if enrich {
+		missing = fetchEnrichment(snap.Scopes, missing, false, func(name string, nugetInfo *PackageInfo) {
+			enrichFromNugetOrg(results[name].pkg, nugetInfo)
+		})
+	}
+	fetchGitHubAdvisories(missing, func(name string, vulns []githubVulnerability) {
+		applyGitHubAdvisories(results[name].pkg, vulns)
+	})
+	return results
+}
+
+// buildPackageStatuses lists every package reference of every loadable
+// project, ordered by project then package.
+func buildPackageStatuses(projects []*ParsedProject, results map[string]nugetResult) []packageStatus {
+	var statuses []packageStatus
+	for _, p := range projects {
+		if p.LoadErr != nil {
+			continue
+		}
+		for ref := range p.Packages {
+			st := packageStatus{
+				Project:     p.FileName,
+				ProjectPath: p.FilePath,
+				Package:     ref.Name,
+				Installed:   ref.Version.String(),
+				Declared:    ref.Version.Range,
+				Locked:      ref.Locked,
+				file:        p.SourceFileForPackage(ref.Name),
+				targets:     p.TargetFrameworks,
+				readOnly:    !p.canUpdatePackage(ref.Name),
+			}
+			res := results[ref.Name]
+			st.Source = res.source
+			if res.err != nil {
+				st.Error = res.err.Error()
+			}
+			if info := res.pkg; info != nil {
+				st.info = info
+				installed := info.Resolve(ref.Version)
+				st.Installed = installed.String()
+				st.Deprecated = info.Deprecated
+				if info.Deprecated {
+					st.DeprecationMessage = info.DeprecationMessage
+					st.AlternatePackage = info.AlternatePackageID
+				}
+				if v := info.LatestStable(); v != nil {
+					st.LatestStable = v.SemVer.String()
+				}
+				if v := info.LatestStableForFramework(p.TargetFrameworks); v != nil {
+					st.LatestCompatible = v.SemVer.String()
+					st.Outdated = v.SemVer.IsNewerThan(installed)
+				}
+				for _, v := range info.Versions {
+					if v.SemVer.String() == st.Installed && len(v.Vulnerabilities) > 0 {
+						st.Vulnerable = true
+						for _, vuln := range v.Vulnerabilities {
+							st.Advisories = append(st.Advisories, statusAdvisory{Severity: vuln.SeverityLabel(), URL: vuln.AdvisoryURL})
+						}
+						break
+					}
+				}
+			}
+			statuses = append(statuses, st)
+		}
+	}
+	slices.SortFunc(statuses, func(a, b packageStatus) int {
+		return cmp.Or(
+			cmp.Compare(a.ProjectPath, b.ProjectPath),
+			cmp.Compare(strings.ToLower(a.Package), strings.ToLower(b.Package)),
+		)
+	})
+	return statuses
+}
+
+// includePrereleases recomputes the latest versions with pre-releases
+// counted, for --prerelease.
+func includePrereleases(statuses []packageStatus) {
+	for i := range statuses {
+		st := &statuses[i]
+		st.prerelease = true
+		if st.info == nil {
+			continue
+		}
+		if v := st.info.Latest(true); v != nil {
+			st.LatestStable = v.SemVer.String()
+		}
+		if v := st.info.LatestForFramework(st.targets, true); v != nil {
+			st.LatestCompatible = v.SemVer.String()
+			st.Outdated = v.SemVer.IsNewerThan(ParseSemVer(st.Installed))
+		}
+	}
+}
+
+// applyPackageRules applies the update rules from settings: a pinned package
+// is never outdated, and a major-version limit caps its latest compatible
+// version.
+func applyPackageRules(statuses []packageStatus, rules packageRules) {
+	for i := range statuses {
+		st := &statuses[i]
+		st.rule = rules.For(st.Package)
+		st.Pinned = st.rule.Pin
+		switch {
+		case st.Pinned:
+			st.Outdated = false
+		case st.rule.Major != nil && st.info != nil:
+			st.LatestCompatible, st.Outdated = "", false
+			if v := st.rule.latestAllowed(st.info, st.targets, st.prerelease); v != nil {
+				st.LatestCompatible = v.SemVer.String()
+				st.Outdated = v.SemVer.IsNewerThan(ParseSemVer(st.Installed))
+			}
+		}
+	}
+}
+
+func (st packageStatus) statusText() string {
+	var parts []string
+	if st.Error != "" {
+		parts = append(parts, "error: "+st.Error)
+	}
+	if st.Vulnerable {
+		parts = append(parts, "vulnerable")
+	}
+	if st.Outdated {
+		parts = append(parts, "outdated")
+	}
+	if st.Deprecated {
+		parts = append(parts, "deprecated")
+	}
+	if st.Locked {
+		parts = append(parts, "locked")
+	}
+	if st.Pinned {
+		parts = append(parts, "pinned")
+	}
+	if len(parts) == 0 {
+		return "ok"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writePackageStatuses prints statuses as a table, or as a JSON array.
+func writePackageStatuses(w io.Writer, statuses []packageStatus, asJSON bool) error {
+	if asJSON {
+		if statuses == nil {
+			statuses = []packageStatus{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROJECT\tPACKAGE\tINSTALLED\tLATEST\tSTATUS")
+	for _, st := range statuses {
+		latest := st.LatestCompatible
+		if latest == "" {
+			latest = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", st.Project, st.Package, st.Installed, latest, st.statusText())
+	}
+	return tw.Flush()
+}
+
+// plannedUpdate is one version bump in one file. Projects sharing a .props
+// file share one update, compatible with all of their target frameworks.
+type plannedUpdate struct {
+	File    string
+	Package string
+	From    string
+	To      string
+}
+
+// planUpdates picks the newest compatible version for each outdated,
+// writable package matching pkg ("" with all set means every package),
+// within its rule. Packages whose rule excludes them from bulk updates are
+// only updated when named.
+func planUpdates(statuses []packageStatus, pkg string, all bool) []plannedUpdate {
+	type key struct{ file, pkg string }
+	type group struct {
+		from       SemVer
+		info       *PackageInfo
+		targets    Set[TargetFramework]
+		rule       PackageRule
+		prerelease bool
+	}
+	groups := map[key]*group{}
+	var order []key
+	for _, st := range statuses {
+		if !st.Outdated || st.Locked || st.Pinned || st.readOnly || st.file == "" || st.info == nil {
+			continue
+		}
+		if !all && !strings.EqualFold(st.Package, pkg) {
+			continue
+		}
+		if all && st.rule.NoBulk {
+			continue
+		}
+		k := key{st.file, st.Package}
+		g := groups[k]
+		if g == nil {
+			g = &group{from: ParseSemVer(st.Installed), info: st.info, targets: NewSet[TargetFramework](), rule: st.rule, prerelease: st.prerelease}
+			groups[k] = g
+			order = append(order, k)
+		}
+		for tf := range st.targets {
+			g.targets.Add(tf)
+		}
+	}
+
+	var plan []plannedUpdate
+	for _, k := range order {
+		g := groups[k]
+		v := g.rule.latestAllowed(g.info, g.targets, g.prerelease)
+		if v == nil || !v.SemVer.IsNewerThan(g.from) {
+			continue
+		}
+		plan = append(plan, plannedUpdate{File: k.file, Package: k.pkg, From: g.from.String(), To: v.SemVer.String()})
+	}
+	return plan
+}
+
+// applyPlannedUpdates writes plan, recording each update in the action log
+// and reporting it to w with its file relative to root. Stops at the first
+// write that fails.
+func applyPlannedUpdates(root string, plan []plannedUpdate, w io.Writer) error {
+	for _, u := range plan {
+		rec := ActionRecord{Action: "update", Package: u.Package, Version: u.To, Files: []string{u.File}}
+		rewritten, err := updatePackageVersionFiles(u.File, u.Package, u.To)
+		rec.Files = append(rec.Files, rewritten...)
+		recordAction(rec, err)
+		if err != nil {
+			return fmt.Errorf("updating %s in %s: %w", u.Package, u.File, err)
+		}
+		rel, relErr := filepath.Rel(root, u.File)
+		if relErr != nil {
+			rel = u.File
+		}
+		fmt.Fprintf(w, "Updated %s %s → %s in %s\n", u.Package, u.From, u.To, rel)
+	}
+	return nil
+}
+
+// remainingAfterUpgrade drops the statuses plan brought up to date, leaving
+// what `outdated --upgrade` couldn't fix: locked, pinned, and read-only
+// references, and vulnerable ones without a newer version.
+func remainingAfterUpgrade(stale []packageStatus, plan []plannedUpdate) []packageStatus {
+	var left []packageStatus
+	for _, st := range stale {
+		upgraded := slices.ContainsFunc(plan, func(u plannedUpdate) bool {
+			return u.File == st.file && strings.EqualFold(u.Package, st.Package)
+		})
+		if !upgraded {
+			left = append(left, st)
+		}
+	}
+	return left
+}
+
+// runHeadlessCommand implements `guget list`, `guget outdated`, and
+// `guget update`. Returns the process exit code.
+func runHeadlessCommand(command string, flags BuiltFlags, settings UserConfig) int {
+	if command == "update" || command == "outdated" && flags.Upgrade {
+		if flags.ReadOnly {
+			logError("guget %s cannot run with --read-only", command)
+			return exitError
+		}
+	}
+	if command == "outdated" && flags.Format != "json" && flags.Format != formatDotnetOutdated {
+		logError("guget outdated supports --format %s, not %s", formatDotnetOutdated, flags.Format)
+		return exitError
+	}
+	if command == "update" {
+		if !flags.All && flags.Package == "" {
+			logError("guget update needs --all or --package <id>")
+			return exitError
+		}
+	}
+
+	snap, err := loadWorkspace(flags.ProjectDir, flags.Solution)
+	if err != nil {
+		logError("%v", err)
+		return exitError
+	}
+	results := fetchResults(snap, flags.Deadline, !flags.NoEnrich)
+	statuses := buildPackageStatuses(snap.ParsedProjects, results)
+	if flags.Prerelease {
+		includePrereleases(statuses)
+	}
+	applyPackageRules(statuses, settings.Packages)
+
+	failed := 0
+	for _, res := range results {
+		if res.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		logError("%d package(s) could not be checked", failed)
+	}
+
+	switch command {
+	case "list":
+		if err := writePackageStatuses(os.Stdout, statuses, flags.JSON); err != nil {
+			logError("%v", err)
+			return exitError
+		}
+
+	case "outdated":
+		var stale []packageStatus
+		for _, st := range statuses {
+			if st.Outdated || st.Vulnerable {
+				stale = append(stale, st)
+			}
+		}
+		switch {
+		case flags.Format == formatDotnetOutdated:
+			if err := saveDotnetOutdated(flags.Output, stale); err != nil {
+				logError("%v", err)
+				return exitError
+			}
+		case len(stale) == 0 && !flags.JSON:
+			if !flags.Quiet {
+				fmt.Println("All packages are up to date.")
+			}
+		default:
+			if err := writePackageStatuses(os.Stdout, stale, flags.JSON); err != nil {
+				logError("%v", err)
+				return exitError
+			}
+		}
+		if flags.Upgrade {
+			plan := planUpdates(statuses, "", true)
+			if settings.DisableBulkWrites && len(plan) > 1 {
+				logError("Bulk writes are disabled in settings (%d updates planned); use guget update --package", len(plan))
+				return exitError
+			}
+			// Keep a report on stdout parseable.
+			out := io.Writer(os.Stdout)
+			if flags.Format == formatDotnetOutdated && flags.Output == "" || flags.JSON {
+				out = os.Stderr
+			}
+			if err := applyPlannedUpdates(snap.ProjectDir, plan, out); err != nil {
+				logError("%v", err)
+				return exitError
+			}
+			stale = remainingAfterUpgrade(stale, plan)
+		}
+		if failed > 0 {
+			return exitError
+		}
+		if len(stale) > 0 {
+			return exitOutdated
+		}
+
+	case "update":
+		plan := planUpdates(statuses, flags.Package, flags.All)
+		if len(plan) == 0 {
+			if !flags.Quiet {
+				fmt.Println("Nothing to update.")
+			}
+			break
+		}
+		if settings.DisableBulkWrites && len(plan) > 1 {
+			logError("Bulk writes are disabled in settings (%d updates planned); use --package", len(plan))
+			return exitError
+		}
+		if err := applyPlannedUpdates(snap.ProjectDir, plan, os.Stdout); err != nil {
+			logError("%v", err)
+			return exitError
+		}
+	}
+
+	if failed > 0 {
+		return exitError
+	}
+	return exitOK
+}
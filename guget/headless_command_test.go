@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func headlessTestProject(name, file string, refs ...PackageReference) *ParsedProject {
+	p := newBrokenProject(name, nil)
+	p.FilePath = file
+	for _, ref := range refs {
+		p.Packages.Add(ref)
+		p.PackageSources[strings.ToLower(ref.Name)] = file
+	}
+	return p
+}
+
+func TestBuildPackageStatuses(t *testing.T) {
+	a := headlessTestProject("A.csproj", "/src/A/A.csproj",
+		PackageReference{Name: "Newer", Version: ParseSemVer("1.0.0")},
+		PackageReference{Name: "Current", Version: ParseSemVer("2.0.0")},
+		PackageReference{Name: "Broken", Version: ParseSemVer("1.0.0")},
+	)
+	results := map[string]nugetResult{
+		"Newer": {source: "nuget.org", pkg: &PackageInfo{Versions: []PackageVersion{
+			{SemVer: ParseSemVer("1.1.0")},
+			{SemVer: ParseSemVer("1.0.0"), Vulnerabilities: []PackageVulnerability{{Severity: 2}}},
+		}}},
+		"Current": {source: "nuget.org", pkg: &PackageInfo{Deprecated: true, Versions: []PackageVersion{{SemVer: ParseSemVer("2.0.0")}}}},
+		"Broken":  {err: errors.New("boom")},
+	}
+
+	statuses := buildPackageStatuses([]*ParsedProject{a}, results)
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 statuses, got %+v", statuses)
+	}
+	byName := map[string]packageStatus{}
+	for _, st := range statuses {
+		byName[st.Package] = st
+	}
+	if st := byName["Newer"]; !st.Outdated || !st.Vulnerable || st.LatestCompatible != "1.1.0" {
+		t.Errorf("Newer = %+v, want outdated and vulnerable with 1.1.0 available", st)
+	}
+	if st := byName["Current"]; st.Outdated || !st.Deprecated || st.statusText() != "deprecated" {
+		t.Errorf("Current = %+v (%s), want up to date but deprecated", st, st.statusText())
+	}
+	if st := byName["Broken"]; st.Error != "boom" || st.Outdated {
+		t.Errorf("Broken = %+v, want the load error", st)
+	}
+	if statuses[0].Package != "Broken" || statuses[2].Package != "Newer" {
+		t.Errorf("expected statuses sorted by package, got %s, %s, %s", statuses[0].Package, statuses[1].Package, statuses[2].Package)
+	}
+}
+
+func TestPlanUpdates(t *testing.T) {
+	info := &PackageInfo{Versions: []PackageVersion{{SemVer: ParseSemVer("3.0.0")}, {SemVer: ParseSemVer("1.0.0")}}}
+	props := "/src/Directory.Packages.props"
+	a := headlessTestProject("A.csproj", "/src/A/A.csproj", PackageReference{Name: "Shared", Version: ParseSemVer("1.0.0")})
+	b := headlessTestProject("B.csproj", "/src/B/B.csproj",
+		PackageReference{Name: "Shared", Version: ParseSemVer("1.0.0")},
+		PackageReference{Name: "Pinned", Version: ParseSemVer("1.0.0"), Locked: true},
+	)
+	a.PackageSources["shared"] = props
+	b.PackageSources["shared"] = props
+	results := map[string]nugetResult{"Shared": {pkg: info}, "Pinned": {pkg: info}}
+	statuses := buildPackageStatuses([]*ParsedProject{a, b}, results)
+
+	plan := planUpdates(statuses, "", true)
+	if len(plan) != 1 {
+		t.Fatalf("expected one update for the shared props file and none for the locked package, got %+v", plan)
+	}
+	if u := plan[0]; u.File != props || u.Package != "Shared" || u.From != "1.0.0" || u.To != "3.0.0" {
+		t.Errorf("unexpected update %+v", u)
+	}
+
+	if plan := planUpdates(statuses, "other", false); len(plan) != 0 {
+		t.Errorf("expected --package to filter, got %+v", plan)
+	}
+	if plan := planUpdates(statuses, "shared", false); len(plan) != 1 {
+		t.Errorf("expected --package to match case-insensitively, got %+v", plan)
+	}
+}
+
+func TestPlanUpdates_PackageRules(t *testing.T) {
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("9.0.0")}, {SemVer: ParseSemVer("8.2.0")}, {SemVer: ParseSemVer("8.0.0")},
+	}}
+	a := headlessTestProject("A.csproj", "/src/A/A.csproj",
+		PackageReference{Name: "Frozen", Version: ParseSemVer("8.0.0")},
+		PackageReference{Name: "EfCore", Version: ParseSemVer("8.0.0")},
+		PackageReference{Name: "Manual", Version: ParseSemVer("8.0.0")},
+	)
+	results := map[string]nugetResult{"Frozen": {pkg: info}, "EfCore": {pkg: info}, "Manual": {pkg: info}}
+	statuses := buildPackageStatuses([]*ParsedProject{a}, results)
+	eight := 8
+	applyPackageRules(statuses, packageRules{
+		"Frozen": {Pin: true},
+		"Ef*":    {Major: &eight},
+		"manual": {NoBulk: true},
+	})
+
+	for _, st := range statuses {
+		if st.Package == "Frozen" && (!st.Pinned || st.Outdated) {
+			t.Errorf("Frozen: pinned %v outdated %v, want pinned and not outdated", st.Pinned, st.Outdated)
+		}
+		if st.Package == "EfCore" && st.LatestCompatible != "8.2.0" {
+			t.Errorf("EfCore: latest compatible %s, want 8.2.0 within the major limit", st.LatestCompatible)
+		}
+	}
+
+	plan := planUpdates(statuses, "", true)
+	if len(plan) != 1 || plan[0].Package != "EfCore" || plan[0].To != "8.2.0" {
+		t.Fatalf("update --all: got %+v, want only EfCore → 8.2.0", plan)
+	}
+	if plan := planUpdates(statuses, "Manual", false); len(plan) != 1 || plan[0].To != "9.0.0" {
+		t.Errorf("update --package Manual: got %+v, want it updated when named", plan)
+	}
+	if plan := planUpdates(statuses, "Frozen", false); len(plan) != 0 {
+		t.Errorf("update --package Frozen: got %+v, want a pinned package left alone", plan)
+	}
+}
+
+func TestIncludePrereleases(t *testing.T) {
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("10.0.0-preview.3")}, {SemVer: ParseSemVer("9.0.1")}, {SemVer: ParseSemVer("9.0.0")},
+	}}
+	a := headlessTestProject("A.csproj", "/src/A/A.csproj",
+		PackageReference{Name: "Sdk", Version: ParseSemVer("9.0.1")},
+	)
+	statuses := buildPackageStatuses([]*ParsedProject{a}, map[string]nugetResult{"Sdk": {pkg: info}})
+	if statuses[0].Outdated {
+		t.Fatal("expected 9.0.1 to be current without pre-releases")
+	}
+
+	includePrereleases(statuses)
+	if st := statuses[0]; !st.Outdated || st.LatestCompatible != "10.0.0-preview.3" || st.LatestStable != "10.0.0-preview.3" {
+		t.Errorf("got outdated %v, latest %s / %s; want the preview", st.Outdated, st.LatestCompatible, st.LatestStable)
+	}
+	if plan := planUpdates(statuses, "Sdk", false); len(plan) != 1 || plan[0].To != "10.0.0-preview.3" {
+		t.Errorf("update --package Sdk: got %+v, want the preview", plan)
+	}
+}
+
+func TestWritePackageStatuses_EmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writePackageStatuses(&buf, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	var out []packageStatus
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil || out == nil {
+		t.Errorf("expected an empty JSON array, got %q (%v)", buf.String(), err)
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// httpCache keeps GET responses from package sources on disk so the next
+// launch can reuse them within the TTL, and afterwards revalidate with
+// ETag / Last-Modified instead of downloading whole registration indexes.
+type httpCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// responseCache is used by every NugetService created after it is set; nil
+// (--no-cache) sends every request to the source.
+var responseCache *httpCache
+
+// httpCacheMaxAge is how long an entry may go unused before it is pruned.
+const httpCacheMaxAge = 30 * 24 * time.Hour
+
+func defaultHTTPCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "guget", "http")
+}
+
+// setHTTPCache enables the on-disk cache, or disables it when dir is "".
+func setHTTPCache(dir string, ttl time.Duration) {
+	if dir == "" {
+		responseCache = nil
+		return
+	}
+	responseCache = &httpCache{dir: dir, ttl: ttl}
+	go responseCache.prune(httpCacheMaxAge)
+}
+
+// cacheEntry is the metadata stored next to a cached body.
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	Stored       time.Time `json:"stored"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	ContentType  string    `json:"contentType,omitempty"`
+}
+
+func (c *httpCache) paths(url string) (meta, body string) {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	base := filepath.Join(c.dir, key[:2], key)
+	return base + ".json", base + ".body"
+}
+
+func (c *httpCache) load(url string) (cacheEntry, bool) {
+	metaPath, bodyPath := c.paths(url)
+	var e cacheEntry
+	data, err := os.ReadFile(metaPath)
+	if err != nil || json.Unmarshal(data, &e) != nil || e.URL != url {
+		return e, false
+	}
+	if _, err := os.Stat(bodyPath); err != nil {
+		return e, false
+	}
+	return e, true
+}
+
+// open returns the stored body of e for reading.
+func (c *httpCache) open(e cacheEntry) (*os.File, int64, error) {
+	_, bodyPath := c.paths(e.URL)
+	f, err := os.Open(bodyPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, info.Size(), nil
+}
+
+// storeMeta writes the metadata of e, whose body is already in place.
+func (c *httpCache) storeMeta(e cacheEntry) {
+	metaPath, bodyPath := c.paths(e.URL)
+	meta, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := writeFileAtomic(metaPath, meta); err != nil {
+		logDebug("HTTP cache: %v", err)
+		return
+	}
+	// Keep prune from removing a body that was just revalidated.
+	now := time.Now()
+	os.Chtimes(bodyPath, now, now)
+}
+
+// tee wraps body so that everything the caller reads is also written to a
+// temporary file, which becomes the cached body of e once body reaches EOF.
+// The response is never held in memory, so large registration indexes still
+// stream into their decoder. If the body cannot be cached, body is returned
+// unchanged.
+func (c *httpCache) tee(e cacheEntry, body io.ReadCloser) io.ReadCloser {
+	metaPath, _ := c.paths(e.URL)
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0700); err != nil {
+		logDebug("HTTP cache: %v", err)
+		return body
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(metaPath), ".tmp-*")
+	if err != nil {
+		logDebug("HTTP cache: %v", err)
+		return body
+	}
+	return &cacheWriter{body: body, tmp: tmp, cache: c, entry: e}
+}
+
+// cacheWriter is the io.ReadCloser returned by httpCache.tee.
+type cacheWriter struct {
+	body  io.ReadCloser
+	tmp   *os.File // nil once the body is stored or abandoned
+	cache *httpCache
+	entry cacheEntry
+}
+
+func (w *cacheWriter) Read(p []byte) (int, error) {
+	n, err := w.body.Read(p)
+	if n > 0 && w.tmp != nil {
+		if _, werr := w.tmp.Write(p[:n]); werr != nil {
+			logDebug("HTTP cache: %v", werr)
+			w.abandon()
+		}
+	}
+	switch {
+	case err == io.EOF:
+		w.commit()
+	case err != nil:
+		w.abandon()
+	}
+	return n, err
+}
+
+// Close reads whatever the caller left unread, such as the whitespace after
+// a JSON document, so a complete response is still cached.
+func (w *cacheWriter) Close() error {
+	if w.tmp != nil {
+		io.Copy(io.Discard, w)
+	}
+	w.abandon()
+	return w.body.Close()
+}
+
+func (w *cacheWriter) commit() {
+	if w.tmp == nil {
+		return
+	}
+	tmp := w.tmp
+	w.tmp = nil
+	_, bodyPath := w.cache.paths(w.entry.URL)
+	// Body first, so a reader never finds metadata without its body.
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		logDebug("HTTP cache: %v", err)
+		return
+	}
+	if err := os.Rename(tmp.Name(), bodyPath); err != nil {
+		os.Remove(tmp.Name())
+		logDebug("HTTP cache: %v", err)
+		return
+	}
+	w.cache.storeMeta(w.entry)
+}
+
+func (w *cacheWriter) abandon() {
+	if w.tmp == nil {
+		return
+	}
+	w.tmp.Close()
+	os.Remove(w.tmp.Name())
+	w.tmp = nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// prune removes entries not refreshed within maxAge.
+func (c *httpCache) prune(maxAge time.Duration) {
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().Before(cutoff) {
+			if os.Remove(path) == nil {
+				removed++
+			}
+		}
+		return nil
+	})
+	if removed > 0 {
+		logDebug("HTTP cache: pruned %d stale file(s) from %s", removed, c.dir)
+	}
+}
+
+type cacheBypassKey struct{}
+
+// withCacheBypass marks requests made with ctx to skip the TTL and go to the
+// source, still revalidating so an unchanged response costs a 304.
+func withCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// cachingTransport answers GETs from the httpCache while they are fresh and
+// revalidates them once they are not.
+type cachingTransport struct {
+	base  http.RoundTripper
+	cache *httpCache
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+	url := req.URL.String()
+	entry, cached := t.cache.load(url)
+	bypass, _ := req.Context().Value(cacheBypassKey{}).(bool)
+
+	if cached && !bypass && time.Since(entry.Stored) < t.cache.ttl {
+		if resp, err := t.cache.respond(req, entry); err == nil {
+			logTrace("HTTP cache hit: %s", url)
+			return resp, nil
+		}
+		cached = false
+	}
+
+	if cached && (entry.ETag != "" || entry.LastModified != "") {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		resp.Body.Close()
+		entry.Stored = time.Now()
+		cachedResp, err := t.cache.respond(req, entry)
+		if err != nil {
+			// The body went away since load; fetch it again unconditionally.
+			req.Header.Del("If-None-Match")
+			req.Header.Del("If-Modified-Since")
+			return t.base.RoundTrip(req)
+		}
+		logTrace("HTTP cache revalidated: %s", url)
+		t.cache.storeMeta(entry)
+		return cachedResp, nil
+
+	case resp.StatusCode == http.StatusOK:
+		resp.Body = t.cache.tee(cacheEntry{
+			URL:          url,
+			Stored:       time.Now(),
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			ContentType:  resp.Header.Get("Content-Type"),
+		}, resp.Body)
+		return resp, nil
+	}
+	return resp, nil
+}
+
+// respond builds a response that reads the stored body of e from disk.
+func (c *httpCache) respond(req *http.Request, e cacheEntry) (*http.Response, error) {
+	body, size, err := c.open(e)
+	if err != nil {
+		return nil, err
+	}
+	h := make(http.Header)
+	if e.ContentType != "" {
+		h.Set("Content-Type", e.ContentType)
+	}
+	h.Set("Content-Length", strconv.FormatInt(size, 10))
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          body,
+		ContentLength: size,
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachingTransport(t *testing.T) {
+	var hits, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	defer srv.Close()
+
+	cache := &httpCache{dir: t.TempDir(), ttl: time.Hour}
+	client := &http.Client{Transport: &cachingTransport{base: http.DefaultTransport, cache: cache}}
+	get := func(ctx context.Context) string {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/registration/a/index.json", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if got := get(context.Background()); got != `{"ok":true}` {
+		t.Fatalf("first GET body = %q", got)
+	}
+	if got := get(context.Background()); got != `{"ok":true}` || hits.Load() != 1 {
+		t.Fatalf("fresh entry should be served from disk: body %q, %d request(s)", got, hits.Load())
+	}
+
+	// A bypass goes to the source, which answers 304 for the stored ETag.
+	if got := get(withCacheBypass(context.Background())); got != `{"ok":true}` || notModified.Load() != 1 {
+		t.Fatalf("bypass should revalidate: body %q, %d 304(s)", got, notModified.Load())
+	}
+
+	// Past the TTL the entry is revalidated rather than refetched.
+	cache.ttl = 0
+	if got := get(context.Background()); got != `{"ok":true}` || notModified.Load() != 2 || hits.Load() != 3 {
+		t.Fatalf("stale entry should revalidate: body %q, %d request(s), %d 304(s)", got, hits.Load(), notModified.Load())
+	}
+}
+
+func TestCachingTransport_SkipsErrors(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &cachingTransport{base: http.DefaultTransport, cache: &httpCache{dir: t.TempDir(), ttl: time.Hour}}}
+	for range 2 {
+		resp, err := client.Get(srv.URL + "/missing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("status = %d, want 404", resp.StatusCode)
+		}
+	}
+	if hits.Load() != 2 {
+		t.Errorf("errors must not be cached; got %d request(s), want 2", hits.Load())
+	}
+}
+
+func TestCachingTransport_StreamsBody(t *testing.T) {
+	release := make(chan struct{})
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, `{"items":[`)
+		w.(http.Flusher).Flush()
+		<-release
+		io.WriteString(w, `1]}`+"\n")
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := &http.Client{Transport: &cachingTransport{base: http.DefaultTransport, cache: &httpCache{dir: t.TempDir(), ttl: time.Hour}}}
+	resp, err := client.Get(srv.URL + "/registration/a/index.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The start of the body is readable while the server still holds back
+	// the rest, so the transport isn't buffering the response.
+	head := make([]byte, len(`{"items":[`))
+	if _, err := io.ReadFull(resp.Body, head); err != nil || string(head) != `{"items":[` {
+		t.Fatalf("head = %q, %v", head, err)
+	}
+	release <- struct{}{}
+	// Stop short of the trailing newline, as a JSON decoder does; Close
+	// reads the rest so the entry is still stored.
+	rest := make([]byte, len(`1]}`))
+	if _, err := io.ReadFull(resp.Body, rest); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	resp, err = client.Get(srv.URL + "/registration/a/index.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"items":[1]}`+"\n" || hits.Load() != 1 {
+		t.Fatalf("second GET should come from the cache: body %q, %d request(s)", body, hits.Load())
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// layoutState is the panel sizing and starred items remembered for one
+// project directory. Offsets are relative to each panel's base width, as
+// adjusted with [ / ].
+type layoutState struct {
+	ProjectsOffset int  `json:"projectsOffset"`
+	DetailOffset   int  `json:"detailOffset"`
+	ShowLicense    bool `json:"showLicense,omitempty"`
+	// StarredPackages holds lowercased package ids; StarredProjects holds
+	// project paths relative to the project directory, with forward slashes.
+	StarredPackages []string `json:"starredPackages,omitempty"`
+	StarredProjects []string `json:"starredProjects,omitempty"`
+}
+
+func (st layoutState) empty() bool {
+	return st.ProjectsOffset == 0 && st.DetailOffset == 0 && !st.ShowLicense &&
+		len(st.StarredPackages) == 0 && len(st.StarredProjects) == 0
+}
+
+// layoutStatePath returns the file holding saved layouts for every project
+// directory, or "" when no user config directory is available.
+func layoutStatePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "guget", "layout.json")
+}
+
+// readLayouts returns every saved layout in the file at path; none when the
+// file doesn't exist yet.
+func readLayouts(path string) (map[string]layoutState, error) {
+	layouts := make(map[string]layoutState)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return layouts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &layouts); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return layouts, nil
+}
+
+// loadLayout returns the saved layout for projectDir.
+func loadLayout(path, projectDir string) (layoutState, bool) {
+	if path == "" {
+		return layoutState{}, false
+	}
+	layouts, err := readLayouts(path)
+	if err != nil {
+		logWarn("Ignoring unreadable layout file: %v", err)
+		return layoutState{}, false
+	}
+	st, ok := layouts[projectDir]
+	return st, ok
+}
+
+// layoutWrites serialises writes to the layout file, and pendingLayouts
+// holds the newest layout not yet written per file and project directory. Saves are
+// started from key presses and may overlap or finish out of order; each one
+// writes whatever is newest when it runs, so an older layout never lands
+// last.
+var (
+	layoutWrites   sync.Mutex
+	pendingLayouts = make(map[string]layoutState)
+)
+
+// queueLayout records st as projectDir's newest layout and returns the save
+// to run, typically in a tea.Cmd.
+func queueLayout(path, projectDir string, st layoutState) func() error {
+	key := path + "\x00" + projectDir
+	layoutWrites.Lock()
+	pendingLayouts[key] = st
+	layoutWrites.Unlock()
+	return func() error {
+		layoutWrites.Lock()
+		defer layoutWrites.Unlock()
+		st, ok := pendingLayouts[key]
+		if !ok {
+			return nil // a later save already wrote it
+		}
+		delete(pendingLayouts, key)
+		return writeLayout(path, projectDir, st)
+	}
+}
+
+// saveLayout records st for projectDir, leaving other directories' layouts
+// untouched. An empty layout removes the entry.
+func saveLayout(path, projectDir string, st layoutState) error {
+	return queueLayout(path, projectDir, st)()
+}
+
+// writeLayout rewrites the layout file with st for projectDir. A file that
+// exists but can't be read is left alone rather than replaced with this one
+// layout. Callers hold layoutWrites.
+func writeLayout(path, projectDir string, st layoutState) error {
+	if path == "" {
+		return nil
+	}
+	layouts, err := readLayouts(path)
+	if err != nil {
+		return fmt.Errorf("not overwriting the layout file: %w", err)
+	}
+	if st.empty() {
+		delete(layouts, projectDir)
+	} else {
+		layouts[projectDir] = st
+	}
+	data, err := json.MarshalIndent(layouts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestLayoutState_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guget", "layout.json")
+
+	if _, ok := loadLayout(path, "/src/a"); ok {
+		t.Fatal("expected no layout before saving")
+	}
+	if err := saveLayout(path, "/src/a", layoutState{ProjectsOffset: 6, DetailOffset: -4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveLayout(path, "/src/b", layoutState{DetailOffset: 2, ShowLicense: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if st, ok := loadLayout(path, "/src/a"); !ok || !reflect.DeepEqual(st, layoutState{ProjectsOffset: 6, DetailOffset: -4}) {
+		t.Errorf("/src/a = %+v, %v", st, ok)
+	}
+
+	// Resetting one directory leaves the other alone.
+	if err := saveLayout(path, "/src/a", layoutState{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadLayout(path, "/src/a"); ok {
+		t.Error("expected /src/a to be removed after reset")
+	}
+	if st, ok := loadLayout(path, "/src/b"); !ok || st.DetailOffset != 2 || !st.ShowLicense {
+		t.Errorf("/src/b = %+v, %v", st, ok)
+	}
+}
+
+func TestLayoutState_Stars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guget", "layout.json")
+	want := layoutState{
+		StarredPackages: []string{"serilog"},
+		StarredProjects: []string{"src/App/App.csproj"},
+	}
+	if err := saveLayout(path, "/src/a", want); err != nil {
+		t.Fatal(err)
+	}
+	if st, ok := loadLayout(path, "/src/a"); !ok || !reflect.DeepEqual(st, want) {
+		t.Errorf("/src/a = %+v, %v", st, ok)
+	}
+
+	// Unstarring everything with default sizing removes the entry.
+	if err := saveLayout(path, "/src/a", layoutState{StarredPackages: []string{}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadLayout(path, "/src/a"); ok {
+		t.Error("expected /src/a to be removed once nothing is starred")
+	}
+}
+
+func TestLayoutState_OverlappingSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guget", "layout.json")
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := saveLayout(path, fmt.Sprintf("/src/%d", i), layoutState{ProjectsOffset: i + 1}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	for i := range 20 {
+		if st, ok := loadLayout(path, fmt.Sprintf("/src/%d", i)); !ok || st.ProjectsOffset != i+1 {
+			t.Errorf("/src/%d = %+v, %v; want every save kept", i, st, ok)
+		}
+	}
+
+	// Saves finishing out of order still leave the newest layout.
+	older := queueLayout(path, "/src/a", layoutState{DetailOffset: 2})
+	newer := queueLayout(path, "/src/a", layoutState{DetailOffset: 4})
+	if err := newer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := older(); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := loadLayout(path, "/src/a"); st.DetailOffset != 4 {
+		t.Errorf("/src/a = %+v, want the newest layout", st)
+	}
+}
+
+func TestLayoutState_UnreadableFileIsKept(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "layout.json")
+	broken := `{"/src/a": {"projectsOffset": 6`
+	os.WriteFile(path, []byte(broken), 0644)
+
+	if err := saveLayout(path, "/src/b", layoutState{DetailOffset: 2}); err == nil {
+		t.Error("expected the save to be refused")
+	}
+	if data, _ := os.ReadFile(path); string(data) != broken {
+		t.Errorf("layout file = %q, want it left as it was", data)
+	}
+}
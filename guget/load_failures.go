@@ -0,0 +1,127 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// failureKind buckets package load errors by their likely cause so the
+// summary can suggest one fix per bucket instead of one per package.
+type failureKind int
+
+const (
+	failureOther failureKind = iota
+	failureAuth
+	failureNotFound
+	failureTimeout
+	failureNetwork
+)
+
+func (k failureKind) String() string {
+	switch k {
+	case failureAuth:
+		return "authentication"
+	case failureNotFound:
+		return "not found"
+	case failureTimeout:
+		return "timeout"
+	case failureNetwork:
+		return "network"
+	}
+	return "other"
+}
+
+// hint is a one-line suggestion shown under each group in the summary.
+func (k failureKind) hint() string {
+	switch k {
+	case failureAuth:
+		return "Check the credentials for this source in nuget.config or your credential provider."
+	case failureNotFound:
+		return "The source has no such package; check the ID and packageSourceMapping."
+	case failureTimeout:
+		return "The source was too slow; retry, or raise --timeout / --load-deadline."
+	case failureNetwork:
+		return "The source could not be reached; check the URL, VPN, or proxy."
+	}
+	return "See the log panel for details."
+}
+
+func classifyLoadError(err error) failureKind {
+	var he *httpStatusError
+	var ne net.Error
+	switch {
+	case err == nil:
+		return failureOther
+	case errors.As(err, &he) && (he.Code == http.StatusUnauthorized || he.Code == http.StatusForbidden):
+		return failureAuth
+	case errors.As(err, &he) && he.Code == http.StatusNotFound, errors.Is(err, errPackageNotFound):
+		return failureNotFound
+	case isLoadTimeout(err), errors.Is(err, context.DeadlineExceeded), errors.As(err, &ne) && ne.Timeout():
+		return failureTimeout
+	case errors.As(err, &ne):
+		return failureNetwork
+	}
+	return failureOther
+}
+
+// failureGroup is every package that failed the same way on the same source.
+type failureGroup struct {
+	Source   string
+	Kind     failureKind
+	Packages []string
+}
+
+// groupLoadFailures collects errored results into groups ordered by size,
+// largest first, then by source and kind.
+func groupLoadFailures(results map[string]nugetResult) []failureGroup {
+	type key struct {
+		source string
+		kind   failureKind
+	}
+	byKey := map[key]*failureGroup{}
+	for name, res := range results {
+		if res.err == nil {
+			continue
+		}
+		source := res.source
+		if source == "" {
+			source = "unknown source"
+		}
+		k := key{source, classifyLoadError(res.err)}
+		g := byKey[k]
+		if g == nil {
+			g = &failureGroup{Source: source, Kind: k.kind}
+			byKey[k] = g
+		}
+		g.Packages = append(g.Packages, name)
+	}
+
+	groups := make([]failureGroup, 0, len(byKey))
+	for _, g := range byKey {
+		slices.SortFunc(g.Packages, func(a, b string) int {
+			return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+		})
+		groups = append(groups, *g)
+	}
+	slices.SortFunc(groups, func(a, b failureGroup) int {
+		return cmp.Or(
+			cmp.Compare(len(b.Packages), len(a.Packages)),
+			cmp.Compare(a.Source, b.Source),
+			cmp.Compare(a.Kind, b.Kind),
+		)
+	})
+	return groups
+}
+
+func serviceNames(services []*NugetService) string {
+	names := make([]string, len(services))
+	for i, svc := range services {
+		names[i] = svc.SourceName()
+	}
+	return strings.Join(names, ", ")
+}
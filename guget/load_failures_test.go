@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassifyLoadError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want failureKind
+	}{
+		{"unauthorized", &httpStatusError{Code: http.StatusUnauthorized}, failureAuth},
+		{"forbidden wrapped", fmt.Errorf("fetching page: %w", &httpStatusError{Code: http.StatusForbidden}), failureAuth},
+		{"missing package", fmt.Errorf("package %q %w", "Foo", errPackageNotFound), failureNotFound},
+		{"load deadline", &loadTimeoutError{after: time.Minute}, failureTimeout},
+		{"dial timeout", &net.OpError{Op: "dial", Err: timeoutErr{}}, failureTimeout},
+		{"dns", &net.DNSError{Err: "no such host", Name: "feed.local"}, failureNetwork},
+		{"server error", &httpStatusError{Code: http.StatusInternalServerError}, failureOther},
+		{"plain", errors.New("boom"), failureOther},
+	}
+	for _, tt := range tests {
+		if got := classifyLoadError(tt.err); got != tt.want {
+			t.Errorf("%s: classifyLoadError() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+func TestGroupLoadFailures(t *testing.T) {
+	results := map[string]nugetResult{
+		"Ok":    {source: "nuget.org", pkg: &PackageInfo{ID: "Ok"}},
+		"B":     {source: "corp", err: &httpStatusError{Code: http.StatusUnauthorized}},
+		"a":     {source: "corp", err: &httpStatusError{Code: http.StatusUnauthorized}},
+		"Slow":  {source: "nuget.org", err: &loadTimeoutError{after: time.Minute}},
+		"Ghost": {err: errors.New("boom")},
+	}
+
+	groups := groupLoadFailures(results)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %+v", groups)
+	}
+	first := groups[0]
+	if first.Source != "corp" || first.Kind != failureAuth {
+		t.Fatalf("expected the largest group first, got %+v", first)
+	}
+	if len(first.Packages) != 2 || first.Packages[0] != "a" || first.Packages[1] != "B" {
+		t.Fatalf("expected packages sorted case-insensitively, got %v", first.Packages)
+	}
+	if groups[1].Source != "nuget.org" || groups[1].Kind != failureTimeout {
+		t.Fatalf("unexpected second group %+v", groups[1])
+	}
+	if groups[2].Source != "unknown source" || groups[2].Kind != failureOther {
+		t.Fatalf("unexpected third group %+v", groups[2])
+	}
+}
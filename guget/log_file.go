@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// maxLogLines caps how many lines the in-memory log keeps (both the startup
+// logBuffer and the TUI log panel). Older lines are dropped first.
+const maxLogLines = 5000
+
+// logTrimChunk is how many lines beyond the overflow appendLogLine drops at
+// once, so a burst of trace lines doesn't copy the whole log for each one.
+const logTrimChunk = maxLogLines / 10
+
+// appendLogLine appends line and, once there are more than maxLogLines,
+// drops the oldest logTrimChunk lines and any others beyond the cap.
+func appendLogLine(lines []string, line string) []string {
+	lines = append(lines, line)
+	if over := len(lines) - maxLogLines; over > 0 {
+		// Copy down instead of re-slicing so the backing array doesn't keep
+		// growing for the whole session.
+		n := copy(lines, lines[over+logTrimChunk:])
+		clear(lines[n:])
+		lines = lines[:n]
+	}
+	return lines
+}
+
+// rotatingFile is an io.Writer for --log-file that rolls the file over once it
+// exceeds maxBytes, keeping up to keep older files as path.1 … path.N
+// (path.1 being the most recent). maxBytes <= 0 disables rotation.
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	keep     int
+	f        *os.File
+	size     int64
+}
+
+// openRotatingFile creates (truncating) the log file at path.
+func openRotatingFile(path string, maxBytes int64, keep int) (*rotatingFile, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &rotatingFile{path: path, maxBytes: maxBytes, keep: max(keep, 0), f: f}, nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			// Keep logging to the current file rather than losing output.
+			fmt.Fprintf(os.Stderr, "Failed to rotate log file %q: %v\n", r.path, err)
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts path.N-1 → path.N … path → path.1 and reopens path empty.
+// With keep == 0 the current file is simply truncated.
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	if r.keep > 0 {
+		os.Remove(rotatedLogPath(r.path, r.keep))
+		for i := r.keep - 1; i >= 1; i-- {
+			os.Rename(rotatedLogPath(r.path, i), rotatedLogPath(r.path, i+1))
+		}
+		if err := os.Rename(r.path, rotatedLogPath(r.path, 1)); err != nil {
+			// The file couldn't be moved aside (e.g. locked on Windows):
+			// append to it rather than losing what it holds.
+			return r.reopen(os.O_APPEND, err)
+		}
+	}
+	return r.reopen(os.O_TRUNC, nil)
+}
+
+// reopen opens the log file again after a rotation attempt, truncated or
+// appended to as flag says, returning cause (or the open error) so Write
+// can report it. The size starts over either way, so a file that can't be
+// rotated is retried after another maxBytes rather than on every write.
+func (r *rotatingFile) reopen(flag int, cause error) error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|flag, 0666)
+	if err != nil {
+		r.f = nil
+		return err
+	}
+	r.f = f
+	r.size = 0
+	return cause
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func rotatedLogPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendLogLine_CapsLength(t *testing.T) {
+	var lines []string
+	for i := range maxLogLines + 10 {
+		lines = appendLogLine(lines, fmt.Sprint(i))
+	}
+	// The first line over the cap drops a whole chunk, so later ones don't
+	// copy the log again.
+	if want := maxLogLines - logTrimChunk + 9; len(lines) != want {
+		t.Fatalf("len = %d, want %d", len(lines), want)
+	}
+	if lines[0] != fmt.Sprint(logTrimChunk+1) || lines[len(lines)-1] != fmt.Sprint(maxLogLines+9) {
+		t.Errorf("kept %q … %q, want the newest lines", lines[0], lines[len(lines)-1])
+	}
+	for i := range 3 * maxLogLines {
+		lines = appendLogLine(lines, fmt.Sprint(i))
+		if len(lines) > maxLogLines {
+			t.Fatalf("len = %d after %d more lines, want at most %d", len(lines), i+1, maxLogLines)
+		}
+	}
+}
+
+func TestRotatingFile_RotatesAndKeepsN(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guget.log")
+	w, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		path:                    "dddddddd\n",
+		rotatedLogPath(path, 1): "cccccccc\n",
+		rotatedLogPath(path, 2): "bbbbbbbb\n",
+	}
+	for p, content := range want {
+		got, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("reading %s: %v", p, err)
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), got, content)
+		}
+	}
+	if _, err := os.Stat(rotatedLogPath(path, 3)); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 rotated files, found %s", rotatedLogPath(path, 3))
+	}
+}
+
+func TestRotatingFile_FailedRenameKeepsLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guget.log")
+	// A non-empty directory where path.1 should go makes the rename fail.
+	blocker := rotatedLogPath(path, 1)
+	if err := os.MkdirAll(filepath.Join(blocker, "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := openRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rotation errors go to stderr; keep the test output clean.
+	stderr := os.Stderr
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() { os.Stderr = stderr }()
+	for _, s := range []string{"aaaaaaaa\n", "bbbbbbbb\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+
+	if got, _ := os.ReadFile(path); string(got) != "aaaaaaaa\nbbbbbbbb\n" {
+		t.Errorf("log = %q, want both lines kept after a failed rotation", got)
+	}
+}
+
+func TestRotatingFile_ZeroMaxDisablesRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guget.log")
+	w, err := openRotatingFile(path, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		w.Write([]byte("0123456789\n"))
+	}
+	w.Close()
+	if _, err := os.Stat(rotatedLogPath(path, 1)); !os.IsNotExist(err) {
+		t.Error("expected no rotation when maxBytes is 0")
+	}
+}
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/colorprofile"
 )
 
 var version = "dev"
@@ -25,7 +30,7 @@ func (b *logBuffer) Write(p []byte) (int, error) {
 		return len(p), nil
 	}
 	b.mu.Lock()
-	b.lines = append(b.lines, line)
+	b.lines = appendLogLine(b.lines, line)
 	send := b.send
 	b.mu.Unlock()
 	if send != nil {
@@ -48,34 +53,132 @@ func (b *logBuffer) Lines() []string {
 }
 
 const (
-	Flag_NoColor    = "no-color"
-	Flag_Verbosity  = "verbosity"
-	Flag_ProjectDir = "project"
-	Flag_Version    = "version"
-	Flag_LogFile    = "log-file"
-	Flag_Theme      = "theme"
-	Flag_SortBy     = "sort-by"
+	Flag_NoColor     = "no-color"
+	Flag_Verbosity   = "verbosity"
+	Flag_Quiet       = "quiet"
+	Flag_ProjectDir  = "project"
+	Flag_Version     = "version"
+	Flag_LogFile     = "log-file"
+	Flag_LogMaxSize  = "log-max-size"
+	Flag_LogKeep     = "log-keep"
+	Flag_ActionLog   = "action-log"
+	Flag_ReadOnly    = "read-only"
+	Flag_Timeout     = "timeout"
+	Flag_Deadline    = "load-deadline"
+	Flag_NoCache     = "no-cache"
+	Flag_NoEnrich    = "no-enrich"
+	Flag_NoNugetOrg  = "no-nuget-org"
+	Flag_Prerelease  = "prerelease"
+	Flag_CacheTTL    = "cache-ttl"
+	Flag_Theme       = "theme"
+	Flag_SortBy      = "sort-by"
+	Flag_Output      = "output"
+	Flag_From        = "from"
+	Flag_To          = "to"
+	Flag_Template    = "template"
+	Flag_Profile     = "profile"
+	Flag_JSON        = "json"
+	Flag_All         = "all"
+	Flag_Upgrade     = "upgrade"
+	Flag_Package     = "package"
+	Flag_Format      = "format"
+	Flag_Source      = "source"
+	Flag_APIKey      = "api-key"
+	Flag_CredTimeout = "credential-timeout"
+	Flag_RestoreJobs = "restore-jobs"
+	Flag_Concurrency = "concurrency"
+	Flag_Include     = "include"
+	Flag_Interval    = "interval"
+	Flag_Webhook     = "webhook"
+	Flag_WebhookFmt  = "webhook-format"
+	Flag_MetricsAddr = "metrics-addr"
 )
 
 type BuiltFlags struct {
-	NoColor    bool
-	Verbosity  string
-	ProjectDir string
-	Version    bool
-	LogFile    string
-	Theme      string
-	SortBy     string
+	NoColor       bool
+	Verbosity     string
+	Quiet         bool
+	ProjectDir    string
+	Solution      string // set from --project when it names a .sln, .slnx or workspace file
+	Version       bool
+	LogFile       string
+	LogMaxSize    int
+	LogKeep       int
+	ActionLog     string
+	ReadOnly      bool
+	Timeout       time.Duration
+	Deadline      time.Duration
+	NoCache       bool
+	NoEnrich      bool
+	NoNugetOrg    bool
+	Prerelease    bool
+	CacheTTL      time.Duration
+	Theme         string
+	SortBy        string
+	Output        string
+	From          string
+	To            string
+	Template      string
+	Profile       string
+	JSON          bool
+	All           bool
+	Upgrade       bool
+	Package       string
+	Format        string
+	PackageFile   string // push: the .nupkg operand, taken by popSubcommand
+	Source        string
+	APIKey        string
+	CredTimeout   time.Duration
+	RestoreJobs   int
+	Concurrency   int
+	Include       string
+	Interval      time.Duration
+	Webhook       string
+	WebhookFormat string
+	MetricsAddr   string
 }
 
 func BuildFlags(flags map[string]IParsedFlag) BuiltFlags {
 	return BuiltFlags{
-		NoColor:    GetFlag[bool](flags, Flag_NoColor),
-		Verbosity:  GetFlag[string](flags, Flag_Verbosity),
-		ProjectDir: GetFlag[string](flags, Flag_ProjectDir),
-		Version:    GetFlag[bool](flags, Flag_Version),
-		LogFile:    GetFlag[string](flags, Flag_LogFile),
-		Theme:      GetFlag[string](flags, Flag_Theme),
-		SortBy:     GetFlag[string](flags, Flag_SortBy),
+		NoColor:       GetFlag[bool](flags, Flag_NoColor),
+		Verbosity:     GetFlag[string](flags, Flag_Verbosity),
+		Quiet:         GetFlag[bool](flags, Flag_Quiet),
+		ProjectDir:    GetFlag[string](flags, Flag_ProjectDir),
+		Version:       GetFlag[bool](flags, Flag_Version),
+		LogFile:       GetFlag[string](flags, Flag_LogFile),
+		LogMaxSize:    GetFlag[int](flags, Flag_LogMaxSize),
+		LogKeep:       GetFlag[int](flags, Flag_LogKeep),
+		ActionLog:     GetFlag[string](flags, Flag_ActionLog),
+		ReadOnly:      GetFlag[bool](flags, Flag_ReadOnly),
+		Timeout:       GetFlag[time.Duration](flags, Flag_Timeout),
+		Deadline:      GetFlag[time.Duration](flags, Flag_Deadline),
+		NoCache:       GetFlag[bool](flags, Flag_NoCache),
+		NoEnrich:      GetFlag[bool](flags, Flag_NoEnrich),
+		NoNugetOrg:    GetFlag[bool](flags, Flag_NoNugetOrg),
+		Prerelease:    GetFlag[bool](flags, Flag_Prerelease),
+		CacheTTL:      GetFlag[time.Duration](flags, Flag_CacheTTL),
+		CredTimeout:   GetFlag[time.Duration](flags, Flag_CredTimeout),
+		RestoreJobs:   GetFlag[int](flags, Flag_RestoreJobs),
+		Concurrency:   GetFlag[int](flags, Flag_Concurrency),
+		Include:       GetFlag[string](flags, Flag_Include),
+		Theme:         GetFlag[string](flags, Flag_Theme),
+		SortBy:        GetFlag[string](flags, Flag_SortBy),
+		Output:        GetFlag[string](flags, Flag_Output),
+		From:          GetFlag[string](flags, Flag_From),
+		To:            GetFlag[string](flags, Flag_To),
+		Template:      GetFlag[string](flags, Flag_Template),
+		Profile:       GetFlag[string](flags, Flag_Profile),
+		JSON:          GetFlag[bool](flags, Flag_JSON),
+		All:           GetFlag[bool](flags, Flag_All),
+		Upgrade:       GetFlag[bool](flags, Flag_Upgrade),
+		Package:       GetFlag[string](flags, Flag_Package),
+		Format:        GetFlag[string](flags, Flag_Format),
+		Source:        GetFlag[string](flags, Flag_Source),
+		APIKey:        GetFlag[string](flags, Flag_APIKey),
+		Interval:      GetFlag[time.Duration](flags, Flag_Interval),
+		Webhook:       GetFlag[string](flags, Flag_Webhook),
+		WebhookFormat: GetFlag[string](flags, Flag_WebhookFmt),
+		MetricsAddr:   GetFlag[string](flags, Flag_MetricsAddr),
 	}
 }
 
@@ -97,7 +200,13 @@ func registerCLIFlags() {
 		Aliases:        []string{"-v", "--verbose"},
 		Default:        Optional("warn"),
 		Description:    "Set the logging verbosity level",
-		ExpectedValues: []string{"", "none", "error", "err", "warn", "warning", "info", "debug", "dbg", "trace", "trc"},
+		ExpectedValues: validVerbosities,
+	})
+	RegisterFlag(Flag[bool]{
+		Name:        Flag_Quiet,
+		Aliases:     []string{"-q", "--quiet"},
+		Default:     Optional(false),
+		Description: "Headless commands: print only the final result or errors",
 	})
 	RegisterFlag(Flag[string]{
 		Name:    Flag_ProjectDir,
@@ -109,7 +218,7 @@ func registerCLIFlags() {
 			}
 			return dir
 		},
-		Description: "Set the target project directory (defaults to current working directory)",
+		Description: "Set the target project directory, a .sln/.slnx file to load only its projects, or a workspace file listing several repositories (defaults to current working directory)",
 	})
 	RegisterFlag(Flag[string]{
 		Name:        Flag_LogFile,
@@ -117,6 +226,100 @@ func registerCLIFlags() {
 		Default:     Optional(""),
 		Description: "Write all log output to this file (in addition to the TUI log panel)",
 	})
+	RegisterFlag(Flag[int]{
+		Name:        Flag_LogMaxSize,
+		Aliases:     []string{"--log-max-size"},
+		Default:     Optional(10),
+		Description: "Rotate the log file once it exceeds this many MB (0 = never rotate)",
+	})
+	RegisterFlag(Flag[int]{
+		Name:        Flag_LogKeep,
+		Aliases:     []string{"--log-keep"},
+		Default:     Optional(3),
+		Description: "Number of rotated log files to keep alongside the log file",
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_ActionLog,
+		Aliases:     []string{"--action-log"},
+		Default:     Optional(""),
+		Description: "Append a JSON line to this file for every write or restore made in the TUI",
+	})
+	RegisterFlag(Flag[bool]{
+		Name:        Flag_ReadOnly,
+		Aliases:     []string{"-ro", "--read-only"},
+		Default:     Optional(false),
+		Description: "Browse only: disable update, add, remove, restore, and cache clearing",
+	})
+	RegisterFlag(Flag[time.Duration]{
+		Name:        Flag_Timeout,
+		Aliases:     []string{"--timeout"},
+		Default:     Optional(15 * time.Second),
+		Description: "Timeout for each request to a package source (e.g. 30s)",
+		Parser:      time.ParseDuration,
+	})
+	RegisterFlag(Flag[time.Duration]{
+		Name:        Flag_Deadline,
+		Aliases:     []string{"--load-deadline"},
+		Default:     Optional(2 * time.Minute),
+		Description: "Give up on packages still loading after this long; they show as timed out and can be retried (0 = no deadline)",
+		Parser:      time.ParseDuration,
+	})
+	RegisterFlag(Flag[bool]{
+		Name:        Flag_NoCache,
+		Aliases:     []string{"--no-cache"},
+		Default:     Optional(false),
+		Description: "Don't read or write the on-disk cache of package source responses",
+	})
+	RegisterFlag(Flag[bool]{
+		Name:        Flag_NoEnrich,
+		Aliases:     []string{"--no-enrich"},
+		Default:     Optional(false),
+		Description: "Don't look up packages from other feeds on nuget.org for vulnerabilities and links",
+	})
+	RegisterFlag(Flag[bool]{
+		Name:        Flag_Prerelease,
+		Aliases:     []string{"-pre", "--prerelease"},
+		Default:     Optional(false),
+		Description: "Include pre-release versions in search results, latest versions, and update suggestions",
+	})
+	RegisterFlag(Flag[bool]{
+		Name:        Flag_NoNugetOrg,
+		Aliases:     []string{"--no-nuget-org"},
+		Default:     Optional(false),
+		Description: "Never contact nuget.org: no fallback source, no enrichment, and nuget.org sources in configs are skipped",
+	})
+	RegisterFlag(Flag[time.Duration]{
+		Name:        Flag_CacheTTL,
+		Aliases:     []string{"--cache-ttl"},
+		Default:     Optional(time.Hour),
+		Description: "Reuse cached package source responses this long before revalidating them (0 = always revalidate)",
+		Parser:      time.ParseDuration,
+	})
+	RegisterFlag(Flag[time.Duration]{
+		Name:        Flag_CredTimeout,
+		Aliases:     []string{"--credential-timeout"},
+		Default:     Optional(time.Duration(0)),
+		Description: "Timeout for each credential provider call (default 10s, or NUGET_PLUGIN_REQUEST_TIMEOUT_IN_SECONDS)",
+		Parser:      time.ParseDuration,
+	})
+	RegisterFlag(Flag[int]{
+		Name:        Flag_RestoreJobs,
+		Aliases:     []string{"--restore-jobs"},
+		Default:     Optional(0),
+		Description: "Projects restored at once when a restore runs project by project (default one per CPU, up to 4)",
+	})
+	RegisterFlag(Flag[int]{
+		Name:        Flag_Concurrency,
+		Aliases:     []string{"--concurrency"},
+		Default:     Optional(0),
+		Description: "Packages looked up at once while loading (default 16); lower it if a feed rate-limits you",
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_Include,
+		Aliases:     []string{"--include"},
+		Default:     Optional(""),
+		Description: "Also load files matching these comma-separated name globs as projects, e.g. \"*.msbuildproj,*.sqlproj\"",
+	})
 	RegisterFlag(Flag[string]{
 		Name:           Flag_Theme,
 		Aliases:        []string{"-t", "--theme"},
@@ -144,11 +347,157 @@ func registerCLIFlags() {
 			}
 		},
 	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_Output,
+		Aliases:     []string{"-out", "--output"},
+		Default:     Optional(""),
+		Description: "snapshot: file to write (defaults to a timestamped file in .guget/snapshots); config export: file to write (defaults to stdout); config import: settings file to replace (defaults to .guget/config.json); config set/unset: settings file to change (defaults to the user settings); report: file to write (defaults to stdout); outdated: file for the --format dotnet-outdated report (defaults to stdout); daemon: file to rewrite on every run (defaults to a timestamped file in .guget/reports per run)",
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_From,
+		Aliases:     []string{"--from"},
+		Default:     Optional(""),
+		Description: "diff-snapshot: snapshot to compare from (defaults to the newest in .guget/snapshots); config import: settings file to import",
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_To,
+		Aliases:     []string{"--to"},
+		Default:     Optional(""),
+		Description: "diff-snapshot: snapshot to compare to (defaults to the current workspace)",
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_Template,
+		Aliases:     []string{"-tpl", "--template"},
+		Default:     Optional(""),
+		Description: "new: dotnet new template to create in the project directory",
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_Profile,
+		Aliases:     []string{"--profile"},
+		Default:     Optional(""),
+		Description: "new: dependency profile to add (one \"Id [Version]\" per line; no version = latest stable)",
+	})
+	RegisterFlag(Flag[bool]{
+		Name:        Flag_JSON,
+		Aliases:     []string{"--json"},
+		Default:     Optional(false),
+		Description: "list, outdated: print JSON instead of a table",
+	})
+	RegisterFlag(Flag[bool]{
+		Name:        Flag_All,
+		Aliases:     []string{"--all"},
+		Default:     Optional(false),
+		Description: "update: update every outdated package to its latest compatible version",
+	})
+	RegisterFlag(Flag[bool]{
+		Name:        Flag_Upgrade,
+		Aliases:     []string{"--upgrade"},
+		Default:     Optional(false),
+		Description: "outdated: after reporting, update the outdated packages to their latest compatible versions, like dotnet-outdated --upgrade",
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_Package,
+		Aliases:     []string{"-pkg", "--package"},
+		Default:     Optional(""),
+		Description: "update: package to update to its latest compatible version",
+	})
+	RegisterFlag(Flag[string]{
+		Name:           Flag_Format,
+		Aliases:        []string{"-fmt", "--format"},
+		Default:        Optional("json"),
+		Description:    "report, daemon: output format; outdated: dotnet-outdated prints dotnet-outdated's JSON report",
+		ExpectedValues: append(slices.Clone(validReportFormats), formatDotnetOutdated),
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_Source,
+		Aliases:     []string{"-s", "--source"},
+		Default:     Optional(""),
+		Description: "push: source name or URL to publish to (defaults to defaultPushSource in nuget.config); creds test: the source to test",
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_APIKey,
+		Aliases:     []string{"-k", "--api-key"},
+		Default:     Optional(""),
+		Description: "push: API key for the source (defaults to the key saved in nuget.config)",
+	})
+	RegisterFlag(Flag[time.Duration]{
+		Name:        Flag_Interval,
+		Aliases:     []string{"--interval"},
+		Default:     Optional(24 * time.Hour),
+		Description: "daemon: how long to wait between runs",
+		Parser:      time.ParseDuration,
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_Webhook,
+		Aliases:     []string{"--webhook"},
+		Default:     Optional(""),
+		Description: "daemon, report: URL to POST the findings to (defaults to the webhook setting)",
+	})
+	RegisterFlag(Flag[string]{
+		Name:           Flag_WebhookFmt,
+		Aliases:        []string{"--webhook-format"},
+		Default:        Optional("json"),
+		Description:    "daemon, report: webhook body; slack and teams post a chat message of new advisories and major versions, only when there are some",
+		ExpectedValues: webhookFormats,
+	})
+	RegisterFlag(Flag[string]{
+		Name:        Flag_MetricsAddr,
+		Aliases:     []string{"--metrics-addr"},
+		Default:     Optional(""),
+		Description: "daemon: serve Prometheus metrics at /metrics on this address, e.g. :9464",
+	})
+}
+
+// validVerbosities are the log levels --verbose and the verbosity setting
+// accept.
+var validVerbosities = []string{"", "none", "error", "err", "warn", "warning", "info", "debug", "dbg", "trace", "trc"}
+
+// subcommands are the non-interactive commands accepted as the first argument.
+var subcommands = []string{"snapshot", "diff-snapshot", "new", "config", "list", "outdated", "update", "report", "daemon", "push", "creds"}
+
+// subcommandActions are accepted after a subcommand; popSubcommand returns
+// them as e.g. "config export".
+var subcommandActions = map[string][]string{
+	"config": {"export", "import", "set", "unset"},
+	"creds":  {"test"},
 }
 
-// initCLI registers CLI flags, parses os.Args, and returns the resolved flag values.
-// Named initCLI (not Init) to avoid confusion with App.Init() in the same package.
-func initCLI() BuiltFlags {
+// subcommandOperands is how many operands a command takes before its flags:
+// push the package file, creds test the source, and config set the setting
+// and its value.
+var subcommandOperands = map[string]int{"push": 1, "creds test": 1, "config set": 2, "config unset": 1}
+
+// popSubcommand removes a leading subcommand from os.Args so the remaining
+// flags parse as usual. Returns "" when guget should start the TUI. The
+// command's operands are removed and returned too.
+func popSubcommand() (string, []string) {
+	if len(os.Args) < 2 {
+		return "", nil
+	}
+	for _, cmd := range subcommands {
+		if os.Args[1] == cmd {
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+			if len(os.Args) > 1 && slices.Contains(subcommandActions[cmd], os.Args[1]) {
+				cmd += " " + os.Args[1]
+				os.Args = append(os.Args[:1], os.Args[2:]...)
+			}
+			var operands []string
+			// Only the first operand can't look like a flag, so a value such as
+			// "-8" can follow a setting name.
+			for len(operands) < subcommandOperands[cmd] && len(os.Args) > 1 && (len(operands) > 0 || !strings.HasPrefix(os.Args[1], "-")) {
+				operands = append(operands, os.Args[1])
+				os.Args = append(os.Args[:1], os.Args[2:]...)
+			}
+			return cmd, operands
+		}
+	}
+	return "", nil
+}
+
+// initCLI registers CLI flags, parses os.Args, and returns the resolved flag
+// values and the names of those given explicitly. Named initCLI (not Init)
+// to avoid confusion with App.Init() in the same package.
+func initCLI() (BuiltFlags, Set[string]) {
 	rebuildStyles()
 	logSetLevel(LogLevelWarn)
 	// Allow LOG_LEVEL env var to override the pre-parse default; --verbose will
@@ -161,20 +510,80 @@ func initCLI() BuiltFlags {
 	parsedFlags, _ := ParseFlags()
 	builtFlags := BuildFlags(parsedFlags)
 
-	logSetLevel(logParseLevel(builtFlags.Verbosity))
+	applyVerbosity(builtFlags)
 	logSetColor(!builtFlags.NoColor)
 
-	return builtFlags
+	return builtFlags, GivenFlags(parsedFlags)
+}
+
+// applyVerbosity sets the log level from --verbose. --quiet caps the logger
+// at errors regardless so headless commands emit nothing but their result.
+func applyVerbosity(flags BuiltFlags) {
+	logSetLevel(logParseLevel(flags.Verbosity))
+	if flags.Quiet && logLevel > LogLevelError {
+		logSetLevel(LogLevelError)
+	}
+}
+
+// applySettingDefaults fills in the flags not given on the command line
+// from settings.
+func applySettingDefaults(flags BuiltFlags, given Set[string], settings UserConfig) BuiltFlags {
+	if !given.Contains(Flag_Theme) && settings.Theme != "" {
+		flags.Theme = settings.Theme
+	}
+	if !given.Contains(Flag_SortBy) && settings.SortBy != "" {
+		flags.SortBy = settings.SortBy
+	}
+	if !given.Contains(Flag_Verbosity) && settings.Verbosity != "" {
+		flags.Verbosity = settings.Verbosity
+	}
+	if !given.Contains(Flag_Prerelease) && settings.Prerelease {
+		flags.Prerelease = true
+	}
+	if !given.Contains(Flag_Webhook) {
+		flags.Webhook = settings.Webhook
+	}
+	if !given.Contains(Flag_WebhookFmt) && settings.WebhookFormat != "" {
+		flags.WebhookFormat = settings.WebhookFormat
+	}
+	return flags
 }
 
 type nugetResult struct {
-	pkg    *PackageInfo
-	source string
-	err    error
+	pkg      *PackageInfo
+	source   string
+	err      error
+	enriched bool // nuget.org has been asked for pkg's vulnerabilities and links
 }
 
 func main() {
-	builtFlags := initCLI()
+	command, operands := popSubcommand()
+	builtFlags, given := initCLI()
+	switch {
+	case command == "creds test" && len(operands) > 0:
+		builtFlags.Source = operands[0]
+	case command == "push" && len(operands) > 0:
+		builtFlags.PackageFile = operands[0]
+	}
+	applyTerminalCaps(detectTerminalCaps(os.Getenv, runtime.GOOS, enableVirtualTerminal))
+	builtFlags.ProjectDir, builtFlags.Solution = splitSolutionArg(builtFlags.ProjectDir)
+	settings := loadSettings(builtFlags.ProjectDir)
+	builtFlags = applySettingDefaults(builtFlags, given, settings)
+	applyVerbosity(builtFlags)
+	if settings.DisableNugetOrg {
+		builtFlags.NoNugetOrg = true
+	}
+	if builtFlags.NoNugetOrg {
+		builtFlags.NoEnrich = true
+		setNugetOrgDisabled(true)
+	}
+	if settings.GitHubAdvisories {
+		token := cmp.Or(os.Getenv("GITHUB_TOKEN"), os.Getenv("GH_TOKEN"))
+		if token == "" {
+			logWarn("githubAdvisories is on but neither GITHUB_TOKEN nor GH_TOKEN is set; skipping the GitHub advisory cross-check")
+		}
+		setGitHubAdvisoryToken(token)
+	}
 	initTheme(builtFlags.Theme, builtFlags.NoColor)
 
 	if builtFlags.Version {
@@ -182,10 +591,69 @@ func main() {
 		os.Exit(0)
 	}
 
+	if builtFlags.ActionLog != "" {
+		l, err := openActionLog(builtFlags.ActionLog)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open action log %q: %v\n", builtFlags.ActionLog, err)
+			os.Exit(1)
+		}
+		defer l.Close()
+		actionLog = l
+	}
+
+	setRequestTimeout(builtFlags.Timeout)
+	if builtFlags.CredTimeout == 0 {
+		builtFlags.CredTimeout, _ = settings.credentialTimeout()
+	}
+	setCredentialTimeout(builtFlags.CredTimeout)
+	if builtFlags.RestoreJobs <= 0 {
+		builtFlags.RestoreJobs = settings.RestoreParallelism
+	}
+	setRestoreJobs(builtFlags.RestoreJobs)
+	if builtFlags.Concurrency <= 0 {
+		builtFlags.Concurrency = settings.FetchConcurrency
+	}
+	setFetchConcurrency(builtFlags.Concurrency)
+	includes := splitProjectIncludes(builtFlags.Include)
+	if len(includes) == 0 {
+		includes = settings.Include
+	}
+	if err := setProjectIncludes(includes); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --include: %v\n", err)
+		os.Exit(1)
+	}
+	setPinnedProviders(settings.CredentialProviders)
+	setSearchPrerelease(builtFlags.Prerelease)
+	if !builtFlags.NoCache {
+		setHTTPCache(defaultHTTPCacheDir(), builtFlags.CacheTTL)
+	}
+
+	if command == "snapshot" || command == "diff-snapshot" {
+		os.Exit(runSnapshotCommand(command, builtFlags))
+	}
+	if strings.HasPrefix(command, "config") {
+		os.Exit(runConfigCommand(command, operands, builtFlags, settings))
+	}
+	if command == "list" || command == "outdated" || command == "update" {
+		os.Exit(runHeadlessCommand(command, builtFlags, settings))
+	}
+	if command == "report" {
+		os.Exit(runReportCommand(builtFlags, settings))
+	}
+	if command == "daemon" {
+		os.Exit(runDaemonCommand(builtFlags, settings))
+	}
+	if command == "push" {
+		os.Exit(runPushCommand(builtFlags))
+	}
+	if strings.HasPrefix(command, "creds") {
+		os.Exit(runCredsCommand(command, builtFlags))
+	}
+
 	// Capture all startup logs for the TUI log panel.
 	buf := &logBuffer{}
 	if builtFlags.LogFile != "" {
-		f, err := os.Create(builtFlags.LogFile)
+		f, err := openRotatingFile(builtFlags.LogFile, int64(builtFlags.LogMaxSize)<<20, builtFlags.LogKeep)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to open log file %q: %v\n", builtFlags.LogFile, err)
 			os.Exit(1)
@@ -196,20 +664,33 @@ func main() {
 		logSetOutput(buf)
 	}
 
+	if command == "new" {
+		if builtFlags.ReadOnly {
+			logFatal("guget new cannot run with --read-only")
+		}
+		if err := runNewCommand(builtFlags); err != nil {
+			logFatal("%v", err)
+		}
+	}
+
 	fullProjectPath, err := filepath.Abs(builtFlags.ProjectDir)
 	if err != nil {
 		logFatal("Couldn't get absolute path for project directory: %v", err)
 	}
 	logInfo("Starting guget with project directory: %s", fullProjectPath)
 
-	snapshot, err := loadWorkspace(fullProjectPath)
+	snapshot, err := loadWorkspace(fullProjectPath, builtFlags.Solution)
 	if err != nil {
 		logFatal("Error loading workspace: %v", err)
 	}
 
-	m := NewApp(fullProjectPath, snapshot, buf.Lines(), builtFlags)
+	m := NewApp(fullProjectPath, snapshot, buf.Lines(), builtFlags, settings)
 
-	p := tea.NewProgram(m)
+	var opts []tea.ProgramOption
+	if termCaps.Colors != colorprofile.Unknown {
+		opts = append(opts, tea.WithColorProfile(termCaps.Colors))
+	}
+	p := tea.NewProgram(m, opts...)
 
 	// Wire up live log forwarding to the TUI now that the program exists.
 	buf.mu.Lock()
@@ -217,7 +698,7 @@ func main() {
 	buf.mu.Unlock()
 	m.SetSender(p.Send)
 	m.startInitialLoad()
-	stopWatcher := watchWorkspaceFiles(fullProjectPath, p.Send)
+	stopWatcher := watchWorkspaceFiles(snapshot.Roots, p.Send)
 	defer stopWatcher()
 
 	if _, err := p.Run(); err != nil {
@@ -229,6 +710,8 @@ func main() {
 // enrichFromNugetOrg merges vulnerability and metadata from nuget.org into
 // a PackageInfo fetched from a private feed.
 func enrichFromNugetOrg(info, nugetInfo *PackageInfo) {
+	info.NugetOrgURL = "https://www.nuget.org/packages/" + nugetInfo.ID
+
 	// Build a version→vulnerabilities map from nuget.org data.
 	nugetVulns := make(map[string][]PackageVulnerability, len(nugetInfo.Versions))
 	for _, v := range nugetInfo.Versions {
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// renderMarkdown renders the common subset of GitHub-flavoured markdown that
+// package READMEs and release notes use — headings, paragraphs, lists, block
+// quotes, code fences, rules and tables — as styled text wrapped to width.
+// Inline HTML is dropped and images are replaced by their alt text.
+func renderMarkdown(src string, width int) string {
+	if width < 10 {
+		width = 10
+	}
+	lines := strings.Split(strings.ReplaceAll(stripHTMLComments(src), "\r\n", "\n"), "\n")
+	var out []string
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			out = append(out, wrapSpans(parseInline(strings.Join(para, " "), styleText), width, "", "")...)
+			out = append(out, "")
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence := trimmed[:3]
+			lang := strings.TrimSpace(trimmed[3:])
+			bar := styleBorder.Render(glyphs.CodeBar)
+			if lang != "" {
+				out = append(out, bar+styleMuted.Render(lang))
+			}
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code := strings.ReplaceAll(lines[i], "\t", "    ")
+				out = append(out, bar+styleCyan.Render(truncateStyled(code, width-2)))
+			}
+			out = append(out, "")
+
+		case markdownHeading.MatchString(trimmed):
+			flush()
+			m := markdownHeading.FindStringSubmatch(trimmed)
+			text := plainInline(strings.TrimRight(m[2], " #"))
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			switch len(m[1]) {
+			case 1:
+				out = append(out, styleAccentBold.Render(truncateStyled(strings.ToUpper(text), width)))
+				out = append(out, styleBorder.Render(strings.Repeat(glyphs.RuleHeavy, min(width, lipgloss.Width(text)))))
+			case 2:
+				out = append(out, styleAccentBold.Render(truncateStyled(text, width)))
+				out = append(out, styleBorder.Render(strings.Repeat(glyphs.Rule, min(width, lipgloss.Width(text)))))
+			default:
+				out = append(out, styleTextBold.Render(truncateStyled(text, width)))
+			}
+			out = append(out, "")
+
+		case markdownRule.MatchString(trimmed) && len(para) == 0:
+			out = append(out, styleBorder.Render(strings.Repeat(glyphs.Rule, width)), "")
+
+		case len(para) > 0 && indent < 4 && markdownSetext.MatchString(trimmed):
+			// "Title\n=====" and "Title\n-----" headings.
+			text := plainInline(strings.Join(para, " "))
+			para = nil
+			out = append(out, styleAccentBold.Render(truncateStyled(text, width)))
+			out = append(out, styleBorder.Render(strings.Repeat(map[byte]string{'=': glyphs.RuleHeavy, '-': glyphs.Rule}[trimmed[0]], min(width, lipgloss.Width(text)))), "")
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")))
+			}
+			i--
+			bar := styleBorder.Render(glyphs.QuoteBar)
+			out = append(out, wrapSpans(parseInline(strings.Join(quote, " "), styleSubtle), width, bar, bar)...)
+			out = append(out, "")
+
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && markdownTableSep.MatchString(strings.TrimSpace(lines[i+1])):
+			flush()
+			var rows [][]string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				if !markdownTableSep.MatchString(strings.TrimSpace(lines[i])) {
+					rows = append(rows, tableCells(lines[i]))
+				}
+			}
+			i--
+			out = append(out, renderMarkdownTable(rows, width)...)
+			out = append(out, "")
+
+		case markdownBullet.MatchString(line) || markdownOrdered.MatchString(line):
+			flush()
+			marker, text := "", ""
+			if m := markdownBullet.FindStringSubmatch(line); m != nil {
+				marker, text = glyphs.Bullet, m[2]
+				if task := markdownTask.FindStringSubmatch(text); task != nil {
+					marker, text = map[bool]string{true: glyphs.TaskDone, false: glyphs.TaskOpen}[task[1] != " "], task[2]
+				}
+			} else {
+				m := markdownOrdered.FindStringSubmatch(line)
+				marker, text = m[2]+".", m[3]
+			}
+			// Continuation lines of the item join it.
+			for i+1 < len(lines) {
+				next := lines[i+1]
+				if strings.TrimSpace(next) == "" || markdownBullet.MatchString(next) || markdownOrdered.MatchString(next) ||
+					markdownHeading.MatchString(strings.TrimSpace(next)) || strings.HasPrefix(strings.TrimSpace(next), "```") {
+					break
+				}
+				text += " " + strings.TrimSpace(next)
+				i++
+			}
+			pad := strings.Repeat("  ", min(indent/2, 4))
+			first := pad + styleAccent.Render(marker) + " "
+			rest := pad + strings.Repeat(" ", lipgloss.Width(marker)+1)
+			out = append(out, wrapSpans(parseInline(text, styleText), width, first, rest)...)
+			if i+1 >= len(lines) || strings.TrimSpace(lines[i+1]) == "" {
+				out = append(out, "")
+			}
+
+		default:
+			if plain := strings.TrimSpace(markdownHTMLTag.ReplaceAllString(trimmed, "")); plain == "" {
+				// A line of nothing but HTML (badges, <p align>, <br>).
+				continue
+			}
+			para = append(para, trimmed)
+		}
+	}
+	flush()
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n")
+}
+
+var (
+	markdownHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownSetext   = regexp.MustCompile(`^(=+|-+)$`)
+	markdownRule     = regexp.MustCompile(`^(\*\s*){3,}$|^(-\s*){3,}$|^(_\s*){3,}$`)
+	markdownBullet   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	markdownOrdered  = regexp.MustCompile(`^(\s*)(\d{1,9})[.)]\s+(.*)$`)
+	markdownTask     = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	markdownTableSep = regexp.MustCompile(`^\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?$`)
+	markdownHTMLTag  = regexp.MustCompile(`<[^>]+>`)
+	markdownComment  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	markdownBadge    = regexp.MustCompile(`\[(!\[[^\]]*\]\([^)]*\))\]\([^)]*\)`)
+	markdownInline   = regexp.MustCompile("!\\[([^\\]]*)\\]\\([^)]*\\)|\\[([^\\]]+)\\]\\(([^)\\s]+)[^)]*\\)|`([^`]+)`|\\*\\*([^*]+)\\*\\*|__([^_]+)__|\\*([^*\\s][^*]*)\\*|<(https?://[^>]+)>")
+)
+
+func stripHTMLComments(s string) string {
+	return markdownComment.ReplaceAllString(s, "")
+}
+
+// mdSpan is a run of inline text drawn in one style, optionally linked.
+type mdSpan struct {
+	text  string
+	style lipgloss.Style
+	url   string
+}
+
+// parseInline splits markdown inline text into styled spans: links, code,
+// bold and italic. Inline HTML tags are dropped.
+func parseInline(s string, base lipgloss.Style) []mdSpan {
+	s = markdownHTMLTag.ReplaceAllStringFunc(s, func(tag string) string {
+		if strings.HasPrefix(tag, "<http://") || strings.HasPrefix(tag, "<https://") {
+			return tag // an autolink, not HTML
+		}
+		return ""
+	})
+	s = markdownBadge.ReplaceAllString(s, "$1") // a linked image shows as the image
+	var spans []mdSpan
+	last := 0
+	for _, m := range markdownInline.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] > last {
+			spans = append(spans, mdSpan{text: s[last:m[0]], style: base})
+		}
+		group := func(n int) string {
+			if m[2*n] < 0 {
+				return ""
+			}
+			return s[m[2*n]:m[2*n+1]]
+		}
+		switch {
+		case m[2] >= 0: // image
+			if alt := group(1); alt != "" {
+				spans = append(spans, mdSpan{text: "[" + alt + "]", style: styleMuted})
+			}
+		case m[4] >= 0: // link
+			spans = append(spans, mdSpan{text: group(2), style: styleAccent, url: group(3)})
+		case m[8] >= 0: // code
+			spans = append(spans, mdSpan{text: group(4), style: styleCyan})
+		case m[10] >= 0:
+			spans = append(spans, mdSpan{text: group(5), style: base.Bold(true)})
+		case m[12] >= 0:
+			spans = append(spans, mdSpan{text: group(6), style: base.Bold(true)})
+		case m[14] >= 0:
+			spans = append(spans, mdSpan{text: group(7), style: base.Italic(true)})
+		case m[16] >= 0: // autolink
+			spans = append(spans, mdSpan{text: group(8), style: styleAccent, url: group(8)})
+		}
+		last = m[1]
+	}
+	if last < len(s) {
+		spans = append(spans, mdSpan{text: s[last:], style: base})
+	}
+	return spans
+}
+
+// plainInline returns the text of inline markdown without its markup.
+func plainInline(s string) string {
+	var b strings.Builder
+	for _, sp := range parseInline(s, lipgloss.NewStyle()) {
+		b.WriteString(sp.text)
+	}
+	return b.String()
+}
+
+// wrapSpans word-wraps spans to width. The first line starts with first and
+// the rest with rest; both count towards the width.
+func wrapSpans(spans []mdSpan, width int, first, rest string) []string {
+	var lines []string
+	prefix := first
+	var cur strings.Builder
+	curW := 0
+	avail := func() int { return max(1, width-lipgloss.Width(prefix)) }
+	newLine := func() {
+		lines = append(lines, prefix+cur.String())
+		cur.Reset()
+		curW = 0
+		prefix = rest
+	}
+	pendingSpace := false
+	for _, sp := range spans {
+		text := sp.text
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			pendingSpace = curW > 0
+		}
+		words := strings.Fields(text)
+		for i, word := range words {
+			if i > 0 {
+				pendingSpace = curW > 0
+			}
+			word = truncateStyled(word, avail())
+			rendered := sp.style.Render(word)
+			w := lipgloss.Width(word)
+			if sp.url != "" && termCaps.Hyperlinks {
+				// Each word links on its own so a wrapped link still works; the
+				// link glyph follows only its last word.
+				rendered = "\x1b]8;;" + sp.url + "\x1b\\" + rendered + "\x1b]8;;\x1b\\"
+				if i == len(words)-1 {
+					rendered += glyphs.Link
+					w += lipgloss.Width(glyphs.Link)
+				}
+			}
+			space := 0
+			if pendingSpace {
+				space = 1
+			}
+			if curW > 0 && curW+space+w > avail() {
+				newLine()
+				space = 0
+			}
+			if space > 0 {
+				cur.WriteString(" ")
+				curW++
+			}
+			cur.WriteString(rendered)
+			curW += w
+			pendingSpace = false
+		}
+		if strings.HasSuffix(text, " ") || strings.HasSuffix(text, "\t") {
+			pendingSpace = curW > 0
+		}
+	}
+	if curW > 0 || len(lines) == 0 {
+		newLine()
+	}
+	return lines
+}
+
+// tableCells splits a "| a | b |" row into its trimmed, plain-text cells.
+func tableCells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	cells := strings.Split(row, "|")
+	for i, c := range cells {
+		cells[i] = plainInline(strings.TrimSpace(c))
+	}
+	return cells
+}
+
+// renderMarkdownTable lays rows out in columns sized to their content,
+// narrowing the widest ones until the table fits width. The first row is the
+// header.
+func renderMarkdownTable(rows [][]string, width int) []string {
+	cols := 0
+	for _, r := range rows {
+		cols = max(cols, len(r))
+	}
+	if cols == 0 {
+		return nil
+	}
+	widths := make([]int, cols)
+	for _, r := range rows {
+		for c, cell := range r {
+			widths[c] = max(widths[c], lipgloss.Width(cell))
+		}
+	}
+	total := func() int {
+		t := (cols - 1) * 3
+		for _, w := range widths {
+			t += w
+		}
+		return t
+	}
+	for total() > width {
+		widest := 0
+		for c := range widths {
+			if widths[c] > widths[widest] {
+				widest = c
+			}
+		}
+		if widths[widest] <= 3 {
+			break
+		}
+		widths[widest]--
+	}
+
+	var out []string
+	for ri, r := range rows {
+		cells := make([]string, cols)
+		for c := range cols {
+			cell := ""
+			if c < len(r) {
+				cell = truncateStyled(r[c], widths[c])
+			}
+			cell = fmt.Sprintf("%-*s", widths[c]+len(cell)-lipgloss.Width(cell), cell)
+			if ri == 0 {
+				cells[c] = styleTextBold.Render(cell)
+			} else {
+				cells[c] = styleText.Render(cell)
+			}
+		}
+		out = append(out, strings.Join(cells, styleBorder.Render(" "+glyphs.ColumnSep+" ")))
+		if ri == 0 {
+			seps := make([]string, cols)
+			for c, w := range widths {
+				seps[c] = strings.Repeat(glyphs.Rule, w)
+			}
+			out = append(out, styleBorder.Render(strings.Join(seps, glyphs.Rule+glyphs.Cross+glyphs.Rule)))
+		}
+	}
+	return out
+}
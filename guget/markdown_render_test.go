@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+var testANSI = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func plainMarkdown(src string, width int) string {
+	defer func(caps TerminalCaps) { termCaps = caps }(termCaps)
+	termCaps.Hyperlinks = false
+	return testANSI.ReplaceAllString(renderMarkdown(src, width), "")
+}
+
+func TestRenderMarkdown_Blocks(t *testing.T) {
+	src := strings.Join([]string{
+		"<!-- hidden -->",
+		`<p align="center"><img src="logo.png"></p>`,
+		"# Widgets",
+		"",
+		"A **fast** widget library, see [the docs](https://example.com/docs).",
+		"",
+		"## Install",
+		"```bash",
+		"dotnet add package Widgets",
+		"```",
+		"",
+		"- first",
+		"- [x] done",
+		"1. one",
+		"",
+		"> quoted",
+		"",
+		"| Name | Value |",
+		"|------|-------|",
+		"| a    | `b`   |",
+	}, "\n")
+	got := plainMarkdown(src, 60)
+	for _, want := range []string{
+		"WIDGETS\n═══════",
+		"A fast widget library, see the docs.",
+		"Install\n───────",
+		"│ bash\n│ dotnet add package Widgets",
+		"• first\n☑ done\n1. one",
+		"▌ quoted",
+		"Name │ Value",
+		"a    │ b",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered markdown missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"hidden", "<p", "**", "](", "```"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("rendered markdown contains %q:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestRenderMarkdown_ASCIIGlyphs(t *testing.T) {
+	defer func(g glyphSet) { glyphs = g }(glyphs)
+	glyphs = asciiGlyphs
+	src := "# Widgets\n\n- first\n- [x] done\n\n> quoted\n\n| Name | Value |\n|---|---|\n| a | b |\n"
+	got := plainMarkdown(src, 60)
+	for _, want := range []string{"WIDGETS\n=======", "* first\n[x] done", "> quoted", "Name | Value\n-----+------"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered markdown missing %q:\n%s", want, got)
+		}
+	}
+	for _, r := range got {
+		if r > 0x7f {
+			t.Fatalf("rendered markdown contains non-ASCII %q:\n%s", r, got)
+		}
+	}
+}
+
+func TestRenderMarkdown_WrapsToWidth(t *testing.T) {
+	src := "- " + strings.Repeat("word ", 30) + "\n\n" + strings.Repeat("[![build](b.svg)](ci) text ", 10)
+	for _, line := range strings.Split(renderMarkdown(src, 30), "\n") {
+		if w := lipgloss.Width(line); w > 30 {
+			t.Errorf("line is %d cells wide, want at most 30: %q", w, line)
+		}
+	}
+	if got := plainMarkdown(src, 30); !strings.Contains(got, "  word") || !strings.Contains(got, "[build] text") {
+		t.Errorf("list continuation not indented or badge not replaced:\n%s", got)
+	}
+}
+
+func TestFetchReadme(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			fmt.Fprintf(w, `{"resources":[{"@id":%q,"@type":"RegistrationsBaseUrl/3.6.0"},{"@id":%q,"@type":"PackageBaseAddress/3.0.0"}]}`, srv.URL+"/reg/", srv.URL+"/flat/")
+		case "/flat/widgets/2.0.0-beta/readme":
+			fmt.Fprint(w, "# Widgets")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := NewNugetService(NugetSource{Name: "feed", URL: srv.URL + "/index.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := svc.FetchReadme("Widgets", "2.0.0-Beta"); err != nil || body != "# Widgets" {
+		t.Errorf("FetchReadme = %q, %v", body, err)
+	}
+	if body, err := svc.FetchReadme("Gadgets", "1.0.0"); err != nil || body != "" {
+		t.Errorf("FetchReadme without a README = %q, %v; want empty and no error", body, err)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GitHubAdvisory is the subset of a GitHub Security Advisory shown in the
+// advisory details overlay.
+type GitHubAdvisory struct {
+	GHSAID          string                  `json:"ghsa_id"`
+	CVEID           string                  `json:"cve_id"`
+	HTMLURL         string                  `json:"html_url"`
+	Summary         string                  `json:"summary"`
+	Severity        string                  `json:"severity"`
+	PublishedAt     string                  `json:"published_at"`
+	CVSS            advisoryCVSS            `json:"cvss"`
+	CVSSSeverities  map[string]advisoryCVSS `json:"cvss_severities"`
+	Vulnerabilities []advisoryVulnerability `json:"vulnerabilities"`
+}
+
+type advisoryCVSS struct {
+	Score        float64 `json:"score"`
+	VectorString string  `json:"vector_string"`
+}
+
+type advisoryVulnerability struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	VulnerableVersionRange string         `json:"vulnerable_version_range"`
+	FirstPatchedVersion    patchedVersion `json:"first_patched_version"`
+}
+
+// patchedVersion accepts both the current string form of
+// first_patched_version and the older {"identifier": "..."} object.
+type patchedVersion string
+
+func (p *patchedVersion) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*p = patchedVersion(s)
+		return nil
+	}
+	var obj struct {
+		Identifier string `json:"identifier"`
+	}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	*p = patchedVersion(obj.Identifier)
+	return nil
+}
+
+// Score returns the advisory's CVSS score and vector, preferring v4, then
+// v3, then the legacy top-level cvss field. Returns 0 when none is scored.
+func (a *GitHubAdvisory) Score() (float64, string) {
+	for _, key := range []string{"cvss_v4", "cvss_v3"} {
+		if c, ok := a.CVSSSeverities[key]; ok && c.Score > 0 {
+			return c.Score, c.VectorString
+		}
+	}
+	return a.CVSS.Score, a.CVSS.VectorString
+}
+
+// AffectedRange returns the vulnerable range and first patched version the
+// advisory lists for the given NuGet package.
+func (a *GitHubAdvisory) AffectedRange(packageID string) (vulnerable, fixed string) {
+	for _, v := range a.Vulnerabilities {
+		if strings.EqualFold(v.Package.Ecosystem, "nuget") && strings.EqualFold(v.Package.Name, packageID) {
+			return v.VulnerableVersionRange, string(v.FirstPatchedVersion)
+		}
+	}
+	return "", ""
+}
+
+// ghsaID extracts the GHSA identifier from an advisory URL such as
+// https://github.com/advisories/GHSA-xxxx-xxxx-xxxx. Returns "" when the URL
+// is not a GitHub advisory.
+func ghsaID(advisoryURL string) string {
+	id := advisoryLabel(advisoryURL)
+	if !strings.HasPrefix(strings.ToUpper(id), "GHSA-") {
+		return ""
+	}
+	return id
+}
+
+// FetchGitHubAdvisory returns the GitHub Security Advisory behind advisoryURL.
+func FetchGitHubAdvisory(advisoryURL string) (*GitHubAdvisory, error) {
+	id := ghsaID(advisoryURL)
+	if id == "" {
+		return nil, fmt.Errorf("not a GitHub advisory: %s", advisoryURL)
+	}
+	apiURL := "https://api.github.com/advisories/" + id
+	logTrace("FetchGitHubAdvisory: GET %s", apiURL)
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := githubClient.Do(req)
+	if err != nil {
+		logTrace("FetchGitHubAdvisory: fetch error: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		logTrace("FetchGitHubAdvisory: %s returned HTTP %d", id, resp.StatusCode)
+		return nil, fmt.Errorf("GitHub API returned %d", resp.StatusCode)
+	}
+	var adv GitHubAdvisory
+	if err := json.NewDecoder(resp.Body).Decode(&adv); err != nil {
+		logTrace("FetchGitHubAdvisory: decode error: %v", err)
+		return nil, err
+	}
+	return &adv, nil
+}
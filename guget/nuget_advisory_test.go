@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGitHubAdvisory_Decode(t *testing.T) {
+	body := `{
+  "ghsa_id": "GHSA-5crp-9r3c-p9vr",
+  "cve_id": "CVE-2024-21907",
+  "summary": "Improper Handling of Exceptional Conditions in Newtonsoft.Json",
+  "severity": "high",
+  "cvss": {"score": 7.5, "vector_string": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"},
+  "cvss_severities": {
+    "cvss_v3": {"score": 7.5, "vector_string": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"},
+    "cvss_v4": {"score": 0, "vector_string": null}
+  },
+  "vulnerabilities": [
+    {"package": {"ecosystem": "npm", "name": "newtonsoft.json"}, "vulnerable_version_range": "< 1.0", "first_patched_version": "1.0"},
+    {"package": {"ecosystem": "nuget", "name": "Newtonsoft.Json"}, "vulnerable_version_range": "< 13.0.1", "first_patched_version": {"identifier": "13.0.1"}}
+  ]
+}`
+	var adv GitHubAdvisory
+	if err := json.Unmarshal([]byte(body), &adv); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if score, vector := adv.Score(); score != 7.5 || vector == "" {
+		t.Errorf("Score() = %v, %q; want the v3 score since v4 is unscored", score, vector)
+	}
+	affected, fixed := adv.AffectedRange("newtonsoft.json")
+	if affected != "< 13.0.1" || fixed != "13.0.1" {
+		t.Errorf("AffectedRange = %q, %q; want the NuGet entry", affected, fixed)
+	}
+}
+
+func TestGHSAID(t *testing.T) {
+	cases := map[string]string{
+		"https://github.com/advisories/GHSA-5crp-9r3c-p9vr": "GHSA-5crp-9r3c-p9vr",
+		"https://example.com/advisory/CVE-2024-1":           "",
+	}
+	for url, want := range cases {
+		if got := ghsaID(url); got != want {
+			t.Errorf("ghsaID(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
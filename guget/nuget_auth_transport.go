@@ -0,0 +1,200 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// providerRetryDelay is how long a source waits before asking its credential
+// providers again after they failed or supplied credentials that were
+// refused, so a bad account doesn't launch a provider on every request.
+const providerRetryDelay = time.Minute
+
+// authTransport injects Basic Auth and answers a 401 by asking the credential
+// providers. Provider credentials are refreshed whenever they stop working —
+// Azure DevOps session tokens expire after an hour or so — rather than only
+// on the first 401 of the session.
+type authTransport struct {
+	base       http.RoundTripper
+	sourceURL  string
+	sourceName string
+	proxy      nugetProxy // passed on to credential providers
+	// fetch asks the credential providers; isRetry makes them bypass
+	// their token cache.
+	fetch func(isRetry bool) (*sourceCredential, error)
+
+	mu       sync.Mutex
+	username string
+	password string
+	gen      int  // bumped whenever the credentials change
+	provided bool // the current credentials came from a credential provider
+	worked   bool // a request has succeeded with the current credentials
+	forced   bool // the current credentials were fetched bypassing the provider cache
+	waitTill time.Time
+
+	refreshMu sync.Mutex // one provider call at a time; others wait and reuse its result
+}
+
+func newAuthTransport(source NugetSource) *authTransport {
+	t := &authTransport{
+		base:       http.DefaultTransport,
+		sourceURL:  source.URL,
+		sourceName: source.Name,
+		proxy:      source.Proxy,
+		username:   source.Username,
+		password:   source.Password,
+	}
+	t.fetch = func(isRetry bool) (*sourceCredential, error) {
+		if isRetry {
+			clearCredentialProviderCache()
+		}
+		return fetchFromCredentialProvider(t.sourceURL, t.sourceName, t.proxy, isRetry)
+	}
+	return t
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	cred := sourceCredential{Username: t.username, Password: t.password}
+	gen := t.gen
+	t.mu.Unlock()
+
+	// Clone so we never mutate the caller's request.
+	req = req.Clone(req.Context())
+	if cred.Username != "" || cred.Password != "" {
+		logTrace("[%s] sending Basic Auth (username=%q, password=%d chars)", t.sourceName, cred.Username, len(cred.Password))
+		req.SetBasicAuth(cred.Username, cred.Password)
+	} else {
+		logTrace("[%s] no credentials available, sending unauthenticated request", t.sourceName)
+	}
+	resp, err := t.base.RoundTrip(req)
+
+	// A 401 refreshes the credentials at most twice per request: once from
+	// the provider's cache and once bypassing it.
+	for attempt := 0; ; attempt++ {
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			if err == nil && (cred.Username != "" || cred.Password != "") {
+				t.markWorked(gen)
+			}
+			return resp, err
+		}
+		if attempt == 2 {
+			return resp, nil
+		}
+		fresh, freshGen, ok := t.refresh(gen)
+		if !ok {
+			return resp, nil
+		}
+		resp.Body.Close()
+		cred, gen = fresh, freshGen
+		resp, err = t.doAuthenticatedRequest(req, &cred)
+	}
+}
+
+// origin says where the current credentials came from: "nuget.config", the
+// "credential provider", or "" when there are none.
+func (t *authTransport) origin() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case t.provided:
+		return "credential provider"
+	case t.username != "" || t.password != "":
+		return "nuget.config"
+	}
+	return ""
+}
+
+// markWorked records that the credentials of generation gen were accepted.
+func (t *authTransport) markWorked(gen int) {
+	t.mu.Lock()
+	if t.gen == gen && !t.worked {
+		t.worked = true
+		t.forced = false
+	}
+	t.mu.Unlock()
+}
+
+// refresh replaces the credentials of generation gen after a 401 and returns
+// the new ones. When another request already replaced them, those are
+// returned without asking the providers again. ok is false when there is
+// nothing new to try.
+func (t *authTransport) refresh(gen int) (cred sourceCredential, newGen int, ok bool) {
+	t.refreshMu.Lock()
+	defer t.refreshMu.Unlock()
+
+	t.mu.Lock()
+	if t.gen != gen {
+		cred, newGen = sourceCredential{Username: t.username, Password: t.password}, t.gen
+		t.mu.Unlock()
+		return cred, newGen, true
+	}
+	now := time.Now()
+	if now.Before(t.waitTill) {
+		t.mu.Unlock()
+		return cred, gen, false
+	}
+	if t.provided && !t.worked && t.forced {
+		// Even a token fetched past the cache was refused: the account
+		// lacks access rather than holding a stale token.
+		logDebug("[%s] fresh provider credentials were refused; asking again in %s", t.sourceName, providerRetryDelay)
+		t.waitTill = now.Add(providerRetryDelay)
+		t.forced = false
+		t.mu.Unlock()
+		return cred, gen, false
+	}
+	// Provider credentials that get a 401 have expired or are stale, so the
+	// provider is told to bypass its cache.
+	isRetry := t.provided
+	expired := t.provided && t.worked
+	t.mu.Unlock()
+
+	switch {
+	case expired:
+		logInfo("[%s] credentials expired, refreshing them from the credential provider", t.sourceName)
+	case isRetry:
+		logDebug("[%s] provider credentials returned 401, clearing cache and retrying", t.sourceName)
+	default:
+		logTrace("[%s] got 401, invoking credential provider", t.sourceName)
+	}
+	fetched, err := t.fetch(isRetry)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		logDebug("[%s] credential provider: %v", t.sourceName, err)
+		t.waitTill = time.Now().Add(providerRetryDelay)
+		return cred, gen, false
+	}
+	t.username, t.password = fetched.Username, fetched.Password
+	t.gen++
+	t.provided, t.worked, t.forced = true, false, isRetry
+	return *fetched, t.gen, true
+}
+
+// doAuthenticatedRequest creates a new request with Basic Auth and sends it.
+func (t *authTransport) doAuthenticatedRequest(origReq *http.Request, cred *sourceCredential) (*http.Response, error) {
+	// Bodies (e.g. a pushed package) are replayed from GetBody; the first
+	// attempt already consumed the original reader.
+	var body io.Reader
+	if origReq.GetBody != nil {
+		rc, err := origReq.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		body = rc
+	}
+	req, err := http.NewRequestWithContext(origReq.Context(), origReq.Method, origReq.URL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = origReq.ContentLength
+	req.GetBody = origReq.GetBody
+	for k, v := range origReq.Header {
+		req.Header[k] = v
+	}
+	req.SetBasicAuth(cred.Username, cred.Password)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// tokenServer accepts Basic Auth with the current token only.
+type tokenServer struct {
+	mu    sync.Mutex
+	token string
+	body  string // body of the last authorized request
+}
+
+func (s *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, pass, ok := r.BasicAuth(); !ok || pass != s.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	b, _ := io.ReadAll(r.Body)
+	s.body = string(b)
+}
+
+func (s *tokenServer) rotate(token string) {
+	s.mu.Lock()
+	s.token = token
+	s.mu.Unlock()
+}
+
+// stubFetch records provider calls and hands out the server's token.
+type stubFetch struct {
+	srv   *tokenServer
+	err   error
+	calls []bool // isRetry of each call
+}
+
+func (f *stubFetch) fetch(isRetry bool) (*sourceCredential, error) {
+	f.calls = append(f.calls, isRetry)
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.srv.mu.Lock()
+	defer f.srv.mu.Unlock()
+	return &sourceCredential{Username: "VssSessionToken", Password: f.srv.token}, nil
+}
+
+func newStubTransport(t *testing.T, srv *tokenServer) (*http.Client, *stubFetch, string) {
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	f := &stubFetch{srv: srv}
+	at := newAuthTransport(NugetSource{Name: "corp", URL: ts.URL})
+	at.fetch = f.fetch
+	return &http.Client{Transport: at}, f, ts.URL
+}
+
+func getStatus(t *testing.T, client *http.Client, url string) int {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAuthTransport_RefreshesExpiredToken(t *testing.T) {
+	srv := &tokenServer{token: "token-1"}
+	client, f, url := newStubTransport(t, srv)
+
+	if code := getStatus(t, client, url); code != http.StatusOK {
+		t.Fatalf("first request: status %d, want 200", code)
+	}
+	if len(f.calls) != 1 || f.calls[0] {
+		t.Fatalf("provider calls = %v, want one cached lookup", f.calls)
+	}
+	if code := getStatus(t, client, url); code != http.StatusOK || len(f.calls) != 1 {
+		t.Fatalf("second request: status %d with %d provider calls, want 200 reusing the token", code, len(f.calls))
+	}
+
+	// The session token expires mid-session.
+	srv.rotate("token-2")
+	if code := getStatus(t, client, url); code != http.StatusOK {
+		t.Fatalf("after expiry: status %d, want 200", code)
+	}
+	if len(f.calls) != 2 || !f.calls[1] {
+		t.Fatalf("provider calls = %v, want a second call bypassing the cache", f.calls)
+	}
+	if code := getStatus(t, client, url); code != http.StatusOK || len(f.calls) != 2 {
+		t.Fatalf("after refresh: status %d with %d provider calls, want 200 reusing the new token", code, len(f.calls))
+	}
+}
+
+func TestAuthTransport_RefusedCredentialsBackOff(t *testing.T) {
+	srv := &tokenServer{token: "never-handed-out"}
+	client, f, url := newStubTransport(t, srv)
+	f.err = errors.New("no credential providers found")
+
+	for i := 0; i < 3; i++ {
+		if code := getStatus(t, client, url); code != http.StatusUnauthorized {
+			t.Fatalf("request %d: status %d, want 401", i, code)
+		}
+	}
+	if len(f.calls) != 1 {
+		t.Errorf("provider calls = %v, want one call within %s", f.calls, providerRetryDelay)
+	}
+}
+
+func TestAuthTransport_ReplaysBody(t *testing.T) {
+	srv := &tokenServer{token: "token-1"}
+	client, _, url := newStubTransport(t, srv)
+
+	resp, err := client.Post(url, "application/octet-stream", strings.NewReader("package bytes"))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || srv.body != "package bytes" {
+		t.Errorf("status %d, body %q; want 200 with the body replayed", resp.StatusCode, srv.body)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configSetting is one effective entry of a nuget.config section and the
+// file it came from.
+type configSetting struct {
+	Key    string
+	Value  string
+	Origin string
+}
+
+// configSection is a nuget.config section after merging the whole chain.
+// ClearedIn names the file whose <clear/> stopped inheritance, if any.
+type configSection struct {
+	Name      string
+	Settings  []configSetting
+	ClearedIn string
+}
+
+// effectiveNugetConfig is the merged view of every nuget.config that applies
+// to a directory. guget only reads these sections; it does not act on them.
+type effectiveNugetConfig struct {
+	Files    []string // closest first
+	Sections []configSection
+}
+
+// inspectedConfigSections are shown in this order; anything else found in a
+// file is listed after them.
+var inspectedConfigSections = []string{
+	"config", "packageSources", "disabledPackageSources", "activePackageSource",
+	"packageSourceMapping", "packageSourceCredentials", "apikeys",
+	"packageRestore", "bindingRedirects", "packageManagement", "solution",
+	"fallbackPackageFolders", "trustedSigners", "auditSources",
+}
+
+// xmlNode is a generic element, for sections whose shape varies.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+func (n xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// nugetConfigChain lists the nuget.config files that apply to dir, closest
+// first: each directory up to the root, then the user and machine configs.
+func nugetConfigChain(dir string) []string {
+	var files []string
+	var seen configFileSet
+	add := func(path string) {
+		if seen.add(path) {
+			files = append(files, path)
+		}
+	}
+	for {
+		for _, path := range dirNugetConfigs(dir) {
+			add(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	for _, path := range globalNugetConfigs() {
+		add(path)
+	}
+	return files
+}
+
+// loadEffectiveNugetConfig merges files (closest first): the closest file
+// defining a key wins, and a <clear/> hides everything farther away.
+// Secrets are masked.
+func loadEffectiveNugetConfig(files []string) effectiveNugetConfig {
+	cfg := effectiveNugetConfig{Files: files}
+	byName := map[string]*configSection{}
+	var order []string
+	stopped := NewSet[string]()
+	seenKeys := map[string]Set[string]{}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var root xmlNode
+		if err := xml.Unmarshal(data, &root); err != nil {
+			logDebug("Config inspector: skipping %s: %v", file, err)
+			continue
+		}
+		for _, sec := range root.Nodes {
+			name := sec.XMLName.Local
+			if stopped.Contains(name) {
+				continue
+			}
+			s := byName[name]
+			if s == nil {
+				s = &configSection{Name: name}
+				byName[name] = s
+				order = append(order, name)
+				seenKeys[name] = NewSet[string]()
+			}
+			for _, entry := range sectionEntries(sec) {
+				k := strings.ToLower(entry.Key)
+				if seenKeys[name].Contains(k) {
+					continue
+				}
+				seenKeys[name].Add(k)
+				entry.Origin = file
+				s.Settings = append(s.Settings, entry)
+			}
+			for _, n := range sec.Nodes {
+				if n.XMLName.Local == "clear" {
+					s.ClearedIn = file
+					stopped.Add(name)
+				}
+			}
+		}
+	}
+
+	for _, name := range inspectedConfigSections {
+		if s := byName[name]; s != nil {
+			cfg.Sections = append(cfg.Sections, *s)
+			delete(byName, name)
+		}
+	}
+	for _, name := range order {
+		if s := byName[name]; s != nil {
+			cfg.Sections = append(cfg.Sections, *s)
+		}
+	}
+	return cfg
+}
+
+// sectionEntries flattens a section into key/value pairs. Most sections are
+// <add key value/> lists; credentials, mappings, and trusted signers nest one
+// level deeper and are summarised per child element.
+func sectionEntries(sec xmlNode) []configSetting {
+	var out []configSetting
+	section := sec.XMLName.Local
+	for _, n := range sec.Nodes {
+		switch n.XMLName.Local {
+		case "clear":
+			continue
+		case "add":
+			out = append(out, configSetting{Key: n.attr("key"), Value: maskConfigValue(section, n.attr("key"), n.attr("value"))})
+		case "packageSource": // packageSourceMapping
+			var patterns []string
+			for _, p := range n.Nodes {
+				patterns = append(patterns, p.attr("pattern"))
+			}
+			out = append(out, configSetting{Key: n.attr("key"), Value: strings.Join(patterns, ", ")})
+		case "author", "repository": // trustedSigners
+			value := fmt.Sprintf("%s, %d certificate(s)", n.XMLName.Local, countNodes(n, "certificate"))
+			if idx := n.attr("serviceIndex"); idx != "" {
+				value += ", " + idx
+			}
+			out = append(out, configSetting{Key: n.attr("name"), Value: value})
+		default:
+			if section == "packageSourceCredentials" {
+				// Source names are XML-encoded as element names (spaces become _x0020_).
+				var parts []string
+				for _, c := range n.Nodes {
+					if key := c.attr("key"); key != "" {
+						parts = append(parts, key+"="+maskConfigValue(section, key, c.attr("value")))
+					}
+				}
+				out = append(out, configSetting{Key: strings.ReplaceAll(n.XMLName.Local, "_x0020_", " "), Value: strings.Join(parts, ", ")})
+				continue
+			}
+			out = append(out, configSetting{Key: n.XMLName.Local, Value: strings.TrimSpace(n.attr("value"))})
+		}
+	}
+	return out
+}
+
+func countNodes(n xmlNode, name string) int {
+	count := 0
+	for _, c := range n.Nodes {
+		if c.XMLName.Local == name {
+			count++
+		}
+	}
+	return count
+}
+
+// maskConfigValue hides API keys and passwords, and expands environment
+// references in everything else the way NuGet does.
+func maskConfigValue(section, key, value string) string {
+	k := strings.ToLower(key)
+	if section == "apikeys" || strings.Contains(k, "password") || strings.Contains(k, "apikey") || strings.Contains(k, "token") {
+		if value == "" {
+			return ""
+		}
+		return "••••••"
+	}
+	return expandConfigValue(value)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEffectiveNugetConfig(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "src")
+	os.MkdirAll(sub, 0755)
+	near := filepath.Join(sub, "nuget.config")
+	far := filepath.Join(root, "nuget.config")
+	os.WriteFile(near, []byte(`<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <config>
+    <add key="dependencyVersion" value="HighestMinor" />
+  </config>
+  <packageRestore>
+    <clear />
+    <add key="enabled" value="False" />
+  </packageRestore>
+  <packageSourceCredentials>
+    <Corp_x0020_Feed>
+      <add key="Username" value="me" />
+      <add key="ClearTextPassword" value="hunter2" />
+    </Corp_x0020_Feed>
+  </packageSourceCredentials>
+</configuration>`), 0644)
+	os.WriteFile(far, []byte(`<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <config>
+    <add key="dependencyVersion" value="Lowest" />
+    <add key="http_proxy" value="http://proxy:8080" />
+  </config>
+  <packageRestore>
+    <add key="automatic" value="True" />
+  </packageRestore>
+  <trustedSigners>
+    <author name="microsoft">
+      <certificate fingerprint="AB" hashAlgorithm="SHA256" allowUntrustedRoot="false" />
+    </author>
+  </trustedSigners>
+</configuration>`), 0644)
+
+	cfg := loadEffectiveNugetConfig([]string{near, far})
+	sections := map[string]configSection{}
+	for _, s := range cfg.Sections {
+		sections[s.Name] = s
+	}
+
+	config := sections["config"]
+	if len(config.Settings) != 2 || config.Settings[0].Value != "HighestMinor" || config.Settings[0].Origin != near || config.Settings[1].Origin != far {
+		t.Errorf("config = %+v, want the closer dependencyVersion plus the farther http_proxy", config.Settings)
+	}
+	restore := sections["packageRestore"]
+	if len(restore.Settings) != 1 || restore.ClearedIn != near {
+		t.Errorf("packageRestore = %+v, want only the closer file's setting after <clear/>", restore)
+	}
+	creds := sections["packageSourceCredentials"]
+	if len(creds.Settings) != 1 || creds.Settings[0].Key != "Corp Feed" || creds.Settings[0].Value != "Username=me, ClearTextPassword=••••••" {
+		t.Errorf("credentials = %+v, want the decoded name with the password masked", creds.Settings)
+	}
+	if signers := sections["trustedSigners"]; len(signers.Settings) != 1 || signers.Settings[0].Value != "author, 1 certificate(s)" {
+		t.Errorf("trustedSigners = %+v", signers.Settings)
+	}
+	if cfg.Sections[0].Name != "config" {
+		t.Errorf("expected sections in display order, got %s first", cfg.Sections[0].Name)
+	}
+}
+
+func TestNugetConfigChain(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	os.MkdirAll(sub, 0755)
+	os.WriteFile(filepath.Join(root, "nuget.config"), []byte("<configuration/>"), 0644)
+	os.WriteFile(filepath.Join(root, "a", "nuget.config"), []byte("<configuration/>"), 0644)
+
+	chain := nugetConfigChain(sub)
+	if len(chain) < 2 || chain[0] != filepath.Join(root, "a", "nuget.config") || chain[1] != filepath.Join(root, "nuget.config") {
+		t.Errorf("chain = %v, want a/nuget.config then the root config", chain)
+	}
+}
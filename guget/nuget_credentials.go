@@ -9,6 +9,7 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -31,6 +32,15 @@ type credentialProvider struct {
 	isDLL bool // requires "dotnet exec"
 }
 
+// providerRequest is one credential lookup handed to a provider.
+type providerRequest struct {
+	sourceURL   string
+	env         []string // the provider's environment
+	isRetry     bool
+	interactive bool      // let the provider prompt, e.g. for a device-code sign-in
+	echo        io.Writer // also receives the provider's stderr; nil = trace log only
+}
+
 type credentialProviderResponse struct {
 	Username  string   `json:"Username"`
 	Password  string   `json:"Password"`
@@ -112,10 +122,10 @@ func parseCredentials(data []byte) map[string]sourceCredential {
 				}
 				switch strings.ToLower(key) {
 				case "username":
-					username = value
+					username = expandCredentialValue(value)
 					logTrace("parseCredentials: [%s] username = %q", currentSource, username)
 				case "cleartextpassword":
-					clearPass = value
+					clearPass = expandCredentialValue(value)
 					logTrace("parseCredentials: [%s] ClearTextPassword present (%d chars)", currentSource, len(clearPass))
 				case "password":
 					encPass = value
@@ -159,11 +169,19 @@ func parseCredentials(data []byte) map[string]sourceCredential {
 
 // fetchFromCredentialProvider tries all discovered credential providers in parallel for the given source URL.
 // When isRetry is true, providers are told this is a retry so they bypass cached tokens.
-func fetchFromCredentialProvider(sourceURL, sourceName string, isRetry bool) (*sourceCredential, error) {
-	providers := findCredentialProviders()
+// Providers run with guget's environment plus the proxy from nuget.config, if any.
+func fetchFromCredentialProvider(sourceURL, sourceName string, proxy nugetProxy, isRetry bool) (*sourceCredential, error) {
+	providers, err := providersFor(findCredentialProviders(), sourceName)
+	if err != nil {
+		return nil, err
+	}
 	if len(providers) == 0 {
 		return nil, fmt.Errorf("no credential providers found")
 	}
+	req := providerRequest{sourceURL: sourceURL, env: proxy.environ(os.Environ()), isRetry: isRetry}
+	if proxy.URL != "" {
+		logTrace("[%s] passing http_proxy %s from nuget.config to credential providers", sourceName, proxy.URL)
+	}
 
 	type providerResult struct {
 		cred *sourceCredential
@@ -177,7 +195,7 @@ func fetchFromCredentialProvider(sourceURL, sourceName string, isRetry bool) (*s
 		wg.Add(1)
 		go func(p credentialProvider) {
 			defer wg.Done()
-			cred, err := invokeProvider(p, sourceURL, isRetry)
+			cred, err := invokeProvider(p, req)
 			results <- providerResult{cred, err, filepath.Base(p.path)}
 		}(p)
 	}
@@ -318,6 +336,65 @@ func findCredentialProviders() []credentialProvider {
 	return providers
 }
 
+// pinnedProviders maps lowercased source names to the one credential
+// provider to ask for them, from the credentialProviders setting.
+var pinnedProviders map[string]string
+
+func setPinnedProviders(pins map[string]string) {
+	pinnedProviders = make(map[string]string, len(pins))
+	for source, provider := range pins {
+		pinnedProviders[strings.ToLower(source)] = provider
+	}
+}
+
+// providersFor narrows the discovered providers to the one pinned for
+// sourceName, if any. A pin names a provider by file name, with or without
+// its extension, or by path; a path outside the usual plugin folders is
+// used as given.
+func providersFor(providers []credentialProvider, sourceName string) ([]credentialProvider, error) {
+	pin, ok := pinnedProviders[strings.ToLower(sourceName)]
+	if !ok || pin == "" {
+		return providers, nil
+	}
+	for _, p := range providers {
+		if providerMatches(p, pin) {
+			return []credentialProvider{p}, nil
+		}
+	}
+	if info, err := os.Stat(pin); err == nil && !info.IsDir() {
+		return []credentialProvider{{path: pin, isDLL: strings.HasSuffix(strings.ToLower(pin), ".dll")}}, nil
+	}
+	return nil, fmt.Errorf("credential provider %q pinned for %s was not found", pin, sourceName)
+}
+
+// providerMatches reports whether pin names p.
+func providerMatches(p credentialProvider, pin string) bool {
+	if providerNamed(p, pin) || strings.EqualFold(p.path, pin) {
+		return true
+	}
+	abs, err := filepath.Abs(pin)
+	return err == nil && strings.EqualFold(filepath.Clean(p.path), abs)
+}
+
+// providerNamed reports whether pin is p's file name, with or without its
+// extension. A pin holding a path never matches.
+func providerNamed(p credentialProvider, pin string) bool {
+	if strings.ContainsAny(pin, `/\`) {
+		return false
+	}
+	base := filepath.Base(p.path)
+	if strings.EqualFold(base, pin) {
+		return true
+	}
+	lower := strings.ToLower(base)
+	for _, ext := range []string{".exe", ".dll", ".bat"} {
+		if strings.HasSuffix(lower, ext) && strings.EqualFold(base[:len(base)-len(ext)], pin) {
+			return true
+		}
+	}
+	return false
+}
+
 // findProvidersInDir scans dir for sub-directories that contain a credential
 // provider executable or DLL whose name matches the directory name.
 func findProvidersInDir(dir string) []credentialProvider {
@@ -435,13 +512,107 @@ func findPluginsOnPath() []credentialProvider {
 	return providers
 }
 
+// credentialTimeout overrides the provider timeout when set, from
+// --credential-timeout or the credentialProviderTimeout setting.
+var credentialTimeout time.Duration
+
+func setCredentialTimeout(d time.Duration) {
+	if d > 0 {
+		credentialTimeout = d
+	}
+}
+
+// providerTimeout bounds a credential provider call. Without
+// --credential-timeout, NuGet's NUGET_PLUGIN_REQUEST_TIMEOUT_IN_SECONDS
+// raises it for providers that are slow behind a proxy; being in the
+// environment, it reaches the provider too.
+func providerTimeout() time.Duration {
+	if credentialTimeout > 0 {
+		return credentialTimeout
+	}
+	return envSeconds("NUGET_PLUGIN_REQUEST_TIMEOUT_IN_SECONDS", 10*time.Second)
+}
+
+// providerHandshakeTimeout bounds how long a V2 plugin may take to start and
+// send its handshake (NUGET_PLUGIN_HANDSHAKE_TIMEOUT_IN_SECONDS).
+func providerHandshakeTimeout() time.Duration {
+	return envSeconds("NUGET_PLUGIN_HANDSHAKE_TIMEOUT_IN_SECONDS", 10*time.Second)
+}
+
+// envSeconds reads a whole number of seconds from the environment variable
+// key, or returns def when it is unset or invalid.
+func envSeconds(key string, def time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		logWarn("Ignoring %s=%q: not a positive number of seconds", key, v)
+		return def
+	}
+	return time.Duration(n) * time.Second
+}
+
+// providerStderr logs a credential provider's stderr at trace level, line by
+// line, and keeps the last line to explain a failure. Providers report proxy
+// and sign-in problems there rather than in their protocol output.
+type providerStderr struct {
+	name    string
+	echo    io.Writer // see providerRequest.echo
+	mu      sync.Mutex
+	partial []byte
+	last    string
+}
+
+func (w *providerStderr) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.line(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *providerStderr) line(s string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return
+	}
+	logTrace("[%s] stderr: %s", w.name, s)
+	if w.echo != nil {
+		fmt.Fprintf(w.echo, "  [%s] %s\n", w.name, s)
+	}
+	w.last = s
+}
+
+// explain adds the provider's last line of stderr to err.
+func (w *providerStderr) explain(err error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		w.line(string(w.partial))
+		w.partial = nil
+	}
+	if err == nil || w.last == "" {
+		return err
+	}
+	return fmt.Errorf("%w (stderr: %s)", err, w.last)
+}
+
 // invokeProvider tries V2 first, falling back to V1 if the provider doesn't speak V2.
-// When isRetry is true, the credential provider is told this is a retry (e.g. after a 401),
+// When req.isRetry is true, the credential provider is told this is a retry (e.g. after a 401),
 // which causes it to bypass cached tokens and acquire fresh credentials.
-func invokeProvider(provider credentialProvider, sourceURL string, isRetry bool) (*sourceCredential, error) {
+func invokeProvider(provider credentialProvider, req providerRequest) (*sourceCredential, error) {
 	name := filepath.Base(provider.path)
 
-	cred, err := invokeProviderV2(provider, sourceURL, isRetry)
+	cred, err := invokeProviderV2(provider, req)
 	if err == nil && (cred.Username != "" || cred.Password != "") {
 		return cred, nil
 	}
@@ -450,20 +621,23 @@ func invokeProvider(provider credentialProvider, sourceURL string, isRetry bool)
 	}
 
 	logDebug("[%s] V2 returned no credentials, trying V1 protocol", name)
-	return invokeProviderV1(provider, sourceURL, isRetry)
+	return invokeProviderV1(provider, req)
 }
 
 // invokeProviderV1 calls a credential provider using the V1 command-line args protocol.
-func invokeProviderV1(provider credentialProvider, sourceURL string, isRetry bool) (*sourceCredential, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func invokeProviderV1(provider credentialProvider, req providerRequest) (*sourceCredential, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout())
 	defer cancel()
 
 	var cmd *exec.Cmd
 	retryStr := "false"
-	if isRetry {
+	if req.isRetry {
 		retryStr = "true"
 	}
-	args := []string{"-Uri", sourceURL, "-NonInteractive", "-IsRetry", retryStr}
+	args := []string{"-Uri", req.sourceURL, "-IsRetry", retryStr}
+	if !req.interactive {
+		args = append(args, "-NonInteractive")
+	}
 	if provider.isDLL {
 		dotnetArgs := append([]string{"exec", provider.path}, args...)
 		cmd = exec.CommandContext(ctx, "dotnet", dotnetArgs...)
@@ -471,10 +645,14 @@ func invokeProviderV1(provider credentialProvider, sourceURL string, isRetry boo
 	} else {
 		cmd = exec.CommandContext(ctx, provider.path, args...)
 	}
+	stderr := &providerStderr{name: filepath.Base(provider.path), echo: req.echo}
+	cmd.Env = req.env
+	cmd.Stderr = stderr
 	out, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("provider exited non-zero: %w", err)
+		return nil, stderr.explain(fmt.Errorf("provider exited non-zero: %w", err))
 	}
+	stderr.explain(nil)
 	logTrace("invokeProviderV1: %s produced %d bytes of output", filepath.Base(provider.path), len(out))
 
 	// Credential providers sometimes emit informational lines to stdout before
@@ -504,8 +682,8 @@ func invokeProviderV1(provider credentialProvider, sourceURL string, isRetry boo
 }
 
 // invokeProviderV2 calls a credential provider using the V2 stdin/stdout JSON protocol.
-func invokeProviderV2(provider credentialProvider, sourceURL string, isRetry bool) (*sourceCredential, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func invokeProviderV2(provider credentialProvider, req providerRequest) (_ *sourceCredential, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), max(providerTimeout(), providerHandshakeTimeout()))
 	defer cancel()
 
 	// CredentialProvider.Microsoft requires -Plugin to enter V2 mode.
@@ -521,6 +699,9 @@ func invokeProviderV2(provider credentialProvider, sourceURL string, isRetry boo
 	} else {
 		cmd = exec.CommandContext(ctx, provider.path, args...)
 	}
+	stderr := &providerStderr{name: filepath.Base(provider.path), echo: req.echo}
+	cmd.Env = req.env
+	cmd.Stderr = stderr
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -538,14 +719,17 @@ func invokeProviderV2(provider credentialProvider, sourceURL string, isRetry boo
 		stdin.Close()
 		_ = cmd.Process.Kill()
 		_ = cmd.Wait()
+		err = stderr.explain(err)
 	}()
 
 	scanner := bufio.NewScanner(stdout)
 
 	// 1. Read Handshake Request from plugin.
+	handshakeTimer := time.AfterFunc(providerHandshakeTimeout(), cancel)
 	if !scanner.Scan() {
 		return nil, fmt.Errorf("V2: no handshake from plugin")
 	}
+	handshakeTimer.Stop()
 	var handshake pluginMessage
 	if err := json.Unmarshal(scanner.Bytes(), &handshake); err != nil {
 		return nil, fmt.Errorf("V2: parsing handshake: %w", err)
@@ -570,9 +754,9 @@ func invokeProviderV2(provider credentialProvider, sourceURL string, isRetry boo
 	// 3. Send GetAuthenticationCredentials Request.
 	credReqId := newRequestID()
 	payloadJSON, _ := json.Marshal(map[string]any{
-		"Uri":              sourceURL,
-		"IsRetry":          isRetry,
-		"IsNonInteractive": true,
+		"Uri":              req.sourceURL,
+		"IsRetry":          req.isRetry,
+		"IsNonInteractive": !req.interactive,
 		"CanShowDialog":    false,
 	})
 	writePluginMessage(stdin, pluginMessage{
@@ -581,7 +765,7 @@ func invokeProviderV2(provider credentialProvider, sourceURL string, isRetry boo
 		Method:    "GetAuthenticationCredentials",
 		Payload:   json.RawMessage(payloadJSON),
 	})
-	logTrace("invokeProviderV2: sent GetAuthenticationCredentials (RequestId=%s, Uri=%s)", credReqId, sourceURL)
+	logTrace("invokeProviderV2: sent GetAuthenticationCredentials (RequestId=%s, Uri=%s)", credReqId, req.sourceURL)
 
 	// 4. Read messages until we get the credential response.
 	for scanner.Scan() {
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// NugetLocal is one of the local caches reported by `dotnet nuget locals`
+// (http-cache, global-packages, temp, plugins-cache).
+type NugetLocal struct {
+	Name string
+	Path string
+	Size int64 // bytes on disk; 0 when the folder does not exist
+}
+
+// parseNugetLocalsList parses `dotnet nuget locals all --list` output, which
+// prints one "name: path" line per cache. Older SDKs prefix lines with
+// "info : ".
+func parseNugetLocalsList(out string) []NugetLocal {
+	var locals []NugetLocal
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimPrefix(line, "info :"))
+		name, path, ok := strings.Cut(line, ": ")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		locals = append(locals, NugetLocal{Name: name, Path: strings.TrimSpace(path)})
+	}
+	return locals
+}
+
+// listNugetLocals asks the dotnet CLI where each cache lives and measures
+// how much space each one uses.
+func listNugetLocals() ([]NugetLocal, error) {
+	logDebug("dotnet nuget locals all --list")
+	out, err := exec.Command("dotnet", "nuget", "locals", "all", "--list").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("dotnet nuget locals: %w\n%s", err, strings.TrimSpace(string(out)))
+	}
+	locals := parseNugetLocalsList(string(out))
+	for i := range locals {
+		locals[i].Size = dirSize(locals[i].Path)
+	}
+	return locals, nil
+}
+
+// clearNugetLocal runs `dotnet nuget locals <name> --clear`; name "all"
+// clears every cache.
+func clearNugetLocal(name string) error {
+	logInfo("dotnet nuget locals %s --clear", name)
+	out, err := exec.Command("dotnet", "nuget", "locals", name, "--clear").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// dirSize returns the total size of the regular files under root, skipping
+// anything it cannot read.
+func dirSize(root string) int64 {
+	var total int64
+	filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseNugetLocalsList(t *testing.T) {
+	out := `http-cache: /home/dev/.local/share/NuGet/http-cache
+global-packages: /home/dev/.nuget/packages/
+info : temp: /tmp/NuGetScratch
+plugins-cache: C:\Users\dev\AppData\Local\NuGet\plugins-cache
+Welcome to .NET!
+`
+	got := parseNugetLocalsList(out)
+	want := []NugetLocal{
+		{Name: "http-cache", Path: "/home/dev/.local/share/NuGet/http-cache"},
+		{Name: "global-packages", Path: "/home/dev/.nuget/packages/"},
+		{Name: "temp", Path: "/tmp/NuGetScratch"},
+		{Name: "plugins-cache", Path: `C:\Users\dev\AppData\Local\NuGet\plugins-cache`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNugetLocalsList =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "a", "b"), 0755)
+	os.WriteFile(filepath.Join(root, "one"), make([]byte, 100), 0644)
+	os.WriteFile(filepath.Join(root, "a", "b", "two"), make([]byte, 50), 0644)
+	if got := dirSize(root); got != 150 {
+		t.Errorf("dirSize = %d, want 150", got)
+	}
+	if got := dirSize(filepath.Join(root, "missing")); got != 0 {
+		t.Errorf("dirSize(missing) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PackageFolders are the local folders NuGet restores from before touching
+// the network: the global packages folder that restore extracts into, and
+// read-only fallback folders (e.g. an offline SDK cache).
+type PackageFolders struct {
+	Global   string
+	Fallback []string
+}
+
+// packageFoldersConfig is the subset of a NuGet.Config file that controls
+// package folders.
+type packageFoldersConfig struct {
+	XMLName       xml.Name        `xml:"configuration"`
+	Config        []packageSource `xml:"config>add"`
+	Fallback      []packageSource `xml:"fallbackPackageFolders>add"`
+	FallbackClear []struct{}      `xml:"fallbackPackageFolders>clear"`
+}
+
+// packageFoldersFromNugetConfig reads globalPackagesFolder and
+// fallbackPackageFolders from a single NuGet.Config. Relative paths are
+// resolved against the config file's directory. cleared reports a <clear/>
+// inside <fallbackPackageFolders>.
+func packageFoldersFromNugetConfig(path string) (global string, fallback []string, cleared bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, false
+	}
+	var cfg packageFoldersConfig
+	if err := unmarshalXMLText(data, &cfg); err != nil {
+		return "", nil, false
+	}
+	dir := filepath.Dir(path)
+	for _, c := range cfg.Config {
+		if value := expandConfigValue(c.Value); strings.EqualFold(c.Key, "globalPackagesFolder") && strings.TrimSpace(value) != "" {
+			global = resolveConfigPath(dir, value)
+		}
+	}
+	for _, f := range cfg.Fallback {
+		if value := expandConfigValue(f.Value); strings.TrimSpace(value) != "" {
+			fallback = append(fallback, resolveConfigPath(dir, value))
+		}
+	}
+	return global, fallback, len(cfg.FallbackClear) > 0
+}
+
+// resolveConfigPath makes a folder path from a NuGet.Config value absolute,
+// relative to the directory holding the config file.
+func resolveConfigPath(configDir, value string) string {
+	value = filepath.FromSlash(strings.ReplaceAll(strings.TrimSpace(value), `\`, "/"))
+	if strings.HasPrefix(value, "~"+string(os.PathSeparator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			value = filepath.Join(home, value[2:])
+		}
+	}
+	if !filepath.IsAbs(value) {
+		value = filepath.Join(configDir, value)
+	}
+	return filepath.Clean(value)
+}
+
+// defaultGlobalPackagesFolder returns ~/.nuget/packages, the folder restore
+// uses when neither NUGET_PACKAGES nor globalPackagesFolder is set.
+func defaultGlobalPackagesFolder() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		logWarn("os.UserHomeDir(): %v", err)
+		return ""
+	}
+	return filepath.Join(home, ".nuget", "packages")
+}
+
+// applyPackageFolderEnv applies the NUGET_PACKAGES and
+// NUGET_FALLBACK_PACKAGES environment overrides, which take precedence over
+// every NuGet.Config, and fills in the default global folder.
+func (pf *PackageFolders) applyPackageFolderEnv() {
+	if v := strings.TrimSpace(os.Getenv("NUGET_PACKAGES")); v != "" {
+		pf.Global = filepath.Clean(v)
+	}
+	if v := strings.TrimSpace(os.Getenv("NUGET_FALLBACK_PACKAGES")); v != "" {
+		pf.Fallback = nil
+		for _, p := range strings.Split(v, string(os.PathListSeparator)) {
+			if p = strings.TrimSpace(p); p != "" {
+				pf.Fallback = append(pf.Fallback, filepath.Clean(p))
+			}
+		}
+	}
+	if pf.Global == "" {
+		pf.Global = defaultGlobalPackagesFolder()
+	}
+}
+
+// Contains reports whether id at version is already extracted in the global
+// packages folder or one of the fallback folders, and which folder has it.
+// Both use the lowercase id/version layout written by restore; a package is
+// only complete once its .nupkg.metadata or .sha512 marker exists.
+func (pf PackageFolders) Contains(id, version string) (string, bool) {
+	id = strings.ToLower(id)
+	version = strings.ToLower(version)
+	for _, folder := range pf.all() {
+		if extracted(folder, id, version) {
+			return folder, true
+		}
+	}
+	return "", false
+}
+
+// CachedVersions returns the lowercase versions of id that are fully
+// extracted in any of the folders, listing each package directory once
+// rather than probing every version individually.
+func (pf PackageFolders) CachedVersions(id string) map[string]bool {
+	id = strings.ToLower(id)
+	cached := make(map[string]bool)
+	for _, folder := range pf.all() {
+		entries, err := os.ReadDir(filepath.Join(folder, id))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			version := strings.ToLower(e.Name())
+			if e.IsDir() && !cached[version] && extracted(folder, id, version) {
+				cached[version] = true
+			}
+		}
+	}
+	return cached
+}
+
+// all returns the global folder followed by the fallback folders, skipping
+// unset entries.
+func (pf PackageFolders) all() []string {
+	var folders []string
+	if pf.Global != "" {
+		folders = append(folders, pf.Global)
+	}
+	for _, f := range pf.Fallback {
+		if f != "" {
+			folders = append(folders, f)
+		}
+	}
+	return folders
+}
+
+// extracted reports whether the lowercase id/version directory under folder
+// holds a completely extracted package.
+func extracted(folder, id, version string) bool {
+	dir := filepath.Join(folder, id, version)
+	for _, marker := range []string{".nupkg.metadata", id + "." + version + ".nupkg.sha512"} {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
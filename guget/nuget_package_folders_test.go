@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectSources_PackageFolders(t *testing.T) {
+	t.Setenv("NUGET_PACKAGES", "")
+	t.Setenv("NUGET_FALLBACK_PACKAGES", "")
+
+	root := t.TempDir()
+	child := filepath.Join(root, "src")
+	os.MkdirAll(child, 0755)
+	os.WriteFile(filepath.Join(root, "nuget.config"), []byte(`<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <config>
+    <add key="globalPackagesFolder" value="outer-packages" />
+  </config>
+  <fallbackPackageFolders>
+    <add key="outer" value="/opt/outer-fallback" />
+  </fallbackPackageFolders>
+</configuration>`), 0644)
+	os.WriteFile(filepath.Join(child, "nuget.config"), []byte(`<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <config>
+    <add key="globalPackagesFolder" value="..\.packages" />
+  </config>
+  <fallbackPackageFolders>
+    <clear />
+    <add key="sdk" value="/usr/share/dotnet/sdk/NuGetFallbackFolder" />
+  </fallbackPackageFolders>
+</configuration>`), 0644)
+
+	folders := DetectSources(child).Folders
+	if want := filepath.Join(root, ".packages"); folders.Global != want {
+		t.Errorf("Global = %q, want %q (closest config, relative to it)", folders.Global, want)
+	}
+	if len(folders.Fallback) != 1 || folders.Fallback[0] != filepath.FromSlash("/usr/share/dotnet/sdk/NuGetFallbackFolder") {
+		t.Errorf("Fallback = %v, want only the closest folder after <clear/>", folders.Fallback)
+	}
+}
+
+func TestPackageFoldersFromNugetConfig_UTF16AndVariables(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("GUGET_TEST_CACHE", cache)
+
+	path := filepath.Join(t.TempDir(), "NuGet.Config")
+	content := "<?xml version=\"1.0\" encoding=\"utf-16\"?>\r\n" +
+		"<configuration>\r\n" +
+		"  <config><add key=\"globalPackagesFolder\" value=\"%GUGET_TEST_CACHE%/packages\" /></config>\r\n" +
+		"  <fallbackPackageFolders><add key=\"sdk\" value=\"%GUGET_TEST_CACHE%/fallback\" /></fallbackPackageFolders>\r\n" +
+		"</configuration>\r\n"
+	os.WriteFile(path, (&textFile{Encoding: encodingUTF16LE, BOM: true}).encode(content), 0644)
+
+	global, fallback, _ := packageFoldersFromNugetConfig(path)
+	if want := filepath.Join(cache, "packages"); global != want {
+		t.Errorf("global = %q, want %q", global, want)
+	}
+	if want := filepath.Join(cache, "fallback"); len(fallback) != 1 || fallback[0] != want {
+		t.Errorf("fallback = %v, want [%s]", fallback, want)
+	}
+}
+
+func TestDetectSources_PackageFoldersEnvOverride(t *testing.T) {
+	env := t.TempDir()
+	t.Setenv("NUGET_PACKAGES", env)
+	t.Setenv("NUGET_FALLBACK_PACKAGES", "")
+
+	folders := DetectSources(t.TempDir()).Folders
+	if folders.Global != env {
+		t.Errorf("Global = %q, want NUGET_PACKAGES %q", folders.Global, env)
+	}
+}
+
+func TestPackageFolders_Contains(t *testing.T) {
+	global := t.TempDir()
+	fallback := t.TempDir()
+	os.MkdirAll(filepath.Join(global, "serilog", "4.0.0"), 0755)
+	os.WriteFile(filepath.Join(global, "serilog", "4.0.0", ".nupkg.metadata"), []byte("{}"), 0644)
+	os.MkdirAll(filepath.Join(global, "polly", "8.5.2"), 0755) // extraction not finished
+	os.MkdirAll(filepath.Join(fallback, "dapper", "2.1.35"), 0755)
+	os.WriteFile(filepath.Join(fallback, "dapper", "2.1.35", "dapper.2.1.35.nupkg.sha512"), []byte("x"), 0644)
+
+	pf := PackageFolders{Global: global, Fallback: []string{fallback}}
+	if folder, ok := pf.Contains("Serilog", "4.0.0"); !ok || folder != global {
+		t.Errorf("Serilog 4.0.0: got (%q, %v), want global folder", folder, ok)
+	}
+	if _, ok := pf.Contains("Polly", "8.5.2"); ok {
+		t.Error("Polly 8.5.2 has no completion marker and should not count as downloaded")
+	}
+	if folder, ok := pf.Contains("Dapper", "2.1.35"); !ok || folder != fallback {
+		t.Errorf("Dapper 2.1.35: got (%q, %v), want fallback folder", folder, ok)
+	}
+}
+
+func TestPackageFolders_CachedVersions(t *testing.T) {
+	global := t.TempDir()
+	fallback := t.TempDir()
+	for _, v := range []string{"3.1.1", "4.0.0-beta.1"} {
+		os.MkdirAll(filepath.Join(global, "serilog", v), 0755)
+		os.WriteFile(filepath.Join(global, "serilog", v, ".nupkg.metadata"), []byte("{}"), 0644)
+	}
+	os.MkdirAll(filepath.Join(global, "serilog", "4.0.0"), 0755) // incomplete
+	os.MkdirAll(filepath.Join(fallback, "serilog", "2.12.0"), 0755)
+	os.WriteFile(filepath.Join(fallback, "serilog", "2.12.0", "serilog.2.12.0.nupkg.sha512"), []byte("x"), 0644)
+
+	got := PackageFolders{Global: global, Fallback: []string{fallback}}.CachedVersions("Serilog")
+	want := map[string]bool{"3.1.1": true, "4.0.0-beta.1": true, "2.12.0": true}
+	if len(got) != len(want) {
+		t.Fatalf("CachedVersions = %v, want %v", got, want)
+	}
+	for v := range want {
+		if !got[v] {
+			t.Errorf("expected %s to be cached, got %v", v, got)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMinimumFixedVersion(t *testing.T) {
+	vuln := []PackageVulnerability{{AdvisoryURL: "https://github.com/advisories/GHSA-test", Severity: 2}}
+	net9 := []TargetFramework{ParseTargetFramework("net9.0")}
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("3.0.0")},
+		{SemVer: ParseSemVer("2.1.0-beta")},
+		{SemVer: ParseSemVer("2.0.2")},
+		{SemVer: ParseSemVer("2.0.1"), Frameworks: net9},
+		{SemVer: ParseSemVer("2.0.0"), Vulnerabilities: vuln},
+		{SemVer: ParseSemVer("1.0.0"), Vulnerabilities: vuln},
+	}}
+	net8 := NewSet[TargetFramework]()
+	net8.Add(ParseTargetFramework("net8.0"))
+
+	if got := info.MinimumFixedVersion(ParseSemVer("1.0.0"), NewSet[TargetFramework]()); got == nil || got.SemVer.String() != "2.0.1" {
+		t.Errorf("no targets: got %v, want 2.0.1", got)
+	}
+	if got := info.MinimumFixedVersion(ParseSemVer("1.0.0"), net8); got == nil || got.SemVer.String() != "2.0.2" {
+		t.Errorf("net8.0: got %v, want 2.0.2 (2.0.1 is net9.0-only)", got)
+	}
+	if got := info.MinimumFixedVersion(ParseSemVer("3.0.0"), net8); got != nil {
+		t.Errorf("nothing newer: got %v, want nil", got.SemVer)
+	}
+}
+
+func TestReleaseLag(t *testing.T) {
+	day := func(s string) time.Time {
+		tm, _ := time.Parse("2006-01-02", s)
+		return tm
+	}
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("3.0.0-rc1"), Published: day("2025-06-01")},
+		{SemVer: ParseSemVer("2.0.0"), Published: day("2024-01-01")},
+		{SemVer: ParseSemVer("1.0.0"), Published: day("2022-01-01")},
+		{SemVer: ParseSemVer("0.9.0"), Published: day("1900-01-01")},
+	}}
+
+	if lag, ok := info.ReleaseLag(ParseSemVer("1.0.0")); !ok || lag != day("2024-01-01").Sub(day("2022-01-01")) {
+		t.Errorf("1.0.0: lag = %v, ok = %v; want the gap to 2.0.0 (pre-releases ignored)", lag, ok)
+	}
+	if lag, ok := info.ReleaseLag(ParseSemVer("2.0.0")); !ok || lag != 0 {
+		t.Errorf("2.0.0: lag = %v, ok = %v; want 0, true", lag, ok)
+	}
+	if _, ok := info.ReleaseLag(ParseSemVer("0.9.0")); ok {
+		t.Error("0.9.0: want ok = false for the NuGet unknown-date sentinel")
+	}
+}
+
+func TestVersionsBetween(t *testing.T) {
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("3.0.0")},
+		{SemVer: ParseSemVer("2.2.0-rc1")},
+		{SemVer: ParseSemVer("2.1.0")},
+		{SemVer: ParseSemVer("2.0.1")},
+		{SemVer: ParseSemVer("2.0.0")},
+		{SemVer: ParseSemVer("1.0.0")},
+	}}
+	names := func(vs []PackageVersion) []string {
+		var out []string
+		for _, v := range vs {
+			out = append(out, v.SemVer.String())
+		}
+		return out
+	}
+
+	if got := names(info.VersionsBetween(ParseSemVer("2.0.0"), ParseSemVer("2.1.0"))); len(got) != 2 || got[0] != "2.1.0" || got[1] != "2.0.1" {
+		t.Errorf("2.0.0 → 2.1.0: got %v, want [2.1.0 2.0.1]", got)
+	}
+	if got := names(info.VersionsBetween(ParseSemVer("2.0.0"), ParseSemVer("3.0.0"))); len(got) != 3 {
+		t.Errorf("stable target should skip pre-releases: got %v", got)
+	}
+	if got := names(info.VersionsBetween(ParseSemVer("2.1.0"), ParseSemVer("2.2.0-rc1"))); len(got) != 1 || got[0] != "2.2.0-rc1" {
+		t.Errorf("pre-release target: got %v, want [2.2.0-rc1]", got)
+	}
+	if got := info.VersionsBetween(ParseSemVer("3.0.0"), ParseSemVer("3.0.0")); len(got) != 0 {
+		t.Errorf("up to date: got %v, want none", names(got))
+	}
+}
+
+func TestMatchGitHubRelease(t *testing.T) {
+	releases := []GitHubRelease{{TagName: "v4.0.0"}, {TagName: "Serilog-v3.1.0"}, {TagName: "release/2.0.0"}, {TagName: "1.0.0"}, {TagName: "v1.0.0-preview"}}
+	for version, want := range map[string]string{
+		"4.0.0": "v4.0.0",
+		"3.1.0": "Serilog-v3.1.0",
+		"2.0.0": "release/2.0.0",
+		"1.0.0": "1.0.0",
+	} {
+		if got := matchGitHubRelease(releases, version); got == nil || got.TagName != want {
+			t.Errorf("matchGitHubRelease(%s) = %v, want %s", version, got, want)
+		}
+	}
+	if got := matchGitHubRelease(releases, "0.0.0"); got != nil {
+		t.Errorf("matchGitHubRelease(0.0.0) = %s, want nil", got.TagName)
+	}
+	if got := matchGitHubRelease(releases, "11.0.0"); got != nil {
+		t.Errorf("11.0.0 must not match a 1.0.0 tag, got %s", got.TagName)
+	}
+}
+
+func TestSiblingQueries(t *testing.T) {
+	info := &PackageInfo{Authors: NewSet[string]()}
+	info.Authors.Add("Serilog Contributors")
+	info.Authors.Add("nblumhardt")
+	got := info.SiblingQueries([]string{"serilog", "Serilog", "nblumhardt"})
+	want := []string{"owner:serilog", "owner:nblumhardt", `author:"Serilog Contributors"`, "author:nblumhardt"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("SiblingQueries = %q, want %q", got, want)
+	}
+	if got := (&PackageInfo{Authors: NewSet[string]()}).SiblingQueries(nil); len(got) != 0 {
+		t.Errorf("no owners or authors: got %q, want none", got)
+	}
+}
+
+func TestPackageInfoResolve(t *testing.T) {
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("9.0.0")},
+		{SemVer: ParseSemVer("8.0.11")},
+		{SemVer: ParseSemVer("8.0.1")},
+	}}
+	tests := []struct {
+		declared, want string
+	}{
+		{"8.*", "8.0.11"},
+		{"[8.0,9.0)", "8.0.1"},
+		{"8.0.1", "8.0.1"},
+		{"7.*", "7.*"}, // nothing matches: left as declared
+	}
+	for _, tt := range tests {
+		if got := info.Resolve(ParseSemVer(tt.declared)); got.String() != tt.want {
+			t.Errorf("Resolve(%q) = %s, want %s", tt.declared, got, tt.want)
+		}
+	}
+	var none *PackageInfo
+	if got := none.Resolve(ParseSemVer("8.*")); got.Declared() != "8.*" {
+		t.Errorf("nil info: got %s, want the declaration unchanged", got.Declared())
+	}
+}
+
+func TestLatestForFramework_Prerelease(t *testing.T) {
+	net9 := []TargetFramework{ParseTargetFramework("net9.0")}
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("10.0.0-rc.1"), Frameworks: net9},
+		{SemVer: ParseSemVer("9.1.0-preview.2")},
+		{SemVer: ParseSemVer("9.0.0")},
+	}}
+	net8 := NewSet[TargetFramework]()
+	net8.Add(ParseTargetFramework("net8.0"))
+
+	if got := info.LatestForFramework(net8, false); got == nil || got.SemVer.String() != "9.0.0" {
+		t.Errorf("stable: got %v, want 9.0.0", got)
+	}
+	if got := info.LatestForFramework(net8, true); got == nil || got.SemVer.String() != "9.1.0-preview.2" {
+		t.Errorf("with pre-releases on net8.0: got %v, want 9.1.0-preview.2 (10.0.0-rc.1 is net9.0-only)", got)
+	}
+	if got := info.Latest(true); got == nil || got.SemVer.String() != "10.0.0-rc.1" {
+		t.Errorf("latest with pre-releases: got %v, want 10.0.0-rc.1", got)
+	}
+}
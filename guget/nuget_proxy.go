@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// nugetProxy is the web proxy set in the <config> section of a NuGet.Config,
+// which dotnet uses for its own requests. Credential providers are separate
+// processes that only see environment variables, so guget hands it to them
+// as HTTP_PROXY / HTTPS_PROXY.
+type nugetProxy struct {
+	URL      string // http_proxy
+	Username string // http_proxy.user
+	Password string // http_proxy.password (DPAPI-encrypted on Windows)
+	NoProxy  string // no_proxy: comma-separated hosts that bypass the proxy
+}
+
+// proxyConfig is the subset of a NuGet.Config file that sets the proxy.
+type proxyConfig struct {
+	XMLName xml.Name        `xml:"configuration"`
+	Config  []packageSource `xml:"config>add"`
+}
+
+// proxyFromNugetConfig reads the proxy settings from a single NuGet.Config.
+func proxyFromNugetConfig(path string) nugetProxy {
+	var p nugetProxy
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p
+	}
+	var cfg proxyConfig
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return p
+	}
+	for _, c := range cfg.Config {
+		value := strings.TrimSpace(c.Value)
+		switch strings.ToLower(c.Key) {
+		case "http_proxy":
+			p.URL = expandConfigValue(value)
+		case "http_proxy.user":
+			p.Username = expandCredentialValue(value)
+		case "http_proxy.password":
+			if pass, err := decryptNuGetPassword(value); err == nil {
+				p.Password = pass
+			} else {
+				logDebug("http_proxy.password in %s: %v", path, err)
+			}
+		case "no_proxy":
+			p.NoProxy = value
+		}
+	}
+	return p
+}
+
+// merge fills the settings p leaves unset from a farther config; like every
+// other NuGet.Config key, the closest definition of each one wins.
+func (p *nugetProxy) merge(farther nugetProxy) {
+	if p.URL == "" {
+		p.URL = farther.URL
+	}
+	if p.Username == "" {
+		p.Username = farther.Username
+	}
+	if p.Password == "" {
+		p.Password = farther.Password
+	}
+	if p.NoProxy == "" {
+		p.NoProxy = farther.NoProxy
+	}
+}
+
+// proxyURL returns the proxy as a URL with its credentials, or "" when no
+// proxy is configured.
+func (p nugetProxy) proxyURL() string {
+	if p.URL == "" {
+		return ""
+	}
+	raw := p.URL
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		logWarn("Ignoring http_proxy %q: %v", p.URL, err)
+		return ""
+	}
+	if p.Username != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+	return u.String()
+}
+
+// environ returns env with the proxy variables set from p. A proxy from
+// NuGet.Config takes precedence over the environment, as it does in NuGet;
+// without one env is returned unchanged.
+func (p nugetProxy) environ(env []string) []string {
+	proxy := p.proxyURL()
+	if proxy == "" {
+		return env
+	}
+	// Both spellings: .NET reads the lowercase names first on Unix.
+	for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		env = setEnv(env, key, proxy)
+	}
+	if p.NoProxy != "" {
+		env = setEnv(env, "NO_PROXY", p.NoProxy)
+		env = setEnv(env, "no_proxy", p.NoProxy)
+	}
+	return env
+}
+
+// setEnv replaces key in env, or appends it.
+func setEnv(env []string, key, value string) []string {
+	entry := key + "=" + value
+	for i, kv := range env {
+		if strings.HasPrefix(kv, key+"=") {
+			env[i] = entry
+			return env
+		}
+	}
+	return append(env, entry)
+}
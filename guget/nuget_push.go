@@ -0,0 +1,354 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// errPushConflict is returned when the feed already has the pushed version.
+var errPushConflict = errors.New("this version already exists on the feed")
+
+// pushProgress is called as the package uploads; total is the request size.
+type pushProgress func(sent, total int64)
+
+// readNupkgIdentity reads the package id and version from the .nuspec at the
+// root of a .nupkg, so push can report what it is publishing.
+func readNupkgIdentity(file string) (id, version string, err error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return "", "", fmt.Errorf("not a valid .nupkg: %w", err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if path.Dir(f.Name) != "." || !strings.EqualFold(path.Ext(f.Name), ".nuspec") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", "", err
+		}
+		defer rc.Close()
+		var nuspec struct {
+			Metadata struct {
+				ID      string `xml:"id"`
+				Version string `xml:"version"`
+			} `xml:"metadata"`
+		}
+		if err := xml.NewDecoder(rc).Decode(&nuspec); err != nil {
+			return "", "", fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		return nuspec.Metadata.ID, nuspec.Metadata.Version, nil
+	}
+	return "", "", fmt.Errorf("not a valid .nupkg: no .nuspec at the package root")
+}
+
+// Push uploads a .nupkg to the feed's PackagePublish endpoint. apiKey may be
+// empty for feeds that authenticate with credentials alone.
+func (s *NugetService) Push(ctx context.Context, file, apiKey string, progress pushProgress) error {
+	if s.publishBase == "" {
+		return fmt.Errorf("[%s] the service index has no PackagePublish resource; this feed does not accept pushes", s.sourceName)
+	}
+
+	// The protocol expects multipart/form-data with the package as the only
+	// part. Buffering it lets the auth transport replay the body after a 401.
+	pkg, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("package", "package.nupkg")
+	if err != nil {
+		return err
+	}
+	part.Write(pkg)
+	if err := mw.Close(); err != nil {
+		return err
+	}
+	data := body.Bytes()
+	newBody := func() io.ReadCloser {
+		return io.NopCloser(&progressReader{r: bytes.NewReader(data), total: int64(len(data)), report: progress})
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.publishBase, newBody())
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	req.GetBody = func() (io.ReadCloser, error) { return newBody(), nil }
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("X-NuGet-Protocol-Version", "4.1.0")
+	if apiKey != "" {
+		req.Header.Set("X-NuGet-ApiKey", apiKey)
+	}
+
+	// Uploads can take far longer than a metadata request, so the per-request
+	// timeout does not apply; ctx bounds the push instead.
+	client := *s.client
+	client.Timeout = 0
+	logDebug("[%s] PUT %s (%s)", s.sourceName, s.publishBase, formatBytes(int64(len(data))))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	logDebug("[%s] PUT %s → %d", s.sourceName, s.publishBase, resp.StatusCode)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusConflict:
+		return errPushConflict
+	}
+	msg := pushErrorMessage(resp)
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%s: %s (check the API key and its push scope for this package)", resp.Status, msg)
+	}
+	return fmt.Errorf("%s: %s", resp.Status, msg)
+}
+
+// CanPublish reports whether the feed advertises a PackagePublish endpoint,
+// which push and delete both go through.
+func (s *NugetService) CanPublish() bool { return s.publishBase != "" }
+
+// DeleteVersion asks the feed to remove one version of a package. What that
+// means is up to the feed: nuget.org and Azure Artifacts unlist it (hidden
+// from search but still restorable), others delete it outright.
+func (s *NugetService) DeleteVersion(ctx context.Context, id, version, apiKey string) error {
+	if s.publishBase == "" {
+		return fmt.Errorf("[%s] the service index has no PackagePublish resource; this feed does not accept deletes", s.sourceName)
+	}
+	u := strings.TrimSuffix(s.publishBase, "/") + "/" + url.PathEscape(id) + "/" + url.PathEscape(version)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-NuGet-Protocol-Version", "4.1.0")
+	if apiKey != "" {
+		req.Header.Set("X-NuGet-ApiKey", apiKey)
+	}
+	logDebug("[%s] DELETE %s", s.sourceName, u)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	logDebug("[%s] DELETE %s → %d", s.sourceName, u, resp.StatusCode)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s %s is not on %s (or is already deleted)", id, version, s.sourceName)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%s: %s (check the API key and that you own %s)", resp.Status, pushErrorMessage(resp), id)
+	}
+	return fmt.Errorf("%s: %s", resp.Status, pushErrorMessage(resp))
+}
+
+// pushErrorMessage extracts the server's explanation for a failed push. Feeds
+// disagree on where it goes: nuget.org uses the status line, others a JSON or
+// plain-text body.
+func pushErrorMessage(resp *http.Response) string {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	text := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(text, "{") {
+		var body struct {
+			Message string `json:"message"`
+			Error   any    `json:"error"`
+		}
+		if json.Unmarshal(raw, &body) == nil {
+			switch e := body.Error.(type) {
+			case string:
+				if body.Message == "" {
+					body.Message = e
+				}
+			case map[string]any:
+				if m, ok := e["message"].(string); ok && body.Message == "" {
+					body.Message = m
+				}
+			}
+			if body.Message != "" {
+				return body.Message
+			}
+		}
+	}
+	if text != "" && !strings.HasPrefix(text, "<") {
+		line, _, _ := strings.Cut(text, "\n")
+		return truncate(strings.TrimSpace(line), 200)
+	}
+	// HTML error pages and empty bodies: the reason phrase is all there is.
+	if _, reason, ok := strings.Cut(resp.Status, " "); ok && reason != http.StatusText(resp.StatusCode) {
+		return reason
+	}
+	return "the server gave no reason"
+}
+
+// progressReader reports how much of a request body has been read.
+type progressReader struct {
+	r      io.Reader
+	sent   int64
+	total  int64
+	report pushProgress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.sent += int64(n)
+	if p.report != nil && n > 0 {
+		p.report(p.sent, p.total)
+	}
+	return n, err
+}
+
+// resolvePushSource maps --source to a configured source by name or URL. An
+// empty value falls back to defaultPushSource in nuget.config; a URL that is
+// not configured is used as-is.
+func resolvePushSource(projectDir, source string) (NugetSource, error) {
+	if source == "" {
+		for _, sec := range loadEffectiveNugetConfig(nugetConfigChain(projectDir)).Sections {
+			if sec.Name != "config" {
+				continue
+			}
+			for _, st := range sec.Settings {
+				if strings.EqualFold(st.Key, "defaultPushSource") {
+					source = st.Value
+				}
+			}
+		}
+		if source == "" {
+			return NugetSource{}, fmt.Errorf("no --source given and no defaultPushSource in nuget.config")
+		}
+	}
+	for _, s := range DetectSources(projectDir).Sources {
+		if strings.EqualFold(s.Name, source) || strings.TrimRight(s.URL, "/") == strings.TrimRight(source, "/") {
+			return s, nil
+		}
+	}
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return NugetSource{Name: source, URL: source}, nil
+	}
+	return NugetSource{}, fmt.Errorf("unknown source %q: not a configured source name or an http(s) URL", source)
+}
+
+// configAPIKey returns the API key stored for sourceURL in the <apikeys>
+// section of the closest nuget.config that has one. NuGet encrypts these,
+// so they can only be read on Windows.
+func configAPIKey(files []string, sourceURL string) string {
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var root xmlNode
+		if xml.Unmarshal(data, &root) != nil {
+			continue
+		}
+		for _, sec := range root.Nodes {
+			if sec.XMLName.Local != "apikeys" {
+				continue
+			}
+			for _, n := range sec.Nodes {
+				if n.XMLName.Local != "add" || strings.TrimRight(n.attr("key"), "/") != strings.TrimRight(sourceURL, "/") {
+					continue
+				}
+				key, err := decryptNuGetPassword(n.attr("value"))
+				if err != nil {
+					logWarn("Can't read the API key for %s from %s: %v", sourceURL, file, err)
+					return ""
+				}
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// resolveAPIKey picks the API key for publishing to sourceURL: the explicit
+// value, then the key saved in nuget.config.
+func resolveAPIKey(projectDir, sourceURL, explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if key := configAPIKey(nugetConfigChain(projectDir), sourceURL); key != "" {
+		return key
+	}
+	if parseADOFeedURL(sourceURL) != nil {
+		// Azure Artifacts authenticates with credentials but still requires
+		// the header to be present; any value works.
+		return "AzureDevOps"
+	}
+	return ""
+}
+
+// runPushCommand publishes a .nupkg to a package source.
+func runPushCommand(flags BuiltFlags) int {
+	if flags.ReadOnly {
+		logError("guget push cannot run with --read-only")
+		return exitError
+	}
+	if flags.PackageFile == "" {
+		logError("Usage: guget push <package.nupkg> --source <name or URL> [--api-key <key>]")
+		return exitError
+	}
+	id, ver, err := readNupkgIdentity(flags.PackageFile)
+	if err != nil {
+		logError("%s: %v", flags.PackageFile, err)
+		return exitError
+	}
+	source, err := resolvePushSource(flags.ProjectDir, flags.Source)
+	if err != nil {
+		logError("%v", err)
+		return exitError
+	}
+
+	apiKey := resolveAPIKey(flags.ProjectDir, source.URL, flags.APIKey)
+	svc, err := NewNugetService(source)
+	if err != nil {
+		logError("[%s] %v", source.Name, err)
+		return exitError
+	}
+
+	name := filepath.Base(flags.PackageFile)
+	if id != "" {
+		name = id + " " + ver
+	}
+	var progress pushProgress
+	if !flags.Quiet {
+		lastPct := -1
+		progress = func(sent, total int64) {
+			if pct := int(sent * 100 / max(total, 1)); pct != lastPct {
+				lastPct = pct
+				fmt.Fprintf(os.Stderr, "\rPushing %s to %s: %3d%% (%s / %s)", name, source.Name, pct, formatBytes(sent), formatBytes(total))
+			}
+		}
+	}
+	err = svc.Push(context.Background(), flags.PackageFile, apiKey, progress)
+	if progress != nil {
+		fmt.Fprintln(os.Stderr)
+	}
+	if errors.Is(err, errPushConflict) {
+		logError("%s was not pushed to %s: %v", name, source.Name, err)
+		return exitError
+	}
+	if err != nil {
+		logError("Pushing %s to %s failed: %v", name, source.Name, err)
+		return exitError
+	}
+	fmt.Printf("Pushed %s to %s\n", name, source.Name)
+	return exitOK
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestNupkg(t *testing.T, id, version string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), id+"."+version+".nupkg")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create(id + ".nuspec")
+	fmt.Fprintf(w, `<?xml version="1.0"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata><id>%s</id><version>%s</version></metadata>
+</package>`, id, version)
+	zw.Create("lib/net8.0/" + id + ".dll")
+	zw.Close()
+	f.Close()
+	return file
+}
+
+func TestReadNupkgIdentity(t *testing.T) {
+	id, ver, err := readNupkgIdentity(writeTestNupkg(t, "Contoso.Utils", "1.2.3"))
+	if err != nil || id != "Contoso.Utils" || ver != "1.2.3" {
+		t.Errorf("got %q %q %v, want Contoso.Utils 1.2.3", id, ver, err)
+	}
+	notZip := filepath.Join(t.TempDir(), "bad.nupkg")
+	os.WriteFile(notZip, []byte("nope"), 0644)
+	if _, _, err := readNupkgIdentity(notZip); err == nil {
+		t.Error("expected an error for a file that is not a zip")
+	}
+}
+
+func TestNugetServicePush(t *testing.T) {
+	var status int
+	var reason string
+	var gotKey, gotFile string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprintf(w, `{"resources":[{"@id":%q,"@type":"RegistrationsBaseUrl/3.6.0"},{"@id":%q,"@type":"PackagePublish/2.0.0"}]}`,
+				srv.URL+"/reg/", srv.URL+"/api/v2/package")
+			return
+		}
+		if r.Method != http.MethodPut || r.URL.Path != "/api/v2/package" {
+			http.NotFound(w, r)
+			return
+		}
+		gotKey = r.Header.Get("X-NuGet-ApiKey")
+		if file, _, err := r.FormFile("package"); err == nil {
+			data, _ := io.ReadAll(file)
+			gotFile = string(data)
+		}
+		if reason != "" {
+			http.Error(w, reason, status)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	svc, err := NewNugetService(NugetSource{Name: "feed", URL: srv.URL + "/index.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := writeTestNupkg(t, "Contoso.Utils", "1.2.3")
+	want, _ := os.ReadFile(pkg)
+
+	status = http.StatusCreated
+	var sent, total int64
+	err = svc.Push(context.Background(), pkg, "secret", func(s, tot int64) { sent, total = s, tot })
+	if err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if gotKey != "secret" || gotFile != string(want) {
+		t.Errorf("server got key %q and %d bytes, want the API key and the package", gotKey, len(gotFile))
+	}
+	if total == 0 || sent != total {
+		t.Errorf("progress ended at %d/%d, want the whole body", sent, total)
+	}
+
+	status = http.StatusConflict
+	if err := svc.Push(context.Background(), pkg, "secret", nil); !errors.Is(err, errPushConflict) {
+		t.Errorf("409: got %v, want errPushConflict", err)
+	}
+
+	status, reason = http.StatusForbidden, "The specified API key is invalid, has expired, or does not have permission to access the specified package."
+	if err := svc.Push(context.Background(), pkg, "wrong", nil); err == nil || !strings.Contains(err.Error(), "API key is invalid") {
+		t.Errorf("403: got %v, want the server's message", err)
+	}
+}
+
+func TestNugetServicePush_NoPublishResource(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"resources":[{"@id":%q,"@type":"RegistrationsBaseUrl/3.6.0"}]}`, srv.URL+"/reg/")
+	}))
+	defer srv.Close()
+
+	svc, err := NewNugetService(NugetSource{Name: "feed", URL: srv.URL + "/index.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Push(context.Background(), writeTestNupkg(t, "A", "1.0.0"), "", nil); err == nil || !strings.Contains(err.Error(), "PackagePublish") {
+		t.Errorf("got %v, want an error naming the missing PackagePublish resource", err)
+	}
+}
+
+func TestPushErrorMessage(t *testing.T) {
+	tests := []struct {
+		status string
+		body   string
+		want   string
+	}{
+		{"400 Bad Request", `{"error":{"message":"Package is invalid"}}`, "Package is invalid"},
+		{"400 Bad Request", "Version 1.0 is not a valid SemVer\nmore", "Version 1.0 is not a valid SemVer"},
+		{"409 A package with this version already exists", "<html>conflict</html>", "A package with this version already exists"},
+		{"500 Internal Server Error", "", "the server gave no reason"},
+	}
+	for _, tt := range tests {
+		code := 0
+		fmt.Sscanf(tt.status, "%d", &code)
+		resp := &http.Response{Status: tt.status, StatusCode: code, Body: io.NopCloser(strings.NewReader(tt.body))}
+		if got := pushErrorMessage(resp); got != tt.want {
+			t.Errorf("pushErrorMessage(%q, %q) = %q, want %q", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestNugetServiceDeleteVersion(t *testing.T) {
+	var gotMethod, gotPath, gotKey string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprintf(w, `{"resources":[{"@id":%q,"@type":"RegistrationsBaseUrl/3.6.0"},{"@id":%q,"@type":"PackagePublish/2.0.0"}]}`,
+				srv.URL+"/reg/", srv.URL+"/api/v2/package/")
+			return
+		}
+		gotMethod, gotPath, gotKey = r.Method, r.URL.Path, r.Header.Get("X-NuGet-ApiKey")
+		if strings.HasSuffix(r.URL.Path, "/9.9.9") {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	svc, err := NewNugetService(NugetSource{Name: "feed", URL: srv.URL + "/index.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !svc.CanPublish() {
+		t.Fatal("CanPublish() = false with a PackagePublish resource")
+	}
+	if err := svc.DeleteVersion(context.Background(), "Contoso.Utils", "1.2.3", "secret"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if gotMethod != http.MethodDelete || gotPath != "/api/v2/package/Contoso.Utils/1.2.3" || gotKey != "secret" {
+		t.Errorf("server got %s %s key %q", gotMethod, gotPath, gotKey)
+	}
+	if err := svc.DeleteVersion(context.Background(), "Contoso.Utils", "9.9.9", "secret"); err == nil || !strings.Contains(err.Error(), "not on feed") {
+		t.Errorf("404: got %v, want a not-found error", err)
+	}
+}
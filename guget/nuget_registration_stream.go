@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Registration indexes from some internal feeds inline every page, which can
+// add up to several megabytes of catalog entries. Decoding them into a
+// registrationIndex keeps every description, author list and tag set alive at
+// once; the functions below walk the JSON with a token stream instead and hand
+// each leaf to a callback, so only one catalog entry is materialised at a time.
+
+// decodeRegistrationIndex streams an index document, calling visit for every
+// inlined leaf. Pages whose items are not inlined are returned so the caller
+// can fetch them.
+func decodeRegistrationIndex(r io.Reader, visit func(*registrationLeaf)) ([]registrationPage, error) {
+	dec := json.NewDecoder(r)
+	var pending []registrationPage
+	err := walkObject(dec, func(key string) error {
+		if key != "items" {
+			return skipValue(dec)
+		}
+		return walkArray(dec, func() error {
+			page, inlined, err := decodePage(dec, visit)
+			if err != nil {
+				return err
+			}
+			if !inlined {
+				pending = append(pending, page)
+			}
+			return nil
+		})
+	})
+	return pending, err
+}
+
+// decodeRegistrationPage streams a separately fetched page document.
+func decodeRegistrationPage(r io.Reader, visit func(*registrationLeaf)) error {
+	_, _, err := decodePage(json.NewDecoder(r), visit)
+	return err
+}
+
+// decodePage reads one page object, visiting its leaves. inlined reports
+// whether the page carried any items.
+func decodePage(dec *json.Decoder, visit func(*registrationLeaf)) (page registrationPage, inlined bool, err error) {
+	err = walkObject(dec, func(key string) error {
+		switch key {
+		case "@id":
+			return dec.Decode(&page.ID)
+		case "lower":
+			return dec.Decode(&page.Lower)
+		case "upper":
+			return dec.Decode(&page.Upper)
+		case "items":
+			return walkArray(dec, func() error {
+				var w registrationLeafWrapper
+				if err := dec.Decode(&w); err != nil {
+					return err
+				}
+				inlined = true
+				visit(&w.CatalogEntry)
+				return nil
+			})
+		}
+		return skipValue(dec)
+	})
+	return page, inlined, err
+}
+
+// walkObject consumes a JSON object, calling field for each key. field must
+// consume the key's value. A null object is treated as empty.
+func walkObject(dec *json.Decoder, field func(key string) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("expected object, got %v", tok)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected object key, got %v", tok)
+		}
+		if err := field(key); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token() // '}'
+	return err
+}
+
+// walkArray consumes a JSON array, calling elem once per element. elem must
+// consume the element. A null array is treated as empty.
+func walkArray(dec *json.Decoder, elem func() error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("expected array, got %v", tok)
+	}
+	for dec.More() {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token() // ']'
+	return err
+}
+
+// skipValue consumes and discards the next JSON value.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testRegistrationIndex = `{
+  "@id": "https://example.test/reg/foo/index.json",
+  "count": 2,
+  "items": [
+    {
+      "@id": "https://example.test/reg/foo/page/1.0.0/1.1.0.json",
+      "lower": "1.0.0",
+      "upper": "1.1.0",
+      "items": [
+        {"@id": "x", "catalogEntry": {"id": "Foo", "version": "1.0.0", "description": "first", "tags": ["a", "b"],
+          "dependencyGroups": [{"targetFramework": ".NETStandard2.0", "dependencies": [{"id": "Bar", "range": "[1.0.0, )"}]}]}},
+        {"@id": "y", "catalogEntry": {"id": "Foo", "version": "1.1.0", "vulnerabilities": [{"advisoryUrl": "https://example.test/adv", "severity": "2"}]}}
+      ]
+    },
+    {
+      "@id": "https://example.test/reg/foo/page/2.0.0/3.0.0.json",
+      "lower": "2.0.0",
+      "upper": "3.0.0"
+    }
+  ],
+  "context": {"@vocab": "https://schema.nuget.org/schema#", "nested": [[1, 2], {"k": null}]}
+}`
+
+func TestDecodeRegistrationIndex_StreamsLeavesAndReturnsPendingPages(t *testing.T) {
+	var leaves []registrationLeaf
+	pending, err := decodeRegistrationIndex(strings.NewReader(testRegistrationIndex), func(ce *registrationLeaf) {
+		leaves = append(leaves, *ce)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leaves) != 2 || leaves[0].Version != "1.0.0" || leaves[1].Version != "1.1.0" {
+		t.Fatalf("leaves = %+v", leaves)
+	}
+	if len(leaves[0].DependencyGroups) != 1 || leaves[0].DependencyGroups[0].Dependencies[0].ID != "Bar" {
+		t.Errorf("dependency groups not decoded: %+v", leaves[0].DependencyGroups)
+	}
+	if len(leaves[1].Vulnerabilities) != 1 || leaves[1].Vulnerabilities[0].Severity != 2 {
+		t.Errorf("vulnerabilities not decoded: %+v", leaves[1].Vulnerabilities)
+	}
+	if len(pending) != 1 || pending[0].ID != "https://example.test/reg/foo/page/2.0.0/3.0.0.json" || pending[0].Upper != "3.0.0" {
+		t.Errorf("pending = %+v", pending)
+	}
+}
+
+func TestDecodeRegistrationPage(t *testing.T) {
+	page := `{"@id": "p", "count": 1, "items": [{"catalogEntry": {"id": "Foo", "version": "2.0.0"}}], "parent": "idx"}`
+	var versions []string
+	if err := decodeRegistrationPage(strings.NewReader(page), func(ce *registrationLeaf) {
+		versions = append(versions, ce.Version)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions[0] != "2.0.0" {
+		t.Errorf("versions = %v", versions)
+	}
+}
+
+func TestDecodeRegistrationIndex_Malformed(t *testing.T) {
+	for _, doc := range []string{`[]`, `{"items": {}}`, `{"items": [`} {
+		if _, err := decodeRegistrationIndex(strings.NewReader(doc), func(*registrationLeaf) {}); err == nil {
+			t.Errorf("expected an error for %q", doc)
+		}
+	}
+}
+
+func TestDecodeRegistrationPage_VersionDeprecation(t *testing.T) {
+	page := `{"items": [
+		{"catalogEntry": {"id": "Foo", "version": "1.0.0", "deprecation": {"reasons": ["Legacy", "CriticalBugs"], "message": "Use Bar", "alternatePackage": {"id": "Bar"}}}},
+		{"catalogEntry": {"id": "Foo", "version": "2.0.0"}}
+	]}`
+	var deps []*VersionDeprecation
+	if err := decodeRegistrationPage(strings.NewReader(page), func(ce *registrationLeaf) {
+		deps = append(deps, ce.Deprecation.versionDeprecation())
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 2 || deps[0] == nil || deps[1] != nil {
+		t.Fatalf("deprecations = %+v", deps)
+	}
+	if got := deps[0].ReasonLabel(); got != "legacy, critical bugs" {
+		t.Errorf("ReasonLabel() = %q", got)
+	}
+	if deps[0].Message != "Use Bar" || deps[0].AlternatePackageID != "Bar" {
+		t.Errorf("deprecation = %+v", deps[0])
+	}
+	if got := (VersionDeprecation{}).ReasonLabel(); got != "deprecated" {
+		t.Errorf("empty ReasonLabel() = %q", got)
+	}
+}
@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 )
 
 type serviceIndex struct {
@@ -55,6 +60,7 @@ type SearchResult struct {
 	Version        string          `json:"version"` // latest stable
 	Description    string          `json:"description"`
 	Authors        StringOrArray   `json:"authors"`
+	Owners         StringOrArray   `json:"owners"`
 	Tags           StringOrArray   `json:"tags"`
 	TotalDownloads int             `json:"totalDownloads"`
 	Verified       bool            `json:"verified"`
@@ -91,6 +97,37 @@ type PackageVersion struct {
 	Frameworks       []TargetFramework      // target frameworks this version supports
 	Vulnerabilities  []PackageVulnerability // CVE advisories for this specific version
 	DependencyGroups []dependencyGroup      // declared dependencies, for dep tree overlay
+	License          string                 // SPDX expression or license URL; "" if none
+	Deprecation      *VersionDeprecation    // nil unless this specific version is deprecated
+}
+
+// VersionDeprecation is the deprecation NuGet records for a single version.
+type VersionDeprecation struct {
+	Reasons            []string // e.g. "Legacy", "CriticalBugs", "Other"
+	Message            string
+	AlternatePackageID string
+}
+
+// ReasonLabel joins the deprecation reasons into readable text, e.g.
+// "legacy, critical bugs"; "deprecated" when NuGet gives none.
+func (d VersionDeprecation) ReasonLabel() string {
+	var labels []string
+	for _, r := range d.Reasons {
+		var b strings.Builder
+		for i, c := range r {
+			if i > 0 && unicode.IsUpper(c) {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(unicode.ToLower(c))
+		}
+		if b.Len() > 0 {
+			labels = append(labels, b.String())
+		}
+	}
+	if len(labels) == 0 {
+		return "deprecated"
+	}
+	return strings.Join(labels, ", ")
 }
 
 // PackageInfo is the full picture of a package.
@@ -104,22 +141,20 @@ type PackageInfo struct {
 	RepositoryType     string           // e.g. "git"
 	RepositoryURL      string           // e.g. "https://github.com/owner/repo"
 	Versions           []PackageVersion // sorted newest → oldest
+	TrimmedVersions    int              // versions dropped by trimVersions; refetchAllVersions restores them
 	Deprecated         bool
 	DeprecationMessage string
 	AlternatePackageID string
 	NugetOrgURL        string // set when package exists on nuget.org (even if found via another source)
 }
 
-// registrationIndex is returned by the RegistrationsBaseUrl endpoint.
-type registrationIndex struct {
-	Items []registrationPage `json:"items"`
-}
-
+// registrationPage is one entry of the RegistrationsBaseUrl index. Its leaves
+// are streamed by decodeRegistrationIndex rather than stored here; pages that
+// are not inlined are fetched from ID.
 type registrationPage struct {
-	ID    string                    `json:"@id"`
-	Items []registrationLeafWrapper `json:"items"` // nil if not inlined, must fetch page URL
-	Lower string                    `json:"lower"`
-	Upper string                    `json:"upper"`
+	ID    string `json:"@id"`
+	Lower string `json:"lower"`
+	Upper string `json:"upper"`
 }
 
 type registrationLeafWrapper struct {
@@ -139,6 +174,8 @@ type registrationLeaf struct {
 	DependencyGroups []dependencyGroup      `json:"dependencyGroups"`
 	Vulnerabilities  []PackageVulnerability `json:"vulnerabilities"`
 	Deprecation      *deprecationRaw        `json:"deprecation"`
+	LicenseExpr      string                 `json:"licenseExpression"`
+	LicenseURL       string                 `json:"licenseUrl"`
 }
 
 type repositoryMeta struct {
@@ -184,126 +221,16 @@ type deprecationRaw struct {
 	} `json:"alternatePackage"`
 }
 
-// authTransport injects Basic Auth and retries on 401 via credential providers.
-type authTransport struct {
-	base       http.RoundTripper
-	sourceURL  string
-	sourceName string
-	mu         sync.Mutex
-	username   string
-	password   string
-	provOnce   sync.Once // ensures the credential provider is invoked at most once
-	retried    bool      // true after a cache-clear retry has been attempted
-}
-
-func newAuthTransport(source NugetSource) *authTransport {
-	return &authTransport{
-		base:       http.DefaultTransport,
-		sourceURL:  source.URL,
-		sourceName: source.Name,
-		username:   source.Username,
-		password:   source.Password,
-	}
-}
-
-func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	t.mu.Lock()
-	user, pass := t.username, t.password
-	t.mu.Unlock()
-
-	// Clone so we never mutate the caller's request.
-	req = req.Clone(req.Context())
-	if user != "" || pass != "" {
-		logTrace("[%s] sending Basic Auth (username=%q, password=%d chars)", t.sourceName, user, len(pass))
-		req.SetBasicAuth(user, pass)
-	} else {
-		logTrace("[%s] no credentials available, sending unauthenticated request", t.sourceName)
-	}
-
-	resp, err := t.base.RoundTrip(req)
-	if err != nil || resp.StatusCode != http.StatusUnauthorized {
-		return resp, err
-	}
-
-	// 401 — ask a credential provider (once per transport lifetime).
-	logTrace("[%s] got 401, invoking credential provider", t.sourceName)
-	resp.Body.Close()
-
-	var providerCred *sourceCredential
-	t.provOnce.Do(func() {
-		cred, provErr := fetchFromCredentialProvider(t.sourceURL, t.sourceName, false)
-		if provErr != nil {
-			logDebug("[%s] credential provider: %v", t.sourceName, provErr)
-			return
-		}
-		t.mu.Lock()
-		t.username = cred.Username
-		t.password = cred.Password
-		t.mu.Unlock()
-		providerCred = cred
-	})
-
-	if providerCred == nil {
-		// Provider not available or already tried and failed — surface the 401.
-		return &http.Response{
-			StatusCode: http.StatusUnauthorized,
-			Status:     "401 Unauthorized",
-			Body:       http.NoBody,
-			Header:     make(http.Header),
-		}, nil
-	}
-
-	// Retry with the provider-supplied credentials.
-	resp2, err2 := t.doAuthenticatedRequest(req, providerCred)
-	if err2 != nil || resp2.StatusCode != http.StatusUnauthorized {
-		return resp2, err2
-	}
-
-	// Still 401 — the cached token may be stale. Clear the credential provider
-	// cache, re-invoke with IsRetry=true to force a fresh token, and try once more.
-	t.mu.Lock()
-	alreadyRetried := t.retried
-	t.retried = true
-	t.mu.Unlock()
-
-	if alreadyRetried {
-		return resp2, nil
-	}
-
-	logDebug("[%s] provider credentials returned 401, clearing cache and retrying", t.sourceName)
-	resp2.Body.Close()
-	clearCredentialProviderCache()
-
-	cred, provErr := fetchFromCredentialProvider(t.sourceURL, t.sourceName, true)
-	if provErr != nil {
-		logDebug("[%s] credential provider retry: %v", t.sourceName, provErr)
-		return &http.Response{
-			StatusCode: http.StatusUnauthorized,
-			Status:     "401 Unauthorized",
-			Body:       http.NoBody,
-			Header:     make(http.Header),
-		}, nil
-	}
-
-	t.mu.Lock()
-	t.username = cred.Username
-	t.password = cred.Password
-	t.mu.Unlock()
-
-	return t.doAuthenticatedRequest(req, cred)
-}
-
-// doAuthenticatedRequest creates a new request with Basic Auth and sends it.
-func (t *authTransport) doAuthenticatedRequest(origReq *http.Request, cred *sourceCredential) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(origReq.Context(), origReq.Method, origReq.URL.String(), nil)
-	if err != nil {
-		return nil, err
+// versionDeprecation converts the raw catalog deprecation; nil stays nil.
+func (d *deprecationRaw) versionDeprecation() *VersionDeprecation {
+	if d == nil {
+		return nil
 	}
-	for k, v := range origReq.Header {
-		req.Header[k] = v
+	return &VersionDeprecation{
+		Reasons:            d.Reasons,
+		Message:            d.Message,
+		AlternatePackageID: d.AlternatePackage.ID,
 	}
-	req.SetBasicAuth(cred.Username, cred.Password)
-	return t.base.RoundTrip(req)
 }
 
 // adoFeedResponse is the response from the Get Feed API.
@@ -319,17 +246,24 @@ type adoUpstreamSource struct {
 	Status   string `json:"status"`
 }
 
-// NugetService talks to a single NuGet v3 feed.
+// NugetService talks to a single NuGet feed: a v3 feed through its service
+// index, or a v2 (OData) feed when v2Base is set.
 type NugetService struct {
-	sourceURL      string
-	sourceName     string
-	client         *http.Client
-	searchBase     string   // resolved from service index
-	regBase        string   // RegistrationsBaseUrl
-	flatBase       string   // PackageBaseAddress (flat container for .nupkg/.nuspec)
-	detailTemplate string   // PackageDetailsUriTemplate (e.g. "https://.../packages/{id}/{version}")
-	adoSearchBase  string   // Azure DevOps REST API base (faster alternative to SearchQueryService)
-	adoUpstreams   []string // public NuGet upstream source URLs discovered from ADO feed config
+	sourceURL        string
+	sourceName       string
+	client           *http.Client
+	searchBase       string   // resolved from service index
+	regBase          string   // RegistrationsBaseUrl
+	flatBase         string   // PackageBaseAddress (flat container for .nupkg/.nuspec)
+	detailTemplate   string   // PackageDetailsUriTemplate (e.g. "https://.../packages/{id}/{version}")
+	publishBase      string   // PackagePublish (push endpoint), "" for read-only feeds
+	adoSearchBase    string   // Azure DevOps REST API base (faster alternative to SearchQueryService)
+	adoUpstreams     []string // public NuGet upstream source URLs discovered from ADO feed config
+	movedTo          string   // where the service index permanently redirects, if it does
+	movedTemporarily bool     // a redirect hop was temporary, so movedTo is not trustworthy
+	v2Base           string   // OData root of a v2 feed; "" for v3 feeds
+	auth             *authTransport
+	health           *sourceHealth
 
 	// upstreamSearchBases caches the resolved SearchQueryService URL for each
 	// upstream source index, avoiding re-fetching the service index on every search.
@@ -587,19 +521,91 @@ func projectOrRepoURL(leaf *registrationLeaf) string {
 	return ""
 }
 
+// searchPrerelease makes searches return pre-release versions. Set from
+// --prerelease and toggled in the TUI via setSearchPrerelease; atomic since
+// searches run on background goroutines.
+var searchPrerelease atomic.Bool
+
+func setSearchPrerelease(on bool) { searchPrerelease.Store(on) }
+
+// requestTimeout bounds every HTTP request made to a package source or the
+// GitHub API. Set from --timeout via setRequestTimeout.
+var requestTimeout = 15 * time.Second
+
+func setRequestTimeout(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	requestTimeout = d
+	githubClient.Timeout = d
+}
+
 // NewNugetService creates and initialises a service for the given NugetSource.
 func NewNugetService(source NugetSource) (*NugetService, error) {
+	health := &sourceHealth{}
+	auth := newAuthTransport(source)
+	auth.base = &healthTransport{base: auth.base, health: health}
+	var transport http.RoundTripper = auth
+	if responseCache != nil {
+		transport = &cachingTransport{base: transport, cache: responseCache}
+	}
 	svc := &NugetService{
 		sourceURL:  source.URL,
 		sourceName: source.Name,
-		client:     &http.Client{Transport: newAuthTransport(source), Timeout: 15 * time.Second},
+		client:     &http.Client{Transport: transport, Timeout: requestTimeout},
+		auth:       auth,
+		health:     health,
+	}
+	svc.client.CheckRedirect = svc.noteRedirect
+	if source.ProtocolVersion == "2" || isV2SourceURL(source.URL) {
+		if err := svc.resolveV2(); err != nil {
+			return nil, err
+		}
+	} else if err := svc.resolveEndpoints(); err != nil {
+		// Old TeamCity, ProGet and NuGet.Server feeds have no service index
+		// but may still answer the v2 API at the same URL. A URL naming an
+		// index.json is v3 whatever went wrong.
+		if strings.HasSuffix(strings.ToLower(source.URL), ".json") {
+			return nil, err
+		}
+		if v2err := svc.resolveV2(); v2err != nil {
+			logTrace("[%s] not a v2 feed either: %v", svc.sourceName, v2err)
+			return nil, err
+		}
+		logDebug("[%s] no v3 service index (%v); using the v2 API", svc.sourceName, err)
 	}
-	if err := svc.resolveEndpoints(); err != nil {
-		return nil, err
+	if svc.movedTo != "" {
+		logWarn("[%s] service index moved permanently to %s; the sources overlay (s) can update nuget.config", svc.sourceName, svc.movedTo)
 	}
 	return svc, nil
 }
 
+// noteRedirect follows redirects like the default policy, remembering where
+// the service index ends up when every hop is permanent (301/308) so the
+// source URL can be migrated.
+func (s *NugetService) noteRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if via[0].URL.String() != s.sourceURL {
+		return nil
+	}
+	if len(via) == 1 {
+		s.movedTo, s.movedTemporarily = "", false
+	}
+	code := req.Response.StatusCode
+	if code != http.StatusMovedPermanently && code != http.StatusPermanentRedirect {
+		s.movedTo, s.movedTemporarily = "", true
+	}
+	if !s.movedTemporarily {
+		s.movedTo = req.URL.String()
+	}
+	return nil
+}
+
+// MovedTo returns the URL the service index permanently redirects to, or "".
+func (s *NugetService) MovedTo() string { return s.movedTo }
+
 func (s *NugetService) resolveEndpoints() error {
 	var idx serviceIndex
 	if err := s.getJSON(s.sourceURL, &idx); err != nil {
@@ -623,6 +629,8 @@ func (s *NugetService) resolveEndpoints() error {
 			s.flatBase = strings.TrimSuffix(r.ID, "/")
 		case strings.HasPrefix(r.Type, "PackageDetailsUriTemplate"):
 			s.detailTemplate = r.ID
+		case strings.HasPrefix(r.Type, "PackagePublish"):
+			s.publishBase = r.ID
 		}
 	}
 	if s.searchBase == "" {
@@ -671,6 +679,10 @@ func (s *NugetService) resolveEndpoints() error {
 // For Azure DevOps feeds, it uses the ADO REST API which is significantly
 // faster than the NuGet SearchQueryService (query2) endpoint.
 func (s *NugetService) Search(query string, take int) ([]SearchResult, error) {
+	if s.v2Base != "" {
+		results, _, err := s.searchV2(query, 0, take, false)
+		return results, err
+	}
 	if s.adoSearchBase != "" {
 		return s.searchADO(query, take)
 	}
@@ -678,7 +690,7 @@ func (s *NugetService) Search(query string, take int) ([]SearchResult, error) {
 	params := url.Values{}
 	params.Set("q", query)
 	params.Set("take", strconv.Itoa(take))
-	params.Set("prerelease", "false")
+	params.Set("prerelease", strconv.FormatBool(searchPrerelease.Load()))
 	params.Set("semVerLevel", "2.0.0")
 	var resp searchResponse
 	if err := s.getJSON(s.searchBase+"?"+params.Encode(), &resp); err != nil {
@@ -688,6 +700,57 @@ func (s *NugetService) Search(query string, take int) ([]SearchResult, error) {
 	return resp.Data, nil
 }
 
+// Owners returns the accounts that own packageID on this source. Only the
+// search API reports owners; registration metadata has authors alone.
+func (s *NugetService) Owners(packageID string) ([]string, error) {
+	if s.v2Base != "" {
+		return nil, nil // the v2 API has no owners
+	}
+	results, err := s.Search("packageid:"+packageID, 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		if strings.EqualFold(r.ID, packageID) {
+			return r.Owners, nil
+		}
+	}
+	return nil, nil
+}
+
+// SiblingQueries returns search queries that find other packages from the
+// same publisher: one per owner account, then one per author.
+func (p *PackageInfo) SiblingQueries(owners []string) []string {
+	seen := NewSet[string]()
+	var queries []string
+	add := func(field, value string) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return
+		}
+		if strings.ContainsAny(value, " \t") {
+			value = strconv.Quote(value)
+		}
+		q := field + ":" + value
+		if !seen.Contains(strings.ToLower(q)) {
+			seen.Add(strings.ToLower(q))
+			queries = append(queries, q)
+		}
+	}
+	for _, o := range owners {
+		add("owner", o)
+	}
+	authors := make([]string, 0, p.Authors.Len())
+	for a := range p.Authors {
+		authors = append(authors, a)
+	}
+	sort.Strings(authors)
+	for _, a := range authors {
+		add("author", a)
+	}
+	return queries
+}
+
 // searchADO uses the Azure DevOps REST API for package search, which is
 // dramatically faster than the NuGet SearchQueryService on ADO feeds.
 // When the feed has public NuGet upstream sources (e.g. nuget.org), those
@@ -747,6 +810,71 @@ func (s *NugetService) searchADO(query string, take int) ([]SearchResult, error)
 	return merged, nil
 }
 
+// Browse lists one page of the feed's own packages for the feed browser.
+// query uses the search syntax: "" lists the most downloaded first, and
+// "tags:x" or "owner:x" filter. Unlike Search it never fans out to upstream
+// sources. Azure DevOps feeds list their packages through the REST API, which
+// cannot filter by tag or owner, so filtered queries there use the slower
+// SearchQueryService.
+func (s *NugetService) Browse(query string, skip, take int) ([]SearchResult, int, error) {
+	if s.v2Base != "" {
+		return s.searchV2(query, skip, take, true)
+	}
+	if s.adoSearchBase != "" && query == "" {
+		results, err := s.browseADOLocal(skip, take)
+		// The REST API does not report a total; assume more while pages are full.
+		total := skip + len(results)
+		if len(results) == take {
+			total++
+		}
+		return results, total, err
+	}
+	if s.searchBase == "" {
+		return nil, 0, fmt.Errorf("[%s] SearchQueryService not found in service index — browsing unavailable", s.sourceName)
+	}
+	logDebug("[%s] browse query=%q skip=%d take=%d", s.sourceName, query, skip, take)
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("skip", strconv.Itoa(skip))
+	params.Set("take", strconv.Itoa(take))
+	params.Set("prerelease", strconv.FormatBool(searchPrerelease.Load()))
+	params.Set("semVerLevel", "2.0.0")
+	var resp searchResponse
+	if err := s.getJSON(s.searchBase+"?"+params.Encode(), &resp); err != nil {
+		return nil, 0, err
+	}
+	for i := range resp.Data {
+		resp.Data[i].Source = s.sourceName
+	}
+	return resp.Data, int(resp.TotalHits), nil
+}
+
+// browseADOLocal pages through the packages stored in an Azure DevOps feed.
+func (s *NugetService) browseADOLocal(skip, take int) ([]SearchResult, error) {
+	// Build URL manually — see searchADOLocal.
+	browseURL := s.adoSearchBase +
+		"?$top=" + strconv.Itoa(take) +
+		"&$skip=" + strconv.Itoa(skip) +
+		"&includeDescription=true" +
+		"&api-version=7.1-preview.1"
+	var resp adoPackageResponse
+	if err := s.getJSON(browseURL, &resp); err != nil {
+		return nil, fmt.Errorf("ADO REST API browse: %w", err)
+	}
+	results := make([]SearchResult, 0, len(resp.Value))
+	for _, pkg := range resp.Value {
+		r := SearchResult{ID: pkg.Name, Description: pkg.Description, Source: s.sourceName}
+		for _, v := range pkg.Versions {
+			r.Versions = append(r.Versions, searchVersion{Version: v.Version})
+		}
+		if len(r.Versions) > 0 {
+			r.Version = r.Versions[0].Version
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
 // searchADOLocal searches the ADO REST API for packages cached in the feed.
 func (s *NugetService) searchADOLocal(query string, take int) ([]SearchResult, error) {
 	// Build URL manually — url.Values.Encode() would percent-encode the "$"
@@ -798,7 +926,7 @@ func (s *NugetService) searchUpstream(serviceIndexURL, query string, take int) (
 	params := url.Values{}
 	params.Set("q", query)
 	params.Set("take", strconv.Itoa(take))
-	params.Set("prerelease", "false")
+	params.Set("prerelease", strconv.FormatBool(searchPrerelease.Load()))
 	params.Set("semVerLevel", "2.0.0")
 
 	req, err := http.NewRequest("GET", searchBase+"?"+params.Encode(), nil)
@@ -869,76 +997,90 @@ func (s *NugetService) resolveUpstreamSearchBase(serviceIndexURL string) (string
 // feed types (e.g. Azure DevOps returns HTTP 500 from its search endpoint for
 // packages not in the feed, whereas the registration endpoint returns 404).
 func (s *NugetService) SearchExact(packageID string) (*PackageInfo, error) {
+	info, err := s.searchExact(context.Background(), packageID)
+	s.health.lookup(err)
+	return info, err
+}
+
+// RefreshExact is SearchExact without the on-disk cache's TTL: every
+// response is revalidated with the source.
+func (s *NugetService) RefreshExact(packageID string) (*PackageInfo, error) {
+	info, err := s.searchExact(withCacheBypass(context.Background()), packageID)
+	s.health.lookup(err)
+	return info, err
+}
+
+func (s *NugetService) searchExact(ctx context.Context, packageID string) (*PackageInfo, error) {
+	if s.v2Base != "" {
+		return s.searchExactV2(ctx, packageID)
+	}
 	searchStart := time.Now()
 	logDebug("[%s] looking up %q via registration index", s.sourceName, packageID)
 	regURL := fmt.Sprintf("%s%s/index.json", s.regBase, strings.ToLower(packageID))
 
-	var regIdx registrationIndex
-	if err := s.getJSON(regURL, &regIdx); err != nil {
+	var versions []PackageVersion
+	var latestLeaf *registrationLeaf       // newest version overall (for fallback metadata)
+	var latestStableLeaf *registrationLeaf // newest stable version (preferred for metadata)
+
+	// Leaves are streamed one at a time; only the two metadata leaves above
+	// are retained, everything else is reduced to a PackageVersion.
+	visit := func(ce *registrationLeaf) {
+		// "listed: false" means hidden from search results, but the package
+		// still exists on NuGet. Developers who already have it in their
+		// project need to see its metadata and deprecation notice, so we
+		// include unlisted versions rather than pretending they don't exist.
+		sv := ParseSemVer(ce.Version)
+		if latestLeaf == nil || sv.IsNewerThan(ParseSemVer(latestLeaf.Version)) {
+			latestLeaf = ce
+		}
+		if !sv.IsPreRelease() {
+			if latestStableLeaf == nil || sv.IsNewerThan(ParseSemVer(latestStableLeaf.Version)) {
+				latestStableLeaf = ce
+			}
+		}
+		published, _ := time.Parse(time.RFC3339, ce.Published)
+		versions = append(versions, PackageVersion{
+			SemVer:           sv,
+			Published:        published,
+			Frameworks:       groupFrameworks(ce.DependencyGroups),
+			Vulnerabilities:  ce.Vulnerabilities,
+			DependencyGroups: ce.DependencyGroups,
+			License:          packageLicense(ce.LicenseExpr, ce.LicenseURL),
+			Deprecation:      ce.Deprecation.versionDeprecation(),
+		})
+	}
+
+	var pending []registrationPage
+	err := s.getStream(ctx, regURL, func(r io.Reader) error {
+		var err error
+		pending, err = decodeRegistrationIndex(r, visit)
+		return err
+	})
+	if err != nil {
 		var he *httpStatusError
 		if errors.As(err, &he) && he.Code == http.StatusNotFound {
 			logDebug("[%s] %q not found (404)", s.sourceName, packageID)
-			return nil, fmt.Errorf("package %q not found", packageID)
+			return nil, fmt.Errorf("package %q %w", packageID, errPackageNotFound)
 		}
 		return nil, err
 	}
 
-	logTrace("[%s] registration index for %q has %d page(s)", s.sourceName, packageID, len(regIdx.Items))
+	logTrace("[%s] registration index for %q: %d inlined version(s), %d page(s) to fetch", s.sourceName, packageID, len(versions), len(pending))
 
-	var versions []PackageVersion
-	var latestLeaf *registrationLeaf       // newest version overall (for fallback metadata)
-	var latestStableLeaf *registrationLeaf // newest stable version (preferred for metadata)
-
-	for pi, page := range regIdx.Items {
-		items := page.Items
-		if len(items) == 0 {
-			// Page not inlined — fetch it separately.
-			logTrace("[%s] fetching registration page %d/%d: %s", s.sourceName, pi+1, len(regIdx.Items), page.ID)
-			var fullPage registrationPage
-			if err := s.getJSON(page.ID, &fullPage); err != nil {
-				return nil, fmt.Errorf("fetching page %s: %w", page.ID, err)
-			}
-			items = fullPage.Items
-		}
-
-		for i := range items {
-			ce := &items[i].CatalogEntry
-			// "listed: false" means hidden from search results, but the package
-			// still exists on NuGet. Developers who already have it in their
-			// project need to see its metadata and deprecation notice, so we
-			// include unlisted versions rather than pretending they don't exist.
-			sv := ParseSemVer(ce.Version)
-			if latestLeaf == nil || sv.IsNewerThan(ParseSemVer(latestLeaf.Version)) {
-				latestLeaf = ce
-			}
-			if !sv.IsPreRelease() {
-				if latestStableLeaf == nil || sv.IsNewerThan(ParseSemVer(latestStableLeaf.Version)) {
-					latestStableLeaf = ce
-				}
-			}
-			seen := NewSet[string]()
-			var frameworks []TargetFramework
-			for _, dg := range ce.DependencyGroups {
-				raw := normFramework(dg.TargetFramework)
-				if raw != "" && !seen.Contains(raw) {
-					seen.Add(raw)
-					frameworks = append(frameworks, ParseTargetFramework(raw))
-				}
-			}
-			published, _ := time.Parse(time.RFC3339, ce.Published)
-			versions = append(versions, PackageVersion{
-				SemVer:           sv,
-				Published:        published,
-				Frameworks:       frameworks,
-				Vulnerabilities:  ce.Vulnerabilities,
-				DependencyGroups: ce.DependencyGroups,
-			})
+	for pi, page := range pending {
+		// Page not inlined — fetch it separately.
+		logTrace("[%s] fetching registration page %d/%d: %s", s.sourceName, pi+1, len(pending), page.ID)
+		err := s.getStream(ctx, page.ID, func(r io.Reader) error {
+			return decodeRegistrationPage(r, visit)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fetching page %s: %w", page.ID, err)
 		}
 	}
 
 	if len(versions) == 0 || latestLeaf == nil {
 		logDebug("[%s] %q has no versions in registration index", s.sourceName, packageID)
-		return nil, fmt.Errorf("package %q not found", packageID)
+		return nil, fmt.Errorf("package %q %w", packageID, errPackageNotFound)
 	}
 
 	sortVersionsDesc(versions)
@@ -1005,59 +1147,153 @@ func (s *NugetService) SearchExact(packageID string) (*PackageInfo, error) {
 	return pkg, nil
 }
 
+// groupFrameworks returns the distinct target frameworks of a version's
+// dependency groups.
+func groupFrameworks(groups []dependencyGroup) []TargetFramework {
+	seen := NewSet[string]()
+	var frameworks []TargetFramework
+	for _, dg := range groups {
+		raw := normFramework(dg.TargetFramework)
+		if raw != "" && !seen.Contains(raw) {
+			seen.Add(raw)
+			frameworks = append(frameworks, ParseTargetFramework(raw))
+		}
+	}
+	return frameworks
+}
+
 // LatestStable returns the newest non-pre-release version.
-func (p *PackageInfo) LatestStable() *PackageVersion {
+func (p *PackageInfo) LatestStable() *PackageVersion { return p.Latest(false) }
+
+// Latest returns the newest version, skipping pre-releases unless
+// prerelease is set.
+func (p *PackageInfo) Latest(prerelease bool) *PackageVersion {
 	for i := range p.Versions {
-		if !p.Versions[i].SemVer.IsPreRelease() {
+		if prerelease || !p.Versions[i].SemVer.IsPreRelease() {
 			return &p.Versions[i]
 		}
 	}
 	return nil
 }
 
+// Resolve returns the version restore would pick for a declared range or
+// floating version from the versions the feed lists. A plain version, or a
+// range nothing listed satisfies, is returned unchanged.
+func (p *PackageInfo) Resolve(declared SemVer) SemVer {
+	r, ok := ParseVersionRange(declared.Range)
+	if p == nil || !ok {
+		return declared
+	}
+	available := make([]SemVer, len(p.Versions))
+	for i, v := range p.Versions {
+		available[i] = v.SemVer
+	}
+	if best, ok := r.Best(available); ok {
+		return best
+	}
+	return declared
+}
+
+// ReleaseLag returns how far installed trails the newest stable release,
+// measured between their publish dates (the "libyear" metric). Zero when
+// installed is the newest stable. ok is false when either date is unknown.
+func (p *PackageInfo) ReleaseLag(installed SemVer) (lag time.Duration, ok bool) {
+	latest := p.LatestStable()
+	if latest == nil {
+		return 0, false
+	}
+	if !latest.SemVer.IsNewerThan(installed) {
+		return 0, true
+	}
+	for _, v := range p.Versions {
+		if v.SemVer.String() != installed.String() {
+			continue
+		}
+		// NuGet uses 1900-01-01 as its "unknown" publish date.
+		if v.Published.Year() < 2005 || latest.Published.Year() < 2005 {
+			return 0, false
+		}
+		if lag = latest.Published.Sub(v.Published); lag < 0 {
+			lag = 0
+		}
+		return lag, true
+	}
+	return 0, false
+}
+
 // LatestStableForFramework returns the newest stable version whose declared
 // target frameworks are compatible with all of the project's targets.
 // Returns nil if no compatible stable version exists (callers fall back to
 // LatestStable themselves for display purposes).
 func (p *PackageInfo) LatestStableForFramework(targets Set[TargetFramework]) *PackageVersion {
+	return p.LatestForFramework(targets, false)
+}
+
+// LatestForFramework is LatestStableForFramework that also considers
+// pre-releases when prerelease is set.
+func (p *PackageInfo) LatestForFramework(targets Set[TargetFramework], prerelease bool) *PackageVersion {
 	for i := range p.Versions {
 		v := &p.Versions[i]
-		if v.SemVer.IsPreRelease() {
+		if v.SemVer.IsPreRelease() && !prerelease {
 			continue
 		}
+		if v.supportsAll(targets) {
+			return v
+		}
+	}
+	return nil
+}
 
-		// No frameworks declared means the package supports everything
-		if len(v.Frameworks) == 0 {
+// MinimumFixedVersion returns the oldest version newer than installed that
+// has no known vulnerabilities and is compatible with all of targets — the
+// smallest update that clears every advisory. Pre-releases are only
+// considered when installed is itself a pre-release. Returns nil when no
+// such version exists.
+func (p *PackageInfo) MinimumFixedVersion(installed SemVer, targets Set[TargetFramework]) *PackageVersion {
+	// Versions are ordered newest first.
+	for i := len(p.Versions) - 1; i >= 0; i-- {
+		v := &p.Versions[i]
+		if !v.SemVer.IsNewerThan(installed) || len(v.Vulnerabilities) > 0 {
+			continue
+		}
+		if v.SemVer.IsPreRelease() && !installed.IsPreRelease() {
+			continue
+		}
+		if v.supportsAll(targets) {
 			return v
 		}
+	}
+	return nil
+}
 
-		// Check if this version is compatible with all project frameworks.
-		// Skip FamilyUnknown targets — these arise from unresolved MSBuild
-		// property references (e.g. $(TargetFrameworksForLibraries)) that we
-		// cannot evaluate without running MSBuild. Since we have no information
-		// about what they resolve to, we cannot conclude incompatibility.
-		allCompatible := true
-		for target := range targets {
-			if target.Family == FamilyUnknown {
-				continue // can't determine compatibility; don't block
-			}
-			compatibleWithProj := false
-			for _, versionFw := range v.Frameworks {
-				if target.IsCompatibleWith(versionFw) {
-					compatibleWithProj = true
-					break
-				}
-			}
-			if !compatibleWithProj {
-				allCompatible = false
+// supportsAll reports whether the version's declared target frameworks are
+// compatible with every one of targets.
+func (v *PackageVersion) supportsAll(targets Set[TargetFramework]) bool {
+	// No frameworks declared means the package supports everything
+	if len(v.Frameworks) == 0 {
+		return true
+	}
+
+	// Skip FamilyUnknown targets — these arise from unresolved MSBuild
+	// property references (e.g. $(TargetFrameworksForLibraries)) that we
+	// cannot evaluate without running MSBuild. Since we have no information
+	// about what they resolve to, we cannot conclude incompatibility.
+	for target := range targets {
+		if target.Family == FamilyUnknown {
+			continue // can't determine compatibility; don't block
+		}
+		compatibleWithProj := false
+		for _, versionFw := range v.Frameworks {
+			if target.IsCompatibleWith(versionFw) {
+				compatibleWithProj = true
 				break
 			}
 		}
-		if allCompatible {
-			return v
+		if !compatibleWithProj {
+			return false
 		}
 	}
-	return nil
+	return true
 }
 
 // VersionsSince returns all versions newer than the given semver string.
@@ -1072,6 +1308,23 @@ func (p *PackageInfo) VersionsSince(since string) []PackageVersion {
 	return result
 }
 
+// VersionsBetween returns the versions an update from installed to target
+// would cross: newer than installed, up to and including target, newest
+// first. Pre-releases are skipped unless target is itself a pre-release.
+func (p *PackageInfo) VersionsBetween(installed, target SemVer) []PackageVersion {
+	var result []PackageVersion
+	for _, v := range p.Versions {
+		if !v.SemVer.IsNewerThan(installed) || v.SemVer.IsNewerThan(target) {
+			continue
+		}
+		if v.SemVer.IsPreRelease() && !target.IsPreRelease() {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
 type StringOrArray []string
 
 func (s *StringOrArray) UnmarshalJSON(b []byte) error {
@@ -1088,6 +1341,9 @@ func (s *StringOrArray) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// errPackageNotFound is wrapped by SearchExact when a source has no such package.
+var errPackageNotFound = errors.New("not found")
+
 // httpStatusError is returned by getJSON for non-200 responses so callers can
 // inspect the status code and decide whether to treat it as a hard failure.
 type httpStatusError struct {
@@ -1112,26 +1368,72 @@ func isTransientHTTP(code int) bool {
 	return false
 }
 
+// maxRetries is how many times a request answered with a transient status
+// (429 or 5xx) is retried before that status is returned.
+const maxRetries = 4
+
+var (
+	// retryBackoff is the wait before the first retry; each further retry
+	// doubles it.
+	retryBackoff = 500 * time.Millisecond
+	// maxRetryDelay caps a single wait, including one a Retry-After asks for.
+	maxRetryDelay = 30 * time.Second
+)
+
+// retryDelay is how long to wait before retry attempt (counting from 1) of a
+// request: what its Retry-After header asks for, in seconds or as an HTTP
+// date, or else an exponential backoff with up to 50% jitter, so requests
+// rate-limited together don't retry together.
+func retryDelay(attempt int, retryAfter string, now time.Time) time.Duration {
+	if retryAfter = strings.TrimSpace(retryAfter); retryAfter != "" {
+		if secs, err := strconv.Atoi(retryAfter); err == nil && secs >= 0 {
+			return min(time.Duration(secs)*time.Second, maxRetryDelay)
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return min(max(at.Sub(now), 0), maxRetryDelay)
+		}
+	}
+	backoff := retryBackoff << (attempt - 1)
+	if backoff > 0 {
+		backoff += time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	}
+	return min(backoff, maxRetryDelay)
+}
+
 func (s *NugetService) getJSON(u string, dst any) error {
+	return s.getStream(context.Background(), u, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(dst)
+	})
+}
+
+// getStream GETs u (retrying transient errors; see retryDelay) and hands the
+// body to decode, so large documents can be processed without buffering
+// them whole.
+func (s *NugetService) getStream(ctx context.Context, u string, decode func(io.Reader) error) error {
 	logTrace("[%s] GET %s", s.sourceName, u)
-	start := time.Now()
-	resp, err := s.client.Get(u)
-	elapsed := time.Since(start)
-	if err != nil {
-		logTrace("[%s] GET %s failed after %s: %v", s.sourceName, u, elapsed, err)
-		return err
+	get := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		return s.client.Do(req)
 	}
-	// Retry once on transient HTTP errors.
-	if isTransientHTTP(resp.StatusCode) {
+	start := time.Now()
+	resp, err := get()
+	for attempt := 1; err == nil && isTransientHTTP(resp.StatusCode) && attempt <= maxRetries; attempt++ {
 		resp.Body.Close()
-		jitter := 500 + rand.Intn(1000)
-		logWarn("[%s] GET %s → %d, retrying in %dms...", s.sourceName, u, resp.StatusCode, jitter)
-		time.Sleep(time.Duration(jitter) * time.Millisecond)
-		resp, err = s.client.Get(u)
-		if err != nil {
-			logWarn("[%s] GET %s retry failed: %v", s.sourceName, u, err)
-			return err
+		delay := retryDelay(attempt, resp.Header.Get("Retry-After"), time.Now())
+		logWarn("[%s] GET %s → %d, retry %d/%d in %s...", s.sourceName, u, resp.StatusCode, attempt, maxRetries, delay.Round(time.Millisecond))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
 		}
+		resp, err = get()
+	}
+	if err != nil {
+		logTrace("[%s] GET %s failed after %s: %v", s.sourceName, u, time.Since(start), err)
+		return err
 	}
 	defer resp.Body.Close()
 	logTrace("[%s] GET %s → %d (%s)", s.sourceName, u, resp.StatusCode, time.Since(start))
@@ -1139,7 +1441,7 @@ func (s *NugetService) getJSON(u string, dst any) error {
 		return &httpStatusError{Code: resp.StatusCode, URL: u}
 	}
 	decStart := time.Now()
-	err = json.NewDecoder(resp.Body).Decode(dst)
+	err = decode(resp.Body)
 	logTrace("[%s] JSON decode %s (%s)", s.sourceName, u, time.Since(decStart))
 	return err
 }
@@ -1202,6 +1504,24 @@ type GitHubRelease struct {
 	HTMLURL     string `json:"html_url"`
 }
 
+// matchGitHubRelease finds the release tagged for version. Tags vary by
+// project: "1.2.3", "v1.2.3", "Serilog-v1.2.3", "release/1.2.3".
+func matchGitHubRelease(releases []GitHubRelease, version string) *GitHubRelease {
+	v := strings.ToLower(version)
+	for i, rel := range releases {
+		tag := strings.ToLower(rel.TagName)
+		if tag == v || tag == "v"+v {
+			return &releases[i]
+		}
+		for _, sep := range []string{"-", "_", "/", "@", " "} {
+			if strings.HasSuffix(tag, sep+v) || strings.HasSuffix(tag, sep+"v"+v) {
+				return &releases[i]
+			}
+		}
+	}
+	return nil
+}
+
 // parseGitHubRepo extracts owner and repo from a GitHub URL.
 // Returns ("","") if the URL is not a recognised GitHub repository URL.
 func parseGitHubRepo(rawURL string) (owner, repo string) {
@@ -1340,6 +1660,37 @@ func (s *NugetService) FetchNuspec(packageID, version string) string {
 	return s.fetchNuspec(s.flatBase, packageID, version)
 }
 
+// maxReadmeSize caps how much of a package README is downloaded.
+const maxReadmeSize = 1 << 20
+
+// FetchReadme downloads the README embedded in a package version from the
+// flat container's readme endpoint, which nuget.org serves. Returns "" with
+// no error when the package has no README or the source doesn't serve them.
+func (s *NugetService) FetchReadme(packageID, version string) (string, error) {
+	if s.flatBase == "" {
+		logTrace("FetchReadme: [%s] no PackageBaseAddress available", s.sourceName)
+		return "", nil
+	}
+	u := fmt.Sprintf("%s/%s/%s/readme", s.flatBase, strings.ToLower(packageID), strings.ToLower(version))
+	logTrace("FetchReadme: GET %s", u)
+	resp, err := s.client.Get(u)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", nil
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReadmeSize))
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
 // ExtractNuspecRepoURL extracts <repository url="..."> from nuspec XML.
 func ExtractNuspecRepoURL(body string) string {
 	repoURL := extractRepoURL(body)
@@ -227,3 +227,47 @@ func TestSearchExact_FrameworkInfo(t *testing.T) {
 		t.Error("no versions had parsed Frameworks")
 	}
 }
+
+func TestBrowse_Paging(t *testing.T) {
+	svc := nugetOrgService(t)
+
+	first, total, err := svc.Browse("", 0, 5)
+	if err != nil {
+		t.Fatalf("Browse: %v", err)
+	}
+	if len(first) != 5 || total < 1000 {
+		t.Fatalf("expected a full first page of a large feed, got %d results of %d", len(first), total)
+	}
+	second, _, err := svc.Browse("", 5, 5)
+	if err != nil {
+		t.Fatalf("Browse page 2: %v", err)
+	}
+	for _, a := range first {
+		for _, b := range second {
+			if strings.EqualFold(a.ID, b.ID) {
+				t.Errorf("%s appears on both pages", a.ID)
+			}
+		}
+	}
+}
+
+func TestBrowse_Owner(t *testing.T) {
+	svc := nugetOrgService(t)
+
+	results, _, err := svc.Browse("owner:serilog", 0, 20)
+	if err != nil {
+		t.Fatalf("Browse: %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatal("expected packages owned by serilog")
+	}
+	for _, r := range results {
+		owned := false
+		for _, o := range r.Owners {
+			owned = owned || strings.EqualFold(o, "serilog")
+		}
+		if !owned {
+			t.Errorf("%s is not owned by serilog (owners %v)", r.ID, r.Owners)
+		}
+	}
+}
@@ -2,14 +2,35 @@ package main
 
 import (
 	"encoding/xml"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 )
 
 const defaultNugetSource = "https://api.nuget.org/v3/index.json"
 
+// nugetOrgDisabled stops every call guget would make to nuget.org: there is
+// no fallback source, no enrichment of private-feed packages, and nuget.org
+// sources listed in configs are skipped. Set from --no-nuget-org or the
+// disableNugetOrg setting via setNugetOrgDisabled.
+var nugetOrgDisabled bool
+
+func setNugetOrgDisabled(disabled bool) { nugetOrgDisabled = disabled }
+
+// isNugetOrgURL reports whether raw points at nuget.org or a subdomain.
+func isNugetOrgURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "nuget.org" || strings.HasSuffix(host, ".nuget.org")
+}
+
 type nugetConfig struct {
 	XMLName              xml.Name                 `xml:"configuration"`
 	PackageSources       []packageSource          `xml:"packageSources>add"`
@@ -20,8 +41,9 @@ type nugetConfig struct {
 }
 
 type packageSource struct {
-	Key   string `xml:"key,attr"`
-	Value string `xml:"value,attr"`
+	Key             string `xml:"key,attr"`
+	Value           string `xml:"value,attr"`
+	ProtocolVersion string `xml:"protocolVersion,attr"`
 }
 
 type NugetSource struct {
@@ -29,12 +51,51 @@ type NugetSource struct {
 	URL      string
 	Username string // from <packageSourceCredentials> (cleartext or DPAPI-decrypted)
 	Password string
+	// ConfigPath is the nuget.config that defines the source; "" for sources
+	// from Directory.Build.props or the nuget.org fallback.
+	ConfigPath string
+	// Proxy is the http_proxy of the nuget.config hierarchy the source was
+	// found in, passed on to credential providers.
+	Proxy nugetProxy
+	// ProtocolVersion is the protocolVersion attribute of the source: "2"
+	// marks a v2 (OData) feed, anything else is probed as v3 first.
+	ProtocolVersion string
 }
 
 // DetectedConfig holds everything discovered from the nuget.config hierarchy.
 type DetectedConfig struct {
-	Sources []NugetSource
-	Mapping *PackageSourceMapping
+	Sources   []NugetSource
+	Mapping   *PackageSourceMapping
+	Folders   PackageFolders
+	Conflicts []SourceConflict
+	Configs   []string // nuget.config files read, closest first
+	Proxy     nugetProxy
+}
+
+// SourceConflict records a source dropped because an earlier (closer to the
+// project) definition already used its name or its URL.
+type SourceConflict struct {
+	SameName bool // same name with a different URL; otherwise same URL under another name
+	Kept     NugetSource
+	Dropped  NugetSource
+}
+
+// Explain says which definition wins and what that means for credentials and
+// packageSourceMapping, both of which are looked up by source name.
+func (c SourceConflict) Explain() string {
+	if c.SameName {
+		return fmt.Sprintf("%q is defined in %s (%s) and in %s (%s). The definition closest to the project wins: its URL and credentials are used, and packageSourceMapping patterns for %q apply to it.",
+			c.Kept.Name, sourceOrigin(c.Kept), c.Kept.URL, sourceOrigin(c.Dropped), c.Dropped.URL, c.Kept.Name)
+	}
+	return fmt.Sprintf("%s is listed as %q in %s and as %q in %s. Only %q is queried, so credentials and packageSourceMapping patterns under %q are ignored.",
+		c.Kept.URL, c.Kept.Name, sourceOrigin(c.Kept), c.Dropped.Name, sourceOrigin(c.Dropped), c.Kept.Name, c.Dropped.Name)
+}
+
+func sourceOrigin(s NugetSource) string {
+	if s.ConfigPath == "" {
+		return "Directory.Build.props"
+	}
+	return s.ConfigPath
 }
 
 // parsedMappingResult is an internal type returned by sourcesFromNugetConfig
@@ -44,40 +105,143 @@ type parsedMappingResult struct {
 	cleared bool                // <clear/> inside <packageSourceMapping>
 }
 
-// DetectSources walks from projectDir up to root collecting NuGet sources and
-// package-source mapping rules. <clear/> stops inheritance. Falls back to nuget.org.
+// DetectSources walks from projectDir up to root, then the user and machine
+// configs, collecting NuGet sources and package-source mapping rules the way
+// dotnet restore does. A <clear/> in packageSources drops the sources of
+// every farther config, but disabledPackageSources, credentials, and other
+// settings are still merged from the whole chain. Falls back to nuget.org
+// unless nugetOrgDisabled.
 func DetectSources(projectDir string) DetectedConfig {
-	seen := NewSet[string]()
+	// 1. The chain, closest first: each directory's configs and then its
+	// Directory.Build.props, up to the root; then the user and machine
+	// configs. Missing files are skipped, and so are names that reach a file
+	// already listed, as the case variants do on case-insensitive
+	// filesystems (Windows, macOS).
+	type layer struct {
+		path  string
+		dir   string // "" for user and machine configs
+		props bool   // Directory.Build.props rather than a nuget.config
+	}
+	var layers []layer
+	var seen configFileSet
+	addLayer := func(l layer) {
+		if seen.add(l.path) {
+			layers = append(layers, l)
+		}
+	}
+	for dir := projectDir; ; {
+		for _, path := range dirNugetConfigs(dir) {
+			addLayer(layer{path: path, dir: dir})
+		}
+		addLayer(layer{path: filepath.Join(dir, "Directory.Build.props"), dir: dir, props: true})
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break // reached root
+		}
+		dir = parent
+	}
+	for _, path := range globalNugetConfigs() {
+		addLayer(layer{path: path})
+	}
+
+	// 2. Disabled sources and credentials apply to a source whichever config
+	// defines it; the closest entry for each source name wins.
+	disabled := map[string]bool{}
+	disabledCleared := false
+	creds := map[string]sourceCredential{}
+	for _, l := range layers {
+		if l.props {
+			continue
+		}
+		dis, disCleared, cs := sourceSettingsFromNugetConfig(l.path)
+		if !disabledCleared {
+			for name, off := range dis {
+				if _, ok := disabled[name]; !ok {
+					disabled[name] = off
+				}
+			}
+			disabledCleared = disCleared
+		}
+		for key, c := range cs {
+			if _, ok := creds[key]; !ok {
+				creds[key] = c
+			}
+		}
+	}
+
+	// 3. Sources, mapping, folders, and proxy.
 	var sources []NugetSource
+	var conflicts []SourceConflict
 	mapping := &PackageSourceMapping{Entries: make(map[string][]string)}
 	mappingCleared := false
+	var folders PackageFolders
+	fallbackCleared := false
+	var proxy nugetProxy
 
+	// add keeps the first definition of each name and each URL; sources are
+	// visited closest first, so the nearest config wins as it does in NuGet.
 	add := func(s NugetSource) {
+		if nugetOrgDisabled && isNugetOrgURL(s.URL) {
+			logDebug("Skipping source %s (%s): nuget.org is disabled", s.Name, s.URL)
+			return
+		}
+		if disabled[strings.ToLower(s.Name)] {
+			logTrace("DetectSources: [%s] skipped (disabled)", s.Name)
+			return
+		}
 		url := strings.TrimRight(s.URL, "/")
-		if !seen.Contains(url) {
-			seen.Add(url)
-			sources = append(sources, s)
+		for _, kept := range sources {
+			sameURL := strings.TrimRight(kept.URL, "/") == url
+			sameName := strings.EqualFold(kept.Name, s.Name)
+			if !sameURL && !sameName {
+				continue
+			}
+			if !(sameURL && sameName) {
+				conflicts = append(conflicts, SourceConflict{SameName: sameName, Kept: kept, Dropped: s})
+			}
+			return
 		}
+		sources = append(sources, s)
 	}
 
-	// addConfig adds sources and mapping rules from a config file.
-	// Returns true if a <clear/> was found in <packageSources>.
-	// Deduplicates by resolved path so case-insensitive filesystems
-	// (Windows) don't parse the same file twice.
-	seenConfigs := NewSet[string]()
-	addConfig := func(path string) bool {
-		resolved, err := filepath.Abs(path)
-		if err == nil {
-			resolved = strings.ToLower(resolved)
-			if seenConfigs.Contains(resolved) {
-				return false
+	// clearedIn is the directory whose config declared <clear/> in
+	// packageSources; sources from anything farther are ignored. Configs
+	// outside the walk never share it.
+	cleared, clearedIn := false, ""
+	var configs []string
+	for _, l := range layers {
+		keepSources := !cleared || (l.dir != "" && l.dir == clearedIn)
+		if l.props {
+			if keepSources {
+				for _, s := range sourcesFromBuildProps(l.path) {
+					add(s)
+				}
 			}
-			seenConfigs.Add(resolved)
+			continue
+		}
+		configs = append(configs, l.path)
+		srcs, clears, mr := sourcesFromNugetConfig(l.path)
+		if keepSources {
+			for _, s := range srcs {
+				add(s)
+			}
+			if clears && !cleared {
+				cleared, clearedIn = true, l.dir
+			}
+		} else if len(srcs) > 0 {
+			logTrace("DetectSources: ignoring %d source(s) in %q (cleared by a closer config)", len(srcs), l.path)
 		}
-		srcs, cleared, mr := sourcesFromNugetConfig(path)
-		for _, s := range srcs {
-			add(s)
+		// Configs are visited closest first, so the first globalPackagesFolder
+		// wins and farther fallback folders are dropped after a <clear/>.
+		global, fallback, fbCleared := packageFoldersFromNugetConfig(l.path)
+		if folders.Global == "" {
+			folders.Global = global
 		}
+		if !fallbackCleared {
+			folders.Fallback = append(folders.Fallback, fallback...)
+			fallbackCleared = fbCleared
+		}
+		proxy.merge(proxyFromNugetConfig(l.path))
 		if !mappingCleared && mr != nil {
 			if mr.cleared {
 				mapping = &PackageSourceMapping{Entries: make(map[string][]string)}
@@ -87,63 +251,95 @@ func DetectSources(projectDir string) DetectedConfig {
 				mapping.Entries[k] = append(mapping.Entries[k], v...)
 			}
 		}
-		return cleared
 	}
 
-	// 1. Walk from projectDir up to root, collecting nuget.config + Directory.Build.props
-	cleared := false
-	dir := projectDir
-	for {
-		if addConfig(filepath.Join(dir, "nuget.config")) {
-			cleared = true
-		}
-		if addConfig(filepath.Join(dir, "NuGet.Config")) {
-			cleared = true
-		}
-		if addConfig(filepath.Join(dir, ".nuget", "NuGet.Config")) {
-			cleared = true
-		}
-		for _, s := range sourcesFromBuildProps(filepath.Join(dir, "Directory.Build.props")) {
-			add(s)
-		}
-
-		if cleared {
-			break // <clear/> found — do not inherit from parent dirs, user, or machine
-		}
+	// 4. Fallback to nuget.org
+	if len(sources) == 0 && !nugetOrgDisabled {
+		add(NugetSource{Name: "nuget.org", URL: defaultNugetSource})
+	}
 
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			break // reached root
-		}
-		dir = parent
+	// Nil out empty mapping so IsConfigured() returns false.
+	if len(mapping.Entries) == 0 {
+		mapping = nil
 	}
 
-	// 2. User-level config (skipped if any config declared <clear/>)
-	if !cleared {
-		if addConfig(userNugetConfigPath()) {
-			cleared = true
+	folders.applyPackageFolderEnv()
+	for i := range sources {
+		sources[i].Proxy = proxy
+		if c, ok := creds[normalizeCredentialKey(sources[i].Name)]; ok {
+			sources[i].Username, sources[i].Password = c.Username, c.Password
 		}
 	}
 
-	// 3. Machine-level config (skipped if any config declared <clear/>)
-	if !cleared {
-		addConfig(machineNugetConfigPath())
-	}
+	return DetectedConfig{Sources: sources, Mapping: mapping, Folders: folders, Conflicts: conflicts, Configs: configs, Proxy: proxy}
+}
 
-	// 4. Fallback to nuget.org
-	if len(sources) == 0 {
-		add(NugetSource{Name: "nuget.org", URL: defaultNugetSource})
-	}
+// expandConfigValue substitutes environment variables in a NuGet.Config
+// value, so templated URLs resolve the way they do for dotnet.
+func expandConfigValue(s string) string {
+	return expandEnvRefs(s, os.LookupEnv, true)
+}
 
-	// Nil out empty mapping so IsConfigured() returns false.
-	if len(mapping.Entries) == 0 {
-		mapping = nil
+// expandCredentialValue substitutes only %VAR% references in a user name or
+// password, as NuGet does: '$' is too common in passwords to read as a
+// reference.
+func expandCredentialValue(s string) string {
+	return expandEnvRefs(s, os.LookupEnv, false)
+}
+
+// expandEnvRefs replaces %VAR% references (as NuGet does on every platform)
+// and, when dollar is set, $VAR / ${VAR} ones with their values. References
+// to unset variables are left as written, so a literal '%' or '$' survives.
+func expandEnvRefs(s string, lookup func(string) (string, bool), dollar bool) string {
+	if !strings.ContainsRune(s, '%') && (!dollar || !strings.ContainsRune(s, '$')) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '%':
+			if end := strings.IndexByte(s[i+1:], '%'); end > 0 {
+				if v, ok := lookup(s[i+1 : i+1+end]); ok {
+					b.WriteString(v)
+					i += end + 2
+					continue
+				}
+			}
+		case '$':
+			name, width := envRefName(s[i+1:])
+			if dollar && name != "" {
+				if v, ok := lookup(name); ok {
+					b.WriteString(v)
+					i += 1 + width
+					continue
+				}
+			}
+		}
+		b.WriteByte(s[i])
+		i++
 	}
+	return b.String()
+}
 
-	return DetectedConfig{Sources: sources, Mapping: mapping}
+// envRefName reads the variable name after a '$', either braced or a run of
+// letters, digits, and underscores. Returns the name and the bytes consumed.
+func envRefName(s string) (string, int) {
+	if strings.HasPrefix(s, "{") {
+		if end := strings.IndexByte(s, '}'); end > 1 {
+			return s[1:end], end + 1
+		}
+		return "", 0
+	}
+	n := 0
+	for n < len(s) && (s[n] == '_' || s[n] >= 'a' && s[n] <= 'z' || s[n] >= 'A' && s[n] <= 'Z' || n > 0 && s[n] >= '0' && s[n] <= '9') {
+		n++
+	}
+	return s[:n], n
 }
 
-// sourcesFromNugetConfig parses a single NuGet.Config file.
+// sourcesFromNugetConfig parses a single NuGet.Config file. Disabled sources
+// are included: disabledPackageSources applies across the whole chain, so
+// DetectSources filters them.
 func sourcesFromNugetConfig(path string) ([]NugetSource, bool, *parsedMappingResult) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -159,25 +355,19 @@ func sourcesFromNugetConfig(path string) ([]NugetSource, bool, *parsedMappingRes
 
 	cleared := len(cfg.PackageSourcesClear) > 0
 
-	// Build disabled-sources set from this file only.
-	disabled := NewSet[string]()
-	for _, d := range cfg.DisabledSources {
-		disabled.Add(strings.ToLower(d.Key))
-	}
-
 	// Parse credentials keyed by normalised source name
 	creds := parseCredentials(data)
 	logTrace("sourcesFromNugetConfig: %q — %d credential block(s), cleared=%v", path, len(creds), cleared)
 
 	var sources []NugetSource
 	for _, ps := range cfg.PackageSources {
-		if disabled.Contains(strings.ToLower(ps.Key)) {
-			logTrace("sourcesFromNugetConfig: [%s] skipped (disabled)", ps.Key)
-			continue
-		}
+		value := expandConfigValue(ps.Value)
 		// Only include http/https sources (skip local folder paths)
-		if strings.HasPrefix(ps.Value, "http://") || strings.HasPrefix(ps.Value, "https://") {
-			s := NugetSource{Name: ps.Key, URL: ps.Value}
+		if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+			s := NugetSource{Name: ps.Key, URL: value, ConfigPath: path, ProtocolVersion: ps.ProtocolVersion}
+			if to, reason := suggestSourceURL(value); to != "" {
+				logWarn("Source [%s] uses a deprecated URL (%s); the sources overlay (s) can switch it to %s", ps.Key, reason, to)
+			}
 			if c, ok := creds[normalizeCredentialKey(ps.Key)]; ok {
 				s.Username = c.Username
 				s.Password = c.Password
@@ -187,7 +377,7 @@ func sourcesFromNugetConfig(path string) ([]NugetSource, bool, *parsedMappingRes
 			}
 			sources = append(sources, s)
 		} else {
-			logTrace("sourcesFromNugetConfig: [%s] skipped (not http/https: %q)", ps.Key, ps.Value)
+			logTrace("sourcesFromNugetConfig: [%s] skipped (not http/https: %q)", ps.Key, value)
 		}
 	}
 
@@ -255,6 +445,86 @@ func sourcesFromBuildProps(path string) []NugetSource {
 	return sources
 }
 
+// sourceSettingsFromNugetConfig reads the settings of a single NuGet.Config
+// that apply to sources defined anywhere in the chain: disabledPackageSources
+// (lowercase name → disabled; value="false" re-enables a source a farther
+// config disabled) and packageSourceCredentials.
+func sourceSettingsFromNugetConfig(path string) (disabled map[string]bool, cleared bool, creds map[string]sourceCredential) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, nil
+	}
+	var cfg nugetConfig
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return nil, false, nil
+	}
+	disabled = make(map[string]bool, len(cfg.DisabledSources))
+	for _, d := range cfg.DisabledSources {
+		disabled[strings.ToLower(d.Key)] = !strings.EqualFold(strings.TrimSpace(d.Value), "false")
+	}
+	return disabled, len(cfg.DisabledSourcesClear) > 0, parseCredentials(data)
+}
+
+// configFileSet tracks config files by identity rather than by name.
+type configFileSet struct {
+	files []os.FileInfo
+}
+
+// add reports whether path is an existing file not seen before, and records it.
+func (fs *configFileSet) add(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	for _, seen := range fs.files {
+		if os.SameFile(seen, info) {
+			return false
+		}
+	}
+	fs.files = append(fs.files, info)
+	return true
+}
+
+// nugetConfigNames are the file names NuGet looks for in each directory;
+// they differ only in case, which matters on case-sensitive filesystems.
+var nugetConfigNames = []string{"nuget.config", "NuGet.config", "NuGet.Config"}
+
+// dirNugetConfigs lists the config files read from one directory of the walk
+// from the project up, including the legacy solution-level .nuget folder.
+func dirNugetConfigs(dir string) []string {
+	paths := make([]string, 0, len(nugetConfigNames)+1)
+	for _, name := range nugetConfigNames {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	return append(paths, filepath.Join(dir, ".nuget", "NuGet.Config"))
+}
+
+// globalNugetConfigs lists the configs read after the directory walk, closest
+// first: the user NuGet.Config, the additional user configs in its config
+// folder, the machine NuGet.Config, then the machine-wide config folder.
+func globalNugetConfigs() []string {
+	var paths []string
+	if user := userNugetConfigPath(); user != "" {
+		paths = append(paths, user)
+		paths = append(paths, extraNugetConfigs(filepath.Join(filepath.Dir(user), "config"))...)
+	}
+	paths = append(paths, machineNugetConfigPath())
+	return append(paths, extraNugetConfigs(machineNugetConfigDir())...)
+}
+
+// extraNugetConfigs returns the *.config files in dir, sorted by name.
+func extraNugetConfigs(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.config"))
+	sort.Strings(matches)
+	return matches
+}
+
 func userNugetConfigPath() string {
 	if runtime.GOOS == "windows" {
 		appdata := os.Getenv("APPDATA")
@@ -275,3 +545,15 @@ func machineNugetConfigPath() string {
 	}
 	return "/etc/opt/nuget/NuGet.Config"
 }
+
+// machineNugetConfigDir is the folder of machine-wide configs that tools such
+// as Visual Studio install; "" when there is none.
+func machineNugetConfigDir() string {
+	if runtime.GOOS == "windows" {
+		if pf := os.Getenv("ProgramFiles(x86)"); pf != "" {
+			return filepath.Join(pf, "NuGet", "Config")
+		}
+		return ""
+	}
+	return "/etc/opt/NuGet/Config"
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnvRefs(t *testing.T) {
+	env := map[string]string{"FEED": "https://pkgs.example.com/v3/index.json", "PAT": "s3cret", "EMPTY": ""}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+
+	tests := []struct{ in, want string }{
+		{"%FEED%", "https://pkgs.example.com/v3/index.json"},
+		{"$PAT", "s3cret"},
+		{"${PAT}-suffix", "s3cret-suffix"},
+		{"token-%PAT%-x", "token-s3cret-x"},
+		{"%EMPTY%x", "x"},
+		{"%MISSING%", "%MISSING%"},
+		{"$MISSING and 100%", "$MISSING and 100%"},
+		{"pa$$w%rd", "pa$$w%rd"},
+		{"no refs", "no refs"},
+	}
+	for _, tt := range tests {
+		if got := expandEnvRefs(tt.in, lookup, true); got != tt.want {
+			t.Errorf("expandEnvRefs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExpandEnvRefs_CredentialsKeepDollar(t *testing.T) {
+	env := map[string]string{"PAT": "s3cret", "HOME1": "/home/ci"}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+
+	tests := []struct{ in, want string }{
+		{"pa$HOME1", "pa$HOME1"},
+		{"${PAT}", "${PAT}"},
+		{"%PAT%", "s3cret"},
+		{"pa$HOME1%PAT%", "pa$HOME1s3cret"},
+	}
+	for _, tt := range tests {
+		if got := expandEnvRefs(tt.in, lookup, false); got != tt.want {
+			t.Errorf("expandEnvRefs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSourcesFromNugetConfig_ExpandsEnvironment(t *testing.T) {
+	t.Setenv("GUGET_TEST_FEED", "https://pkgs.example.com/v3/index.json")
+	t.Setenv("GUGET_TEST_PAT", "s3cret")
+
+	path := filepath.Join(t.TempDir(), "nuget.config")
+	os.WriteFile(path, []byte(`<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <add key="corp" value="%GUGET_TEST_FEED%" />
+  </packageSources>
+  <packageSourceCredentials>
+    <corp>
+      <add key="Username" value="ci" />
+      <add key="ClearTextPassword" value="%GUGET_TEST_PAT%" />
+    </corp>
+  </packageSourceCredentials>
+</configuration>`), 0644)
+
+	sources, _, _ := sourcesFromNugetConfig(path)
+	if len(sources) != 1 {
+		t.Fatalf("expected the expanded URL to be accepted as a source, got %+v", sources)
+	}
+	s := sources[0]
+	if s.URL != "https://pkgs.example.com/v3/index.json" {
+		t.Errorf("URL = %q", s.URL)
+	}
+	if s.Username != "ci" || s.Password != "s3cret" {
+		t.Errorf("credentials = %q/%q, want ci/s3cret", s.Username, s.Password)
+	}
+}
+
+func TestSourcesFromNugetConfig_PasswordWithDollar(t *testing.T) {
+	t.Setenv("GUGET_TEST_PAT", "s3cret")
+
+	path := filepath.Join(t.TempDir(), "nuget.config")
+	os.WriteFile(path, []byte(`<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <add key="corp" value="https://pkgs.example.com/v3/index.json" />
+  </packageSources>
+  <packageSourceCredentials>
+    <corp>
+      <add key="Username" value="ci" />
+      <add key="ClearTextPassword" value="pa$GUGET_TEST_PAT" />
+    </corp>
+  </packageSourceCredentials>
+</configuration>`), 0644)
+
+	sources, _, _ := sourcesFromNugetConfig(path)
+	if len(sources) != 1 || sources[0].Password != "pa$GUGET_TEST_PAT" {
+		t.Errorf("sources = %+v, want the password as written", sources)
+	}
+}
+
+func TestSourcesFromNugetConfig_ProtocolVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nuget.config")
+	os.WriteFile(path, []byte(`<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <add key="teamcity" value="https://tc.corp.example/guestAuth/app/nuget/feed/main" protocolVersion="2" />
+    <add key="nuget.org" value="https://api.nuget.org/v3/index.json" protocolVersion="3" />
+  </packageSources>
+</configuration>`), 0644)
+
+	sources, _, _ := sourcesFromNugetConfig(path)
+	if len(sources) != 2 || sources[0].ProtocolVersion != "2" || sources[1].ProtocolVersion != "3" {
+		t.Errorf("sources = %+v, want protocolVersion 2 and 3", sources)
+	}
+}
+
+func TestDetectSources_ClearKeepsUserSettings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	userDir := filepath.Join(home, ".nuget", "NuGet")
+	os.MkdirAll(filepath.Join(userDir, "config"), 0755)
+	os.WriteFile(filepath.Join(userDir, "NuGet.Config"), []byte(`<configuration>
+  <packageSources>
+    <add key="user-feed" value="https://user.example.com/v3/index.json" />
+  </packageSources>
+  <disabledPackageSources>
+    <add key="legacy" value="true" />
+    <add key="corp" value="true" />
+  </disabledPackageSources>
+  <packageSourceCredentials>
+    <corp>
+      <add key="Username" value="ci" />
+      <add key="ClearTextPassword" value="from-user-config" />
+    </corp>
+  </packageSourceCredentials>
+</configuration>`), 0644)
+	os.WriteFile(filepath.Join(userDir, "config", "team.config"), []byte(`<configuration>
+  <config>
+    <add key="globalPackagesFolder" value="/packages/team" />
+  </config>
+</configuration>`), 0644)
+
+	repo := t.TempDir()
+	os.WriteFile(filepath.Join(repo, "NuGet.config"), []byte(`<configuration>
+  <packageSources>
+    <clear />
+    <add key="corp" value="https://corp.example.com/v3/index.json" />
+    <add key="legacy" value="https://legacy.example.com/nuget" />
+  </packageSources>
+  <disabledPackageSources>
+    <add key="corp" value="false" />
+  </disabledPackageSources>
+</configuration>`), 0644)
+
+	detected := DetectSources(repo)
+	if len(detected.Sources) != 1 || detected.Sources[0].Name != "corp" {
+		t.Fatalf("sources = %+v, want only corp: legacy disabled by the user config, user-feed cleared", detected.Sources)
+	}
+	if got := detected.Sources[0]; got.Username != "ci" || got.Password != "from-user-config" {
+		t.Errorf("corp credentials = %q/%q, want the user config's", got.Username, got.Password)
+	}
+	if detected.Folders.Global != "/packages/team" {
+		t.Errorf("globalPackagesFolder = %q, want the additional user config's", detected.Folders.Global)
+	}
+}
+
+func TestDetectSources_ReportsConflicts(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "src")
+	os.MkdirAll(child, 0755)
+	os.WriteFile(filepath.Join(child, "nuget.config"), []byte(`<configuration>
+  <packageSources>
+    <add key="corp" value="https://a.example.com/v3/index.json" />
+  </packageSources>
+</configuration>`), 0644)
+	os.WriteFile(filepath.Join(root, "nuget.config"), []byte(`<configuration>
+  <packageSources>
+    <clear />
+    <add key="corp" value="https://b.example.com/v3/index.json" />
+    <add key="mirror" value="https://a.example.com/v3/index.json/" />
+    <add key="public" value="https://api.nuget.org/v3/index.json" />
+  </packageSources>
+</configuration>`), 0644)
+
+	detected := DetectSources(child)
+	if len(detected.Sources) != 2 || detected.Sources[0].URL != "https://a.example.com/v3/index.json" || detected.Sources[1].Name != "public" {
+		t.Fatalf("expected the closest corp and public, got %+v", detected.Sources)
+	}
+	if len(detected.Conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", detected.Conflicts)
+	}
+	byName, byURL := detected.Conflicts[0], detected.Conflicts[1]
+	if !byName.SameName || byName.Dropped.URL != "https://b.example.com/v3/index.json" {
+		t.Errorf("unexpected same-name conflict %+v", byName)
+	}
+	if byURL.SameName || byURL.Kept.Name != "corp" || byURL.Dropped.Name != "mirror" {
+		t.Errorf("unexpected same-URL conflict %+v", byURL)
+	}
+	if !strings.Contains(byURL.Explain(), `under "mirror" are ignored`) {
+		t.Errorf("explanation should say the dropped name's settings are ignored: %s", byURL.Explain())
+	}
+}
+
+func TestDetectSources_NugetOrgDisabled(t *testing.T) {
+	setNugetOrgDisabled(true)
+	defer setNugetOrgDisabled(false)
+
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "nuget.config"), []byte(`<configuration>
+  <packageSources>
+    <clear />
+    <add key="nuget.org" value="https://api.nuget.org/v3/index.json" />
+    <add key="corp" value="https://pkgs.example.com/v3/index.json" />
+  </packageSources>
+</configuration>`), 0644)
+	if got := DetectSources(root).Sources; len(got) != 1 || got[0].Name != "corp" {
+		t.Errorf("expected only corp, got %+v", got)
+	}
+
+	// With nothing configured there is no nuget.org fallback either.
+	empty := t.TempDir()
+	os.WriteFile(filepath.Join(empty, "nuget.config"), []byte(`<configuration>
+  <packageSources>
+    <clear />
+  </packageSources>
+</configuration>`), 0644)
+	if got := DetectSources(empty).Sources; len(got) != 0 {
+		t.Errorf("expected no sources, got %+v", got)
+	}
+}
+
+func TestIsNugetOrgURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://api.nuget.org/v3/index.json":      true,
+		"https://www.nuget.org/api/v2":             true,
+		"https://nuget.org/api/v2/":                true,
+		"https://pkgs.example.com/v3/index.json":   false,
+		"https://nuget.org.example.com/index.json": false,
+		"C:/packages": false,
+	}
+	for raw, want := range tests {
+		if got := isNugetOrgURL(raw); got != want {
+			t.Errorf("isNugetOrgURL(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
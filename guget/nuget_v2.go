@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NuGet v2 feeds expose an OData (Atom) API instead of a v3 service index.
+// Old TeamCity, ProGet and NuGet.Server instances often offer nothing else,
+// so guget can search them and look up package versions through it:
+//
+//	{base}/Search()?searchTerm='json'&includePrerelease=false&$top=20
+//	{base}/FindPackagesById()?id='Newtonsoft.Json'
+
+// isV2SourceURL reports whether a source URL names a v2 endpoint, e.g.
+// https://www.nuget.org/api/v2 or a TeamCity .../FeedService.svc.
+func isV2SourceURL(sourceURL string) bool {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return false
+	}
+	p := strings.ToLower(strings.TrimRight(u.Path, "/"))
+	return strings.HasSuffix(p, "/api/v2") || strings.HasSuffix(p, "/v2") || strings.HasSuffix(p, ".svc")
+}
+
+// odataService is the AtomPub service document at the root of a v2 feed.
+type odataService struct {
+	XMLName     xml.Name `xml:"service"`
+	Collections []struct {
+		Href string `xml:"href,attr"`
+	} `xml:"workspace>collection"`
+}
+
+// resolveV2 checks that the source answers the v2 API and switches the
+// service to it.
+func (s *NugetService) resolveV2() error {
+	var svc odataService
+	err := s.getStream(context.Background(), s.sourceURL, func(r io.Reader) error {
+		return xml.NewDecoder(r).Decode(&svc)
+	})
+	if err != nil {
+		return fmt.Errorf("fetching v2 service document: %w", err)
+	}
+	for _, c := range svc.Collections {
+		if strings.EqualFold(c.Href, "Packages") {
+			s.v2Base = strings.TrimRight(s.sourceURL, "/")
+			logDebug("[%s] v2 (OData) feed: %s", s.sourceName, s.v2Base)
+			return nil
+		}
+	}
+	return errors.New("v2 service document has no Packages collection")
+}
+
+// odataFeed is one page of v2 results.
+type odataFeed struct {
+	Count   int          `xml:"count"` // with $inlinecount=allpages
+	Links   []odataLink  `xml:"link"`
+	Entries []odataEntry `xml:"entry"`
+}
+
+type odataLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// next returns the URL of the following page, or "".
+func (f *odataFeed) next() string {
+	for _, l := range f.Links {
+		if l.Rel == "next" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+type odataEntry struct {
+	Title   string   `xml:"title"` // the package ID on feeds whose properties omit Id
+	Authors []string `xml:"author>name"`
+	Props   struct {
+		ID            string `xml:"Id"`
+		Version       string `xml:"Version"`
+		Description   string `xml:"Description"`
+		Tags          string `xml:"Tags"` // space-separated
+		ProjectURL    string `xml:"ProjectUrl"`
+		LicenseURL    string `xml:"LicenseUrl"`
+		Published     string `xml:"Published"`
+		Dependencies  string `xml:"Dependencies"`
+		DownloadCount int    `xml:"DownloadCount"`
+	} `xml:"properties"`
+}
+
+func (e *odataEntry) id() string {
+	if e.Props.ID != "" {
+		return e.Props.ID
+	}
+	return strings.TrimSpace(e.Title)
+}
+
+func (e *odataEntry) authors() []string {
+	var out []string
+	for _, name := range e.Authors {
+		for _, a := range strings.Split(name, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				out = append(out, a)
+			}
+		}
+	}
+	return out
+}
+
+// published parses an Edm.DateTime, which usually has no zone. v2 feeds
+// mark unlisted versions with a 1900 date; those return the zero time.
+func (e *odataEntry) published() time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(e.Props.Published)); err == nil {
+			if t.Year() <= 1900 {
+				return time.Time{}
+			}
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseV2Dependencies splits a v2 Dependencies value —
+// "id:range:framework|id:range:framework" — into dependency groups in the
+// order their frameworks first appear. An entry with an empty id declares a
+// framework without dependencies.
+func parseV2Dependencies(raw string) []dependencyGroup {
+	var groups []dependencyGroup
+	index := map[string]int{}
+	for _, entry := range strings.Split(raw, "|") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+		id, rng, fw := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), strings.TrimSpace(parts[2])
+		i, ok := index[strings.ToLower(fw)]
+		if !ok {
+			i = len(groups)
+			index[strings.ToLower(fw)] = i
+			groups = append(groups, dependencyGroup{TargetFramework: fw})
+		}
+		if id != "" {
+			groups[i].Dependencies = append(groups[i].Dependencies, packageDependency{ID: id, Range: rng})
+		}
+	}
+	return groups
+}
+
+// odataString quotes s as an OData string literal.
+func odataString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// getODataFeed fetches one page of v2 results.
+func (s *NugetService) getODataFeed(ctx context.Context, u string) (*odataFeed, error) {
+	var feed odataFeed
+	err := s.getStream(ctx, u, func(r io.Reader) error {
+		return xml.NewDecoder(r).Decode(&feed)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// searchV2 runs a v2 Search() for the latest version of each matching
+// package. The total is -1 unless withCount asks the feed to count matches.
+func (s *NugetService) searchV2(query string, skip, take int, withCount bool) ([]SearchResult, int, error) {
+	prerelease := searchPrerelease.Load()
+	latest := "IsLatestVersion"
+	if prerelease {
+		latest = "IsAbsoluteLatestVersion"
+	}
+	// Build URL manually — see searchADOLocal; v2 servers want a literal "$".
+	searchURL := s.v2Base + "/Search()" +
+		"?searchTerm=" + url.QueryEscape(odataString(query)) +
+		"&targetFramework=" + url.QueryEscape(odataString("")) +
+		"&includePrerelease=" + strconv.FormatBool(prerelease) +
+		"&$filter=" + latest +
+		"&$skip=" + strconv.Itoa(skip) +
+		"&$top=" + strconv.Itoa(take) +
+		"&semVerLevel=2.0.0"
+	if withCount {
+		searchURL += "&$inlinecount=allpages"
+	}
+	logDebug("[%s] v2 search query=%q skip=%d take=%d", s.sourceName, query, skip, take)
+	feed, err := s.getODataFeed(context.Background(), searchURL)
+	if err != nil {
+		return nil, 0, err
+	}
+	results := make([]SearchResult, 0, len(feed.Entries))
+	for _, e := range feed.Entries {
+		results = append(results, SearchResult{
+			ID:             e.id(),
+			Version:        e.Props.Version,
+			Description:    e.Props.Description,
+			Authors:        e.authors(),
+			Tags:           strings.Fields(e.Props.Tags),
+			TotalDownloads: e.Props.DownloadCount,
+			Versions:       []searchVersion{{Version: e.Props.Version}},
+			Source:         s.sourceName,
+		})
+	}
+	total := -1
+	if withCount {
+		total = feed.Count
+		if total < skip+len(results) {
+			// Not every server counts; assume more while pages are full.
+			total = skip + len(results)
+			if len(results) == take {
+				total++
+			}
+		}
+	}
+	logDebug("[%s] v2 search returned %d results", s.sourceName, len(results))
+	return results, total, nil
+}
+
+// searchExactV2 is searchExact for v2 feeds: FindPackagesById() lists every
+// version, paged through "next" links. v2 has no vulnerability or
+// deprecation data.
+func (s *NugetService) searchExactV2(ctx context.Context, packageID string) (*PackageInfo, error) {
+	logDebug("[%s] looking up %q via v2 FindPackagesById", s.sourceName, packageID)
+	next := s.v2Base + "/FindPackagesById()?id=" + url.QueryEscape(odataString(packageID)) + "&semVerLevel=2.0.0"
+
+	var versions []PackageVersion
+	var latest, latestStable *odataEntry
+	for page := 0; next != "" && page < 100; page++ {
+		feed, err := s.getODataFeed(ctx, next)
+		if err != nil {
+			var he *httpStatusError
+			if errors.As(err, &he) && he.Code == http.StatusNotFound {
+				return nil, fmt.Errorf("package %q %w", packageID, errPackageNotFound)
+			}
+			return nil, err
+		}
+		for i := range feed.Entries {
+			e := &feed.Entries[i]
+			sv := ParseSemVer(e.Props.Version)
+			if latest == nil || sv.IsNewerThan(ParseSemVer(latest.Props.Version)) {
+				latest = e
+			}
+			if !sv.IsPreRelease() && (latestStable == nil || sv.IsNewerThan(ParseSemVer(latestStable.Props.Version))) {
+				latestStable = e
+			}
+			groups := parseV2Dependencies(e.Props.Dependencies)
+			versions = append(versions, PackageVersion{
+				SemVer:           sv,
+				Published:        e.published(),
+				Frameworks:       groupFrameworks(groups),
+				DependencyGroups: groups,
+				License:          packageLicense("", e.Props.LicenseURL),
+			})
+		}
+		next = feed.next()
+	}
+	if latest == nil {
+		logDebug("[%s] %q has no versions in the v2 feed", s.sourceName, packageID)
+		return nil, fmt.Errorf("package %q %w", packageID, errPackageNotFound)
+	}
+	sortVersionsDesc(versions)
+
+	meta := latestStable
+	if meta == nil {
+		meta = latest
+	}
+	authors := NewSet[string]()
+	for _, a := range meta.authors() {
+		authors.Add(a)
+	}
+	tags := NewSet[string]()
+	for _, t := range strings.Fields(meta.Props.Tags) {
+		tags.Add(t)
+	}
+	id := meta.id()
+	if strings.EqualFold(id, packageID) {
+		id = packageID
+	}
+	logDebug("[%s] found %q: %d versions, latest stable=%s", s.sourceName, packageID, len(versions), meta.Props.Version)
+	return &PackageInfo{
+		ID:            id,
+		LatestVersion: meta.Props.Version,
+		Description:   meta.Props.Description,
+		Authors:       authors,
+		Tags:          tags,
+		ProjectURL:    meta.Props.ProjectURL,
+		Versions:      versions,
+	}, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const v2ServiceDoc = `<?xml version="1.0" encoding="utf-8"?>
+<service xml:base="%[1]s/" xmlns="http://www.w3.org/2007/app" xmlns:atom="http://www.w3.org/2005/Atom">
+  <workspace><atom:title>Default</atom:title><collection href="Packages"><atom:title>Packages</atom:title></collection></workspace>
+</service>`
+
+func v2Entry(id, version, published, deps string) string {
+	return fmt.Sprintf(`<entry>
+    <title type="text">%s</title>
+    <author><name>Jane Doe, Build Team</name></author>
+    <m:properties>
+      <d:Version>%s</d:Version>
+      <d:Description>Internal helpers</d:Description>
+      <d:Tags> internal tools </d:Tags>
+      <d:ProjectUrl>https://git.corp.example/helpers</d:ProjectUrl>
+      <d:Published m:type="Edm.DateTime">%s</d:Published>
+      <d:Dependencies>%s</d:Dependencies>
+      <d:DownloadCount m:type="Edm.Int32">42</d:DownloadCount>
+    </m:properties>
+  </entry>`, id, version, published, deps)
+}
+
+func v2Feed(next string, entries ...string) string {
+	link := ""
+	if next != "" {
+		link = `<link rel="next" href="` + next + `" />`
+	}
+	return `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:d="http://schemas.microsoft.com/ado/2007/08/dataservices" xmlns:m="http://schemas.microsoft.com/ado/2007/08/dataservices/metadata">
+  <m:count>7</m:count>
+  ` + strings.Join(entries, "\n") + link + `
+</feed>`
+}
+
+// newV2Server serves a NuGet.Server-style feed at /nuget with no v3 index.
+func newV2Server(t *testing.T) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/nuget":
+			fmt.Fprintf(w, v2ServiceDoc, srv.URL+"/nuget")
+		case "/nuget/FindPackagesById()":
+			if q.Get("id") != "'Corp.Helpers'" {
+				fmt.Fprint(w, v2Feed(""))
+			} else if q.Get("$skiptoken") == "" {
+				fmt.Fprint(w, v2Feed(srv.URL+"/nuget/FindPackagesById()?id='Corp.Helpers'&amp;$skiptoken='1.0.0'",
+					v2Entry("Corp.Helpers", "1.0.0", "1900-01-01T00:00:00", ""),
+					v2Entry("Corp.Helpers", "2.0.0-beta", "2024-03-01T10:00:00", "Newtonsoft.Json:[13.0.1, ):net6.0")))
+			} else {
+				fmt.Fprint(w, v2Feed("",
+					v2Entry("Corp.Helpers", "1.5.0", "2024-01-15T08:30:00.123", "Newtonsoft.Json:[12.0.1, ):net45|::netstandard2.0")))
+			}
+		case "/nuget/Search()":
+			if q.Get("searchTerm") != "'helpers'" || q.Get("$filter") != "IsLatestVersion" {
+				t.Errorf("unexpected search query %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, v2Feed("", v2Entry("Corp.Helpers", "1.5.0", "2024-01-15T08:30:00", "")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNugetServiceV2_FallsBackWithoutServiceIndex(t *testing.T) {
+	srv := newV2Server(t)
+	svc, err := NewNugetService(NugetSource{Name: "teamcity", URL: srv.URL + "/nuget"})
+	if err != nil {
+		t.Fatalf("NewNugetService: %v", err)
+	}
+	if svc.v2Base != srv.URL+"/nuget" {
+		t.Fatalf("v2Base = %q, want the source URL", svc.v2Base)
+	}
+
+	pkg, err := svc.SearchExact("Corp.Helpers")
+	if err != nil {
+		t.Fatalf("SearchExact: %v", err)
+	}
+	var got []string
+	for _, v := range pkg.Versions {
+		got = append(got, v.SemVer.String())
+	}
+	if want := []string{"2.0.0-beta", "1.5.0", "1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("versions = %v, want %v (both pages, newest first)", got, want)
+	}
+	if pkg.LatestVersion != "1.5.0" || pkg.ProjectURL != "https://git.corp.example/helpers" {
+		t.Errorf("metadata from %s / %q, want the latest stable 1.5.0", pkg.LatestVersion, pkg.ProjectURL)
+	}
+	if !pkg.Authors.Contains("Build Team") || !pkg.Tags.Contains("tools") {
+		t.Errorf("authors %v, tags %v", pkg.Authors, pkg.Tags)
+	}
+	if !pkg.Versions[2].Published.IsZero() {
+		t.Errorf("unlisted 1.0.0 published = %v, want zero", pkg.Versions[2].Published)
+	}
+	if fws := pkg.Versions[1].Frameworks; len(fws) != 2 {
+		t.Errorf("1.5.0 frameworks = %v, want net45 and netstandard2.0", fws)
+	}
+}
+
+func TestNugetServiceV2_Search(t *testing.T) {
+	srv := newV2Server(t)
+	svc, err := NewNugetService(NugetSource{Name: "teamcity", URL: srv.URL + "/nuget", ProtocolVersion: "2"})
+	if err != nil {
+		t.Fatalf("NewNugetService: %v", err)
+	}
+	results, err := svc.Search("helpers", 20)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "Corp.Helpers" || results[0].Version != "1.5.0" || results[0].TotalDownloads != 42 {
+		t.Fatalf("Search = %+v", results)
+	}
+	if _, total, err := svc.Browse("helpers", 0, 20); err != nil || total != 7 {
+		t.Errorf("Browse total = %d (%v), want the feed's count of 7", total, err)
+	}
+}
+
+func TestParseV2Dependencies(t *testing.T) {
+	got := parseV2Dependencies("A:[1.0, ):net45|B:2.0:net45|::netstandard2.0|C:1.0")
+	want := []dependencyGroup{
+		{TargetFramework: "net45", Dependencies: []packageDependency{{ID: "A", Range: "[1.0, )"}, {ID: "B", Range: "2.0"}}},
+		{TargetFramework: "netstandard2.0"},
+		{TargetFramework: "", Dependencies: []packageDependency{{ID: "C", Range: "1.0"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseV2Dependencies = %+v, want %+v", got, want)
+	}
+}
+
+func TestIsV2SourceURL(t *testing.T) {
+	for url, want := range map[string]bool{
+		"https://www.nuget.org/api/v2":                                   true,
+		"https://www.nuget.org/api/v2/":                                  true,
+		"https://tc.corp.example/guestAuth/app/nuget/v1/FeedService.svc": true,
+		"https://tc.corp.example/httpAuth/app/nuget/feed/_Root/main/v2":  true,
+		"https://api.nuget.org/v3/index.json":                            false,
+		"https://proget.corp.example/nuget/Internal/":                    false,
+	} {
+		if got := isV2SourceURL(url); got != want {
+			t.Errorf("isV2SourceURL(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
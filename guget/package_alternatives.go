@@ -0,0 +1,137 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// abandonedAfter is how long a package can go without a release before it
+// is flagged as possibly abandoned.
+const abandonedAfter = 3 * 365 * 24 * time.Hour
+
+// maxAlternatives caps the "consider switching to" list.
+const maxAlternatives = 10
+
+// packageAlternative is a package suggested in place of a deprecated or
+// abandoned one.
+type packageAlternative struct {
+	ID          string
+	Version     string // latest stable, as reported by search
+	Description string
+	Downloads   int
+	Source      string
+	Recommended bool     // named by the package's deprecation notice
+	SharedTags  []string // tags in common with the package being replaced
+}
+
+// LastPublished returns when the newest version was released, or the zero
+// time when no version has a known publish date.
+func (p *PackageInfo) LastPublished() time.Time {
+	var last time.Time
+	for _, v := range p.Versions {
+		// NuGet uses 1900-01-01 as its "unknown" publish date.
+		if v.Published.Year() >= 2005 && v.Published.After(last) {
+			last = v.Published
+		}
+	}
+	return last
+}
+
+// Abandoned reports whether the package has had no release for
+// abandonedAfter as of now.
+func (p *PackageInfo) Abandoned(now time.Time) bool {
+	last := p.LastPublished()
+	return !last.IsZero() && now.Sub(last) > abandonedAfter
+}
+
+// NeedsAlternative reports whether the package is worth replacing:
+// deprecated in the registry, or abandoned.
+func (p *PackageInfo) NeedsAlternative(now time.Time) bool {
+	return p.Deprecated || p.Abandoned(now)
+}
+
+// alternativeTags picks up to n of the package's tags to search for similar
+// packages. Tags that merely repeat the package name are skipped, since they
+// only find the package's own family.
+func (p *PackageInfo) alternativeTags(n int) []string {
+	nameParts := NewSet[string]()
+	for _, part := range strings.FieldsFunc(strings.ToLower(p.ID), func(r rune) bool { return r == '.' || r == '-' }) {
+		nameParts.Add(part)
+	}
+	var tags []string
+	for t := range p.Tags {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" && !nameParts.Contains(t) {
+			tags = append(tags, t)
+		}
+	}
+	sort.Strings(tags)
+	if len(tags) > n {
+		tags = tags[:n]
+	}
+	return tags
+}
+
+// rankAlternatives merges tag-search results into a suggestion list for
+// info. recommended, when non-nil, is the deprecation notice's alternate
+// package and always comes first; the rest are ordered by how many tags they
+// share with info, then by downloads. The package itself, duplicates, and
+// results sharing no tag are dropped.
+func rankAlternatives(info *PackageInfo, recommended *SearchResult, results []SearchResult) []packageAlternative {
+	own := NewSet[string]()
+	for t := range info.Tags {
+		own.Add(strings.ToLower(t))
+	}
+	seen := NewSet[string]()
+	seen.Add(strings.ToLower(info.ID))
+
+	var out []packageAlternative
+	if recommended != nil {
+		seen.Add(strings.ToLower(recommended.ID))
+		out = append(out, packageAlternative{
+			ID:          recommended.ID,
+			Version:     recommended.Version,
+			Description: recommended.Description,
+			Downloads:   recommended.TotalDownloads,
+			Source:      recommended.Source,
+			Recommended: true,
+		})
+	}
+
+	var similar []packageAlternative
+	for _, r := range results {
+		key := strings.ToLower(r.ID)
+		if seen.Contains(key) {
+			continue
+		}
+		seen.Add(key)
+		var shared []string
+		for _, t := range r.Tags {
+			if own.Contains(strings.ToLower(t)) {
+				shared = append(shared, strings.ToLower(t))
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+		similar = append(similar, packageAlternative{
+			ID:          r.ID,
+			Version:     r.Version,
+			Description: r.Description,
+			Downloads:   r.TotalDownloads,
+			Source:      r.Source,
+			SharedTags:  shared,
+		})
+	}
+	sort.SliceStable(similar, func(i, j int) bool {
+		if len(similar[i].SharedTags) != len(similar[j].SharedTags) {
+			return len(similar[i].SharedTags) > len(similar[j].SharedTags)
+		}
+		return similar[i].Downloads > similar[j].Downloads
+	})
+	out = append(out, similar...)
+	if len(out) > maxAlternatives {
+		out = out[:maxAlternatives]
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAbandoned(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("2.0.0"), Published: time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{SemVer: ParseSemVer("1.0.0"), Published: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	if !info.Abandoned(now) {
+		t.Error("last known release 4.5 years ago should count as abandoned")
+	}
+	info.Versions[1].Published = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	if info.Abandoned(now) {
+		t.Error("a release 1.5 years ago should not count as abandoned")
+	}
+	if (&PackageInfo{}).Abandoned(now) {
+		t.Error("unknown publish dates should not count as abandoned")
+	}
+}
+
+func TestAlternativeTags(t *testing.T) {
+	info := &PackageInfo{ID: "Contoso.Json", Tags: NewSet[string]()}
+	for _, tag := range []string{"json", "Serialization", "contoso", "parser", "fast"} {
+		info.Tags.Add(tag)
+	}
+	got := info.alternativeTags(2)
+	if len(got) != 2 || got[0] != "fast" || got[1] != "parser" {
+		t.Errorf("alternativeTags(2) = %q, want [fast parser]", got)
+	}
+}
+
+func TestRankAlternatives(t *testing.T) {
+	info := &PackageInfo{ID: "Old.Json", Tags: NewSet[string]()}
+	info.Tags.Add("json")
+	info.Tags.Add("serialization")
+	recommended := &SearchResult{ID: "New.Json", Version: "2.0.0"}
+	results := []SearchResult{
+		{ID: "Old.Json", Tags: StringOrArray{"json"}},
+		{ID: "New.Json", Tags: StringOrArray{"json"}},
+		{ID: "Popular.Json", Tags: StringOrArray{"JSON"}, TotalDownloads: 1000},
+		{ID: "Exact.Json", Tags: StringOrArray{"json", "serialization"}, TotalDownloads: 10},
+		{ID: "Unrelated", Tags: StringOrArray{"xml"}},
+	}
+	got := rankAlternatives(info, recommended, results)
+	var ids []string
+	for _, a := range got {
+		ids = append(ids, a.ID)
+	}
+	want := []string{"New.Json", "Exact.Json", "Popular.Json"}
+	if len(ids) != len(want) {
+		t.Fatalf("got %q, want %q", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("got %q, want %q", ids, want)
+		}
+	}
+	if !got[0].Recommended || got[1].Recommended {
+		t.Error("only the deprecation notice's alternate should be marked recommended")
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// nugetLicenseBase is where nuget.org points licenseUrl for packages that
+// declare an SPDX expression.
+const nugetLicenseBase = "https://licenses.nuget.org/"
+
+// packageLicense reduces a catalog entry's license fields to one string: the
+// SPDX expression when there is one, otherwise the license URL. Returns ""
+// when the package declares no license or uses the placeholder URL NuGet
+// inserts for packages that embed a license file.
+func packageLicense(expression, licenseURL string) string {
+	if expr := strings.TrimSpace(expression); expr != "" {
+		return expr
+	}
+	u := strings.TrimSpace(licenseURL)
+	if rest, ok := strings.CutPrefix(u, nugetLicenseBase); ok {
+		if expr, err := url.PathUnescape(rest); err == nil && expr != "" {
+			return expr
+		}
+	}
+	if strings.EqualFold(u, "https://aka.ms/deprecateLicenseUrl") {
+		return ""
+	}
+	return u
+}
+
+// isLicenseURL reports whether a packageLicense value is a URL rather than
+// an SPDX expression.
+func isLicenseURL(license string) bool {
+	return strings.HasPrefix(license, "http://") || strings.HasPrefix(license, "https://")
+}
+
+// licenseLink returns a URL describing license: the URL itself, or the
+// nuget.org page for an SPDX expression.
+func licenseLink(license string) string {
+	if license == "" || isLicenseURL(license) {
+		return license
+	}
+	return nugetLicenseBase + url.PathEscape(license)
+}
+
+// licenseLabel is the short form of a license for the package list: the
+// SPDX expression, or "custom" for a license published at a URL.
+func licenseLabel(license string) string {
+	if isLicenseURL(license) {
+		return "custom"
+	}
+	return license
+}
+
+// License returns the license declared by version v, or "" when unknown.
+func (p *PackageInfo) License(v SemVer) string {
+	for _, pv := range p.Versions {
+		if pv.SemVer.String() == v.String() {
+			return pv.License
+		}
+	}
+	return ""
+}
+
+// LicenseChange reports whether moving from one version to another changes
+// the declared license. Unknown licenses never count as a change, and
+// neither do two different URLs: many packages link a per-version copy of
+// the same license, so only changes involving an SPDX expression are
+// trustworthy.
+func (p *PackageInfo) LicenseChange(from, to SemVer) (oldLicense, newLicense string, changed bool) {
+	oldLicense, newLicense = p.License(from), p.License(to)
+	if oldLicense == "" || newLicense == "" || strings.EqualFold(oldLicense, newLicense) {
+		return oldLicense, newLicense, false
+	}
+	if isLicenseURL(oldLicense) && isLicenseURL(newLicense) {
+		return oldLicense, newLicense, false
+	}
+	return oldLicense, newLicense, true
+}
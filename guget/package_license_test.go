@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestPackageLicense(t *testing.T) {
+	tests := []struct {
+		expression, url, want string
+	}{
+		{"MIT", "https://licenses.nuget.org/MIT", "MIT"},
+		{"", "https://licenses.nuget.org/Apache-2.0%20OR%20MIT", "Apache-2.0 OR MIT"},
+		{"", "https://github.com/contoso/utils/blob/main/LICENSE", "https://github.com/contoso/utils/blob/main/LICENSE"},
+		{"", "https://aka.ms/deprecateLicenseUrl", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := packageLicense(tt.expression, tt.url); got != tt.want {
+			t.Errorf("packageLicense(%q, %q) = %q, want %q", tt.expression, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestLicenseChange(t *testing.T) {
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("4.0.0"), License: "BUSL-1.1"},
+		{SemVer: ParseSemVer("3.1.0"), License: "https://example.com/v3.1/LICENSE"},
+		{SemVer: ParseSemVer("3.0.0"), License: "https://example.com/v3.0/LICENSE"},
+		{SemVer: ParseSemVer("2.0.0"), License: "mit"},
+		{SemVer: ParseSemVer("1.0.0"), License: "MIT"},
+		{SemVer: ParseSemVer("0.9.0")},
+	}}
+	check := func(from, to string, want bool) {
+		t.Helper()
+		if _, _, got := info.LicenseChange(ParseSemVer(from), ParseSemVer(to)); got != want {
+			t.Errorf("LicenseChange(%s → %s) = %v, want %v", from, to, got, want)
+		}
+	}
+	check("1.0.0", "2.0.0", false) // same expression, different case
+	check("2.0.0", "4.0.0", true)
+	check("3.0.0", "3.1.0", false) // URLs only
+	check("3.1.0", "4.0.0", true)
+	check("0.9.0", "1.0.0", false) // unknown
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// metadataLint is a property a packable project leaves unset that nuget.org
+// and consumers expect of a published package.
+type metadataLint struct {
+	Property string // the property the fix adds
+	Reason   string
+	Example  string
+}
+
+// missingPackageMetadata lists the metadata p's package would be published
+// without. Projects that aren't packable have nothing missing.
+func (p *ParsedProject) missingPackageMetadata() []metadataLint {
+	if _, _, ok := p.packageIdentity(); !ok {
+		return nil
+	}
+	md := p.Metadata
+	var lints []metadataLint
+	if md.Description == "" {
+		lints = append(lints, metadataLint{"Description", "shown in search results; dotnet pack fills in \"Package Description\"", "A short summary of what the package does"})
+	}
+	if md.PackageLicenseExpression == "" && md.PackageLicenseFile == "" {
+		lints = append(lints, metadataLint{"PackageLicenseExpression", "without a license, consumers can't tell whether they may use the package", "MIT"})
+	}
+	if md.RepositoryUrl == "" {
+		lints = append(lints, metadataLint{"RepositoryUrl", "links the package to its source; Source Link and release notes lookups need it", "https://github.com/owner/repo"})
+	}
+	if md.PackageReleaseNotes == "" {
+		lints = append(lints, metadataLint{"PackageReleaseNotes", "tells consumers what changed in this version", "See CHANGELOG.md"})
+	}
+	return lints
+}
+
+// repositoryURLFromRemote turns a git remote into the browsable URL
+// RepositoryUrl expects: scp-style SSH remotes become https, and credentials
+// and a trailing .git are dropped. Returns "" for remotes it can't map, such
+// as local paths.
+func repositoryURLFromRemote(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if host, path, ok := strings.Cut(strings.TrimPrefix(remote, "git@"), ":"); ok && strings.HasPrefix(remote, "git@") && !strings.Contains(host, "/") {
+		return "https://" + host + "/" + strings.TrimSuffix(path, ".git")
+	}
+	u, err := url.Parse(remote)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	switch u.Scheme {
+	case "https", "http", "ssh", "git":
+	default:
+		return ""
+	}
+	u.Scheme, u.User = "https", nil
+	u.Host = u.Hostname()
+	u.Path = strings.TrimSuffix(u.Path, ".git")
+	return u.String()
+}
+
+// projectProperty is one property to write to a project file.
+type projectProperty struct {
+	Name  string
+	Value string
+}
+
+// xmlTextEscaper escapes a property value for element content.
+var xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// SetProjectProperties adds props to the first unconditional PropertyGroup
+// of filePath, outside any <Target> or <Choose>, after its last property and
+// at its indentation. Without such a group a new one is added after the
+// last PropertyGroup, or before </Project>.
+func SetProjectProperties(filePath string, props []projectProperty) error {
+	if len(props) == 0 {
+		return nil
+	}
+	file, err := readTextFile(filePath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filePath, err)
+	}
+	text := file.Text
+	elems, err := scanXML(text)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", filePath, err)
+	}
+	nl := "\n"
+	if file.CRLF {
+		nl = "\r\n"
+	}
+	unit := detectIndentUnit(strings.Split(text, "\n"))
+	if unit == "" {
+		unit = editorconfigIndent(filePath)
+	}
+	if unit == "" {
+		unit = defaultIndentUnit
+	}
+
+	project := -1
+	for i := range elems {
+		if elems[i].Parent == -1 && strings.EqualFold(elems[i].Name, "Project") {
+			project = i
+			break
+		}
+	}
+	if project < 0 || elems[project].SelfClosing {
+		return fmt.Errorf("could not find insertion point in %s", filePath)
+	}
+	projectIndent, _ := leadingIndent(text, elems[project].CloseStart)
+	outerIndent := projectIndent + unit
+	indent := outerIndent + unit
+
+	group, lastChild, lastGroup := -1, -1, -1
+	for i := range elems {
+		g := &elems[i]
+		if g.Parent != project || !strings.EqualFold(g.Name, "PropertyGroup") {
+			continue
+		}
+		lastGroup = i
+		if group >= 0 || g.SelfClosing || g.attr("Condition") != nil {
+			continue
+		}
+		group = i
+		for c := i + 1; c < len(elems) && elems[c].Start < g.End; c++ {
+			if elems[c].Parent == i {
+				if lastChild < 0 {
+					if ind, ok := leadingIndent(text, elems[c].Start); ok {
+						indent = ind
+					}
+				}
+				lastChild = c
+			}
+		}
+	}
+
+	var elements []string
+	for _, p := range props {
+		elements = append(elements, "<"+p.Name+">"+xmlTextEscaper.Replace(p.Value)+"</"+p.Name+">")
+	}
+
+	var edit textEdit
+	switch {
+	case group >= 0:
+		closeStart := elems[group].CloseStart
+		if _, ok := leadingIndent(text, closeStart); ok {
+			at := lineStart(text, closeStart)
+			edit = textEdit{Start: at, End: at, Text: indent + strings.Join(elements, nl+indent) + nl}
+		} else {
+			// A group written on one line gets the properties on that line.
+			at := closeStart
+			if lastChild >= 0 {
+				at = elems[lastChild].End
+			}
+			edit = textEdit{Start: at, End: at, Text: " " + strings.Join(elements, " ")}
+		}
+	default:
+		block := outerIndent + "<PropertyGroup>" + nl + indent + strings.Join(elements, nl+indent) + nl + outerIndent + "</PropertyGroup>" + nl
+		var at int
+		if lastGroup >= 0 {
+			at = lineEnd(text, elems[lastGroup].End)
+			if at == len(text) && !strings.HasSuffix(text, "\n") {
+				block = nl + strings.TrimSuffix(block, nl)
+			}
+		} else if _, ok := leadingIndent(text, elems[project].CloseStart); ok {
+			at = lineStart(text, elems[project].CloseStart)
+		} else {
+			at = elems[project].CloseStart
+			block = nl + block
+		}
+		edit = textEdit{Start: at, End: at, Text: block}
+	}
+	return writeTextFile(filePath, file, applyEdits(text, []textEdit{edit}))
+}
+
+// localPackageProject returns the project in projects that packs pkgID,
+// for packages a solution builds and consumes itself.
+func localPackageProject(projects []*ParsedProject, pkgID string) *ParsedProject {
+	for _, p := range projects {
+		if id, _, ok := p.packageIdentity(); ok && strings.EqualFold(id, pkgID) {
+			return p
+		}
+	}
+	return nil
+}
+
+// releaseNotesText strips the indentation a multi-line
+// <PackageReleaseNotes> picks up from the project file, so its lists and
+// headings read as markdown.
+func releaseNotesText(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	for i, ln := range lines {
+		lines[i] = strings.TrimSpace(ln)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// packageNote is a team's note and tags on a package, e.g. "pinned until
+// issue #123" tagged "blocked".
+type packageNote struct {
+	Note string   `json:"note,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+func (n packageNote) empty() bool { return n.Note == "" && len(n.Tags) == 0 }
+
+// packageNotes maps package ids to their notes.
+type packageNotes map[string]packageNote
+
+// notesPath is the notes file, kept in the repository so the whole team
+// sees the same notes.
+func notesPath(projectDir string) string {
+	return filepath.Join(projectDir, ".guget", "notes.json")
+}
+
+// loadPackageNotes reads the notes file. A missing file is not an error.
+func loadPackageNotes(path string) (packageNotes, error) {
+	notes := packageNotes{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return notes, nil
+	}
+	if err != nil {
+		return notes, err
+	}
+	if err := json.Unmarshal(data, &notes); err != nil {
+		return packageNotes{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return notes, nil
+}
+
+// savePackageNotes writes the notes file, creating its directory. Keys come
+// out sorted, so the file diffs cleanly.
+func savePackageNotes(path string, notes packageNotes) error {
+	data, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// For returns the note on id, matching case-insensitively.
+func (n packageNotes) For(id string) packageNote {
+	if note, ok := n[id]; ok {
+		return note
+	}
+	for key, note := range n {
+		if strings.EqualFold(key, id) {
+			return note
+		}
+	}
+	return packageNote{}
+}
+
+// Set replaces the note on id, or removes it when note is empty. An entry
+// under another casing of id is replaced too.
+func (n packageNotes) Set(id string, note packageNote) {
+	for key := range n {
+		if strings.EqualFold(key, id) {
+			delete(n, key)
+		}
+	}
+	if !note.empty() {
+		n[id] = note
+	}
+}
+
+// parseTags splits comma- or space-separated tags, dropping a leading '#'
+// and duplicates.
+func parseTags(s string) []string {
+	var tags []string
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		tag := strings.TrimPrefix(f, "#")
+		if tag != "" && !slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
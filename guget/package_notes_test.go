@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPackageNotes_SaveAndLoad(t *testing.T) {
+	path := notesPath(t.TempDir())
+	notes, err := loadPackageNotes(path)
+	if err != nil || len(notes) != 0 {
+		t.Fatalf("missing file: got %v, %v; want no notes and no error", notes, err)
+	}
+
+	notes.Set("Serilog", packageNote{Note: "pinned until issue #123", Tags: []string{"blocked"}})
+	notes.Set("Dapper", packageNote{Tags: []string{"orm"}})
+	if err := savePackageNotes(path, notes); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Index(string(data), "Dapper") > strings.Index(string(data), "Serilog") {
+		t.Errorf("expected sorted keys, got:\n%s", data)
+	}
+
+	loaded, err := loadPackageNotes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.For("serilog"); got.Note != "pinned until issue #123" || !reflect.DeepEqual(got.Tags, []string{"blocked"}) {
+		t.Errorf("For(serilog) = %+v", got)
+	}
+
+	loaded.Set("SERILOG", packageNote{})
+	if len(loaded) != 1 || !loaded.For("Serilog").empty() {
+		t.Errorf("an empty note should remove the entry under any casing, got %v", loaded)
+	}
+}
+
+func TestLoadPackageNotes_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	os.WriteFile(path, []byte(`{"Serilog": "not an object"}`), 0644)
+	if _, err := loadPackageNotes(path); err == nil {
+		t.Error("expected an error for a malformed notes file")
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got := parseTags(" #blocked, security  Blocked,,legacy ")
+	if want := []string{"blocked", "security", "legacy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTags = %v, want %v", got, want)
+	}
+}
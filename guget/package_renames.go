@@ -0,0 +1,61 @@
+package main
+
+import "strings"
+
+// builtinRenames maps well-known packages that were retired in favour of a
+// successor under a new id. Most are the Azure SDK's move to the Azure.*
+// namespace; the successors are not drop-in, but they are where the old
+// package's maintainers point.
+var builtinRenames = map[string]string{
+	"Microsoft.Azure.Storage.Blob":                      "Azure.Storage.Blobs",
+	"Microsoft.Azure.Storage.Queue":                     "Azure.Storage.Queues",
+	"Microsoft.Azure.Storage.File":                      "Azure.Storage.Files.Shares",
+	"Microsoft.Azure.Storage.Common":                    "Azure.Storage.Common",
+	"WindowsAzure.Storage":                              "Azure.Storage.Blobs",
+	"Microsoft.Azure.Cosmos.Table":                      "Azure.Data.Tables",
+	"Microsoft.Azure.DocumentDB":                        "Microsoft.Azure.Cosmos",
+	"Microsoft.Azure.DocumentDB.Core":                   "Microsoft.Azure.Cosmos",
+	"Microsoft.Azure.ServiceBus":                        "Azure.Messaging.ServiceBus",
+	"Microsoft.Azure.EventHubs":                         "Azure.Messaging.EventHubs",
+	"Microsoft.Azure.EventHubs.Processor":               "Azure.Messaging.EventHubs.Processor",
+	"Microsoft.Azure.EventGrid":                         "Azure.Messaging.EventGrid",
+	"Microsoft.Azure.KeyVault":                          "Azure.Security.KeyVault.Secrets",
+	"Microsoft.Azure.Search":                            "Azure.Search.Documents",
+	"Microsoft.Azure.Services.AppAuthentication":        "Azure.Identity",
+	"Microsoft.Azure.Management.Fluent":                 "Azure.ResourceManager",
+	"Microsoft.Azure.Management.ResourceManager":        "Azure.ResourceManager",
+	"Microsoft.IdentityModel.Clients.ActiveDirectory":   "Microsoft.Identity.Client",
+	"System.Data.SqlClient":                             "Microsoft.Data.SqlClient",
+	"Microsoft.Extensions.Http.Polly":                   "Microsoft.Extensions.Http.Resilience",
+	"Microsoft.CodeAnalysis.FxCopAnalyzers":             "Microsoft.CodeAnalysis.NetAnalyzers",
+	"Microsoft.AspNetCore.Authentication.AzureAD.UI":    "Microsoft.Identity.Web",
+	"Microsoft.AspNetCore.Authentication.AzureADB2C.UI": "Microsoft.Identity.Web",
+	"IdentityServer4":                                   "Duende.IdentityServer",
+}
+
+// packageRenames maps a retired package id, lowercased, to its successor.
+type packageRenames map[string]string
+
+// newPackageRenames returns the built-in renames with extra (from the
+// "renames" setting) applied over them. Mapping an id to "" drops a built-in
+// entry, for a team that has decided to stay on the old package.
+func newPackageRenames(extra map[string]string) packageRenames {
+	r := packageRenames{}
+	for from, to := range builtinRenames {
+		r[strings.ToLower(from)] = to
+	}
+	for from, to := range extra {
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if to == "" || strings.EqualFold(from, to) {
+			delete(r, strings.ToLower(from))
+			continue
+		}
+		r[strings.ToLower(from)] = to
+	}
+	return r
+}
+
+// To returns the package that replaces id, or "" when id was not renamed.
+func (r packageRenames) To(id string) string {
+	return r[strings.ToLower(id)]
+}
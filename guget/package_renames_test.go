@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestPackageRenames(t *testing.T) {
+	r := newPackageRenames(map[string]string{
+		"Contoso.Old":            "Contoso.New",
+		"System.Data.SqlClient":  "",
+		"Microsoft.Azure.Search": "microsoft.azure.search",
+	})
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"Microsoft.Azure.Storage.Blob", "Azure.Storage.Blobs"},
+		{"microsoft.azure.storage.blob", "Azure.Storage.Blobs"},
+		{"Contoso.Old", "Contoso.New"},
+		{"System.Data.SqlClient", ""},  // dropped by an empty successor
+		{"Microsoft.Azure.Search", ""}, // mapped to itself
+		{"Azure.Storage.Blobs", ""},
+	}
+	for _, tt := range tests {
+		if got := r.To(tt.id); got != tt.want {
+			t.Errorf("To(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
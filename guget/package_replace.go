@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type replaceOpKind int
+
+const (
+	replaceRemove       replaceOpKind = iota // drop the old package's element
+	replaceAddReference                      // add <PackageReference> for the new package
+	replaceAddVersion                        // add <PackageVersion> for the new package (CPM)
+)
+
+// replaceOp is one file edit of a package replacement.
+type replaceOp struct {
+	Kind    replaceOpKind
+	File    string
+	Version string // replaceAdd*: version to write; "" for a CPM reference
+}
+
+// replaceRow is one project's line in the replacement preview.
+type replaceRow struct {
+	Project    *ParsedProject
+	OldVersion string
+	DefinedIn  string // file holding the old package's version
+	NewVersion string // "" when the project is skipped
+	Note       string // why the project is skipped or only partly changed
+}
+
+// replacePlan swaps Old for New in a set of projects: the preview rows and
+// the file edits that carry it out.
+type replacePlan struct {
+	Old    string
+	New    string
+	Source string // where New was found
+	Info   *PackageInfo
+	Rows   []replaceRow
+	Ops    []replaceOp
+}
+
+// Files returns every file the plan writes, in order of first write.
+func (p replacePlan) Files() []string {
+	seen := NewSet[string]()
+	var files []string
+	for _, op := range p.Ops {
+		if !seen.Contains(op.File) {
+			seen.Add(op.File)
+			files = append(files, op.File)
+		}
+	}
+	return files
+}
+
+func findReference(p *ParsedProject, name string) (PackageReference, bool) {
+	for ref := range p.Packages {
+		if strings.EqualFold(ref.Name, name) {
+			return ref, true
+		}
+	}
+	return PackageReference{}, false
+}
+
+// isCPMFile reports whether file is p's Directory.Packages.props.
+func isCPMFile(p *ParsedProject, file string) bool {
+	for _, t := range p.AddTargets {
+		if t.Kind == AddTargetCPM && t.FilePath == file {
+			return true
+		}
+	}
+	return false
+}
+
+// planReplacement works out how to replace oldName with info in every
+// project of scope. The new package goes where the old one was defined, so a
+// package versioned centrally stays central; its version is the newest
+// stable one compatible with every project sharing that definition. all is
+// the whole workspace, used to keep a shared PackageVersion that projects
+// outside scope still need.
+func planReplacement(all, scope []*ParsedProject, oldName string, info *PackageInfo, source string) replacePlan {
+	plan := replacePlan{Old: oldName, New: info.ID, Source: source, Info: info}
+
+	inScope := NewSet[*ParsedProject]()
+	defTargets := make(map[string]Set[TargetFramework])
+	for _, p := range scope {
+		if p.Legacy || p.LoadErr != nil {
+			continue
+		}
+		if _, ok := findReference(p, oldName); !ok {
+			continue
+		}
+		inScope.Add(p)
+		def := p.SourceFileForPackage(oldName)
+		if defTargets[def] == nil {
+			defTargets[def] = NewSet[TargetFramework]()
+		}
+		for fw := range p.TargetFrameworks {
+			defTargets[def].Add(fw)
+		}
+	}
+
+	seenOp := NewSet[replaceOp]()
+	add := func(op replaceOp) {
+		if !seenOp.Contains(op) {
+			seenOp.Add(op)
+			plan.Ops = append(plan.Ops, op)
+		}
+	}
+	for _, p := range scope {
+		if !inScope.Contains(p) {
+			continue
+		}
+		ref, _ := findReference(p, oldName)
+		def := p.SourceFileForPackage(oldName)
+		row := replaceRow{Project: p, OldVersion: ref.Version.String(), DefinedIn: def}
+		target := info.LatestStableForFramework(defTargets[def])
+		if target == nil {
+			row.Note = "no stable version of " + info.ID + " supports its target frameworks"
+			plan.Rows = append(plan.Rows, row)
+			continue
+		}
+		row.NewVersion = target.SemVer.String()
+		_, hasNew := findReference(p, info.ID)
+		if hasNew {
+			row.Note = "already references " + info.ID + "; " + oldName + " is only removed"
+		}
+
+		if def != p.FilePath && isCPMFile(p, def) {
+			add(replaceOp{Kind: replaceRemove, File: p.FilePath})
+			if !hasNew {
+				add(replaceOp{Kind: replaceAddReference, File: p.FilePath})
+			}
+			// The central PackageVersion can only go once nothing outside
+			// the replacement still uses it.
+			if !sharedDefinitionUsedElsewhere(all, inScope, oldName, def) {
+				add(replaceOp{Kind: replaceRemove, File: def})
+			}
+			if !definesPackage(all, info.ID, def) {
+				add(replaceOp{Kind: replaceAddVersion, File: def, Version: row.NewVersion})
+			}
+		} else {
+			add(replaceOp{Kind: replaceRemove, File: def})
+			if !hasNew {
+				add(replaceOp{Kind: replaceAddReference, File: def, Version: row.NewVersion})
+			}
+		}
+		plan.Rows = append(plan.Rows, row)
+	}
+	return plan
+}
+
+// sharedDefinitionUsedElsewhere reports whether a project outside inScope
+// takes name's version from def.
+func sharedDefinitionUsedElsewhere(all []*ParsedProject, inScope Set[*ParsedProject], name, def string) bool {
+	for _, p := range all {
+		if inScope.Contains(p) {
+			continue
+		}
+		if _, ok := findReference(p, name); ok && p.SourceFileForPackage(name) == def {
+			return true
+		}
+	}
+	return false
+}
+
+// definesPackage reports whether any project already takes name's version
+// from def.
+func definesPackage(all []*ParsedProject, name, def string) bool {
+	for _, p := range all {
+		if _, ok := findReference(p, name); ok && p.SourceFileForPackage(name) == def {
+			return true
+		}
+	}
+	return false
+}
+
+// applyReplacePlan carries out every edit of plan as one transaction: if any
+// edit fails, each file is put back as it was and nothing is journaled. On
+// success the replacement is one entry in the session journal, so a single
+// undo reverts all of it.
+func applyReplacePlan(plan replacePlan) error {
+	files := plan.Files()
+	originals := make(map[string][]byte, len(files))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", f, err)
+		}
+		originals[f] = data
+	}
+
+	rec := ActionRecord{Action: "replace", Package: plan.Old, With: plan.New, Files: files}
+	for _, row := range plan.Rows {
+		if row.NewVersion != "" {
+			rec.Version = row.NewVersion
+			break
+		}
+	}
+
+	var err error
+	for _, op := range plan.Ops {
+		switch op.Kind {
+		case replaceRemove:
+			logInfo("replace: remove %s from %s", plan.Old, op.File)
+			err = RemovePackageReference(op.File, plan.Old)
+		case replaceAddReference:
+			logInfo("replace: add %s %s to %s", plan.New, op.Version, op.File)
+			err = AddPackageReference(op.File, plan.New, op.Version)
+		case replaceAddVersion:
+			logInfo("replace: add PackageVersion %s %s to %s", plan.New, op.Version, op.File)
+			err = AddPackageVersion(op.File, plan.New, op.Version)
+		}
+		if err != nil {
+			err = fmt.Errorf("%s: %w", filepath.Base(op.File), err)
+			break
+		}
+	}
+	if err != nil {
+		for _, f := range files {
+			if rerr := writeFileRetry(f, originals[f], 0644); rerr != nil {
+				logError("replace: could not roll back %s: %v", f, rerr)
+			}
+		}
+		sessionJournal.discard(files)
+		actionLog.record(rec, err)
+		return err
+	}
+	recordAction(rec, nil)
+	return nil
+}
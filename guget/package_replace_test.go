@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanAndApplyReplacement_CPM(t *testing.T) {
+	dir := t.TempDir()
+	props := filepath.Join(dir, "Directory.Packages.props")
+	os.WriteFile(props, []byte(`<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageVersion Include="Microsoft.Azure.Storage.Blob" Version="11.2.3" />
+  </ItemGroup>
+</Project>
+`), 0644)
+	var projects []*ParsedProject
+	for _, name := range []string{"Api", "Worker"} {
+		path := filepath.Join(dir, name, name+".csproj")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Microsoft.Azure.Storage.Blob" />
+  </ItemGroup>
+</Project>
+`), 0644)
+		p, err := ParseCsproj(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		projects = append(projects, p)
+	}
+	info := &PackageInfo{ID: "Azure.Storage.Blobs", Versions: []PackageVersion{
+		{SemVer: ParseSemVer("12.22.0")},
+		{SemVer: ParseSemVer("12.23.0-beta.1")},
+	}}
+
+	// Only Api: Worker still needs the central PackageVersion.
+	plan := planReplacement(projects, projects[:1], "Microsoft.Azure.Storage.Blob", info, "nuget.org")
+	for _, op := range plan.Ops {
+		if op.Kind == replaceRemove && op.File == props {
+			t.Error("the shared PackageVersion must stay while Worker uses it")
+		}
+	}
+	if len(plan.Rows) != 1 || plan.Rows[0].NewVersion != "12.22.0" {
+		t.Fatalf("rows = %+v", plan.Rows)
+	}
+
+	plan = planReplacement(projects, projects, "Microsoft.Azure.Storage.Blob", info, "nuget.org")
+	if got := len(plan.Files()); got != 3 {
+		t.Fatalf("plan writes %d files, want 3", got)
+	}
+	if err := applyReplacePlan(plan); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range append([]string{props}, projects[0].FilePath, projects[1].FilePath) {
+		data, _ := os.ReadFile(p)
+		text := string(data)
+		if strings.Contains(text, "Microsoft.Azure.Storage.Blob") || !strings.Contains(text, `Include="Azure.Storage.Blobs"`) {
+			t.Errorf("%s after replace:\n%s", filepath.Base(p), text)
+		}
+	}
+	if data, _ := os.ReadFile(props); !strings.Contains(string(data), `Version="12.22.0"`) {
+		t.Errorf("props should pin the new package centrally:\n%s", data)
+	}
+	if data, _ := os.ReadFile(projects[0].FilePath); strings.Contains(string(data), "Version=") {
+		t.Errorf("CPM project reference should stay version-less:\n%s", data)
+	}
+
+	// The whole replacement is one undo step.
+	entry, ok := sessionJournal.lastOpen()
+	if !ok || entry.Action != "replace" || len(entry.Files) != 3 {
+		t.Fatalf("journal entry = %+v, %v", entry, ok)
+	}
+	if _, err := sessionJournal.revert(entry.ID); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(props); !strings.Contains(string(data), "Microsoft.Azure.Storage.Blob") {
+		t.Errorf("undo should restore the props file:\n%s", data)
+	}
+}
+
+func TestPlanReplacement_NoCompatibleVersion(t *testing.T) {
+	p := &ParsedProject{
+		FileName:         "Legacy.csproj",
+		FilePath:         "/src/Legacy.csproj",
+		TargetFrameworks: NewSet[TargetFramework](),
+		Packages:         NewSet[PackageReference](),
+		PackageSources:   map[string]string{},
+	}
+	p.TargetFrameworks.Add(ParseTargetFramework("net472"))
+	p.Packages.Add(PackageReference{Name: "Old", Version: ParseSemVer("1.0.0")})
+	info := &PackageInfo{ID: "New", Versions: []PackageVersion{
+		{SemVer: ParseSemVer("2.0.0"), Frameworks: []TargetFramework{ParseTargetFramework("net8.0")}},
+	}}
+
+	plan := planReplacement([]*ParsedProject{p}, []*ParsedProject{p}, "Old", info, "")
+	if len(plan.Ops) != 0 || len(plan.Rows) != 1 || plan.Rows[0].NewVersion != "" || plan.Rows[0].Note == "" {
+		t.Errorf("plan = %+v, want the project skipped with a note", plan)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PackageRule constrains the updates guget suggests and writes for a package.
+type PackageRule struct {
+	// Pin stops guget suggesting or applying any update.
+	Pin bool `json:"pin,omitempty"`
+	// Major keeps updates within one major version, e.g. 8 for 8.x.
+	Major *int `json:"major,omitempty"`
+	// NoBulk leaves the package out of bulk updates such as update-all and
+	// the security update; it can still be updated on its own.
+	NoBulk bool `json:"noBulk,omitempty"`
+	// Reason is shown when the rule refuses an update.
+	Reason string `json:"reason,omitempty"`
+}
+
+// packageRules maps package ids to their rules. A key ending in "*" matches
+// every id starting with the text before it.
+type packageRules map[string]PackageRule
+
+// For returns the rule for id: an exact key first (case-insensitive), then
+// the longest matching prefix pattern.
+func (r packageRules) For(id string) PackageRule {
+	var best PackageRule
+	bestLen := -1
+	for key, rule := range r {
+		if strings.EqualFold(key, id) {
+			return rule
+		}
+		prefix, ok := strings.CutSuffix(key, "*")
+		if ok && len(prefix) > bestLen && strings.HasPrefix(strings.ToLower(id), strings.ToLower(prefix)) {
+			best, bestLen = rule, len(prefix)
+		}
+	}
+	return best
+}
+
+// Allows reports whether the rule permits updating to v.
+func (rule PackageRule) Allows(v SemVer) bool {
+	return !rule.Pin && (rule.Major == nil || v.Major == *rule.Major)
+}
+
+// Describe summarises the rule for the detail panel, e.g. "pinned" or
+// "8.x only, not in bulk updates".
+func (rule PackageRule) Describe() string {
+	var parts []string
+	if rule.Pin {
+		parts = append(parts, "pinned")
+	} else if rule.Major != nil {
+		parts = append(parts, fmt.Sprintf("%d.x only", *rule.Major))
+	}
+	if rule.NoBulk && !rule.Pin {
+		parts = append(parts, "not in bulk updates")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// heldBack returns why a bulk update to version to is refused: "pinned",
+// "not in bulk", or what the rule allows. Returns "" when it isn't.
+func (rule PackageRule) heldBack(to SemVer) string {
+	switch {
+	case rule.Pin:
+		return "pinned"
+	case rule.NoBulk:
+		return "not in bulk"
+	case !rule.Allows(to):
+		return rule.Describe()
+	}
+	return ""
+}
+
+// refusal explains why the rule refuses updating id to v, or returns "" when
+// it doesn't.
+func (rule PackageRule) refusal(id string, v SemVer) string {
+	if rule.Allows(v) {
+		return ""
+	}
+	msg := fmt.Sprintf("%s is %s in settings", id, rule.Describe())
+	if rule.Reason != "" {
+		msg += ": " + rule.Reason
+	}
+	return msg
+}
+
+// latestAllowed returns the newest version of p the rule permits, compatible
+// with every framework in targets — stable only unless prerelease is set.
+// Returns nil when none is.
+func (rule PackageRule) latestAllowed(p *PackageInfo, targets Set[TargetFramework], prerelease bool) *PackageVersion {
+	for i := range p.Versions {
+		v := &p.Versions[i]
+		if (v.SemVer.IsPreRelease() && !prerelease) || (rule.Major != nil && v.SemVer.Major != *rule.Major) {
+			continue
+		}
+		if v.supportsAll(targets) {
+			return v
+		}
+	}
+	return nil
+}
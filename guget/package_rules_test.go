@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestPackageRulesFor(t *testing.T) {
+	eight := 8
+	rules := packageRules{
+		"Newtonsoft.Json":                {Pin: true},
+		"Microsoft.*":                    {NoBulk: true},
+		"Microsoft.EntityFrameworkCore*": {Major: &eight},
+	}
+	if !rules.For("newtonsoft.json").Pin {
+		t.Error("exact keys should match case-insensitively")
+	}
+	if r := rules.For("Microsoft.EntityFrameworkCore.SqlServer"); r.Major == nil || r.NoBulk {
+		t.Errorf("got %+v, want the longest matching prefix", r)
+	}
+	if !rules.For("Microsoft.Extensions.Http").NoBulk {
+		t.Error("expected the Microsoft.* rule")
+	}
+	if r := rules.For("Serilog"); r.Pin || r.Major != nil || r.NoBulk {
+		t.Errorf("got %+v for an unmatched id, want no rule", r)
+	}
+}
+
+func TestPackageRuleAllows(t *testing.T) {
+	eight := 8
+	tests := []struct {
+		rule PackageRule
+		v    string
+		want bool
+	}{
+		{PackageRule{}, "9.0.0", true},
+		{PackageRule{Pin: true}, "8.0.1", false},
+		{PackageRule{Major: &eight}, "8.3.0", true},
+		{PackageRule{Major: &eight}, "9.0.0", false},
+		{PackageRule{NoBulk: true}, "9.0.0", true},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Allows(ParseSemVer(tt.v)); got != tt.want {
+			t.Errorf("%+v allows %s = %v, want %v", tt.rule, tt.v, got, tt.want)
+		}
+	}
+	if msg := (PackageRule{Major: &eight, Reason: "EF 9 needs .NET 9"}).refusal("EfCore", ParseSemVer("9.0.0")); msg != "EfCore is 8.x only in settings: EF 9 needs .NET 9" {
+		t.Errorf("refusal = %q", msg)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// projectAssets is the subset of obj/project.assets.json that guget reads.
+// The file is written by restore and lists every package in the resolved
+// graph, direct and transitive, keyed as "Id/Version".
+type projectAssets struct {
+	Libraries map[string]struct {
+		Type string `json:"type"`
+	} `json:"libraries"`
+	// Targets holds the resolved graph per target framework (and runtime).
+	Targets map[string]map[string]struct {
+		Type         string            `json:"type"`
+		Dependencies map[string]string `json:"dependencies"`
+	} `json:"targets"`
+	Project struct {
+		Frameworks map[string]struct {
+			Dependencies map[string]json.RawMessage `json:"dependencies"`
+		} `json:"frameworks"`
+	} `json:"project"`
+	// Logs holds the NuGet warnings and errors of the restore that wrote
+	// the file, whether it ran on its own or as part of a build.
+	Logs []struct {
+		Code      string `json:"code"` // e.g. NU1701
+		LibraryID string `json:"libraryId"`
+	} `json:"logs"`
+}
+
+// readProjectAssets reads the assets file of the project in projectDir, or
+// returns nil if it has not been restored.
+func readProjectAssets(projectDir string) *projectAssets {
+	data, err := os.ReadFile(filepath.Join(projectDir, "obj", "project.assets.json"))
+	if err != nil {
+		return nil
+	}
+	var assets projectAssets
+	if err := json.Unmarshal(data, &assets); err != nil {
+		logDebug("project.assets.json in %s: %v", projectDir, err)
+		return nil
+	}
+	return &assets
+}
+
+// packageIDs returns the lowercase IDs of the packages in the restore graph,
+// or nil if the project has not been restored.
+func (assets *projectAssets) packageIDs() map[string]bool {
+	if assets == nil {
+		return nil
+	}
+	ids := make(map[string]bool, len(assets.Libraries))
+	for key, lib := range assets.Libraries {
+		if lib.Type != "package" {
+			continue
+		}
+		id, _, _ := strings.Cut(key, "/")
+		ids[strings.ToLower(id)] = true
+	}
+	return ids
+}
+
+// warnings returns the NuGet codes the last restore logged against each
+// package, keyed by lowercase package ID, or nil if there are none.
+func (assets *projectAssets) warnings() map[string][]string {
+	if assets == nil {
+		return nil
+	}
+	var codes map[string][]string
+	for _, l := range assets.Logs {
+		if l.LibraryID == "" || l.Code == "" {
+			continue
+		}
+		id := strings.ToLower(l.LibraryID)
+		if slices.Contains(codes[id], l.Code) {
+			continue
+		}
+		if codes == nil {
+			codes = make(map[string][]string)
+		}
+		codes[id] = append(codes[id], l.Code)
+	}
+	for _, c := range codes {
+		slices.Sort(c)
+	}
+	return codes
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 )
@@ -23,9 +24,109 @@ func shouldSkipProjectDir(name string) bool {
 	return ok
 }
 
-// FindProjectFiles walks rootDir and returns all .csproj, .fsproj, and .vbproj paths,
-// skipping common build-output and metadata directories.
+// projectDiscovery is the set of projects to load and, when they came from
+// a solution, which solution folder each one sits in.
+type projectDiscovery struct {
+	Solution  string   // "" when the directory tree was walked
+	Workspace string   // the workspace file the repositories came from, if any
+	Roots     []string // paths to watch: the repositories, and any workspace file
+	Files     []string
+	Folders   map[string]string // project path → solution folder
+	Repos     map[string]string // project path → workspace repository name
+}
+
+// DiscoverProjects lists the projects under rootDir. With a workspace file
+// it loads every repository the file lists; with a solution it loads only
+// the projects that solution references. Otherwise it uses the single
+// workspace file, or failing that the single .sln or .slnx, in rootDir, and
+// walks the tree if there is neither.
+func DiscoverProjects(rootDir, solution string) (projectDiscovery, error) {
+	if solution == "" {
+		solution = findRootWorkspace(rootDir)
+	}
+	if isWorkspaceFile(solution) {
+		return discoverWorkspace(solution)
+	}
+	return discoverRepo(rootDir, solution)
+}
+
+// discoverRepo lists the projects of one repository: those in solution, or
+// in the only solution in rootDir, or every project under rootDir.
+func discoverRepo(rootDir, solution string) (projectDiscovery, error) {
+	if solution == "" {
+		solution = findRootSolution(rootDir)
+	}
+	if solution == "" {
+		files, err := walkProjectFiles(rootDir)
+		return projectDiscovery{Roots: []string{rootDir}, Files: files}, err
+	}
+
+	listed, err := parseSolution(solution)
+	if err != nil {
+		return projectDiscovery{}, err
+	}
+	logInfo("Loading projects from solution %s", filepath.Base(solution))
+	d := projectDiscovery{Solution: solution, Roots: []string{rootDir}, Folders: make(map[string]string, len(listed))}
+	for _, p := range listed {
+		if _, err := os.Stat(p.Path); err != nil {
+			logWarn("Solution %s lists %s, which could not be read: %v", filepath.Base(solution), p.Path, err)
+			continue
+		}
+		if _, dup := d.Folders[p.Path]; dup {
+			continue
+		}
+		d.Files = append(d.Files, p.Path)
+		d.Folders[p.Path] = p.Folder
+	}
+	return d, nil
+}
+
+// discoverWorkspace lists the projects of every repository in a workspace
+// file, each found as if guget were pointed at that repository. A project
+// reached from two repositories belongs to the first.
+func discoverWorkspace(workspace string) (projectDiscovery, error) {
+	repos, err := parseWorkspaceFile(workspace)
+	if err != nil {
+		return projectDiscovery{}, err
+	}
+	logInfo("Loading %d repositories from workspace %s", len(repos), filepath.Base(workspace))
+	d := projectDiscovery{
+		Workspace: workspace,
+		Roots:     []string{workspace},
+		Folders:   make(map[string]string),
+		Repos:     make(map[string]string),
+	}
+	seen := make(map[string]bool)
+	for _, repo := range repos {
+		rd, err := discoverRepo(repo.Path, "")
+		if err != nil {
+			logWarn("Workspace repository %s could not be scanned: %v", repo.Name, err)
+			continue
+		}
+		d.Roots = append(d.Roots, repo.Path)
+		for _, f := range rd.Files {
+			if seen[projectPathKey(f)] {
+				continue
+			}
+			seen[projectPathKey(f)] = true
+			d.Files = append(d.Files, f)
+			d.Folders[f] = rd.Folders[f]
+			d.Repos[f] = repo.Name
+		}
+	}
+	return d, nil
+}
+
+// FindProjectFiles returns the projects under rootDir, preferring the
+// solution in rootDir when there is exactly one.
 func FindProjectFiles(rootDir string) ([]string, error) {
+	d, err := DiscoverProjects(rootDir, "")
+	return d.Files, err
+}
+
+// walkProjectFiles walks rootDir and returns every project file (see isProjectFile),
+// skipping common build-output and metadata directories.
+func walkProjectFiles(rootDir string) ([]string, error) {
 	var projects []string
 	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -39,8 +140,7 @@ func FindProjectFiles(rootDir string) ([]string, error) {
 			return nil
 		}
 
-		ext := strings.ToLower(filepath.Ext(d.Name()))
-		if ext == ".csproj" || ext == ".fsproj" || ext == ".vbproj" {
+		if isProjectFile(d.Name()) {
 			projects = append(projects, path)
 		}
 		return nil
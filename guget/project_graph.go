@@ -0,0 +1,87 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// projectPathKey normalises a project path for comparison: absolute, cleaned,
+// and case-folded on Windows, where a ProjectReference often spells the path
+// differently from the file on disk.
+func projectPathKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.Clean(path)
+	if runtime.GOOS == "windows" {
+		path = strings.ToLower(path)
+	}
+	return path
+}
+
+// ProjectGraph is the <ProjectReference> graph between the loaded projects.
+// References may point at projects outside the workspace; those have no
+// ParsedProject and no references of their own.
+type ProjectGraph struct {
+	projects map[string]*ParsedProject   // path key → loaded project
+	refBy    map[string][]*ParsedProject // path key → projects referencing it, by file name
+}
+
+func buildProjectGraph(projects []*ParsedProject) *ProjectGraph {
+	g := &ProjectGraph{
+		projects: make(map[string]*ParsedProject, len(projects)),
+		refBy:    make(map[string][]*ParsedProject),
+	}
+	for _, p := range projects {
+		g.projects[projectPathKey(p.FilePath)] = p
+	}
+	for _, p := range projects {
+		for _, ref := range p.ProjectRefs {
+			key := projectPathKey(ref)
+			g.refBy[key] = append(g.refBy[key], p)
+		}
+	}
+	for _, refs := range g.refBy {
+		sort.SliceStable(refs, func(i, j int) bool {
+			return strings.ToLower(refs[i].FileName) < strings.ToLower(refs[j].FileName)
+		})
+	}
+	return g
+}
+
+// Project returns the loaded project at path, or nil when the path is not
+// part of the workspace.
+func (g *ProjectGraph) Project(path string) *ParsedProject {
+	return g.projects[projectPathKey(path)]
+}
+
+// ReferencedBy returns the loaded projects that reference the project at
+// path directly.
+func (g *ProjectGraph) ReferencedBy(path string) []*ParsedProject {
+	return g.refBy[projectPathKey(path)]
+}
+
+// Dependents returns every loaded project that references p directly or
+// through other projects, nearest first. These are the projects that pick up
+// a package change made in p.
+func (g *ProjectGraph) Dependents(p *ParsedProject) []*ParsedProject {
+	seen := map[string]bool{projectPathKey(p.FilePath): true}
+	var out []*ParsedProject
+	queue := []*ParsedProject{p}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, dep := range g.ReferencedBy(cur.FilePath) {
+			key := projectPathKey(dep.FilePath)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, dep)
+			queue = append(queue, dep)
+		}
+	}
+	return out
+}
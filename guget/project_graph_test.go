@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeProjectFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseCsproj_ProjectReferences(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "src", "App", "App.csproj")
+	writeProjectFile(t, app, `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup><LibsDir>..\..\libs</LibsDir></PropertyGroup>
+  <ItemGroup>
+    <ProjectReference Include="..\Core\Core.csproj" />
+    <ProjectReference Include="$(LibsDir)\Util\Util.csproj" />
+    <ProjectReference Include="..\Core\Core.csproj" />
+    <ProjectReference Include="$(Unknown)\X.csproj" />
+  </ItemGroup>
+</Project>`)
+
+	pp, err := ParseCsproj(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(root, "src", "Core", "Core.csproj"),
+		filepath.Join(root, "libs", "Util", "Util.csproj"),
+	}
+	if !reflect.DeepEqual(pp.ProjectRefs, want) {
+		t.Errorf("ProjectRefs = %v, want %v", pp.ProjectRefs, want)
+	}
+	if len(pp.Diagnostics) != 1 || pp.Diagnostics[0].Kind != DiagUnresolvedVariable {
+		t.Errorf("diagnostics = %+v, want one unresolved variable", pp.Diagnostics)
+	}
+}
+
+func TestProjectGraph_Dependents(t *testing.T) {
+	root := t.TempDir()
+	path := func(name string) string { return filepath.Join(root, name, name+".csproj") }
+	project := func(name string, refs ...string) *ParsedProject {
+		p := &ParsedProject{FileName: name + ".csproj", FilePath: path(name)}
+		for _, r := range refs {
+			p.ProjectRefs = append(p.ProjectRefs, path(r))
+		}
+		return p
+	}
+	core := project("Core")
+	data := project("Data", "Core")
+	web := project("Web", "Data", "Core", "External")
+	tests := project("Tests", "Web")
+	g := buildProjectGraph([]*ParsedProject{tests, web, data, core})
+
+	if g.Project(path("External")) != nil {
+		t.Error("External is not loaded and should have no project")
+	}
+	if got := g.ReferencedBy(core.FilePath); !reflect.DeepEqual(got, []*ParsedProject{data, web}) {
+		t.Errorf("ReferencedBy(Core) = %v, want Data and Web", projectFileNames(got))
+	}
+	if got := projectFileNames(g.Dependents(core)); !reflect.DeepEqual(got, []string{"Data.csproj", "Web.csproj", "Tests.csproj"}) {
+		t.Errorf("Dependents(Core) = %v, want nearest first", got)
+	}
+	if got := g.Dependents(tests); len(got) != 0 {
+		t.Errorf("Dependents(Tests) = %v, want none", projectFileNames(got))
+	}
+}
+
+func projectFileNames(projects []*ParsedProject) []string {
+	var out []string
+	for _, p := range projects {
+		out = append(out, p.FileName)
+	}
+	return out
+}
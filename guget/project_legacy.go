@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// msbuildNamespace is the xmlns declared by pre-SDK (ToolsVersion-style)
+// project files.
+const msbuildNamespace = "http://schemas.microsoft.com/developer/msbuild/2003"
+
+// rawAssemblyReference is a legacy <Reference> item. For NuGet packages
+// installed via packages.config, HintPath points into the solution's
+// packages folder (e.g. ..\packages\Newtonsoft.Json.13.0.1\lib\net45\...).
+type rawAssemblyReference struct {
+	Include  string `xml:"Include,attr"`
+	HintPath string `xml:"HintPath"`
+}
+
+type packagesConfig struct {
+	XMLName  xml.Name              `xml:"packages"`
+	Packages []packagesConfigEntry `xml:"package"`
+}
+
+type packagesConfigEntry struct {
+	ID              string `xml:"id,attr"`
+	Version         string `xml:"version,attr"`
+	TargetFramework string `xml:"targetFramework,attr"`
+}
+
+// isLegacyProject reports whether project is an old-style (non-SDK) project:
+// no Sdk attribute or SDK import, and either a ToolsVersion or the MSBuild
+// 2003 namespace.
+func isLegacyProject(project Project) bool {
+	if project.Sdk != "" {
+		return false
+	}
+	for _, imp := range project.Imports {
+		if imp.Sdk != "" {
+			return false
+		}
+	}
+	return project.ToolsVersion != "" || project.XMLName.Space == msbuildNamespace
+}
+
+// legacyTargetFramework converts a TargetFrameworkVersion value (v4.7.2) to
+// its short TFM form (net472). Returns "" if the value is not recognisable.
+func legacyTargetFramework(v string) string {
+	v = strings.TrimPrefix(strings.TrimSpace(strings.ToLower(v)), "v")
+	if v == "" || strings.Contains(v, "$(") {
+		return ""
+	}
+	digits := strings.ReplaceAll(v, ".", "")
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return "net" + digits
+}
+
+// parsePackagesConfig reads a packages.config file.
+func parsePackagesConfig(path string) ([]packagesConfigEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg packagesConfig
+	if err := unmarshalXMLText(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+	return cfg.Packages, nil
+}
+
+// hintPathPackageRe matches a packages-folder directory name such as
+// "Newtonsoft.Json.13.0.1" or "xunit.core.2.4.2-pre.12", splitting the
+// package ID from its version.
+var hintPathPackageRe = regexp.MustCompile(`^(.+?)\.(\d+(?:\.\d+){1,3}(?:-[0-9A-Za-z.-]+)?)$`)
+
+// packageFromHintPath extracts the package ID and version from a HintPath
+// that points into a packages folder. ok is false for non-package references.
+func packageFromHintPath(hintPath string) (id, version string, ok bool) {
+	parts := strings.FieldsFunc(hintPath, func(r rune) bool { return r == '\\' || r == '/' })
+	for i := 0; i < len(parts)-1; i++ {
+		if !strings.EqualFold(parts[i], "packages") {
+			continue
+		}
+		if m := hintPathPackageRe.FindStringSubmatch(parts[i+1]); m != nil {
+			return m[1], m[2], true
+		}
+	}
+	return "", "", false
+}
+
+// mergeLegacyPackages adds packages for a legacy project from packages.config
+// next to the project file, then from any <Reference> HintPaths that point at
+// a packages folder and were not already listed in packages.config.
+func mergeLegacyPackages(result *ParsedProject, project Project, absFilePath string) {
+	for _, pg := range project.PropertyGroups {
+		if fw := legacyTargetFramework(pg.Properties["TargetFrameworkVersion"]); fw != "" {
+			result.TargetFrameworks.Add(ParseTargetFramework(fw))
+		}
+	}
+
+	seen := NewSet[string]()
+	for ref := range result.Packages {
+		seen.Add(strings.ToLower(ref.Name))
+	}
+
+	configPath := filepath.Join(filepath.Dir(absFilePath), "packages.config")
+	if entries, err := parsePackagesConfig(configPath); err == nil {
+		result.PackagesConfig = configPath
+		for _, e := range entries {
+			if e.ID == "" || seen.Contains(strings.ToLower(e.ID)) {
+				continue
+			}
+			seen.Add(strings.ToLower(e.ID))
+			result.checkReferenceVersion(configPath, e.ID, e.Version)
+			result.Packages.Add(PackageReference{Name: e.ID, Version: ParseSemVer(e.Version)})
+			result.PackageSources[strings.ToLower(e.ID)] = configPath
+		}
+	} else if !os.IsNotExist(err) {
+		result.addDiagnostic(DiagSkippedImport, configPath, "packages.config could not be read: %v", err)
+	}
+
+	for _, ig := range project.ItemGroups {
+		for _, ref := range ig.References {
+			id, version, ok := packageFromHintPath(ref.HintPath)
+			if !ok || seen.Contains(strings.ToLower(id)) {
+				continue
+			}
+			seen.Add(strings.ToLower(id))
+			result.Packages.Add(PackageReference{Name: id, Version: ParseSemVer(version)})
+			result.PackageSources[strings.ToLower(id)] = absFilePath
+		}
+	}
+}
+
+// isPackagesConfig reports whether path is a packages.config file.
+func isPackagesConfig(path string) bool {
+	return strings.EqualFold(filepath.Base(path), "packages.config")
+}
+
+// canUpdatePackage reports whether guget can write a new version of pkgName
+// for p: SDK projects always can, legacy projects only for packages listed
+// in their packages.config.
+func (p *ParsedProject) canUpdatePackage(pkgName string) bool {
+	return !p.Legacy || isPackagesConfig(p.SourceFileForPackage(pkgName))
+}
+
+// updatePackagesConfig sets pkgName's version in packages.config and moves
+// the packages-folder paths of the projects next to it (HintPaths, and the
+// imports and checks of build/*.targets) from the old version's folder to
+// the new one, as Update-Package does. A package's lib subfolder and
+// assembly version are left as they are; a restore fetches the new folder.
+// Returns the project files it rewrote, including on failure, so they're
+// recorded with the update; fails when pkgName isn't listed.
+func updatePackagesConfig(configPath, pkgName, newVersion string) ([]string, error) {
+	file, err := readTextFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", configPath, err)
+	}
+	elems, err := scanXML(file.Text)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", configPath, err)
+	}
+	var edits []textEdit
+	id, oldVersion := "", ""
+	for _, e := range elems {
+		idAttr, v := e.attr("id"), e.attr("version")
+		if !strings.EqualFold(e.Name, "package") || idAttr == nil || v == nil || !strings.EqualFold(idAttr.Value, pkgName) {
+			continue
+		}
+		id, oldVersion = idAttr.Value, v.Value
+		if v.Value != newVersion {
+			edits = append(edits, textEdit{Start: v.ValueStart, End: v.ValueEnd, Text: newVersion})
+		}
+	}
+	if id == "" {
+		return nil, fmt.Errorf("%s is not listed in %s", pkgName, configPath)
+	}
+	if len(edits) == 0 {
+		return nil, nil
+	}
+	if err := writeTextFile(configPath, file, applyEdits(file.Text, edits)); err != nil {
+		return nil, err
+	}
+
+	// The packages folder is named <id>.<version>; match it as a whole path
+	// segment so Foo.1.0.0 doesn't match Bar.Foo.1.0.0 or Foo.1.0.0.1.
+	folder := regexp.MustCompile(`(?i)([\\/]` + regexp.QuoteMeta(id) + `\.)` + regexp.QuoteMeta(oldVersion) + `([\\/])`)
+	entries, err := os.ReadDir(filepath.Dir(configPath))
+	if err != nil {
+		return nil, err
+	}
+	var rewritten []string
+	for _, entry := range entries {
+		path := filepath.Join(filepath.Dir(configPath), entry.Name())
+		if entry.IsDir() || !isProjectFile(path) {
+			continue
+		}
+		proj, err := readTextFile(path)
+		if err != nil {
+			return rewritten, fmt.Errorf("read %s: %w", path, err)
+		}
+		text := folder.ReplaceAllString(proj.Text, "${1}"+newVersion+"${2}")
+		if text == proj.Text {
+			continue
+		}
+		rewritten = append(rewritten, path)
+		if err := writeTextFile(path, proj, text); err != nil {
+			return rewritten, err
+		}
+	}
+	return rewritten, nil
+}
+
+// updatePackageVersionFiles is UpdatePackageVersion, also returning the
+// files it rewrote besides filePath: for a packages.config, the projects
+// whose package paths moved.
+func updatePackageVersionFiles(filePath, pkgName, newVersion string) ([]string, error) {
+	if isPackagesConfig(filePath) {
+		return updatePackagesConfig(filePath, pkgName, newVersion)
+	}
+	return nil, UpdatePackageVersion(filePath, pkgName, newVersion)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// profileEntry is one package in a dependency profile. An empty Version
+// means "latest stable compatible with the new project".
+type profileEntry struct {
+	ID      string
+	Version string
+}
+
+// parseDependencyProfile reads a dependency profile: one package per line as
+// "Id" or "Id Version", with blank lines and # comments ignored.
+func parseDependencyProfile(r io.Reader) ([]profileEntry, error) {
+	var entries []profileEntry
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			entries = append(entries, profileEntry{ID: fields[0]})
+		case 2:
+			entries = append(entries, profileEntry{ID: fields[0], Version: fields[1]})
+		default:
+			return nil, fmt.Errorf("line %d: expected \"Id [Version]\", got %q", n, strings.TrimSpace(line))
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// runNewCommand implements `guget new`: it runs `dotnet new <template>` into
+// the project directory, then adds the profile's packages to every project
+// the template created. The TUI opens on that directory afterwards.
+func runNewCommand(flags BuiltFlags) error {
+	if flags.Template == "" {
+		return fmt.Errorf("guget new requires --template (e.g. --template webapi)")
+	}
+	dir, err := filepath.Abs(flags.ProjectDir)
+	if err != nil {
+		return err
+	}
+	if _, err := detectSDKs(); err != nil {
+		return err
+	}
+
+	var profile []profileEntry
+	if flags.Profile != "" {
+		f, err := os.Open(flags.Profile)
+		if err != nil {
+			return fmt.Errorf("opening profile: %w", err)
+		}
+		profile, err = parseDependencyProfile(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("reading profile %s: %w", flags.Profile, err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	before := make(map[string]bool)
+	if existing, err := walkProjectFiles(dir); err == nil {
+		for _, f := range existing {
+			before[f] = true
+		}
+	}
+
+	logInfo("dotnet new %s -o %s", flags.Template, dir)
+	cmd := exec.Command("dotnet", "new", flags.Template, "-o", dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("dotnet new %s: %w\n%s", flags.Template, err, strings.TrimSpace(string(out)))
+	}
+
+	after, err := walkProjectFiles(dir)
+	if err != nil {
+		return err
+	}
+	var created []string
+	for _, f := range after {
+		if !before[f] {
+			created = append(created, f)
+		}
+	}
+	logInfo("dotnet new created %d project(s)", len(created))
+	if len(profile) == 0 || len(created) == 0 {
+		return nil
+	}
+
+	resolve := newProfileResolver(dir)
+	for _, file := range created {
+		if err := applyDependencyProfile(file, profile, resolve); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyDependencyProfile adds each profile package to the project at
+// filePath, resolving missing versions with resolve.
+func applyDependencyProfile(filePath string, profile []profileEntry, resolve func(id string, targets Set[TargetFramework]) (string, error)) error {
+	project, err := ParseCsproj(filePath)
+	if err != nil {
+		return fmt.Errorf("parsing new project %s: %w", filePath, err)
+	}
+	existing := make(map[string]bool)
+	for ref := range project.Packages {
+		existing[strings.ToLower(ref.Name)] = true
+	}
+	for _, entry := range profile {
+		if existing[strings.ToLower(entry.ID)] {
+			continue
+		}
+		version := entry.Version
+		if version == "" {
+			if version, err = resolve(entry.ID, project.TargetFrameworks); err != nil {
+				return fmt.Errorf("resolving %s: %w", entry.ID, err)
+			}
+		}
+		if err := AddPackageReference(filePath, entry.ID, version); err != nil {
+			return fmt.Errorf("adding %s to %s: %w", entry.ID, filepath.Base(filePath), err)
+		}
+		logInfo("Added %s %s to %s", entry.ID, version, filepath.Base(filePath))
+	}
+	return nil
+}
+
+// newProfileResolver returns a resolver that looks up the newest stable
+// version compatible with a project's frameworks on the configured sources.
+func newProfileResolver(dir string) func(id string, targets Set[TargetFramework]) (string, error) {
+	var services []*NugetService
+	var mapping *PackageSourceMapping
+	loaded := false
+	return func(id string, targets Set[TargetFramework]) (string, error) {
+		if !loaded {
+			loaded = true
+			detected := DetectSources(dir)
+			mapping = detected.Mapping
+			for _, src := range detected.Sources {
+				svc, err := NewNugetService(src)
+				if err != nil {
+					logWarn("Failed to initialise NuGet source [%s]: %v", src.Name, err)
+					continue
+				}
+				services = append(services, svc)
+			}
+		}
+		for _, svc := range FilterServices(services, mapping, id) {
+			info, err := svc.SearchExact(id)
+			if err != nil {
+				continue
+			}
+			if v := info.LatestStableForFramework(targets); v != nil {
+				return v.SemVer.String(), nil
+			}
+			if v := info.LatestStable(); v != nil {
+				return v.SemVer.String(), nil
+			}
+		}
+		return "", fmt.Errorf("not found on any configured source")
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDependencyProfile(t *testing.T) {
+	got, err := parseDependencyProfile(strings.NewReader(`# web service baseline
+Serilog.AspNetCore 8.0.0
+Polly   # latest
+
+Swashbuckle.AspNetCore
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []profileEntry{
+		{ID: "Serilog.AspNetCore", Version: "8.0.0"},
+		{ID: "Polly"},
+		{ID: "Swashbuckle.AspNetCore"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := parseDependencyProfile(strings.NewReader("Polly 8.0.0 extra")); err == nil {
+		t.Error("expected an error for a line with three fields")
+	}
+}
+
+func TestApplyDependencyProfile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Svc.csproj")
+	os.WriteFile(file, []byte(`<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Swashbuckle.AspNetCore" Version="6.4.0" />
+  </ItemGroup>
+</Project>
+`), 0644)
+
+	var resolved []string
+	resolve := func(id string, targets Set[TargetFramework]) (string, error) {
+		resolved = append(resolved, id)
+		if !targets.Contains(ParseTargetFramework("net8.0")) {
+			t.Errorf("resolver got targets %v, want net8.0", targets.ToSlice())
+		}
+		return "8.5.0", nil
+	}
+	profile := []profileEntry{
+		{ID: "Serilog.AspNetCore", Version: "8.0.0"},
+		{ID: "Polly"},
+		{ID: "swashbuckle.aspnetcore"}, // already referenced
+	}
+	if err := applyDependencyProfile(file, profile, resolve); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resolved, []string{"Polly"}) {
+		t.Errorf("resolved %v, want only Polly", resolved)
+	}
+
+	project, err := ParseCsproj(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := make(map[string]string)
+	for ref := range project.Packages {
+		versions[ref.Name] = ref.Version.String()
+	}
+	want := map[string]string{"Swashbuckle.AspNetCore": "6.4.0", "Serilog.AspNetCore": "8.0.0", "Polly": "8.5.0"}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("packages = %v, want %v", versions, want)
+	}
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"cmp"
 	"encoding/xml"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -30,10 +35,62 @@ func writeFileRetry(path string, data []byte, perm os.FileMode) error {
 
 type ImportElement struct {
 	Project string `xml:"Project,attr"`
+	Sdk     string `xml:"Sdk,attr"` // set for SDK-resolved imports (e.g. Sdk="Microsoft.NET.Sdk")
+}
+
+type DiagnosticKind int
+
+const (
+	DiagSkippedImport      DiagnosticKind = iota // SDK import, missing file, unmatched wildcard
+	DiagUnresolvedVariable                       // $(Var) we cannot evaluate without MSBuild
+	DiagMalformedVersion                         // Version attribute that is not a valid NuGet version
+	DiagDuplicateReference                       // same package referenced twice in one file
+	DiagVersionConflict                          // same package versioned in two files of the import chain
+	DiagMissingSDK                               // target framework no installed .NET SDK can build
+)
+
+func (k DiagnosticKind) label() string {
+	switch k {
+	case DiagUnresolvedVariable:
+		return "unresolved variable"
+	case DiagMalformedVersion:
+		return "malformed version"
+	case DiagDuplicateReference:
+		return "duplicate reference"
+	case DiagVersionConflict:
+		return "version conflict"
+	case DiagMissingSDK:
+		return "missing SDK"
+	default:
+		return "skipped import"
+	}
+}
+
+// ParseDiagnostic records a non-fatal issue found while parsing a project or
+// one of its imported files. Diagnostics are surfaced in the diagnostics
+// overlay so users can see why something they expect is missing.
+type ParseDiagnostic struct {
+	Kind    DiagnosticKind
+	File    string // file containing the offending element
+	Message string
+}
+
+// VersionConflict records a package that is given a version in more than one
+// file of a project's import chain (the project file and an imported props
+// file, or two props files). The effective definition is the one guget reads
+// and edits; the redundant one can be removed.
+type VersionConflict struct {
+	Package          string
+	EffectiveFile    string
+	EffectiveVersion string
+	RedundantFile    string
+	RedundantVersion string
 }
 
 type Project struct {
 	XMLName        xml.Name        `xml:"Project"`
+	Sdk            string          `xml:"Sdk,attr"`
+	ToolsVersion   string          `xml:"ToolsVersion,attr"` // only set on legacy (non-SDK) projects
 	PropertyGroups []PropertyGroup `xml:"PropertyGroup"`
 	ItemGroups     []ItemGroup     `xml:"ItemGroup"`
 	Imports        []ImportElement `xml:"Import"`
@@ -79,19 +136,71 @@ func (pg *PropertyGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) er
 }
 
 type ItemGroup struct {
-	Condition         string                `xml:"Condition,attr"`
-	PackageReferences []rawPackageReference `xml:"PackageReference"`
-	PackageVersions   []rawPackageReference `xml:"PackageVersion"`
+	Condition         string                 `xml:"Condition,attr"`
+	PackageReferences []rawPackageReference  `xml:"PackageReference"`
+	PackageVersions   []rawPackageReference  `xml:"PackageVersion"`
+	GlobalReferences  []rawPackageReference  `xml:"GlobalPackageReference"`
+	References        []rawAssemblyReference `xml:"Reference"`
+	ProjectReferences []rawProjectReference  `xml:"ProjectReference"`
+}
+
+// rawProjectReference is a <ProjectReference> item naming another project
+// file, usually by a path relative to the referencing project.
+type rawProjectReference struct {
+	Include string `xml:"Include,attr"`
 }
 
 // rawPackageReference is used only for XML unmarshalling.
 // Both Include (new entry) and Update (modify existing) are captured so that
 // unconditional Update elements are not silently dropped.
 type rawPackageReference struct {
-	Include         string `xml:"Include,attr"`
-	Update          string `xml:"Update,attr"`
-	Version         string `xml:"Version,attr"`
-	VersionOverride string `xml:"VersionOverride,attr"`
+	Include         string               `xml:"Include,attr"`
+	Update          string               `xml:"Update,attr"`
+	Version         string               `xml:"Version,attr"`
+	VersionOverride string               `xml:"VersionOverride,attr"`
+	ExtraAttrs      []xml.Attr           `xml:",any,attr"` // GeneratePathProperty, Aliases, NoWarn, ...
+	ExtraElements   []rawMetadataElement `xml:",any"`      // the same metadata written as child elements
+
+	global bool // read from a <GlobalPackageReference>; set by parsePropsFile
+}
+
+type rawMetadataElement struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// ReferenceMetadata is a PackageReference attribute or child element other
+// than Include/Update/Version, e.g. Aliases="Legacy" or <NoWarn>NU1605</NoWarn>.
+type ReferenceMetadata struct {
+	Name  string
+	Value string
+}
+
+// metadata returns the extra attributes and child elements of r in document
+// order (attributes first).
+func (r rawPackageReference) metadata() []ReferenceMetadata {
+	var md []ReferenceMetadata
+	for _, a := range r.ExtraAttrs {
+		if !strings.EqualFold(a.Name.Local, "Condition") {
+			md = append(md, ReferenceMetadata{Name: a.Name.Local, Value: a.Value})
+		}
+	}
+	for _, e := range r.ExtraElements {
+		switch e.XMLName.Local {
+		case "Version", "VersionOverride":
+			continue
+		}
+		md = append(md, ReferenceMetadata{Name: e.XMLName.Local, Value: strings.TrimSpace(e.Value)})
+	}
+	return md
+}
+
+// kind returns how the package reaches projects that import the defining file.
+func (r rawPackageReference) kind() ReferenceKind {
+	if r.global {
+		return RefGlobal
+	}
+	return RefDirect
 }
 
 // effectiveName returns the package name from Include, falling back to Update.
@@ -125,11 +234,32 @@ func resolveProps(s string, props map[string]string) string {
 	return s
 }
 
+// ReferenceKind distinguishes how a package reaches a project.
+type ReferenceKind int
+
+const (
+	RefDirect        ReferenceKind = iota // PackageReference in the project or an imported props file
+	RefGlobal                             // GlobalPackageReference in Directory.Packages.props
+	RefTransitivePin                      // central PackageVersion pinning a transitive dependency
+)
+
+func (k ReferenceKind) label() string {
+	switch k {
+	case RefGlobal:
+		return "global"
+	case RefTransitivePin:
+		return "pinned"
+	default:
+		return ""
+	}
+}
+
 // PackageReference is the parsed, usable form with a real SemVer.
 type PackageReference struct {
 	Name    string
 	Version SemVer
-	Locked  bool // true when the version was specified as [x.y.z] exact pin in the project file
+	Locked  bool          // true when the version was specified as [x.y.z] exact pin in the project file
+	Kind    ReferenceKind // direct reference, global reference, or transitive pin
 }
 
 // isExactLock reports whether a raw version string is a NuGet exact-version pin ([x.y.z]).
@@ -150,6 +280,7 @@ type AddTarget struct {
 	FilePath    string
 	Kind        AddTargetKind
 	Description string // e.g., "this project only", "all projects under /path"
+	Framework   string // non-empty: add under a Condition for this target framework only
 }
 
 type ParsedProject struct {
@@ -157,8 +288,106 @@ type ParsedProject struct {
 	FilePath         string // full path to the .csproj/.fsproj file
 	TargetFrameworks Set[TargetFramework]
 	Packages         Set[PackageReference]
-	PackageSources   map[string]string // lowercase pkg name → absolute path of defining file
-	AddTargets       []AddTarget       // possible locations for adding new packages
+	TransitivePinned bool                           // CentralPackageTransitivePinningEnabled is set
+	PackageSources   map[string]string              // lowercase pkg name → absolute path of defining file
+	PackageMetadata  map[string][]ReferenceMetadata // lowercase pkg name → extra reference metadata
+	AddTargets       []AddTarget                    // possible locations for adding new packages
+	Diagnostics      []ParseDiagnostic              // non-fatal parse issues (skipped imports, etc.)
+	VersionConflicts []VersionConflict              // packages versioned in more than one file
+	LoadErr          error                          // set when the file itself could not be parsed
+	Legacy           bool                           // old-style (non-SDK) project; shown read-only
+	PackagesConfig   string                         // packages.config of a legacy project; its versions can be updated
+	SolutionFolder   string                         // solution folder it is listed under, e.g. "src/libs"
+	Repo             string                         // workspace repository it belongs to, if any
+	ProjectRefs      []string                       // absolute paths of <ProjectReference> targets
+	Metadata         ProjectMetadata                // identity properties from its PropertyGroups
+	RestoreWarnings  map[string][]string            // lowercase pkg name → NuGet codes from the last restore
+
+	definedVersions map[string]string // lowercase pkg name → raw version at PackageSources, while parsing
+}
+
+// newBrokenProject returns a placeholder for a project file that failed to
+// parse, so it can still be listed (with its error) alongside healthy ones.
+func newBrokenProject(filePath string, err error) *ParsedProject {
+	return &ParsedProject{
+		FileName:         filepath.Base(filePath),
+		FilePath:         filePath,
+		TargetFrameworks: NewSet[TargetFramework](),
+		Packages:         NewSet[PackageReference](),
+		PackageSources:   make(map[string]string),
+		LoadErr:          err,
+	}
+}
+
+// addDiagnostic appends a formatted diagnostic for file to the project.
+func (pp *ParsedProject) addDiagnostic(kind DiagnosticKind, file, format string, args ...any) {
+	pp.Diagnostics = append(pp.Diagnostics, ParseDiagnostic{Kind: kind, File: file, Message: fmt.Sprintf(format, args...)})
+}
+
+// recordDefinition remembers the raw version behind the effective definition
+// of a package so later definitions in other files can be checked against it.
+func (pp *ParsedProject) recordDefinition(pkgName, version string) {
+	if pp.definedVersions == nil {
+		pp.definedVersions = make(map[string]string)
+	}
+	pp.definedVersions[strings.ToLower(pkgName)] = version
+}
+
+// checkVersionConflict records a VersionConflict when pkgName, already
+// defined with a version in another file, is given a version again in file.
+func (pp *ParsedProject) checkVersionConflict(file, pkgName, version string) {
+	key := strings.ToLower(pkgName)
+	effectiveFile := pp.PackageSources[key]
+	effectiveVersion := pp.definedVersions[key]
+	if version == "" || effectiveVersion == "" || effectiveFile == file {
+		return
+	}
+	pp.VersionConflicts = append(pp.VersionConflicts, VersionConflict{
+		Package:          pkgName,
+		EffectiveFile:    effectiveFile,
+		EffectiveVersion: effectiveVersion,
+		RedundantFile:    file,
+		RedundantVersion: version,
+	})
+	pp.addDiagnostic(DiagVersionConflict, file, "%s %s is overridden by %s in %s", pkgName, version, effectiveVersion, filepath.Base(effectiveFile))
+}
+
+// recordMetadata stores the extra metadata of raw, if any, so it can be shown
+// in the detail panel.
+func (pp *ParsedProject) recordMetadata(raw rawPackageReference) {
+	md := raw.metadata()
+	if len(md) == 0 {
+		return
+	}
+	if pp.PackageMetadata == nil {
+		pp.PackageMetadata = make(map[string][]ReferenceMetadata)
+	}
+	pp.PackageMetadata[strings.ToLower(raw.effectiveName())] = md
+}
+
+// nugetVersionRe matches a plain or floating NuGet version (1.2.3, 1.2.3.4,
+// 1.2.*, 1.0.0-beta.1+build). Ranges are reduced to their lower bound by
+// ParseSemVer before matching.
+var nugetVersionRe = regexp.MustCompile(`^(\*|\d+(\.\d+){0,3}(\.\*)?)(-[0-9A-Za-z.*-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// checkReferenceVersion records a diagnostic when a package's raw version
+// string contains an unresolved MSBuild property or is not a parseable NuGet
+// version. Empty versions are left to the caller (they are normal in CPM).
+func (pp *ParsedProject) checkReferenceVersion(file, pkgName, version string) {
+	if version == "" {
+		return
+	}
+	if strings.Contains(version, "$(") {
+		pp.addDiagnostic(DiagUnresolvedVariable, file, "%s: version %q uses an MSBuild property that could not be resolved", pkgName, version)
+		return
+	}
+	raw := ParseSemVer(version).Raw
+	if raw == "" && (version[0] == '[' || version[0] == '(') {
+		return // open lower bound such as (,2.0]
+	}
+	if !nugetVersionRe.MatchString(raw) {
+		pp.addDiagnostic(DiagMalformedVersion, file, "%s: %q is not a valid NuGet version", pkgName, version)
+	}
 }
 
 // SourceFileForPackage returns the file path where pkgName is defined.
@@ -177,7 +406,7 @@ func ParseCsproj(filePath string) (*ParsedProject, error) {
 	}
 
 	var project Project
-	if err := xml.Unmarshal(data, &project); err != nil {
+	if err := unmarshalXMLText(data, &project); err != nil {
 		return nil, fmt.Errorf("failed to parse XML: %w", err)
 	}
 
@@ -195,7 +424,8 @@ func ParseCsproj(filePath string) (*ParsedProject, error) {
 		PackageSources:   make(map[string]string),
 	}
 
-	mergePropertyGroups(result, project.PropertyGroups)
+	mergePropertyGroups(result, absFilePath, project.PropertyGroups)
+	result.Metadata.merge(project.PropertyGroups)
 
 	projectDir := filepath.Dir(filePath)
 	visited := map[string]bool{absFilePath: true}
@@ -204,22 +434,43 @@ func ParseCsproj(filePath string) (*ParsedProject, error) {
 	// CPM projects declare <PackageReference Include="Pkg" /> without a Version;
 	// the version is defined centrally as <PackageVersion Include="Pkg" Version="x" />.
 	cpmVersions := make(map[string]string) // lowercase name → version string
+	cpmNames := make(map[string]string)    // lowercase name → name as written
+	var cpmGlobals []rawPackageReference
 	var cpmFilePath string
 	if dpp := findDirectoryPackagesProps(projectDir); dpp != "" {
 		if absDpp, err := filepath.Abs(dpp); err == nil {
 			cpmFilePath = absDpp
-			if refs, _, _, err := parsePropsFile(absDpp); err == nil {
+			if refs, _, propertyGroups, err := parsePropsFile(absDpp); err == nil {
 				for _, r := range refs {
+					if r.global {
+						cpmGlobals = append(cpmGlobals, r)
+						continue
+					}
 					if r.Version != "" {
 						cpmVersions[strings.ToLower(r.Include)] = r.Version
+						cpmNames[strings.ToLower(r.Include)] = r.Include
 					}
 				}
+				result.TransitivePinned = transitivePinningEnabled(propertyGroups)
 			}
 		}
 	}
+	if transitivePinningEnabled(project.PropertyGroups) {
+		result.TransitivePinned = true
+	}
 
+	seenRefs := make(map[string]bool)
 	for _, ig := range project.ItemGroups {
 		for _, raw := range ig.PackageReferences {
+			// Two Include entries for the same package under the same
+			// condition is an authoring error; restore warns with NU1504.
+			if raw.Include != "" {
+				dupKey := ig.Condition + "|" + strings.ToLower(raw.Include)
+				if seenRefs[dupKey] {
+					result.addDiagnostic(DiagDuplicateReference, absFilePath, "%s is referenced more than once", raw.Include)
+				}
+				seenRefs[dupKey] = true
+			}
 			version := raw.Version
 			sourceFile := filePath
 			switch {
@@ -236,15 +487,27 @@ func ParseCsproj(filePath string) (*ParsedProject, error) {
 					sourceFile = cpmFilePath
 				}
 			}
+			result.checkReferenceVersion(absFilePath, raw.effectiveName(), version)
 			result.Packages.Add(PackageReference{
 				Name:    raw.effectiveName(),
 				Version: ParseSemVer(version),
 				Locked:  isExactLock(version),
 			})
 			result.PackageSources[strings.ToLower(raw.effectiveName())] = sourceFile
+			result.recordDefinition(raw.effectiveName(), version)
+			result.recordMetadata(raw)
 		}
 	}
 
+	result.ProjectRefs = projectReferences(result, project, absFilePath)
+
+	// Legacy projects list packages in packages.config and as <Reference>
+	// HintPaths rather than PackageReference items.
+	if isLegacyProject(project) {
+		result.Legacy = true
+		mergeLegacyPackages(result, project, absFilePath)
+	}
+
 	// Implicit import: Directory.Build.props (walk up from project dir)
 	dbp := findDirectoryBuildProps(projectDir)
 	if dbp != "" {
@@ -254,13 +517,10 @@ func ParseCsproj(filePath string) (*ParsedProject, error) {
 	// Explicit <Import> elements in the project file
 	var resolvedImports []string
 	for _, imp := range project.Imports {
-		resolved, err := resolveImportPath(imp.Project, projectDir, projectDir)
-		if err != nil {
-			logDebug("Skipping import in %s: %v", filePath, err)
-			continue
+		for _, resolved := range expandImport(result, imp, absFilePath, projectDir, projectDir) {
+			collectPropsPackages(result, resolved, projectDir, visited)
+			resolvedImports = append(resolvedImports, resolved)
 		}
-		collectPropsPackages(result, resolved, projectDir, visited)
-		resolvedImports = append(resolvedImports, resolved)
 	}
 
 	// Post-process: imported props files (e.g. Directory.Build.props) may also
@@ -284,6 +544,50 @@ func ParseCsproj(filePath string) (*ParsedProject, error) {
 		}
 	}
 
+	// GlobalPackageReference items in Directory.Packages.props apply to every
+	// project without a PackageReference of their own.
+	for _, r := range cpmGlobals {
+		name := strings.ToLower(r.Include)
+		if _, exists := result.PackageSources[name]; exists {
+			continue
+		}
+		result.checkReferenceVersion(cpmFilePath, r.Include, r.Version)
+		result.Packages.Add(PackageReference{
+			Name:    r.Include,
+			Version: ParseSemVer(r.Version),
+			Locked:  isExactLock(r.Version),
+			Kind:    RefGlobal,
+		})
+		result.PackageSources[name] = cpmFilePath
+		result.recordMetadata(r)
+	}
+
+	// The last restore's assets file also records the NuGet warnings it
+	// logged against each package.
+	assets := readProjectAssets(projectDir)
+	result.RestoreWarnings = assets.warnings()
+
+	// With transitive pinning, central versions of packages the project only
+	// gets transitively still decide the resolved version. Only packages that
+	// appear in the last restore graph are listed, so unrelated central
+	// versions do not show up in every project.
+	if result.TransitivePinned && cpmFilePath != "" {
+		if graph := assets.packageIDs(); graph != nil {
+			for name, ver := range cpmVersions {
+				if _, direct := result.PackageSources[name]; direct || !graph[name] {
+					continue
+				}
+				result.Packages.Add(PackageReference{
+					Name:    cpmNames[name],
+					Version: ParseSemVer(ver),
+					Locked:  isExactLock(ver),
+					Kind:    RefTransitivePin,
+				})
+				result.PackageSources[name] = cpmFilePath
+			}
+		}
+	}
+
 	// Build AddTargets: possible locations for adding new packages.
 	// Use the visited map to include ALL transitively discovered props files.
 	absDBP := ""
@@ -313,6 +617,26 @@ func ParseCsproj(filePath string) (*ParsedProject, error) {
 	result.AddTargets = []AddTarget{
 		{FilePath: absFilePath, Kind: AddTargetProject, Description: "this project only"},
 	}
+	// Multi-targeted projects can also take a reference for a single framework.
+	// CPM repos are excluded: a conditional reference there still needs its
+	// version in Directory.Packages.props, which applies to every framework.
+	if absCPM == "" && len(result.TargetFrameworks) > 1 {
+		var fws []string
+		for tf := range result.TargetFrameworks {
+			if !strings.Contains(tf.Raw, "$(") {
+				fws = append(fws, tf.Raw)
+			}
+		}
+		sort.Strings(fws)
+		for _, fw := range fws {
+			result.AddTargets = append(result.AddTargets, AddTarget{
+				FilePath:    absFilePath,
+				Kind:        AddTargetProject,
+				Description: "this project, " + fw + " only",
+				Framework:   fw,
+			})
+		}
+	}
 	if absDBP != "" {
 		result.AddTargets = append(result.AddTargets, AddTarget{
 			FilePath:    absDBP,
@@ -344,21 +668,209 @@ func ParseCsproj(filePath string) (*ParsedProject, error) {
 		})
 	}
 
+	// Legacy projects are read-only, so there is nowhere to add packages.
+	if result.Legacy {
+		result.AddTargets = nil
+	}
+
+	result.definedVersions = nil
 	return result, nil
 }
 
-// mergePropertyGroups extracts target frameworks from PropertyGroup elements.
-func mergePropertyGroups(result *ParsedProject, groups []PropertyGroup) {
+// mergePropertyGroups extracts target frameworks from PropertyGroup elements
+// defined in file.
+func mergePropertyGroups(result *ParsedProject, file string, groups []PropertyGroup) {
 	for _, pg := range groups {
 		for _, fw := range strings.Split(pg.TargetFramework+";"+pg.TargetFrameworks, ";") {
 			fw = strings.TrimSpace(fw)
 			if fw != "" {
+				if strings.Contains(fw, "$(") {
+					result.addDiagnostic(DiagUnresolvedVariable, file, "target framework %q uses an MSBuild property that could not be resolved", fw)
+				}
 				result.TargetFrameworks.Add(ParseTargetFramework(fw))
 			}
 		}
 	}
 }
 
+// ProjectMetadata holds the identity properties a project sets. A field is
+// empty when neither the project nor its imports set it; see fields for the
+// values MSBuild falls back to.
+type ProjectMetadata struct {
+	RootNamespace string
+	AssemblyName  string
+	Authors       string
+	IsPackable    string
+	PackageId     string
+	// The package version comes from PackageVersion, else Version, else
+	// VersionPrefix and VersionSuffix.
+	PackageVersion string
+	Version        string
+	VersionPrefix  string
+	VersionSuffix  string
+	// Package metadata checked by missingPackageMetadata.
+	Description              string
+	PackageLicenseExpression string
+	PackageLicenseFile       string
+	RepositoryUrl            string
+	PackageReleaseNotes      string
+}
+
+// merge fills the fields still empty from groups, so the project file, read
+// first, wins over Directory.Build.props and other imports.
+func (md *ProjectMetadata) merge(groups []PropertyGroup) {
+	props := buildPropsMap(groups)
+	for _, f := range []struct {
+		field *string
+		name  string
+	}{
+		{&md.RootNamespace, "RootNamespace"},
+		{&md.AssemblyName, "AssemblyName"},
+		{&md.Authors, "Authors"},
+		{&md.IsPackable, "IsPackable"},
+		{&md.PackageId, "PackageId"},
+		{&md.PackageVersion, "PackageVersion"},
+		{&md.Version, "Version"},
+		{&md.VersionPrefix, "VersionPrefix"},
+		{&md.VersionSuffix, "VersionSuffix"},
+		{&md.Description, "Description"},
+		{&md.PackageLicenseExpression, "PackageLicenseExpression"},
+		{&md.PackageLicenseFile, "PackageLicenseFile"},
+		{&md.RepositoryUrl, "RepositoryUrl"},
+		{&md.PackageReleaseNotes, "PackageReleaseNotes"},
+	} {
+		if *f.field == "" {
+			*f.field = strings.TrimSpace(resolveProps(props[f.name], props))
+		}
+	}
+}
+
+// packageVersion is the version dotnet pack would give the package, and
+// whether the project sets it at all (the SDK default is 1.0.0).
+func (md ProjectMetadata) packageVersion() (string, bool) {
+	switch {
+	case md.PackageVersion != "":
+		return md.PackageVersion, true
+	case md.Version != "":
+		return md.Version, true
+	case md.VersionPrefix == "" && md.VersionSuffix == "":
+		return "1.0.0", false
+	}
+	version := cmp.Or(md.VersionPrefix, "1.0.0")
+	if md.VersionSuffix != "" {
+		version += "-" + md.VersionSuffix
+	}
+	return version, true
+}
+
+// metadataField is one row of a project's metadata. Default is set when the
+// project leaves the property unset and Value is what MSBuild uses instead.
+type metadataField struct {
+	Name    string
+	Value   string
+	Default bool
+}
+
+// fields lists the metadata of the project in file, filling unset
+// properties with the SDK defaults: the assembly is named after the project
+// file, and the root namespace, authors and package ID follow the assembly
+// name. packable is what IsPackable defaults to.
+func (md ProjectMetadata) fields(file string, packable bool) []metadataField {
+	assembly := md.AssemblyName
+	if assembly == "" {
+		assembly = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	field := func(name, value, fallback string) metadataField {
+		if value == "" {
+			return metadataField{Name: name, Value: fallback, Default: true}
+		}
+		return metadataField{Name: name, Value: value}
+	}
+	version, set := md.packageVersion()
+	return []metadataField{
+		field("AssemblyName", md.AssemblyName, assembly),
+		field("RootNamespace", md.RootNamespace, assembly),
+		field("PackageId", md.PackageId, assembly),
+		{Name: "Version", Value: version, Default: !set},
+		field("Authors", md.Authors, assembly),
+		field("IsPackable", md.IsPackable, strconv.FormatBool(packable)),
+	}
+}
+
+// metadataFields lists p's metadata with the defaults that apply to it.
+// Legacy projects and test projects (those referencing
+// Microsoft.NET.Test.Sdk) are not packable unless they say so.
+func (p *ParsedProject) metadataFields() []metadataField {
+	packable := !p.Legacy
+	for ref := range p.Packages {
+		if strings.EqualFold(ref.Name, "Microsoft.NET.Test.Sdk") {
+			packable = false
+		}
+	}
+	return p.Metadata.fields(p.FilePath, packable)
+}
+
+// packageIdentity returns the ID and version dotnet pack would produce for
+// p. ok is false when p isn't packable or its identity uses MSBuild
+// properties guget can't resolve.
+func (p *ParsedProject) packageIdentity() (id, version string, ok bool) {
+	if p.LoadErr != nil {
+		return "", "", false
+	}
+	packable := false
+	for _, f := range p.metadataFields() {
+		switch f.Name {
+		case "PackageId":
+			id = f.Value
+		case "Version":
+			version = f.Value
+		case "IsPackable":
+			packable = strings.EqualFold(f.Value, "true")
+		}
+	}
+	if !packable || strings.Contains(id, "$(") || strings.Contains(version, "$(") {
+		return "", "", false
+	}
+	return id, version, true
+}
+
+// transitivePinningEnabled reports whether CentralPackageTransitivePinningEnabled
+// is set to true in any of groups.
+func transitivePinningEnabled(groups []PropertyGroup) bool {
+	for _, pg := range groups {
+		if strings.EqualFold(strings.TrimSpace(pg.Properties["CentralPackageTransitivePinningEnabled"]), "true") {
+			return true
+		}
+	}
+	return false
+}
+
+// projectReferences resolves the <ProjectReference> items of a project file
+// to absolute paths, in document order and without duplicates.
+func projectReferences(result *ParsedProject, project Project, absFilePath string) []string {
+	props := buildPropsMap(project.PropertyGroups)
+	dir := filepath.Dir(absFilePath)
+	var refs []string
+	seen := make(map[string]bool)
+	for _, ig := range project.ItemGroups {
+		for _, pr := range ig.ProjectReferences {
+			if strings.TrimSpace(pr.Include) == "" {
+				continue
+			}
+			path, err := resolveImportPath(resolveProps(strings.TrimSpace(pr.Include), props), dir, dir)
+			if err != nil {
+				result.addDiagnostic(DiagUnresolvedVariable, absFilePath, "ProjectReference %s: %v", pr.Include, err)
+				continue
+			}
+			if key := projectPathKey(path); !seen[key] {
+				seen[key] = true
+				refs = append(refs, path)
+			}
+		}
+	}
+	return refs
+}
+
 // findDirectoryBuildProps walks up from startDir looking for Directory.Build.props.
 // Returns the full path if found, or "" if not found.
 func findDirectoryBuildProps(startDir string) string {
@@ -418,6 +930,98 @@ func resolveImportPath(rawPath, referringFileDir, projectDir string) (string, er
 	return filepath.Clean(resolved), nil
 }
 
+// isImportWildcard reports whether an import path uses MSBuild wildcards.
+func isImportWildcard(path string) bool {
+	return strings.ContainsAny(path, "*?")
+}
+
+// expandImport resolves a single <Import> element into the list of files it
+// refers to. SDK imports are skipped (they live inside the .NET SDK, not the
+// repo), wildcard paths are expanded with globImport, and anything that
+// cannot be resolved is recorded as a diagnostic on result rather than being
+// dropped silently. referringFile is the file containing the <Import>.
+func expandImport(result *ParsedProject, imp ImportElement, referringFile, referringDir, projectDir string) []string {
+	if imp.Sdk != "" {
+		logDebug("Skipping SDK import %q (Sdk=%s) in %s", imp.Project, imp.Sdk, referringFile)
+		result.addDiagnostic(DiagSkippedImport, referringFile, "skipped SDK import %q (Sdk=%s)", imp.Project, imp.Sdk)
+		return nil
+	}
+	resolved, err := resolveImportPath(imp.Project, referringDir, projectDir)
+	if err != nil {
+		logDebug("Skipping import in %s: %v", referringFile, err)
+		result.addDiagnostic(DiagUnresolvedVariable, referringFile, "skipped import: %v", err)
+		return nil
+	}
+	if !isImportWildcard(resolved) {
+		return []string{resolved}
+	}
+	matches, err := globImport(resolved)
+	if err != nil {
+		result.addDiagnostic(DiagSkippedImport, referringFile, "invalid import wildcard %q: %v", imp.Project, err)
+		return nil
+	}
+	if len(matches) == 0 {
+		result.addDiagnostic(DiagSkippedImport, referringFile, "import wildcard %q matched no files", imp.Project)
+	}
+	return matches
+}
+
+// globImport expands an import wildcard like filepath.Glob, except that a
+// "**" segment matches any number of directories, as it does in MSBuild.
+func globImport(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		return filepath.Glob(pattern)
+	}
+	sep := string(filepath.Separator)
+	segs := strings.Split(filepath.Clean(pattern), sep)
+	i := 0
+	for i < len(segs) && !isImportWildcard(segs[i]) {
+		i++
+	}
+	for _, seg := range segs[i:] {
+		if _, err := filepath.Match(seg, ""); err != nil {
+			return nil, err
+		}
+	}
+	root := strings.Join(segs[:i], sep)
+	if root == "" {
+		root = sep
+	}
+	var matches []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil // unreadable directories are skipped, like Glob does
+		}
+		rel, err := filepath.Rel(root, path)
+		if err == nil && matchGlobSegments(segs[i:], strings.Split(rel, sep)) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches, nil
+}
+
+// matchGlobSegments reports whether the path segments match the pattern
+// segments, where "**" stands for zero or more whole segments.
+func matchGlobSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for j := 0; j <= len(path); j++ {
+			if matchGlobSegments(pattern[1:], path[j:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	ok, _ := filepath.Match(pattern[0], path[0])
+	return ok && matchGlobSegments(pattern[1:], path[1:])
+}
+
 // parsePropsFile parses a .props file and returns its PackageReferences, Import
 // elements, and PropertyGroups.
 func parsePropsFile(filePath string) ([]rawPackageReference, []ImportElement, []PropertyGroup, error) {
@@ -426,7 +1030,7 @@ func parsePropsFile(filePath string) ([]rawPackageReference, []ImportElement, []
 		return nil, nil, nil, fmt.Errorf("failed to read props file: %w", err)
 	}
 	var project Project
-	if err := xml.Unmarshal(data, &project); err != nil {
+	if err := unmarshalXMLText(data, &project); err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to parse props XML: %w", err)
 	}
 	// Build a property map so $(PropName) in version strings can be resolved.
@@ -451,6 +1055,11 @@ func parsePropsFile(filePath string) ([]rawPackageReference, []ImportElement, []
 			r.Version = resolveProps(r.Version, props)
 			refs = append(refs, r)
 		}
+		for _, r := range ig.GlobalReferences {
+			r.Version = resolveProps(r.Version, props)
+			r.global = true
+			refs = append(refs, r)
+		}
 	}
 
 	// Second pass: conditional ItemGroups as a fallback for packages that have
@@ -480,6 +1089,16 @@ func parsePropsFile(filePath string) ([]rawPackageReference, []ImportElement, []
 			refs = append(refs, r)
 			seen[name] = true
 		}
+		for _, r := range ig.GlobalReferences {
+			name := strings.ToLower(r.effectiveName())
+			if name == "" || seen[name] {
+				continue
+			}
+			r.Version = resolveProps(r.Version, props)
+			r.global = true
+			refs = append(refs, r)
+			seen[name] = true
+		}
 	}
 
 	return refs, project.Imports, project.PropertyGroups, nil
@@ -502,34 +1121,39 @@ func collectPropsPackages(result *ParsedProject, propsPath, projectDir string, v
 	refs, imports, propertyGroups, err := parsePropsFile(absPath)
 	if err != nil {
 		logDebug("Failed to parse props file %s: %v", absPath, err)
+		result.addDiagnostic(DiagSkippedImport, absPath, "import could not be read: %v", err)
 		return
 	}
 
 	for _, raw := range refs {
+		result.checkReferenceVersion(absPath, raw.effectiveName(), raw.Version)
 		ref := PackageReference{
 			Name:    raw.effectiveName(),
 			Version: ParseSemVer(raw.Version),
 			Locked:  isExactLock(raw.Version),
+			Kind:    raw.kind(),
 		}
 		result.Packages.Add(ref)
 		key := strings.ToLower(raw.effectiveName())
 		// Only set source if not already defined (.csproj takes precedence)
 		if _, exists := result.PackageSources[key]; !exists {
 			result.PackageSources[key] = absPath
+			result.recordDefinition(raw.effectiveName(), raw.Version)
+			result.recordMetadata(raw)
+		} else {
+			result.checkVersionConflict(absPath, raw.effectiveName(), raw.Version)
 		}
 	}
 
-	mergePropertyGroups(result, propertyGroups)
+	mergePropertyGroups(result, absPath, propertyGroups)
+	result.Metadata.merge(propertyGroups)
 
 	// Recurse into nested imports
 	propsDir := filepath.Dir(absPath)
 	for _, imp := range imports {
-		resolved, err := resolveImportPath(imp.Project, propsDir, projectDir)
-		if err != nil {
-			logDebug("Skipping nested import in %s: %v", absPath, err)
-			continue
+		for _, resolved := range expandImport(result, imp, absPath, propsDir, projectDir) {
+			collectPropsPackages(result, resolved, projectDir, visited)
 		}
-		collectPropsPackages(result, resolved, projectDir, visited)
 	}
 }
 
@@ -554,171 +1178,288 @@ func ParsePropsAsProject(filePath string) (*ParsedProject, error) {
 		PackageSources:   make(map[string]string),
 	}
 
-	mergePropertyGroups(result, propertyGroups)
+	mergePropertyGroups(result, absPath, propertyGroups)
 
 	for _, raw := range refs {
+		result.checkReferenceVersion(absPath, raw.effectiveName(), raw.Version)
 		result.Packages.Add(PackageReference{
 			Name:    raw.effectiveName(),
 			Version: ParseSemVer(raw.Version),
 			Locked:  isExactLock(raw.Version),
+			Kind:    raw.kind(),
 		})
 		result.PackageSources[strings.ToLower(raw.effectiveName())] = absPath
+		result.recordMetadata(raw)
 	}
 
 	return result, nil
 }
 
-var versionAttrRe = regexp.MustCompile(`(Version\s*=\s*")[^"]*(")`)
-
-// RemovePackageReference removes a <PackageReference> line for pkgName from a
-// .csproj/.fsproj file without altering any other formatting.
+// RemovePackageReference removes the package item for pkgName (all of its
+// lines, if it spans several) from a .csproj/.fsproj or props file without
+// altering any other formatting. An item sharing its line with other
+// markup is cut out of the line alone.
 func RemovePackageReference(filePath, pkgName string) error {
-	data, err := os.ReadFile(filePath)
+	file, err := readTextFile(filePath)
 	if err != nil {
 		return fmt.Errorf("read %s: %w", filePath, err)
 	}
+	elems, err := scanXML(file.Text)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", filePath, err)
+	}
 
-	pkgNameRe := regexp.MustCompile(`(?i)Include\s*=\s*"` + regexp.QuoteMeta(pkgName) + `"`)
-
-	lines := strings.Split(string(data), "\n")
-	changed := false
-	out := lines[:0] // reuse the backing array in-place to avoid an extra allocation
-	for _, line := range lines {
-		if pkgNameRe.MatchString(line) {
-			changed = true
+	var edits []textEdit
+	for _, i := range packageElements(elems, pkgName) {
+		e := elems[i]
+		if aloneOnLines(file.Text, e.Start, e.End) {
+			edits = append(edits, textEdit{Start: lineStart(file.Text, e.Start), End: lineEnd(file.Text, e.End)})
 			continue
 		}
-		out = append(out, line)
+		// Take the whitespace before the item with it, so "<A /> <B />"
+		// becomes "<A />" rather than "<A /> ".
+		start := e.Start
+		for start > 0 && (file.Text[start-1] == ' ' || file.Text[start-1] == '\t') {
+			start--
+		}
+		edits = append(edits, textEdit{Start: start, End: e.End})
 	}
-
-	if !changed {
+	if len(edits) == 0 {
 		return nil
 	}
 
-	return writeFileRetry(filePath, []byte(strings.Join(out, "\n")), 0644)
+	return writeTextFile(filePath, file, applyEdits(file.Text, edits))
 }
 
-// UpdatePackageVersion rewrites the Version attribute for a specific
-// PackageReference in a .csproj/.fsproj file without altering any other
-// formatting.
+// UpdatePackageVersion rewrites the Version attribute (or <Version> child
+// element) of the package items for pkgName in a .csproj/.fsproj or props
+// file without altering any other formatting. Other attributes such as
+// Aliases or GeneratePathProperty are left untouched, as are other items on
+// the same line and commented-out items. A version range or floating version
+// keeps its syntax, moved to newVersion (see SemVer.Retarget). A
+// packages.config is updated together with its project's package paths; see
+// updatePackagesConfig.
 func UpdatePackageVersion(filePath, pkgName, newVersion string) error {
-	data, err := os.ReadFile(filePath)
+	if isPackagesConfig(filePath) {
+		_, err := updatePackagesConfig(filePath, pkgName, newVersion)
+		return err
+	}
+	file, err := readTextFile(filePath)
 	if err != nil {
 		return fmt.Errorf("read %s: %w", filePath, err)
 	}
+	text, err := updatePackageVersionText(file.Text, pkgName, newVersion)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", filePath, err)
+	}
+	if text == file.Text {
+		return nil
+	}
+	return writeTextFile(filePath, file, text)
+}
 
-	pkgNameRe := regexp.MustCompile(`(?i)Include\s*=\s*"` + regexp.QuoteMeta(pkgName) + `"`)
+// updatePackageVersionText returns text with the versions of pkgName set to
+// newVersion, or text itself when none changes.
+func updatePackageVersionText(text, pkgName, newVersion string) (string, error) {
+	elems, err := scanXML(text)
+	if err != nil {
+		return "", err
+	}
 
-	lines := strings.Split(string(data), "\n")
-	changed := false
-	for i, line := range lines {
-		if pkgNameRe.MatchString(line) {
-			updated := versionAttrRe.ReplaceAllString(line, "${1}"+newVersion+"${2}")
-			if updated != line {
-				lines[i] = updated
-				changed = true
-			}
+	var edits []textEdit
+	for _, i := range packageElements(elems, pkgName) {
+		e := elems[i]
+		if v := e.attr("Version"); v != nil {
+			edits = append(edits, textEdit{Start: v.ValueStart, End: v.ValueEnd, Text: retargetVersion(v.Value, newVersion)})
+			continue
+		}
+		if c := childElement(elems, i, "Version"); c >= 0 && !elems[c].SelfClosing {
+			// Keep any whitespace around the value, e.g. "<Version> 1.0 </Version>".
+			inner := text[elems[c].TagEnd:elems[c].CloseStart]
+			value := strings.TrimSpace(inner)
+			start := elems[c].TagEnd + strings.Index(inner, value)
+			edits = append(edits, textEdit{Start: start, End: start + len(value), Text: retargetVersion(value, newVersion)})
 		}
 	}
-
-	if !changed {
-		return nil
+	edits = slices.DeleteFunc(edits, func(e textEdit) bool { return text[e.Start:e.End] == e.Text })
+	if len(edits) == 0 {
+		return text, nil
 	}
+	return applyEdits(text, edits), nil
+}
 
-	return writeFileRetry(filePath, []byte(strings.Join(lines, "\n")), 0644)
+// retargetVersion returns the version to write in place of old, keeping
+// range or floating syntax from it unless newVersion is a range itself.
+func retargetVersion(old, newVersion string) string {
+	if isVersionRange(newVersion) {
+		return newVersion
+	}
+	return ParseSemVer(old).Retarget(newVersion)
 }
 
 // AddPackageReference inserts a new <PackageReference> element into a project or props file.
 // If version is empty, the element is written without a Version attribute (for CPM projects).
 func AddPackageReference(filePath, pkgName, version string) error {
-	return addXMLElement(filePath, "PackageReference", pkgName, version)
+	return addXMLElement(filePath, "PackageReference", pkgName, version, "")
+}
+
+// AddPackageReferenceForFramework inserts a new <PackageReference> that only
+// applies to one target framework of a multi-targeted project. It goes into an
+// ItemGroup conditioned on that framework, which is created if needed.
+func AddPackageReferenceForFramework(filePath, pkgName, version, framework string) error {
+	return addXMLElement(filePath, "PackageReference", pkgName, version, framework)
 }
 
 // AddPackageVersion inserts a new <PackageVersion> element into a Directory.Packages.props file.
 func AddPackageVersion(filePath, pkgName, version string) error {
-	return addXMLElement(filePath, "PackageVersion", pkgName, version)
+	return addXMLElement(filePath, "PackageVersion", pkgName, version, "")
+}
+
+var frameworkConditionRe = regexp.MustCompile(`(?i)^\s*'\$\(TargetFramework\)'\s*==\s*'([^']*)'\s*$`)
+
+// frameworkCondition returns the MSBuild condition that limits an ItemGroup
+// to a single target framework.
+func frameworkCondition(framework string) string {
+	return "'$(TargetFramework)' == '" + framework + "'"
+}
+
+// conditionMatchesFramework reports whether an ItemGroup condition selects
+// exactly framework. An empty framework matches only unconditional groups;
+// compound conditions never match because we cannot tell what they select.
+func conditionMatchesFramework(condition, framework string) bool {
+	if framework == "" {
+		return strings.TrimSpace(condition) == ""
+	}
+	m := frameworkConditionRe.FindStringSubmatch(condition)
+	return m != nil && strings.EqualFold(strings.TrimSpace(m[1]), framework)
 }
 
 // addXMLElement inserts a new XML element (PackageReference or PackageVersion) into a
-// project or props file without altering any other formatting.
-func addXMLElement(filePath, elementTag, pkgName, version string) error {
-	data, err := os.ReadFile(filePath)
+// project or props file without altering any other formatting. The element is
+// placed in the first ItemGroup that already holds elements of the same kind
+// and whose Condition matches framework (unconditional when framework is
+// empty). ItemGroups inside <Target> or <Choose> are never used. When no group
+// qualifies, a new one is created after the last group holding such elements,
+// or before </Project> if there is none. The element copies the quoting,
+// attribute order and self-closing style of its existing siblings.
+func addXMLElement(filePath, elementTag, pkgName, version, framework string) error {
+	file, err := readTextFile(filePath)
 	if err != nil {
 		return fmt.Errorf("read %s: %w", filePath, err)
 	}
+	text := file.Text
+	elems, err := scanXML(text)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", filePath, err)
+	}
+	nl := "\n"
+	if file.CRLF {
+		nl = "\r\n"
+	}
 
-	lines := strings.Split(string(data), "\n")
-
-	elementRe := regexp.MustCompile(`(?i)<` + elementTag)
-	itemGroupOpenRe := regexp.MustCompile(`(?i)<ItemGroup`)
-	itemGroupCloseRe := regexp.MustCompile(`(?i)</ItemGroup>`)
-	projectCloseRe := regexp.MustCompile(`(?i)</Project>`)
+	// Indentation unit: from the file itself, then .editorconfig, then two spaces.
+	unit := detectIndentUnit(strings.Split(text, "\n"))
+	if unit == "" {
+		unit = editorconfigIndent(filePath)
+	}
+	if unit == "" {
+		unit = defaultIndentUnit
+	}
 
-	// Detect indentation from the first existing element line.
-	indent := "  "
-	for _, line := range lines {
-		if elementRe.MatchString(line) {
-			trimmed := strings.TrimLeft(line, " \t")
-			indent = line[:len(line)-len(trimmed)]
+	project := -1
+	for i := range elems {
+		if elems[i].Parent == -1 && strings.EqualFold(elems[i].Name, "Project") {
+			project = i
 			break
 		}
 	}
-
-	var newLine string
-	if version == "" {
-		newLine = indent + fmt.Sprintf(`<%s Include="%s" />`, elementTag, pkgName)
-	} else {
-		newLine = indent + fmt.Sprintf(`<%s Include="%s" Version="%s" />`, elementTag, pkgName, version)
-	}
-
-	// Stack-scan to find an ItemGroup that already contains matching elements.
-	type igState struct {
-		openLine   int
-		hasElement bool
-	}
-	var stack []igState
-	insertAt := -1
-	for i, line := range lines {
-		if itemGroupOpenRe.MatchString(line) {
-			stack = append(stack, igState{openLine: i})
-		} else if elementRe.MatchString(line) && len(stack) > 0 {
-			stack[len(stack)-1].hasElement = true
-		} else if itemGroupCloseRe.MatchString(line) && len(stack) > 0 {
-			top := stack[len(stack)-1]
-			stack = stack[:len(stack)-1]
-			if top.hasElement {
-				insertAt = i
-				break
+	if project < 0 || elems[project].SelfClosing {
+		return fmt.Errorf("could not find insertion point in %s", filePath)
+	}
+	// New groups nest one level under <Project>, their elements one further.
+	projectIndent, _ := leadingIndent(text, elems[project].CloseStart)
+	outerIndent := projectIndent + unit
+	indent := outerIndent + unit
+
+	// Find an ItemGroup that already contains matching elements, outside
+	// any <Target> or <Choose>.
+	nested := func(i int) bool {
+		for p := elems[i].Parent; p >= 0; p = elems[p].Parent {
+			if strings.EqualFold(elems[p].Name, "Target") || strings.EqualFold(elems[p].Name, "Choose") {
+				return true
 			}
 		}
+		return false
 	}
-
-	if insertAt >= 0 {
-		// Insert before the closing </ItemGroup>.
-		lines = append(lines[:insertAt], append([]string{newLine}, lines[insertAt:]...)...)
-	} else {
-		// No matching ItemGroup found — create a new one before </Project>.
-		outerIndent := ""
-		if len(indent) >= 2 {
-			outerIndent = indent[:len(indent)-2]
+	group, lastChild, lastGroup := -1, -1, -1
+	for i := range elems {
+		g := &elems[i]
+		if !strings.EqualFold(g.Name, "ItemGroup") || g.SelfClosing || nested(i) {
+			continue
+		}
+		first, last := -1, -1
+		for c := i + 1; c < len(elems) && elems[c].Start < g.End; c++ {
+			if elems[c].Parent == i && strings.EqualFold(elems[c].Name, elementTag) {
+				if first < 0 {
+					first = c
+				}
+				last = c
+			}
+		}
+		if first < 0 {
+			continue
 		}
-		newBlock := []string{
-			outerIndent + "<ItemGroup>",
-			newLine,
-			outerIndent + "</ItemGroup>",
+		lastGroup = i
+		condition := ""
+		if a := g.attr("Condition"); a != nil {
+			condition = a.Value
 		}
-		inserted := false
-		for i, line := range lines {
-			if projectCloseRe.MatchString(line) {
-				lines = append(lines[:i], append(newBlock, lines[i:]...)...)
-				inserted = true
-				break
+		if conditionMatchesFramework(condition, framework) {
+			group, lastChild = i, last
+			if ind, ok := leadingIndent(text, elems[first].Start); ok {
+				indent = ind
 			}
+			break
 		}
-		if !inserted {
-			return fmt.Errorf("could not find insertion point in %s", filePath)
+	}
+
+	element := packageElementStyle(text, elems, elementTag).render(elementTag, pkgName, version, indent, unit, nl)
+
+	var edit textEdit
+	switch {
+	case group >= 0:
+		closeStart := elems[group].CloseStart
+		if _, ok := leadingIndent(text, closeStart); ok {
+			// Insert on its own line before the closing </ItemGroup>.
+			at := lineStart(text, closeStart)
+			edit = textEdit{Start: at, End: at, Text: indent + element + nl}
+		} else {
+			// A group written on one line gets the element on that line too.
+			at := elems[lastChild].End
+			edit = textEdit{Start: at, End: at, Text: " " + element}
+		}
+	default:
+		// No matching ItemGroup found — create a new one next to the existing
+		// package groups, or before </Project> when there are none.
+		open := "<ItemGroup>"
+		if framework != "" {
+			open = `<ItemGroup Condition="` + frameworkCondition(framework) + `">`
+		}
+		block := outerIndent + open + nl + indent + element + nl + outerIndent + "</ItemGroup>" + nl
+		var at int
+		if lastGroup >= 0 {
+			at = lineEnd(text, elems[lastGroup].End)
+			if at == len(text) && !strings.HasSuffix(text, "\n") {
+				block = nl + strings.TrimSuffix(block, nl)
+			}
+		} else if _, ok := leadingIndent(text, elems[project].CloseStart); ok {
+			at = lineStart(text, elems[project].CloseStart)
+		} else {
+			at = elems[project].CloseStart
+			block = nl + block
 		}
+		edit = textEdit{Start: at, End: at, Text: block}
 	}
 
-	return writeFileRetry(filePath, []byte(strings.Join(lines, "\n")), 0644)
+	return writeTextFile(filePath, file, applyEdits(text, []textEdit{edit}))
 }
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -592,6 +595,312 @@ func TestAddPackageReference_NoVersion(t *testing.T) {
 	}
 }
 
+func TestAddPackageReference_SkipsConditionalAndTargetGroups(t *testing.T) {
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <Target Name="Extra">
+    <ItemGroup>
+      <PackageReference Include="InTarget" Version="1.0.0" />
+    </ItemGroup>
+  </Target>
+  <ItemGroup Condition="'$(TargetFramework)' == 'net48'">
+    <PackageReference Include="System.Memory" Version="4.5.5" />
+  </ItemGroup>
+  <ItemGroup>
+    <Compile Include="Foo.cs" />
+  </ItemGroup>
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+</Project>`
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, []byte(content), 0644)
+
+	if err := AddPackageReference(tmp, "Polly", "8.5.2"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(tmp)
+	want := strings.Replace(content,
+		`    <PackageReference Include="Serilog" Version="3.1.1" />`+"\n",
+		`    <PackageReference Include="Serilog" Version="3.1.1" />`+"\n"+
+			`    <PackageReference Include="Polly" Version="8.5.2" />`+"\n", 1)
+	if string(data) != want {
+		t.Fatalf("expected Polly next to Serilog, got:\n%s", data)
+	}
+}
+
+func TestAddPackageReference_NewGroupAfterConditionalGroup(t *testing.T) {
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup Condition="'$(TargetFramework)' == 'net48'">
+    <PackageReference Include="System.Memory" Version="4.5.5" />
+  </ItemGroup>
+  <PropertyGroup>
+    <Nullable>enable</Nullable>
+  </PropertyGroup>
+</Project>`
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, []byte(content), 0644)
+
+	if err := AddPackageReference(tmp, "Polly", "8.5.2"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(tmp)
+	want := strings.Replace(content, "  </ItemGroup>\n",
+		"  </ItemGroup>\n  <ItemGroup>\n    <PackageReference Include=\"Polly\" Version=\"8.5.2\" />\n  </ItemGroup>\n", 1)
+	if string(data) != want {
+		t.Fatalf("expected a new unconditional group after the existing one, got:\n%s", data)
+	}
+}
+
+func TestAddPackageReferenceForFramework(t *testing.T) {
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+  <ItemGroup Condition=" '$(TargetFramework)' == 'net48' ">
+    <PackageReference Include="System.Memory" Version="4.5.5" />
+  </ItemGroup>
+</Project>`
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, []byte(content), 0644)
+
+	if err := AddPackageReferenceForFramework(tmp, "System.Buffers", "4.6.0", "net48"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddPackageReferenceForFramework(tmp, "Polly", "8.5.2", "net8.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(tmp)
+	want := `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+  <ItemGroup Condition=" '$(TargetFramework)' == 'net48' ">
+    <PackageReference Include="System.Memory" Version="4.5.5" />
+    <PackageReference Include="System.Buffers" Version="4.6.0" />
+  </ItemGroup>
+  <ItemGroup Condition="'$(TargetFramework)' == 'net8.0'">
+    <PackageReference Include="Polly" Version="8.5.2" />
+  </ItemGroup>
+</Project>`
+	if string(data) != want {
+		t.Fatalf("unexpected result:\n%s", data)
+	}
+}
+
+func TestParseCsproj_AddTargets_PerFramework(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Multi.csproj")
+	os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFrameworks>net8.0;net48</TargetFrameworks>
+  </PropertyGroup>
+</Project>`), 0644)
+
+	proj, err := ParseCsproj(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fws []string
+	for _, at := range proj.AddTargets {
+		if at.Framework != "" {
+			fws = append(fws, at.Framework)
+		}
+	}
+	if strings.Join(fws, ",") != "net48,net8.0" {
+		t.Fatalf("expected per-framework targets net48,net8.0, got %v", fws)
+	}
+}
+
+func TestUpdatePackageVersion_PreservesExtraAttributes(t *testing.T) {
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" Aliases="SerilogV3" GeneratePathProperty="true" />
+    <PackageReference Include="Polly"
+                      Version="7.2.4"
+                      NoWarn="NU1605" />
+    <PackageReference Include="Dapper">
+      <Version>2.1.0</Version>
+      <PrivateAssets>all</PrivateAssets>
+    </PackageReference>
+  </ItemGroup>
+</Project>`
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, []byte(content), 0644)
+
+	for name, ver := range map[string]string{"Serilog": "4.0.0", "Polly": "8.5.2", "Dapper": "2.1.35"} {
+		if err := UpdatePackageVersion(tmp, name, ver); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, _ := os.ReadFile(tmp)
+	want := strings.NewReplacer("3.1.1", "4.0.0", "7.2.4", "8.5.2", "2.1.0", "2.1.35").Replace(content)
+	if string(data) != want {
+		t.Fatalf("expected only versions to change, got:\n%s", data)
+	}
+}
+
+func TestUpdatePackageVersion_KeepsRangeSyntax(t *testing.T) {
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.*" />
+    <PackageReference Include="Polly" Version="[7.2.4,8.0)" />
+    <PackageReference Include="Dapper">
+      <Version>[2.1.0]</Version>
+    </PackageReference>
+  </ItemGroup>
+</Project>`
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, []byte(content), 0644)
+
+	for name, ver := range map[string]string{"Serilog": "4.0.0", "Polly": "7.2.5", "Dapper": "2.1.35"} {
+		if err := UpdatePackageVersion(tmp, name, ver); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, _ := os.ReadFile(tmp)
+	want := strings.NewReplacer(`"3.*"`, `"4.*"`, "[7.2.4,8.0)", "[7.2.5,8.0)", "[2.1.0]", "[2.1.35]").Replace(content)
+	if string(data) != want {
+		t.Fatalf("expected the range syntax to be kept, got:\n%s", data)
+	}
+}
+
+func TestRemovePackageReference_MultiLineElement(t *testing.T) {
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+    <PackageReference Include="Polly"
+                      Version="7.2.4" />
+    <PackageReference Include="Dapper" Version="2.1.0">
+      <PrivateAssets>all</PrivateAssets>
+    </PackageReference>
+    <PackageReference Include="Humanizer" Version="2.14.1" />
+  </ItemGroup>
+</Project>`
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, []byte(content), 0644)
+
+	for _, name := range []string{"Polly", "Dapper"} {
+		if err := RemovePackageReference(tmp, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, _ := os.ReadFile(tmp)
+	want := `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+    <PackageReference Include="Humanizer" Version="2.14.1" />
+  </ItemGroup>
+</Project>`
+	if string(data) != want {
+		t.Fatalf("expected whole elements removed, got:\n%s", data)
+	}
+}
+
+func TestParseCsproj_ReferenceMetadata(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Test.csproj")
+	os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" Aliases="SerilogV3" GeneratePathProperty="true" />
+    <PackageReference Include="Polly" Version="8.5.2">
+      <NoWarn>NU1605</NoWarn>
+    </PackageReference>
+    <PackageReference Include="Dapper" Version="2.1.0" />
+  </ItemGroup>
+</Project>`), 0644)
+
+	proj, err := ParseCsproj(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprint(proj.PackageMetadata["serilog"], proj.PackageMetadata["polly"], proj.PackageMetadata["dapper"])
+	want := "[{Aliases SerilogV3} {GeneratePathProperty true}] [{NoWarn NU1605}] []"
+	if got != want {
+		t.Fatalf("metadata = %s, want %s", got, want)
+	}
+}
+
+func TestUpdatePackageVersion_PreservesBOMAndCRLF(t *testing.T) {
+	content := "\xEF\xBB\xBF<Project Sdk=\"Microsoft.NET.Sdk\">\r\n" +
+		"  <ItemGroup>\r\n" +
+		"    <PackageReference Include=\"Serilog\" Version=\"3.1.1\" />\r\n" +
+		"  </ItemGroup>\r\n" +
+		"</Project>\r\n"
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, []byte(content), 0644)
+
+	if err := UpdatePackageVersion(tmp, "Serilog", "4.0.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(tmp)
+	want := strings.Replace(content, "3.1.1", "4.0.0", 1)
+	if string(data) != want {
+		t.Fatalf("expected only the version to change, got:\n%q", data)
+	}
+}
+
+func TestAddPackageReference_CRLFInsertedLines(t *testing.T) {
+	content := "<Project Sdk=\"Microsoft.NET.Sdk\">\r\n" +
+		"  <PropertyGroup>\r\n" +
+		"    <TargetFramework>net8.0</TargetFramework>\r\n" +
+		"  </PropertyGroup>\r\n" +
+		"</Project>\r\n"
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, []byte(content), 0644)
+
+	if err := AddPackageReference(tmp, "Polly", "8.5.2"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(tmp)
+	result := string(data)
+	if !strings.Contains(result, `<PackageReference Include="Polly" Version="8.5.2" />`) {
+		t.Fatalf("expected PackageReference element, got:\n%s", result)
+	}
+	if n, crlf := strings.Count(result, "\n"), strings.Count(result, "\r\n"); n != crlf {
+		t.Fatalf("expected every line to end in CRLF (%d LF, %d CRLF):\n%q", n, crlf, result)
+	}
+}
+
+func TestUTF16ProjectRoundTrip(t *testing.T) {
+	content := "<?xml version=\"1.0\" encoding=\"utf-16\"?>\r\n" +
+		"<Project Sdk=\"Microsoft.NET.Sdk\">\r\n" +
+		"  <ItemGroup>\r\n" +
+		"    <PackageReference Include=\"Serilog\" Version=\"3.1.1\" />\r\n" +
+		"  </ItemGroup>\r\n" +
+		"</Project>\r\n"
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, (&textFile{Encoding: encodingUTF16LE, BOM: true}).encode(content), 0644)
+
+	proj, err := ParseCsproj(tmp)
+	if err != nil {
+		t.Fatalf("ParseCsproj on UTF-16 file: %v", err)
+	}
+	assertContains(t, pkgNameSet(proj), "Serilog")
+
+	if err := UpdatePackageVersion(tmp, "Serilog", "4.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(tmp)
+	if data[0] != 0xFF || data[1] != 0xFE {
+		t.Fatalf("expected UTF-16 LE BOM to be preserved, got % x", data[:2])
+	}
+	f := decodeText(data)
+	if f.Encoding != encodingUTF16LE || !f.CRLF {
+		t.Fatalf("expected UTF-16 LE with CRLF, got encoding=%d crlf=%v", f.Encoding, f.CRLF)
+	}
+	if f.Text != strings.Replace(content, "3.1.1", "4.0.0", 1) {
+		t.Fatalf("unexpected content after update:\n%q", f.Text)
+	}
+}
+
 func TestParseCsproj_AddTargets_Simple(t *testing.T) {
 	td := testDataDir(t)
 	proj, err := ParseCsproj(filepath.Join(td, "ProjectA", "ProjectA.csproj"))
@@ -667,3 +976,649 @@ func keys(m map[string]bool) []string {
 	}
 	return result
 }
+
+func TestParseCsproj_WildcardImport(t *testing.T) {
+	dir := t.TempDir()
+	buildDir := filepath.Join(dir, "build")
+	os.MkdirAll(buildDir, 0755)
+	os.WriteFile(filepath.Join(buildDir, "a.props"), []byte(`<Project>
+  <ItemGroup>
+    <PackageReference Include="Polly" Version="8.5.2" />
+  </ItemGroup>
+</Project>`), 0644)
+	os.WriteFile(filepath.Join(buildDir, "b.props"), []byte(`<Project>
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="4.2.0" />
+  </ItemGroup>
+</Project>`), 0644)
+	csproj := filepath.Join(dir, "App.csproj")
+	os.WriteFile(csproj, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <Import Project="build/*.props" />
+  <Import Project="Sdk.targets" Sdk="Microsoft.NET.Sdk" />
+  <Import Project="$(UnknownDir)\x.props" />
+</Project>`), 0644)
+
+	proj, err := ParseCsproj(csproj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgNames := pkgNameSet(proj)
+	assertContains(t, pkgNames, "Polly")
+	assertContains(t, pkgNames, "Serilog")
+
+	if len(proj.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics (SDK import + unresolved variable), got %d: %v", len(proj.Diagnostics), proj.Diagnostics)
+	}
+	if !strings.Contains(proj.Diagnostics[0].Message, "SDK import") {
+		t.Errorf("expected SDK import diagnostic, got %q", proj.Diagnostics[0].Message)
+	}
+	if !strings.Contains(proj.Diagnostics[1].Message, "$(UnknownDir)") {
+		t.Errorf("expected unresolved variable diagnostic, got %q", proj.Diagnostics[1].Message)
+	}
+}
+
+func TestParseCsproj_RecursiveWildcardImport(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectFile(t, filepath.Join(dir, "build", "a.props"), `<Project>
+  <ItemGroup>
+    <PackageReference Include="Polly" Version="8.5.2" />
+  </ItemGroup>
+</Project>`)
+	writeProjectFile(t, filepath.Join(dir, "build", "nested", "deep", "b.props"), `<Project>
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="4.2.0" />
+  </ItemGroup>
+</Project>`)
+	writeProjectFile(t, filepath.Join(dir, "build", "nested", "c.targets"), `<Project>
+  <ItemGroup>
+    <PackageReference Include="Dapper" Version="2.1.35" />
+  </ItemGroup>
+</Project>`)
+	csproj := filepath.Join(dir, "App.csproj")
+	writeProjectFile(t, csproj, `<Project Sdk="Microsoft.NET.Sdk">
+  <Import Project="build/**/*.props" />
+</Project>`)
+
+	proj, err := ParseCsproj(csproj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgNames := pkgNameSet(proj)
+	assertContains(t, pkgNames, "Polly")
+	assertContains(t, pkgNames, "Serilog")
+	if pkgNames["Dapper"] {
+		t.Error("expected build/**/*.props not to import a .targets file")
+	}
+	if len(proj.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", proj.Diagnostics)
+	}
+}
+
+func TestParseCsproj_WildcardImportNoMatch(t *testing.T) {
+	dir := t.TempDir()
+	csproj := filepath.Join(dir, "App.csproj")
+	os.WriteFile(csproj, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <Import Project="build/*.props" />
+</Project>`), 0644)
+
+	proj, err := ParseCsproj(csproj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proj.Diagnostics) != 1 || !strings.Contains(proj.Diagnostics[0].Message, "matched no files") {
+		t.Fatalf("expected a single no-match diagnostic, got %v", proj.Diagnostics)
+	}
+}
+
+func TestParseCsproj_VersionDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	csproj := filepath.Join(dir, "App.csproj")
+	os.WriteFile(csproj, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFrameworks>$(LibTfms)</TargetFrameworks>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Polly" Version="8.5.2" />
+    <PackageReference Include="Polly" Version="8.5.2" />
+    <PackageReference Include="Serilog" Version="$(SerilogVer)" />
+    <PackageReference Include="Dapper" Version="latest" />
+    <PackageReference Include="NLog" Version="6.*" />
+    <PackageReference Include="AutoMapper" Version="[12.0,13.0)" />
+  </ItemGroup>
+</Project>`), 0644)
+
+	proj, err := ParseCsproj(csproj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[DiagnosticKind]int)
+	for _, d := range proj.Diagnostics {
+		kinds[d.Kind]++
+	}
+	if kinds[DiagDuplicateReference] != 1 {
+		t.Errorf("expected 1 duplicate reference diagnostic, got %d", kinds[DiagDuplicateReference])
+	}
+	if kinds[DiagUnresolvedVariable] != 2 {
+		t.Errorf("expected 2 unresolved variable diagnostics (version + TFM), got %d", kinds[DiagUnresolvedVariable])
+	}
+	if kinds[DiagMalformedVersion] != 1 {
+		t.Errorf("expected 1 malformed version diagnostic (Dapper), got %d: %v", kinds[DiagMalformedVersion], proj.Diagnostics)
+	}
+}
+
+func TestParseCsproj_VersionConflicts(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.props"), []byte(`<Project>
+  <Import Project="b.props" />
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.0.0" />
+    <PackageReference Include="Dapper" Version="2.1.0" />
+  </ItemGroup>
+</Project>`), 0644)
+	os.WriteFile(filepath.Join(dir, "b.props"), []byte(`<Project>
+  <ItemGroup>
+    <PackageReference Include="Dapper" Version="2.0.0" />
+    <PackageReference Include="Polly" />
+  </ItemGroup>
+</Project>`), 0644)
+	csproj := filepath.Join(dir, "App.csproj")
+	os.WriteFile(csproj, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <Import Project="a.props" />
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="4.0.0" />
+    <PackageReference Include="Polly" Version="8.5.2" />
+  </ItemGroup>
+</Project>`), 0644)
+
+	proj, err := ParseCsproj(csproj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range proj.VersionConflicts {
+		got = append(got, fmt.Sprintf("%s %s@%s over %s@%s", c.Package,
+			filepath.Base(c.EffectiveFile), c.EffectiveVersion, filepath.Base(c.RedundantFile), c.RedundantVersion))
+	}
+	want := []string{
+		"Serilog App.csproj@4.0.0 over a.props@3.0.0",
+		"Dapper a.props@2.1.0 over b.props@2.0.0",
+	}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Fatalf("conflicts = %v, want %v", got, want)
+	}
+	n := 0
+	for _, d := range proj.Diagnostics {
+		if d.Kind == DiagVersionConflict {
+			n++
+		}
+	}
+	if n != 2 {
+		t.Errorf("expected 2 version conflict diagnostics, got %d", n)
+	}
+}
+
+func TestParseCsproj_GlobalReferencesAndTransitivePins(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "Directory.Packages.props"), []byte(`<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+    <CentralPackageTransitivePinningEnabled>true</CentralPackageTransitivePinningEnabled>
+  </PropertyGroup>
+  <ItemGroup>
+    <GlobalPackageReference Include="Nerdbank.GitVersioning" Version="3.6.143" />
+    <PackageVersion Include="Serilog" Version="4.0.0" />
+    <PackageVersion Include="System.Text.Json" Version="8.0.5" />
+    <PackageVersion Include="Polly" Version="8.5.2" />
+  </ItemGroup>
+</Project>`), 0644)
+	csproj := filepath.Join(dir, "App.csproj")
+	os.WriteFile(csproj, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" />
+  </ItemGroup>
+</Project>`), 0644)
+	os.MkdirAll(filepath.Join(dir, "obj"), 0755)
+	os.WriteFile(filepath.Join(dir, "obj", "project.assets.json"), []byte(`{
+  "libraries": {
+    "Serilog/4.0.0": {"type": "package"},
+    "System.Text.Json/8.0.5": {"type": "package"},
+    "Lib/1.0.0": {"type": "project"}
+  }
+}`), 0644)
+
+	proj, err := ParseCsproj(csproj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proj.TransitivePinned {
+		t.Error("expected TransitivePinned to be set")
+	}
+	kinds := make(map[string]ReferenceKind)
+	for ref := range proj.Packages {
+		kinds[ref.Name] = ref.Kind
+	}
+	want := map[string]ReferenceKind{
+		"Serilog":                RefDirect,
+		"Nerdbank.GitVersioning": RefGlobal,
+		"System.Text.Json":       RefTransitivePin,
+	}
+	if fmt.Sprint(kinds) != fmt.Sprint(want) {
+		t.Fatalf("package kinds = %v, want %v", kinds, want)
+	}
+	if src := proj.SourceFileForPackage("Nerdbank.GitVersioning"); filepath.Base(src) != "Directory.Packages.props" {
+		t.Errorf("global reference should be edited in Directory.Packages.props, got %s", src)
+	}
+}
+
+func TestNewBrokenProject_KeepsParseError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Broken.csproj")
+	if err := os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1">
+  </ItemGroup>
+</Project>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ParseCsproj(path)
+	if err == nil {
+		t.Fatal("expected parse error for malformed csproj")
+	}
+
+	p := newBrokenProject(path, err)
+	if p.FileName != "Broken.csproj" {
+		t.Errorf("expected FileName Broken.csproj, got %q", p.FileName)
+	}
+	if p.LoadErr == nil {
+		t.Error("expected LoadErr to be set")
+	}
+	if len(p.Packages) != 0 || len(p.TargetFrameworks) != 0 {
+		t.Error("expected broken project to have no packages or frameworks")
+	}
+	if got := collectPropsProjects([]*ParsedProject{p}); len(got) != 0 {
+		t.Errorf("expected no props projects from a broken project, got %d", len(got))
+	}
+}
+
+func TestParseCsproj_LegacyProject(t *testing.T) {
+	dir := t.TempDir()
+	csproj := filepath.Join(dir, "Legacy.csproj")
+	if err := os.WriteFile(csproj, []byte(`<?xml version="1.0" encoding="utf-8"?>
+<Project ToolsVersion="15.0" xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+  <PropertyGroup>
+    <TargetFrameworkVersion>v4.7.2</TargetFrameworkVersion>
+  </PropertyGroup>
+  <ItemGroup>
+    <Reference Include="System" />
+    <Reference Include="Newtonsoft.Json, Version=13.0.0.0, Culture=neutral">
+      <HintPath>..\packages\Newtonsoft.Json.13.0.1\lib\net45\Newtonsoft.Json.dll</HintPath>
+    </Reference>
+    <Reference Include="log4net">
+      <HintPath>..\packages\log4net.2.0.15\lib\net45\log4net.dll</HintPath>
+    </Reference>
+  </ItemGroup>
+</Project>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "packages.config"), []byte(`<?xml version="1.0" encoding="utf-8"?>
+<packages>
+  <package id="Newtonsoft.Json" version="13.0.1" targetFramework="net472" />
+  <package id="Serilog" version="2.12.0" targetFramework="net472" />
+</packages>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ParseCsproj(csproj)
+	if err != nil {
+		t.Fatalf("ParseCsproj: %v", err)
+	}
+	if !p.Legacy {
+		t.Error("expected project to be marked legacy")
+	}
+	if len(p.AddTargets) != 0 {
+		t.Errorf("expected no add targets for a legacy project, got %d", len(p.AddTargets))
+	}
+	if !p.TargetFrameworks.Contains(ParseTargetFramework("net472")) {
+		t.Errorf("expected net472 target framework, got %v", p.TargetFrameworks)
+	}
+
+	names := pkgNameSet(p)
+	for _, want := range []string{"Newtonsoft.Json", "Serilog", "log4net"} {
+		assertContains(t, names, want)
+	}
+	if p.Packages.Len() != 3 {
+		t.Errorf("expected 3 packages, got %d", p.Packages.Len())
+	}
+	if src := p.SourceFileForPackage("Serilog"); filepath.Base(src) != "packages.config" {
+		t.Errorf("expected Serilog from packages.config, got %s", src)
+	}
+	if src := p.SourceFileForPackage("log4net"); filepath.Base(src) != "Legacy.csproj" {
+		t.Errorf("expected log4net from HintPath in Legacy.csproj, got %s", src)
+	}
+	if !p.canUpdatePackage("Serilog") || p.canUpdatePackage("log4net") {
+		t.Error("expected only packages.config entries to be updatable")
+	}
+}
+
+func TestUpdatePackageVersion_PackagesConfig(t *testing.T) {
+	dir := t.TempDir()
+	csproj := filepath.Join(dir, "Legacy.csproj")
+	project := "<Project ToolsVersion=\"15.0\" xmlns=\"http://schemas.microsoft.com/developer/msbuild/2003\">\r\n" +
+		"  <Import Project=\"..\\packages\\Foo.1.0.0\\build\\Foo.props\" Condition=\"Exists('..\\packages\\Foo.1.0.0\\build\\Foo.props')\" />\r\n" +
+		"  <ItemGroup>\r\n" +
+		"    <Reference Include=\"Foo, Version=1.0.0.0\">\r\n      <HintPath>..\\packages\\Foo.1.0.0\\lib\\net45\\Foo.dll</HintPath>\r\n    </Reference>\r\n" +
+		"    <Reference Include=\"Bar.Foo\">\r\n      <HintPath>..\\packages\\Bar.Foo.1.0.0\\lib\\net45\\Bar.Foo.dll</HintPath>\r\n    </Reference>\r\n" +
+		"  </ItemGroup>\r\n" +
+		"</Project>\r\n"
+	config := filepath.Join(dir, "packages.config")
+	writeProjectFile(t, csproj, project)
+	writeProjectFile(t, config, `<packages>
+  <package id="Foo" version="1.0.0" targetFramework="net472" />
+  <package id="Bar.Foo" version="1.0.0" targetFramework="net472" />
+</packages>`)
+
+	if err := UpdatePackageVersion(config, "foo", "1.2.0"); err != nil {
+		t.Fatal(err)
+	}
+	gotConfig, _ := os.ReadFile(config)
+	if want := `<packages>
+  <package id="Foo" version="1.2.0" targetFramework="net472" />
+  <package id="Bar.Foo" version="1.0.0" targetFramework="net472" />
+</packages>`; string(gotConfig) != want {
+		t.Errorf("packages.config:\n%s\nwant:\n%s", gotConfig, want)
+	}
+	gotProject, _ := os.ReadFile(csproj)
+	want := strings.ReplaceAll(project, `\Foo.1.0.0\`, `\Foo.1.2.0\`)
+	if string(gotProject) != want || !strings.Contains(want, `Bar.Foo.1.0.0`) {
+		t.Errorf("project:\n%s\nwant:\n%s", gotProject, want)
+	}
+}
+
+func TestUpdatePackageVersion_PackagesConfigMissingPackage(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "packages.config")
+	writeProjectFile(t, config, `<packages>
+  <package id="Foo" version="1.0.0" targetFramework="net472" />
+</packages>`)
+
+	if err := UpdatePackageVersion(config, "Baz", "2.0.0"); err == nil || !strings.Contains(err.Error(), "Baz is not listed") {
+		t.Errorf("err = %v, want Baz reported missing", err)
+	}
+}
+
+func TestUpdatePackagesConfig_UndoRestoresHintPaths(t *testing.T) {
+	dir := t.TempDir()
+	csproj := filepath.Join(dir, "Legacy.csproj")
+	project := `<Project ToolsVersion="15.0" xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+  <ItemGroup>
+    <Reference Include="Foo">
+      <HintPath>..\packages\Foo.1.0.0\lib\net45\Foo.dll</HintPath>
+    </Reference>
+  </ItemGroup>
+</Project>`
+	config := filepath.Join(dir, "packages.config")
+	packagesConfig := `<packages>
+  <package id="Foo" version="1.0.0" targetFramework="net472" />
+</packages>`
+	writeProjectFile(t, csproj, project)
+	writeProjectFile(t, config, packagesConfig)
+
+	plan := []plannedUpdate{{File: config, Package: "Foo", From: "1.0.0", To: "1.2.0"}}
+	if err := applyPlannedUpdates(dir, plan, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(csproj); !strings.Contains(string(data), `Foo.1.2.0`) {
+		t.Fatalf("expected the HintPath to move:\n%s", data)
+	}
+
+	// The update is one undo step covering the moved HintPaths too.
+	entry, ok := sessionJournal.lastOpen()
+	if !ok || entry.Package != "Foo" || len(entry.Files) != 2 {
+		t.Fatalf("journal entry = %+v, %v", entry, ok)
+	}
+	if _, err := sessionJournal.revert(entry.ID); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{config: packagesConfig, csproj: project} {
+		if got, _ := os.ReadFile(path); string(got) != want {
+			t.Errorf("%s after undo:\n%s\nwant:\n%s", filepath.Base(path), got, want)
+		}
+	}
+}
+
+func TestParseCsproj_SdkProjectNotLegacy(t *testing.T) {
+	p, err := ParseCsproj(filepath.Join(testDataDir(t), "ProjectA", "ProjectA.csproj"))
+	if err != nil {
+		t.Fatalf("ParseCsproj: %v", err)
+	}
+	if p.Legacy {
+		t.Error("SDK-style project should not be marked legacy")
+	}
+}
+
+func TestParseCsproj_RestoreWarnings(t *testing.T) {
+	root := t.TempDir()
+	proj := filepath.Join(root, "App.csproj")
+	writeProjectFile(t, proj, `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Legacy.Widgets" Version="2.1.0" />
+  </ItemGroup>
+</Project>`)
+	writeProjectFile(t, filepath.Join(root, "obj", "project.assets.json"), `{
+  "version": 3,
+  "logs": [
+    {"code": "NU1701", "level": "Warning", "message": "Package 'Legacy.Widgets 2.1.0' was restored using '.NETFramework,Version=v4.6.1'", "libraryId": "Legacy.Widgets", "targetGraphs": ["net8.0"]},
+    {"code": "NU1701", "level": "Warning", "message": "same, another graph", "libraryId": "Legacy.Widgets", "targetGraphs": ["net6.0"]},
+    {"code": "NU1603", "level": "Warning", "message": "App depends on Legacy.Widgets (>= 2.0.0) but ...", "libraryId": "legacy.widgets"},
+    {"code": "NU1503", "level": "Warning", "message": "no library"}
+  ]
+}`)
+
+	pp, err := ParseCsproj(proj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"legacy.widgets": {"NU1603", "NU1701"}}
+	if !reflect.DeepEqual(pp.RestoreWarnings, want) {
+		t.Errorf("RestoreWarnings = %v, want %v", pp.RestoreWarnings, want)
+	}
+}
+
+func TestParseCsproj_Metadata(t *testing.T) {
+	root := t.TempDir()
+	writeProjectFile(t, filepath.Join(root, "Directory.Build.props"), `<Project>
+  <PropertyGroup>
+    <Authors>Contoso</Authors>
+    <AssemblyName>Ignored</AssemblyName>
+  </PropertyGroup>
+</Project>`)
+	proj := filepath.Join(root, "src", "App.csproj")
+	writeProjectFile(t, proj, `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <AssemblyName>Contoso.App</AssemblyName>
+    <RootNamespace>$(AssemblyName).Core</RootNamespace>
+    <VersionPrefix>2.1.0</VersionPrefix>
+    <VersionSuffix>beta</VersionSuffix>
+  </PropertyGroup>
+</Project>`)
+
+	pp, err := ParseCsproj(proj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []metadataField{
+		{Name: "AssemblyName", Value: "Contoso.App"},
+		{Name: "RootNamespace", Value: "Contoso.App.Core"},
+		{Name: "PackageId", Value: "Contoso.App", Default: true},
+		{Name: "Version", Value: "2.1.0-beta"},
+		{Name: "Authors", Value: "Contoso"},
+		{Name: "IsPackable", Value: "true", Default: true},
+	}
+	if got := pp.metadataFields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %+v\nwant %+v", got, want)
+	}
+	if id, version, ok := pp.packageIdentity(); !ok || id != "Contoso.App" || version != "2.1.0-beta" {
+		t.Errorf("packageIdentity = %q, %q, %v", id, version, ok)
+	}
+
+	unset := ProjectMetadata{}.fields(filepath.Join(root, "Legacy.csproj"), false)
+	if unset[0].Value != "Legacy" || !unset[0].Default || unset[3].Value != "1.0.0" || unset[5].Value != "false" {
+		t.Errorf("defaults for an unset legacy project = %+v", unset)
+	}
+}
+
+// TestWriteOperations_PreserveFormatting runs each edit against a file
+// written in some real-world style and expects only the edited bytes to
+// change.
+func TestWriteOperations_PreserveFormatting(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		edit func(path string) error
+		want string
+	}{
+		{
+			name: "update single-quoted tab-indented",
+			in:   "<Project Sdk='Microsoft.NET.Sdk'>\n\t<ItemGroup>\n\t\t<PackageReference Include='Serilog' Version='3.1.1'/>\n\t</ItemGroup>\n</Project>",
+			edit: func(p string) error { return UpdatePackageVersion(p, "Serilog", "4.0.0") },
+			want: "<Project Sdk='Microsoft.NET.Sdk'>\n\t<ItemGroup>\n\t\t<PackageReference Include='Serilog' Version='4.0.0'/>\n\t</ItemGroup>\n</Project>",
+		},
+		{
+			name: "update one of two items on a line",
+			in:   `<Project><ItemGroup><PackageReference Include="A" Version="1.0.0" /><PackageReference Include="B" Version="1.0.0" /></ItemGroup></Project>`,
+			edit: func(p string) error { return UpdatePackageVersion(p, "B", "2.0.0") },
+			want: `<Project><ItemGroup><PackageReference Include="A" Version="1.0.0" /><PackageReference Include="B" Version="2.0.0" /></ItemGroup></Project>`,
+		},
+		{
+			name: "remove one of two items on a line",
+			in:   "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"A\" Version=\"1.0.0\" /> <PackageReference Include=\"B\" Version=\"1.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(p string) error { return RemovePackageReference(p, "B") },
+			want: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"A\" Version=\"1.0.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "commented-out items are left alone",
+			in: `<Project>
+  <ItemGroup>
+    <!-- <PackageReference Include="Serilog" Version="2.0.0" /> -->
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+</Project>`,
+			edit: func(p string) error { return UpdatePackageVersion(p, "Serilog", "4.0.0") },
+			want: `<Project>
+  <ItemGroup>
+    <!-- <PackageReference Include="Serilog" Version="2.0.0" /> -->
+    <PackageReference Include="Serilog" Version="4.0.0" />
+  </ItemGroup>
+</Project>`,
+		},
+		{
+			name: "remove keeps a commented-out copy",
+			in: `<Project>
+  <ItemGroup>
+    <!-- <PackageReference Include="Serilog" Version="2.0.0" /> -->
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+</Project>`,
+			edit: func(p string) error { return RemovePackageReference(p, "Serilog") },
+			want: `<Project>
+  <ItemGroup>
+    <!-- <PackageReference Include="Serilog" Version="2.0.0" /> -->
+  </ItemGroup>
+</Project>`,
+		},
+		{
+			name: "update an Update item and a padded Version element",
+			in: `<Project>
+  <ItemGroup>
+    <PackageReference Update="Serilog" Version="3.1.1" />
+    <PackageReference Include="Dapper">
+      <Version> 2.1.0 </Version>
+    </PackageReference>
+  </ItemGroup>
+</Project>`,
+			edit: func(p string) error {
+				if err := UpdatePackageVersion(p, "Serilog", "4.0.0"); err != nil {
+					return err
+				}
+				return UpdatePackageVersion(p, "Dapper", "2.1.35")
+			},
+			want: `<Project>
+  <ItemGroup>
+    <PackageReference Update="Serilog" Version="4.0.0" />
+    <PackageReference Include="Dapper">
+      <Version> 2.1.35 </Version>
+    </PackageReference>
+  </ItemGroup>
+</Project>`,
+		},
+		{
+			name: "add copies attribute order and self-closing style",
+			in: `<Project>
+  <ItemGroup Condition="'$(Configuration)' > 'Debug'">
+  </ItemGroup>
+  <ItemGroup>
+    <PackageVersion Version="13.0.4" Include="Newtonsoft.Json"/>
+  </ItemGroup>
+</Project>`,
+			edit: func(p string) error { return AddPackageVersion(p, "Polly", "8.5.2") },
+			want: `<Project>
+  <ItemGroup Condition="'$(Configuration)' > 'Debug'">
+  </ItemGroup>
+  <ItemGroup>
+    <PackageVersion Version="13.0.4" Include="Newtonsoft.Json"/>
+    <PackageVersion Version="8.5.2" Include="Polly"/>
+  </ItemGroup>
+</Project>`,
+		},
+		{
+			name: "add copies Version child elements",
+			in:   "<Project>\r\n    <ItemGroup>\r\n        <PackageReference Include=\"Dapper\">\r\n            <Version>2.1.0</Version>\r\n        </PackageReference>\r\n    </ItemGroup>\r\n</Project>\r\n",
+			edit: func(p string) error { return AddPackageReference(p, "Polly", "8.5.2") },
+			want: "<Project>\r\n    <ItemGroup>\r\n        <PackageReference Include=\"Dapper\">\r\n            <Version>2.1.0</Version>\r\n        </PackageReference>\r\n" +
+				"        <PackageReference Include=\"Polly\">\r\n            <Version>8.5.2</Version>\r\n        </PackageReference>\r\n    </ItemGroup>\r\n</Project>\r\n",
+		},
+		{
+			name: "add to a one-line group",
+			in:   "<Project>\n  <ItemGroup><PackageReference Include=\"A\" Version=\"1.0.0\" /></ItemGroup>\n</Project>",
+			edit: func(p string) error { return AddPackageReference(p, "B", "2.0.0") },
+			want: "<Project>\n  <ItemGroup><PackageReference Include=\"A\" Version=\"1.0.0\" /> <PackageReference Include=\"B\" Version=\"2.0.0\" /></ItemGroup>\n</Project>",
+		},
+		{
+			name: "add a group to a file without a trailing newline",
+			in:   "<Project Sdk=\"Microsoft.NET.Sdk\">\n  <PropertyGroup>\n    <TargetFramework>net8.0</TargetFramework>\n  </PropertyGroup>\n</Project>",
+			edit: func(p string) error { return AddPackageReference(p, "Polly", "8.5.2") },
+			want: "<Project Sdk=\"Microsoft.NET.Sdk\">\n  <PropertyGroup>\n    <TargetFramework>net8.0</TargetFramework>\n  </PropertyGroup>\n" +
+				"  <ItemGroup>\n    <PackageReference Include=\"Polly\" Version=\"8.5.2\" />\n  </ItemGroup>\n</Project>",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tmp := filepath.Join(t.TempDir(), "Test.csproj")
+			os.WriteFile(tmp, []byte(c.in), 0644)
+			if err := c.edit(tmp); err != nil {
+				t.Fatal(err)
+			}
+			data, _ := os.ReadFile(tmp)
+			if string(data) != c.want {
+				t.Fatalf("got:\n%s\nwant:\n%s", data, c.want)
+			}
+		})
+	}
+}
+
+func TestUpdatePackageVersion_RefusesMalformedFile(t *testing.T) {
+	content := `<Project>
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+`
+	tmp := filepath.Join(t.TempDir(), "Test.csproj")
+	os.WriteFile(tmp, []byte(content), 0644)
+
+	if err := UpdatePackageVersion(tmp, "Serilog", "4.0.0"); err == nil {
+		t.Fatal("expected an error for an unclosed <Project>")
+	}
+	if data, _ := os.ReadFile(tmp); string(data) != content {
+		t.Fatalf("file changed:\n%s", data)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// publishCheck is whether the package a packable project would produce is
+// already on the source it would be pushed to, so a version that was never
+// bumped is caught before CI's push fails.
+type publishCheck struct {
+	ID      string
+	Version string
+	Source  string // push source name; "" when none could be resolved
+	Exists  bool
+	Err     error
+}
+
+// publishCheckKey identifies the check for p's current package identity, so bumping
+// the version starts a new check.
+func publishCheckKey(p *ParsedProject, id, version string) string {
+	return p.FilePath + "|" + id + "@" + version
+}
+
+// checkPublish looks up p's would-be package on the defaultPushSource of
+// its nuget.config. A configured source already connected in services is
+// reused with its credentials.
+func checkPublish(p *ParsedProject, id, version string, services []*NugetService) publishCheck {
+	check := publishCheck{ID: id, Version: version}
+	source, err := resolvePushSource(filepath.Dir(p.FilePath), "")
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	check.Source = source.Name
+	var svc *NugetService
+	for _, s := range services {
+		if sameSource(NugetSource{Name: s.sourceName, URL: s.sourceURL}, source) {
+			svc = s
+		}
+	}
+	if svc == nil {
+		if svc, err = NewNugetService(source); err != nil {
+			check.Err = err
+			return check
+		}
+	}
+	check.Exists, check.Err = svc.HasVersion(id, version)
+	return check
+}
+
+// HasVersion reports whether the source has version of packageID, listed or
+// not.
+func (s *NugetService) HasVersion(packageID, version string) (bool, error) {
+	info, err := s.SearchExact(packageID)
+	if errors.Is(err, errPackageNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// NuGet treats versions case-insensitively: 1.0.0-BETA collides with
+	// 1.0.0-beta.
+	want := ParseSemVer(version)
+	want.PreRelease = strings.ToLower(want.PreRelease)
+	for _, pv := range info.Versions {
+		v := pv.SemVer
+		v.PreRelease = strings.ToLower(v.PreRelease)
+		if !v.IsNewerThan(want) && !want.IsNewerThan(v) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckPublish(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			fmt.Fprintf(w, `{"resources":[{"@id":%q,"@type":"RegistrationsBaseUrl/3.6.0"}]}`, srv.URL+"/reg/")
+		case "/reg/contoso.app/index.json":
+			fmt.Fprint(w, `{"count":1,"items":[{"lower":"1.0.0","upper":"2.1.0-beta","items":[
+				{"catalogEntry":{"id":"Contoso.App","version":"1.0.0"}},
+				{"catalogEntry":{"id":"Contoso.App","version":"2.1.0-beta","listed":false}}]}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "nuget.config"), []byte(fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <clear />
+    <add key="corp" value="%s/index.json" />
+  </packageSources>
+  <config>
+    <add key="defaultPushSource" value="corp" />
+  </config>
+</configuration>`, srv.URL)), 0644)
+	p := &ParsedProject{FilePath: filepath.Join(root, "App.csproj")}
+
+	tests := []struct {
+		id, version string
+		exists      bool
+	}{
+		{"Contoso.App", "2.1.0-BETA", true},
+		{"Contoso.App", "2.1.0", false},
+		{"Contoso.Other", "1.0.0", false},
+	}
+	for _, tt := range tests {
+		check := checkPublish(p, tt.id, tt.version, nil)
+		if check.Err != nil || check.Source != "corp" || check.Exists != tt.exists {
+			t.Errorf("checkPublish(%s %s) = %+v, want exists=%v on corp", tt.id, tt.version, check, tt.exists)
+		}
+	}
+
+	noPush := &ParsedProject{FilePath: filepath.Join(t.TempDir(), "App.csproj")}
+	if check := checkPublish(noPush, "Contoso.App", "1.0.0", nil); check.Err == nil || check.Source != "" {
+		t.Errorf("checkPublish without a push source = %+v, want an error", check)
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// repoVersion is one version of a package used in a workspace repository,
+// with the projects there that reference it.
+type repoVersion struct {
+	Repo     string
+	Version  string // as declared
+	Projects []*ParsedProject
+}
+
+// repoDivergence is a package referenced from several workspace
+// repositories at more than one version.
+type repoDivergence struct {
+	Package  string
+	Versions []repoVersion // by repository, then version
+	Highest  string
+}
+
+// findRepoDivergences lists the packages whose versions differ between the
+// repositories of a workspace, sorted by name. Projects outside any
+// repository, legacy projects and broken ones are ignored.
+func findRepoDivergences(projects []*ParsedProject) []repoDivergence {
+	type entry struct {
+		name     string
+		versions []repoVersion
+	}
+	byName := make(map[string]*entry)
+	for _, p := range projects {
+		if p.Repo == "" || p.Legacy || p.LoadErr != nil {
+			continue
+		}
+		for ref := range p.Packages {
+			key := strings.ToLower(ref.Name)
+			e := byName[key]
+			if e == nil {
+				e = &entry{name: ref.Name}
+				byName[key] = e
+			}
+			version := ref.Version.Declared()
+			i := slices.IndexFunc(e.versions, func(v repoVersion) bool { return v.Repo == p.Repo && v.Version == version })
+			if i < 0 {
+				e.versions = append(e.versions, repoVersion{Repo: p.Repo, Version: version})
+				i = len(e.versions) - 1
+			}
+			e.versions[i].Projects = append(e.versions[i].Projects, p)
+		}
+	}
+
+	var out []repoDivergence
+	for _, e := range byName {
+		repos := NewSet[string]()
+		versions := NewSet[string]()
+		for _, v := range e.versions {
+			repos.Add(v.Repo)
+			versions.Add(v.Version)
+		}
+		if repos.Len() < 2 || versions.Len() < 2 {
+			continue
+		}
+		slices.SortFunc(e.versions, func(a, b repoVersion) int {
+			if a.Repo != b.Repo {
+				return strings.Compare(a.Repo, b.Repo)
+			}
+			return strings.Compare(a.Version, b.Version)
+		})
+		d := repoDivergence{Package: e.name, Versions: e.versions}
+		for _, v := range e.versions {
+			if d.Highest == "" || ParseSemVer(v.Version).IsNewerThan(ParseSemVer(d.Highest)) {
+				d.Highest = v.Version
+			}
+		}
+		out = append(out, d)
+	}
+	slices.SortFunc(out, func(a, b repoDivergence) int {
+		return strings.Compare(strings.ToLower(a.Package), strings.ToLower(b.Package))
+	})
+	return out
+}
+
+// alignment is one package to move to Version in every repository.
+type alignment struct {
+	Package string
+	Version string
+}
+
+// allowedAlignments drops the alignments the package rules in settings
+// hold back, as they would a bulk update, and returns them separately as
+// "package version: reason".
+func allowedAlignments(rules packageRules, aligns []alignment) (kept []alignment, heldBack []string) {
+	for _, a := range aligns {
+		if why := rules.For(a.Package).heldBack(ParseSemVer(a.Version)); why != "" {
+			heldBack = append(heldBack, fmt.Sprintf("%s %s: %s", a.Package, a.Version, why))
+			continue
+		}
+		kept = append(kept, a)
+	}
+	return kept, heldBack
+}
+
+// alignBranchResult is what aligning did in one git repository.
+type alignBranchResult struct {
+	Repo   string // workspace repository names, joined when they share a git root
+	Root   string // the git work tree
+	Branch string // created branch; "" when nothing changed
+	Files  int
+	Err    error
+}
+
+var branchUnsafeRe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// alignBranchName names the branch carrying alignments, e.g.
+// guget/align-newtonsoft.json-13.0.3.
+func alignBranchName(aligns []alignment) string {
+	if len(aligns) == 1 {
+		name := strings.ToLower(aligns[0].Package) + "-" + aligns[0].Version
+		return "guget/align-" + strings.Trim(branchUnsafeRe.ReplaceAllString(name, "-"), "-.")
+	}
+	return fmt.Sprintf("guget/align-%d-packages", len(aligns))
+}
+
+// alignCommitMessage describes alignments for the commit on each branch.
+func alignCommitMessage(aligns []alignment) string {
+	if len(aligns) == 1 {
+		return fmt.Sprintf("Align %s to %s across workspace repositories", aligns[0].Package, aligns[0].Version)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Align %d packages across workspace repositories\n\n", len(aligns))
+	for _, a := range aligns {
+		fmt.Fprintf(&b, "- %s %s\n", a.Package, a.Version)
+	}
+	return b.String()
+}
+
+// alignOnBranches writes aligns to a new branch in the git repository of
+// each workspace repository, as one commit on top of its HEAD. The edits are
+// made to the committed files, so the working tree, the index and the
+// checked-out branch are left as they are. Locked references are skipped.
+func alignOnBranches(projects []*ParsedProject, aligns []alignment) []alignBranchResult {
+	type gitRepo struct {
+		repos Set[string]
+		files map[string][]alignment // path in the repository → alignments to write there
+	}
+	type location struct {
+		root, prefix string
+		err          error
+	}
+	byRoot := make(map[string]*gitRepo)
+	locations := make(map[string]location) // by directory
+	var roots []string
+	var results []alignBranchResult
+	failed := NewSet[string]()
+	for _, p := range projects {
+		if p.Repo == "" || p.Legacy || p.LoadErr != nil {
+			continue
+		}
+		for _, a := range aligns {
+			ref, ok := findReference(p, a.Package)
+			if !ok || ref.Locked || ref.Version.Declared() == a.Version {
+				continue
+			}
+			file := p.SourceFileForPackage(ref.Name)
+			dir := filepath.Dir(file)
+			loc, ok := locations[dir]
+			if !ok {
+				loc.root, loc.prefix, loc.err = gitLocation(dir)
+				locations[dir] = loc
+			}
+			if loc.err != nil {
+				if !failed.Contains(p.Repo) {
+					failed.Add(p.Repo)
+					results = append(results, alignBranchResult{Repo: p.Repo, Err: loc.err})
+				}
+				continue
+			}
+			g := byRoot[loc.root]
+			if g == nil {
+				g = &gitRepo{repos: NewSet[string](), files: make(map[string][]alignment)}
+				byRoot[loc.root] = g
+				roots = append(roots, loc.root)
+			}
+			g.repos.Add(p.Repo)
+			rel := loc.prefix + filepath.Base(file)
+			if !slices.Contains(g.files[rel], a) {
+				g.files[rel] = append(g.files[rel], a)
+			}
+		}
+	}
+
+	branch := alignBranchName(aligns)
+	message := alignCommitMessage(aligns)
+	for _, root := range roots {
+		g := byRoot[root]
+		names := g.repos.ToSlice()
+		slices.Sort(names)
+		res := alignBranchResult{Repo: strings.Join(names, ", "), Root: root}
+		changes := make(map[string][]byte)
+		for rel, fileAligns := range g.files {
+			data, err := alignCommittedFile(root, rel, fileAligns)
+			if err != nil {
+				res.Err = err
+				break
+			}
+			if data != nil {
+				changes[rel] = data
+			}
+		}
+		if res.Err == nil && len(changes) > 0 {
+			res.Err = commitOnNewBranch(root, branch, message, changes)
+			if res.Err == nil {
+				res.Branch, res.Files = branch, len(changes)
+			}
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// alignCommittedFile returns the file at rel as committed at HEAD with
+// aligns applied, or nil when they change nothing there.
+func alignCommittedFile(root, rel string, aligns []alignment) ([]byte, error) {
+	data, err := runGit(root, nil, nil, "show", "HEAD:"+rel)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not committed: %w", rel, err)
+	}
+	f := decodeText(data)
+	text := f.Text
+	for _, a := range aligns {
+		if text, err = updatePackageVersionText(text, a.Package, a.Version); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", rel, err)
+		}
+	}
+	if text == f.Text {
+		return nil, nil
+	}
+	return f.encode(text), nil
+}
+
+// gitLocation returns the top of the git work tree holding dir, and dir's
+// path within it with a trailing slash ("" at the top).
+func gitLocation(dir string) (root, prefix string, err error) {
+	out, err := runGit(dir, nil, nil, "rev-parse", "--show-toplevel", "--show-prefix")
+	if err != nil {
+		return "", "", fmt.Errorf("%s is not in a git repository: %w", dir, err)
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	root = filepath.Clean(filepath.FromSlash(lines[0]))
+	if len(lines) > 1 {
+		prefix = lines[1]
+	}
+	return root, prefix, nil
+}
+
+// commitOnNewBranch commits changes (path in the repository → content) on
+// top of HEAD and points a new branch at the commit, using a scratch index
+// so the working tree and the real index are untouched. Fails if the branch
+// already exists.
+func commitOnNewBranch(root, branch, message string, changes map[string][]byte) error {
+	if _, err := runGit(root, nil, nil, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		return fmt.Errorf("branch %s already exists", branch)
+	}
+	index, err := os.CreateTemp("", "guget-index-*")
+	if err != nil {
+		return err
+	}
+	index.Close()
+	defer os.Remove(index.Name())
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	if _, err := runGit(root, env, nil, "read-tree", "HEAD"); err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(changes))
+	for path := range changes {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, rel := range paths {
+		blob, err := runGit(root, nil, changes[rel], "hash-object", "-w", "--stdin")
+		if err != nil {
+			return err
+		}
+		mode := "100644"
+		if out, err := runGit(root, env, nil, "ls-files", "--stage", "--", rel); err == nil && len(out) > 6 {
+			mode = string(out[:6])
+		}
+		cacheinfo := mode + "," + strings.TrimSpace(string(blob)) + "," + rel
+		if _, err := runGit(root, env, nil, "update-index", "--add", "--cacheinfo", cacheinfo); err != nil {
+			return err
+		}
+	}
+	tree, err := runGit(root, env, nil, "write-tree")
+	if err != nil {
+		return err
+	}
+	commit, err := runGit(root, nil, []byte(message), "commit-tree", strings.TrimSpace(string(tree)), "-p", "HEAD")
+	if err != nil {
+		return err
+	}
+	_, err = runGit(root, nil, nil, "branch", branch, strings.TrimSpace(string(commit)))
+	return err
+}
+
+// runGit runs git in dir with extra environment and stdin, returning its
+// output. The error carries git's own message.
+func runGit(dir string, env []string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func alignTestProject(t *testing.T, path, repo, body string) *ParsedProject {
+	t.Helper()
+	writeProjectFile(t, path, body)
+	p, err := ParseCsproj(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Repo = repo
+	return p
+}
+
+const alignTestCsproj = `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="%s" />
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+</Project>
+`
+
+func TestFindRepoDivergences(t *testing.T) {
+	root := t.TempDir()
+	csproj := func(v string) string { return strings.Replace(alignTestCsproj, "%s", v, 1) }
+	projects := []*ParsedProject{
+		alignTestProject(t, filepath.Join(root, "a", "One.csproj"), "a", csproj("13.0.1")),
+		alignTestProject(t, filepath.Join(root, "a", "Two.csproj"), "a", csproj("13.0.3")),
+		alignTestProject(t, filepath.Join(root, "b", "Three.csproj"), "b", csproj("13.0.1")),
+		alignTestProject(t, filepath.Join(root, "c", "Loose.csproj"), "", csproj("12.0.3")),
+	}
+
+	got := findRepoDivergences(projects)
+	if len(got) != 1 || got[0].Package != "Newtonsoft.Json" {
+		t.Fatalf("divergences = %+v, want only Newtonsoft.Json", got)
+	}
+	d := got[0]
+	if d.Highest != "13.0.3" {
+		t.Errorf("Highest = %q, want 13.0.3", d.Highest)
+	}
+	var seen []string
+	for _, v := range d.Versions {
+		seen = append(seen, v.Repo+"@"+v.Version)
+	}
+	if strings.Join(seen, " ") != "a@13.0.1 a@13.0.3 b@13.0.1" {
+		t.Errorf("versions = %v", seen)
+	}
+}
+
+func TestAlignOnBranches(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		out, err := runGit(dir, nil, nil, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	var projects []*ParsedProject
+	for _, repo := range []string{"a", "b"} {
+		dir := filepath.Join(root, repo)
+		path := filepath.Join(dir, "src", "App.csproj")
+		writeProjectFile(t, path, strings.Replace(alignTestCsproj, "%s", "13.0.1", 1))
+		git(dir, "init", "-q", "-b", "main")
+		git(dir, "add", ".")
+		git(dir, "commit", "-q", "-m", "initial")
+		projects = append(projects, alignTestProject(t, path, repo, strings.Replace(alignTestCsproj, "%s", "13.0.1", 1)))
+	}
+	// An uncommitted edit in b stays in the working tree, off the branch.
+	bPath := filepath.Join(root, "b", "src", "App.csproj")
+	dirty := strings.Replace(alignTestCsproj, "%s", "13.0.1", 1) + "<!-- local -->\n"
+	if err := os.WriteFile(bPath, []byte(dirty), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := alignOnBranches(projects, []alignment{{Package: "newtonsoft.json", Version: "13.0.3"}})
+	if len(results) != 2 {
+		t.Fatalf("results = %+v, want one per repository", results)
+	}
+	for _, res := range results {
+		if res.Err != nil || res.Branch != "guget/align-newtonsoft.json-13.0.3" || res.Files != 1 {
+			t.Fatalf("result = %+v", res)
+		}
+		committed := git(res.Root, "show", res.Branch+":src/App.csproj")
+		if !strings.Contains(committed, `Include="Newtonsoft.Json" Version="13.0.3"`) || strings.Contains(committed, "local") {
+			t.Errorf("%s: branch has\n%s", res.Repo, committed)
+		}
+		if head := git(res.Root, "rev-parse", "--abbrev-ref", "HEAD"); head != "main" {
+			t.Errorf("%s: checked out %s, want main", res.Repo, head)
+		}
+	}
+	if data, _ := os.ReadFile(bPath); string(data) != dirty {
+		t.Errorf("working tree changed:\n%s", data)
+	}
+
+	again := alignOnBranches(projects, []alignment{{Package: "Newtonsoft.Json", Version: "13.0.3"}})
+	if again[0].Err == nil || !strings.Contains(again[0].Err.Error(), "already exists") {
+		t.Errorf("second run = %+v, want branch exists", again[0])
+	}
+}
+
+func TestAllowedAlignments(t *testing.T) {
+	eight := 8
+	rules := packageRules{
+		"Newtonsoft.Json":        {Pin: true, Reason: "breaks serialization"},
+		"Serilog":                {NoBulk: true},
+		"Microsoft.Extensions.*": {Major: &eight},
+	}
+	aligns := []alignment{
+		{Package: "newtonsoft.json", Version: "13.0.3"},
+		{Package: "Serilog", Version: "3.1.1"},
+		{Package: "Microsoft.Extensions.Logging", Version: "9.0.0"},
+		{Package: "Microsoft.Extensions.Http", Version: "8.0.1"},
+		{Package: "Polly", Version: "8.4.0"},
+	}
+
+	kept, heldBack := allowedAlignments(rules, aligns)
+	want := []alignment{aligns[3], aligns[4]}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept = %v, want %v", kept, want)
+	}
+	wantHeld := []string{
+		"newtonsoft.json 13.0.3: pinned",
+		"Serilog 3.1.1: not in bulk",
+		"Microsoft.Extensions.Logging 9.0.0: 8.x only",
+	}
+	if !reflect.DeepEqual(heldBack, wantHeld) {
+		t.Errorf("heldBack = %q, want %q", heldBack, wantHeld)
+	}
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const reportDir = ".guget/reports"
+
+var validReportFormats = []string{"json", "sarif", "markdown"}
+
+// reportExtension is the file extension used for a report format.
+func reportExtension(format string) string {
+	if format == "markdown" {
+		return ".md"
+	}
+	return "." + format
+}
+
+// packageReport is everything `guget report` writes: the loaded projects and
+// the status of every package reference in them.
+type packageReport struct {
+	Generated time.Time       `json:"generated"`
+	Version   string          `json:"gugetVersion"`
+	Root      string          `json:"root"`
+	Projects  []reportProject `json:"projects"`
+	Packages  []packageStatus `json:"packages"`
+}
+
+type reportProject struct {
+	Name             string   `json:"name"`
+	Path             string   `json:"path"`
+	TargetFrameworks []string `json:"targetFrameworks"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// buildReport gathers the status of every package reference, judged as
+// guget outdated does: with pre-releases when prerelease is set, and the
+// update rules from settings applied.
+func buildReport(root string, projects []*ParsedProject, results map[string]nugetResult, rules packageRules, prerelease bool, now time.Time) packageReport {
+	statuses := buildPackageStatuses(projects, results)
+	if prerelease {
+		includePrereleases(statuses)
+	}
+	applyPackageRules(statuses, rules)
+	rep := packageReport{
+		Generated: now.UTC(),
+		Version:   version,
+		Root:      root,
+		Projects:  []reportProject{},
+		Packages:  statuses,
+	}
+	for _, p := range projects {
+		rp := reportProject{Name: p.FileName, Path: p.FilePath, TargetFrameworks: []string{}}
+		for tf := range p.TargetFrameworks {
+			rp.TargetFrameworks = append(rp.TargetFrameworks, tf.String())
+		}
+		slices.Sort(rp.TargetFrameworks)
+		if p.LoadErr != nil {
+			rp.Error = p.LoadErr.Error()
+		}
+		rep.Projects = append(rep.Projects, rp)
+	}
+	if rep.Packages == nil {
+		rep.Packages = []packageStatus{}
+	}
+	return rep
+}
+
+// writeReport renders rep in one of validReportFormats.
+func writeReport(w io.Writer, format string, rep packageReport) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	case "sarif":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(buildSARIF(rep))
+	case "markdown":
+		return writeMarkdownReport(w, rep)
+	}
+	return fmt.Errorf("unknown report format %q (expected %s)", format, strings.Join(validReportFormats, ", "))
+}
+
+// saveReport writes rep to path, or to a timestamped file in .guget/reports
+// when path is empty. Returns the path written.
+func saveReport(root, path, format string, rep packageReport) (string, error) {
+	if path == "" {
+		path = filepath.Join(root, filepath.FromSlash(reportDir), rep.Generated.Local().Format("20060102-150405")+reportExtension(format))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := writeReport(f, format, rep); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// previousReport reads the JSON report a previous run left at path, or
+// returns nil when there is none to compare with.
+func previousReport(path string) *packageReport {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var rep packageReport
+	if err := json.Unmarshal(data, &rep); err != nil {
+		logWarn("Ignoring the previous report %s: %v", path, err)
+		return nil
+	}
+	return &rep
+}
+
+// --- SARIF 2.1.0 ---
+// Only the subset GitHub code scanning reads is modelled.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name"`
+	ShortDescription     sarifText         `json:"shortDescription"`
+	DefaultConfiguration sarifConfig       `json:"defaultConfiguration"`
+	Properties           map[string]string `json:"properties,omitempty"`
+}
+
+type sarifConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifRules has one vulnerability rule per advisory severity, because code
+// scanning reads security-severity from the rule rather than the result.
+var sarifRules = []sarifRule{
+	{ID: "guget/vulnerable-critical", Name: "VulnerablePackageCritical", ShortDescription: sarifText{"Package version has a critical security advisory"}, DefaultConfiguration: sarifConfig{"error"}, Properties: map[string]string{"security-severity": "9.5", "tags": "security"}},
+	{ID: "guget/vulnerable-high", Name: "VulnerablePackageHigh", ShortDescription: sarifText{"Package version has a high severity security advisory"}, DefaultConfiguration: sarifConfig{"error"}, Properties: map[string]string{"security-severity": "8.0", "tags": "security"}},
+	{ID: "guget/vulnerable-moderate", Name: "VulnerablePackageModerate", ShortDescription: sarifText{"Package version has a moderate severity security advisory"}, DefaultConfiguration: sarifConfig{"warning"}, Properties: map[string]string{"security-severity": "5.5", "tags": "security"}},
+	{ID: "guget/vulnerable-low", Name: "VulnerablePackageLow", ShortDescription: sarifText{"Package version has a low severity security advisory"}, DefaultConfiguration: sarifConfig{"note"}, Properties: map[string]string{"security-severity": "2.0", "tags": "security"}},
+	{ID: "guget/deprecated", Name: "DeprecatedPackage", ShortDescription: sarifText{"Package is deprecated by its author"}, DefaultConfiguration: sarifConfig{"warning"}},
+	{ID: "guget/outdated", Name: "OutdatedPackage", ShortDescription: sarifText{"A newer compatible version is available"}, DefaultConfiguration: sarifConfig{"note"}},
+}
+
+func sarifRuleLevel(id string) string {
+	for _, r := range sarifRules {
+		if r.ID == id {
+			return r.DefaultConfiguration.Level
+		}
+	}
+	return "note"
+}
+
+// buildSARIF turns vulnerable, deprecated, and outdated references into
+// results located at the line that sets the package version.
+func buildSARIF(rep packageReport) sarifLog {
+	lines := referenceLineFinder{}
+	results := []sarifResult{}
+	for _, st := range rep.Packages {
+		file := st.file
+		if file == "" {
+			file = st.ProjectPath
+		}
+		uri := file
+		if rel, err := filepath.Rel(rep.Root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			uri = rel
+		}
+		loc := []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifact{URI: filepath.ToSlash(uri)},
+			Region:           sarifRegion{StartLine: lines.find(file, st.Package)},
+		}}}
+		add := func(rule, msg string) {
+			results = append(results, sarifResult{RuleID: rule, Level: sarifRuleLevel(rule), Message: sarifText{msg}, Locations: loc})
+		}
+
+		for _, a := range st.Advisories {
+			add("guget/vulnerable-"+a.Severity, fmt.Sprintf("%s %s in %s has a %s severity advisory: %s", st.Package, st.Installed, st.Project, a.Severity, a.URL))
+		}
+		if st.Deprecated {
+			msg := fmt.Sprintf("%s is deprecated", st.Package)
+			if st.AlternatePackage != "" {
+				msg += "; use " + st.AlternatePackage + " instead"
+			}
+			if st.DeprecationMessage != "" {
+				msg += ": " + st.DeprecationMessage
+			}
+			add("guget/deprecated", msg)
+		}
+		if st.Outdated {
+			add("guget/outdated", fmt.Sprintf("%s %s in %s can be updated to %s", st.Package, st.Installed, st.Project, st.LatestCompatible))
+		}
+	}
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "guget",
+				Version:        rep.Version,
+				InformationURI: "https://github.com/nulifyer/guget",
+				Rules:          sarifRules,
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// referenceLineFinder locates the line of a package's Include/Update
+// attribute, reading each file once. Unknown lines report 1, since SARIF
+// regions are 1-based.
+type referenceLineFinder map[string][]string
+
+func (f referenceLineFinder) find(file, pkg string) int {
+	lines, ok := f[file]
+	if !ok {
+		if data, err := os.ReadFile(file); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		f[file] = lines
+	}
+	re := regexp.MustCompile(`(?i)\b(Include|Update)\s*=\s*"` + regexp.QuoteMeta(pkg) + `"`)
+	for i, line := range lines {
+		if re.MatchString(line) {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// reportSummary counts what a report found.
+type reportSummary struct {
+	Projects   int `json:"projects"`
+	References int `json:"references"`
+	Outdated   int `json:"outdated"`
+	Vulnerable int `json:"vulnerable"`
+	Deprecated int `json:"deprecated"`
+	Failed     int `json:"failed"` // references that could not be checked
+}
+
+func summarizeReport(rep packageReport) reportSummary {
+	sum := reportSummary{Projects: len(rep.Projects), References: len(rep.Packages)}
+	for _, st := range rep.Packages {
+		if st.Outdated {
+			sum.Outdated++
+		}
+		if st.Vulnerable {
+			sum.Vulnerable++
+		}
+		if st.Deprecated {
+			sum.Deprecated++
+		}
+		if st.Error != "" {
+			sum.Failed++
+		}
+	}
+	return sum
+}
+
+// --- Markdown ---
+
+func writeMarkdownReport(w io.Writer, rep packageReport) error {
+	sum := summarizeReport(rep)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Dependency report\n\n")
+	fmt.Fprintf(&b, "Generated %s by guget %s for `%s`.\n\n", rep.Generated.Format(time.RFC3339), rep.Version, rep.Root)
+	fmt.Fprintf(&b, "| Projects | References | Outdated | Vulnerable | Deprecated | Not checked |\n")
+	fmt.Fprintf(&b, "|---:|---:|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d | %d | %d |\n", sum.Projects, sum.References, sum.Outdated, sum.Vulnerable, sum.Deprecated, sum.Failed)
+
+	if sum.Vulnerable > 0 {
+		fmt.Fprintf(&b, "\n## Vulnerabilities\n\n")
+		fmt.Fprintf(&b, "| Project | Package | Installed | Severity | Advisory |\n")
+		fmt.Fprintf(&b, "|---|---|---|---|---|\n")
+		for _, st := range rep.Packages {
+			for _, a := range st.Advisories {
+				fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", mdCell(st.Project), mdCell(st.Package), mdCell(st.Installed), a.Severity, mdCell(a.URL))
+			}
+		}
+	}
+
+	if sum.Deprecated > 0 {
+		fmt.Fprintf(&b, "\n## Deprecated\n\n")
+		fmt.Fprintf(&b, "| Project | Package | Alternative | Message |\n")
+		fmt.Fprintf(&b, "|---|---|---|---|\n")
+		for _, st := range rep.Packages {
+			if st.Deprecated {
+				fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", mdCell(st.Project), mdCell(st.Package), mdCell(st.AlternatePackage), mdCell(st.DeprecationMessage))
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## Packages\n\n")
+	fmt.Fprintf(&b, "| Project | Package | Installed | Latest compatible | Latest stable | Status |\n")
+	fmt.Fprintf(&b, "|---|---|---|---|---|---|\n")
+	for _, st := range rep.Packages {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			mdCell(st.Project), mdCell(st.Package), mdCell(st.Installed),
+			mdCell(st.LatestCompatible), mdCell(st.LatestStable), mdCell(st.statusText()))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdCell makes s safe inside a Markdown table cell.
+func mdCell(s string) string {
+	if s == "" {
+		return "-"
+	}
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// runReportCommand implements `guget report`. Returns the process exit code.
+func runReportCommand(flags BuiltFlags, settings UserConfig) int {
+	if !slices.Contains(validReportFormats, flags.Format) {
+		logError("Unknown report format %q (expected %s)", flags.Format, strings.Join(validReportFormats, ", "))
+		return exitError
+	}
+	if err := validateWebhookURL(flags.Webhook); err != nil {
+		logError("%v", err)
+		return exitError
+	}
+	snap, err := loadWorkspace(flags.ProjectDir, flags.Solution)
+	if err != nil {
+		logError("%v", err)
+		return exitError
+	}
+	results := fetchResults(snap, flags.Deadline, !flags.NoEnrich)
+	rep := buildReport(snap.ProjectDir, snap.ParsedProjects, results, settings.Packages, flags.Prerelease, time.Now())
+	prev := previousReport(announcedReportPath(flags.Output))
+
+	if flags.Output == "" {
+		err = writeReport(os.Stdout, flags.Format, rep)
+	} else {
+		_, err = saveReport(snap.ProjectDir, flags.Output, flags.Format, rep)
+	}
+	if err != nil {
+		logError("Writing report: %v", err)
+		return exitError
+	}
+	if flags.Webhook != "" {
+		if err := announceReport(flags.Webhook, flags.WebhookFormat, announcedReportPath(flags.Output), prev, rep); err != nil {
+			logError("Posting to webhook: %v", err)
+			return exitError
+		}
+	}
+
+	for _, res := range results {
+		if res.err != nil {
+			logError("Some packages could not be checked; the report marks them with an error")
+			return exitError
+		}
+	}
+	return exitOK
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func reportTestFixture(t *testing.T) packageReport {
+	t.Helper()
+	root, projects, results := reportTestInputs(t)
+	return buildReport(root, projects, results, nil, false, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+}
+
+func reportTestInputs(t *testing.T) (string, []*ParsedProject, map[string]nugetResult) {
+	t.Helper()
+	root := t.TempDir()
+	file := filepath.Join(root, "src", "A", "A.csproj")
+	os.MkdirAll(filepath.Dir(file), 0755)
+	os.WriteFile(file, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Old|Lib" Version="1.0.0" />
+    <PackageReference Include="Gone" Version="2.0.0" />
+  </ItemGroup>
+</Project>
+`), 0644)
+
+	a := headlessTestProject("A.csproj", file,
+		PackageReference{Name: "Old|Lib", Version: ParseSemVer("1.0.0")},
+		PackageReference{Name: "Gone", Version: ParseSemVer("2.0.0")},
+	)
+	results := map[string]nugetResult{
+		"Old|Lib": {pkg: &PackageInfo{Versions: []PackageVersion{
+			{SemVer: ParseSemVer("1.1.0")},
+			{SemVer: ParseSemVer("1.0.0"), Vulnerabilities: []PackageVulnerability{{Severity: 3, AdvisoryURL: "https://github.com/advisories/GHSA-1"}}},
+		}}},
+		"Gone": {pkg: &PackageInfo{Deprecated: true, AlternatePackageID: "New", Versions: []PackageVersion{{SemVer: ParseSemVer("2.0.0")}}}},
+	}
+	return root, []*ParsedProject{a}, results
+}
+
+func TestBuildReport_AppliesRulesAndPrerelease(t *testing.T) {
+	root, projects, results := reportTestInputs(t)
+	results["Old|Lib"].pkg.Versions = append([]PackageVersion{{SemVer: ParseSemVer("2.0.0-rc.1")}}, results["Old|Lib"].pkg.Versions...)
+	now := time.Now()
+
+	pinned := buildReport(root, projects, results, packageRules{"Old|Lib": {Pin: true}}, false, now)
+	st := pinned.Packages[slices.IndexFunc(pinned.Packages, func(st packageStatus) bool { return st.Package == "Old|Lib" })]
+	if st.Outdated || !st.Pinned {
+		t.Errorf("pinned = %+v, want pinned and not outdated", st)
+	}
+	if changes := diffReports(nil, pinned); len(changes.Majors) != 0 {
+		t.Errorf("majors = %+v, want none announced for a pinned package", changes.Majors)
+	}
+
+	pre := buildReport(root, projects, results, nil, true, now)
+	st = pre.Packages[slices.IndexFunc(pre.Packages, func(st packageStatus) bool { return st.Package == "Old|Lib" })]
+	if st.LatestCompatible != "2.0.0-rc.1" {
+		t.Errorf("latest with prerelease = %q, want 2.0.0-rc.1", st.LatestCompatible)
+	}
+	one := 1
+	limited := buildReport(root, projects, results, packageRules{"Old|Lib": {Major: &one}}, true, now)
+	st = limited.Packages[slices.IndexFunc(limited.Packages, func(st packageStatus) bool { return st.Package == "Old|Lib" })]
+	if st.LatestCompatible != "1.1.0" {
+		t.Errorf("latest within 1.x = %q, want 1.1.0", st.LatestCompatible)
+	}
+}
+
+func TestWriteReport_JSON(t *testing.T) {
+	rep := reportTestFixture(t)
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "json", rep); err != nil {
+		t.Fatal(err)
+	}
+	var got packageReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got.Projects) != 1 || len(got.Packages) != 2 {
+		t.Fatalf("expected 1 project and 2 packages, got %+v", got)
+	}
+	for _, st := range got.Packages {
+		if st.Package == "Old|Lib" && (len(st.Advisories) != 1 || st.Advisories[0].Severity != "critical") {
+			t.Errorf("expected the critical advisory, got %+v", st.Advisories)
+		}
+		if st.Package == "Gone" && st.AlternatePackage != "New" {
+			t.Errorf("expected the alternate package, got %+v", st)
+		}
+	}
+}
+
+func TestWriteReport_SARIF(t *testing.T) {
+	rep := reportTestFixture(t)
+	log := buildSARIF(rep)
+	results := log.Runs[0].Results
+	rules := map[string]sarifResult{}
+	for _, r := range results {
+		rules[r.RuleID] = r
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected vulnerable, outdated, and deprecated results, got %+v", results)
+	}
+	vuln, ok := rules["guget/vulnerable-critical"]
+	if !ok || vuln.Level != "error" {
+		t.Fatalf("expected an error-level critical result, got %+v", results)
+	}
+	loc := vuln.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "src/A/A.csproj" || loc.Region.StartLine != 3 {
+		t.Errorf("location = %+v, want src/A/A.csproj line 3", loc)
+	}
+	if r := rules["guget/deprecated"]; r.Locations[0].PhysicalLocation.Region.StartLine != 4 || !strings.Contains(r.Message.Text, "use New") {
+		t.Errorf("deprecated result = %+v", r)
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "sarif", rep); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"version": "2.1.0"`) {
+		t.Errorf("expected a SARIF 2.1.0 log, got %s", buf.String())
+	}
+}
+
+func TestWriteReport_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "markdown", reportTestFixture(t)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"## Vulnerabilities", "## Deprecated", `Old\|Lib`, "| 1 | 2 | 1 | 1 | 1 | 0 |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteReport_UnknownFormat(t *testing.T) {
+	if err := writeReport(&bytes.Buffer{}, "xml", packageReport{}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestSummarizeReport(t *testing.T) {
+	got := summarizeReport(reportTestFixture(t))
+	want := reportSummary{Projects: 1, References: 2, Outdated: 1, Vulnerable: 1, Deprecated: 1}
+	if got != want {
+		t.Errorf("summarizeReport = %+v, want %+v", got, want)
+	}
+}
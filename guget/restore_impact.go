@@ -0,0 +1,366 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxImpactPackages bounds how many packages an impact estimate will visit so
+// a pathological graph cannot keep fetching forever.
+const maxImpactPackages = 400
+
+// RestoreImpact is an estimate of what restore would pull after moving a
+// package from one version to another. It diffs the dependency closures of
+// the two versions using each dependency's lower bound, which is what NuGet
+// picks when nothing else in the graph asks for more.
+type RestoreImpact struct {
+	Added      []string // "Id Version" entries only in the new closure
+	Removed    int      // entries only in the old closure
+	Downloads  int      // added entries not already in a local package folder
+	Bytes      int64    // total .nupkg size of Downloads, where the feed reports it
+	Unresolved int      // dependencies whose metadata could not be fetched
+}
+
+// Summary formats the impact as a single status line.
+func (ri RestoreImpact) Summary() string {
+	if len(ri.Added) == 0 {
+		s := "no new packages"
+		if ri.Removed > 0 {
+			s += fmt.Sprintf(", %d dropped", ri.Removed)
+		}
+		return s
+	}
+	s := fmt.Sprintf("+%d package(s), %d to download", len(ri.Added), ri.Downloads)
+	if ri.Bytes > 0 {
+		s += " (" + formatBytes(ri.Bytes) + ")"
+	}
+	if ri.Removed > 0 {
+		s += fmt.Sprintf(", %d dropped", ri.Removed)
+	}
+	if ri.Unresolved > 0 {
+		s += fmt.Sprintf(", %d unresolved", ri.Unresolved)
+	}
+	return s
+}
+
+// formatBytes renders n using binary units (e.g. 1.4 MB).
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// impactResolver fetches and caches package metadata while walking
+// dependency closures. known holds metadata the TUI already loaded.
+type impactResolver struct {
+	services []*NugetService
+	mapping  *PackageSourceMapping
+	targets  Set[TargetFramework]
+
+	mu      sync.Mutex
+	infos   map[string]*PackageInfo // lowercase id → info (nil = lookup failed)
+	sources map[string]*NugetService
+	sem     chan struct{} // bounds lookups in flight, like the primary load
+}
+
+func newImpactResolver(services []*NugetService, mapping *PackageSourceMapping, targets Set[TargetFramework], known map[string]nugetResult) *impactResolver {
+	r := &impactResolver{
+		services: services,
+		mapping:  mapping,
+		targets:  targets,
+		infos:    make(map[string]*PackageInfo),
+		sources:  make(map[string]*NugetService),
+		sem:      make(chan struct{}, fetchConcurrency),
+	}
+	for name, res := range known {
+		// Trimmed packages may lack the exact versions a dependency range
+		// points at; let info() fetch them in full instead.
+		if res.pkg == nil || res.pkg.TrimmedVersions > 0 {
+			continue
+		}
+		key := strings.ToLower(name)
+		r.infos[key] = res.pkg
+		for _, svc := range services {
+			if strings.EqualFold(svc.SourceName(), res.source) {
+				r.sources[key] = svc
+				break
+			}
+		}
+	}
+	return r
+}
+
+// info returns metadata for id, fetching it from the first eligible source.
+func (r *impactResolver) info(id string) *PackageInfo {
+	key := strings.ToLower(id)
+	r.mu.Lock()
+	info, ok := r.infos[key]
+	r.mu.Unlock()
+	if ok {
+		return info
+	}
+	var src *NugetService
+	for _, svc := range FilterServices(r.services, r.mapping, id) {
+		if fetched, err := svc.SearchExact(id); err == nil {
+			info, src = fetched, svc
+			break
+		}
+	}
+	r.mu.Lock()
+	r.infos[key] = info
+	if src != nil {
+		r.sources[key] = src
+	}
+	r.mu.Unlock()
+	return info
+}
+
+// dependencies returns the dependencies of id@version that apply to the
+// resolver's target frameworks, as lowercase id → lower-bound version.
+func (r *impactResolver) dependencies(id, version string) (map[string]string, bool) {
+	info := r.info(id)
+	if info == nil {
+		return nil, false
+	}
+	var pv *PackageVersion
+	for i := range info.Versions {
+		if strings.EqualFold(info.Versions[i].SemVer.String(), version) {
+			pv = &info.Versions[i]
+			break
+		}
+	}
+	if pv == nil {
+		return nil, false
+	}
+	deps := make(map[string]string)
+	for _, g := range selectDependencyGroups(pv.DependencyGroups, r.targets) {
+		for _, d := range g.Dependencies {
+			ver := ParseSemVer(d.Range)
+			key := strings.ToLower(d.ID)
+			if cur, ok := deps[key]; !ok || ver.IsNewerThan(ParseSemVer(cur)) {
+				deps[key] = ver.String()
+			}
+		}
+	}
+	return deps, true
+}
+
+// selectDependencyGroups picks, for each target framework, the dependency
+// group restore would use: the newest compatible group of the same family,
+// then any compatible group, then the framework-agnostic group.
+func selectDependencyGroups(groups []dependencyGroup, targets Set[TargetFramework]) []dependencyGroup {
+	if len(groups) == 0 {
+		return nil
+	}
+	if targets.Len() == 0 {
+		return groups
+	}
+	picked := make(map[int]bool)
+	for target := range targets {
+		best := -1
+		var bestFw TargetFramework
+		for i, g := range groups {
+			fw := ParseTargetFramework(normFramework(g.TargetFramework))
+			if !target.IsCompatibleWith(fw) {
+				continue
+			}
+			switch {
+			case best < 0,
+				fw.Family == target.Family && (bestFw.Family != target.Family || fw.IsNewerThan(bestFw)),
+				bestFw.Family != target.Family && fw.IsNewerThan(bestFw):
+				best, bestFw = i, fw
+			}
+		}
+		if best >= 0 {
+			picked[best] = true
+		}
+	}
+	var out []dependencyGroup
+	for i, g := range groups {
+		if picked[i] {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// closure walks the dependency graph of id@version breadth-first, fetching
+// each level in parallel, at most fetchConcurrency lookups at a time. Where
+// a package is reached more than once the highest requested version wins.
+// The walk stops growing at maxImpactPackages, even partway through a level.
+// Returns lowercase id → version and the number of packages whose metadata
+// could not be loaded.
+func (r *impactResolver) closure(id, version string) (map[string]string, int) {
+	result := map[string]string{strings.ToLower(id): version}
+	names := map[string]string{strings.ToLower(id): id}
+	level := map[string]string{strings.ToLower(id): version}
+	unresolved, visited := 0, 0
+	for len(level) > 0 && visited < maxImpactPackages {
+		type found struct {
+			deps map[string]string
+			ok   bool
+		}
+		results := make(map[string]found, len(level))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for key, ver := range level {
+			if visited >= maxImpactPackages {
+				break
+			}
+			visited++
+			wg.Add(1)
+			r.sem <- struct{}{}
+			go func(name, ver string) {
+				defer func() { <-r.sem; wg.Done() }()
+				deps, ok := r.dependencies(name, ver)
+				mu.Lock()
+				results[strings.ToLower(name)] = found{deps, ok}
+				mu.Unlock()
+			}(names[key], ver)
+		}
+		wg.Wait()
+
+		next := make(map[string]string)
+		for _, f := range results {
+			if !f.ok {
+				unresolved++
+				continue
+			}
+			for dep, ver := range f.deps {
+				cur, seen := result[dep]
+				if seen && !ParseSemVer(ver).IsNewerThan(ParseSemVer(cur)) {
+					continue
+				}
+				if !seen && len(result) >= maxImpactPackages {
+					continue
+				}
+				result[dep] = ver
+				next[dep] = ver
+				if _, ok := names[dep]; !ok {
+					names[dep] = dep
+				}
+			}
+		}
+		level = next
+	}
+	// Report packages with the casing their metadata uses.
+	out := make(map[string]string, len(result))
+	for key, ver := range result {
+		name := key
+		r.mu.Lock()
+		if info := r.infos[key]; info != nil {
+			name = info.ID
+		}
+		r.mu.Unlock()
+		out[name] = ver
+	}
+	return out, unresolved
+}
+
+// packageSize asks the source that served id for the size of its .nupkg.
+func (r *impactResolver) packageSize(id, version string) int64 {
+	r.mu.Lock()
+	svc := r.sources[strings.ToLower(id)]
+	r.mu.Unlock()
+	if svc == nil {
+		return 0
+	}
+	size, err := svc.PackageSize(id, version)
+	if err != nil {
+		logDebug("package size for %s %s: %v", id, version, err)
+	}
+	return size
+}
+
+// EstimateRestoreImpact diffs the dependency closures of pkgName at
+// fromVersion and toVersion. An empty fromVersion treats the package as newly
+// added. Packages already in folders are counted as added but not as
+// downloads.
+func EstimateRestoreImpact(r *impactResolver, folders PackageFolders, pkgName, fromVersion, toVersion string) RestoreImpact {
+	var oldClosure map[string]string
+	if fromVersion != "" {
+		oldClosure, _ = r.closure(pkgName, fromVersion)
+	}
+	newClosure, unresolved := r.closure(pkgName, toVersion)
+
+	oldSet := make(map[string]bool, len(oldClosure))
+	for id, ver := range oldClosure {
+		oldSet[strings.ToLower(id+" "+ver)] = true
+	}
+	newSet := make(map[string]bool, len(newClosure))
+	impact := RestoreImpact{Unresolved: unresolved}
+	var toDownload [][2]string
+	for id, ver := range newClosure {
+		entry := id + " " + ver
+		newSet[strings.ToLower(entry)] = true
+		if oldSet[strings.ToLower(entry)] {
+			continue
+		}
+		impact.Added = append(impact.Added, entry)
+		if _, cached := folders.Contains(id, ver); !cached {
+			toDownload = append(toDownload, [2]string{id, ver})
+		}
+	}
+	for entry := range oldSet {
+		if !newSet[entry] {
+			impact.Removed++
+		}
+	}
+	sort.Strings(impact.Added)
+	impact.Downloads = len(toDownload)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, d := range toDownload {
+		wg.Add(1)
+		r.sem <- struct{}{}
+		go func(id, ver string) {
+			defer func() { <-r.sem; wg.Done() }()
+			if n := r.packageSize(id, ver); n > 0 {
+				mu.Lock()
+				impact.Bytes += n
+				mu.Unlock()
+			}
+		}(d[0], d[1])
+	}
+	wg.Wait()
+	return impact
+}
+
+// PackageSize returns the size in bytes of a .nupkg in the flat container,
+// read from a HEAD request. Returns 0 when the feed does not report it.
+func (s *NugetService) PackageSize(packageID, version string) (int64, error) {
+	if s.flatBase == "" {
+		return 0, fmt.Errorf("no PackageBaseAddress for %s", s.sourceName)
+	}
+	lower := strings.ToLower(packageID)
+	ver := strings.ToLower(version)
+	u := fmt.Sprintf("%s/%s/%s/%s.%s.nupkg", s.flatBase, lower, ver, lower, ver)
+	logTrace("[%s] HEAD %s", s.sourceName, u)
+	req, err := http.NewRequest(http.MethodHead, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, &httpStatusError{Code: resp.StatusCode, URL: u}
+	}
+	if resp.ContentLength < 0 {
+		return 0, nil
+	}
+	return resp.ContentLength, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func impactPackage(id string, versions map[string][]dependencyGroup) nugetResult {
+	info := &PackageInfo{ID: id}
+	for ver, groups := range versions {
+		info.Versions = append(info.Versions, PackageVersion{SemVer: ParseSemVer(ver), DependencyGroups: groups})
+	}
+	return nugetResult{pkg: info}
+}
+
+func TestEstimateRestoreImpact(t *testing.T) {
+	known := map[string]nugetResult{
+		"App.Lib": impactPackage("App.Lib", map[string][]dependencyGroup{
+			"1.0.0": {{TargetFramework: "net8.0", Dependencies: []packageDependency{{ID: "Shared", Range: "[1.0.0, )"}}}},
+			"2.0.0": {{TargetFramework: "net8.0", Dependencies: []packageDependency{
+				{ID: "Shared", Range: "[2.0.0, )"},
+				{ID: "Extra", Range: "1.0.0"},
+			}}},
+		}),
+		"Shared": impactPackage("Shared", map[string][]dependencyGroup{
+			"1.0.0": nil,
+			"2.0.0": nil,
+		}),
+		"Extra": impactPackage("Extra", map[string][]dependencyGroup{
+			"1.0.0": {
+				{TargetFramework: ".NETStandard2.0", Dependencies: []packageDependency{{ID: "Polyfill", Range: "1.0.0"}}},
+				{TargetFramework: "net8.0", Dependencies: []packageDependency{{ID: "Shared", Range: "1.5.0"}}},
+			},
+		}),
+	}
+	targets := NewSet[TargetFramework]()
+	targets.Add(ParseTargetFramework("net8.0"))
+
+	r := newImpactResolver(nil, nil, targets, known)
+	impact := EstimateRestoreImpact(r, PackageFolders{}, "App.Lib", "1.0.0", "2.0.0")
+
+	// Extra's net8.0 group wins over netstandard2.0, and its lower Shared
+	// request does not displace the 2.0.0 already in the graph.
+	want := []string{"App.Lib 2.0.0", "Extra 1.0.0", "Shared 2.0.0"}
+	if !reflect.DeepEqual(impact.Added, want) {
+		t.Errorf("Added = %v, want %v", impact.Added, want)
+	}
+	if impact.Removed != 2 {
+		t.Errorf("Removed = %d, want 2", impact.Removed)
+	}
+	if impact.Downloads != 3 || impact.Unresolved != 0 {
+		t.Errorf("Downloads = %d, Unresolved = %d, want 3 and 0", impact.Downloads, impact.Unresolved)
+	}
+}
+
+func TestEstimateRestoreImpact_AddMode(t *testing.T) {
+	known := map[string]nugetResult{
+		"Solo": impactPackage("Solo", map[string][]dependencyGroup{
+			"1.0.0": {{Dependencies: []packageDependency{{ID: "Missing", Range: "1.0.0"}}}},
+		}),
+	}
+	r := newImpactResolver(nil, nil, NewSet[TargetFramework](), known)
+	impact := EstimateRestoreImpact(r, PackageFolders{}, "Solo", "", "1.0.0")
+	if len(impact.Added) != 2 || impact.Removed != 0 {
+		t.Errorf("Added = %v, Removed = %d", impact.Added, impact.Removed)
+	}
+	if impact.Unresolved != 1 {
+		t.Errorf("Unresolved = %d, want 1 (no sources to fetch Missing from)", impact.Unresolved)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[int64]string{512: "512 B", 2048: "2.0 KB", 5 * 1024 * 1024: "5.0 MB"}
+	for n, want := range cases {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestImpactResolverClosure_BoundsLookups(t *testing.T) {
+	defer func(n int) { fetchConcurrency = n }(fetchConcurrency)
+	setFetchConcurrency(3)
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	svc := &NugetService{
+		sourceURL:  srv.URL + "/index.json",
+		sourceName: "feed",
+		client:     srv.Client(),
+		searchBase: srv.URL + "/search",
+		regBase:    srv.URL + "/registration/",
+	}
+
+	// A root with more direct dependencies than the cap allows.
+	var deps []packageDependency
+	for i := range maxImpactPackages + 100 {
+		deps = append(deps, packageDependency{ID: fmt.Sprintf("Dep%d", i), Range: "1.0.0"})
+	}
+	known := map[string]nugetResult{
+		"Wide": impactPackage("Wide", map[string][]dependencyGroup{"1.0.0": {{Dependencies: deps}}}),
+	}
+	r := newImpactResolver([]*NugetService{svc}, nil, NewSet[TargetFramework](), known)
+	closure, unresolved := r.closure("Wide", "1.0.0")
+
+	if len(closure) > maxImpactPackages {
+		t.Errorf("closure has %d packages, want at most %d", len(closure), maxImpactPackages)
+	}
+	if unresolved != len(closure)-1 {
+		t.Errorf("unresolved = %d, want %d", unresolved, len(closure)-1)
+	}
+	if peak > 3 {
+		t.Errorf("peak concurrent lookups = %d, want at most 3", peak)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// retargetFrameworks are the target frameworks offered when previewing a
+// retarget, oldest first.
+var retargetFrameworks = []string{"netstandard2.0", "netstandard2.1", "net6.0", "net7.0", "net8.0", "net9.0", "net10.0"}
+
+// retargetCandidates lists the frameworks a project targeting current could
+// move to: those it doesn't target yet and that aren't older than a target
+// of the same family.
+func retargetCandidates(current Set[TargetFramework]) []TargetFramework {
+	var out []TargetFramework
+	for _, raw := range retargetFrameworks {
+		tf := ParseTargetFramework(raw)
+		older := false
+		for c := range current {
+			if c.Family == tf.Family && !tf.IsNewerThan(c) {
+				older = true
+			}
+		}
+		if !older {
+			out = append(out, tf)
+		}
+	}
+	return out
+}
+
+// retargetVerdict is what retargeting a project means for one of its
+// packages.
+type retargetVerdict int
+
+const (
+	retargetBlocked retargetVerdict = iota // no known version supports the new target
+	retargetUpdate                         // a newer version does; the installed one doesn't
+	retargetUnknown                        // the package's versions aren't loaded
+	retargetOK                             // the installed version supports the new target
+)
+
+// retargetImpact is one package reference under a retarget.
+type retargetImpact struct {
+	Package   string
+	Installed SemVer
+	Verdict   retargetVerdict
+	LatestNow *PackageVersion // newest version for the current targets
+	LatestNew *PackageVersion // newest version for the new target
+}
+
+// gainsVersion reports whether the new target makes a newer version
+// available than the current targets allow.
+func (r retargetImpact) gainsVersion() bool {
+	if r.LatestNew == nil {
+		return false
+	}
+	return r.LatestNow == nil || r.LatestNew.SemVer.IsNewerThan(r.LatestNow.SemVer)
+}
+
+// analyzeRetarget previews moving p from its target frameworks to to: for
+// every package it references, whether the installed version still fits,
+// and the newest version before and after. Blockers sort first.
+func analyzeRetarget(p *ParsedProject, results map[string]nugetResult, to TargetFramework, prerelease bool) []retargetImpact {
+	target := NewSet[TargetFramework]()
+	target.Add(to)
+	var out []retargetImpact
+	for ref := range p.Packages {
+		r := retargetImpact{Package: ref.Name, Installed: ref.Version, Verdict: retargetUnknown}
+		info := results[ref.Name].pkg
+		if info == nil || len(info.Versions) == 0 {
+			out = append(out, r)
+			continue
+		}
+		r.Installed = info.Resolve(ref.Version)
+		r.LatestNow = info.LatestForFramework(p.TargetFrameworks, prerelease || r.Installed.IsPreRelease())
+		r.LatestNew = info.LatestForFramework(target, prerelease || r.Installed.IsPreRelease())
+		for i := range info.Versions {
+			v := &info.Versions[i]
+			if v.SemVer.String() != r.Installed.String() {
+				continue
+			}
+			if v.supportsAll(target) {
+				r.Verdict = retargetOK
+			}
+			break
+		}
+		if r.Verdict != retargetOK {
+			switch {
+			case r.LatestNew == nil:
+				r.Verdict = retargetBlocked
+			case r.LatestNew.SemVer.IsNewerThan(r.Installed):
+				r.Verdict = retargetUpdate
+			}
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Verdict != out[j].Verdict {
+			return out[i].Verdict < out[j].Verdict
+		}
+		return strings.ToLower(out[i].Package) < strings.ToLower(out[j].Package)
+	})
+	return out
+}
+
+// SetTargetFramework rewrites the one unconditional <TargetFramework> of
+// filePath to framework. Projects that multi-target, set the framework
+// under a condition, or inherit it from an import are refused: those need
+// a human to decide what the new value should be.
+func SetTargetFramework(filePath, framework string) error {
+	file, err := readTextFile(filePath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filePath, err)
+	}
+	text := file.Text
+	elems, err := scanXML(text)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", filePath, err)
+	}
+	found := -1
+	for i := range elems {
+		e := &elems[i]
+		switch {
+		case strings.EqualFold(e.Name, "TargetFrameworks"):
+			return fmt.Errorf("%s multi-targets; edit <TargetFrameworks> by hand", filePath)
+		case !strings.EqualFold(e.Name, "TargetFramework") || e.Parent < 0:
+			continue
+		}
+		group := &elems[e.Parent]
+		if e.attr("Condition") != nil || group.attr("Condition") != nil || group.Parent < 0 || elems[group.Parent].Parent != -1 {
+			return fmt.Errorf("%s sets <TargetFramework> conditionally; edit it by hand", filePath)
+		}
+		if found >= 0 {
+			return fmt.Errorf("%s sets <TargetFramework> more than once; edit it by hand", filePath)
+		}
+		found = i
+	}
+	if found < 0 {
+		return fmt.Errorf("%s doesn't set <TargetFramework> itself; it is inherited from an import", filePath)
+	}
+	e := elems[found]
+	if e.SelfClosing || strings.Contains(text[e.TagEnd:e.CloseStart], "$(") {
+		return fmt.Errorf("%s sets <TargetFramework> from a property; edit it by hand", filePath)
+	}
+	edit := textEdit{Start: e.TagEnd, End: e.CloseStart, Text: framework}
+	return writeTextFile(filePath, file, applyEdits(text, []textEdit{edit}))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRetargetCandidates(t *testing.T) {
+	current := NewSet[TargetFramework]()
+	current.Add(ParseTargetFramework("net8.0"))
+	current.Add(ParseTargetFramework("netstandard2.0"))
+	var got []string
+	for _, tf := range retargetCandidates(current) {
+		got = append(got, tf.String())
+	}
+	if want := "netstandard2.1 net9.0 net10.0"; strings.Join(got, " ") != want {
+		t.Errorf("candidates = %v, want %s", got, want)
+	}
+}
+
+func TestAnalyzeRetarget(t *testing.T) {
+	fws := func(raw ...string) []TargetFramework {
+		var out []TargetFramework
+		for _, r := range raw {
+			out = append(out, ParseTargetFramework(r))
+		}
+		return out
+	}
+	version := func(v string, frameworks ...string) PackageVersion {
+		return PackageVersion{SemVer: ParseSemVer(v), Frameworks: fws(frameworks...)}
+	}
+	results := map[string]nugetResult{
+		// Newest first, as the feeds return them.
+		"Portable": {pkg: &PackageInfo{Versions: []PackageVersion{version("2.0.0", "net8.0"), version("1.0.0", "netstandard2.0")}}},
+		"NeedsBump": {pkg: &PackageInfo{Versions: []PackageVersion{
+			version("3.0.0", "netstandard2.0"), version("1.0.0", "net472"),
+		}}},
+		"Legacy": {pkg: &PackageInfo{Versions: []PackageVersion{version("4.0.0", "net472")}}},
+	}
+	p := &ParsedProject{TargetFrameworks: NewSet[TargetFramework](), Packages: NewSet[PackageReference]()}
+	p.TargetFrameworks.Add(ParseTargetFramework("net472"))
+	for _, ref := range []string{"Portable@1.0.0", "NeedsBump@1.0.0", "Legacy@4.0.0", "Missing@1.0.0"} {
+		name, v, _ := strings.Cut(ref, "@")
+		p.Packages.Add(PackageReference{Name: name, Version: ParseSemVer(v)})
+	}
+
+	got := analyzeRetarget(p, results, ParseTargetFramework("net8.0"), false)
+	want := []struct {
+		name    string
+		verdict retargetVerdict
+		latest  string
+		gains   bool
+	}{
+		{"Legacy", retargetBlocked, "", false},
+		{"NeedsBump", retargetUpdate, "3.0.0", false},
+		{"Missing", retargetUnknown, "", false},
+		{"Portable", retargetOK, "2.0.0", true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d impacts, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		r := got[i]
+		latest := ""
+		if r.LatestNew != nil {
+			latest = r.LatestNew.SemVer.String()
+		}
+		if r.Package != w.name || r.Verdict != w.verdict || latest != w.latest || r.gainsVersion() != w.gains {
+			t.Errorf("impact %d = %s verdict %d latest %q gains %v, want %+v", i, r.Package, r.Verdict, latest, r.gainsVersion(), w)
+		}
+	}
+}
+
+func TestSetTargetFramework(t *testing.T) {
+	tests := []struct {
+		name, before, after, err string
+	}{
+		{
+			name:   "rewrites the value in place",
+			before: "<Project Sdk=\"Microsoft.NET.Sdk\">\r\n  <PropertyGroup>\r\n    <TargetFramework>net6.0</TargetFramework>\r\n  </PropertyGroup>\r\n</Project>\r\n",
+			after:  "<Project Sdk=\"Microsoft.NET.Sdk\">\r\n  <PropertyGroup>\r\n    <TargetFramework>net8.0</TargetFramework>\r\n  </PropertyGroup>\r\n</Project>\r\n",
+		},
+		{
+			name:   "refuses multi-targeting",
+			before: "<Project><PropertyGroup><TargetFrameworks>net6.0;net48</TargetFrameworks></PropertyGroup></Project>",
+			err:    "multi-targets",
+		},
+		{
+			name:   "refuses a conditional value",
+			before: "<Project><PropertyGroup Condition=\"'$(Ci)' == 'true'\"><TargetFramework>net6.0</TargetFramework></PropertyGroup></Project>",
+			err:    "conditionally",
+		},
+		{
+			name:   "refuses an inherited value",
+			before: "<Project><PropertyGroup><OutputType>Exe</OutputType></PropertyGroup></Project>",
+			err:    "inherited",
+		},
+		{
+			name:   "refuses a property reference",
+			before: "<Project><PropertyGroup><TargetFramework>$(DefaultTfm)</TargetFramework></PropertyGroup></Project>",
+			err:    "from a property",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "App.csproj")
+			os.WriteFile(path, []byte(tt.before), 0644)
+			err := SetTargetFramework(path, "net8.0")
+			got, _ := os.ReadFile(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("err = %v, want one mentioning %q", err, tt.err)
+				}
+				if string(got) != tt.before {
+					t.Errorf("file changed despite the error:\n%s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.after {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.after)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/xml"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -14,10 +15,18 @@ type SemVer struct {
 	PreRelease string // e.g. "beta.1", "rc.2"
 	Build      string // build metadata after '+', ignored for precedence
 	Raw        string
+	// Range is the declaration as written when it is a version range or a
+	// floating version, e.g. "[1.0,2.0)" or "8.*"; empty for a plain version.
+	// The other fields then describe its lower bound.
+	Range string
 }
 
 func ParseSemVer(s string) SemVer {
 	raw := s
+	declared := ""
+	if isVersionRange(s) {
+		declared = strings.TrimSpace(s)
+	}
 
 	// Handle NuGet version range notation: [min,max), (min,max], [min,), etc.
 	// Extract the lower bound as the effective version for display and comparison.
@@ -63,9 +72,17 @@ func ParseSemVer(s string) SemVer {
 		PreRelease: pre,
 		Build:      build,
 		Raw:        raw,
+		Range:      declared,
 	}
 }
 
+// isVersionRange reports whether s is a NuGet range or floating version
+// rather than a single version.
+func isVersionRange(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "[") || strings.HasPrefix(s, "(") || strings.Contains(s, "*")
+}
+
 // IsNewerThan returns true if v is strictly newer than other.
 // Follows SemVer 2.0.0 precedence rules. Build metadata is ignored.
 func (v SemVer) IsNewerThan(other SemVer) bool {
@@ -152,3 +169,185 @@ func (s *SemVer) UnmarshalXMLAttr(attr xml.Attr) error {
 	*s = ParseSemVer(attr.Value)
 	return nil
 }
+
+// Declared returns the version as written in the project: the range or
+// floating version when there is one, otherwise the version itself.
+func (v SemVer) Declared() string {
+	if v.Range != "" {
+		return v.Range
+	}
+	return v.String()
+}
+
+// Retarget returns what to write in place of v to move it to newVersion,
+// keeping v's range or floating syntax (see VersionRange.Retarget).
+func (v SemVer) Retarget(newVersion string) string {
+	r, ok := ParseVersionRange(v.Range)
+	if !ok {
+		return newVersion
+	}
+	return r.Retarget(ParseSemVer(newVersion))
+}
+
+// VersionRange is a parsed NuGet version range ("[1.0,2.0)", "(,3.0]",
+// "[1.2.3]") or floating version ("8.*", "8.1.*", "1.2.3-*", "*-*").
+type VersionRange struct {
+	Min, Max     SemVer
+	HasMin       bool
+	HasMax       bool
+	MinInclusive bool
+	MaxInclusive bool
+
+	floating   bool
+	floatDepth int    // numeric parts fixed before the '*'; -1 when only the label floats
+	floatPre   bool   // pre-release labels may match
+	prePrefix  string // fixed start of a floating label: "beta." in "1.0.0-beta.*"
+}
+
+// ParseVersionRange parses a range or floating version. ok is false for a
+// plain version or malformed input.
+func ParseVersionRange(s string) (r VersionRange, ok bool) {
+	s = strings.TrimSpace(s)
+	if !isVersionRange(s) {
+		return VersionRange{}, false
+	}
+	if s[0] != '[' && s[0] != '(' {
+		return parseFloatRange(s)
+	}
+	last := s[len(s)-1]
+	if len(s) < 3 || (last != ']' && last != ')') {
+		return VersionRange{}, false
+	}
+	inner := s[1 : len(s)-1]
+	lo, hi, isInterval := strings.Cut(inner, ",")
+	lo, hi = strings.TrimSpace(lo), strings.TrimSpace(hi)
+	if !isInterval {
+		// "[1.2.3]" is the only valid single-version form.
+		if s[0] != '[' || last != ']' || lo == "" {
+			return VersionRange{}, false
+		}
+		hi = lo
+	}
+	r = VersionRange{MinInclusive: s[0] == '[', MaxInclusive: last == ']'}
+	if lo != "" {
+		r.Min, r.HasMin = ParseSemVer(lo), true
+	}
+	if hi != "" {
+		r.Max, r.HasMax = ParseSemVer(hi), true
+	}
+	if !r.HasMin && !r.HasMax {
+		return VersionRange{}, false
+	}
+	return r, true
+}
+
+func parseFloatRange(s string) (VersionRange, bool) {
+	r := VersionRange{floating: true, HasMin: true, MinInclusive: true, floatDepth: -1}
+	num, pre, hasPre := strings.Cut(s, "-")
+	if hasPre {
+		if !strings.HasSuffix(pre, "*") || strings.Count(pre, "*") != 1 {
+			return VersionRange{}, false
+		}
+		r.floatPre, r.prePrefix = true, strings.TrimSuffix(pre, "*")
+	}
+	parts := strings.Split(num, ".")
+	if parts[len(parts)-1] == "*" {
+		r.floatDepth = len(parts) - 1
+		parts = parts[:r.floatDepth]
+	}
+	if len(parts) > 4 || strings.Contains(strings.Join(parts, "."), "*") {
+		return VersionRange{}, false
+	}
+	if r.floatDepth < 0 && !r.floatPre {
+		return VersionRange{}, false
+	}
+	r.Min = ParseSemVer(strings.Join(parts, "."))
+	return r, true
+}
+
+// IsFloating reports whether the range is a floating version such as "8.*".
+func (r VersionRange) IsFloating() bool { return r.floating }
+
+// Contains reports whether v satisfies the range. Pre-releases only satisfy
+// a floating version whose label floats, or an interval with a pre-release
+// bound, as in NuGet.
+func (r VersionRange) Contains(v SemVer) bool {
+	if r.floating {
+		if v.IsPreRelease() && (!r.floatPre || !strings.HasPrefix(v.PreRelease, r.prePrefix)) {
+			return false
+		}
+		depth := r.floatDepth
+		if depth < 0 {
+			depth = 4
+		}
+		have := [4]int{v.Major, v.Minor, v.Patch, v.Revision}
+		want := [4]int{r.Min.Major, r.Min.Minor, r.Min.Patch, r.Min.Revision}
+		return slices.Equal(have[:depth], want[:depth])
+	}
+	if v.IsPreRelease() && !(r.HasMin && r.Min.IsPreRelease()) && !(r.HasMax && r.Max.IsPreRelease()) {
+		return false
+	}
+	if r.HasMin && (r.Min.IsNewerThan(v) || (!r.MinInclusive && !v.IsNewerThan(r.Min))) {
+		return false
+	}
+	if r.HasMax && (v.IsNewerThan(r.Max) || (!r.MaxInclusive && !r.Max.IsNewerThan(v))) {
+		return false
+	}
+	return true
+}
+
+// Best returns the version restore would pick from available: the highest
+// match for a floating version, the lowest for an interval.
+func (r VersionRange) Best(available []SemVer) (SemVer, bool) {
+	var best SemVer
+	found := false
+	for _, v := range available {
+		if !r.Contains(v) {
+			continue
+		}
+		if !found || (r.floating && v.IsNewerThan(best)) || (!r.floating && best.IsNewerThan(v)) {
+			best, found = v, true
+		}
+	}
+	return best, found
+}
+
+// Retarget returns the range moved to v in the same syntax: a floating
+// version keeps how many parts float ("8.*" → "9.*"), an exact pin stays
+// exact ("[1.2.3]" → "[2.0.0]"), and an interval gets v as its inclusive
+// lower bound. An upper bound that would exclude v moves to the next major
+// version, so the range keeps its intent of staying below a breaking release.
+func (r VersionRange) Retarget(v SemVer) string {
+	plain := v.String()
+	if i := strings.IndexByte(plain, '-'); i >= 0 {
+		plain = plain[:i]
+	}
+	if r.floating {
+		out := plain
+		if r.floatDepth >= 0 {
+			parts := strings.Split(plain, ".")
+			for len(parts) < r.floatDepth {
+				parts = append(parts, "0")
+			}
+			out = strings.Join(append(parts[:r.floatDepth:r.floatDepth], "*"), ".")
+		}
+		if r.floatPre {
+			out += "-" + r.prePrefix + "*"
+		}
+		return out
+	}
+	if r.HasMin && r.HasMax && r.MinInclusive && r.MaxInclusive && r.Min.String() == r.Max.String() {
+		return "[" + v.String() + "]"
+	}
+	if !r.HasMax {
+		return "[" + v.String() + ",)"
+	}
+	max, closing := r.Max.String(), ")"
+	if r.MaxInclusive {
+		closing = "]"
+	}
+	if v.IsNewerThan(r.Max) || (!r.MaxInclusive && !r.Max.IsNewerThan(v)) {
+		max, closing = strconv.Itoa(v.Major+1)+".0.0", ")"
+	}
+	return "[" + v.String() + "," + max + closing
+}
@@ -517,3 +517,124 @@ func TestIsNewerThan_BuildMetadataIgnored(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSemVer_KeepsRangeDeclaration(t *testing.T) {
+	tests := []struct {
+		input, raw, declared string
+	}{
+		{"[1.15.0,2.0)", "1.15.0", "[1.15.0,2.0)"},
+		{"8.*", "8.*", "8.*"},
+		{"1.2.3-*", "1.2.3-*", "1.2.3-*"},
+		{"1.2.3", "1.2.3", "1.2.3"},
+	}
+	for _, tt := range tests {
+		v := ParseSemVer(tt.input)
+		if v.Raw != tt.raw || v.Declared() != tt.declared {
+			t.Errorf("ParseSemVer(%q): Raw %q Declared %q, want %q %q", tt.input, v.Raw, v.Declared(), tt.raw, tt.declared)
+		}
+	}
+	if ParseSemVer("1.2.3").Range != "" {
+		t.Error("a plain version should have no Range")
+	}
+}
+
+func TestParseVersionRange_Invalid(t *testing.T) {
+	for _, s := range []string{"1.2.3", "", "[,]", "[1.0", "(1.0)", "1.*.3", "1.0-beta"} {
+		if _, ok := ParseVersionRange(s); ok {
+			t.Errorf("ParseVersionRange(%q) ok, want rejected", s)
+		}
+	}
+}
+
+func TestVersionRange_Contains(t *testing.T) {
+	tests := []struct {
+		rng, v string
+		want   bool
+	}{
+		{"[1.0,2.0)", "1.0", true},
+		{"[1.0,2.0)", "1.9.9", true},
+		{"[1.0,2.0)", "2.0", false},
+		{"[1.0,2.0]", "2.0", true},
+		{"(1.0,2.0)", "1.0", false},
+		{"[1.0,)", "42.0", true},
+		{"(,2.0]", "0.1", true},
+		{"[1.0,2.0)", "1.5.0-beta", false}, // no pre-release bound
+		{"[1.0-beta,2.0)", "1.5.0-beta", true},
+		{"[1.2.3]", "1.2.3", true},
+		{"[1.2.3]", "1.2.4", false},
+		{"8.*", "8.0.11", true},
+		{"8.*", "9.0.0", false},
+		{"8.*", "8.1.0-rc.1", false},
+		{"8.1.*", "8.1.7", true},
+		{"8.1.*", "8.2.0", false},
+		{"*", "13.0.3", true},
+		{"*-*", "14.0.0-preview.1", true},
+		{"1.2.3-*", "1.2.3-beta.4", true},
+		{"1.2.3-*", "1.2.3", true},
+		{"1.2.3-*", "1.2.4-beta", false},
+		{"1.0.0-beta.*", "1.0.0-beta.7", true},
+		{"1.0.0-beta.*", "1.0.0-rc.1", false},
+	}
+	for _, tt := range tests {
+		r, ok := ParseVersionRange(tt.rng)
+		if !ok {
+			t.Errorf("ParseVersionRange(%q) failed", tt.rng)
+			continue
+		}
+		if got := r.Contains(ParseSemVer(tt.v)); got != tt.want {
+			t.Errorf("%s contains %s = %v, want %v", tt.rng, tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestVersionRange_Best(t *testing.T) {
+	var available []SemVer
+	for _, s := range []string{"9.0.0", "8.0.11", "8.0.10", "8.0.0", "7.0.5", "7.0.0"} {
+		available = append(available, ParseSemVer(s))
+	}
+	tests := []struct {
+		rng, want string
+	}{
+		{"8.*", "8.0.11"},        // floating: highest match
+		{"[7.0.1,9.0)", "7.0.5"}, // interval: lowest match
+		{"*", "9.0.0"},
+		{"[10.0,)", ""},
+	}
+	for _, tt := range tests {
+		r, _ := ParseVersionRange(tt.rng)
+		got, ok := r.Best(available)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("%s: got %s, want no match", tt.rng, got)
+			}
+			continue
+		}
+		if !ok || got.String() != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.rng, got, tt.want)
+		}
+	}
+}
+
+func TestSemVer_Retarget(t *testing.T) {
+	tests := []struct {
+		declared, to, want string
+	}{
+		{"1.2.3", "2.0.0", "2.0.0"},
+		{"8.*", "9.0.1", "9.*"},
+		{"8.1.*", "9.0.1", "9.0.*"},
+		{"*", "9.0.1", "*"},
+		{"1.2.3-*", "1.3.0", "1.3.0-*"},
+		{"1.0.0-beta.*", "2.0.0-beta.1", "2.0.0-beta.*"},
+		{"[1.2.3]", "1.3.0", "[1.3.0]"},
+		{"[1.0,)", "1.5.0", "[1.5.0,)"},
+		{"[1.0,2.0)", "1.5.0", "[1.5.0,2.0)"},
+		{"(1.0,2.0]", "2.0", "[2.0,2.0]"},
+		{"[1.0,2.0)", "2.1.0", "[2.1.0,3.0.0)"}, // upper bound moves past the new version
+		{"(,2.0]", "1.5.0", "[1.5.0,2.0]"},
+	}
+	for _, tt := range tests {
+		if got := ParseSemVer(tt.declared).Retarget(tt.to); got != tt.want {
+			t.Errorf("%q retargeted to %s = %q, want %q", tt.declared, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// solutionFolderTypeGUID is the project type .sln files use for solution
+// folders, which group projects but are not projects themselves.
+const solutionFolderTypeGUID = "2150E333-8FDC-42A3-9474-1A3956D46DE8"
+
+// solutionProject is a project listed in a solution, with the solution
+// folder it sits in ("" at the top level, "src/libs" when nested).
+type solutionProject struct {
+	Path   string // absolute
+	Folder string
+}
+
+func isSolutionFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".sln" || ext == ".slnx"
+}
+
+// projectExtensions are the MSBuild project types loaded without --include.
+var projectExtensions = []string{".csproj", ".fsproj", ".vbproj", ".esproj"}
+
+// projectIncludes are file name globs also loaded as projects, e.g.
+// "*.msbuildproj". Set from --include or the include setting via
+// setProjectIncludes.
+var projectIncludes []string
+
+// setProjectIncludes checks and installs extra project file name globs.
+func setProjectIncludes(patterns []string) error {
+	if err := validateProjectIncludes(patterns); err != nil {
+		return err
+	}
+	projectIncludes = nil
+	for _, p := range patterns {
+		projectIncludes = append(projectIncludes, strings.ToLower(p))
+	}
+	return nil
+}
+
+// validateProjectIncludes rejects malformed globs and ones naming
+// directories: patterns match file names only.
+func validateProjectIncludes(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := filepath.Match(p, ""); err != nil || p == "" {
+			return fmt.Errorf("include pattern %q is not a valid glob", p)
+		}
+		if strings.ContainsAny(p, `/\`) {
+			return fmt.Errorf("include pattern %q names a path; patterns match file names, e.g. *.msbuildproj", p)
+		}
+	}
+	return nil
+}
+
+// projectFileKinds lists what is loaded as a project, for messages.
+func projectFileKinds() string {
+	return strings.Join(append(slices.Clone(projectExtensions), projectIncludes...), ", ")
+}
+
+// splitProjectIncludes splits a comma-separated --include value.
+func splitProjectIncludes(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func isProjectFile(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	if slices.Contains(projectExtensions, filepath.Ext(name)) {
+		return true
+	}
+	for _, p := range projectIncludes {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// splitSolutionArg turns a --project value that names a solution or
+// workspace file into its directory and that file; any other path is
+// returned unchanged.
+func splitSolutionArg(path string) (dir, solution string) {
+	if isSolutionFile(path) || isWorkspaceFile(path) {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return filepath.Dir(path), path
+		}
+	}
+	return path, ""
+}
+
+// findRootSolution returns the only .sln or .slnx directly in dir, or ""
+// when there is none or more than one to choose from.
+func findRootSolution(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var found []string
+	for _, e := range entries {
+		if !e.IsDir() && isSolutionFile(e.Name()) {
+			found = append(found, filepath.Join(dir, e.Name()))
+		}
+	}
+	if len(found) > 1 {
+		logInfo("Found %d solutions in %s; scanning the directory tree instead (pass --project <file.sln> to pick one)", len(found), dir)
+		return ""
+	}
+	if len(found) == 1 {
+		return found[0]
+	}
+	return ""
+}
+
+// parseSolution lists the projects referenced by a .sln or .slnx file.
+func parseSolution(path string) ([]solutionProject, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var projects []solutionProject
+	if strings.EqualFold(filepath.Ext(path), ".slnx") {
+		projects, err = parseSlnx(data)
+	} else {
+		projects, err = parseSln(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	for i := range projects {
+		// Solutions written on Windows use backslashes on every platform.
+		rel := filepath.FromSlash(strings.ReplaceAll(projects[i].Path, `\`, "/"))
+		projects[i].Path = filepath.Join(dir, rel)
+	}
+	return projects, nil
+}
+
+var (
+	slnProjectRe = regexp.MustCompile(`^Project\("\{([^}]+)\}"\)\s*=\s*"([^"]*)"\s*,\s*"([^"]*)"\s*,\s*"\{([^}]+)\}"`)
+	slnNestedRe  = regexp.MustCompile(`^\{([^}]+)\}\s*=\s*\{([^}]+)\}`)
+)
+
+// parseSln reads the classic text format. Solution folders are entries with
+// solutionFolderTypeGUID; the NestedProjects section maps each child GUID to
+// its parent folder.
+func parseSln(data []byte) ([]solutionProject, error) {
+	type entry struct {
+		name, path string
+		folder     bool
+	}
+	entries := map[string]entry{}
+	var order []string
+	parent := map[string]string{}
+
+	inNested := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(line, "GlobalSection(NestedProjects)"):
+			inNested = true
+		case strings.HasPrefix(line, "EndGlobalSection"):
+			inNested = false
+		case inNested:
+			if m := slnNestedRe.FindStringSubmatch(line); m != nil {
+				parent[strings.ToUpper(m[1])] = strings.ToUpper(m[2])
+			}
+		default:
+			if m := slnProjectRe.FindStringSubmatch(line); m != nil {
+				id := strings.ToUpper(m[4])
+				entries[id] = entry{name: m[2], path: m[3], folder: strings.EqualFold(m[1], solutionFolderTypeGUID)}
+				order = append(order, id)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	folderOf := func(id string) string {
+		var parts []string
+		seen := NewSet[string]()
+		for p := parent[id]; p != "" && !seen.Contains(p); p = parent[p] {
+			seen.Add(p)
+			parts = append([]string{entries[p].name}, parts...)
+		}
+		return strings.Join(parts, "/")
+	}
+
+	var projects []solutionProject
+	for _, id := range order {
+		e := entries[id]
+		if e.folder || !isProjectFile(e.path) {
+			continue
+		}
+		projects = append(projects, solutionProject{Path: e.path, Folder: folderOf(id)})
+	}
+	return projects, nil
+}
+
+type slnxFolder struct {
+	Name     string        `xml:"Name,attr"`
+	Projects []slnxProject `xml:"Project"`
+	Folders  []slnxFolder  `xml:"Folder"`
+}
+
+type slnxProject struct {
+	Path string `xml:"Path,attr"`
+}
+
+// parseSlnx reads the XML format, where folders are named by their full
+// path, e.g. <Folder Name="/src/libs/">.
+func parseSlnx(data []byte) ([]solutionProject, error) {
+	var root slnxFolder
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var projects []solutionProject
+	var walk func(f slnxFolder, folder string)
+	walk = func(f slnxFolder, folder string) {
+		for _, p := range f.Projects {
+			if isProjectFile(p.Path) {
+				projects = append(projects, solutionProject{Path: p.Path, Folder: folder})
+			}
+		}
+		for _, sub := range f.Folders {
+			name := strings.Trim(sub.Name, "/")
+			if !strings.HasPrefix(sub.Name, "/") && folder != "" {
+				name = folder + "/" + name
+			}
+			walk(sub, name)
+		}
+	}
+	walk(root, "")
+	return projects, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSln = `
+Microsoft Visual Studio Solution File, Format Version 12.00
+# Visual Studio Version 17
+Project("{2150E333-8FDC-42A3-9474-1A3956D46DE8}") = "src", "src", "{11111111-1111-1111-1111-111111111111}"
+EndProject
+Project("{2150E333-8FDC-42A3-9474-1A3956D46DE8}") = "libs", "libs", "{22222222-2222-2222-2222-222222222222}"
+EndProject
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "App", "src\App\App.csproj", "{33333333-3333-3333-3333-333333333333}"
+EndProject
+Project("{9A19103F-16F7-4668-BE54-9A1E7A4F7556}") = "Core", "src\libs\Core\Core.csproj", "{44444444-4444-4444-4444-444444444444}"
+EndProject
+Project("{F2A71F9B-5D33-465A-A702-920D77279786}") = "Tool", "Tool.fsproj", "{55555555-5555-5555-5555-555555555555}"
+EndProject
+Global
+	GlobalSection(NestedProjects) = preSolution
+		{22222222-2222-2222-2222-222222222222} = {11111111-1111-1111-1111-111111111111}
+		{33333333-3333-3333-3333-333333333333} = {11111111-1111-1111-1111-111111111111}
+		{44444444-4444-4444-4444-444444444444} = {22222222-2222-2222-2222-222222222222}
+	EndGlobalSection
+EndGlobal
+`
+
+const testSlnx = `<Solution>
+  <Folder Name="/src/">
+    <Project Path="src/App/App.csproj" />
+  </Folder>
+  <Folder Name="/src/libs/">
+    <Project Path="src/libs/Core/Core.csproj" />
+  </Folder>
+  <Project Path="Tool.fsproj" />
+</Solution>
+`
+
+func writeSolutionTree(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte("<Project Sdk=\"Microsoft.NET.Sdk\" />"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestParseSolution_Formats(t *testing.T) {
+	for name, content := range map[string]string{"App.sln": testSln, "App.slnx": testSlnx} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, name)
+			os.WriteFile(path, []byte(content), 0644)
+
+			got, err := parseSolution(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []solutionProject{
+				{filepath.Join(dir, "src", "App", "App.csproj"), "src"},
+				{filepath.Join(dir, "src", "libs", "Core", "Core.csproj"), "src/libs"},
+				{filepath.Join(dir, "Tool.fsproj"), ""},
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d projects, want %d: %+v", len(got), len(want), got)
+			}
+			for _, w := range want {
+				found := false
+				for _, g := range got {
+					if g == w {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("missing %+v in %+v", w, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDiscoverProjects_PrefersRootSolution(t *testing.T) {
+	dir := writeSolutionTree(t, "src/App/App.csproj", "Tool.fsproj", "samples/Demo/Demo.csproj")
+	os.WriteFile(filepath.Join(dir, "App.slnx"), []byte(testSlnx), 0644)
+
+	d, err := DiscoverProjects(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Solution != filepath.Join(dir, "App.slnx") {
+		t.Errorf("Solution = %q, want the root .slnx", d.Solution)
+	}
+	// Core.csproj is listed but missing; Demo.csproj exists but isn't listed.
+	if len(d.Files) != 2 {
+		t.Fatalf("Files = %v, want App and Tool only", d.Files)
+	}
+	if got := d.Folders[filepath.Join(dir, "src", "App", "App.csproj")]; got != "src" {
+		t.Errorf("App folder = %q, want src", got)
+	}
+}
+
+func TestDiscoverProjects_WalksWithSeveralSolutions(t *testing.T) {
+	dir := writeSolutionTree(t, "src/App/App.csproj", "Tool.fsproj", "samples/Demo/Demo.csproj")
+	os.WriteFile(filepath.Join(dir, "A.sln"), []byte(testSln), 0644)
+	os.WriteFile(filepath.Join(dir, "B.slnx"), []byte(testSlnx), 0644)
+
+	d, err := DiscoverProjects(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Solution != "" || len(d.Files) != 3 {
+		t.Errorf("got solution %q and %d files, want a full walk of 3", d.Solution, len(d.Files))
+	}
+
+	d, err = DiscoverProjects(dir, filepath.Join(dir, "A.sln"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Files) != 2 {
+		t.Errorf("explicit solution: Files = %v, want 2", d.Files)
+	}
+}
+
+func TestIsProjectFile_Includes(t *testing.T) {
+	t.Cleanup(func() { setProjectIncludes(nil) })
+	for _, name := range []string{"App.csproj", "Lib.FSPROJ", "Old.vbproj", "web/Client.esproj"} {
+		if !isProjectFile(name) {
+			t.Errorf("isProjectFile(%q) = false", name)
+		}
+	}
+	if isProjectFile("Db.msbuildproj") {
+		t.Error("Db.msbuildproj loaded without --include")
+	}
+
+	if err := setProjectIncludes(splitProjectIncludes(" *.msbuildproj, Build*.proj ,")); err != nil {
+		t.Fatal(err)
+	}
+	dir := writeSolutionTree(t, "App/App.csproj", "Db/Db.MSBuildProj", "Build.Tasks.proj", "notes.proj")
+	files, err := walkProjectFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f))
+	}
+	if strings.Join(names, " ") != "App.csproj Build.Tasks.proj Db.MSBuildProj" {
+		t.Errorf("walked %v", names)
+	}
+
+	for _, bad := range []string{"[x", "src/*.proj", ""} {
+		if err := setProjectIncludes([]string{bad}); err == nil {
+			t.Errorf("setProjectIncludes(%q) accepted", bad)
+		}
+	}
+}
+
+func TestSplitSolutionArg(t *testing.T) {
+	dir := t.TempDir()
+	sln := filepath.Join(dir, "App.sln")
+	os.WriteFile(sln, []byte(testSln), 0644)
+
+	if gotDir, gotSln := splitSolutionArg(sln); gotDir != dir || gotSln != sln {
+		t.Errorf("splitSolutionArg(%q) = %q, %q", sln, gotDir, gotSln)
+	}
+	if gotDir, gotSln := splitSolutionArg(dir); gotDir != dir || gotSln != "" {
+		t.Errorf("splitSolutionArg(%q) = %q, %q", dir, gotDir, gotSln)
+	}
+}
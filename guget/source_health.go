@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// sourceStats is how a source has been answering so far this session.
+type sourceStats struct {
+	Requests      int           // HTTP exchanges that reached the network
+	Latency       time.Duration // of the last exchange
+	Status        int           // HTTP status of the last exchange; 0 when it failed
+	Err           error         // why the last exchange failed, if it did
+	Lookups       int           // package lookups
+	FailedLookups int           // lookups that failed for any reason but not-found
+	LookupErr     error         // the last lookup failure
+}
+
+// sourceHealth collects a service's sourceStats. Responses served from the
+// on-disk cache are not counted: only the feed's own answers say anything
+// about its health.
+type sourceHealth struct {
+	mu    sync.Mutex
+	stats sourceStats
+}
+
+func (h *sourceHealth) snapshot() sourceStats {
+	if h == nil {
+		return sourceStats{}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.stats
+}
+
+func (h *sourceHealth) exchange(latency time.Duration, status int, err error) {
+	h.mu.Lock()
+	h.stats.Requests++
+	h.stats.Latency, h.stats.Status, h.stats.Err = latency, status, err
+	h.mu.Unlock()
+}
+
+// lookup records the outcome of a package lookup. A package the source does
+// not have is a normal answer, not a failure.
+func (h *sourceHealth) lookup(err error) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	h.stats.Lookups++
+	if err != nil && classifyLoadError(err) != failureNotFound {
+		h.stats.FailedLookups++
+		h.stats.LookupErr = err
+	}
+	h.mu.Unlock()
+}
+
+// healthTransport times every request it sends and records the outcome.
+type healthTransport struct {
+	base   http.RoundTripper
+	health *sourceHealth
+}
+
+func (t *healthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+	}
+	t.health.exchange(time.Since(start), status, err)
+	return resp, err
+}
+
+// reachability sums up the last exchange, and whether it went well.
+func (st sourceStats) reachability() (label string, ok bool) {
+	switch {
+	case st.Requests == 0:
+		return "not contacted yet", true
+	case st.Err != nil:
+		return "unreachable: " + st.Err.Error(), false
+	case st.Status == http.StatusUnauthorized || st.Status == http.StatusForbidden:
+		return fmt.Sprintf("access denied (%d)", st.Status), false
+	case st.Status >= 500:
+		return fmt.Sprintf("failing (%d)", st.Status), false
+	}
+	return fmt.Sprintf("reachable · %s", st.Latency.Round(time.Millisecond)), true
+}
+
+// authState describes the credentials a source is using and whether the
+// feed accepted them.
+func (st sourceStats) authState(origin string) (label string, ok bool) {
+	rejected := st.Status == http.StatusUnauthorized || st.Status == http.StatusForbidden
+	switch {
+	case rejected && origin == "":
+		return "needs credentials", false
+	case rejected:
+		return "credentials from " + origin + " rejected", false
+	case origin == "":
+		return "anonymous", true
+	}
+	return "credentials from " + origin, true
+}
+
+// Protocol names the API the service resolved for its source.
+func (s *NugetService) Protocol() string {
+	switch {
+	case s.v2Base != "":
+		return "v2 (OData)"
+	case s.adoSearchBase != "":
+		return "v3 + Azure DevOps search"
+	}
+	return "v3"
+}
+
+// Stats returns how the source has been answering.
+func (s *NugetService) Stats() sourceStats { return s.health.snapshot() }
+
+// CredentialOrigin says where the credentials being sent came from: "" when
+// there are none.
+func (s *NugetService) CredentialOrigin() string {
+	if s.auth == nil {
+		return ""
+	}
+	return s.auth.origin()
+}
+
+// sourceFailure is a source whose service could not be created at load.
+type sourceFailure struct {
+	Source NugetSource
+	Err    error
+}
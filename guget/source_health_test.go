@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSourceHealth_Lookups(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/index.json":
+			fmt.Fprintf(w, `{"resources":[{"@id":%q,"@type":"RegistrationsBaseUrl/3.6.0"}]}`, srv.URL+"/reg/")
+		case strings.HasPrefix(r.URL.Path, "/reg/private"):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := NewNugetService(NugetSource{Name: "feed", URL: srv.URL + "/index.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := svc.Protocol(); got != "v3" {
+		t.Errorf("Protocol = %q, want v3", got)
+	}
+	st := svc.Stats()
+	if label, ok := st.reachability(); st.Requests != 1 || !ok || !strings.HasPrefix(label, "reachable") {
+		t.Errorf("after connecting: %+v, %q", st, label)
+	}
+
+	svc.SearchExact("Missing.Package")
+	if st = svc.Stats(); st.Lookups != 1 || st.FailedLookups != 0 {
+		t.Errorf("a package the source lacks counted as failed: %+v", st)
+	}
+
+	svc.SearchExact("Private.Package")
+	st = svc.Stats()
+	if st.Lookups != 2 || st.FailedLookups != 1 || classifyLoadError(st.LookupErr) != failureAuth {
+		t.Errorf("after a 403: %+v", st)
+	}
+	if label, ok := st.reachability(); ok || label != "access denied (403)" {
+		t.Errorf("reachability = %q, %v", label, ok)
+	}
+	if label, ok := st.authState(svc.CredentialOrigin()); ok || label != "needs credentials" {
+		t.Errorf("authState = %q, %v", label, ok)
+	}
+}
+
+func TestSourceStats_AuthState(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		origin string
+		want   string
+		ok     bool
+	}{
+		{200, "", "anonymous", true},
+		{200, "nuget.config", "credentials from nuget.config", true},
+		{401, "credential provider", "credentials from credential provider rejected", false},
+		{403, "", "needs credentials", false},
+	} {
+		st := sourceStats{Requests: 1, Status: tc.status}
+		if got, ok := st.authState(tc.origin); got != tc.want || ok != tc.ok {
+			t.Errorf("authState(%d, %q) = %q, %v; want %q, %v", tc.status, tc.origin, got, ok, tc.want, tc.ok)
+		}
+	}
+	unreachable := sourceStats{Requests: 1, Err: errors.New("dial tcp: connection refused")}
+	if label, ok := unreachable.reachability(); ok || label != "unreachable: dial tcp: connection refused" {
+		t.Errorf("reachability = %q, %v", label, ok)
+	}
+}
+
+func TestSourceScopesConnect_KeepsFailures(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	scopes := sourceScopes{{Sources: []NugetSource{{Name: "gone", URL: srv.URL + "/index.json"}}}}
+
+	services, failed := scopes.connect()
+	if len(services) != 0 || len(failed) != 1 || failed[0].Source.Name != "gone" || failed[0].Err == nil {
+		t.Errorf("connect = %v, %+v; want the source listed as failed", services, failed)
+	}
+}
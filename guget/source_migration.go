@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// sourceMigration is a source whose URL should be replaced in the
+// nuget.config that defines it.
+type sourceMigration struct {
+	Name       string
+	ConfigPath string
+	From       string
+	To         string
+	Reason     string
+}
+
+// suggestSourceURL returns the modern URL for a deprecated feed endpoint,
+// with a short reason, or "" when the URL is already current.
+func suggestSourceURL(raw string) (string, string) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", ""
+	}
+	host := strings.ToLower(u.Hostname())
+	path := strings.ToLower(strings.TrimRight(u.Path, "/"))
+
+	switch {
+	case host == "api.nuget.org" && u.Scheme == "http":
+		u.Scheme = "https"
+		return u.String(), "nuget.org only serves HTTPS"
+
+	case (host == "nuget.org" || host == "www.nuget.org" || host == "packages.nuget.org") && strings.HasPrefix(path, "/api/v2"):
+		return defaultNugetSource, "the nuget.org v2 API is deprecated"
+
+	case host == "myget.org" || host == "www.myget.org":
+		// /F/{feed}/api/v2 → /F/{feed}/api/v3/index.json
+		segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(segs) >= 4 && strings.EqualFold(segs[0], "F") && strings.EqualFold(segs[2], "api") && strings.EqualFold(segs[3], "v2") {
+			return "https://www.myget.org/F/" + segs[1] + "/api/v3/index.json", "MyGet v2 feeds have a v3 endpoint"
+		}
+
+	default:
+		ado := parseADOFeedURL(raw)
+		if ado == nil {
+			break
+		}
+		modern := "https://pkgs.dev.azure.com/" + ado.Org
+		if ado.Project != "" {
+			modern += "/" + ado.Project
+		}
+		modern += "/_packaging/" + ado.Feed + "/nuget/v3/index.json"
+		switch {
+		case strings.HasSuffix(host, ".pkgs.visualstudio.com"):
+			return modern, "visualstudio.com feed URLs are superseded by pkgs.dev.azure.com"
+		case strings.HasSuffix(path, "/nuget/v2"):
+			return modern, "Azure Artifacts v2 endpoints are deprecated"
+		}
+	}
+	return "", ""
+}
+
+// planSourceMigrations lists the sources whose URL is deprecated or that
+// answered with a permanent redirect. Sources not defined in a nuget.config
+// (e.g. the nuget.org fallback) can't be rewritten and are skipped.
+func planSourceMigrations(sources []NugetSource, services []*NugetService) []sourceMigration {
+	moved := make(map[string]string, len(services))
+	for _, svc := range services {
+		if to := svc.MovedTo(); to != "" {
+			moved[strings.ToLower(svc.SourceName())] = to
+		}
+	}
+
+	var out []sourceMigration
+	for _, src := range sources {
+		if src.ConfigPath == "" {
+			continue
+		}
+		m := sourceMigration{Name: src.Name, ConfigPath: src.ConfigPath, From: src.URL}
+		if to := moved[strings.ToLower(src.Name)]; to != "" {
+			m.To, m.Reason = to, "the feed redirects here permanently"
+		} else {
+			m.To, m.Reason = suggestSourceURL(src.URL)
+		}
+		if m.To != "" && m.To != m.From {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// migrateSourceURL rewrites the value of the <add key="name"> entry whose URL
+// is from, leaving the rest of the file byte-for-byte as it was. Commented-out
+// entries are left alone, and values built from environment variables are
+// refused rather than flattened.
+func migrateSourceURL(configPath, name, from, to string) error {
+	file, err := readTextFile(configPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", configPath, err)
+	}
+	elems, err := scanXML(file.Text)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", configPath, err)
+	}
+	var edits []textEdit
+	for _, e := range elems {
+		key, value := e.attr("key"), e.attr("value")
+		if !strings.EqualFold(e.Name, "add") || key == nil || value == nil || !strings.EqualFold(html.UnescapeString(key.Value), name) {
+			continue
+		}
+		v := html.UnescapeString(value.Value)
+		if expandConfigValue(v) != from {
+			continue
+		}
+		if v != from {
+			return fmt.Errorf("source %q in %s is built from environment variables; update it by hand", name, configPath)
+		}
+		var esc bytes.Buffer
+		xml.EscapeText(&esc, []byte(to))
+		// Include the quotes, so a single-quoted value is written with the
+		// double quotes the escaping assumes.
+		edits = append(edits, textEdit{Start: value.ValueStart - 1, End: value.ValueEnd + 1, Text: `"` + esc.String() + `"`})
+	}
+	if len(edits) == 0 {
+		return fmt.Errorf("source %q with URL %s not found in %s", name, from, configPath)
+	}
+	return writeTextFile(configPath, file, applyEdits(file.Text, edits))
+}
+
+// applySourceMigrations rewrites every migration as one change: if any
+// fails, the nuget.config files already rewritten are put back, so the
+// migration can be undone, and is logged, as a whole.
+func applySourceMigrations(migrations []sourceMigration) error {
+	rec := ActionRecord{Action: "migrate"}
+	originals := make(map[string][]byte)
+	var names []string
+	for _, mg := range migrations {
+		names = append(names, mg.Name)
+		if _, ok := originals[mg.ConfigPath]; ok {
+			continue
+		}
+		data, err := os.ReadFile(mg.ConfigPath)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", mg.ConfigPath, err)
+		}
+		originals[mg.ConfigPath] = data
+		rec.Files = append(rec.Files, mg.ConfigPath)
+	}
+	rec.Source = strings.Join(names, ", ")
+
+	for _, mg := range migrations {
+		if err := migrateSourceURL(mg.ConfigPath, mg.Name, mg.From, mg.To); err != nil {
+			for _, f := range rec.Files {
+				if rerr := writeFileRetry(f, originals[f], 0644); rerr != nil {
+					logError("migrate: could not roll back %s: %v", f, rerr)
+				}
+			}
+			sessionJournal.discard(rec.Files)
+			actionLog.record(rec, err)
+			return err
+		}
+		logInfo("Source [%s]: %s → %s in %s", mg.Name, mg.From, mg.To, mg.ConfigPath)
+	}
+	recordAction(rec, nil)
+	return nil
+}
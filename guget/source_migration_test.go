@@ -0,0 +1,174 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSuggestSourceURL(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"https://www.nuget.org/api/v2/", defaultNugetSource},
+		{"http://api.nuget.org/v3/index.json", "https://api.nuget.org/v3/index.json"},
+		{"https://www.myget.org/F/acme/api/v2", "https://www.myget.org/F/acme/api/v3/index.json"},
+		{"https://acme.pkgs.visualstudio.com/_packaging/core/nuget/v3/index.json", "https://pkgs.dev.azure.com/acme/_packaging/core/nuget/v3/index.json"},
+		{"https://pkgs.dev.azure.com/acme/web/_packaging/core/nuget/v2", "https://pkgs.dev.azure.com/acme/web/_packaging/core/nuget/v3/index.json"},
+		{"https://pkgs.dev.azure.com/acme/_packaging/core/nuget/v3/index.json", ""},
+		{defaultNugetSource, ""},
+		{"https://nuget.pkg.github.com/acme/index.json", ""},
+	}
+	for _, tt := range tests {
+		got, reason := suggestSourceURL(tt.in)
+		if got != tt.want {
+			t.Errorf("suggestSourceURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if (got == "") != (reason == "") {
+			t.Errorf("suggestSourceURL(%q): reason %q should accompany a suggestion", tt.in, reason)
+		}
+	}
+}
+
+const migrationConfig = `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <!-- keep this comment -->
+    <add key="nuget.org" value="https://www.nuget.org/api/v2/" />
+    <add key="corp" value='https://feed.example.com/v3/index.json?a=1&amp;b=2' protocolVersion="3" />
+  </packageSources>
+  <disabledPackageSources>
+    <add key="nuget.org" value="true" />
+  </disabledPackageSources>
+</configuration>
+`
+
+func TestMigrateSourceURL_RewritesOnlyThatValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nuget.config")
+	os.WriteFile(path, []byte(migrationConfig), 0644)
+
+	if err := migrateSourceURL(path, "nuget.org", "https://www.nuget.org/api/v2/", defaultNugetSource); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateSourceURL(path, "corp", "https://feed.example.com/v3/index.json?a=1&b=2", "https://new.example.com/v3/index.json?a=1&b=2"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	want := strings.NewReplacer(
+		`"https://www.nuget.org/api/v2/"`, `"`+defaultNugetSource+`"`,
+		`'https://feed.example.com/v3/index.json?a=1&amp;b=2'`, `"https://new.example.com/v3/index.json?a=1&amp;b=2"`,
+	).Replace(migrationConfig)
+	if string(data) != want {
+		t.Errorf("unexpected rewrite:\n%s", data)
+	}
+
+	if err := migrateSourceURL(path, "missing", "https://x", "https://y"); err == nil {
+		t.Error("expected an error for a source that isn't in the file")
+	}
+}
+
+func TestMigrateSourceURL_RefusesEnvironmentValues(t *testing.T) {
+	t.Setenv("GUGET_TEST_HOST", "www.nuget.org")
+	path := filepath.Join(t.TempDir(), "nuget.config")
+	os.WriteFile(path, []byte(`<configuration><packageSources><add key="nuget.org" value="https://%GUGET_TEST_HOST%/api/v2" /></packageSources></configuration>`), 0644)
+
+	if err := migrateSourceURL(path, "nuget.org", "https://www.nuget.org/api/v2", defaultNugetSource); err == nil {
+		t.Error("expected a templated value to be refused")
+	}
+}
+
+func TestMigrateSourceURL_SkipsCommentedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nuget.config")
+	config := `<configuration>
+  <packageSources>
+    <!-- <add key="corp" value="https://feed.example.com/nuget/v2" /> -->
+    <add key="corp" value="https://feed.example.com/nuget/v2" />
+  </packageSources>
+</configuration>`
+	os.WriteFile(path, []byte(config), 0644)
+
+	if err := migrateSourceURL(path, "corp", "https://feed.example.com/nuget/v2", "https://feed.example.com/v3/index.json"); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(config, `<add key="corp" value="https://feed.example.com/nuget/v2" />`+"\n  </packageSources>",
+		`<add key="corp" value="https://feed.example.com/v3/index.json" />`+"\n  </packageSources>", 1)
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("unexpected rewrite:\n%s", data)
+	}
+}
+
+func TestApplySourceMigrations_AllOrNothing(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "nuget.config")
+	nested := filepath.Join(dir, "src", "nuget.config")
+	rootConfig := `<configuration><packageSources><add key="nuget.org" value="https://www.nuget.org/api/v2/" /></packageSources></configuration>`
+	nestedConfig := `<configuration><packageSources><add key="corp" value="https://acme.pkgs.visualstudio.com/_packaging/core/nuget/v3/index.json" /></packageSources></configuration>`
+	mustWriteFile(t, root, rootConfig)
+	mustWriteFile(t, nested, nestedConfig)
+
+	migrations := []sourceMigration{
+		{Name: "nuget.org", ConfigPath: root, From: "https://www.nuget.org/api/v2/", To: defaultNugetSource},
+		{Name: "corp", ConfigPath: nested, From: "https://elsewhere.example.com/v2", To: "https://example.com/v3/index.json"},
+	}
+	if err := applySourceMigrations(migrations); err == nil {
+		t.Fatal("expected the second migration to fail")
+	}
+	if data, _ := os.ReadFile(root); string(data) != rootConfig {
+		t.Errorf("the first config should be rolled back:\n%s", data)
+	}
+
+	migrations[1].From = "https://acme.pkgs.visualstudio.com/_packaging/core/nuget/v3/index.json"
+	if err := applySourceMigrations(migrations); err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := sessionJournal.lastOpen()
+	if !ok || entry.Action != "migrate" || len(entry.Files) != 2 || entry.summary() != "migrate nuget.org, corp" {
+		t.Fatalf("journal entry = %+v, %v", entry, ok)
+	}
+	if _, err := sessionJournal.revert(entry.ID); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{root: rootConfig, nested: nestedConfig} {
+		if data, _ := os.ReadFile(path); string(data) != want {
+			t.Errorf("undo should restore %s:\n%s", path, data)
+		}
+	}
+}
+
+func TestNugetService_NotesPermanentRedirect(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old/index.json":
+			http.Redirect(w, r, "/new/index.json", http.StatusMovedPermanently)
+		case "/temp/index.json":
+			http.Redirect(w, r, "/new/index.json", http.StatusFound)
+		case "/new/index.json":
+			w.Write([]byte(`{"resources":[{"@type":"RegistrationsBaseUrl/3.6.0","@id":"` + srv.URL + `/reg/"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := NewNugetService(NugetSource{Name: "corp", URL: srv.URL + "/old/index.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.MovedTo() != srv.URL+"/new/index.json" {
+		t.Errorf("MovedTo = %q, want the permanent redirect target", svc.MovedTo())
+	}
+	plan := planSourceMigrations([]NugetSource{{Name: "corp", URL: srv.URL + "/old/index.json", ConfigPath: "nuget.config"}}, []*NugetService{svc})
+	if len(plan) != 1 || plan[0].To != srv.URL+"/new/index.json" {
+		t.Errorf("expected a migration to the redirect target, got %+v", plan)
+	}
+
+	svc, err = NewNugetService(NugetSource{Name: "corp", URL: srv.URL + "/temp/index.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.MovedTo() != "" {
+		t.Errorf("MovedTo = %q, want temporary redirects ignored", svc.MovedTo())
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// sourceScope is one nuget.config chain and the projects that resolve
+// packages through it. Projects see different chains when nuget.config files
+// are nested below the workspace root, just as they do for dotnet restore.
+type sourceScope struct {
+	Configs  []string // nuget.config files read, closest first
+	Sources  []NugetSource
+	Mapping  *PackageSourceMapping
+	Services []*NugetService
+	Projects Set[string] // project file paths
+
+	conflicts []SourceConflict
+	packages  Set[string] // lowercase names of packages the projects reference
+}
+
+// sourceScopes holds the workspace root's scope first, then one per distinct
+// chain found under it.
+type sourceScopes []sourceScope
+
+func newSourceScope(d DetectedConfig) sourceScope {
+	return sourceScope{
+		Configs:   d.Configs,
+		Sources:   d.Sources,
+		Mapping:   d.Mapping,
+		Projects:  NewSet[string](),
+		conflicts: d.Conflicts,
+		packages:  NewSet[string](),
+	}
+}
+
+// buildSourceScopes groups projects by the sources their directory resolves.
+// Projects whose chain matches root's share the root scope.
+func buildSourceScopes(root DetectedConfig, projects []*ParsedProject, detect func(dir string) DetectedConfig) sourceScopes {
+	scopes := sourceScopes{newSourceScope(root)}
+	bySignature := map[string]int{workspaceSourceSignature(root.Sources, root.Mapping): 0}
+	byDir := map[string]int{}
+	for _, p := range projects {
+		dir := filepath.Dir(p.FilePath)
+		i, ok := byDir[dir]
+		if !ok {
+			d := detect(dir)
+			sig := workspaceSourceSignature(d.Sources, d.Mapping)
+			if i, ok = bySignature[sig]; !ok {
+				i = len(scopes)
+				scopes = append(scopes, newSourceScope(d))
+				bySignature[sig] = i
+			}
+			byDir[dir] = i
+		}
+		scopes[i].Projects.Add(p.FilePath)
+		for ref := range p.Packages {
+			scopes[i].packages.Add(strings.ToLower(ref.Name))
+		}
+	}
+	return scopes
+}
+
+// connect creates one service per distinct source across all scopes, so
+// scopes sharing a feed share its client, and returns them in order along
+// with the sources that could not be reached.
+func (s sourceScopes) connect() ([]*NugetService, []sourceFailure) {
+	byKey := map[string]*NugetService{}
+	var all []*NugetService
+	var failed []sourceFailure
+	for i := range s {
+		for _, src := range s[i].Sources {
+			key := strings.ToLower(src.Name) + "=" + strings.TrimRight(strings.ToLower(src.URL), "/")
+			svc, seen := byKey[key]
+			if !seen {
+				var err error
+				svc, err = NewNugetService(src)
+				if err != nil {
+					logWarn("Failed to initialise NuGet source [%s]: %v", src.Name, err)
+					failed = append(failed, sourceFailure{Source: src, Err: err})
+				} else {
+					all = append(all, svc)
+				}
+				byKey[key] = svc
+			}
+			if svc != nil {
+				s[i].Services = append(s[i].Services, svc)
+			}
+		}
+	}
+	return all, failed
+}
+
+// sources lists every distinct source across the scopes, root's first.
+func (s sourceScopes) sources() []NugetSource {
+	seen := NewSet[string]()
+	var out []NugetSource
+	for _, sc := range s {
+		for _, src := range sc.Sources {
+			key := strings.ToLower(src.Name) + "=" + strings.TrimRight(strings.ToLower(src.URL), "/")
+			if !seen.Contains(key) {
+				seen.Add(key)
+				out = append(out, src)
+			}
+		}
+	}
+	return out
+}
+
+// conflicts lists each scope's source conflicts once.
+func (s sourceScopes) conflicts() []SourceConflict {
+	seen := NewSet[SourceConflict]()
+	var out []SourceConflict
+	for _, sc := range s {
+		for _, c := range sc.conflicts {
+			if !seen.Contains(c) {
+				seen.Add(c)
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// signature changes whenever any scope's sources or mapping change.
+func (s sourceScopes) signature() string {
+	var b strings.Builder
+	for _, sc := range s {
+		b.WriteString(workspaceSourceSignature(sc.Sources, sc.Mapping))
+		b.WriteString("--\n")
+	}
+	return b.String()
+}
+
+// servicesFor lists the services to query for a package: those of every
+// scope with a project referencing it, each filtered by that scope's
+// mapping. Packages no project references (search, new projects) use the
+// root scope.
+func (s sourceScopes) servicesFor(name string) []*NugetService {
+	if len(s) == 0 {
+		return nil
+	}
+	lower := strings.ToLower(name)
+	seen := NewSet[*NugetService]()
+	var out []*NugetService
+	matched := false
+	for _, sc := range s {
+		if len(s) > 1 && !sc.packages.Contains(lower) {
+			continue
+		}
+		matched = true
+		for _, svc := range FilterServices(sc.Services, sc.Mapping, name) {
+			if !seen.Contains(svc) {
+				seen.Add(svc)
+				out = append(out, svc)
+			}
+		}
+	}
+	if !matched {
+		return FilterServices(s[0].Services, s[0].Mapping, name)
+	}
+	return out
+}
+
+// services lists every service across the scopes.
+func (s sourceScopes) services() []*NugetService {
+	seen := NewSet[*NugetService]()
+	var out []*NugetService
+	for _, sc := range s {
+		for _, svc := range sc.Services {
+			if !seen.Contains(svc) {
+				seen.Add(svc)
+				out = append(out, svc)
+			}
+		}
+	}
+	return out
+}
+
+// hasSource reports whether src is one of the scope's sources.
+func (sc *sourceScope) hasSource(src NugetSource) bool {
+	for _, s := range sc.Sources {
+		if sameSource(s, src) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameSource reports whether a and b are the same source definition.
+func sameSource(a, b NugetSource) bool {
+	return strings.EqualFold(a.Name, b.Name) && strings.TrimRight(a.URL, "/") == strings.TrimRight(b.URL, "/")
+}
+
+// scopeFor returns the scope a project resolves its sources through, or nil
+// when the project is unknown (e.g. a .props file).
+func (s sourceScopes) scopeFor(projectPath string) *sourceScope {
+	for i := range s {
+		if s[i].Projects.Contains(projectPath) {
+			return &s[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildSourceScopes_GroupsProjectsByConfigChain(t *testing.T) {
+	corp := NugetSource{Name: "corp", URL: "https://pkgs.example.com/v3/index.json"}
+	public := NugetSource{Name: "nuget.org", URL: defaultNugetSource}
+	root := DetectedConfig{Sources: []NugetSource{public}}
+	nested := DetectedConfig{Sources: []NugetSource{corp, public}, Configs: []string{"/src/Internal/nuget.config"}}
+
+	detect := func(dir string) DetectedConfig {
+		if filepath.Base(dir) == "Internal" {
+			return nested
+		}
+		return root
+	}
+	app := headlessTestProject("App.csproj", filepath.FromSlash("/src/App/App.csproj"), PackageReference{Name: "Serilog"})
+	lib := headlessTestProject("Lib.csproj", filepath.FromSlash("/src/Lib/Lib.csproj"), PackageReference{Name: "Serilog"})
+	internal := headlessTestProject("Internal.csproj", filepath.FromSlash("/src/Internal/Internal.csproj"), PackageReference{Name: "Corp.Logging"})
+
+	scopes := buildSourceScopes(root, []*ParsedProject{app, lib, internal}, detect)
+	if len(scopes) != 2 {
+		t.Fatalf("expected root and nested scopes, got %d", len(scopes))
+	}
+	if got := scopes.scopeFor(app.FilePath); got != &scopes[0] {
+		t.Errorf("App should use the root scope")
+	}
+	if got := scopes.scopeFor(internal.FilePath); got != &scopes[1] || len(got.Configs) != 1 {
+		t.Errorf("Internal should use the nested scope, got %+v", got)
+	}
+	if got := scopes.sources(); len(got) != 2 || got[0] != public || got[1] != corp {
+		t.Errorf("sources() = %+v, want nuget.org then corp", got)
+	}
+	if !scopes[1].hasSource(corp) || scopes[0].hasSource(corp) {
+		t.Error("only the nested scope should have the corp source")
+	}
+}
+
+func TestSourceScopes_ServicesFor(t *testing.T) {
+	publicSvc := &NugetService{sourceName: "nuget.org"}
+	corpSvc := &NugetService{sourceName: "corp"}
+	scopes := sourceScopes{
+		{Services: []*NugetService{publicSvc}, packages: NewSet[string]()},
+		{Services: []*NugetService{corpSvc, publicSvc}, packages: NewSet[string]()},
+	}
+	scopes[0].packages.Add("serilog")
+	scopes[1].packages.Add("corp.logging")
+	scopes[1].packages.Add("serilog")
+
+	if got := scopes.servicesFor("Corp.Logging"); len(got) != 2 || got[0] != corpSvc {
+		t.Errorf("Corp.Logging should use the nested scope's services, got %v", serviceNames(got))
+	}
+	if got := scopes.servicesFor("Serilog"); len(got) != 2 {
+		t.Errorf("Serilog is used in both scopes; want both services once, got %v", serviceNames(got))
+	}
+	if got := scopes.servicesFor("Unreferenced"); len(got) != 1 || got[0] != publicSvc {
+		t.Errorf("unreferenced packages should use the root scope, got %v", serviceNames(got))
+	}
+}
+
+func TestDetectSources_ListsConfigsRead(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "src", "Internal")
+	os.MkdirAll(nested, 0755)
+	cfg := `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <clear />
+    <add key="corp" value="https://pkgs.example.com/v3/index.json" />
+  </packageSources>
+</configuration>`
+	os.WriteFile(filepath.Join(nested, "nuget.config"), []byte(cfg), 0644)
+
+	d := DetectSources(nested)
+	if len(d.Configs) != 1 || d.Configs[0] != filepath.Join(nested, "nuget.config") {
+		t.Errorf("Configs = %v, want only the nested nuget.config", d.Configs)
+	}
+}
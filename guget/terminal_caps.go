@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/colorprofile"
+)
+
+// TerminalCaps describes what the attached terminal can render. Rendering
+// code asks for a capability rather than checking for a specific terminal,
+// so quirks of e.g. the legacy Windows console are handled in one place.
+type TerminalCaps struct {
+	Name       string               // best guess at the terminal, for logs
+	Hyperlinks bool                 // OSC 8 hyperlinks
+	Unicode    bool                 // box-drawing and symbol glyphs render at their expected width
+	Colors     colorprofile.Profile // forced colour profile; Unknown lets bubbletea detect it
+	AltScreen  bool                 // alternate screen buffer is usable
+	Mouse      bool                 // click and wheel reporting is usable
+}
+
+// termCaps holds the capabilities detected at startup.
+var termCaps = TerminalCaps{Name: "default", Hyperlinks: true, Unicode: true, AltScreen: true, Mouse: true}
+
+// glyphSet holds the symbols whose rendering depends on Unicode support.
+type glyphSet struct {
+	Cursor    string // list selection marker, two cells wide
+	Link      string // suffix marking a clickable hyperlink
+	OK, Fail  string // pass and fail marks
+	Dot       string // healthy status marker
+	Bullet    string // markdown list item
+	TaskDone  string // markdown task list item, checked
+	TaskOpen  string // markdown task list item, unchecked
+	CodeBar   string // left edge of a code block, two cells wide
+	QuoteBar  string // left edge of a block quote, two cells wide
+	RuleHeavy string // one cell of a level-1 heading underline
+	Rule      string // one cell of other underlines, rules and table header separators
+	ColumnSep string // between table columns
+	Cross     string // where ColumnSep meets Rule
+}
+
+var (
+	unicodeGlyphs = glyphSet{
+		Cursor: "▶ ", Link: " ↗", OK: "✓", Fail: "✗", Dot: "●",
+		Bullet: "•", TaskDone: "☑", TaskOpen: "☐", CodeBar: "│ ", QuoteBar: "▌ ",
+		RuleHeavy: "═", Rule: "─", ColumnSep: "│", Cross: "┼",
+	}
+	asciiGlyphs = glyphSet{
+		Cursor: "> ", Link: "", OK: "+", Fail: "x", Dot: "o",
+		Bullet: "*", TaskDone: "[x]", TaskOpen: "[ ]", CodeBar: "| ", QuoteBar: "> ",
+		RuleHeavy: "=", Rule: "-", ColumnSep: "|", Cross: "+",
+	}
+	glyphs = unicodeGlyphs
+)
+
+// detectTerminalCaps inspects the environment to work out what the terminal
+// supports. vtSupported reports whether a Windows console accepts VT escape
+// sequences; it is only consulted for the legacy console.
+//
+// GUGET_HYPERLINKS, GUGET_UNICODE, GUGET_ALTSCREEN and GUGET_MOUSE
+// ("0"/"1") override the detected value for each capability.
+func detectTerminalCaps(getenv func(string) string, goos string, vtSupported func() bool) TerminalCaps {
+	caps := TerminalCaps{Name: "default", Hyperlinks: true, Unicode: true, AltScreen: true, Mouse: true}
+	term := strings.ToLower(getenv("TERM"))
+	program := getenv("TERM_PROGRAM")
+
+	switch {
+	case getenv("WT_SESSION") != "":
+		caps.Name = "Windows Terminal"
+	case program != "":
+		caps.Name = program
+		// Terminal.app prints OSC 8 sequences as garbage.
+		caps.Hyperlinks = program != "Apple_Terminal"
+	case term == "dumb":
+		caps = TerminalCaps{Name: "dumb", Colors: colorprofile.ASCII}
+	case term == "linux":
+		// Linux virtual console: 16 colours, limited font.
+		caps = TerminalCaps{Name: "linux console", Colors: colorprofile.ANSI, AltScreen: true}
+	case goos == "windows" && getenv("ConEmuANSI") == "ON":
+		caps.Name = "ConEmu"
+		caps.Hyperlinks = false
+	case goos == "windows" && term == "":
+		// Legacy console host: no OSC 8, ambiguous-width glyphs, and no VT
+		// support at all before Windows 10.
+		caps = TerminalCaps{Name: "conhost", Colors: colorprofile.ANSI}
+		caps.AltScreen = vtSupported()
+		caps.Mouse = caps.AltScreen
+		if !caps.AltScreen {
+			caps.Colors = colorprofile.ASCII
+		}
+	}
+
+	override := func(name string, v *bool) {
+		switch getenv(name) {
+		case "1", "true", "on":
+			*v = true
+		case "0", "false", "off":
+			*v = false
+		}
+	}
+	override("GUGET_HYPERLINKS", &caps.Hyperlinks)
+	override("GUGET_UNICODE", &caps.Unicode)
+	override("GUGET_ALTSCREEN", &caps.AltScreen)
+	override("GUGET_MOUSE", &caps.Mouse)
+	return caps
+}
+
+// applyTerminalCaps makes caps the active capability set.
+func applyTerminalCaps(caps TerminalCaps) {
+	termCaps = caps
+	if caps.Unicode {
+		glyphs = unicodeGlyphs
+	} else {
+		glyphs = asciiGlyphs
+	}
+	logDebug("Terminal: %s (hyperlinks=%t unicode=%t colors=%s altscreen=%t mouse=%t)",
+		caps.Name, caps.Hyperlinks, caps.Unicode, caps.Colors, caps.AltScreen, caps.Mouse)
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// enableVirtualTerminal is a no-op outside Windows; every supported
+// terminal understands VT sequences.
+func enableVirtualTerminal() bool {
+	return true
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/colorprofile"
+)
+
+func TestDetectTerminalCaps(t *testing.T) {
+	vtOK := func() bool { return true }
+	vtMissing := func() bool { return false }
+	cases := []struct {
+		name string
+		env  map[string]string
+		goos string
+		vt   func() bool
+		want TerminalCaps
+	}{
+		{
+			name: "windows terminal",
+			env:  map[string]string{"WT_SESSION": "abc"},
+			goos: "windows",
+			want: TerminalCaps{Name: "Windows Terminal", Hyperlinks: true, Unicode: true, AltScreen: true, Mouse: true},
+		},
+		{
+			name: "conhost with VT",
+			goos: "windows",
+			vt:   vtOK,
+			want: TerminalCaps{Name: "conhost", Colors: colorprofile.ANSI, AltScreen: true, Mouse: true},
+		},
+		{
+			name: "conhost without VT",
+			goos: "windows",
+			vt:   vtMissing,
+			want: TerminalCaps{Name: "conhost", Colors: colorprofile.ASCII},
+		},
+		{
+			name: "apple terminal",
+			env:  map[string]string{"TERM_PROGRAM": "Apple_Terminal", "TERM": "xterm-256color"},
+			goos: "darwin",
+			want: TerminalCaps{Name: "Apple_Terminal", Unicode: true, AltScreen: true, Mouse: true},
+		},
+		{
+			name: "override",
+			env:  map[string]string{"TERM": "dumb", "GUGET_UNICODE": "1"},
+			goos: "linux",
+			want: TerminalCaps{Name: "dumb", Unicode: true, Colors: colorprofile.ASCII},
+		},
+		{
+			name: "plain unix terminal",
+			env:  map[string]string{"TERM": "xterm-256color"},
+			goos: "linux",
+			want: TerminalCaps{Name: "default", Hyperlinks: true, Unicode: true, AltScreen: true, Mouse: true},
+		},
+		{
+			name: "mouse turned off",
+			env:  map[string]string{"TERM": "xterm-256color", "GUGET_MOUSE": "0"},
+			goos: "linux",
+			want: TerminalCaps{Name: "default", Hyperlinks: true, Unicode: true, AltScreen: true},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			vt := tc.vt
+			if vt == nil {
+				vt = func() bool { t.Error("vtSupported consulted outside conhost"); return true }
+			}
+			got := detectTerminalCaps(func(k string) string { return tc.env[k] }, tc.goos, vt)
+			if got != tc.want {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// transitiveVuln is a vulnerable package a project pulls in through its
+// direct dependencies rather than referencing itself.
+type transitiveVuln struct {
+	Framework  string
+	ID         string
+	Version    string
+	Severity   int // 0=low 1=moderate 2=high 3=critical, the worst advisory
+	Advisories []string
+	// Path runs from a direct dependency to the vulnerable package, e.g.
+	// [Microsoft.AspNetCore.Http 2.1.0, System.Text.Encodings.Web 4.5.0].
+	// nil when the project's restore graph could not be read.
+	Path []graphPackage
+}
+
+// graphPackage is one package in a restore graph.
+type graphPackage struct {
+	ID      string
+	Version string
+}
+
+func (p graphPackage) String() string { return p.ID + " " + p.Version }
+
+func (v transitiveVuln) SeverityLabel() string {
+	return PackageVulnerability{Severity: IntOrString(v.Severity)}.SeverityLabel()
+}
+
+// vulnerableListOutput is the subset of
+// `dotnet list package --vulnerable --include-transitive --format json`
+// that guget reads.
+type vulnerableListOutput struct {
+	Problems []struct {
+		Text string `json:"text"`
+	} `json:"problems"`
+	Projects []struct {
+		Path       string `json:"path"`
+		Frameworks []struct {
+			Framework          string `json:"framework"`
+			TransitivePackages []struct {
+				ID              string `json:"id"`
+				ResolvedVersion string `json:"resolvedVersion"`
+				Vulnerabilities []struct {
+					Severity    string `json:"severity"`
+					AdvisoryURL string `json:"advisoryurl"`
+				} `json:"vulnerabilities"`
+			} `json:"transitivePackages"`
+		} `json:"frameworks"`
+	} `json:"projects"`
+}
+
+// parseVulnerableList reads the JSON output of dotnet list package
+// --vulnerable --include-transitive and returns the vulnerable transitive
+// packages per project path. Direct references are left out: guget already
+// flags those from registration data.
+func parseVulnerableList(data []byte) (map[string][]transitiveVuln, error) {
+	var out vulnerableListOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("parsing dotnet list output: %w", err)
+	}
+	if len(out.Projects) == 0 && len(out.Problems) > 0 {
+		return nil, fmt.Errorf("dotnet list: %s", out.Problems[0].Text)
+	}
+	result := make(map[string][]transitiveVuln)
+	for _, p := range out.Projects {
+		path := filepath.Clean(p.Path)
+		vulns := []transitiveVuln{}
+		for _, fw := range p.Frameworks {
+			for _, pkg := range fw.TransitivePackages {
+				if len(pkg.Vulnerabilities) == 0 {
+					continue
+				}
+				v := transitiveVuln{Framework: fw.Framework, ID: pkg.ID, Version: pkg.ResolvedVersion}
+				for _, adv := range pkg.Vulnerabilities {
+					v.Severity = max(v.Severity, parseSeverity(adv.Severity))
+					if adv.AdvisoryURL != "" {
+						v.Advisories = append(v.Advisories, adv.AdvisoryURL)
+					}
+				}
+				vulns = append(vulns, v)
+			}
+		}
+		result[path] = vulns
+	}
+	return result, nil
+}
+
+// parseSeverity maps dotnet's severity names to the registration scale.
+func parseSeverity(s string) int {
+	switch strings.ToLower(s) {
+	case "critical":
+		return 3
+	case "high":
+		return 2
+	case "moderate", "medium":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// restoreGraph is the resolved package graph of a project, read from
+// obj/project.assets.json: every target's packages with their dependencies,
+// and the packages the project references directly.
+type restoreGraph struct {
+	targets map[string]map[string]graphNode // target → lowercase id → node
+	direct  []string                        // lowercase ids
+}
+
+type graphNode struct {
+	pkg  graphPackage
+	deps []string // lowercase ids
+}
+
+// readRestoreGraph reads the restore graph of the project in projectDir, or
+// returns nil if it has not been restored.
+func readRestoreGraph(projectDir string) *restoreGraph {
+	assets := readProjectAssets(projectDir)
+	if assets == nil {
+		return nil
+	}
+	g := &restoreGraph{targets: make(map[string]map[string]graphNode)}
+	for target, libs := range assets.Targets {
+		nodes := make(map[string]graphNode, len(libs))
+		for key, lib := range libs {
+			if lib.Type != "package" {
+				continue
+			}
+			id, version, _ := strings.Cut(key, "/")
+			node := graphNode{pkg: graphPackage{ID: id, Version: version}}
+			for dep := range lib.Dependencies {
+				node.deps = append(node.deps, strings.ToLower(dep))
+			}
+			slices.Sort(node.deps)
+			nodes[strings.ToLower(id)] = node
+		}
+		g.targets[target] = nodes
+	}
+	for _, fw := range assets.Project.Frameworks {
+		for id := range fw.Dependencies {
+			if lower := strings.ToLower(id); !slices.Contains(g.direct, lower) {
+				g.direct = append(g.direct, lower)
+			}
+		}
+	}
+	slices.Sort(g.direct)
+	return g
+}
+
+// pathTo returns the shortest chain from a direct dependency to id, across
+// every target in the graph. Returns nil when id is not reachable.
+func (g *restoreGraph) pathTo(id string) []graphPackage {
+	id = strings.ToLower(id)
+	var best []graphPackage
+	targets := make([]string, 0, len(g.targets))
+	for t := range g.targets {
+		targets = append(targets, t)
+	}
+	slices.Sort(targets)
+	for _, t := range targets {
+		if path := g.pathIn(g.targets[t], id); path != nil && (best == nil || len(path) < len(best)) {
+			best = path
+		}
+	}
+	return best
+}
+
+// pathIn searches one target breadth-first from the direct dependencies.
+func (g *restoreGraph) pathIn(nodes map[string]graphNode, id string) []graphPackage {
+	parent := make(map[string]string)
+	var queue []string
+	for _, d := range g.direct {
+		if _, ok := nodes[d]; ok && d != id {
+			parent[d] = ""
+			queue = append(queue, d)
+		}
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, dep := range nodes[cur].deps {
+			if _, seen := parent[dep]; seen {
+				continue
+			}
+			if _, ok := nodes[dep]; !ok {
+				continue
+			}
+			parent[dep] = cur
+			if dep == id {
+				var path []graphPackage
+				for at := dep; at != ""; at = parent[at] {
+					path = append(path, nodes[at].pkg)
+				}
+				slices.Reverse(path)
+				return path
+			}
+			queue = append(queue, dep)
+		}
+	}
+	return nil
+}
+
+// transitiveBump finds the lowest release of the direct package at the head
+// of path that requires a newer version of the next package along it than
+// the one resolved — the smallest direct update that moves the vulnerable
+// chain forward. Returns nil when info lists no such release.
+func transitiveBump(path []graphPackage, info *PackageInfo) *PackageVersion {
+	if len(path) < 2 || info == nil {
+		return nil
+	}
+	installed := ParseSemVer(path[0].Version)
+	next, resolved := path[1].ID, ParseSemVer(path[1].Version)
+	for i := len(info.Versions) - 1; i >= 0; i-- {
+		v := &info.Versions[i]
+		if !v.SemVer.IsNewerThan(installed) || (v.SemVer.IsPreRelease() && !installed.IsPreRelease()) {
+			continue
+		}
+		if raisesDependency(v.DependencyGroups, next, resolved) {
+			return v
+		}
+	}
+	return nil
+}
+
+// raisesDependency reports whether every group that declares id requires a
+// version newer than resolved.
+func raisesDependency(groups []dependencyGroup, id string, resolved SemVer) bool {
+	declared := false
+	for _, g := range groups {
+		for _, d := range g.Dependencies {
+			if !strings.EqualFold(d.ID, id) {
+				continue
+			}
+			declared = true
+			// A bare version in a dependency means "at least".
+			low := ParseSemVer(strings.TrimSpace(d.Range))
+			if r, ok := ParseVersionRange(d.Range); ok {
+				if !r.HasMin {
+					return false
+				}
+				low = r.Min
+			}
+			if !low.IsNewerThan(resolved) {
+				return false
+			}
+		}
+	}
+	return declared
+}
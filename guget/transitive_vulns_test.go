@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseVulnerableList(t *testing.T) {
+	data := []byte(`{
+  "version": 1,
+  "parameters": "--vulnerable --include-transitive",
+  "projects": [
+    {
+      "path": "/src/App/App.csproj",
+      "frameworks": [
+        {
+          "framework": "net8.0",
+          "topLevelPackages": [
+            {"id": "Newtonsoft.Json", "requestedVersion": "12.0.1", "resolvedVersion": "12.0.1",
+             "vulnerabilities": [{"severity": "High", "advisoryurl": "https://github.com/advisories/GHSA-5crp-9r3c-p9vr"}]}
+          ],
+          "transitivePackages": [
+            {"id": "System.Text.Encodings.Web", "resolvedVersion": "4.5.0",
+             "vulnerabilities": [
+               {"severity": "Moderate", "advisoryurl": "https://github.com/advisories/GHSA-a"},
+               {"severity": "Critical", "advisoryurl": "https://github.com/advisories/GHSA-b"}
+             ]},
+            {"id": "System.Memory", "resolvedVersion": "4.5.5"}
+          ]
+        }
+      ]
+    }
+  ]
+}`)
+	got, err := parseVulnerableList(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vulns := got[filepath.Clean("/src/App/App.csproj")]
+	if len(vulns) != 1 {
+		t.Fatalf("vulns = %+v, want only the transitive System.Text.Encodings.Web", vulns)
+	}
+	v := vulns[0]
+	if v.ID != "System.Text.Encodings.Web" || v.Version != "4.5.0" || v.Framework != "net8.0" {
+		t.Errorf("vuln = %+v", v)
+	}
+	if v.SeverityLabel() != "critical" || len(v.Advisories) != 2 {
+		t.Errorf("severity = %s, advisories = %v; want the worst of both", v.SeverityLabel(), v.Advisories)
+	}
+}
+
+func TestParseVulnerableList_Problem(t *testing.T) {
+	data := []byte(`{"version": 1, "problems": [{"level": "error", "text": "No assets file was found for App.csproj. Please run restore."}]}`)
+	if _, err := parseVulnerableList(data); err == nil {
+		t.Error("expected the reported problem as an error")
+	}
+}
+
+func TestRestoreGraphPathTo(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "obj"), 0755)
+	os.WriteFile(filepath.Join(dir, "obj", "project.assets.json"), []byte(`{
+  "targets": {
+    "net8.0": {
+      "Microsoft.AspNetCore.Http/2.1.0": {"type": "package", "dependencies": {"Microsoft.AspNetCore.WebUtilities": "2.1.0"}},
+      "Microsoft.AspNetCore.WebUtilities/2.1.0": {"type": "package", "dependencies": {"System.Text.Encodings.Web": "4.5.0"}},
+      "Serilog/4.0.0": {"type": "package", "dependencies": {"Microsoft.AspNetCore.WebUtilities": "2.1.0"}},
+      "System.Text.Encodings.Web/4.5.0": {"type": "package"},
+      "Lib/1.0.0": {"type": "project"}
+    }
+  },
+  "project": {
+    "frameworks": {
+      "net8.0": {"dependencies": {"Microsoft.AspNetCore.Http": {"target": "Package", "version": "[2.1.0, )"}}}
+    }
+  }
+}`), 0644)
+
+	graph := readRestoreGraph(dir)
+	if graph == nil {
+		t.Fatal("expected a graph")
+	}
+	got := fmt.Sprint(graph.pathTo("system.text.encodings.web"))
+	want := "[Microsoft.AspNetCore.Http 2.1.0 Microsoft.AspNetCore.WebUtilities 2.1.0 System.Text.Encodings.Web 4.5.0]"
+	if got != want {
+		t.Errorf("path = %s, want %s", got, want)
+	}
+	// Serilog is in the graph but not referenced directly, so it is no start.
+	if path := graph.pathTo("Serilog"); path != nil {
+		t.Errorf("path to Serilog = %v, want nil", path)
+	}
+	if readRestoreGraph(t.TempDir()) != nil {
+		t.Error("expected nil without an assets file")
+	}
+}
+
+func TestTransitiveBump(t *testing.T) {
+	dep := func(r string) []dependencyGroup {
+		return []dependencyGroup{{TargetFramework: "net8.0", Dependencies: []packageDependency{{ID: "System.Text.Json", Range: r}}}}
+	}
+	info := &PackageInfo{Versions: []PackageVersion{
+		{SemVer: ParseSemVer("4.0.0-beta"), DependencyGroups: dep("[9.0.0, )")},
+		{SemVer: ParseSemVer("3.2.0"), DependencyGroups: dep("[8.0.5, )")},
+		{SemVer: ParseSemVer("3.1.0"), DependencyGroups: dep("8.0.4")},
+		{SemVer: ParseSemVer("3.0.0"), DependencyGroups: dep("[8.0.0, )")},
+		{SemVer: ParseSemVer("2.0.0"), DependencyGroups: dep("[6.0.0, )")},
+	}}
+	path := []graphPackage{{"App.Client", "2.0.0"}, {"System.Text.Json", "8.0.0"}}
+
+	if got := transitiveBump(path, info); got == nil || got.SemVer.String() != "3.1.0" {
+		t.Errorf("bump = %v, want 3.1.0: the lowest release requiring above 8.0.0", got)
+	}
+	path[1].Version = "9.0.0"
+	if got := transitiveBump(path, info); got != nil {
+		t.Errorf("bump = %v, want nil: only a pre-release raises it", got.SemVer)
+	}
+	if transitiveBump(path, nil) != nil || transitiveBump(path[:1], info) != nil {
+		t.Error("expected nil without registration data or a transitive hop")
+	}
+}
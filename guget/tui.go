@@ -30,6 +30,7 @@ type App struct {
 	ctx *AppContext
 
 	projectDir string
+	solution   string // .sln/.slnx or workspace file named by --project; reloads keep using it
 	send       func(bubble_tea.Msg)
 
 	focus focusPanel
@@ -39,52 +40,91 @@ type App struct {
 	detail   detailPanel
 	log      logPanel
 
-	picker        versionPicker
-	search        packageSearch
-	confirmRemove confirmRemove
-	confirmUpdate confirmUpdate
-	locationPick  locationPicker
-	projectPick   projectPicker
-	depTree       depTreeOverlay
-	releaseNotes  releaseNotesOverlay
-	sources       sourcesOverlay
-	help          helpOverlay
+	picker          versionPicker
+	search          packageSearch
+	confirmRemove   confirmRemove
+	confirmUpdate   confirmUpdate
+	confirmFix      confirmConflictFix
+	confirmTyped    confirmTyped
+	security        securityUpdate
+	locationPick    locationPicker
+	projectPick     projectPicker
+	depTree         depTreeOverlay
+	releaseNotes    releaseNotesOverlay
+	advisory        advisoryOverlay
+	caches          cacheOverlay
+	sources         sourcesOverlay
+	help            helpOverlay
+	diagnostics     diagnosticsOverlay
+	configInspector configInspector
+	readme          readmeOverlay
+	browse          feedBrowser
+	alternatives    alternativesOverlay
+	replace         replacePreview
+	changes         sessionChanges
+	updatePlan      updatePlanOverlay
+	noteEditor      noteEditor
+	metadataFix     metadataFixOverlay
+	retarget        retargetOverlay
+	transitive      transitiveOverlay
+	failures        failureSummary
+	projectGraph    projectGraphOverlay
+	restoreReport   restoreReport
+	restoreLive     restoreProgress
+	repoAlign       repoAlignOverlay
+	reportExport    reportExport
+
+	stars favorites
+
+	// publishChecks holds the push-source lookups for packable projects by
+	// publishCheckKey; nil while one is running.
+	publishChecks map[string]*publishCheck
 
 	workspaceGeneration int
 	sourceSignature     string
 	activeReload        reloadRequestedMsg
+	loadFresh           bool // the running load bypasses the HTTP cache
 	pendingReload       reloadRequestedMsg
 	hasPendingReload    bool
+	failuresShown       bool // the failure summary opens at most once per session
 
 	resizeDebounceID int
+
+	bodyGen   int // bumped by Update unless the message is panelNeutral
+	bodyCache bodyCache
 }
 
 // overlays returns all overlay sections in priority order (highest first).
 // Used for generic key dispatch and rendering.
 func (m *App) overlays() []Overlay {
 	return []Overlay{
-		&m.depTree, &m.releaseNotes, &m.sources, &m.help,
+		&m.depTree, &m.releaseNotes, &m.readme, &m.advisory, &m.caches, &m.sources, &m.help, &m.diagnostics,
+		&m.configInspector, &m.browse, &m.alternatives, &m.replace, &m.changes, &m.updatePlan, &m.noteEditor,
+		&m.metadataFix, &m.retarget, &m.transitive, &m.failures, &m.reportExport, &m.projectGraph, &m.restoreLive, &m.restoreReport, &m.repoAlign,
 		&m.search, &m.picker, &m.locationPick, &m.projectPick,
-		&m.confirmRemove, &m.confirmUpdate,
+		&m.confirmTyped, &m.confirmRemove, &m.confirmUpdate, &m.confirmFix, &m.security,
 	}
 }
 
-func (m *App) anyOverlayActive() bool {
+// activeOverlay returns the overlay that receives keys, or nil.
+func (m *App) activeOverlay() Overlay {
 	for _, o := range m.overlays() {
 		if o.IsActive() {
-			return true
+			return o
 		}
 	}
-	return false
+	return nil
+}
+
+func (m *App) anyOverlayActive() bool {
+	return m.activeOverlay() != nil
 }
 
-func NewApp(projectDir string, snapshot *workspaceSnapshot, initialLogLines []string, flags BuiltFlags) *App {
+func NewApp(projectDir string, snapshot *workspaceSnapshot, initialLogLines []string, flags BuiltFlags, settings UserConfig) *App {
 	sp := bubbles_spinner.New()
 	sp.Spinner = bubbles_spinner.Dot
 	sp.Style = styleAccent
 
-	projItems := buildProjectItems(snapshot.ParsedProjects, snapshot.PropsProjects)
-
 	dv := bubbles_viewport.New(bubbles_viewport.WithWidth(40), bubbles_viewport.WithHeight(20))
 	lv := bubbles_viewport.New(bubbles_viewport.WithWidth(80), bubbles_viewport.WithHeight(logPanelLines))
 
@@ -98,26 +138,38 @@ func NewApp(projectDir string, snapshot *workspaceSnapshot, initialLogLines []st
 	ctx := &AppContext{
 		ParsedProjects:  snapshot.ParsedProjects,
 		PropsProjects:   snapshot.PropsProjects,
+		Solution:        snapshot.Solution,
 		NugetServices:   snapshot.NugetServices,
 		Sources:         snapshot.Sources,
 		SourceMapping:   snapshot.SourceMapping,
+		SourceScopes:    snapshot.Scopes,
+		PackageFolders:  snapshot.PackageFolders,
+		SourceConflicts: snapshot.Conflicts,
+		SourceFailures:  snapshot.SourceFailures,
+		SDKs:            snapshot.SDKs,
+		SDKErr:          snapshot.SDKErr,
 		PendingPackages: NewSet[string](),
 		Spinner:         sp,
 		Results:         make(map[string]nugetResult),
 		LogLines:        initialLogLines,
+		ReadOnly:        flags.ReadOnly,
+		LoadDeadline:    flags.Deadline,
+		NoEnrich:        flags.NoEnrich,
+		Prerelease:      flags.Prerelease,
 	}
 
 	m := &App{
 		ctx:             ctx,
 		projectDir:      projectDir,
-		sourceSignature: workspaceSourceSignature(snapshot.Sources, snapshot.SourceMapping),
+		solution:        flags.Solution,
+		sourceSignature: snapshot.Scopes.signature(),
 		projects: projectPanel{
 			sectionBase: sectionBase{baseWidth: 30, minWidth: 10},
-			items:       projItems,
 		},
 		packages: packagePanel{
 			sortMode: sortMode,
 			sortDir:  sortDir,
+			filter:   newPackageFilterInput(),
 		},
 		detail: detailPanel{
 			sectionBase: sectionBase{baseWidth: 50, minWidth: 10},
@@ -135,6 +187,22 @@ func NewApp(projectDir string, snapshot *workspaceSnapshot, initialLogLines []st
 			sectionBase: sectionBase{basePct: 60, minWidth: 56, maxMargin: 4},
 			vp:          bubbles_viewport.New(bubbles_viewport.WithWidth(60), bubbles_viewport.WithHeight(20)),
 		},
+		diagnostics: diagnosticsOverlay{
+			sectionBase: sectionBase{basePct: 70, minWidth: 56, maxMargin: 4},
+			vp:          bubbles_viewport.New(bubbles_viewport.WithWidth(60), bubbles_viewport.WithHeight(20)),
+		},
+		configInspector: configInspector{
+			sectionBase: sectionBase{basePct: 70, minWidth: 56, maxMargin: 4},
+			vp:          bubbles_viewport.New(bubbles_viewport.WithWidth(60), bubbles_viewport.WithHeight(20)),
+		},
+		readme: readmeOverlay{
+			sectionBase: sectionBase{basePct: 80, minWidth: 56, maxMargin: 4},
+			vp:          bubbles_viewport.New(bubbles_viewport.WithWidth(60), bubbles_viewport.WithHeight(20)),
+		},
+		retarget: retargetOverlay{
+			sectionBase: sectionBase{basePct: 70, minWidth: 56, maxMargin: 4},
+			vp:          bubbles_viewport.New(bubbles_viewport.WithWidth(60), bubbles_viewport.WithHeight(20)),
+		},
 	}
 	// Set back-pointers so sections can access the App.
 	m.projects.app = m
@@ -142,6 +210,24 @@ func NewApp(projectDir string, snapshot *workspaceSnapshot, initialLogLines []st
 	m.search.app = m
 	m.sources.app = m
 	m.help.app = m
+	m.diagnostics.app = m
+	m.configInspector.app = m
+	m.readme.app = m
+	m.retarget.app = m
+	m.ctx.Config = settings
+	m.ctx.Renames = newPackageRenames(settings.Renames)
+	m.ctx.Rules = settings.Packages
+	m.loadNotes()
+	m.ctx.ShowLogs = settings.ShowLogs
+	st, saved := loadLayout(layoutStatePath(), projectDir)
+	if !saved {
+		st.ProjectsOffset, st.DetailOffset = settings.ProjectsPanelOffset, settings.DetailPanelOffset
+	}
+	m.projects.widthOffset = st.ProjectsOffset
+	m.detail.widthOffset = st.DetailOffset
+	m.packages.showLicense = st.ShowLicense
+	m.stars = newFavorites(st)
+	m.setProjectItems(buildProjectItems(snapshot.ParsedProjects, snapshot.PropsProjects))
 	return m
 }
 
@@ -151,6 +237,9 @@ func (m *App) Init() bubble_tea.Cmd {
 
 func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 	var cmds []bubble_tea.Cmd
+	if !m.panelNeutral(msg) {
+		m.bodyGen++
+	}
 
 	switch msg := msg.(type) {
 
@@ -175,6 +264,18 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 			if m.releaseNotes.active {
 				m.releaseNotes.resizeViewport()
 			}
+			if m.diagnostics.active {
+				m.diagnostics.refreshView()
+			}
+			if m.readme.active {
+				m.readme.refreshView()
+			}
+			if m.retarget.active {
+				m.retarget.refreshView()
+			}
+			if m.configInspector.active {
+				m.configInspector.refreshView()
+			}
 		}
 
 	case bubbles_spinner.TickMsg:
@@ -186,6 +287,9 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 		if msg.generation != m.workspaceGeneration {
 			break
 		}
+		if msg.result.pkg != nil {
+			msg.result.pkg.trimVersions(m.ctx.Rules.For(msg.name), m.versionUses(msg.name))
+		}
 		m.ctx.Results[msg.name] = msg.result
 		if m.ctx.PendingPackages != nil {
 			m.ctx.PendingPackages.Remove(msg.name)
@@ -193,7 +297,12 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 		if m.ctx.LoadingTotal > 0 {
 			m.ctx.LoadingDone++
 			if m.ctx.LoadingDone >= m.ctx.LoadingTotal {
+				if m.ctx.Loading && !m.failuresShown {
+					m.failuresShown = true
+					m.openFailureSummary()
+				}
 				m.ctx.Loading = false
+				m.startEnrichment()
 				if m.ctx.Reloading {
 					m.finishReloadSuccess()
 				} else {
@@ -201,8 +310,28 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 				}
 			}
 		}
-		m.rebuildPackageRows()
-		m.refreshDetail()
+		resort := m.ctx.PendingPackages.Len() == 0
+		m.updatePackageRows(msg.name, resort)
+		// Only the detail for the package under the cursor can have changed,
+		// unless the resort or a triage view's rebuild moved the cursor.
+		if resort || m.packages.view != viewAll || (m.packages.cursor < len(m.packages.rows) && m.packages.rows[m.packages.cursor].ref.Name == msg.name) {
+			m.refreshDetail()
+		}
+
+	case packageEnrichedMsg:
+		m.applyEnrichment(msg)
+
+	case packageAdvisoriesMsg:
+		m.applyGitHubAdvisories(msg)
+
+	case enrichDoneMsg:
+		if msg.generation == m.workspaceGeneration {
+			m.ctx.Enriching = false
+			logInfo("Checked %d package(s) from other feeds for advisories", msg.count)
+			// New advisories can change the status order.
+			m.updatePackageRows("", true)
+			m.refreshDetail()
+		}
 
 	case reloadRequestedMsg:
 		m.requestReload(msg)
@@ -214,6 +343,9 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 		if msg.err != nil {
 			cmds = append(cmds, m.setStatus("▲ Save failed: "+msg.err.Error(), true))
 		} else {
+			if msg.apply != nil {
+				msg.apply()
+			}
 			status := "✓ Saved"
 			if msg.written > 0 && msg.skipped > 0 {
 				status = fmt.Sprintf("✓ Saved %d, %d locked", msg.written, msg.skipped)
@@ -223,13 +355,41 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 			cmds = append(cmds, m.setStatus(status, false))
 		}
 
+	case alignBranchesMsg:
+		cmds = append(cmds, m.finishAlignBranches(msg.results))
+
+	case publishCheckMsg:
+		m.publishChecks[msg.key] = &msg.check
+		if m.detail.project {
+			m.refreshDetail()
+		}
+
+	case readmeReadyMsg:
+		m.readme.finish(msg)
+
+	case restoreLineMsg:
+		m.restoreLive.addLine(msg)
+
+	case restoreTargetMsg:
+		m.restoreLive.updateTarget(msg)
+
 	case restoreResultMsg:
 		m.ctx.Restoring = false
-		if msg.err != nil {
+		m.setRestoreReport(msg.outcomes, msg.elapsed)
+		m.refreshRestoreWarnings()
+		cancelled := m.finishRestoreProgress(msg.outcomes)
+		hint := ""
+		if n := m.restoreReport.messageCount(); n > 0 {
+			hint = fmt.Sprintf(" · %d NuGet message(s), D for details", n)
+		}
+		if cancelled {
+			cmds = append(cmds, m.setStatus("⊘ Restore cancelled after "+formatElapsed(msg.elapsed)+" (D for details)", true))
+		} else if msg.err != nil {
 			logError("restore failed: %v", msg.err)
-			cmds = append(cmds, m.setStatus("✗ Restore failed (see logs)", true))
+			first, _, _ := strings.Cut(msg.err.Error(), "\n")
+			cmds = append(cmds, m.setStatus("✗ "+first+" (D for details)", true))
 		} else {
-			cmds = append(cmds, m.setStatus("✓ Restore complete", false))
+			cmds = append(cmds, m.setStatus("✓ Restore complete in "+formatElapsed(msg.elapsed)+hint, false))
 		}
 
 	case searchDebounceMsg:
@@ -238,6 +398,36 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 			cmds = append(cmds, m.search.doSearchCmd(msg.query))
 		}
 
+	case changeRevertedMsg:
+		m.changes.entries = sessionJournal.Entries()
+		if msg.err != nil {
+			cmds = append(cmds, m.setStatus("✗ Can't revert "+msg.entry.summary()+": "+msg.err.Error(), true))
+			break
+		}
+		cmds = append(cmds, m.setStatus("✓ Reverted "+msg.entry.summary(), false))
+		m.requestReload(reloadRequestedMsg{reason: "revert"})
+
+	case alternativesReadyMsg:
+		m.alternatives.applyResults(msg)
+
+	case replacementFetchedMsg:
+		m.alternatives.showReplacement(msg)
+
+	case replaceDoneMsg:
+		if msg.err != nil {
+			cmds = append(cmds, m.setStatus("✗ Replace failed, nothing was changed: "+msg.err.Error(), true))
+			break
+		}
+		if m.ctx.Results == nil {
+			m.ctx.Results = make(map[string]nugetResult)
+		}
+		m.ctx.Results[msg.plan.New] = nugetResult{pkg: msg.plan.Info, source: msg.plan.Source}
+		cmds = append(cmds, m.setStatus(fmt.Sprintf("✓ Replaced %s with %s (%d file(s), ctrl+z to undo)", msg.plan.Old, msg.plan.New, len(msg.plan.Files())), false))
+		m.requestReload(reloadRequestedMsg{reason: "replace " + msg.plan.Old})
+
+	case siblingQueriesMsg:
+		cmds = append(cmds, m.search.applySiblingQueries(msg))
+
 	case searchResultsMsg:
 		if msg.query == m.search.lastQuery {
 			m.search.loading = false
@@ -254,6 +444,12 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 			m.search.err = msg.err
 			break
 		}
+		if m.search.replaceFor != "" {
+			m.search.closeOverlay()
+			m.search.input.Blur()
+			m.openReplacePreview(m.search.replaceFor, msg.info, msg.source)
+			break
+		}
 		m.search.fetchedInfo = msg.info
 		m.search.fetchedSource = msg.source
 		m.search.closeOverlay()
@@ -273,8 +469,8 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 		}
 
 	case logLineMsg:
-		m.ctx.LogLines = append(m.ctx.LogLines, msg.line)
-		m.updateLogView()
+		m.ctx.LogLines = appendLogLine(m.ctx.LogLines, msg.line)
+		m.appendLogView(msg.line)
 
 	case releaseListReadyMsg:
 		m.releaseNotes.ghLoading = false
@@ -297,7 +493,7 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 		if msg.err != nil {
 			m.releaseNotes.ghErr = msg.err
 			// Auto-switch to NuSpec if GitHub failed and NuSpec is available.
-			if m.releaseNotes.nsAvailable || len(m.releaseNotes.nsVersions) > 0 {
+			if m.releaseNotes.activeTab == tabReleases && (m.releaseNotes.nsAvailable || len(m.releaseNotes.nsVersions) > 0) {
 				m.releaseNotes.activeTab = tabNuSpec
 			}
 			m.releaseNotes.updateViewportContent()
@@ -306,7 +502,7 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 		m.releaseNotes.ghReleases = msg.releases
 		m.releaseNotes.ghAvailable = len(msg.releases) > 0
 		if len(msg.releases) == 0 {
-			if m.releaseNotes.nsAvailable || len(m.releaseNotes.nsVersions) > 0 {
+			if m.releaseNotes.activeTab == tabReleases && (m.releaseNotes.nsAvailable || len(m.releaseNotes.nsVersions) > 0) {
 				m.releaseNotes.activeTab = tabNuSpec
 			}
 			m.releaseNotes.updateViewportContent()
@@ -326,6 +522,31 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 		}
 		m.releaseNotes.updateViewportContent()
 
+	case browsePageMsg:
+		m.browse.applyPage(msg)
+
+	case versionDeletedMsg:
+		if msg.err != nil {
+			logError("Deleting %s %s from %s: %v", msg.pkgName, msg.version, msg.source, msg.err)
+			cmds = append(cmds, m.setStatus("✗ "+msg.err.Error(), true))
+			break
+		}
+		logInfo("Deleted %s %s from %s", msg.pkgName, msg.version, msg.source)
+		cmds = append(cmds, m.setStatus(fmt.Sprintf("✓ %s %s unlisted or deleted on %s", msg.pkgName, msg.version, msg.source), false))
+		if !m.ctx.Loading && !m.ctx.Reloading {
+			m.refetchPackages([]string{msg.pkgName}, true)
+		}
+
+	case changeNotesReadyMsg:
+		m.releaseNotes.chLoading = false
+		if m.releaseNotes.nsNotesCache == nil {
+			m.releaseNotes.nsNotesCache = make(map[string]string)
+		}
+		for ver, notes := range msg.notes {
+			m.releaseNotes.nsNotesCache[ver] = notes
+		}
+		m.releaseNotes.updateViewportContent()
+
 	case nuspecVersionNotesReadyMsg:
 		m.releaseNotes.nsLoading = false
 		if msg.notes != "" {
@@ -338,6 +559,66 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 		m.releaseNotes.nsNotesCache[msg.version] = msg.notes
 		m.releaseNotes.updateViewportContent()
 
+	case allVersionsMsg:
+		if m.picker.active && m.picker.pkgName == msg.pkgName && m.picker.loadingAll > 0 {
+			m.picker.loadingAll = 0
+			if msg.err != nil {
+				cmds = append(cmds, m.setStatus("✗ Could not load older versions: "+msg.err.Error(), true))
+			} else {
+				m.picker.setVersions(msg.info.Versions)
+			}
+		}
+
+	case restoreImpactMsg:
+		if m.picker.active && m.picker.pkgName == msg.pkgName {
+			m.picker.impactLoading = ""
+			impact := msg.impact
+			m.picker.impact[msg.version] = &impact
+		}
+
+	case advisoryReadyMsg:
+		if m.advisory.details != nil {
+			if msg.err != nil {
+				m.advisory.errs[msg.url] = msg.err
+			} else {
+				m.advisory.details[msg.url] = msg.advisory
+			}
+		}
+
+	case nugetLocalsMsg:
+		m.caches.loading = false
+		m.caches.err = msg.err
+		m.caches.locals = msg.locals
+		if m.caches.cursor > len(msg.locals) {
+			m.caches.cursor = len(msg.locals)
+		}
+
+	case sourcesMigratedMsg:
+		m.sources.migrating = false
+		if msg.err != nil {
+			logError("updating source URLs: %v", msg.err)
+			cmds = append(cmds, m.setStatus("✗ Updating source URLs failed, nothing was changed (see logs)", true))
+		} else if msg.migrated > 0 {
+			cmds = append(cmds, m.setStatus(fmt.Sprintf("✓ Updated %d source URL(s) in nuget.config", msg.migrated), false))
+			m.requestReload(reloadRequestedMsg{reason: "source URLs updated"})
+		}
+
+	case sourceRetriedMsg:
+		cmds = append(cmds, m.finishSourceRetry(msg))
+
+	case nugetLocalClearedMsg:
+		m.caches.clearing = ""
+		if msg.err != nil {
+			logError("clearing %s failed: %v", msg.name, msg.err)
+			cmds = append(cmds, m.setStatus("✗ Clearing "+msg.name+" failed (see logs)", true))
+		} else {
+			cmds = append(cmds, m.setStatus("✓ Cleared "+msg.name, false))
+			if m.caches.active {
+				m.caches.loading = true
+				cmds = append(cmds, listNugetLocalsCmd())
+			}
+		}
+
 	case depTreeReadyMsg:
 		m.depTree.loading = false
 		m.depTree.err = msg.err
@@ -346,6 +627,15 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 		}
 		m.depTree.vp.SetContent(m.depTree.buildContent())
 
+	case transitiveScanMsg:
+		m.applyTransitiveScan(msg)
+
+	case bubble_tea.MouseClickMsg:
+		return m, m.handleMouseClick(msg)
+
+	case bubble_tea.MouseWheelMsg:
+		return m, m.handleMouseWheel(msg)
+
 	case bubble_tea.KeyMsg:
 		handled := false
 		for _, o := range m.overlays() {
@@ -355,6 +645,10 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 				break
 			}
 		}
+		if !handled && m.packages.filtering {
+			cmds = append(cmds, m.handlePackageFilterKey(msg))
+			handled = true
+		}
 		if !handled {
 			cmds = append(cmds, m.handleKey(msg))
 		}
@@ -389,7 +683,7 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 			if keyMsg, ok := msg.(bubble_tea.KeyMsg); ok && (keyMsg.String() == "v" || keyMsg.String() == "n") {
 				// handled by handleKey above
 				if keyMsg.String() == "v" {
-					m.openVersionPicker()
+					cmds = append(cmds, m.openVersionPicker())
 				}
 			} else {
 				var cmd bubble_tea.Cmd
@@ -405,6 +699,15 @@ func (m *App) Update(msg bubble_tea.Msg) (bubble_tea.Model, bubble_tea.Cmd) {
 		}
 	}
 
+	// The detail panel describes the selected project while the project
+	// list has focus, and the selected package otherwise.
+	if m.detail.project != (m.focus == focusProjects) {
+		m.refreshDetail()
+	}
+	if m.detail.project {
+		cmds = append(cmds, m.checkSelectedPublish())
+	}
+
 	return m, bubble_tea.Batch(cmds...)
 }
 
@@ -414,6 +717,9 @@ func (m *App) setStatus(text string, isErr bool) bubble_tea.Cmd {
 		text = text[:i]
 	}
 	maxW := m.layoutWidth() - 6 // padding + border
+	if m.ctx.ReadOnly {
+		maxW -= lipgloss.Width("READ-ONLY · ")
+	}
 	if lipgloss.Width(text) > maxW && maxW > 3 {
 		text = text[:maxW-3] + "..."
 	}
@@ -425,6 +731,10 @@ func (m *App) setStatus(text string, isErr bool) bubble_tea.Cmd {
 func (m *App) handleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 	switch msg.String() {
 	case "ctrl+c", "q", "esc":
+		if msg.String() == "esc" && m.packageFiltered() {
+			m.clearPackageFilter()
+			return nil
+		}
 		return bubble_tea.Quit
 
 	case "tab":
@@ -454,9 +764,25 @@ func (m *App) handleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 	case "s":
 		m.sources.active = !m.sources.active
 		if m.sources.active {
+			m.sources.cursor = min(m.sources.cursor, max(0, len(m.ctx.Sources)-1))
 			m.ctx.StatusLine = ""
 		}
 
+	case "!":
+		m.diagnostics.active = true
+		m.ctx.StatusLine = ""
+		m.diagnostics.refreshView()
+
+	case "c":
+		m.openConfigInspector()
+
+	case "b":
+		return m.openFeedBrowser()
+
+	case "L":
+		m.packages.showLicense = !m.packages.showLicense
+		return m.persistLayout()
+
 	case "?":
 		m.help.active = !m.help.active
 		if m.help.active {
@@ -498,9 +824,29 @@ func (m *App) handleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 			return m.updatePackage(true, scopeAll)
 		}
 
+	case "ctrl+u":
+		if m.focus == focusPackages {
+			return m.updateAllInView()
+		}
+
+	case "f":
+		if m.focus == focusPackages {
+			return m.fixVulnerability(scopeSelected)
+		}
+
+	case "F":
+		if m.focus == focusPackages {
+			return m.fixVulnerability(scopeAll)
+		}
+
+	case "S":
+		if m.focus == focusPackages {
+			return m.openSecurityUpdate()
+		}
+
 	case "v":
 		if m.focus == focusPackages {
-			m.openVersionPicker()
+			return m.openVersionPicker()
 		}
 
 	case "r":
@@ -516,19 +862,111 @@ func (m *App) handleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 	case "ctrl+r":
 		m.requestReload(reloadRequestedMsg{reason: "manual reload"})
 
+	case "e":
+		return m.retryFailedPackages()
+
+	case "ctrl+f":
+		if m.focus == focusPackages {
+			return m.refreshSelectedPackage()
+		}
+
+	case "E":
+		if !m.openFailureSummary() {
+			return m.setStatus("No package load failures", false)
+		}
+
 	case "n":
 		if m.focus == focusPackages || m.focus == focusDetail {
 			return m.openReleaseNotes()
 		}
 
+	case "M":
+		if m.focus == focusPackages || m.focus == focusDetail {
+			return m.openReadme()
+		}
+
+	case "m":
+		if m.focus == focusProjects {
+			return m.openMetadataFix()
+		}
+
+	case "J":
+		if (m.focus == focusPackages || m.focus == focusDetail) && m.packages.cursor < len(m.packages.rows) {
+			return m.jumpToLocalProject(m.packages.rows[m.packages.cursor].ref.Name)
+		}
+
+	case "ctrl+z":
+		return m.undoLast()
+
+	case "Z":
+		return m.openSessionChanges()
+
+	case "p":
+		if (m.focus == focusPackages || m.focus == focusDetail) && m.packages.cursor < len(m.packages.rows) {
+			return m.openReplaceSearch(m.packages.rows[m.packages.cursor].ref.Name)
+		}
+
+	case "*":
+		return m.toggleStar()
+
+	case "ctrl+s":
+		return m.toggleStarredOnly()
+
+	case "N":
+		if (m.focus == focusPackages || m.focus == focusDetail) && m.packages.cursor < len(m.packages.rows) {
+			return m.openNoteEditor(m.packages.rows[m.packages.cursor].ref.Name)
+		}
+
+	case "g":
+		if (m.focus == focusPackages || m.focus == focusDetail) && m.packages.cursor < len(m.packages.rows) {
+			return m.openAlternatives(m.packages.rows[m.packages.cursor])
+		}
+
+	case "w":
+		if (m.focus == focusPackages || m.focus == focusDetail) && m.packages.cursor < len(m.packages.rows) {
+			return m.openSiblingSearch(m.packages.rows[m.packages.cursor])
+		}
+
 	case "t":
-		if m.focus == focusPackages {
+		switch m.focus {
+		case focusPackages:
 			return m.openDepTree()
+		case focusProjects:
+			return m.openRetarget()
 		}
 
 	case "T":
 		return m.openTransitiveDepTree()
 
+	case "V":
+		return m.openTransitiveScan()
+
+	case "W":
+		return m.openRepoAlignment()
+
+	case "G":
+		m.openProjectGraph()
+
+	case "D":
+		if m.ctx.Restoring {
+			m.restoreLive.active = true
+			m.ctx.StatusLine = ""
+		} else if !m.openRestoreReport() {
+			return m.setStatus("No restore has run yet", false)
+		}
+
+	case "P":
+		return m.togglePrerelease()
+
+	case "H":
+		return m.openSnapshotDiff()
+
+	case "C":
+		return m.openCaches()
+
+	case "X":
+		return m.openReportExport()
+
 	case "o":
 		if m.focus == focusPackages {
 			m.packages.sortMode = m.packages.sortMode.next()
@@ -550,25 +988,54 @@ func (m *App) handleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 
 	case "d":
 		if m.focus == focusPackages && m.packages.cursor < len(m.packages.rows) {
+			if cmd := m.readOnlyProjectStatus(m.selectedProject()); cmd != nil {
+				return cmd
+			}
 			m.confirmRemove = newConfirmRemove(m, m.packages.rows[m.packages.cursor].ref.Name)
 			m.ctx.StatusLine = ""
 		}
 
+	case "x":
+		if m.focus == focusPackages && m.packages.cursor < len(m.packages.rows) {
+			return m.openConflictFix(m.packages.rows[m.packages.cursor])
+		}
+
 	case "/":
 		return m.openSearch()
 
+	case "i":
+		if m.focus == focusPackages || m.focus == focusProjects {
+			return m.openPackageFilter()
+		}
+
+	case "I":
+		if m.focus == focusPackages || m.focus == focusProjects {
+			m.focus = focusPackages
+			m.cycleView()
+		}
+
 	case "[":
 		m.resizeFocused(-2)
 		m.relayout()
-		return nil
+		return m.persistLayout()
 	case "]":
 		m.resizeFocused(2)
 		m.relayout()
-		return nil
+		return m.persistLayout()
+
+	case "=":
+		m.projects.widthOffset = 0
+		m.detail.widthOffset = 0
+		m.relayout()
+		m.refreshDetail()
+		return bubble_tea.Batch(m.persistLayout(), m.setStatus("✓ Layout reset", false))
 
 	case "enter":
-		if m.focus == focusProjects {
+		switch m.focus {
+		case focusProjects:
 			m.focus = focusPackages
+		case focusPackages, focusDetail:
+			return m.openAdvisories()
 		}
 	}
 	return nil
@@ -595,6 +1062,24 @@ func (m *App) resizeFocused(delta int) {
 	}
 }
 
+// persistLayout saves the current panel offsets and starred items for this
+// project directory so the next session opens with the same layout.
+func (m *App) persistLayout() bubble_tea.Cmd {
+	st := layoutState{
+		ProjectsOffset: m.projects.widthOffset,
+		DetailOffset:   m.detail.widthOffset,
+		ShowLicense:    m.packages.showLicense,
+	}
+	m.starredLayout(&st)
+	save := queueLayout(layoutStatePath(), m.projectDir, st)
+	return func() bubble_tea.Msg {
+		if err := save(); err != nil {
+			logWarn("Could not save layout: %v", err)
+		}
+		return nil
+	}
+}
+
 func (m *App) selectedProject() *ParsedProject {
 	if m.projects.cursor >= 0 && m.projects.cursor < len(m.projects.items) {
 		return m.projects.items[m.projects.cursor].project
@@ -613,7 +1098,10 @@ func (m *App) rowByName(name string) *packageRow {
 
 func (m *App) View() bubble_tea.View {
 	v := bubble_tea.NewView("")
-	v.AltScreen = true
+	v.AltScreen = termCaps.AltScreen
+	if termCaps.Mouse {
+		v.MouseMode = bubble_tea.MouseModeCellMotion
+	}
 
 	if m.ctx.Width == 0 {
 		v.SetContent("Initializing...")
@@ -657,12 +1145,13 @@ func (m *App) View() bubble_tea.View {
 	}
 
 	leftW, midW, rightW := m.panelWidths()
-
-	left := m.renderProjectPanel(leftW)
-	mid := m.renderPackagePanel(midW)
-	right := m.renderDetailPanel(rightW)
-
-	body := lipgloss.JoinHorizontal(lipgloss.Top, left, mid, right)
+	key := bodyCacheKey{gen: m.bodyGen, left: leftW, mid: midW, right: rightW, height: m.bodyOuterHeight(), focus: m.focus}
+	body := m.bodyCache.get(key, func() string {
+		left := m.renderProjectPanel(leftW)
+		mid := m.renderPackagePanel(midW)
+		right := m.renderDetailPanel(rightW)
+		return lipgloss.JoinHorizontal(lipgloss.Top, left, mid, right)
+	})
 
 	parts := []string{body}
 	if m.ctx.ShowLogs {
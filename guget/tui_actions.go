@@ -2,7 +2,7 @@ package main
 
 import (
 	"fmt"
-	"os/exec"
+	"path/filepath"
 	"strings"
 
 	bubble_tea "charm.land/bubbletea/v2"
@@ -32,6 +32,26 @@ func (m *App) updatePackage(useStable bool, scope actionScope) bubble_tea.Cmd {
 	return m.applyOrConfirmUpdate(row.ref.Name, target.SemVer.String(), project)
 }
 
+// fixVulnerability moves the selected package to the smallest version that
+// clears all of its advisories, rather than to latest.
+func (m *App) fixVulnerability(scope actionScope) bubble_tea.Cmd {
+	if m.packages.cursor >= len(m.packages.rows) {
+		return nil
+	}
+	row := m.packages.rows[m.packages.cursor]
+	if !row.vulnerable {
+		return nil
+	}
+	if row.minFixed == nil {
+		return m.setStatus("✗ no compatible version of "+row.ref.Name+" clears every advisory", true)
+	}
+	var project *ParsedProject
+	if scope == scopeSelected {
+		project = m.selectedProject()
+	}
+	return m.applyOrConfirmUpdate(row.ref.Name, row.minFixed.SemVer.String(), project)
+}
+
 func (m *App) isPropsProject(p *ParsedProject) bool {
 	for _, pp := range m.ctx.PropsProjects {
 		if pp == p {
@@ -49,7 +69,45 @@ func (m *App) allProjects() []*ParsedProject {
 	return all
 }
 
+// readOnlySessionStatus reports a write or exec attempt made while guget was
+// started with --read-only. Returns nil when actions are allowed.
+func (m *App) readOnlySessionStatus() bubble_tea.Cmd {
+	if !m.ctx.ReadOnly {
+		return nil
+	}
+	return m.setStatus("✗ read-only session (started with --read-only)", true)
+}
+
+// readOnlyProjectStatus reports a write attempt against a project guget
+// cannot safely edit (legacy or unparseable), or any write in a read-only
+// session. Returns nil for writable ones.
+func (m *App) readOnlyProjectStatus(p *ParsedProject) bubble_tea.Cmd {
+	switch {
+	case m.ctx.ReadOnly:
+		return m.readOnlySessionStatus()
+	case p == nil:
+		return nil
+	case p.LoadErr != nil:
+		return m.setStatus("✗ "+p.FileName+" could not be parsed", true)
+	case p.Legacy:
+		return m.setStatus("✗ "+p.FileName+" is a legacy project (read-only)", true)
+	}
+	return nil
+}
+
+// readOnlyUpdateStatus is readOnlyProjectStatus for version updates, which
+// a legacy project also allows when it lists its packages in packages.config.
+func (m *App) readOnlyUpdateStatus(p *ParsedProject) bubble_tea.Cmd {
+	if p != nil && p.Legacy && p.PackagesConfig != "" && p.LoadErr == nil && !m.ctx.ReadOnly {
+		return nil
+	}
+	return m.readOnlyProjectStatus(p)
+}
+
 func (m *App) applyVersion(pkgName, version string, targetProject *ParsedProject) bubble_tea.Cmd {
+	if cmd := m.readOnlyUpdateStatus(targetProject); cmd != nil {
+		return cmd
+	}
 	projects := m.ctx.ParsedProjects
 	if targetProject != nil {
 		projects = []*ParsedProject{targetProject}
@@ -59,6 +117,9 @@ func (m *App) applyVersion(pkgName, version string, targetProject *ParsedProject
 	var propsSource string
 	skippedLocked := 0
 	for _, p := range projects {
+		if !p.canUpdatePackage(pkgName) {
+			continue
+		}
 		updated := NewSet[PackageReference]()
 		changed := false
 		for ref := range p.Packages {
@@ -67,7 +128,7 @@ func (m *App) applyVersion(pkgName, version string, targetProject *ParsedProject
 					// scope=all: skip locked versions, track count for status warning
 					skippedLocked++
 				} else {
-					ref.Version = ParseSemVer(version)
+					ref.Version = ParseSemVer(ref.Version.Retarget(version))
 					changed = true
 				}
 			}
@@ -88,13 +149,13 @@ func (m *App) applyVersion(pkgName, version string, targetProject *ParsedProject
 	// to every other project that inherits from the same file.
 	if propsSource != "" {
 		for _, p := range m.allProjects() {
-			if p.SourceFileForPackage(pkgName) != propsSource {
+			if p.Legacy || p.SourceFileForPackage(pkgName) != propsSource {
 				continue
 			}
 			updated := NewSet[PackageReference]()
 			for ref := range p.Packages {
 				if ref.Name == pkgName {
-					ref.Version = ParseSemVer(version)
+					ref.Version = ParseSemVer(ref.Version.Retarget(version))
 				}
 				updated.Add(ref)
 			}
@@ -117,57 +178,95 @@ func (m *App) applyVersion(pkgName, version string, targetProject *ParsedProject
 	}
 	written := len(toWrite)
 	return func() bubble_tea.Msg {
+		rec := ActionRecord{Action: "update", Package: pkgName, Version: version}
 		seen := make(map[string]bool)
 		for _, fp := range toWrite {
 			if seen[fp] {
 				continue
 			}
 			seen[fp] = true
+			rec.Files = append(rec.Files, fp)
 			logDebug("writing %s to %s", pkgName, fp)
-			if err := UpdatePackageVersion(fp, pkgName, version); err != nil {
+			rewritten, err := updatePackageVersionFiles(fp, pkgName, version)
+			rec.Files = append(rec.Files, rewritten...)
+			if err != nil {
 				logWarn("write failed for %s: %v", fp, err)
+				recordAction(rec, err)
 				return writeResultMsg{err: err}
 			}
 		}
+		recordAction(rec, nil)
 		return writeResultMsg{err: nil, written: written, skipped: skippedLocked}
 	}
 }
 
 func (m *App) restore(scope actionScope) bubble_tea.Cmd {
-	m.ctx.Restoring = true
+	projects := m.ctx.ParsedProjects
+	filtered := false
 	if scope == scopeSelected {
 		sel := m.selectedProject()
 		if sel != nil && !m.isPropsProject(sel) {
-			return runDotnetRestore([]*ParsedProject{sel})
+			projects = []*ParsedProject{sel}
+			filtered = true
 		}
 	}
 	// scopeAll, or "All Projects" selected, or .props file — restore all actual project files.
-	return runDotnetRestore(m.ctx.ParsedProjects)
+	if cmd := m.readOnlySessionStatus(); cmd != nil {
+		return cmd
+	}
+	if cmd := m.sdkStatus(projects); cmd != nil {
+		return cmd
+	}
+	m.ctx.Restoring = true
+	return m.startRestore(restoreTargets(projects, m.ctx.Solution, filtered))
 }
 
-func runDotnetRestore(projects []*ParsedProject) bubble_tea.Cmd {
-	return func() bubble_tea.Msg {
-		var lastErr error
-		for _, p := range projects {
-			if p.FilePath == "" {
-				continue
-			}
-			logDebug("dotnet restore: %s", p.FilePath)
-			cmd := exec.Command("dotnet", "restore", p.FilePath)
-			out, err := cmd.CombinedOutput()
-			if err != nil {
-				logWarn("restore failed for %s: %v\n%s", p.FilePath, err, strings.TrimSpace(string(out)))
-				lastErr = fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(out)))
-			} else {
-				logInfo("restore succeeded for %s", p.FileName)
-			}
+// restoreTargets returns what to pass to dotnet restore. A single restore of
+// the solution is much faster than one per project, since shared
+// dependencies are resolved once, so it is used unless the scope is filtered
+// or a project failed to load and would fail the whole run.
+func restoreTargets(projects []*ParsedProject, solution string, filtered bool) []string {
+	var targets []string
+	broken := false
+	for _, p := range projects {
+		if p.FilePath == "" {
+			continue
+		}
+		if p.LoadErr != nil {
+			broken = true
+			continue
+		}
+		targets = append(targets, p.FilePath)
+	}
+	if solution != "" && !filtered && !broken && len(targets) > 1 {
+		return []string{solution}
+	}
+	return targets
+}
+
+// sdkStatus reports why dotnet cannot run against projects — no SDK at all,
+// or a target framework no installed SDK can build. Returns nil when every
+// project is buildable.
+func (m *App) sdkStatus(projects []*ParsedProject) bubble_tea.Cmd {
+	if m.ctx.SDKErr != nil {
+		return m.setStatus("✗ "+m.ctx.SDKErr.Error(), true)
+	}
+	for _, p := range projects {
+		if p.LoadErr != nil {
+			continue
+		}
+		if missing := missingSDKTargets(m.ctx.SDKs, p); len(missing) > 0 {
+			return m.setStatus(fmt.Sprintf("✗ %s targets %s; newest SDK is %s", p.FileName, strings.Join(missing, ", "), m.ctx.SDKs[0].Version), true)
 		}
-		return restoreResultMsg{err: lastErr}
 	}
+	return nil
 }
 
 func (m *App) removePackage(pkgName string) bubble_tea.Cmd {
 	targetProject := m.selectedProject() // nil = all projects
+	if cmd := m.readOnlyProjectStatus(targetProject); cmd != nil {
+		return cmd
+	}
 	var toWrite []string
 	var propsSource string
 
@@ -178,6 +277,9 @@ func (m *App) removePackage(pkgName string) bubble_tea.Cmd {
 	}
 
 	for _, p := range projects {
+		if p.Legacy {
+			continue
+		}
 		for ref := range p.Packages {
 			if strings.EqualFold(ref.Name, pkgName) {
 				sourceFile := p.SourceFileForPackage(pkgName)
@@ -198,7 +300,7 @@ func (m *App) removePackage(pkgName string) bubble_tea.Cmd {
 	// every other project that inherited it from the same file.
 	if propsSource != "" {
 		for _, p := range m.allProjects() {
-			if p.SourceFileForPackage(pkgName) != propsSource {
+			if p.Legacy || p.SourceFileForPackage(pkgName) != propsSource {
 				continue
 			}
 			for ref := range p.Packages {
@@ -240,18 +342,110 @@ func (m *App) removePackage(pkgName string) bubble_tea.Cmd {
 		return nil
 	}
 	return func() bubble_tea.Msg {
+		rec := ActionRecord{Action: "remove", Package: pkgName}
 		seen := make(map[string]bool)
 		for _, fp := range toWrite {
 			if seen[fp] {
 				continue
 			}
 			seen[fp] = true
+			rec.Files = append(rec.Files, fp)
 			logDebug("RemovePackageReference: %s from %s", pkgName, fp)
 			if err := RemovePackageReference(fp, pkgName); err != nil {
 				logWarn("remove failed for %s: %v", fp, err)
+				recordAction(rec, err)
 				return writeResultMsg{err: err}
 			}
 		}
+		recordAction(rec, nil)
 		return writeResultMsg{err: nil}
 	}
 }
+
+// versionConflictFor returns the first version conflict for row's package in
+// the selected project, or in any project when "All Projects" is selected.
+func (m *App) versionConflictFor(row packageRow) (*ParsedProject, *VersionConflict) {
+	projects := m.ctx.ParsedProjects
+	if sel := m.selectedProject(); sel != nil {
+		projects = []*ParsedProject{sel}
+	}
+	for _, p := range projects {
+		for i, c := range p.VersionConflicts {
+			if strings.EqualFold(c.Package, row.ref.Name) {
+				return p, &p.VersionConflicts[i]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// openConflictFix asks for confirmation before removing the redundant
+// definition of a package that is versioned in two files.
+func (m *App) openConflictFix(row packageRow) bubble_tea.Cmd {
+	p, c := m.versionConflictFor(row)
+	if c == nil {
+		return m.setStatus(row.ref.Name+" has no version conflict", false)
+	}
+	if cmd := m.readOnlyProjectStatus(p); cmd != nil {
+		return cmd
+	}
+	// A shared props file may still be the effective definition for another
+	// project; removing it there would drop the package from that project.
+	key := strings.ToLower(c.Package)
+	for _, other := range m.allProjects() {
+		if other != p && other.PackageSources[key] == c.RedundantFile {
+			return m.setStatus("✗ "+filepath.Base(c.RedundantFile)+" still supplies "+c.Package+" to "+other.FileName, true)
+		}
+	}
+	m.confirmFix = newConfirmConflictFix(m, *c, p)
+	m.ctx.StatusLine = ""
+	return nil
+}
+
+// removeRedundantDefinition deletes the overridden definition of a conflict
+// from its file and, once that succeeded, drops the conflict from every
+// project that reported it.
+func (m *App) removeRedundantDefinition(c VersionConflict, project *ParsedProject) bubble_tea.Cmd {
+	logInfo("removeRedundantDefinition: %s %s from %s (kept %s in %s)", c.Package, c.RedundantVersion, c.RedundantFile, c.EffectiveVersion, project.FileName)
+	return func() bubble_tea.Msg {
+		err := RemovePackageReference(c.RedundantFile, c.Package)
+		recordAction(ActionRecord{Action: "remove", Package: c.Package, Version: c.RedundantVersion, Files: []string{c.RedundantFile}}, err)
+		if err != nil {
+			logWarn("remove failed for %s: %v", c.RedundantFile, err)
+			return writeResultMsg{err: err}
+		}
+		return writeResultMsg{apply: func() { m.dropRedundantDefinition(c) }}
+	}
+}
+
+// dropRedundantDefinition removes the conflict c from every project that
+// reported it, along with the reference its redundant definition added. Each
+// project's own conflict decides which reference that is, since the
+// effective version can differ between projects sharing the redundant file.
+func (m *App) dropRedundantDefinition(c VersionConflict) {
+	for _, p := range m.allProjects() {
+		var own *VersionConflict
+		kept := p.VersionConflicts[:0]
+		for _, pc := range p.VersionConflicts {
+			if strings.EqualFold(pc.Package, c.Package) && pc.RedundantFile == c.RedundantFile {
+				own = &pc
+				continue
+			}
+			kept = append(kept, pc)
+		}
+		p.VersionConflicts = kept
+		// With the same version in both files the two definitions made one
+		// reference, which is the effective one and stays.
+		if own == nil || own.RedundantVersion == own.EffectiveVersion {
+			continue
+		}
+		for ref := range p.Packages {
+			if strings.EqualFold(ref.Name, c.Package) && ref.Version.Raw == own.RedundantVersion {
+				p.Packages.Remove(ref)
+			}
+		}
+	}
+	m.rebuildPackageRows()
+	m.clampOffset()
+	m.refreshDetail()
+}
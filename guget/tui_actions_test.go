@@ -0,0 +1,73 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveRedundantDefinition_UpdatesModelOnlyAfterWrite(t *testing.T) {
+	dir := t.TempDir()
+	props := filepath.Join(dir, "Directory.Build.props")
+	project := testProjectWithPackages(filepath.Join(dir, "App.csproj"), "Polly")
+	project.Packages.Add(PackageReference{Name: "Polly", Version: ParseSemVer("2.0.0")})
+	// A second project whose effective version is the first one's redundant
+	// version; its reference must survive.
+	other := testProjectWithPackages(filepath.Join(dir, "Other.csproj"))
+	other.Packages.Add(PackageReference{Name: "Polly", Version: ParseSemVer("2.0.0")})
+	conflict := VersionConflict{
+		Package:          "Polly",
+		EffectiveFile:    project.FilePath,
+		EffectiveVersion: "1.0.0",
+		RedundantFile:    props,
+		RedundantVersion: "2.0.0",
+	}
+	project.VersionConflicts = []VersionConflict{conflict}
+	other.VersionConflicts = []VersionConflict{{
+		Package:          "Polly",
+		EffectiveFile:    other.FilePath,
+		EffectiveVersion: "2.0.0",
+		RedundantFile:    props,
+		RedundantVersion: "2.0.0",
+	}}
+	app := &App{
+		ctx: &AppContext{
+			ParsedProjects:  []*ParsedProject{project, other},
+			Results:         map[string]nugetResult{},
+			PendingPackages: NewSet[string](),
+		},
+	}
+
+	// The props file doesn't exist yet, so the write fails.
+	msg := app.removeRedundantDefinition(conflict, project)().(writeResultMsg)
+	if msg.err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if len(project.VersionConflicts) != 1 || project.Packages.Len() != 2 {
+		t.Fatalf("expected the model untouched after a failed write, got %d conflicts and %d refs", len(project.VersionConflicts), project.Packages.Len())
+	}
+
+	mustWriteFile(t, props, `<Project>
+  <ItemGroup>
+    <PackageReference Include="Polly" Version="2.0.0" />
+  </ItemGroup>
+</Project>
+`)
+	msg = app.removeRedundantDefinition(conflict, project)().(writeResultMsg)
+	if msg.err != nil {
+		t.Fatalf("remove: %v", msg.err)
+	}
+	if len(project.VersionConflicts) != 1 {
+		t.Fatal("expected the model to wait for the write result")
+	}
+	msg.apply()
+
+	if len(project.VersionConflicts) != 0 || len(other.VersionConflicts) != 0 {
+		t.Fatalf("expected the conflict dropped everywhere, got %v and %v", project.VersionConflicts, other.VersionConflicts)
+	}
+	if refs := project.Packages.ToSlice(); len(refs) != 1 || refs[0].Version.String() != "1.0.0" {
+		t.Fatalf("expected only the effective reference left, got %v", refs)
+	}
+	if refs := other.Packages.ToSlice(); len(refs) != 1 || refs[0].Version.String() != "2.0.0" {
+		t.Fatalf("expected the other project's reference kept, got %v", refs)
+	}
+}
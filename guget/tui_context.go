@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	bubbles_spinner "charm.land/bubbles/v2/spinner"
 )
 
@@ -12,12 +14,19 @@ type AppContext struct {
 	Height int
 
 	// Shared data
-	ParsedProjects []*ParsedProject
-	PropsProjects  []*ParsedProject
-	NugetServices  []*NugetService
-	Results        map[string]nugetResult
-	Sources        []NugetSource
-	SourceMapping  *PackageSourceMapping
+	ParsedProjects  []*ParsedProject
+	PropsProjects   []*ParsedProject
+	Solution        string // the .sln/.slnx the projects came from; restore runs against it
+	NugetServices   []*NugetService
+	Results         map[string]nugetResult
+	Sources         []NugetSource
+	SourceMapping   *PackageSourceMapping
+	SourceScopes    sourceScopes // which sources each project resolves through
+	PackageFolders  PackageFolders
+	SourceConflicts []SourceConflict // duplicate names/URLs dropped while reading nuget.config
+	SourceFailures  []sourceFailure  // sources that failed to initialise; r in the sources overlay retries
+	SDKs            []DotnetSDK
+	SDKErr          error
 
 	// Loading state
 	Loading         bool
@@ -26,6 +35,16 @@ type AppContext struct {
 	PendingPackages Set[string]
 	Spinner         bubbles_spinner.Model
 	Restoring       bool
+	ReadOnly        bool // --read-only: every write and exec action is refused
+	Config          UserConfig
+	Renames         packageRenames            // built-in renames plus the "renames" setting
+	Rules           packageRules              // the "packages" setting
+	Notes           packageNotes              // .guget/notes.json
+	TransitiveVulns map[string]transitiveScan // last V scan by project path; nil until run
+	LoadDeadline    time.Duration             // --load-deadline; 0 = wait for every package
+	Prerelease      bool                      // P / --prerelease: latest versions and search include pre-releases
+	NoEnrich        bool                      // --no-enrich: never look packages up on nuget.org
+	Enriching       bool                      // the advisory pass after a load (nuget.org, then GitHub) is running
 	Reloading       bool
 
 	// Status bar
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// favorites are the packages and projects starred with *, remembered per
+// project directory alongside the panel layout.
+type favorites struct {
+	packages Set[string] // lowercased package ids
+	projects Set[string] // projectStarKey paths
+	only     bool        // ctrl+s: show only starred items
+}
+
+func newFavorites(st layoutState) favorites {
+	f := favorites{packages: NewSet[string](), projects: NewSet[string]()}
+	for _, id := range st.StarredPackages {
+		f.packages.Add(strings.ToLower(id))
+	}
+	for _, path := range st.StarredProjects {
+		f.projects.Add(path)
+	}
+	return f
+}
+
+// projectStarKey identifies p across sessions and machines: its path
+// relative to the project directory, with forward slashes.
+func (m *App) projectStarKey(p *ParsedProject) string {
+	rel, err := filepath.Rel(m.projectDir, p.FilePath)
+	if err != nil {
+		rel = p.FilePath
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (m *App) packageStarred(id string) bool {
+	return m.stars.packages.Contains(strings.ToLower(id))
+}
+
+func (m *App) projectStarred(p *ParsedProject) bool {
+	return p != nil && m.stars.projects.Contains(m.projectStarKey(p))
+}
+
+// setProjectItems replaces the full project list and shows the part the
+// starred-only filter lets through.
+func (m *App) setProjectItems(items []projectItem) {
+	m.projects.all = items
+	m.filterProjectItems()
+}
+
+// filterProjectItems rebuilds the visible project list from the full one,
+// keeping All Projects first, and keeps the selected project when it is
+// still listed.
+func (m *App) filterProjectItems() {
+	selectedPath := ""
+	if sel := m.selectedProject(); sel != nil {
+		selectedPath = sel.FilePath
+	}
+	if !m.stars.only {
+		m.projects.items = m.projects.all
+	} else {
+		m.projects.items = slices.DeleteFunc(slices.Clone(m.projects.all), func(item projectItem) bool {
+			return item.project != nil && !m.projectStarred(item.project)
+		})
+	}
+	m.selectProjectByPath(selectedPath)
+}
+
+// toggleStar stars or unstars the selected project or package, depending on
+// which panel has focus.
+func (m *App) toggleStar() bubble_tea.Cmd {
+	var name string
+	var starred bool
+	switch m.focus {
+	case focusProjects:
+		p := m.selectedProject()
+		if p == nil {
+			return nil
+		}
+		key := m.projectStarKey(p)
+		if starred = !m.stars.projects.Contains(key); starred {
+			m.stars.projects.Add(key)
+		} else {
+			m.stars.projects.Remove(key)
+		}
+		name = p.FileName
+		m.filterProjectItems()
+	case focusPackages, focusDetail:
+		if m.packages.cursor >= len(m.packages.rows) {
+			return nil
+		}
+		name = m.packages.rows[m.packages.cursor].ref.Name
+		key := strings.ToLower(name)
+		if starred = !m.stars.packages.Contains(key); starred {
+			m.stars.packages.Add(key)
+		} else {
+			m.stars.packages.Remove(key)
+		}
+	default:
+		return nil
+	}
+	m.rebuildPackageRows()
+	m.selectPackageByName(name)
+	m.refreshDetail()
+	status := "★ Starred " + name
+	if !starred {
+		status = "Unstarred " + name
+	}
+	return bubble_tea.Batch(m.persistLayout(), m.setStatus(status, false))
+}
+
+// toggleStarredOnly switches between every item and only the starred ones.
+func (m *App) toggleStarredOnly() bubble_tea.Cmd {
+	m.stars.only = !m.stars.only
+	selected := ""
+	if m.packages.cursor < len(m.packages.rows) {
+		selected = m.packages.rows[m.packages.cursor].ref.Name
+	}
+	m.filterProjectItems()
+	m.rebuildPackageRows()
+	m.selectPackageByName(selected)
+	m.refreshDetail()
+	if m.stars.only {
+		return m.setStatus("★ Showing starred packages and projects (ctrl+s to show all)", false)
+	}
+	return m.setStatus("Showing all packages and projects", false)
+}
+
+// starredLayout fills st's starred lists, sorted so the layout file diffs
+// cleanly.
+func (m *App) starredLayout(st *layoutState) {
+	st.StarredPackages = m.stars.packages.ToSlice()
+	st.StarredProjects = m.stars.projects.ToSlice()
+	slices.Sort(st.StarredPackages)
+	slices.Sort(st.StarredProjects)
+}
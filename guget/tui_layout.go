@@ -15,6 +15,15 @@ func (m *App) footerKeys() []kv {
 		}
 	}
 
+	if m.packages.filtering {
+		return []kv{
+			{"type", "filter by name"},
+			{"↑↓", "nav"},
+			{"enter", "keep filter"},
+			{"esc", "clear"},
+		}
+	}
+
 	// Main screen — varies by focused panel.
 	isAllProjects := m.selectedProject() == nil
 
@@ -26,7 +35,11 @@ func (m *App) footerKeys() []kv {
 			{"^r", "reload"},
 			{"r/R", "restore/all"},
 			{"T", "deps"},
+			{"G", "project refs"},
+			{"t", "retarget"},
+			{"m", "metadata"},
 			{"/", "add"},
+			{"!", "issues"},
 			{"?", "help"},
 			{"esc/q", "quit"},
 		}
@@ -40,6 +53,7 @@ func (m *App) footerKeys() []kv {
 				{"v", "version"},
 				{"d", "del"},
 				{"o/O", "sort/dir"},
+				{"i/I", "filter/view"},
 				{"t/T", "deps"},
 				{"n", "notes"},
 				{"^r", "reload"},
@@ -56,6 +70,7 @@ func (m *App) footerKeys() []kv {
 			{"v", "version"},
 			{"d", "del"},
 			{"o/O", "sort/dir"},
+			{"i/I", "filter/view"},
 			{"t/T", "deps"},
 			{"n", "notes"},
 			{"^r", "reload"},
@@ -71,6 +86,7 @@ func (m *App) footerKeys() []kv {
 			{"↑↓", "scroll"},
 			{"v", "version"},
 			{"n", "notes"},
+			{"M", "readme"},
 			{"^r", "reload"},
 			{"r/R", "restore/all"},
 			{"?", "help"},
@@ -90,24 +106,42 @@ func (m *App) footerKeys() []kv {
 	return []kv{{"?", "help"}, {"esc/q", "quit"}}
 }
 
-func (m *App) footerLines() int {
-	keys := m.footerKeys()
-	w := m.layoutWidth() - 4
-	lines, curW := 1, 0
-	for _, pair := range keys {
-		ew := lipgloss.Width(pair.k) + 1 + lipgloss.Width(pair.v)
-		needed := ew
-		if curW > 0 {
-			needed += 3 // matches renderFooter() sepW
+// footerSep separates keybinds on a footer row.
+const footerSep = " · "
+
+// footerRows wraps the footer keybinds into rows that fit the footer's
+// width; renderFooter, footerLines, and footerKeyAt all lay them out this
+// way.
+func (m *App) footerRows() [][]kv {
+	w := m.layoutWidth() - 4 // padding
+	var rows [][]kv
+	var cur []kv
+	curW := 0
+	for _, pair := range m.footerKeys() {
+		entryW := footerEntryWidth(pair)
+		needed := entryW
+		if len(cur) > 0 {
+			needed += lipgloss.Width(footerSep)
 		}
-		if curW+needed > w && curW > 0 {
-			lines++
-			curW = ew
-		} else {
-			curW += needed
+		if curW+needed > w && len(cur) > 0 {
+			rows = append(rows, cur)
+			cur, curW, needed = nil, 0, entryW
 		}
+		cur = append(cur, pair)
+		curW += needed
+	}
+	if len(cur) > 0 {
+		rows = append(rows, cur)
 	}
-	return lines + 1 // +1 for status row
+	return rows
+}
+
+func footerEntryWidth(pair kv) int {
+	return lipgloss.Width(pair.k) + 1 + lipgloss.Width(pair.v)
+}
+
+func (m *App) footerLines() int {
+	return max(1, len(m.footerRows())) + 1 // +1 for status row
 }
 
 // bodyOuterHeight returns the outer height for each main panel.
@@ -131,8 +165,8 @@ func (m *App) panelContentHeight() int {
 }
 
 func (m *App) packageListHeight() int {
-	// content height minus column header (1) + divider (1)
-	return imax(1, m.panelContentHeight()-2)
+	// content height minus column header (1) + divider (1), and the filter line
+	return imax(1, m.panelContentHeight()-2-m.packageFilterLines())
 }
 
 func (m *App) projectListHeight() int {
@@ -209,45 +243,23 @@ func (m *App) overlayHeight() int {
 }
 
 func (m *App) renderFooter() string {
-	keys := m.footerKeys()
-
-	w := m.layoutWidth() - 4 // padding
+	sep := styleMuted.Render(footerSep)
 	var lines []string
-	var cur []string
-	curW := 0
-	sep := styleMuted.Render(" · ")
-	sepW := 3
-
-	for _, pair := range keys {
-		k := styleAccentBold.Render(pair.k)
-		v := styleSubtle.Render(pair.v)
-		entry := k + " " + v
-		entryW := lipgloss.Width(pair.k) + 1 + lipgloss.Width(pair.v)
-
-		needed := entryW
-		if len(cur) > 0 {
-			needed += sepW
-		}
-		if curW+needed > w && len(cur) > 0 {
-			lines = append(lines, strings.Join(cur, sep))
-			cur = nil
-			curW = 0
-		}
-		cur = append(cur, entry)
-		if curW > 0 {
-			curW += sepW
+	for _, row := range m.footerRows() {
+		entries := make([]string, len(row))
+		for i, pair := range row {
+			entries[i] = styleAccentBold.Render(pair.k) + " " + styleSubtle.Render(pair.v)
 		}
-		curW += entryW
-	}
-	if len(cur) > 0 {
-		lines = append(lines, strings.Join(cur, sep))
+		lines = append(lines, strings.Join(entries, sep))
 	}
 	keybinds := strings.Join(lines, "\n")
 
 	// Status line — always reserve the row so height is stable.
 	statusStr := ""
 	if m.ctx.Restoring {
-		statusStr = m.ctx.Spinner.View() + styleAccent.Render(" restoring...")
+		statusStr = m.ctx.Spinner.View() + styleAccent.Render(
+			fmt.Sprintf(" restoring... (%d/%d) · D to watch", len(m.restoreLive.finished), len(m.restoreLive.targets)),
+		)
 	} else if m.ctx.Reloading {
 		if m.ctx.LoadingTotal > 0 {
 			statusStr = m.ctx.Spinner.View() + styleAccent.Render(
@@ -262,6 +274,15 @@ func (m *App) renderFooter() string {
 			s = styleRed
 		}
 		statusStr = s.Render(m.ctx.StatusLine)
+	} else if m.ctx.Enriching {
+		statusStr = m.ctx.Spinner.View() + styleMuted.Render(" checking for advisories...")
+	}
+	if m.ctx.ReadOnly {
+		badge := styleYellowBold.Render("READ-ONLY")
+		if statusStr != "" {
+			badge += styleMuted.Render(" · ")
+		}
+		statusStr = badge + statusStr
 	}
 
 	return styleFooterBar.
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	bubble_tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// handleMouseClick presses the footer keybind under a left click, or
+// focuses the panel it lands on and selects the project or package row
+// there. Clicks inside overlays are ignored; they are driven by the keys
+// their footers list.
+func (m *App) handleMouseClick(msg bubble_tea.MouseClickMsg) bubble_tea.Cmd {
+	mouse := msg.Mouse()
+	if mouse.Button != bubble_tea.MouseLeft || m.ctx.Width == 0 || m.ctx.Loading {
+		return nil
+	}
+	if key, ok := m.footerKeyAt(mouse.X, mouse.Y); ok {
+		_, cmd := m.Update(key)
+		return cmd
+	}
+	if m.anyOverlayActive() {
+		return nil
+	}
+
+	bodyH := m.bodyOuterHeight()
+	if mouse.Y >= bodyH {
+		if m.ctx.ShowLogs && mouse.Y < bodyH+logPanelOuterHeight {
+			m.focus = focusLog
+		}
+		return nil
+	}
+	leftW, midW, _ := m.panelWidths()
+	switch {
+	case mouse.X < leftW:
+		m.focus = focusProjects
+		m.clickProjectRow(mouse.Y)
+	case mouse.X < leftW+midW:
+		m.focus = focusPackages
+		m.clickPackageRow(mouse.Y)
+	default:
+		m.focus = focusDetail
+	}
+	return nil
+}
+
+// panelListTop is the first list row inside a main panel: top border, then
+// the title or column header, then a divider.
+const panelListTop = 3
+
+// clickProjectRow selects the project drawn at screen row y. Each project
+// takes three rows: title, description, and a gap.
+func (m *App) clickProjectRow(y int) {
+	rel := y - panelListTop
+	if rel < 0 || rel%3 == 2 || rel/3 >= m.projectListHeight() {
+		return
+	}
+	i := m.projects.scroll + rel/3
+	if i >= len(m.projects.items) || i == m.projects.cursor {
+		return
+	}
+	m.projects.cursor = i
+	m.clampProjectOffset()
+	m.packages.cursor = 0
+	m.packages.scroll = 0
+	m.rebuildPackageRows()
+	m.refreshDetail()
+}
+
+// clickPackageRow selects the package drawn at screen row y.
+func (m *App) clickPackageRow(y int) {
+	if sel := m.selectedProject(); sel != nil && sel.LoadErr != nil {
+		return
+	}
+	rel := y - panelListTop - m.packageFilterLines()
+	if rel < 0 || rel >= m.packageListHeight() {
+		return
+	}
+	i := m.packages.scroll + rel
+	if i >= len(m.packages.rows) || i == m.packages.cursor {
+		return
+	}
+	m.packages.cursor = i
+	m.clampOffset()
+	m.refreshDetail()
+}
+
+// handleMouseWheel scrolls the focused panel, or the open overlay, as the
+// arrow keys do.
+func (m *App) handleMouseWheel(msg bubble_tea.MouseWheelMsg) bubble_tea.Cmd {
+	var key bubble_tea.KeyPressMsg
+	switch msg.Mouse().Button {
+	case bubble_tea.MouseWheelUp:
+		key = bubble_tea.KeyPressMsg{Code: bubble_tea.KeyUp}
+	case bubble_tea.MouseWheelDown:
+		key = bubble_tea.KeyPressMsg{Code: bubble_tea.KeyDown}
+	default:
+		return nil
+	}
+	_, cmd := m.Update(key)
+	return cmd
+}
+
+// footerKeyAt returns the key of the footer keybind drawn at x, y. A label
+// such as "u/U" offers one key per part; a click on its description
+// presses the first.
+func (m *App) footerKeyAt(x, y int) (bubble_tea.KeyPressMsg, bool) {
+	// The footer sits at the bottom: top border, status row, keybind rows.
+	row := y - (m.ctx.Height - m.footerLines() - 1) - 2
+	rows := m.footerRows()
+	if row < 0 || row >= len(rows) {
+		return bubble_tea.KeyPressMsg{}, false
+	}
+	at := 2 // styleFooterBar padding
+	for _, pair := range rows[row] {
+		end := at + footerEntryWidth(pair)
+		if x >= at && x < end {
+			return footerEntryKey(pair.k, x-at)
+		}
+		at = end + lipgloss.Width(footerSep)
+	}
+	return bubble_tea.KeyPressMsg{}, false
+}
+
+// footerEntryKey picks the key for a click at offset x into a keybind
+// label followed by its description.
+func footerEntryKey(label string, x int) (bubble_tea.KeyPressMsg, bool) {
+	parts := strings.Split(label, "/")
+	if slices.Contains(parts, "") {
+		parts = []string{label} // the "/" key itself
+	}
+	if len(parts) > 1 && x < lipgloss.Width(label) {
+		at := 0
+		for _, part := range parts {
+			w := lipgloss.Width(part)
+			if x < at+w {
+				return keyFromLabel(part)
+			}
+			at += w + 1
+		}
+		return bubble_tea.KeyPressMsg{}, false
+	}
+	return keyFromLabel(parts[0])
+}
+
+// keyFromLabel turns a footer key label — "u", "enter", "^r", "↑" — into
+// the key press it stands for.
+func keyFromLabel(label string) (bubble_tea.KeyPressMsg, bool) {
+	label = strings.TrimSpace(label)
+	if rest, ok := strings.CutPrefix(label, "^"); ok && utf8.RuneCountInString(rest) == 1 {
+		r, _ := utf8.DecodeRuneInString(rest)
+		return bubble_tea.KeyPressMsg{Code: r, Mod: bubble_tea.ModCtrl}, true
+	}
+	if rest, ok := strings.CutPrefix(label, "ctrl+"); ok && utf8.RuneCountInString(rest) == 1 {
+		r, _ := utf8.DecodeRuneInString(rest)
+		return bubble_tea.KeyPressMsg{Code: r, Mod: bubble_tea.ModCtrl}, true
+	}
+	switch label {
+	case "enter":
+		return bubble_tea.KeyPressMsg{Code: bubble_tea.KeyEnter}, true
+	case "esc":
+		return bubble_tea.KeyPressMsg{Code: bubble_tea.KeyEscape}, true
+	case "tab":
+		return bubble_tea.KeyPressMsg{Code: bubble_tea.KeyTab}, true
+	case "shift+tab":
+		return bubble_tea.KeyPressMsg{Code: bubble_tea.KeyTab, Mod: bubble_tea.ModShift}, true
+	case "space":
+		return bubble_tea.KeyPressMsg{Code: bubble_tea.KeySpace, Text: " "}, true
+	case "↑":
+		return bubble_tea.KeyPressMsg{Code: bubble_tea.KeyUp}, true
+	case "↓":
+		return bubble_tea.KeyPressMsg{Code: bubble_tea.KeyDown}, true
+	case "←":
+		return bubble_tea.KeyPressMsg{Code: bubble_tea.KeyLeft}, true
+	case "→":
+		return bubble_tea.KeyPressMsg{Code: bubble_tea.KeyRight}, true
+	}
+	if utf8.RuneCountInString(label) == 1 {
+		r, _ := utf8.DecodeRuneInString(label)
+		return bubble_tea.KeyPressMsg{Code: r, Text: label}, true
+	}
+	return bubble_tea.KeyPressMsg{}, false
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	bubbles_textinpute "charm.land/bubbles/v2/textinput"
+	bubble_tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+func newPackageFilterInput() bubbles_textinpute.Model {
+	ti := bubbles_textinpute.New()
+	ti.Prompt = ""
+	ti.Placeholder = "package name, e.g. json or msext"
+	ti.CharLimit = 100
+	return ti
+}
+
+// openPackageFilter focuses the packages panel and starts editing the
+// filter shown above its rows.
+func (m *App) openPackageFilter() bubble_tea.Cmd {
+	m.focus = focusPackages
+	m.packages.filtering = true
+	m.packages.filter.CursorEnd()
+	m.clampOffset()
+	return m.packages.filter.Focus()
+}
+
+// handlePackageFilterKey edits the filter while it has the keyboard. ↑/↓
+// still move through the narrowed rows; enter hands the keys back to the
+// panel with the filter kept, so actions apply to the filtered view; esc
+// clears it.
+func (m *App) handlePackageFilterKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return bubble_tea.Quit
+	case "esc":
+		m.clearPackageFilter()
+		return nil
+	case "enter":
+		m.packages.filtering = false
+		m.packages.filter.Blur()
+		if strings.TrimSpace(m.packages.filter.Value()) == "" {
+			m.packages.filter.Reset() // any view stays
+			m.rebuildPackageRows()
+		}
+		return nil
+	case "up", "down":
+		return m.handleKey(msg)
+	}
+	prev := m.packages.filter.Value()
+	var cmd bubble_tea.Cmd
+	m.packages.filter, cmd = m.packages.filter.Update(msg)
+	if m.packages.filter.Value() != prev {
+		m.packages.cursor = 0
+		m.packages.scroll = 0
+		m.rebuildPackageRows()
+		m.refreshDetail()
+	}
+	return cmd
+}
+
+// clearPackageFilter drops the name filter and any view and shows every
+// package again, keeping the selected one.
+func (m *App) clearPackageFilter() {
+	selected := ""
+	if m.packages.cursor < len(m.packages.rows) {
+		selected = m.packages.rows[m.packages.cursor].ref.Name
+	}
+	m.packages.filtering = false
+	m.packages.filter.Blur()
+	m.packages.filter.Reset()
+	m.packages.view, m.packages.viewSource = viewAll, ""
+	m.rebuildPackageRows()
+	for i, row := range m.packages.rows {
+		if row.ref.Name == selected {
+			m.packages.cursor = i
+			break
+		}
+	}
+	m.clampOffset()
+	m.refreshDetail()
+}
+
+// packageFiltered reports whether the name filter or a view narrows the list.
+func (m *App) packageFiltered() bool {
+	return m.packages.filter.Value() != "" || m.packages.view != viewAll
+}
+
+// packageFilterLines is the number of rows the filter line takes above the
+// column header: one while it is being edited or narrows the list.
+func (m *App) packageFilterLines() int {
+	if m.packages.filtering || m.packageFiltered() {
+		return 1
+	}
+	return 0
+}
+
+// renderPackageFilter draws the filter line for a panel innerW wide.
+func (m *App) renderPackageFilter(innerW int) string {
+	label := styleAccentBold.Render("Filter: ")
+	if m.packages.view != viewAll {
+		label += styleYellow.Render("["+m.packages.view.label(m.packages.viewSource)+"]") + " "
+	}
+	count := styleMuted.Render(fmt.Sprintf("  %d of %d", len(m.packages.rows), m.packages.unfiltered))
+	if !m.packages.filtering && m.packages.filter.Value() == "" {
+		return label + count
+	}
+	m.packages.filter.SetWidth(max(8, innerW-lipgloss.Width(label)-lipgloss.Width(count)-2))
+	return label + m.packages.filter.View() + count
+}
+
+// cycleView moves the package list to the next view: vulnerable, outdated,
+// deprecated, then each source in turn when packages come from several,
+// and back to all packages.
+func (m *App) cycleView() {
+	var sources []string
+	for _, res := range m.ctx.Results {
+		if res.source != "" && !slices.Contains(sources, res.source) {
+			sources = append(sources, res.source)
+		}
+	}
+	slices.Sort(sources)
+	if len(sources) < 2 {
+		sources = nil
+	}
+
+	p := &m.packages
+	switch {
+	case p.view < viewDeprecated:
+		p.view++
+	case p.view == viewDeprecated && len(sources) > 0:
+		p.view, p.viewSource = viewSource, sources[0]
+	case p.view == viewSource:
+		i := slices.Index(sources, p.viewSource)
+		if i >= 0 && i+1 < len(sources) {
+			p.viewSource = sources[i+1]
+		} else {
+			p.view, p.viewSource = viewAll, ""
+		}
+	default:
+		p.view, p.viewSource = viewAll, ""
+	}
+	p.cursor = 0
+	p.scroll = 0
+	m.rebuildPackageRows()
+	m.refreshDetail()
+}
+
+// packageFilterMatch reports whether name matches filter, ignoring case:
+// as a substring, or with the filter's characters appearing in order, so
+// "msext" finds Microsoft.Extensions.*.
+func packageFilterMatch(name, filter string) bool {
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	if filter == "" {
+		return true
+	}
+	name = strings.ToLower(name)
+	if strings.Contains(name, filter) {
+		return true
+	}
+	rest := filter
+	for _, r := range name {
+		if rest == "" {
+			break
+		}
+		if strings.HasPrefix(rest, string(r)) {
+			rest = rest[len(string(r)):]
+		}
+	}
+	return rest == ""
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
@@ -23,7 +26,7 @@ func (m *App) startInitialLoad() {
 	if m.ctx.Results == nil {
 		m.ctx.Results = make(map[string]nugetResult, len(names))
 	}
-	m.startPackageFetch(names, true)
+	m.startPackageFetch(names, true, false)
 	m.rebuildPackageRows()
 	m.refreshDetail()
 }
@@ -56,7 +59,7 @@ func (m *App) requestReload(req reloadRequestedMsg) {
 	}
 
 	go func() {
-		snapshot, err := loadWorkspace(m.projectDir)
+		snapshot, err := loadWorkspace(m.projectDir, m.solution)
 		m.send(workspaceReloadedMsg{
 			generation: generation,
 			snapshot:   snapshot,
@@ -79,7 +82,7 @@ func (m *App) handleWorkspaceReloaded(msg workspaceReloadedMsg) {
 	}
 
 	currentSourceSig := m.sourceSignature
-	nextSourceSig := workspaceSourceSignature(msg.snapshot.Sources, msg.snapshot.SourceMapping)
+	nextSourceSig := msg.snapshot.Scopes.signature()
 	invalidateAll := currentSourceSig != "" && currentSourceSig != nextSourceSig
 	if invalidateAll {
 		logInfo("NuGet source configuration changed; refreshing all package metadata")
@@ -90,7 +93,7 @@ func (m *App) handleWorkspaceReloaded(msg workspaceReloadedMsg) {
 
 	nextResults, toFetch := planPackageReload(msg.snapshot, m.ctx.Results, invalidateAll)
 	m.ctx.Results = nextResults
-	m.startPackageFetch(toFetch, false)
+	m.startPackageFetch(toFetch, false, false)
 	m.rebuildPackageRows()
 	m.refreshDetail()
 
@@ -112,19 +115,34 @@ func (m *App) applyWorkspaceSnapshot(snapshot *workspaceSnapshot) {
 
 	m.ctx.ParsedProjects = snapshot.ParsedProjects
 	m.ctx.PropsProjects = snapshot.PropsProjects
+	m.ctx.Solution = snapshot.Solution
 	m.ctx.NugetServices = snapshot.NugetServices
 	m.ctx.Sources = snapshot.Sources
 	m.ctx.SourceMapping = snapshot.SourceMapping
-	m.projects.items = buildProjectItems(snapshot.ParsedProjects, snapshot.PropsProjects)
+	m.ctx.SourceScopes = snapshot.Scopes
+	m.ctx.PackageFolders = snapshot.PackageFolders
+	m.ctx.SourceConflicts = snapshot.Conflicts
+	m.ctx.SourceFailures = snapshot.SourceFailures
+	m.ctx.SDKs = snapshot.SDKs
+	m.ctx.SDKErr = snapshot.SDKErr
+	m.setProjectItems(buildProjectItems(snapshot.ParsedProjects, snapshot.PropsProjects))
+	m.loadNotes()
 	m.selectProjectByPath(selectedProjectPath)
 
 	m.rebuildPackageRows()
 	m.selectPackageByName(selectedPackage)
 }
 
+// buildProjectItems lists the projects grouped by workspace repository, then
+// by solution folder, keeping solution order within a folder; projects
+// outside any folder come first.
 func buildProjectItems(parsedProjects []*ParsedProject, propsProjects []*ParsedProject) []projectItem {
 	items := []projectItem{{name: "All Projects", project: nil}}
-	for _, p := range parsedProjects {
+	ordered := slices.Clone(parsedProjects)
+	slices.SortStableFunc(ordered, func(a, b *ParsedProject) int {
+		return cmp.Or(cmp.Compare(a.Repo, b.Repo), cmp.Compare(a.SolutionFolder, b.SolutionFolder))
+	})
+	for _, p := range ordered {
 		items = append(items, projectItem{name: p.FileName, project: p})
 	}
 	for _, p := range propsProjects {
@@ -169,7 +187,8 @@ func (m *App) selectPackageByName(name string) {
 	m.clampOffset()
 }
 
-func (m *App) startPackageFetch(names []string, initial bool) {
+func (m *App) startPackageFetch(names []string, initial, fresh bool) {
+	m.loadFresh = fresh
 	m.ctx.LoadingDone = 0
 	m.ctx.LoadingTotal = len(names)
 	m.ctx.PendingPackages = NewSet[string]()
@@ -188,7 +207,60 @@ func (m *App) startPackageFetch(names []string, initial bool) {
 		return
 	}
 
-	fetchPackageMetadataAsync(m.send, m.workspaceGeneration, m.ctx.NugetServices, m.ctx.SourceMapping, names)
+	fetchPackageMetadataAsync(m.send, m.workspaceGeneration, m.ctx.SourceScopes, names, m.ctx.LoadDeadline, fresh)
+}
+
+// startEnrichment looks up on nuget.org, in the background, the packages
+// the finished load found on other feeds, so their rows gain nuget.org's
+// vulnerability data and links; those nuget.org doesn't know are
+// cross-checked with the GitHub Advisory Database when a token is set.
+// Skipped with --no-enrich and no token.
+func (m *App) startEnrichment() {
+	if m.ctx.NoEnrich && githubAdvisoryToken == "" {
+		return
+	}
+	names := enrichmentCandidates(m.ctx.Results)
+	if len(names) == 0 {
+		return
+	}
+	for _, name := range names {
+		res := m.ctx.Results[name]
+		res.enriched = true
+		m.ctx.Results[name] = res
+	}
+	m.ctx.Enriching = true
+	fetchEnrichmentAsync(m.send, m.workspaceGeneration, m.ctx.SourceScopes, names, m.loadFresh, !m.ctx.NoEnrich)
+}
+
+// applyEnrichment merges one package's nuget.org data into its result.
+func (m *App) applyEnrichment(msg packageEnrichedMsg) {
+	if msg.generation != m.workspaceGeneration {
+		return
+	}
+	res, ok := m.ctx.Results[msg.name]
+	if !ok || res.pkg == nil {
+		return
+	}
+	enrichFromNugetOrg(res.pkg, msg.nugetInfo)
+	m.updatePackageRows(msg.name, false)
+	if m.packages.cursor < len(m.packages.rows) && m.packages.rows[m.packages.cursor].ref.Name == msg.name {
+		m.refreshDetail()
+	}
+}
+
+// applyGitHubAdvisories adds one package's GitHub advisories to its result.
+func (m *App) applyGitHubAdvisories(msg packageAdvisoriesMsg) {
+	if msg.generation != m.workspaceGeneration {
+		return
+	}
+	res, ok := m.ctx.Results[msg.name]
+	if !ok || res.pkg == nil || !applyGitHubAdvisories(res.pkg, msg.vulns) {
+		return
+	}
+	m.updatePackageRows(msg.name, false)
+	if m.packages.cursor < len(m.packages.rows) && m.packages.rows[m.packages.cursor].ref.Name == msg.name {
+		m.refreshDetail()
+	}
 }
 
 func (m *App) finishReloadSuccess() {
@@ -227,6 +299,26 @@ func (m *App) closeReloadUnsafeOverlays() {
 	}
 	m.confirmUpdate.project = nil
 
+	if m.confirmFix.app != nil {
+		m.confirmFix.closeOverlay()
+	}
+	m.confirmFix.project = nil
+
+	if m.failures.app != nil {
+		m.failures.closeOverlay()
+	}
+	m.failures.groups = nil
+
+	if m.confirmTyped.app != nil {
+		m.confirmTyped.closeOverlay()
+	}
+	m.confirmTyped.run = nil
+
+	if m.security.app != nil {
+		m.security.closeOverlay()
+	}
+	m.security.project = nil
+
 	if m.locationPick.app != nil {
 		m.locationPick.closeOverlay()
 	}
@@ -271,3 +363,56 @@ func formatReloadPaths(rootDir string, paths []string) string {
 	}
 	return strings.Join(parts, ", ")
 }
+
+// retryFailedPackages refetches every package whose last load ended in an
+// error (including load-deadline timeouts) without reloading the workspace.
+func (m *App) retryFailedPackages() tea.Cmd {
+	var names []string
+	for name, res := range m.ctx.Results {
+		if res.err != nil {
+			names = append(names, name)
+		}
+	}
+	return m.retryPackages(names)
+}
+
+// retryPackages drops the cached results for names and fetches them again.
+func (m *App) retryPackages(names []string) tea.Cmd {
+	if m.ctx.Loading || m.ctx.Reloading {
+		return m.setStatus("Still loading — try again when it finishes", true)
+	}
+	if len(names) == 0 {
+		return m.setStatus("No failed packages to retry", false)
+	}
+	names = slices.Clone(names)
+	sort.Strings(names)
+	m.refetchPackages(names, false)
+	logInfo("Retrying %d failed package(s)", len(names))
+	return m.setStatus(fmt.Sprintf("Retrying %d failed package(s)…", len(names)), false)
+}
+
+// refreshSelectedPackage reloads the selected package straight from its
+// sources, revalidating anything the on-disk cache holds for it.
+func (m *App) refreshSelectedPackage() tea.Cmd {
+	if m.ctx.Loading || m.ctx.Reloading {
+		return m.setStatus("Still loading — try again when it finishes", true)
+	}
+	if m.packages.cursor >= len(m.packages.rows) {
+		return nil
+	}
+	name := m.packages.rows[m.packages.cursor].ref.Name
+	m.refetchPackages([]string{name}, true)
+	logInfo("Refreshing %s from its sources", name)
+	return m.setStatus("Refreshing "+name+"…", false)
+}
+
+func (m *App) refetchPackages(names []string, fresh bool) {
+	for _, name := range names {
+		delete(m.ctx.Results, name)
+	}
+	m.startPackageFetch(names, false, fresh)
+	for _, name := range names {
+		m.updatePackageRows(name, false)
+	}
+	m.refreshDetail()
+}
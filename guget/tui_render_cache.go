@@ -0,0 +1,93 @@
+package main
+
+import (
+	bubbles_spinner "charm.land/bubbles/v2/spinner"
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// bodyCacheKey identifies one rendering of the three main panels. gen is
+// bumped whenever a message may have changed what the panels show; the rest
+// covers layout changes that don't go through Update state.
+type bodyCacheKey struct {
+	gen                      int
+	left, mid, right, height int
+	focus                    focusPanel
+}
+
+// bodyCache memoizes the joined projects/packages/detail panels so messages
+// that only touch the footer or log panel (spinner ticks, log lines, log
+// scrolling) don't re-render and re-measure every package row.
+type bodyCache struct {
+	key   bodyCacheKey
+	view  string
+	valid bool
+}
+
+func (c *bodyCache) get(key bodyCacheKey, render func() string) string {
+	if c.valid && c.key == key {
+		return c.view
+	}
+	c.key, c.view, c.valid = key, render(), true
+	return c.view
+}
+
+// panelNeutral reports whether msg is known not to change anything the main
+// panels render. Anything not listed here invalidates the body cache.
+func (m *App) panelNeutral(msg bubble_tea.Msg) bool {
+	switch msg := msg.(type) {
+	case bubbles_spinner.TickMsg:
+		// The spinner only animates in the footer, and in the project list
+		// while a restore runs; the detail panel bakes its frame in at
+		// refreshDetail time.
+		return !m.ctx.Restoring
+	case logLineMsg, restoreLineMsg:
+		return true
+	case bubble_tea.MouseReleaseMsg, bubble_tea.MouseMotionMsg:
+		return true
+	case bubble_tea.KeyMsg:
+		if o := m.activeOverlay(); o != nil {
+			// Typing into an overlay's text input only changes the overlay.
+			t, ok := o.(textEntry)
+			return ok && t.typing() && textEditKey(msg)
+		}
+		if m.focus != focusLog {
+			return false
+		}
+		// Keys the log viewport scrolls with; none of them act on the
+		// main panels while the log has focus.
+		switch msg.String() {
+		case "up", "down", "k", "j", "pgup", "pgdown", "home", "end", "ctrl+u", "ctrl+d", "u", "d", "b", "f", "space":
+			return true
+		}
+	}
+	return false
+}
+
+// textEntry is implemented by overlays with a text input; typing reports
+// whether that input has the keyboard.
+type textEntry interface {
+	typing() bool
+}
+
+func (s *packageSearch) typing() bool      { return s.input.Focused() }
+func (s *feedBrowser) typing() bool        { return s.input.Focused() }
+func (s *versionPicker) typing() bool      { return s.filtering }
+func (s *noteEditor) typing() bool         { return true }
+func (s *confirmTyped) typing() bool       { return s.input.Focused() }
+func (s *metadataFixOverlay) typing() bool { return len(s.inputs) > 0 }
+
+// textEditKey reports whether msg only edits the text of a focused input:
+// a printable character, or a key that deletes text or moves within it.
+func textEditKey(msg bubble_tea.KeyMsg) bool {
+	k := msg.Key()
+	if k.Text != "" && k.Mod&(bubble_tea.ModCtrl|bubble_tea.ModAlt) == 0 {
+		return true
+	}
+	switch msg.String() {
+	case "backspace", "delete", "left", "right", "home", "end",
+		"ctrl+a", "ctrl+e", "ctrl+b", "ctrl+f", "ctrl+h", "ctrl+w", "ctrl+k", "ctrl+u",
+		"alt+backspace", "alt+left", "alt+right":
+		return true
+	}
+	return false
+}
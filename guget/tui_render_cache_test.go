@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	bubbles_textinpute "charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestBodyCache_RendersOncePerKey(t *testing.T) {
+	var c bodyCache
+	renders := 0
+	render := func() string {
+		renders++
+		return "body"
+	}
+	key := bodyCacheKey{gen: 1, left: 20, mid: 60, right: 40, height: 30, focus: focusPackages}
+
+	c.get(key, render)
+	c.get(key, render)
+	if renders != 1 {
+		t.Fatalf("expected an unchanged key to reuse the render, got %d render(s)", renders)
+	}
+
+	key.gen++
+	c.get(key, render)
+	key.mid--
+	if got := c.get(key, render); got != "body" || renders != 3 {
+		t.Fatalf("expected a new generation and a resize to re-render, got %q after %d render(s)", got, renders)
+	}
+}
+
+func TestPanelNeutral(t *testing.T) {
+	letter := tea.KeyPressMsg{Code: 'a', Text: "a"}
+	backspace := tea.KeyPressMsg{Code: tea.KeyBackspace}
+	enter := tea.KeyPressMsg{Code: tea.KeyEnter}
+	down := tea.KeyPressMsg{Code: tea.KeyDown}
+
+	app := &App{ctx: &AppContext{}}
+	app.focus = focusLog
+	if !app.panelNeutral(down) || !app.panelNeutral(logLineMsg{}) {
+		t.Fatal("expected log scrolling and log lines to leave the panels alone")
+	}
+	app.focus = focusPackages
+	if app.panelNeutral(down) {
+		t.Fatal("expected moving through packages to re-render the panels")
+	}
+
+	app.search.active = true
+	app.search.input = bubbles_textinpute.New()
+	app.search.input.Focus()
+	if !app.panelNeutral(letter) || !app.panelNeutral(backspace) {
+		t.Fatal("expected typing into the search box to leave the panels alone")
+	}
+	if app.panelNeutral(enter) {
+		t.Fatal("expected enter in the search overlay to re-render the panels")
+	}
+
+	// Without a focused input, printable keys are overlay commands that may
+	// act on the panels.
+	app.search.active = false
+	app.confirmRemove.active = true
+	if app.panelNeutral(tea.KeyPressMsg{Code: 'y', Text: "y"}) {
+		t.Fatal("expected a key in an overlay without a text input to re-render the panels")
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+func newAdvisoryOverlay(m *App, pkgName, version string, vulns []PackageVulnerability) advisoryOverlay {
+	return advisoryOverlay{
+		sectionBase: sectionBase{app: m, baseWidth: 70, minWidth: 44, maxMargin: 4, active: true},
+		pkgName:     pkgName,
+		version:     version,
+		vulns:       vulns,
+		details:     make(map[string]*GitHubAdvisory),
+		errs:        make(map[string]error),
+	}
+}
+
+// openAdvisories opens the advisory overlay for the selected package and
+// starts fetching details for each advisory affecting the installed version.
+func (m *App) openAdvisories() bubble_tea.Cmd {
+	if m.packages.cursor >= len(m.packages.rows) {
+		return nil
+	}
+	row := m.packages.rows[m.packages.cursor]
+	vulns := row.installedVulnerabilities()
+	if len(vulns) == 0 {
+		return nil
+	}
+	m.ctx.StatusLine = ""
+	m.advisory = newAdvisoryOverlay(m, row.info.ID, row.ref.Version.String(), vulns)
+
+	var cmds []bubble_tea.Cmd
+	for _, v := range vulns {
+		cmds = append(cmds, fetchAdvisoryCmd(v.AdvisoryURL))
+	}
+	return bubble_tea.Batch(cmds...)
+}
+
+func fetchAdvisoryCmd(advisoryURL string) bubble_tea.Cmd {
+	return func() bubble_tea.Msg {
+		adv, err := FetchGitHubAdvisory(advisoryURL)
+		return advisoryReadyMsg{url: advisoryURL, advisory: adv, err: err}
+	}
+}
+
+func (s *advisoryOverlay) FooterKeys() []kv {
+	return []kv{{"↑↓", "nav"}, {"esc", "close"}}
+}
+
+func (s *advisoryOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q", "enter":
+		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.vulns)-1 {
+			s.cursor++
+		}
+	}
+	return nil
+}
+
+func (s *advisoryOverlay) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	var lines []string
+	lines = append(lines,
+		styleRedBold.Render("Security advisories"),
+		styleSubtle.Render(s.pkgName+" "+s.version),
+		styleBorder.Render(strings.Repeat("─", inner)),
+	)
+
+	for i, v := range s.vulns {
+		prefix := "  "
+		if i == s.cursor {
+			prefix = styleAccentBold.Render(glyphs.Cursor)
+		}
+		sev := v.SeverityLabel()
+		lines = append(lines, prefix+severityStyle(sev).Render(padRight(sev, 9))+styleText.Render(advisoryLabel(v.AdvisoryURL)))
+	}
+	lines = append(lines, styleBorder.Render(strings.Repeat("─", inner)))
+
+	if s.cursor < len(s.vulns) {
+		lines = append(lines, s.renderAdvisory(s.vulns[s.cursor], inner)...)
+	}
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
+
+// renderAdvisory renders the fetched details for one advisory, or its
+// loading/error state.
+func (s *advisoryOverlay) renderAdvisory(v PackageVulnerability, width int) []string {
+	label := func(k string) string { return styleMuted.Render(padRight(k, 11)) }
+	link := hyperlink(v.AdvisoryURL, styleCyan.Render(advisoryLabel(v.AdvisoryURL)))
+
+	adv, ok := s.details[v.AdvisoryURL]
+	if !ok {
+		if err := s.errs[v.AdvisoryURL]; err != nil {
+			return []string{
+				styleRed.Render(truncate("Could not load advisory: "+err.Error(), width)),
+				label("Link") + link,
+			}
+		}
+		return []string{styleMuted.Render("Loading advisory…")}
+	}
+
+	var lines []string
+	if adv.Summary != "" {
+		lines = append(lines, styleTextBold.Render(wordWrap(adv.Summary, width)), "")
+	}
+	if adv.CVEID != "" {
+		lines = append(lines, label("CVE")+styleText.Render(adv.CVEID))
+	}
+	sev := adv.Severity
+	if sev == "" {
+		sev = v.SeverityLabel()
+	}
+	lines = append(lines, label("Severity")+severityStyle(sev).Render(sev))
+	if score, vector := adv.Score(); score > 0 {
+		cvss := styleText.Render(fmt.Sprintf("%.1f", score))
+		if vector != "" {
+			cvss += "  " + styleMuted.Render(truncate(vector, width-16))
+		}
+		lines = append(lines, label("CVSS")+cvss)
+	}
+	affected, fixed := adv.AffectedRange(s.pkgName)
+	if affected != "" {
+		lines = append(lines, label("Affected")+styleYellow.Render(affected))
+	}
+	if fixed != "" {
+		lines = append(lines, label("Fixed in")+styleGreen.Render(fixed))
+	} else {
+		lines = append(lines, label("Fixed in")+styleMuted.Render("no patched version"))
+	}
+	if t, err := time.Parse(time.RFC3339, adv.PublishedAt); err == nil {
+		lines = append(lines, label("Published")+styleText.Render(timeAgo(t)))
+	}
+	lines = append(lines, label("Link")+link)
+	return lines
+}
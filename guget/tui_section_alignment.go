@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// openRepoAlignment lists the packages whose versions differ between the
+// repositories of a workspace, each set to align to its highest version.
+func (m *App) openRepoAlignment() bubble_tea.Cmd {
+	if !slices.ContainsFunc(m.ctx.ParsedProjects, func(p *ParsedProject) bool { return p.Repo != "" }) {
+		return m.setStatus("▲ Version alignment needs a workspace file listing several repositories", true)
+	}
+	rows := findRepoDivergences(m.ctx.ParsedProjects)
+	if len(rows) == 0 {
+		return m.setStatus("✓ Every package shared between repositories is at one version", false)
+	}
+	s := repoAlignOverlay{
+		sectionBase: sectionBase{app: m, basePct: 80, minWidth: 60, maxMargin: 4, active: true},
+		rows:        rows,
+		targets:     make([]string, len(rows)),
+		selected:    make([]bool, len(rows)),
+	}
+	for i, d := range rows {
+		s.targets[i] = d.Highest
+	}
+	m.repoAlign = s
+	m.ctx.StatusLine = ""
+	return nil
+}
+
+// versions lists the distinct versions of row i, oldest first.
+func (s *repoAlignOverlay) versions(i int) []string {
+	var out []string
+	for _, v := range s.rows[i].Versions {
+		if !slices.Contains(out, v.Version) {
+			out = append(out, v.Version)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b string) int {
+		switch va, vb := ParseSemVer(a), ParseSemVer(b); {
+		case va.IsNewerThan(vb):
+			return 1
+		case vb.IsNewerThan(va):
+			return -1
+		}
+		return 0
+	})
+	return out
+}
+
+// cycleTarget moves row i's target version by delta among its versions.
+func (s *repoAlignOverlay) cycleTarget(i, delta int) {
+	versions := s.versions(i)
+	j := slices.Index(versions, s.targets[i])
+	s.targets[i] = versions[(j+delta+len(versions))%len(versions)]
+}
+
+// alignments returns the selected rows as alignments, or the row under the
+// cursor when none is selected.
+func (s *repoAlignOverlay) alignments() []alignment {
+	var out []alignment
+	for i, on := range s.selected {
+		if on {
+			out = append(out, alignment{Package: s.rows[i].Package, Version: s.targets[i]})
+		}
+	}
+	if len(out) == 0 && s.cursor < len(s.rows) {
+		out = append(out, alignment{Package: s.rows[s.cursor].Package, Version: s.targets[s.cursor]})
+	}
+	return out
+}
+
+// allowedAlignments returns the selected alignments the package rules in
+// settings permit, logging the ones they hold back, or a status when none
+// is left.
+func (s *repoAlignOverlay) allowedAlignments() ([]alignment, bubble_tea.Cmd) {
+	aligns, heldBack := allowedAlignments(s.app.ctx.Rules, s.alignments())
+	for _, msg := range heldBack {
+		logInfo("align: held back %s", msg)
+	}
+	if len(aligns) == 0 {
+		return nil, s.app.setStatus(fmt.Sprintf("✗ Held back by settings: %s", strings.Join(heldBack, "; ")), true)
+	}
+	return aligns, nil
+}
+
+// alignOnDisk previews writing aligns to the working trees of every
+// repository, through the usual update plan.
+func (m *App) alignOnDisk(aligns []alignment) bubble_tea.Cmd {
+	var targets []updateTarget
+	for _, a := range aligns {
+		targets = append(targets, updateTarget{Package: a.Package, Version: a.Version, Info: m.ctx.Results[a.Package].pkg})
+	}
+	projects := slices.DeleteFunc(slices.Clone(m.ctx.ParsedProjects), func(p *ParsedProject) bool { return p.Repo == "" })
+	return m.openUpdatePlan("Align versions across repositories", projects, targets)
+}
+
+// alignOnBranchesCmd creates the alignment branches in the background.
+func (m *App) alignOnBranchesCmd(aligns []alignment) bubble_tea.Cmd {
+	projects := slices.Clone(m.ctx.ParsedProjects)
+	return func() bubble_tea.Msg {
+		return alignBranchesMsg{results: alignOnBranches(projects, aligns)}
+	}
+}
+
+// finishAlignBranches logs what each repository got and sums it up.
+func (m *App) finishAlignBranches(results []alignBranchResult) bubble_tea.Cmd {
+	created, failed := 0, 0
+	var firstErr error
+	for _, res := range results {
+		switch {
+		case res.Err != nil:
+			failed++
+			if firstErr == nil {
+				firstErr = res.Err
+			}
+			logWarn("align: %s: %v", res.Repo, res.Err)
+		case res.Branch != "":
+			created++
+			logInfo("align: %s: created %s (%d file(s)) in %s", res.Repo, res.Branch, res.Files, res.Root)
+		default:
+			logInfo("align: %s: already aligned at HEAD", res.Repo)
+		}
+	}
+	switch {
+	case failed > 0:
+		return m.setStatus(fmt.Sprintf("✗ Alignment failed in %d of %d repositories: %v (l for log)", failed, len(results), firstErr), true)
+	case created == 0:
+		return m.setStatus("✓ Already aligned in every repository's HEAD", false)
+	default:
+		branch := ""
+		for _, res := range results {
+			if res.Branch != "" {
+				branch = res.Branch
+				break
+			}
+		}
+		return m.setStatus(fmt.Sprintf("✓ Created %s in %d repositories; your checkouts are unchanged", branch, created), false)
+	}
+}
+
+func (s *repoAlignOverlay) FooterKeys() []kv {
+	return []kv{{"↑↓", "nav"}, {"←→", "version"}, {"space", "select"}, {"enter", "align files"}, {"b", "align on branches"}, {"esc", "close"}}
+}
+
+func (s *repoAlignOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q", "W":
+		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.rows)-1 {
+			s.cursor++
+		}
+	case "left", "h":
+		s.cycleTarget(s.cursor, -1)
+	case "right", "l":
+		s.cycleTarget(s.cursor, 1)
+	case "space":
+		s.selected[s.cursor] = !s.selected[s.cursor]
+	case "a":
+		on := slices.Contains(s.selected, false)
+		for i := range s.selected {
+			s.selected[i] = on
+		}
+	case "enter":
+		if cmd := s.app.readOnlySessionStatus(); cmd != nil {
+			return cmd
+		}
+		aligns, cmd := s.allowedAlignments()
+		s.closeOverlay()
+		if cmd != nil {
+			return cmd
+		}
+		return s.app.alignOnDisk(aligns)
+	case "b":
+		if cmd := s.app.readOnlySessionStatus(); cmd != nil {
+			return cmd
+		}
+		aligns, cmd := s.allowedAlignments()
+		s.closeOverlay()
+		if cmd != nil {
+			return cmd
+		}
+		m := s.app
+		return m.guardBulk("branch", len(aligns), fmt.Sprintf("Create branches aligning %d packages?", len(aligns)), func() bubble_tea.Cmd {
+			m.setStatus(fmt.Sprintf("Creating %s in each repository…", alignBranchName(aligns)), false)
+			return m.alignOnBranchesCmd(aligns)
+		})
+	}
+	return nil
+}
+
+func (s *repoAlignOverlay) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	title := fmt.Sprintf("Version alignment · %d package(s) differ across repositories", len(s.rows))
+	lines := []string{
+		styleAccentBold.Render(truncate(title, inner)),
+		styleBorder.Render(strings.Repeat("─", inner)),
+	}
+
+	colPkg := min(40, inner/3)
+	maxRows := max(1, s.app.overlayHeight()-14)
+	start := 0
+	if s.cursor >= maxRows {
+		start = s.cursor - maxRows + 1
+	}
+	end := min(len(s.rows), start+maxRows)
+	for i := start; i < end; i++ {
+		d := s.rows[i]
+		prefix, nameStyle := "  ", styleText
+		if i == s.cursor {
+			prefix, nameStyle = styleAccentBold.Render(glyphs.Cursor), styleAccentBold
+		}
+		box := styleMuted.Render("[ ] ")
+		if s.selected[i] {
+			box = styleGreen.Render("[x] ")
+		}
+		target := "→ " + s.targets[i]
+		var spread []string
+		for _, v := range d.Versions {
+			spread = append(spread, v.Repo+" "+v.Version)
+		}
+		used := 6 + colPkg + len(target) + 2
+		lines = append(lines, prefix+box+
+			padRight(nameStyle.Render(truncate(d.Package, colPkg-1)), colPkg)+
+			styleGreen.Render(target)+"  "+
+			styleMuted.Render(truncate(strings.Join(spread, " · "), max(8, inner-used))))
+	}
+	if end < len(s.rows) {
+		lines = append(lines, styleMuted.Render(fmt.Sprintf("  … %d more", len(s.rows)-end)))
+	}
+
+	// Where the row under the cursor stands in each repository.
+	if s.cursor < len(s.rows) {
+		lines = append(lines, "", styleSubtleBold.Render(s.rows[s.cursor].Package))
+		for _, v := range s.rows[s.cursor].Versions {
+			versionStyle := styleYellow
+			if v.Version == s.targets[s.cursor] {
+				versionStyle = styleGreen
+			}
+			var names []string
+			for _, p := range v.Projects {
+				names = append(names, p.FileName)
+			}
+			line := "  " + padRight(styleText.Render(truncate(v.Repo, 20)), 22) + padRight(versionStyle.Render(v.Version), 16)
+			lines = append(lines, line+styleMuted.Render(truncate(strings.Join(names, ", "), max(8, inner-40))))
+		}
+	}
+	lines = append(lines, "", styleMuted.Render(wordWrap("enter writes the files in every repository; b commits the change to a new branch in each one and leaves your checkouts alone. Packages pinned, limited to another major version, or kept out of bulk updates in settings are left alone.", inner)))
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// alternativeTagCount is how many of a package's tags are searched for
+// similar packages.
+const alternativeTagCount = 3
+
+func (m *App) openAlternatives(row packageRow) bubble_tea.Cmd {
+	if row.info == nil {
+		return nil
+	}
+	info := row.info
+	reason := ""
+	switch {
+	case info.Deprecated:
+		reason = "deprecated"
+		if info.DeprecationMessage != "" {
+			reason += ": " + info.DeprecationMessage
+		}
+	case row.renamedTo != "":
+		reason = "renamed to " + row.renamedTo
+	case info.Abandoned(time.Now()):
+		reason = "no release since " + info.LastPublished().Format("Jan 2006")
+	}
+	m.alternatives = alternativesOverlay{
+		sectionBase: sectionBase{app: m, baseWidth: 84, minWidth: 56, maxMargin: 4, active: true},
+		pkgID:       info.ID,
+		reason:      reason,
+		loading:     true,
+	}
+	m.ctx.StatusLine = ""
+
+	services := m.ctx.NugetServices
+	alt := info.AlternatePackageID
+	if alt == "" {
+		alt = row.renamedTo
+	}
+	return func() bubble_tea.Msg {
+		var recommended *SearchResult
+		if alt != "" {
+			recommended = &SearchResult{ID: alt}
+			for _, svc := range services {
+				results, err := svc.Search("packageid:"+alt, 1)
+				if err == nil && len(results) > 0 && strings.EqualFold(results[0].ID, alt) {
+					results[0].Source = svc.SourceName()
+					recommended = &results[0]
+					break
+				}
+			}
+		}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		var found []SearchResult
+		var lastErr error
+		for _, tag := range info.alternativeTags(alternativeTagCount) {
+			for _, svc := range services {
+				wg.Add(1)
+				go func(svc *NugetService, tag string) {
+					defer wg.Done()
+					results, err := svc.Search("tags:"+tag, 20)
+					mu.Lock()
+					defer mu.Unlock()
+					if err != nil {
+						logDebug("[%s] tag search %q failed: %v", svc.SourceName(), tag, err)
+						lastErr = err
+						return
+					}
+					for i := range results {
+						results[i].Source = svc.SourceName()
+					}
+					found = append(found, results...)
+				}(svc, tag)
+			}
+		}
+		wg.Wait()
+
+		items := rankAlternatives(info, recommended, found)
+		if len(items) == 0 && lastErr != nil {
+			return alternativesReadyMsg{id: info.ID, err: lastErr}
+		}
+		return alternativesReadyMsg{id: info.ID, items: items}
+	}
+}
+
+func (s *alternativesOverlay) applyResults(msg alternativesReadyMsg) {
+	if !s.active || !strings.EqualFold(msg.id, s.pkgID) {
+		return
+	}
+	s.loading = false
+	s.items = msg.items
+	s.err = msg.err
+	s.cursor = 0
+}
+
+// fetchReplacement loads the chosen alternative's versions so a compatible
+// one can be proposed.
+func (s *alternativesOverlay) fetchReplacement(alt packageAlternative) bubble_tea.Cmd {
+	if cached, ok := s.app.ctx.Results[alt.ID]; ok && cached.pkg != nil {
+		return func() bubble_tea.Msg {
+			return replacementFetchedMsg{id: alt.ID, info: cached.pkg, source: cached.source}
+		}
+	}
+	s.fetching = true
+	services := FilterServices(s.app.ctx.NugetServices, s.app.ctx.SourceMapping, alt.ID)
+	return func() bubble_tea.Msg {
+		var lastErr error
+		for _, svc := range services {
+			info, err := svc.SearchExact(alt.ID)
+			if err == nil {
+				return replacementFetchedMsg{id: alt.ID, info: info, source: svc.SourceName()}
+			}
+			lastErr = err
+		}
+		return replacementFetchedMsg{id: alt.ID, err: lastErr}
+	}
+}
+
+// showReplacement hands the chosen alternative to the replacement preview.
+func (s *alternativesOverlay) showReplacement(msg replacementFetchedMsg) {
+	s.fetching = false
+	if !s.active {
+		return
+	}
+	if msg.err != nil {
+		s.err = msg.err
+		return
+	}
+	s.closeOverlay()
+	s.app.openReplacePreview(s.pkgID, msg.info, msg.source)
+}
+
+func (s *alternativesOverlay) FooterKeys() []kv {
+	return []kv{{"↑↓", "nav"}, {"enter", "replace…"}, {"esc", "close"}}
+}
+
+func (s *alternativesOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q", "g":
+		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.items)-1 {
+			s.cursor++
+		}
+	case "enter":
+		if s.fetching || s.cursor >= len(s.items) {
+			return nil
+		}
+		if cmd := s.app.readOnlyProjectStatus(s.app.selectedProject()); cmd != nil {
+			return cmd
+		}
+		s.err = nil
+		return s.fetchReplacement(s.items[s.cursor])
+	}
+	return nil
+}
+
+func (s *alternativesOverlay) Render() string {
+	w := s.Width()
+	innerW := w - 6 // border (2) + padding (2*2)
+
+	lines := []string{styleAccentBold.Render("Alternatives to ") + styleTextBold.Render(s.pkgID)}
+	if s.reason != "" {
+		lines = append(lines, styleYellow.Render(wordWrap(s.reason, innerW)))
+	}
+	lines = append(lines, styleBorder.Render(strings.Repeat("─", innerW)))
+
+	const colDl = 9
+	colID := max(innerW-colDl-2, 20)
+	switch {
+	case s.loading:
+		lines = append(lines, s.app.ctx.Spinner.View()+" "+styleSubtle.Render("Looking for alternatives..."))
+	case s.err != nil && len(s.items) == 0:
+		lines = append(lines, styleRed.Render(wordWrap("✗ "+s.err.Error(), innerW)))
+	case len(s.items) == 0:
+		lines = append(lines, styleMuted.Render("No alternatives found"))
+	default:
+		for i, a := range s.items {
+			prefix := "  "
+			idStyle := styleText
+			if i == s.cursor {
+				prefix = styleAccent.Render(glyphs.Cursor)
+				idStyle = styleAccentBold
+			}
+			downloads := ""
+			if a.Downloads > 0 {
+				downloads = formatDownloads(a.Downloads)
+			}
+			label := idStyle.Render(truncate(a.ID, colID-1))
+			lines = append(lines, prefix+padRight(label, colID)+styleMuted.Render(fmt.Sprintf("%*s", colDl, downloads)))
+		}
+		a := s.items[s.cursor]
+		lines = append(lines, styleBorder.Render(strings.Repeat("─", innerW)))
+		if a.Recommended {
+			lines = append(lines, styleGreen.Render("Recommended by the deprecation notice"))
+		} else {
+			lines = append(lines, styleMuted.Render("Shares tags: ")+styleText.Render(strings.Join(a.SharedTags, ", ")))
+		}
+		if a.Version != "" {
+			lines = append(lines, styleMuted.Render("Latest: ")+styleText.Render(a.Version)+styleMuted.Render("  on "+a.Source))
+		}
+		if desc := strings.TrimSpace(a.Description); desc != "" {
+			wrapped := strings.Split(wordWrap(desc, innerW), "\n")
+			if len(wrapped) > 3 {
+				wrapped = append(wrapped[:3], "…")
+			}
+			lines = append(lines, styleSubtle.Render(strings.Join(wrapped, "\n")))
+		}
+		if s.fetching {
+			lines = append(lines, s.app.ctx.Spinner.View()+" "+styleSubtle.Render("Fetching versions..."))
+		} else if s.err != nil {
+			lines = append(lines, styleRed.Render(wordWrap("✗ "+s.err.Error(), innerW)))
+		}
+	}
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
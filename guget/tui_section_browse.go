@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	bubbles_textinpute "charm.land/bubbles/v2/textinput"
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// browsePageSize is how many packages the feed browser loads at a time.
+const browsePageSize = 50
+
+var browseModeNames = []string{"Top", "Tag", "Owner"}
+
+func (m *App) openFeedBrowser() bubble_tea.Cmd {
+	if len(m.ctx.NugetServices) == 0 {
+		return m.setStatus("✗ No package sources to browse", true)
+	}
+	ti := bubbles_textinpute.New()
+	ti.CharLimit = 100
+	m.browse = feedBrowser{
+		sectionBase: sectionBase{app: m, basePct: 80, minWidth: 60, maxMargin: 4, active: true},
+		input:       ti,
+		source:      min(m.browse.source, len(m.ctx.NugetServices)-1),
+	}
+	m.ctx.StatusLine = ""
+	return m.browse.reload()
+}
+
+func (s *feedBrowser) service() *NugetService {
+	services := s.app.ctx.NugetServices
+	if s.source >= len(services) {
+		return nil
+	}
+	return services[s.source]
+}
+
+// reload starts a fresh listing for the current source, mode, and filter.
+func (s *feedBrowser) reload() bubble_tea.Cmd {
+	s.gen++
+	s.results, s.total, s.cursor, s.err = nil, 0, 0, nil
+	term := strings.TrimSpace(s.input.Value())
+	switch s.mode {
+	case browseTag:
+		s.query = "tags:" + term
+	case browseOwner:
+		s.query = "owner:" + term
+	default:
+		s.query = ""
+	}
+	if s.mode != browseTop && term == "" {
+		s.loading = false
+		return nil
+	}
+	return s.fetchPage()
+}
+
+// fetchPage loads the next page of the current listing.
+func (s *feedBrowser) fetchPage() bubble_tea.Cmd {
+	svc := s.service()
+	if svc == nil {
+		return nil
+	}
+	s.loading = true
+	gen, query, skip := s.gen, s.query, len(s.results)
+	return func() bubble_tea.Msg {
+		results, total, err := svc.Browse(query, skip, browsePageSize)
+		return browsePageMsg{gen: gen, results: results, total: total, err: err}
+	}
+}
+
+// applyPage appends a page unless the listing has changed since it was asked for.
+func (s *feedBrowser) applyPage(msg browsePageMsg) {
+	if msg.gen != s.gen {
+		return
+	}
+	s.loading = false
+	s.err = msg.err
+	s.results = append(s.results, msg.results...)
+	s.total = max(msg.total, len(s.results))
+	if len(msg.results) == 0 {
+		// An empty page means the feed has nothing more, whatever it claimed.
+		s.total = len(s.results)
+	}
+}
+
+func (s *feedBrowser) FooterKeys() []kv {
+	return []kv{{"↑↓", "nav"}, {"tab", "mode"}, {"ctrl+s", "source"}, {"enter", "filter"}, {"esc", "close"}}
+}
+
+func (s *feedBrowser) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		s.input.Blur()
+		s.closeOverlay()
+		return nil
+	case "tab", "shift+tab":
+		step := 1
+		if msg.String() == "shift+tab" {
+			step = len(browseModeNames) - 1
+		}
+		s.mode = (s.mode + browseMode(step)) % browseMode(len(browseModeNames))
+		s.input.Reset()
+		var focus bubble_tea.Cmd
+		if s.mode == browseTop {
+			s.input.Blur()
+		} else {
+			s.input.Placeholder = strings.ToLower(browseModeNames[s.mode]) + " name, then enter"
+			focus = s.input.Focus()
+		}
+		return bubble_tea.Batch(focus, s.reload())
+	case "ctrl+s":
+		s.source = (s.source + 1) % len(s.app.ctx.NugetServices)
+		return s.reload()
+	case "enter":
+		return s.reload()
+	case "up", "ctrl+p":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+		return nil
+	case "down", "ctrl+n":
+		if s.cursor < len(s.results)-1 {
+			s.cursor++
+		}
+		// Load the next page as the cursor nears the end of what is loaded.
+		if !s.loading && s.err == nil && len(s.results) < s.total && s.cursor >= len(s.results)-5 {
+			return s.fetchPage()
+		}
+		return nil
+	}
+	if s.mode == browseTop {
+		return nil
+	}
+	var cmd bubble_tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return cmd
+}
+
+// formatDownloads shortens a download count: 950, 12.3K, 4.5M.
+func formatDownloads(n int) string {
+	switch {
+	case n >= 1_000_000_000:
+		return fmt.Sprintf("%.1fB", float64(n)/1e9)
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fK", float64(n)/1e3)
+	}
+	return fmt.Sprint(n)
+}
+
+func (s *feedBrowser) Render() string {
+	w := s.Width()
+	innerW := w - 6 // border (2) + padding (2*2)
+	svc := s.service()
+
+	var lines []string
+	title := styleAccentBold.Render("Browse Feed")
+	if svc != nil {
+		title += "  " + styleTextBold.Render(svc.SourceName())
+		if n := len(s.app.ctx.NugetServices); n > 1 {
+			title += styleMuted.Render(fmt.Sprintf("  (%d/%d)", s.source+1, n))
+		}
+	}
+	lines = append(lines, title)
+
+	var tabs []string
+	for i, name := range browseModeNames {
+		if browseMode(i) == s.mode {
+			tabs = append(tabs, styleAccentBold.Render(name))
+		} else {
+			tabs = append(tabs, styleMuted.Render(name))
+		}
+	}
+	lines = append(lines, strings.Join(tabs, styleBorder.Render(" │ ")))
+	if s.mode != browseTop {
+		lines = append(lines, s.input.View())
+	}
+	lines = append(lines, styleBorder.Render(strings.Repeat("─", innerW)))
+
+	// Columns: prefix(2) + id(flex) + version(14) + downloads(9)
+	const colVer = 14
+	const colDl = 9
+	colID := max(innerW-colVer-colDl-2, 20)
+
+	// Leave room for the title block above and the details block below.
+	maxVisible := max(s.app.overlayHeight()-16, 5)
+
+	switch {
+	case s.err != nil && len(s.results) == 0:
+		lines = append(lines, styleRed.Render(wordWrap("✗ "+s.err.Error(), innerW)))
+	case s.loading && len(s.results) == 0:
+		lines = append(lines, s.app.ctx.Spinner.View()+" "+styleSubtle.Render("Loading..."))
+	case len(s.results) == 0 && strings.HasSuffix(s.query, ":"):
+		lines = append(lines, styleMuted.Render("Type a "+strings.ToLower(browseModeNames[s.mode])+" and press enter"))
+	case len(s.results) == 0:
+		lines = append(lines, styleMuted.Render("No packages found"))
+	default:
+		start := 0
+		if s.cursor >= maxVisible {
+			start = s.cursor - maxVisible + 1
+		}
+		end := min(start+maxVisible, len(s.results))
+		for i := start; i < end; i++ {
+			r := s.results[i]
+			prefix := "  "
+			idStyle := styleText
+			if i == s.cursor {
+				prefix = styleAccent.Render(glyphs.Cursor)
+				idStyle = styleAccentBold
+			}
+			downloads := ""
+			if r.TotalDownloads > 0 {
+				downloads = formatDownloads(r.TotalDownloads)
+			}
+			lines = append(lines, prefix+
+				padRight(idStyle.Render(truncate(r.ID, colID-1)), colID)+
+				padRight(styleSubtle.Render(truncate(r.Version, colVer-2)), colVer)+
+				styleMuted.Render(fmt.Sprintf("%*s", colDl, downloads)))
+		}
+		status := fmt.Sprintf("%d of %d", len(s.results), s.total)
+		if s.loading {
+			status = s.app.ctx.Spinner.View() + " " + status
+		}
+		lines = append(lines, styleMuted.Render(status))
+	}
+
+	if s.cursor < len(s.results) {
+		r := s.results[s.cursor]
+		lines = append(lines, styleBorder.Render(strings.Repeat("─", innerW)))
+		if desc := strings.TrimSpace(r.Description); desc != "" {
+			wrapped := strings.Split(wordWrap(desc, innerW), "\n")
+			if len(wrapped) > 3 {
+				wrapped = append(wrapped[:3], "…")
+			}
+			lines = append(lines, styleSubtle.Render(strings.Join(wrapped, "\n")))
+		}
+		field := func(label string, values []string) {
+			if len(values) > 0 {
+				lines = append(lines, styleMuted.Render(label+" ")+styleText.Render(truncate(strings.Join(values, ", "), innerW-len(label)-1)))
+			}
+		}
+		field("Authors:", r.Authors)
+		field("Owners: ", r.Owners)
+		field("Tags:   ", r.Tags)
+		if len(r.Versions) > 0 {
+			lines = append(lines, styleMuted.Render(fmt.Sprintf("Versions: %d", len(r.Versions))))
+		}
+	}
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
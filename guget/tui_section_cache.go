@@ -0,0 +1,141 @@
+package main
+
+import (
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+func (m *App) openCaches() bubble_tea.Cmd {
+	if m.ctx.SDKErr != nil {
+		return m.setStatus("✗ "+m.ctx.SDKErr.Error(), true)
+	}
+	m.ctx.StatusLine = ""
+	m.caches = cacheOverlay{
+		sectionBase: sectionBase{app: m, baseWidth: 72, minWidth: 48, maxMargin: 4, active: true},
+		loading:     true,
+	}
+	return listNugetLocalsCmd()
+}
+
+func listNugetLocalsCmd() bubble_tea.Cmd {
+	return func() bubble_tea.Msg {
+		locals, err := listNugetLocals()
+		return nugetLocalsMsg{locals: locals, err: err}
+	}
+}
+
+func clearNugetLocalCmd(name string) bubble_tea.Cmd {
+	return func() bubble_tea.Msg {
+		return nugetLocalClearedMsg{name: name, err: clearNugetLocal(name)}
+	}
+}
+
+// selected returns the cache name under the cursor; the row after the
+// caches is "all".
+func (s *cacheOverlay) selected() string {
+	if s.cursor < len(s.locals) {
+		return s.locals[s.cursor].Name
+	}
+	return "all"
+}
+
+func (s *cacheOverlay) FooterKeys() []kv {
+	if s.confirming != "" {
+		return []kv{{"y", "clear"}, {"n/esc", "cancel"}}
+	}
+	return []kv{{"↑↓", "nav"}, {"c", "clear"}, {"esc", "close"}}
+}
+
+func (s *cacheOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	if s.confirming != "" {
+		switch msg.String() {
+		case "y", "enter":
+			name := s.confirming
+			s.confirming = ""
+			s.clearing = name
+			return clearNugetLocalCmd(name)
+		case "n", "esc", "q":
+			s.confirming = ""
+		}
+		return nil
+	}
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q":
+		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.locals) {
+			s.cursor++
+		}
+	case "c", "enter":
+		if cmd := s.app.readOnlySessionStatus(); cmd != nil {
+			return cmd
+		}
+		if !s.loading && s.clearing == "" && len(s.locals) > 0 {
+			s.confirming = s.selected()
+		}
+	}
+	return nil
+}
+
+func (s *cacheOverlay) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	lines := []string{
+		styleAccentBold.Render("NuGet caches"),
+		styleBorder.Render(strings.Repeat("─", inner)),
+	}
+
+	switch {
+	case s.err != nil:
+		lines = append(lines, styleRed.Render(wordWrap("Error: "+s.err.Error(), inner)))
+	case s.loading && len(s.locals) == 0:
+		lines = append(lines, styleMuted.Render("Measuring caches…"))
+	default:
+		var total int64
+		for i, l := range s.locals {
+			total += l.Size
+			lines = append(lines, s.renderRow(i, l.Name, formatBytes(l.Size), inner))
+			lines = append(lines, "    "+styleMuted.Render(truncate(l.Path, inner-4)))
+		}
+		lines = append(lines, s.renderRow(len(s.locals), "all", formatBytes(total), inner))
+	}
+
+	lines = append(lines, "")
+	switch {
+	case s.confirming != "":
+		lines = append(lines, styleYellowBold.Render("Clear "+s.confirming+"? ")+styleMuted.Render("y / n"))
+	case s.clearing != "":
+		lines = append(lines, styleMuted.Render("Clearing "+s.clearing+"…"))
+	default:
+		lines = append(lines, styleMuted.Render(wordWrap("Stale caches can keep serving old versions; clearing forces the next restore to fetch again.", inner)))
+	}
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
+
+func (s *cacheOverlay) renderRow(i int, name, size string, inner int) string {
+	prefix := "  "
+	nameStyle := styleText
+	if i == s.cursor {
+		prefix = styleAccentBold.Render(glyphs.Cursor)
+		nameStyle = styleAccentBold
+	}
+	left := prefix + nameStyle.Render(name)
+	right := styleCyan.Render(size)
+	gap := inner - lipgloss.Width(left) - lipgloss.Width(right)
+	return left + strings.Repeat(" ", max(gap, 1)) + right
+}
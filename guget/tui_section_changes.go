@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// undoLast reverts the newest change of this session that is still in place.
+func (m *App) undoLast() bubble_tea.Cmd {
+	if cmd := m.readOnlySessionStatus(); cmd != nil {
+		return cmd
+	}
+	entry, ok := sessionJournal.lastOpen()
+	if !ok {
+		return m.setStatus("Nothing to undo", false)
+	}
+	return revertChangeCmd(entry)
+}
+
+func revertChangeCmd(entry journalEntry) bubble_tea.Cmd {
+	return func() bubble_tea.Msg {
+		reverted, err := sessionJournal.revert(entry.ID)
+		rec := ActionRecord{Action: "revert", Package: entry.Package, Version: entry.Version}
+		for _, fc := range entry.Files {
+			rec.Files = append(rec.Files, fc.Path)
+		}
+		recordAction(rec, err)
+		if err != nil {
+			logWarn("revert %s: %v", entry.summary(), err)
+		} else {
+			logInfo("reverted %s (%d file(s))", entry.summary(), len(entry.Files))
+		}
+		return changeRevertedMsg{entry: reverted, err: err}
+	}
+}
+
+func (m *App) openSessionChanges() bubble_tea.Cmd {
+	m.changes = sessionChanges{
+		sectionBase: sectionBase{app: m, baseWidth: 76, minWidth: 50, maxMargin: 4, active: true},
+		entries:     sessionJournal.Entries(),
+	}
+	m.ctx.StatusLine = ""
+	return nil
+}
+
+func (s *sessionChanges) FooterKeys() []kv {
+	return []kv{{"↑↓", "nav"}, {"enter", "revert"}, {"esc", "close"}}
+}
+
+func (s *sessionChanges) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q", "Z":
+		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.entries)-1 {
+			s.cursor++
+		}
+	case "enter", "x":
+		if s.cursor >= len(s.entries) || s.entries[s.cursor].Reverted {
+			return nil
+		}
+		if cmd := s.app.readOnlySessionStatus(); cmd != nil {
+			return cmd
+		}
+		return revertChangeCmd(s.entries[s.cursor])
+	}
+	return nil
+}
+
+func (s *sessionChanges) Render() string {
+	w := s.Width()
+	innerW := w - 6 // border (2) + padding (2*2)
+
+	lines := []string{
+		styleAccentBold.Render("Session Changes"),
+		styleBorder.Render(strings.Repeat("─", innerW)),
+	}
+	if len(s.entries) == 0 {
+		lines = append(lines, styleMuted.Render("No files changed in this session"))
+	}
+	for i, e := range s.entries {
+		prefix := "  "
+		style := styleText
+		if i == s.cursor {
+			prefix = styleAccent.Render(glyphs.Cursor)
+			style = styleAccentBold
+		}
+		when := e.Time.Format("15:04:05")
+		label := truncate(e.summary(), innerW-14)
+		if e.Reverted {
+			lines = append(lines, prefix+styleMuted.Render(when+"  "+label+"  (reverted)"))
+		} else {
+			lines = append(lines, prefix+styleMuted.Render(when+"  ")+style.Render(label))
+		}
+		if i == s.cursor {
+			for _, fc := range e.Files {
+				lines = append(lines, "    "+styleSubtle.Render(truncate(filepath.Base(fc.Path), innerW-4)))
+			}
+		}
+	}
+	if open := countOpen(s.entries); open > 0 {
+		lines = append(lines, "", styleMuted.Render(fmt.Sprintf("%d change(s) can be reverted; ctrl+z reverts the newest", open)))
+	}
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
+
+func countOpen(entries []journalEntry) int {
+	n := 0
+	for _, e := range entries {
+		if !e.Reverted {
+			n++
+		}
+	}
+	return n
+}
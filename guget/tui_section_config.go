@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// openConfigInspector shows the effective nuget.config for the selected
+// project's directory, or the workspace root for "All Projects".
+func (m *App) openConfigInspector() {
+	dir := m.projectDir
+	if p := m.selectedProject(); p != nil {
+		dir = filepath.Dir(p.FilePath)
+	}
+	m.configInspector.dir = dir
+	m.configInspector.config = loadEffectiveNugetConfig(nugetConfigChain(dir))
+	m.configInspector.active = true
+	m.ctx.StatusLine = ""
+	m.configInspector.refreshView()
+}
+
+func (s *configInspector) FooterKeys() []kv {
+	return []kv{{"↑↓", "scroll"}, {"esc", "close"}}
+}
+
+func (s *configInspector) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+		s.refreshView()
+	case "]":
+		s.Resize(4)
+		s.refreshView()
+	case "esc", "c", "q":
+		s.closeOverlay()
+	default:
+		var cmd bubble_tea.Cmd
+		s.vp, cmd = s.vp.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+func (s *configInspector) refreshView() {
+	w := s.Width()
+	innerW := w - 6 // border (2) + padding (2*2)
+
+	rel, err := filepath.Rel(s.app.projectDir, s.dir)
+	if err != nil || rel == "." {
+		rel = filepath.Base(s.dir)
+	}
+	var lines []string
+	lines = append(lines, styleAccentBold.Render("NuGet Configuration")+"  "+styleMuted.Render(truncate(rel, innerW-22)))
+	lines = append(lines, styleBorder.Render(strings.Repeat("─", innerW)))
+
+	cfg := s.config
+	if len(cfg.Files) == 0 {
+		lines = append(lines, styleMuted.Render("No nuget.config applies here; NuGet defaults are in effect."))
+	}
+	// Files are numbered so each setting can point at its origin compactly.
+	index := map[string]int{}
+	for i, f := range cfg.Files {
+		index[f] = i + 1
+		lines = append(lines, styleCyan.Render(fmt.Sprintf("[%d] ", i+1))+styleSubtle.Render(truncate(f, innerW-5)))
+	}
+	lines = append(lines, "")
+
+	for _, sec := range cfg.Sections {
+		lines = append(lines, styleTextBold.Render(sec.Name))
+		if len(sec.Settings) == 0 {
+			lines = append(lines, "  "+styleMuted.Render("(empty)"))
+		}
+		for _, st := range sec.Settings {
+			origin := styleCyan.Render(fmt.Sprintf("[%d]", index[st.Origin]))
+			key := st.Key
+			if key == "" {
+				key = "(unnamed)"
+			}
+			text := truncate(key+" = "+st.Value, innerW-8)
+			lines = append(lines, "  "+origin+" "+styleSubtle.Render(text))
+		}
+		if sec.ClearedIn != "" {
+			lines = append(lines, "  "+styleYellow.Render(fmt.Sprintf("<clear/> in [%d] hides farther files", index[sec.ClearedIn])))
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, styleMuted.Render(wordWrap("Read-only: guget reports these settings but only acts on sources, mappings, credentials, and package folders.", innerW)))
+
+	maxH := s.app.overlayHeight() - 6
+	if maxH < 8 {
+		maxH = 8
+	}
+
+	s.vp.SetWidth(w - 4)
+	s.vp.SetHeight(maxH)
+	s.vp.SetContent(strings.Join(lines, "\n"))
+	s.vp.GotoTop()
+}
+
+func (s *configInspector) Render() string {
+	box := styleOverlay.
+		Width(s.Width()).
+		Render(s.vp.View())
+
+	return s.centerOverlay(box)
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"path/filepath"
 	"strings"
 
+	bubbles_textinpute "charm.land/bubbles/v2/textinput"
 	bubble_tea "charm.land/bubbletea/v2"
 )
 
@@ -22,6 +25,91 @@ func newConfirmUpdate(m *App, pkgName, newVersion string, project *ParsedProject
 	}
 }
 
+func newConfirmConflictFix(m *App, c VersionConflict, project *ParsedProject) confirmConflictFix {
+	return confirmConflictFix{
+		sectionBase: sectionBase{app: m, baseWidth: 60, minWidth: 40, maxMargin: 4, active: true},
+		conflict:    c,
+		project:     project,
+	}
+}
+
+func newConfirmTyped(m *App, title, phrase string, run func() bubble_tea.Cmd) confirmTyped {
+	ti := bubbles_textinpute.New()
+	ti.Placeholder = phrase
+	ti.CharLimit = len(phrase) + 8
+	ti.SetWidth(30)
+	return confirmTyped{
+		sectionBase: sectionBase{app: m, baseWidth: 56, minWidth: 40, maxMargin: 4, active: true},
+		title:       title,
+		phrase:      phrase,
+		input:       ti,
+		run:         run,
+	}
+}
+
+// guardBulk runs a write that touches count packages or projects, subject to
+// the user config: refused outright when bulk writes are disabled, and behind
+// a typed "<verb> <count>" confirmation at or above the threshold.
+func (m *App) guardBulk(verb string, count int, title string, run func() bubble_tea.Cmd) bubble_tea.Cmd {
+	if count <= 1 {
+		return run()
+	}
+	cfg := m.ctx.Config
+	if cfg.DisableBulkWrites {
+		return m.setStatus(fmt.Sprintf("✗ bulk writes are disabled by config (%s %d)", verb, count), true)
+	}
+	if cfg.BulkConfirmThreshold <= 0 || count < cfg.BulkConfirmThreshold {
+		return run()
+	}
+	m.confirmTyped = newConfirmTyped(m, title, fmt.Sprintf("%s %d", verb, count), run)
+	m.ctx.StatusLine = ""
+	return m.confirmTyped.input.Focus()
+}
+
+func (s *confirmTyped) FooterKeys() []kv {
+	return []kv{{"enter", "confirm"}, {"esc", "cancel"}}
+}
+
+func (s *confirmTyped) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		s.input.Blur()
+		s.closeOverlay()
+		return nil
+	case "enter":
+		if strings.TrimSpace(s.input.Value()) != s.phrase {
+			s.mismatch = true
+			return nil
+		}
+		s.input.Blur()
+		s.closeOverlay()
+		run := s.run
+		s.run = nil
+		return run()
+	}
+	s.mismatch = false
+	var cmd bubble_tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return cmd
+}
+
+func (s *confirmTyped) Render() string {
+	w := s.Width()
+	lines := []string{
+		styleRedBold.Render(s.title),
+		"",
+		styleMuted.Render("Type ") + styleTextBold.Render(s.phrase) + styleMuted.Render(" to continue"),
+		s.input.View(),
+	}
+	if s.mismatch {
+		lines = append(lines, styleRed.Render("Doesn't match — nothing was changed"))
+	}
+	box := styleOverlayDanger.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
+
 func (s *confirmRemove) FooterKeys() []kv {
 	return []kv{{"enter/y", "confirm"}, {"esc", "cancel"}}
 }
@@ -38,7 +126,13 @@ func (s *confirmRemove) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 		s.closeOverlay()
 	case "enter", "y":
 		s.closeOverlay()
-		return s.app.removePackage(s.pkgName)
+		if s.app.selectedProject() != nil {
+			return s.app.removePackage(s.pkgName)
+		}
+		n := s.app.projectsReferencing(s.pkgName, false)
+		return s.app.guardBulk("remove", n, fmt.Sprintf("Remove %s from %d projects?", s.pkgName, n), func() bubble_tea.Cmd {
+			return s.app.removePackage(s.pkgName)
+		})
 	}
 	return nil
 }
@@ -64,20 +158,89 @@ func (s *confirmUpdate) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 	return nil
 }
 
-// applyOrConfirmUpdate calls applyVersion directly, or opens the lock-confirm
-// overlay if the currently-installed version is pinned with [x.y.z].
+func (s *confirmConflictFix) FooterKeys() []kv {
+	return []kv{{"enter/y", "confirm"}, {"esc", "cancel"}}
+}
+
+func (s *confirmConflictFix) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+		return nil
+	case "]":
+		s.Resize(4)
+		return nil
+	case "esc", "n", "q":
+		s.closeOverlay()
+	case "enter", "y":
+		s.closeOverlay()
+		return s.app.removeRedundantDefinition(s.conflict, s.project)
+	}
+	return nil
+}
+
+// applyOrConfirmUpdate calls applyVersion directly, or opens the confirm
+// overlay if the currently-installed version is pinned with [x.y.z] or the
+// new version declares a different license. Updates across all projects go
+// through the update plan preview instead, and updates the package's rule
+// in settings forbids are refused.
 func (m *App) applyOrConfirmUpdate(pkgName, newVersion string, project *ParsedProject) bubble_tea.Cmd {
-	if project != nil {
+	if cmd := m.readOnlySessionStatus(); cmd != nil {
+		return cmd
+	}
+	if msg := m.ctx.Rules.For(pkgName).refusal(pkgName, ParseSemVer(newVersion)); msg != "" {
+		return m.setStatus("✗ "+msg, true)
+	}
+	if project == nil {
+		var info *PackageInfo
 		for _, row := range m.packages.rows {
-			if strings.EqualFold(row.ref.Name, pkgName) && row.ref.Locked {
-				m.confirmUpdate = newConfirmUpdate(m, pkgName, newVersion, project)
-				return nil
+			if strings.EqualFold(row.ref.Name, pkgName) {
+				info = row.info
 			}
 		}
+		return m.openUpdatePlan("Update "+pkgName+" to "+newVersion, m.ctx.ParsedProjects,
+			[]updateTarget{{Package: pkgName, Version: newVersion, Info: info}})
+	}
+	for _, row := range m.packages.rows {
+		if !strings.EqualFold(row.ref.Name, pkgName) {
+			continue
+		}
+		c := newConfirmUpdate(m, pkgName, newVersion, project)
+		c.pinned = row.ref.Locked
+		if row.info != nil {
+			from, to, changed := row.info.LicenseChange(row.effectiveVersion(), ParseSemVer(newVersion))
+			if changed {
+				c.licenseFrom, c.licenseTo = from, to
+			}
+		}
+		if c.pinned || c.licenseTo != "" {
+			m.confirmUpdate = c
+			m.ctx.StatusLine = ""
+			return nil
+		}
+		break
 	}
 	return m.applyVersion(pkgName, newVersion, project)
 }
 
+// projectsReferencing counts the editable projects that reference pkgName,
+// optionally leaving out ones where the version is locked.
+func (m *App) projectsReferencing(pkgName string, skipLocked bool) int {
+	n := 0
+	for _, p := range m.ctx.ParsedProjects {
+		if p.Legacy {
+			continue
+		}
+		for ref := range p.Packages {
+			if strings.EqualFold(ref.Name, pkgName) && !(skipLocked && ref.Locked) {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
+
 func (s *confirmRemove) Render() string {
 	w := s.Width()
 	lines := []string{
@@ -99,11 +262,41 @@ func (s *confirmUpdate) Render() string {
 			break
 		}
 	}
+	var lines []string
+	if s.pinned {
+		lines = append(lines,
+			styleYellowBold.Render("Version is pinned"),
+			styleSubtle.Render(s.pkgName)+"  "+styleYellow.Render("["+pinnedVer+"]"),
+			"",
+		)
+	}
+	if s.licenseTo != "" {
+		if !s.pinned {
+			lines = append(lines, styleYellowBold.Render("License changes"), styleSubtle.Render(s.pkgName), "")
+		}
+		innerW := w - 6
+		lines = append(lines,
+			styleMuted.Render("from ")+styleText.Render(truncate(s.licenseFrom, innerW-5)),
+			styleMuted.Render("to   ")+styleYellow.Render(truncate(s.licenseTo, innerW-5)),
+			"",
+		)
+	}
+	lines = append(lines, styleMuted.Render("Update to "+s.newVersion+" anyway?"))
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
+
+func (s *confirmConflictFix) Render() string {
+	w := s.Width()
+	c := s.conflict
 	lines := []string{
-		styleYellowBold.Render("Version is pinned"),
-		styleSubtle.Render(s.pkgName) + "  " + styleYellow.Render("["+pinnedVer+"]"),
+		styleYellowBold.Render("Remove redundant definition?"),
+		styleSubtle.Render(c.Package),
 		"",
-		styleMuted.Render("Update to " + s.newVersion + " anyway?"),
+		styleMuted.Render("keep    ") + styleText.Render(c.EffectiveVersion) + "  " + styleCyan.Render(filepath.Base(c.EffectiveFile)),
+		styleMuted.Render("remove  ") + styleText.Render(c.RedundantVersion) + "  " + styleCyan.Render(filepath.Base(c.RedundantFile)),
 	}
 	box := styleOverlay.
 		Width(w).
@@ -62,6 +62,9 @@ func (m *App) openTransitiveDepTree() bubble_tea.Cmd {
 	if proj == nil {
 		return m.setStatus("▲ Select a project first", true)
 	}
+	if cmd := m.sdkStatus([]*ParsedProject{proj}); cmd != nil {
+		return cmd
+	}
 	m.ctx.StatusLine = ""
 	m.depTree = newDepTreeOverlay(m, proj.FileName+" (transitive packages)", true)
 	return runDepTreeCmd(proj)
@@ -3,8 +3,11 @@ package main
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	bubble_tea "charm.land/bubbletea/v2"
 	lipgloss "charm.land/lipgloss/v2"
 )
 
@@ -43,6 +46,9 @@ func (m *App) renderDetailPanel(w int) string {
 	}
 
 	title := styleSubtleBold.Render("Package Detail")
+	if m.detail.project {
+		title = styleSubtleBold.Render("Project Detail")
+	}
 	divider := styleBorder.Render(strings.Repeat("─", w-4))
 
 	content := lipgloss.JoinVertical(lipgloss.Left, title, divider, m.detail.vp.View())
@@ -50,9 +56,143 @@ func (m *App) renderDetailPanel(w int) string {
 	return renderToPanel(s, w, m.bodyOuterHeight(), content)
 }
 
+// renderBrokenProjectDetail explains why a project failed to parse.
+func (m *App) renderBrokenProjectDetail(p *ParsedProject) string {
+	w := m.detail.vp.Width() - 2
+	if w < 10 {
+		w = 10
+	}
+	var s strings.Builder
+	s.WriteString(styleRedBold.Render(p.FileName) + "\n\n")
+	s.WriteString(styleMuted.Render("Path") + "\n")
+	s.WriteString(styleSubtle.Render(wordWrap(p.FilePath, w)) + "\n\n")
+	s.WriteString(styleMuted.Render("Error") + "\n")
+	s.WriteString(styleRed.Render(wordWrap(p.LoadErr.Error(), w)) + "\n\n")
+	s.WriteString(styleMuted.Render(wordWrap("Fix the file and it will be reloaded automatically, or press ctrl+r.", w)) + "\n")
+	return s.String()
+}
+
+// renderProjectDetail describes the selected project: its identity
+// properties, with SDK defaults muted, then its frameworks and references.
+func (m *App) renderProjectDetail(p *ParsedProject) string {
+	w := m.detail.vp.Width() - 2
+	if w < 10 {
+		w = 10
+	}
+	var s strings.Builder
+	if p == nil {
+		s.WriteString(styleAccentBold.Render("All Projects") + "\n\n")
+		s.WriteString(styleSubtle.Render(fmt.Sprintf("%d projects", len(m.ctx.ParsedProjects))) + "\n\n")
+		s.WriteString(styleMuted.Render(wordWrap("Select a project to see its metadata.", w)) + "\n")
+		return s.String()
+	}
+
+	s.WriteString(styleAccentBold.Render(p.FileName) + "\n\n")
+	s.WriteString(styleMuted.Render("Path") + "\n")
+	s.WriteString(styleSubtle.Render(wordWrap(p.FilePath, w)) + "\n\n")
+
+	s.WriteString(styleMuted.Render("Metadata") + "\n")
+	for _, f := range p.metadataFields() {
+		name := fmt.Sprintf("  %-14s ", f.Name)
+		if f.Default {
+			s.WriteString(styleMuted.Render(name) + styleMuted.Render(truncate(f.Value, w-17)+" (default)") + "\n")
+		} else {
+			s.WriteString(styleMuted.Render(name) + styleText.Render(truncate(f.Value, w-17)) + "\n")
+		}
+	}
+
+	s.WriteString(m.renderProjectPublishing(p, w))
+
+	var fws []string
+	for fw := range p.TargetFrameworks {
+		fws = append(fws, fw.String())
+	}
+	slices.Sort(fws)
+	if len(fws) > 0 {
+		s.WriteString("\n" + styleMuted.Render("Frameworks") + "\n")
+		for _, fw := range fws {
+			s.WriteString(styleSubtle.Render("  "+fw) + "\n")
+		}
+	}
+
+	s.WriteString("\n" + styleMuted.Render("References") + "\n")
+	s.WriteString(styleSubtle.Render(fmt.Sprintf("  %d packages", p.Packages.Len())) + "\n")
+	if n := len(p.ProjectRefs); n > 0 {
+		s.WriteString(styleSubtle.Render(fmt.Sprintf("  %d project references", n)) + "\n")
+	}
+	switch {
+	case p.PackagesConfig != "":
+		s.WriteString("\n" + styleYellow.Render(wordWrap("Legacy (non-SDK) project using packages.config: versions can be updated, which also moves the packages folder paths in "+p.FileName+"; add and remove packages in Visual Studio, and restore with nuget restore.", w)) + "\n")
+	case p.Legacy:
+		s.WriteString("\n" + styleYellow.Render(wordWrap("Legacy (non-SDK) project: shown read-only.", w)) + "\n")
+	}
+	return s.String()
+}
+
+// renderProjectPublishing shows the package a packable project would
+// produce, whether its push source already has that version, and the
+// package metadata it is missing.
+func (m *App) renderProjectPublishing(p *ParsedProject, w int) string {
+	id, version, ok := p.packageIdentity()
+	if !ok {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString("\n" + styleMuted.Render("Publishing") + "\n")
+	s.WriteString("  " + styleText.Render(truncate(id+" "+version, w-2)) + "\n")
+	check := m.publishChecks[publishCheckKey(p, id, version)]
+	switch {
+	case check == nil:
+		s.WriteString("  " + m.ctx.Spinner.View() + styleMuted.Render("checking the push source...") + "\n")
+	case check.Err != nil && check.Source == "":
+		s.WriteString(styleMuted.Render(wordWrap("Not checked: "+check.Err.Error(), w)) + "\n")
+	case check.Err != nil:
+		s.WriteString(styleYellow.Render(wordWrap("Couldn't check "+check.Source+": "+check.Err.Error(), w)) + "\n")
+	case check.Exists:
+		s.WriteString(styleRed.Render(wordWrap("✗ "+version+" is already on "+check.Source+"; bump the version before packing", w)) + "\n")
+	default:
+		s.WriteString(styleGreen.Render(wordWrap("✓ "+version+" is not on "+check.Source+" yet", w)) + "\n")
+	}
+	if lints := p.missingPackageMetadata(); len(lints) > 0 {
+		for _, l := range lints {
+			s.WriteString(styleYellow.Render(truncate("⚠ no "+l.Property, w)) + "\n")
+		}
+		s.WriteString(styleMuted.Render(wordWrap("m adds the missing metadata", w)) + "\n")
+	}
+	return s.String()
+}
+
+// checkSelectedPublish starts the publish check for the selected project,
+// once per package identity.
+func (m *App) checkSelectedPublish() bubble_tea.Cmd {
+	p := m.selectedProject()
+	if p == nil {
+		return nil
+	}
+	id, version, ok := p.packageIdentity()
+	if !ok {
+		return nil
+	}
+	key := publishCheckKey(p, id, version)
+	if _, started := m.publishChecks[key]; started {
+		return nil
+	}
+	if m.publishChecks == nil {
+		m.publishChecks = make(map[string]*publishCheck)
+	}
+	m.publishChecks[key] = nil
+	services := m.ctx.NugetServices
+	return func() bubble_tea.Msg {
+		return publishCheckMsg{key: key, check: checkPublish(p, id, version, services)}
+	}
+}
+
 func (m *App) renderDetail(row packageRow) string {
 	if row.err != nil {
-		return styleRed.Render("Error: " + row.err.Error())
+		// A package the solution builds itself may not be on any feed yet.
+		return styleRed.Render("Error: "+row.err.Error()) + "\n\n" +
+			styleMuted.Render("Press e to retry failed packages.") + "\n\n" +
+			m.renderDetailLocalPackage(row, m.detail.vp.Width()-2)
 	}
 	if row.loading {
 		return m.ctx.Spinner.View() + " " + styleAccent.Render("Loading package data...")
@@ -68,10 +208,20 @@ func (m *App) renderDetail(row packageRow) string {
 
 	var s strings.Builder
 	s.WriteString(m.renderDetailHeader(row, w))
+	s.WriteString(m.renderDetailLag(row))
+	s.WriteString(m.renderDetailLicense(row, w))
 	s.WriteString(m.renderDetailVulnerabilities(row))
+	s.WriteString(m.renderDetailRestoreWarnings(row, w))
+	s.WriteString(m.renderDetailTransitive(row, w))
+	s.WriteString(m.renderDetailNote(row, w))
+	s.WriteString(m.renderDetailLocalPackage(row, w))
+	s.WriteString(m.renderDetailRule(row))
+	s.WriteString(m.renderDetailRename(row))
 	s.WriteString(m.renderDetailDeprecation(row, w))
 	s.WriteString(m.renderDetailSource(row))
 	s.WriteString(m.renderDetailDefinedIn(row))
+	s.WriteString(m.renderDetailMetadata(row, w))
+	s.WriteString(m.renderDetailConflict(row))
 	s.WriteString(m.renderDetailProjectVersions(row))
 	s.WriteString(m.renderDetailVersionList(row, w))
 	s.WriteString(m.renderDetailFrameworks(row))
@@ -93,9 +243,14 @@ func (m *App) renderDetailHeader(row packageRow, w int) string {
 	name := hyperlink(pkgLink, styleAccentBold.Render(row.info.ID))
 	s.WriteString(name + "\n\n")
 
-	// description
-	if row.info.Description != "" {
-		s.WriteString(styleSubtle.Render(wordWrap(row.info.Description, w)) + "\n\n")
+	// description, from the project that builds the package when it is
+	// local: the feed may still carry an older one
+	desc := row.info.Description
+	if p := localPackageProject(m.ctx.ParsedProjects, row.ref.Name); p != nil && p.Metadata.Description != "" {
+		desc = p.Metadata.Description
+	}
+	if desc != "" {
+		s.WriteString(styleSubtle.Render(wordWrap(desc, w)) + "\n\n")
 	}
 
 	// authors
@@ -105,23 +260,68 @@ func (m *App) renderDetailHeader(row packageRow, w int) string {
 			authors = append(authors, a)
 		}
 		s.WriteString(styleMuted.Render("Authors") + "\n")
-		s.WriteString(styleText.Render(strings.Join(authors, ", ")) + "\n\n")
+		s.WriteString(styleText.Render(strings.Join(authors, ", ")) + "\n")
+		s.WriteString(styleMuted.Render("w for more from this owner/author") + "\n\n")
 	}
 
 	return s.String()
 }
 
-func (m *App) renderDetailVulnerabilities(row packageRow) string {
-	if !row.vulnerable {
-		return ""
-	}
-	var vulns []PackageVulnerability
+// renderDetailLag shows when the installed version was released and how far
+// it trails the newest stable release.
+func (m *App) renderDetailLag(row packageRow) string {
+	installed := row.effectiveVersion()
+	var published string
 	for _, v := range row.info.Versions {
-		if v.SemVer.String() == row.ref.Version.String() {
-			vulns = v.Vulnerabilities
+		if v.SemVer.String() == installed.String() {
+			published = timeAgo(v.Published)
 			break
 		}
 	}
+	lag, ok := row.info.ReleaseLag(installed)
+	if published == "" && !ok {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(styleMuted.Render("Installed") + "\n")
+	line := styleText.Render(installed.String())
+	if declared := row.ref.Version; !row.diverged && !row.ref.Locked && declared.Range != "" {
+		line += styleMuted.Render("  from " + declared.Range)
+	}
+	if published != "" {
+		line += styleMuted.Render("  released " + published)
+	}
+	if ok {
+		if lag > 0 {
+			line += "  " + styleYellow.Render(formatLag(lag)+" latest")
+		} else {
+			line += "  " + styleGreen.Render("latest")
+		}
+	}
+	s.WriteString(line + "\n\n")
+	return s.String()
+}
+
+// renderDetailLicense shows the installed version's license and warns when
+// the latest compatible version declares a different one.
+func (m *App) renderDetailLicense(row packageRow, w int) string {
+	license := row.license()
+	if license == "" {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(styleMuted.Render("License") + "\n")
+	s.WriteString(hyperlink(licenseLink(license), styleText.Render(wordWrap(license, w))) + "\n")
+	if _, to, changed := row.licenseChange(); changed {
+		msg := fmt.Sprintf("⚠ %s changes the license to %s", row.latestCompatible.SemVer.String(), to)
+		s.WriteString(styleYellow.Render(wordWrap(msg, w)) + "\n")
+	}
+	s.WriteString("\n")
+	return s.String()
+}
+
+func (m *App) renderDetailVulnerabilities(row packageRow) string {
+	vulns := row.installedVulnerabilities()
 	if len(vulns) == 0 {
 		return ""
 	}
@@ -130,26 +330,133 @@ func (m *App) renderDetailVulnerabilities(row packageRow) string {
 	s.WriteString(styleRedBold.Render("Vulnerabilities") + "\n")
 	for _, vuln := range vulns {
 		sev := vuln.SeverityLabel()
-		var sevStyle lipgloss.Style
-		switch sev {
-		case "critical", "high":
-			sevStyle = styleRedBold
-		case "moderate":
-			sevStyle = styleYellowBold
-		default:
-			sevStyle = styleTextBold
-		}
-		sevStr := sevStyle.Render(sev)
+		sevStr := severityStyle(sev).Render(sev)
 		label := hyperlink(vuln.AdvisoryURL, styleSubtle.Render(advisoryLabel(vuln.AdvisoryURL)))
 		s.WriteString("  " + sevStr + "  " + label + "\n")
 	}
+	if row.minFixed != nil {
+		s.WriteString(styleMuted.Render("  fixed in ") + styleGreen.Render(row.minFixed.SemVer.String()) + styleMuted.Render("  (f to apply)") + "\n")
+	} else {
+		s.WriteString(styleMuted.Render("  no compatible fixed version") + "\n")
+	}
+	s.WriteString(styleMuted.Render("  enter for advisory details") + "\n")
+	s.WriteString("\n")
+	return s.String()
+}
+
+// renderDetailRestoreWarnings lists the NuGet codes the last restore logged
+// for the package, with what each means.
+func (m *App) renderDetailRestoreWarnings(row packageRow, w int) string {
+	if len(row.restoreWarnings) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(styleYellowBold.Render("Restore warnings") + "\n")
+	for _, code := range row.restoreWarnings {
+		line := styleYellow.Render(code)
+		if summary := nugetCodeSummary(code); summary != "" {
+			line += "  " + styleText.Render(wordWrap(summary, max(10, w-len(code)-2)))
+		}
+		s.WriteString("  " + line + "\n")
+	}
+	s.WriteString(styleMuted.Render("  D for the last restore's messages") + "\n")
+	s.WriteString("\n")
+	return s.String()
+}
+
+// severityStyle returns the style for an advisory severity label.
+func severityStyle(label string) lipgloss.Style {
+	switch strings.ToLower(label) {
+	case "critical", "high":
+		return styleRedBold
+	case "moderate", "medium":
+		return styleYellowBold
+	default:
+		return styleTextBold
+	}
+}
+
+// renderDetailNote shows the team's note and tags on the package.
+func (m *App) renderDetailNote(row packageRow, w int) string {
+	note := m.ctx.Notes.For(row.ref.Name)
+	if note.empty() {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(styleMuted.Render("Team note") + "\n")
+	if note.Note != "" {
+		s.WriteString(styleText.Render(wordWrap(note.Note, w)) + "\n")
+	}
+	if len(note.Tags) > 0 {
+		tags := make([]string, len(note.Tags))
+		for i, t := range note.Tags {
+			tags[i] = "#" + t
+		}
+		s.WriteString(styleCyan.Render(wordWrap(strings.Join(tags, " "), w)) + "\n")
+	}
+	s.WriteString(styleMuted.Render("N to edit") + "\n\n")
+	return s.String()
+}
+
+// renderDetailLocalPackage names the workspace project that packs the
+// package and shows the <PackageReleaseNotes> it will ship with, which the
+// feed only has once that version is published.
+func (m *App) renderDetailLocalPackage(row packageRow, w int) string {
+	p := localPackageProject(m.ctx.ParsedProjects, row.ref.Name)
+	if p == nil {
+		return ""
+	}
+	_, version, _ := p.packageIdentity()
+	var s strings.Builder
+	s.WriteString(styleMuted.Render("Built in this workspace") + "\n")
+	s.WriteString(styleSubtle.Render(truncate(p.FileName+" packs "+version, w)) + "\n")
+	if notes := releaseNotesText(p.Metadata.PackageReleaseNotes); notes != "" {
+		s.WriteString(styleMuted.Render("Release notes (PackageReleaseNotes)") + "\n")
+		s.WriteString(renderMarkdown(notes, w) + "\n")
+	} else {
+		s.WriteString(styleMuted.Render(wordWrap("No <PackageReleaseNotes> in the project", w)) + "\n")
+	}
+	s.WriteString(styleMuted.Render("J jumps to the project") + "\n\n")
+	return s.String()
+}
+
+// renderDetailRule shows the package's update rule from settings.
+func (m *App) renderDetailRule(row packageRow) string {
+	desc := row.rule.Describe()
+	if desc == "" {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(styleMuted.Render("Update rule") + "\n")
+	s.WriteString(styleText.Render(desc) + "\n")
+	if row.rule.Reason != "" {
+		s.WriteString(styleSubtle.Render(row.rule.Reason) + "\n")
+	}
 	s.WriteString("\n")
 	return s.String()
 }
 
+func (m *App) renderDetailRename(row packageRow) string {
+	if row.renamedTo == "" {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(styleYellowBold.Render("Renamed") + "\n")
+	s.WriteString(styleMuted.Render("Now published as ") + styleText.Render(row.renamedTo) + "\n")
+	s.WriteString(styleMuted.Render("p to replace") + "\n\n")
+	return s.String()
+}
+
 func (m *App) renderDetailDeprecation(row packageRow, w int) string {
 	if !row.info.Deprecated {
-		return ""
+		if !row.info.Abandoned(time.Now()) {
+			return ""
+		}
+		var s strings.Builder
+		s.WriteString(styleYellowBold.Render("Possibly abandoned") + "\n")
+		s.WriteString(styleText.Render("No release since "+timeAgo(row.info.LastPublished())) + "\n")
+		s.WriteString(styleMuted.Render("g for alternatives") + "\n\n")
+		return s.String()
 	}
 	var s strings.Builder
 	s.WriteString(styleYellowBold.Render("Deprecated") + "\n")
@@ -159,6 +466,7 @@ func (m *App) renderDetailDeprecation(row packageRow, w int) string {
 	if row.info.AlternatePackageID != "" {
 		s.WriteString(styleMuted.Render("Use instead: ") + styleText.Render(row.info.AlternatePackageID) + "\n")
 	}
+	s.WriteString(styleMuted.Render("g for alternatives") + "\n")
 	s.WriteString("\n")
 	return s.String()
 }
@@ -169,7 +477,7 @@ func (m *App) renderDetailSource(row packageRow) string {
 	sourceURL := ""
 	for _, svc := range m.ctx.NugetServices {
 		if strings.EqualFold(svc.SourceName(), row.source) {
-			sourceURL = svc.PackageURL(row.info.ID, row.ref.Version.String(), row.info.ProjectURL)
+			sourceURL = svc.PackageURL(row.info.ID, row.installedVersion().String(), row.info.ProjectURL)
 			break
 		}
 	}
@@ -187,12 +495,68 @@ func (m *App) renderDetailDefinedIn(row packageRow) string {
 	if sel == nil {
 		return ""
 	}
+	legacy := ""
+	switch {
+	case sel.Legacy && sel.canUpdatePackage(row.ref.Name):
+		legacy = styleYellow.Render("legacy project · version updates only") + "\n\n"
+	case sel.Legacy:
+		legacy = styleYellow.Render("legacy project · read-only") + "\n\n"
+	}
 	sourceFile := sel.SourceFileForPackage(row.ref.Name)
 	if sourceFile == sel.FilePath {
-		return ""
+		return legacy
+	}
+	kind := ""
+	switch row.ref.Kind {
+	case RefGlobal:
+		kind = styleSubtle.Render("global package reference · applies to every project") + "\n"
+	case RefTransitivePin:
+		kind = styleSubtle.Render("transitive pin · not referenced directly") + "\n"
 	}
 	return styleMuted.Render("Defined in") + "\n" +
-		styleCyan.Render(filepath.Base(sourceFile)) + "\n\n"
+		styleCyan.Render(filepath.Base(sourceFile)) + "\n" + kind + "\n" + legacy
+}
+
+// renderDetailMetadata lists extra PackageReference metadata such as
+// Aliases, GeneratePathProperty or NoWarn, which change how the package is
+// consumed and are easy to miss in the project file.
+func (m *App) renderDetailMetadata(row packageRow, w int) string {
+	p := m.selectedProject()
+	if p == nil {
+		p = row.project
+	}
+	if p == nil {
+		return ""
+	}
+	md := p.PackageMetadata[strings.ToLower(row.ref.Name)]
+	if len(md) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(styleMuted.Render("Metadata") + "\n")
+	for _, e := range md {
+		s.WriteString(styleSubtle.Render("  "+e.Name+" ") + styleText.Render(truncate(e.Value, max(w-len(e.Name)-3, 4))) + "\n")
+	}
+	s.WriteString("\n")
+	return s.String()
+}
+
+// renderDetailConflict shows both locations of a package that is versioned in
+// more than one file of the import chain.
+func (m *App) renderDetailConflict(row packageRow) string {
+	p, c := m.versionConflictFor(row)
+	if c == nil {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(styleYellowBold.Render("Version conflict") + "\n")
+	if m.selectedProject() == nil {
+		s.WriteString(styleSubtle.Render("  in "+p.FileName) + "\n")
+	}
+	s.WriteString("  " + styleText.Render(c.EffectiveVersion) + " " + styleCyan.Render(filepath.Base(c.EffectiveFile)) + styleMuted.Render(" (effective)") + "\n")
+	s.WriteString("  " + styleMuted.Render(c.RedundantVersion) + " " + styleCyan.Render(filepath.Base(c.RedundantFile)) + styleMuted.Render(" (redundant)") + "\n")
+	s.WriteString(styleMuted.Render("  press x to remove the redundant definition") + "\n\n")
+	return s.String()
 }
 
 func (m *App) renderDetailProjectVersions(row packageRow) string {
@@ -206,9 +570,9 @@ func (m *App) renderDetailProjectVersions(row packageRow) string {
 		for ref := range p.Packages {
 			if ref.Name == row.ref.Name {
 				proj := styleSubtle.Render(fmt.Sprintf("  %-20s", truncate(p.FileName, 20)))
-				ver := styleText.Render(ref.Version.String())
+				ver := styleText.Render(ref.Version.Declared())
 				if ref.Locked {
-					ver = styleYellow.Render("[") + ver + styleYellow.Render("]")
+					ver = styleYellow.Render("[") + styleText.Render(ref.Version.String()) + styleYellow.Render("]")
 				}
 				line := proj + " " + ver
 				sourceFile := p.SourceFileForPackage(ref.Name)
@@ -242,12 +606,13 @@ func (m *App) renderDetailVersionList(row packageRow, w int) string {
 	s.WriteString(styleMuted.Render("Versions") + "\n")
 	const limit = 12
 
-	installedStr := row.ref.Version.String()
+	installed := row.installedVersion()
+	installedStr := installed.String()
 	oldestStr := ""
 	if row.diverged {
-		oldestStr = row.oldest.String()
+		oldestStr = row.info.Resolve(row.oldest).String()
 	}
-	curMajor, curMinor := row.ref.Version.Major, row.ref.Version.Minor
+	curMajor, curMinor := installed.Major, installed.Minor
 	latestPatchStr := ""
 	for _, v := range displayVersions {
 		if v.SemVer.Major == curMajor && v.SemVer.Minor == curMinor && !v.SemVer.IsPreRelease() {
@@ -282,7 +647,7 @@ func (m *App) renderDetailVersionList(row packageRow, w int) string {
 		switch {
 		case isCurrent:
 			vStyle = styleAccent
-			marker = "▶ "
+			marker = glyphs.Cursor
 		case isCompat:
 			vStyle = styleYellow
 			marker = "↑ "
@@ -332,6 +697,9 @@ func (m *App) renderDetailVersionList(row packageRow, w int) string {
 			renderVRow(pv)
 		}
 	}
+	if row.info.TrimmedVersions > 0 {
+		s.WriteString(styleMuted.Render(fmt.Sprintf("  %d older versions not loaded (v to browse all)", row.info.TrimmedVersions)) + "\n")
+	}
 
 	return s.String()
 }
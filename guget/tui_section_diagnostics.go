@@ -0,0 +1,89 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+func (s *diagnosticsOverlay) FooterKeys() []kv {
+	return []kv{{"↑↓", "scroll"}, {"esc", "close"}}
+}
+
+func (s *diagnosticsOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+		s.refreshView()
+	case "]":
+		s.Resize(4)
+		s.refreshView()
+	case "esc", "!", "q":
+		s.closeOverlay()
+	default:
+		var cmd bubble_tea.Cmd
+		s.vp, cmd = s.vp.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// diagnosticProjects returns the projects whose diagnostics should be shown:
+// the selected project, or every project when "All Projects" is selected.
+func (s *diagnosticsOverlay) diagnosticProjects() []*ParsedProject {
+	if sel := s.app.selectedProject(); sel != nil {
+		return []*ParsedProject{sel}
+	}
+	return s.app.allProjects()
+}
+
+func (s *diagnosticsOverlay) refreshView() {
+	w := s.Width()
+	innerW := w - 6 // border (2) + padding (2*2)
+
+	var lines []string
+	lines = append(lines, styleAccentBold.Render("Parse Diagnostics"))
+	lines = append(lines, styleBorder.Render(strings.Repeat("─", innerW)))
+
+	total := 0
+	for _, p := range s.diagnosticProjects() {
+		if len(p.Diagnostics) == 0 {
+			continue
+		}
+		total += len(p.Diagnostics)
+		lines = append(lines, styleTextBold.Render(p.FileName))
+		for _, d := range p.Diagnostics {
+			label := d.Kind.label()
+			file := filepath.Base(d.File)
+			lines = append(lines, "  "+styleYellow.Render(label)+"  "+styleCyan.Render(truncate(file, innerW-len(label)-4)))
+			for _, ln := range strings.Split(wordWrap(d.Message, innerW-4), "\n") {
+				lines = append(lines, "    "+styleSubtle.Render(ln))
+			}
+		}
+		lines = append(lines, "")
+	}
+	if total == 0 {
+		lines = append(lines, styleMuted.Render("No parse issues found"))
+	}
+
+	// Available height for content inside the overlay box:
+	// overlay area - border (2) - padding (2) - margin (2)
+	maxH := s.app.overlayHeight() - 6
+	if maxH < 8 {
+		maxH = 8
+	}
+
+	s.vp.SetWidth(w - 4)
+	s.vp.SetHeight(maxH)
+	s.vp.SetContent(strings.Join(lines, "\n"))
+	s.vp.GotoTop()
+}
+
+func (s *diagnosticsOverlay) Render() string {
+	box := styleOverlay.
+		Width(s.Width()).
+		Render(s.vp.View())
+
+	return s.centerOverlay(box)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// openFailureSummary shows the grouped load failures, if there are any.
+// Reports whether the overlay opened.
+func (m *App) openFailureSummary() bool {
+	groups := groupLoadFailures(m.ctx.Results)
+	if len(groups) == 0 {
+		return false
+	}
+	m.failures = failureSummary{
+		sectionBase: sectionBase{app: m, baseWidth: 76, minWidth: 48, maxMargin: 4, active: true},
+		groups:      groups,
+	}
+	return true
+}
+
+func (s *failureSummary) FooterKeys() []kv {
+	return []kv{{"↑↓", "nav"}, {"enter", "retry group"}, {"a", "retry all"}, {"esc", "close"}}
+}
+
+func (s *failureSummary) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q", "E":
+		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.groups)-1 {
+			s.cursor++
+		}
+	case "enter":
+		if s.cursor < len(s.groups) {
+			names := s.groups[s.cursor].Packages
+			s.closeOverlay()
+			return s.app.retryPackages(names)
+		}
+	case "a":
+		s.closeOverlay()
+		return s.app.retryFailedPackages()
+	}
+	return nil
+}
+
+func (s *failureSummary) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	total := 0
+	for _, g := range s.groups {
+		total += len(g.Packages)
+	}
+	lines := []string{
+		styleRedBold.Render(fmt.Sprintf("%d package(s) failed to load", total)),
+		styleBorder.Render(strings.Repeat("─", inner)),
+	}
+
+	// Keep the list within the overlay; each group takes three lines plus a gap.
+	maxGroups := max(1, (s.app.overlayHeight()-8)/4)
+	start := 0
+	if s.cursor >= maxGroups {
+		start = s.cursor - maxGroups + 1
+	}
+	end := min(len(s.groups), start+maxGroups)
+
+	for i := start; i < end; i++ {
+		g := s.groups[i]
+		prefix := "  "
+		titleStyle := styleText
+		if i == s.cursor {
+			prefix = styleAccentBold.Render(glyphs.Cursor)
+			titleStyle = styleAccentBold
+		}
+		kindStyle := styleRed
+		if g.Kind == failureTimeout {
+			kindStyle = styleYellow
+		}
+		title := titleStyle.Render(truncate(g.Source, inner/2)) + "  " +
+			kindStyle.Render(g.Kind.String()) + "  " +
+			styleMuted.Render(fmt.Sprintf("%d package(s)", len(g.Packages)))
+		lines = append(lines,
+			prefix+title,
+			"    "+styleSubtle.Render(truncate(strings.Join(g.Packages, ", "), inner-4)),
+			"    "+styleMuted.Render(truncate(g.Kind.hint(), inner-4)),
+			"",
+		)
+	}
+	if end < len(s.groups) {
+		lines = append(lines, styleMuted.Render(fmt.Sprintf("  … %d more group(s)", len(s.groups)-end)), "")
+	}
+
+	lines = append(lines, styleMuted.Render(wordWrap("Press E to see this summary again; failed rows are marked ✗.", inner)))
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
@@ -38,7 +38,7 @@ func (s *helpOverlay) refreshView() {
 		{
 			title: "Navigation",
 			rows: [][2]string{
-				{"tab / shift+tab", "cycle focus between panels"},
+				{"tab / shift+tab", "cycle focus between panels (projects panel: detail shows project metadata)"},
 				{"↑ / ↓  or  j / k", "move up / down in list"},
 				{"enter", "switch focus to packages panel"},
 			},
@@ -50,21 +50,50 @@ func (s *helpOverlay) refreshView() {
 				{"U", "update to latest compatible (all projects)"},
 				{"a", "update to latest stable (this project)"},
 				{"A", "update to latest stable (all projects)"},
+				{"ctrl+u", "update every outdated package in view (plan preview)"},
+				{"f", "update to minimum fixed version (this project)"},
+				{"F", "update to minimum fixed version (all projects)"},
+				{"S", "security update: fix every vulnerable package in view"},
 				{"v", "pick a specific version from the list"},
 				{"d", "delete selected package from project"},
+				{"x", "remove redundant version definition (conflict)"},
 				{"t", "show declared dependency tree for package"},
-				{"n", "view release notes (GitHub or NuGet)"},
+				{"w", "search for more packages by the same owner / author"},
+				{"g", "suggest alternatives to a deprecated or abandoned package"},
+				{"p", "replace with another package (renamed: successor pre-filled)"},
+				{"N", "edit the team note and tags on the package (.guget/notes.json)"},
+				{"J", "jump to the workspace project that builds the package (local)"},
+				{"enter", "show advisory details (vulnerable package)"},
+				{"n", "view release notes, incl. changes since the installed version"},
+				{"M", "read the package README (rendered markdown)"},
 				{"o", "cycle sort order"},
 				{"O", "change sort direction"},
+				{"i", "filter the list by package name (substring or fuzzy); esc clears"},
+				{"I", "cycle view: vulnerable, outdated, deprecated, each source, all"},
 			},
 		},
 		{
 			title: "Project actions",
 			rows: [][2]string{
 				{"ctrl+r", "reload projects from disk"},
+				{"ctrl+z", "undo the last change to a project file"},
+				{"Z", "list this session's changes and revert any of them"},
+				{"e", "retry packages that failed or timed out"},
+				{"ctrl+f", "refresh the selected package, bypassing the cache"},
+				{"E", "show load failures grouped by source and cause"},
 				{"r", "run dotnet restore (selected project)"},
 				{"R", "run dotnet restore (all projects)"},
+				{"D", "watch a running restore, or show the last one's results and NuGet warnings"},
+				{"x", "cancel a running restore (from its progress view)"},
 				{"T", "show full transitive dependency tree"},
+				{"V", "scan every project for vulnerable transitive dependencies"},
+				{"G", "show project references and the projects that depend on this one"},
+				{"m", "projects panel: add the package metadata a packable project is missing"},
+				{"t", "projects panel: preview retargeting to another framework, and write it"},
+				{"W", "workspace: align package versions that differ between repositories"},
+				{"H", "show changes since the newest snapshot"},
+				{"C", "show NuGet cache sizes and clear caches"},
+				{"X", "export a JSON, SARIF, or Markdown report"},
 				{"/", "search NuGet and add a package"},
 			},
 		},
@@ -72,9 +101,14 @@ func (s *helpOverlay) refreshView() {
 			title: "Version picker  (v)",
 			rows: [][2]string{
 				{"↑ / ↓  or  j / k", "move cursor"},
+				{"← / →  or  h / l", "close / open a major version group"},
+				{"/", "filter versions, e.g. 6.0 or rc (esc clears)"},
+				{"p / c", "hide pre-releases / incompatible versions"},
 				{"u", "apply version (this project)"},
 				{"U", "apply version (all projects)"},
-				{"enter", "apply version"},
+				{"enter", "apply version, or open/close the group on a header"},
+				{"i", "estimate restore impact"},
+				{"x", "unlist/delete version on its feed (typed confirm)"},
 				{"esc / q", "close picker"},
 			},
 		},
@@ -97,8 +131,18 @@ func (s *helpOverlay) refreshView() {
 			title: "View toggles",
 			rows: [][2]string{
 				{"[ / ]", "resize focused panel"},
+				{"=", "reset panel sizes"},
 				{"l", "toggle log panel"},
 				{"s", "toggle sources panel"},
+				{"c", "inspect the effective nuget.config settings"},
+				{"b", "browse a source: top packages, by tag, by owner"},
+				{"L", "toggle license column"},
+				{"*", "star / unstar the selected package or project"},
+				{"ctrl+s", "show only starred packages and projects"},
+				{"P", "include pre-releases in latest versions, status, and search"},
+				{"m", "sources panel: rewrite moved or deprecated source URLs in nuget.config"},
+				{"r", "sources panel: retry a source that failed to initialise"},
+				{"!", "show parse diagnostics"},
 				{"?", "toggle this help"},
 				{"esc / q / ctrl+c", "quit"},
 			},
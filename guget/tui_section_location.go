@@ -31,7 +31,9 @@ func (m *App) addPackageToProject(pkgName, version string, project *ParsedProjec
 	filePath := project.FilePath
 	return func() bubble_tea.Msg {
 		logInfo("AddPackageReference: %s %s → %s", pkgName, version, filePath)
-		if err := AddPackageReference(filePath, pkgName, version); err != nil {
+		err := AddPackageReference(filePath, pkgName, version)
+		recordAction(ActionRecord{Action: "add", Package: pkgName, Version: version, Files: []string{filePath}}, err)
+		if err != nil {
 			return writeResultMsg{err: err}
 		}
 		return writeResultMsg{err: nil}
@@ -42,6 +44,9 @@ func (m *App) addPackageToProject(pkgName, version string, project *ParsedProjec
 // AddTargets (e.g. Directory.Build.props, CPM, imported props). If the project
 // is a .props file or has only one target, it adds directly.
 func (m *App) openLocationPickerOrAdd(pkgName, version string, project *ParsedProject) bubble_tea.Cmd {
+	if cmd := m.readOnlySessionStatus(); cmd != nil {
+		return cmd
+	}
 	// Props files: add directly, no picker needed.
 	if strings.HasSuffix(strings.ToLower(project.FilePath), ".props") {
 		return m.addPackageToProject(pkgName, version, project)
@@ -104,6 +109,9 @@ func (s *locationPicker) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 // For CPM targets, it performs a dual write: PackageVersion to the CPM file
 // and a version-less PackageReference to the project file.
 func (m *App) addPackageToLocation(pkgName, version string, project *ParsedProject, target AddTarget) bubble_tea.Cmd {
+	if cmd := m.readOnlySessionStatus(); cmd != nil {
+		return cmd
+	}
 	project.Packages.Add(PackageReference{Name: pkgName, Version: ParseSemVer(version)})
 	project.PackageSources[strings.ToLower(pkgName)] = target.FilePath
 
@@ -156,25 +164,28 @@ func (m *App) addPackageToLocation(pkgName, version string, project *ParsedProje
 	projectFilePath := project.FilePath
 	targetFilePath := target.FilePath
 	targetKind := target.Kind
+	framework := target.Framework
 
 	return func() bubble_tea.Msg {
-		switch targetKind {
-		case AddTargetCPM:
+		rec := ActionRecord{Action: "add", Package: pkgName, Version: version, Framework: framework, Files: []string{targetFilePath}}
+		var err error
+		switch {
+		case framework != "":
+			logInfo("AddPackageReference (%s): %s %s → %s", framework, pkgName, version, targetFilePath)
+			err = AddPackageReferenceForFramework(targetFilePath, pkgName, version, framework)
+		case targetKind == AddTargetCPM:
 			logInfo("AddPackageVersion: %s %s → %s", pkgName, version, targetFilePath)
-			if err := AddPackageVersion(targetFilePath, pkgName, version); err != nil {
-				return writeResultMsg{err: err}
-			}
-			logInfo("AddPackageReference (CPM): %s → %s", pkgName, projectFilePath)
-			if err := AddPackageReference(projectFilePath, pkgName, ""); err != nil {
-				return writeResultMsg{err: err}
+			if err = AddPackageVersion(targetFilePath, pkgName, version); err == nil {
+				logInfo("AddPackageReference (CPM): %s → %s", pkgName, projectFilePath)
+				rec.Files = append(rec.Files, projectFilePath)
+				err = AddPackageReference(projectFilePath, pkgName, "")
 			}
 		default:
 			logInfo("AddPackageReference: %s %s → %s", pkgName, version, targetFilePath)
-			if err := AddPackageReference(targetFilePath, pkgName, version); err != nil {
-				return writeResultMsg{err: err}
-			}
+			err = AddPackageReference(targetFilePath, pkgName, version)
 		}
-		return writeResultMsg{err: nil}
+		recordAction(rec, err)
+		return writeResultMsg{err: err}
 	}
 }
 
@@ -220,7 +231,7 @@ func (s *locationPicker) Render() string {
 		prefix := "  "
 		nameStyle := styleMuted
 		if i == s.cursor {
-			prefix = "▶ "
+			prefix = glyphs.Cursor
 			nameStyle = styleAccentBold
 		}
 		line := prefix +
@@ -7,11 +7,23 @@ import (
 )
 
 func (m *App) updateLogView() {
-	var colored []string
+	m.log.colored = m.log.colored[:0]
 	for _, line := range m.ctx.LogLines {
-		colored = append(colored, colorizeLogLine(line))
+		m.log.colored = append(m.log.colored, colorizeLogLine(line))
 	}
-	m.log.vp.SetContent(strings.Join(colored, "\n"))
+	m.log.vp.SetContent(strings.Join(m.log.colored, "\n"))
+	m.log.vp.GotoBottom()
+}
+
+// appendLogView adds one line to the log panel, colorizing only that line.
+// The viewport content is only rebuilt while the panel is visible; showing
+// it again goes through updateLogView.
+func (m *App) appendLogView(line string) {
+	if !m.ctx.ShowLogs {
+		return
+	}
+	m.log.colored = appendLogLine(m.log.colored, colorizeLogLine(line))
+	m.log.vp.SetContent(strings.Join(m.log.colored, "\n"))
 	m.log.vp.GotoBottom()
 }
 
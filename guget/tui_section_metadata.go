@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	bubbles_textinpute "charm.land/bubbles/v2/textinput"
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// openMetadataFix prompts for the package metadata the selected packable
+// project is missing. RepositoryUrl is filled in from the git remote.
+func (m *App) openMetadataFix() bubble_tea.Cmd {
+	p := m.selectedProject()
+	if p == nil {
+		return m.setStatus("Select a project to check its package metadata", false)
+	}
+	if cmd := m.readOnlyProjectStatus(p); cmd != nil {
+		return cmd
+	}
+	if _, _, ok := p.packageIdentity(); !ok {
+		return m.setStatus(p.FileName+" is not packable", false)
+	}
+	lints := p.missingPackageMetadata()
+	if len(lints) == 0 {
+		return m.setStatus("✓ "+p.FileName+" has complete package metadata", false)
+	}
+	inputs := make([]bubbles_textinpute.Model, len(lints))
+	for i, l := range lints {
+		inputs[i] = bubbles_textinpute.New()
+		inputs[i].Placeholder = l.Example
+		inputs[i].CharLimit = 500
+		if l.Property == "RepositoryUrl" {
+			if out, err := runGit(filepath.Dir(p.FilePath), nil, nil, "remote", "get-url", "origin"); err == nil {
+				inputs[i].SetValue(repositoryURLFromRemote(string(out)))
+			}
+		}
+	}
+	m.metadataFix = metadataFixOverlay{
+		sectionBase: sectionBase{app: m, baseWidth: 72, minWidth: 48, maxMargin: 4, active: true},
+		project:     p,
+		lints:       lints,
+		inputs:      inputs,
+	}
+	m.ctx.StatusLine = ""
+	return m.metadataFix.inputs[0].Focus()
+}
+
+func (s *metadataFixOverlay) FooterKeys() []kv {
+	return []kv{{"tab", "next"}, {"enter", "add filled in"}, {"esc", "cancel"}}
+}
+
+func (s *metadataFixOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		s.closeOverlay()
+		return nil
+	case "tab", "down":
+		return s.focus(s.cursor + 1)
+	case "shift+tab", "up":
+		return s.focus(s.cursor - 1)
+	case "enter":
+		var props []projectProperty
+		for i, l := range s.lints {
+			if v := strings.TrimSpace(s.inputs[i].Value()); v != "" {
+				props = append(props, projectProperty{Name: l.Property, Value: v})
+			}
+		}
+		if len(props) == 0 {
+			return s.app.setStatus("Fill in at least one property, or esc to cancel", false)
+		}
+		s.closeOverlay()
+		return s.app.addProjectProperties(s.project, props)
+	}
+	var cmd bubble_tea.Cmd
+	s.inputs[s.cursor], cmd = s.inputs[s.cursor].Update(msg)
+	return cmd
+}
+
+// focus moves the cursor to input i, wrapping around.
+func (s *metadataFixOverlay) focus(i int) bubble_tea.Cmd {
+	s.inputs[s.cursor].Blur()
+	s.cursor = (i + len(s.inputs)) % len(s.inputs)
+	return s.inputs[s.cursor].Focus()
+}
+
+// addProjectProperties writes props to project's file; the file watcher
+// reloads the project afterwards.
+func (m *App) addProjectProperties(project *ParsedProject, props []projectProperty) bubble_tea.Cmd {
+	var names []string
+	for _, p := range props {
+		names = append(names, p.Name)
+	}
+	logInfo("addProjectProperties: %s to %s", strings.Join(names, ", "), project.FileName)
+	file := project.FilePath
+	return func() bubble_tea.Msg {
+		err := SetProjectProperties(file, props)
+		recordAction(ActionRecord{Action: "metadata", Package: filepath.Base(file), Files: []string{file}}, err)
+		if err != nil {
+			logWarn("adding metadata to %s failed: %v", file, err)
+		}
+		return writeResultMsg{err: err}
+	}
+}
+
+func (s *metadataFixOverlay) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	lines := []string{
+		styleAccentBold.Render("Package metadata for ") + styleTextBold.Render(s.project.FileName),
+		styleBorder.Render(strings.Repeat("─", inner)),
+	}
+	for i, l := range s.lints {
+		s.inputs[i].SetWidth(inner - 2)
+		label := styleMuted.Render(l.Property)
+		if i == s.cursor {
+			label = styleAccentBold.Render(l.Property)
+		}
+		lines = append(lines,
+			label,
+			styleSubtle.Render(wordWrap(l.Reason, inner)),
+			s.inputs[i].View(),
+			"",
+		)
+	}
+	lines = append(lines, styleMuted.Render(wordWrap(fmt.Sprintf("Filled-in properties are added to the first PropertyGroup of %s; empty ones are skipped. Shared values belong in Directory.Build.props instead.", s.project.FileName), inner)))
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	bubbles_textinpute "charm.land/bubbles/v2/textinput"
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// loadNotes rereads the notes file, e.g. after a pull brought in a
+// teammate's notes.
+func (m *App) loadNotes() {
+	notes, err := loadPackageNotes(notesPath(m.projectDir))
+	if err != nil {
+		logWarn("Ignoring package notes: %v", err)
+	}
+	m.ctx.Notes = notes
+}
+
+// openNoteEditor edits the note and tags on the selected package.
+func (m *App) openNoteEditor(pkgName string) bubble_tea.Cmd {
+	if cmd := m.readOnlySessionStatus(); cmd != nil {
+		return cmd
+	}
+	current := m.ctx.Notes.For(pkgName)
+	note := bubbles_textinpute.New()
+	note.Placeholder = "e.g. pinned until issue #123"
+	note.CharLimit = 500
+	note.SetValue(current.Note)
+	tags := bubbles_textinpute.New()
+	tags.Placeholder = "comma-separated, e.g. blocked, security"
+	tags.CharLimit = 200
+	tags.SetValue(strings.Join(current.Tags, ", "))
+	m.noteEditor = noteEditor{
+		sectionBase: sectionBase{app: m, baseWidth: 64, minWidth: 44, maxMargin: 4, active: true},
+		pkgName:     pkgName,
+		note:        note,
+		tags:        tags,
+	}
+	m.ctx.StatusLine = ""
+	return m.noteEditor.note.Focus()
+}
+
+func (s *noteEditor) FooterKeys() []kv {
+	return []kv{{"tab", "note/tags"}, {"enter", "save"}, {"esc", "cancel"}}
+}
+
+func (s *noteEditor) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		s.closeOverlay()
+		return nil
+	case "tab", "shift+tab", "up", "down":
+		s.onTags = !s.onTags
+		if s.onTags {
+			s.note.Blur()
+			return s.tags.Focus()
+		}
+		s.tags.Blur()
+		return s.note.Focus()
+	case "enter":
+		s.closeOverlay()
+		return s.save()
+	}
+	var cmd bubble_tea.Cmd
+	if s.onTags {
+		s.tags, cmd = s.tags.Update(msg)
+	} else {
+		s.note, cmd = s.note.Update(msg)
+	}
+	return cmd
+}
+
+// save writes the edited note to the notes file. Clearing both fields
+// removes the package's entry.
+func (s *noteEditor) save() bubble_tea.Cmd {
+	m := s.app
+	note := packageNote{Note: strings.TrimSpace(s.note.Value()), Tags: parseTags(s.tags.Value())}
+	path := notesPath(m.projectDir)
+	// Merge into the file as it is now, so notes a teammate added since the
+	// session started are kept.
+	notes, err := loadPackageNotes(path)
+	if err != nil {
+		return m.setStatus("✗ "+err.Error(), true)
+	}
+	notes.Set(s.pkgName, note)
+	if err := savePackageNotes(path, notes); err != nil {
+		logError("Writing notes: %v", err)
+		return m.setStatus("✗ Writing notes: "+err.Error(), true)
+	}
+	m.ctx.Notes = notes
+	m.refreshDetail()
+	rel, relErr := filepath.Rel(m.projectDir, path)
+	if relErr != nil {
+		rel = path
+	}
+	if note.empty() {
+		return m.setStatus("✓ Removed the note on "+s.pkgName+" from "+filepath.ToSlash(rel), false)
+	}
+	return m.setStatus("✓ Saved the note on "+s.pkgName+" to "+filepath.ToSlash(rel), false)
+}
+
+func (s *noteEditor) Render() string {
+	w := s.Width()
+	inner := w - 6
+	s.note.SetWidth(inner - 2)
+	s.tags.SetWidth(inner - 2)
+
+	label := func(text string, on bool) string {
+		if on {
+			return styleAccentBold.Render(text)
+		}
+		return styleMuted.Render(text)
+	}
+	lines := []string{
+		styleAccentBold.Render("Note on ") + styleTextBold.Render(s.pkgName),
+		styleBorder.Render(strings.Repeat("─", inner)),
+		label("Note", !s.onTags),
+		s.note.View(),
+		"",
+		label("Tags", s.onTags),
+		s.tags.View(),
+		"",
+		styleMuted.Render(wordWrap("Saved to .guget/notes.json — commit it to share notes with the team. Clear both fields to remove the note.", inner)),
+	}
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
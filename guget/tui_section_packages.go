@@ -1,24 +1,30 @@
 package main
 
 import (
+	"cmp"
+	"slices"
 	"strings"
 	"time"
 
+	bubble_tea "charm.land/bubbletea/v2"
 	lipgloss "charm.land/lipgloss/v2"
 )
 
 func currentVersionText(row packageRow) string {
 	if row.diverged {
-		return row.oldest.String() + "–" + row.ref.Version.String()
+		return row.oldest.Declared() + "–" + row.ref.Version.Declared()
 	}
 	if row.ref.Locked {
 		return "[" + row.ref.Version.String() + "]"
 	}
-	return row.ref.Version.String()
+	return row.ref.Version.Declared()
 }
 
 // availableVersionText returns the plain text for the merged available column.
 func availableVersionText(row packageRow) string {
+	if row.renamedTo != "" {
+		return "renamed → " + row.renamedTo
+	}
 	if row.latestCompatible == nil {
 		return "-"
 	}
@@ -29,17 +35,41 @@ func availableVersionText(row packageRow) string {
 	return compat
 }
 
+// maxLicenseColW caps the License column so long expressions don't crowd
+// out the package name.
+const maxLicenseColW = 24
+
+// renderLicenseCell returns the styled license for the License column,
+// highlighted when the available update declares a different license.
+func renderLicenseCell(row packageRow, w int) string {
+	label := truncate(licenseLabel(row.license()), w)
+	if label == "" {
+		return styleMuted.Render("—")
+	}
+	if _, _, changed := row.licenseChange(); changed {
+		return styleYellow.Render(label)
+	}
+	return styleMuted.Render(label)
+}
+
 // renderAvailableVersion returns the styled string for the merged available column.
 func renderAvailableVersion(row packageRow) string {
+	if row.rule.Pin {
+		return styleMuted.Render(availableVersionText(row))
+	}
+	if row.renamedTo != "" {
+		return styleYellow.Render("renamed → ") + styleText.Render(row.renamedTo)
+	}
 	if row.latestCompatible == nil {
 		return styleSubtle.Render("-")
 	}
+	installed := row.installedVersion()
 	compat := row.latestCompatible.SemVer.String()
 	var compStyle lipgloss.Style
 	switch {
-	case row.latestCompatible.SemVer.IsNewerThan(row.ref.Version):
+	case row.latestCompatible.SemVer.IsNewerThan(installed):
 		compStyle = styleYellow
-	case row.ref.Version.IsNewerThan(row.latestCompatible.SemVer):
+	case installed.IsNewerThan(row.latestCompatible.SemVer):
 		compStyle = styleMuted
 	default:
 		compStyle = styleGreen
@@ -48,9 +78,9 @@ func renderAvailableVersion(row packageRow) string {
 		latest := row.latestStable.SemVer.String()
 		var latestStyle lipgloss.Style
 		switch {
-		case row.latestStable.SemVer.IsNewerThan(row.ref.Version):
+		case row.latestStable.SemVer.IsNewerThan(installed):
 			latestStyle = stylePurple
-		case row.ref.Version.IsNewerThan(row.latestStable.SemVer):
+		case installed.IsNewerThan(row.latestStable.SemVer):
 			latestStyle = styleMuted
 		default:
 			latestStyle = styleGreen
@@ -78,7 +108,11 @@ func (m *App) renderPackagePanel(w int) string {
 	colCurrent := len("Current")
 	colAvail := len("Available")
 	colSource := len("Source")
+	colLicense := len("License")
 	for _, row := range m.packages.rows {
+		if n := len(licenseLabel(row.license())); n > colLicense {
+			colLicense = min(n, maxLicenseColW)
+		}
 		if n := len(currentVersionText(row)); n > colCurrent {
 			colCurrent = n
 		}
@@ -92,9 +126,14 @@ func (m *App) renderPackagePanel(w int) string {
 	colCurrent += colPad
 	colAvail += colPad
 	colSource += colPad
+	colLicense += colPad
 
-	// Reserve columns: source hides first, then available.
+	// Reserve columns: license hides first, then source, then available.
 	budget := innerW - colPrefix - colCurrent
+	showLicense := m.packages.showLicense && budget >= minNameW+colAvail+colSource+colLicense
+	if showLicense {
+		budget -= colLicense
+	}
 	showSource := budget >= minNameW+colAvail+colSource
 	if showSource {
 		budget -= colSource
@@ -115,21 +154,42 @@ func (m *App) renderPackagePanel(w int) string {
 		sortArrow = "▲"
 	}
 	pkgHeader := "Package (by " + m.packages.sortMode.label() + " " + sortArrow + ")"
+	if m.ctx.Prerelease {
+		pkgHeader = "Package (by " + m.packages.sortMode.label() + " " + sortArrow + ", incl. pre-releases)"
+	}
 	header := "  " + padRight(hStyle.Render(pkgHeader), nameW) +
 		padRight(hStyle.Render("Current"), colCurrent)
 	if showAvail {
 		header += padRight(hStyle.Render("Available"), colAvail)
 	}
+	if showLicense {
+		header += padRight(hStyle.Render("License"), colLicense)
+	}
 	if showSource {
 		header += hStyle.Render("Source")
 	}
+	if m.packageFilterLines() > 0 {
+		lines = append(lines, m.renderPackageFilter(innerW))
+	}
 	lines = append(lines, header)
 	lines = append(lines,
 		styleBorder.Render(strings.Repeat("─", innerW)),
 	)
 
 	// rows
-	if len(m.packages.rows) == 0 {
+	if sel := m.selectedProject(); sel != nil && sel.LoadErr != nil {
+		lines = append(lines, "")
+		lines = append(lines, styleRed.Render("  Project file could not be parsed"))
+		lines = append(lines, styleMuted.Render("  See the detail panel for the error"))
+	} else if len(m.packages.rows) == 0 && m.packageFiltered() {
+		lines = append(lines, "")
+		lines = append(lines, styleMuted.Render("  No packages match the filter"))
+		lines = append(lines, styleMuted.Render("  Press esc to clear it"))
+	} else if len(m.packages.rows) == 0 && m.stars.only {
+		lines = append(lines, "")
+		lines = append(lines, styleMuted.Render("  No starred packages here"))
+		lines = append(lines, styleMuted.Render("  Press * to star, ctrl+s to show all"))
+	} else if len(m.packages.rows) == 0 {
 		lines = append(lines, "")
 		lines = append(lines, styleMuted.Render("  No packages found"))
 		lines = append(lines, styleMuted.Render("  Press / to search NuGet"))
@@ -147,32 +207,64 @@ func (m *App) renderPackagePanel(w int) string {
 		// icon
 		icon := row.statusStyle().Render(row.statusIcon())
 
-		// name
-		rawName := truncate(row.ref.Name, nameW-1)
+		// name, tagged with its category for global references and pins,
+		// marked local when a workspace project builds it, and starred when
+		// it is a favorite
+		tag := row.ref.Kind.label()
+		tagW := 0
+		if tag != "" {
+			tagW = len(tag) + 1
+		}
+		local := localPackageProject(m.ctx.ParsedProjects, row.ref.Name) != nil
+		if local {
+			tagW += len(" local")
+		}
+		starred := m.packageStarred(row.ref.Name)
+		if starred {
+			tagW += 2
+		}
+		badge := restoreWarningBadge(row.restoreWarnings)
+		if badge != "" {
+			tagW += len(badge) + 1
+		}
+		rawName := truncate(row.ref.Name, nameW-1-tagW)
 		nameStyle := styleText
 		if selected {
 			nameStyle = styleAccentBold
 		}
-		name := padRight(nameStyle.Render(rawName), nameW)
+		nameText := nameStyle.Render(rawName)
+		if starred {
+			nameText += " " + styleYellow.Render("★")
+		}
+		if tag != "" {
+			nameText += " " + styleCyan.Render(tag)
+		}
+		if local {
+			nameText += " " + styleGreen.Render("local")
+		}
+		if badge != "" {
+			nameText += " " + styleYellow.Render(badge)
+		}
+		name := padRight(nameText, nameW)
 
 		var current string
 		if row.diverged {
-			low := styleSubtle.Render(row.oldest.String())
+			low := styleSubtle.Render(row.oldest.Declared())
 			sep := styleMuted.Render("–")
-			high := styleYellow.Render(row.ref.Version.String())
+			high := styleYellow.Render(row.ref.Version.Declared())
 			current = padRight(low+sep+high, colCurrent)
 		} else if row.ref.Locked {
 			verText := styleYellow.Render("[") + styleSubtle.Render(row.ref.Version.String()) + styleYellow.Render("]")
 			current = padRight(verText, colCurrent)
 		} else {
 			current = padRight(
-				styleSubtle.Render(row.ref.Version.String()), colCurrent)
+				styleSubtle.Render(row.ref.Version.Declared()), colCurrent)
 		}
 
 		line := ""
 		prefix := "  "
 		if selected && focused {
-			prefix = styleAccent.Render("▶ ")
+			prefix = styleAccent.Render(glyphs.Cursor)
 		}
 		line += prefix + icon + " " + name + current
 
@@ -181,6 +273,10 @@ func (m *App) renderPackagePanel(w int) string {
 			line += padRight(renderAvailableVersion(row), colAvail)
 		}
 
+		if showLicense {
+			line += padRight(renderLicenseCell(row, colLicense-colPad), colLicense)
+		}
+
 		if showSource {
 			line += styleMuted.Render(row.source)
 		}
@@ -223,12 +319,29 @@ func versionCompatible(v PackageVersion, targets Set[TargetFramework]) bool {
 	return true
 }
 
+// minimumFixedAcross returns the smallest version that clears every advisory
+// for projects installed anywhere between oldest and newest, without moving
+// any of them backwards.
+func minimumFixedAcross(info *PackageInfo, oldest, newest SemVer, targets Set[TargetFramework]) *PackageVersion {
+	fix := info.MinimumFixedVersion(oldest, targets)
+	if fix == nil || !newest.IsNewerThan(fix.SemVer) {
+		return fix
+	}
+	for i := range info.Versions {
+		if info.Versions[i].SemVer.String() == newest.String() && len(info.Versions[i].Vulnerabilities) == 0 {
+			return &info.Versions[i]
+		}
+	}
+	return info.MinimumFixedVersion(newest, targets)
+}
+
 // defaultVersionCursor returns the index of the first stable, compatible
 // version in a newest-first sorted slice — the natural default selection.
-// Falls back to 0 if nothing matches.
-func defaultVersionCursor(versions []PackageVersion, targets Set[TargetFramework]) int {
+// With prerelease, pre-releases count too. Falls back to 0 if nothing
+// matches.
+func defaultVersionCursor(versions []PackageVersion, targets Set[TargetFramework], prerelease bool) int {
 	for i, v := range versions {
-		if !v.SemVer.IsPreRelease() && versionCompatible(v, targets) {
+		if (prerelease || !v.SemVer.IsPreRelease()) && versionCompatible(v, targets) {
 			return i
 		}
 	}
@@ -277,57 +390,154 @@ func (m *App) rebuildPackageRows() {
 			}
 
 			row := packageRow{
-				ref:      PackageReference{Name: name, Version: newest},
+				ref:      PackageReference{Name: name, Version: newest, Kind: g.refs[0].Kind},
 				project:  g.project,
-				info:     res.pkg,
-				source:   res.source,
-				err:      res.err,
-				loading:  m.ctx.PendingPackages.Contains(name),
 				diverged: oldest != newest,
 				oldest:   oldest,
 			}
-			if res.pkg != nil {
-				row.latestCompatible = res.pkg.LatestStableForFramework(g.project.TargetFrameworks)
-				row.latestStable = res.pkg.LatestStable()
-				row.deprecated = res.pkg.Deprecated
-				for _, v := range res.pkg.Versions {
-					vs := v.SemVer.String()
-					if vs == newest.String() || vs == oldest.String() {
-						if len(v.Vulnerabilities) > 0 {
-							row.vulnerable = true
-							break
-						}
-					}
-				}
-			}
+			row.rule = m.ctx.Rules.For(name)
+			row.prerelease = m.ctx.Prerelease
+			row.applyResult(res, m.ctx.PendingPackages.Contains(name))
+			row.renamedTo = m.ctx.Renames.To(name)
+			row.restoreWarnings = m.restoreWarningCodes(nil, name)
 			rows = append(rows, row)
 		}
 	} else {
 		for ref := range sel.Packages {
-			res := m.ctx.Results[ref.Name]
-			row := packageRow{
-				ref:     ref,
-				project: sel,
-				info:    res.pkg,
-				source:  res.source,
-				err:     res.err,
-				loading: m.ctx.PendingPackages.Contains(ref.Name),
-			}
-			if res.pkg != nil {
-				row.latestCompatible = res.pkg.LatestStableForFramework(sel.TargetFrameworks)
-				row.latestStable = res.pkg.LatestStable()
-				row.deprecated = res.pkg.Deprecated
-				for _, v := range res.pkg.Versions {
-					if v.SemVer.String() == ref.Version.String() {
-						row.vulnerable = len(v.Vulnerabilities) > 0
-						break
-					}
-				}
-			}
+			row := packageRow{ref: ref, project: sel, rule: m.ctx.Rules.For(ref.Name), prerelease: m.ctx.Prerelease}
+			row.applyResult(m.ctx.Results[ref.Name], m.ctx.PendingPackages.Contains(ref.Name))
+			row.renamedTo = m.ctx.Renames.To(ref.Name)
+			row.restoreWarnings = m.restoreWarningCodes(sel, ref.Name)
 			rows = append(rows, row)
 		}
 	}
 
+	if m.stars.only {
+		rows = slices.DeleteFunc(rows, func(row packageRow) bool { return !m.packageStarred(row.ref.Name) })
+	}
+	m.packages.unfiltered = len(rows)
+	if view := m.packages.view; view != viewAll {
+		rows = slices.DeleteFunc(rows, func(row packageRow) bool { return !view.match(row, m.packages.viewSource) })
+	}
+	if filter := m.packages.filter.Value(); filter != "" {
+		rows = slices.DeleteFunc(rows, func(row packageRow) bool { return !packageFilterMatch(row.ref.Name, filter) })
+	}
+	m.sortPackageRows(rows)
+	m.packages.rows = rows
+	if m.packages.cursor >= len(rows) {
+		m.packages.cursor = imax(0, len(rows)-1)
+	}
+	m.clampOffset()
+}
+
+// togglePrerelease switches whether latest versions, status icons, and
+// searches include pre-releases.
+func (m *App) togglePrerelease() bubble_tea.Cmd {
+	m.ctx.Prerelease = !m.ctx.Prerelease
+	setSearchPrerelease(m.ctx.Prerelease)
+	selected := ""
+	if m.packages.cursor < len(m.packages.rows) {
+		selected = m.packages.rows[m.packages.cursor].ref.Name
+	}
+	m.rebuildPackageRows()
+	m.selectPackageByName(selected)
+	m.refreshDetail()
+	if m.ctx.Prerelease {
+		return m.setStatus("Including pre-releases in latest versions and search (P to turn off)", false)
+	}
+	return m.setStatus("Stable versions only", false)
+}
+
+// versionUses lists what each project has installed of pkgName and targets,
+// for trimVersions.
+func (m *App) versionUses(pkgName string) []versionUse {
+	var uses []versionUse
+	for _, p := range m.ctx.ParsedProjects {
+		for ref := range p.Packages {
+			if ref.Name == pkgName {
+				uses = append(uses, versionUse{installed: ref.Version, targets: p.TargetFrameworks})
+			}
+		}
+	}
+	return uses
+}
+
+// applyResult fills the row's registry-derived fields from res. In the All
+// Projects view a diverged row counts as vulnerable when either its oldest
+// or newest installed version is. The latest versions respect the row's
+// rule, so a package limited to one major version is judged within it, and
+// include pre-releases when the row's prerelease flag is set.
+func (r *packageRow) applyResult(res nugetResult, loading bool) {
+	r.info, r.source, r.err, r.loading = res.pkg, res.source, res.err, loading
+	r.latestCompatible, r.latestStable, r.minFixed = nil, nil, nil
+	r.deprecated, r.vulnerable = false, false
+	if res.pkg == nil {
+		return
+	}
+	targets := r.project.TargetFrameworks
+	r.latestCompatible = res.pkg.LatestForFramework(targets, r.prerelease)
+	r.latestStable = res.pkg.Latest(r.prerelease)
+	if r.rule.Major != nil {
+		r.latestCompatible = r.rule.latestAllowed(res.pkg, targets, r.prerelease)
+		r.latestStable = r.rule.latestAllowed(res.pkg, nil, r.prerelease)
+	}
+	r.deprecated = res.pkg.Deprecated
+	oldest, newest := r.effectiveVersion().String(), r.installedVersion().String()
+	for _, v := range res.pkg.Versions {
+		vs := v.SemVer.String()
+		if (vs == oldest || vs == newest) && len(v.Vulnerabilities) > 0 {
+			r.vulnerable = true
+			break
+		}
+	}
+	if r.vulnerable {
+		r.minFixed = minimumFixedAcross(res.pkg, r.effectiveVersion(), r.installedVersion(), targets)
+	}
+}
+
+// updatePackageRows refreshes, in place, the rows showing pkgName after its
+// metadata arrives, instead of rebuilding every row. Sorting is deferred
+// until resort is true (typically when the last pending package lands) so a
+// large solution isn't re-sorted once per package. A triage view rebuilds
+// instead: the package may only now enter the view, or drop out of it.
+func (m *App) updatePackageRows(pkgName string, resort bool) {
+	if m.packages.view != viewAll {
+		var current string
+		if m.packages.cursor < len(m.packages.rows) {
+			current = m.packages.rows[m.packages.cursor].ref.Name
+		}
+		m.rebuildPackageRows()
+		m.selectPackageByName(current)
+		return
+	}
+	res := m.ctx.Results[pkgName]
+	loading := m.ctx.PendingPackages.Contains(pkgName)
+	for i := range m.packages.rows {
+		if m.packages.rows[i].ref.Name == pkgName {
+			m.packages.rows[i].applyResult(res, loading)
+		}
+	}
+	if !resort {
+		return
+	}
+	// Keep the cursor on the same package while rows move around it.
+	var current string
+	if m.packages.cursor < len(m.packages.rows) {
+		current = m.packages.rows[m.packages.cursor].ref.Name
+	}
+	m.sortPackageRows(m.packages.rows)
+	for i, row := range m.packages.rows {
+		if row.ref.Name == current {
+			m.packages.cursor = i
+			break
+		}
+	}
+	m.clampOffset()
+}
+
+// sortPackageRows orders rows by the current sort mode and direction, then
+// groups them by reference kind.
+func (m *App) sortPackageRows(rows []packageRow) {
 	switch m.packages.sortMode {
 	case sortByName:
 		sortPackageRowsByName(rows)
@@ -350,20 +560,17 @@ func (m *App) rebuildPackageRows() {
 			rows[i], rows[j] = rows[j], rows[i]
 		}
 	}
-
-	m.packages.rows = rows
-	if m.packages.cursor >= len(rows) {
-		m.packages.cursor = imax(0, len(rows)-1)
-	}
-	m.clampOffset()
+	sortPackageRowsByKind(rows)
 }
 
 func sortPackageRowsByName(rows []packageRow) {
-	for i := 1; i < len(rows); i++ {
-		for j := i; j > 0 && rows[j].ref.Name < rows[j-1].ref.Name; j-- {
-			rows[j], rows[j-1] = rows[j-1], rows[j]
-		}
-	}
+	slices.SortStableFunc(rows, func(a, b packageRow) int { return cmp.Compare(a.ref.Name, b.ref.Name) })
+}
+
+// sortPackageRowsByKind groups global references and transitive pins after
+// the project's own references, keeping the order within each group.
+func sortPackageRowsByKind(rows []packageRow) {
+	slices.SortStableFunc(rows, func(a, b packageRow) int { return cmp.Compare(a.ref.Kind, b.ref.Kind) })
 }
 
 func sortPackageRowsByStatus(rows []packageRow) {
@@ -374,7 +581,10 @@ func sortPackageRowsByStatus(rows []packageRow) {
 		if r.vulnerable {
 			return 1
 		}
-		if r.deprecated {
+		if r.rule.Pin {
+			return 4
+		}
+		if r.deprecated || r.renamedTo != "" {
 			return 2
 		}
 		ver := r.effectiveVersion()
@@ -387,19 +597,11 @@ func sortPackageRowsByStatus(rows []packageRow) {
 		}
 		return 4
 	}
-	for i := 1; i < len(rows); i++ {
-		for j := i; j > 0 && priority(rows[j]) < priority(rows[j-1]); j-- {
-			rows[j], rows[j-1] = rows[j-1], rows[j]
-		}
-	}
+	slices.SortStableFunc(rows, func(a, b packageRow) int { return cmp.Compare(priority(a), priority(b)) })
 }
 
 func sortPackageRowsBySource(rows []packageRow) {
-	for i := 1; i < len(rows); i++ {
-		for j := i; j > 0 && rows[j].source < rows[j-1].source; j-- {
-			rows[j], rows[j-1] = rows[j-1], rows[j]
-		}
-	}
+	slices.SortStableFunc(rows, func(a, b packageRow) int { return cmp.Compare(a.source, b.source) })
 }
 
 // sortPackageRowsByCurrent sorts by the published date of the currently
@@ -417,11 +619,7 @@ func sortPackageRowsByCurrent(rows []packageRow) {
 		}
 		return time.Time{}
 	}
-	for i := 1; i < len(rows); i++ {
-		for j := i; j > 0 && published(rows[j]).Before(published(rows[j-1])); j-- {
-			rows[j], rows[j-1] = rows[j-1], rows[j]
-		}
-	}
+	slices.SortStableFunc(rows, func(a, b packageRow) int { return published(a).Compare(published(b)) })
 }
 
 // sortPackageRowsByAvailable sorts by the published date of the best available
@@ -439,14 +637,21 @@ func sortPackageRowsByAvailable(rows []packageRow) {
 		}
 		return time.Time{}
 	}
-	for i := 1; i < len(rows); i++ {
-		for j := i; j > 0 && published(rows[j]).Before(published(rows[j-1])); j-- {
-			rows[j], rows[j-1] = rows[j-1], rows[j]
-		}
-	}
+	slices.SortStableFunc(rows, func(a, b packageRow) int { return published(a).Compare(published(b)) })
 }
 
 func (m *App) refreshDetail() {
+	m.detail.project = m.focus == focusProjects
+	if sel := m.selectedProject(); sel != nil && sel.LoadErr != nil {
+		m.detail.vp.SetContent(m.renderBrokenProjectDetail(sel))
+		m.detail.vp.GotoTop()
+		return
+	}
+	if m.detail.project {
+		m.detail.vp.SetContent(m.renderProjectDetail(m.selectedProject()))
+		m.detail.vp.GotoTop()
+		return
+	}
 	if m.packages.cursor >= len(m.packages.rows) {
 		m.detail.vp.SetContent("")
 		return
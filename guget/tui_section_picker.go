@@ -1,17 +1,27 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"slices"
 	"strings"
 
+	bubbles_textinpute "charm.land/bubbles/v2/textinput"
 	bubble_tea "charm.land/bubbletea/v2"
 	lipgloss "charm.land/lipgloss/v2"
 )
 
 func (s *versionPicker) FooterKeys() []kv {
-	return []kv{{"↑↓", "nav"}, {"u/U", "update/all"}, {"esc", "close"}}
+	if s.filtering {
+		return []kv{{"↑↓", "nav"}, {"enter", "done"}, {"esc", "clear"}}
+	}
+	return []kv{{"↑↓", "nav"}, {"←→", "group"}, {"/", "filter"}, {"p/c", "hide pre/incompat"}, {"u/U", "update/all"}, {"i", "impact"}, {"x", "unlist"}, {"esc", "close"}}
 }
 
 func (s *versionPicker) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	if s.filtering {
+		return s.handleFilterKey(msg)
+	}
 	switch msg.String() {
 	case "[":
 		s.Resize(-4)
@@ -28,14 +38,38 @@ func (s *versionPicker) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 			s.cursor--
 		}
 	case "down", "j":
-		if s.cursor < len(s.versions)-1 {
+		if s.cursor < len(s.rows)-1 {
 			s.cursor++
 		}
+	case "left", "h":
+		s.collapseGroup()
+	case "right", "l":
+		if s.cursor < len(s.rows) && s.rows[s.cursor].Header {
+			s.toggleGroup()
+		}
+	case "/":
+		s.filtering = true
+		s.filter.CursorEnd()
+		return s.filter.Focus()
+	case "p":
+		s.hidePre = !s.hidePre
+		s.refilter()
+	case "c":
+		s.hideIncompat = !s.hideIncompat
+		s.refilter()
+	case "i":
+		return s.estimateImpact()
+	case "x":
+		return s.deleteVersion()
 	case "u":
 		return s.applyPickerVersion(scopeSelected)
 	case "U":
 		return s.applyPickerVersion(scopeAll)
-	case "enter":
+	case "enter", "space":
+		if s.cursor < len(s.rows) && s.rows[s.cursor].Header {
+			s.toggleGroup()
+			return nil
+		}
 		if v := s.selectedVersion(); v != nil {
 			s.closeOverlay()
 			if s.addMode {
@@ -47,6 +81,43 @@ func (s *versionPicker) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 	return nil
 }
 
+// handleFilterKey edits the filter while it has the keyboard. ↑/↓ still
+// move through the narrowed list; enter hands the keys back with the filter
+// kept; esc clears it.
+func (s *versionPicker) handleFilterKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return bubble_tea.Quit
+	case "esc":
+		s.filtering = false
+		s.filter.Blur()
+		s.filter.Reset()
+		s.refilter()
+		return nil
+	case "enter":
+		s.filtering = false
+		s.filter.Blur()
+		return nil
+	case "up":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+		return nil
+	case "down":
+		if s.cursor < len(s.rows)-1 {
+			s.cursor++
+		}
+		return nil
+	}
+	prev := s.filter.Value()
+	var cmd bubble_tea.Cmd
+	s.filter, cmd = s.filter.Update(msg)
+	if s.filter.Value() != prev {
+		s.refilter()
+	}
+	return cmd
+}
+
 // routeAddVersion handles the add-mode flow after a version is selected:
 // single project goes to location picker, "All Projects" goes to project picker.
 func (s *versionPicker) routeAddVersion(version string) bubble_tea.Cmd {
@@ -73,28 +144,331 @@ func (s *versionPicker) applyPickerVersion(scope actionScope) bubble_tea.Cmd {
 	return s.app.applyOrConfirmUpdate(s.pkgName, v.SemVer.String(), project)
 }
 
+// deleteVersion asks the package's feed to unlist or delete the selected
+// version, behind a typed confirmation. Meant for pulling bad internal
+// releases; the feed decides whether the version is unlisted or removed.
+func (s *versionPicker) deleteVersion() bubble_tea.Cmd {
+	m := s.app
+	if cmd := m.readOnlySessionStatus(); cmd != nil {
+		return cmd
+	}
+	v := s.selectedVersion()
+	if v == nil || s.addMode {
+		return nil
+	}
+	source := m.ctx.Results[s.pkgName].source
+	var svc *NugetService
+	for _, candidate := range m.ctx.NugetServices {
+		if strings.EqualFold(candidate.SourceName(), source) {
+			svc = candidate
+			break
+		}
+	}
+	if svc == nil || !svc.CanPublish() {
+		return m.setStatus(fmt.Sprintf("✗ %s does not accept deletes (no PackagePublish endpoint)", source), true)
+	}
+
+	pkgName, version, projectDir := s.pkgName, v.SemVer.String(), m.projectDir
+	s.closeOverlay()
+	title := fmt.Sprintf("Unlist or delete %s %s on %s? nuget.org and Azure Artifacts unlist it; other feeds may delete it permanently.", pkgName, version, svc.SourceName())
+	m.confirmTyped = newConfirmTyped(m, title, "delete "+version, func() bubble_tea.Cmd {
+		logInfo("Deleting %s %s from %s", pkgName, version, svc.SourceName())
+		return bubble_tea.Batch(
+			m.setStatus(fmt.Sprintf("Deleting %s %s from %s…", pkgName, version, svc.SourceName()), false),
+			func() bubble_tea.Msg {
+				apiKey := resolveAPIKey(projectDir, svc.SourceURL(), "")
+				err := svc.DeleteVersion(context.Background(), pkgName, version, apiKey)
+				recordAction(ActionRecord{Action: "delete", Package: pkgName, Version: version, Source: svc.SourceName()}, err)
+				return versionDeletedMsg{pkgName: pkgName, version: version, source: svc.SourceName(), err: err}
+			},
+		)
+	})
+	m.ctx.StatusLine = ""
+	return m.confirmTyped.input.Focus()
+}
+
 func newVersionPicker(m *App, pkgName string, versions []PackageVersion, targets Set[TargetFramework], project *ParsedProject, addMode bool) versionPicker {
-	return versionPicker{
+	s := versionPicker{
 		sectionBase:   sectionBase{app: m, baseWidth: 50, minWidth: 40, maxMargin: 4, active: true},
 		pkgName:       pkgName,
 		versions:      versions,
-		cursor:        defaultVersionCursor(versions, targets),
+		expanded:      NewSet[int](),
+		filter:        newVersionFilterInput(),
 		targets:       targets,
 		addMode:       addMode,
 		targetProject: project,
+		cached:        m.ctx.PackageFolders.CachedVersions(pkgName),
+		impact:        make(map[string]*RestoreImpact),
 	}
+	if len(versions) > 0 {
+		s.showVersion(defaultVersionCursor(versions, targets, m.ctx.Prerelease))
+	}
+	return s
 }
 
-func (m *App) openVersionPicker() {
-	if m.packages.cursor >= len(m.packages.rows) {
+func newVersionFilterInput() bubbles_textinpute.Model {
+	ti := bubbles_textinpute.New()
+	ti.Prompt = ""
+	ti.Placeholder = "version, e.g. 6.0 or rc"
+	ti.CharLimit = 40
+	return ti
+}
+
+// shows reports whether v passes the filter and the hide toggles.
+func (s *versionPicker) shows(v PackageVersion) bool {
+	switch {
+	case s.hidePre && v.SemVer.IsPreRelease():
+		return false
+	case s.hideIncompat && !versionCompatible(v, s.targets):
+		return false
+	}
+	return versionFilterMatches(v.SemVer.String(), s.filter.Value())
+}
+
+// rebuildRows regroups the versions after they, the open groups, or the
+// filters change.
+func (s *versionPicker) rebuildRows() {
+	s.rows = groupVersionsByMajor(s.versions, s.expanded, s.shows)
+}
+
+// refilter regroups after the filter or a toggle changes, opening the
+// groups a typed filter matches and keeping the cursor on its version when
+// that is still listed.
+func (s *versionPicker) refilter() {
+	keep := -1
+	if v := s.selectedVersion(); v != nil {
+		keep = s.rows[s.cursor].Index
+	}
+	if strings.TrimSpace(s.filter.Value()) != "" {
+		for _, v := range s.versions {
+			if s.shows(v) {
+				s.expanded.Add(v.SemVer.Major)
+			}
+		}
+	}
+	s.rebuildRows()
+	s.cursor = 0
+	first := -1
+	for r, row := range s.rows {
+		if row.Header {
+			continue
+		}
+		if row.Index == keep {
+			s.cursor = r
+			return
+		}
+		if first < 0 {
+			first = r
+		}
+	}
+	if first >= 0 {
+		s.cursor = first
+	}
+}
+
+// showVersion opens the group of the version at index i and moves the
+// cursor to it.
+func (s *versionPicker) showVersion(i int) {
+	s.expanded.Add(s.versions[i].SemVer.Major)
+	s.rebuildRows()
+	for r, row := range s.rows {
+		if !row.Header && row.Index == i {
+			s.cursor = r
+			return
+		}
+	}
+}
+
+// toggleGroup opens or closes the group whose header is under the cursor.
+func (s *versionPicker) toggleGroup() {
+	major := s.rows[s.cursor].Major
+	if s.expanded.Contains(major) {
+		s.expanded.Remove(major)
+	} else {
+		s.expanded.Add(major)
+	}
+	s.rebuildRows()
+}
+
+// collapseGroup closes the group the cursor is in and moves to its header.
+func (s *versionPicker) collapseGroup() {
+	if s.cursor >= len(s.rows) {
 		return
 	}
+	major := s.rows[s.cursor].Major
+	s.expanded.Remove(major)
+	s.rebuildRows()
+	for r, row := range s.rows {
+		if row.Header && row.Major == major {
+			s.cursor = r
+			return
+		}
+	}
+	s.cursor = min(s.cursor, len(s.rows)-1)
+}
+
+// estimateImpact starts a background estimate of what restore would pull
+// when moving to the selected version.
+func (s *versionPicker) estimateImpact() bubble_tea.Cmd {
+	v := s.selectedVersion()
+	if v == nil || s.impactLoading != "" {
+		return nil
+	}
+	version := v.SemVer.String()
+	if _, done := s.impact[version]; done {
+		return nil
+	}
+	s.impactLoading = version
+	ctx := s.app.ctx
+	// Copy the loaded results so the estimate does not race the UI.
+	known := make(map[string]nugetResult, len(ctx.Results))
+	for name, res := range ctx.Results {
+		known[name] = res
+	}
+	resolver := newImpactResolver(ctx.NugetServices, ctx.SourceMapping, s.targets, known)
+	folders, pkgName, current := ctx.PackageFolders, s.pkgName, s.current
+	return func() bubble_tea.Msg {
+		impact := EstimateRestoreImpact(resolver, folders, pkgName, current, version)
+		return restoreImpactMsg{pkgName: pkgName, version: version, impact: impact}
+	}
+}
+
+func (m *App) openVersionPicker() bubble_tea.Cmd {
+	if m.packages.cursor >= len(m.packages.rows) {
+		return nil
+	}
 	row := m.packages.rows[m.packages.cursor]
 	if row.info == nil {
-		return
+		return nil
 	}
 	m.ctx.StatusLine = ""
 	m.picker = newVersionPicker(m, row.ref.Name, row.info.Versions, row.project.TargetFrameworks, m.selectedProject(), false)
+	m.picker.current = row.effectiveVersion().String()
+	if i := slices.IndexFunc(row.info.Versions, func(v PackageVersion) bool { return v.SemVer.String() == m.picker.current }); i >= 0 {
+		// Open the installed version's group too, keeping the cursor.
+		m.picker.expanded.Add(row.info.Versions[i].SemVer.Major)
+		m.picker.showVersion(m.picker.rows[m.picker.cursor].Index)
+	}
+	if row.info.TrimmedVersions == 0 {
+		return nil
+	}
+	// Only a window of versions is kept in memory; fetch the rest for the
+	// lifetime of the picker.
+	m.picker.loadingAll = row.info.TrimmedVersions
+	services, source, name, enrich := m.ctx.NugetServices, row.source, row.ref.Name, !m.ctx.NoEnrich
+	return func() bubble_tea.Msg {
+		info, err := refetchAllVersions(services, source, name, enrich)
+		return allVersionsMsg{pkgName: name, info: info, err: err}
+	}
+}
+
+// setVersions swaps in a fuller version list, keeping the cursor on the
+// version it was on.
+func (s *versionPicker) setVersions(versions []PackageVersion) {
+	var current string
+	if v := s.selectedVersion(); v != nil {
+		current = v.SemVer.String()
+	}
+	s.versions = versions
+	s.cursor = 0
+	s.rebuildRows()
+	for i, v := range versions {
+		if v.SemVer.String() == current {
+			s.showVersion(i)
+			break
+		}
+	}
+}
+
+// renderFilter draws the filter line, w wide: the filter while it is
+// edited or set, the hidden kinds of version, and how many are listed.
+// Empty when nothing narrows the list.
+func (s *versionPicker) renderFilter(w int) string {
+	if !s.filtering && s.filter.Value() == "" && !s.hidePre && !s.hideIncompat {
+		return ""
+	}
+	label := styleAccentBold.Render("Filter: ")
+	if s.hidePre {
+		label += styleYellow.Render("[no pre]") + " "
+	}
+	if s.hideIncompat {
+		label += styleYellow.Render("[compatible]") + " "
+	}
+	shown := 0
+	for _, v := range s.versions {
+		if s.shows(v) {
+			shown++
+		}
+	}
+	count := styleMuted.Render(fmt.Sprintf("  %d of %d", shown, len(s.versions)))
+	if !s.filtering && s.filter.Value() == "" {
+		return label + count
+	}
+	s.filter.SetWidth(max(6, w-lipgloss.Width(label)-lipgloss.Width(count)-2))
+	return label + s.filter.View() + count
+}
+
+// renderGroupHeader renders the header of a major version's group: whether
+// it is open, its version count, and its newest version.
+func (s *versionPicker) renderGroupHeader(row versionListRow, selected bool, w int) string {
+	arrow := "▸ "
+	if s.expanded.Contains(row.Major) {
+		arrow = "▾ "
+	}
+	prefix, style := "  ", styleTextBold
+	if selected {
+		prefix, style = glyphs.Cursor, styleAccentBold
+	}
+	count := "1 version"
+	if row.Count != 1 {
+		count = fmt.Sprintf("%d versions", row.Count)
+	}
+	text := style.Render(prefix+arrow+fmt.Sprintf("%d.x", row.Major)) +
+		styleMuted.Render("  "+count+" · newest "+s.versions[row.Index].SemVer.String())
+	if current := ParseSemVer(s.current); s.current != "" && current.Major == row.Major {
+		text += styleCyan.Render(" · installed")
+	}
+	return truncateStyled(text, w)
+}
+
+// renderVersionDetails describes the highlighted version: when it was
+// published, the advisories filed against it, and whether it's deprecated,
+// so a bad version is visible before it's applied.
+func (s *versionPicker) renderVersionDetails(v PackageVersion, w int) []string {
+	lines := []string{"", styleBorder.Render(strings.Repeat("─", w))}
+	published := styleSubtle.Render("Published: ")
+	if ago := timeAgo(v.Published); ago != "" {
+		published += styleText.Render(v.Published.Format("2006-01-02")) + styleMuted.Render(" · "+ago)
+	} else {
+		published += styleMuted.Render("unknown")
+	}
+	lines = append(lines, published)
+
+	if len(v.Vulnerabilities) == 0 {
+		lines = append(lines, styleSubtle.Render("Advisories: ")+styleMuted.Render("none known"))
+	} else {
+		lines = append(lines, styleSubtle.Render("Advisories:"))
+		for _, vuln := range v.Vulnerabilities {
+			style := styleYellow
+			if int(vuln.Severity) >= 2 {
+				style = styleRed
+			}
+			id := hyperlink(vuln.AdvisoryURL, advisoryLabel(vuln.AdvisoryURL))
+			lines = append(lines, "  "+styleRed.Render("▲ ")+style.Render(id)+styleMuted.Render("  "+vuln.SeverityLabel()))
+		}
+	}
+
+	if d := v.Deprecation; d != nil {
+		notice := styleYellow.Render("~ deprecated: " + d.ReasonLabel())
+		if d.AlternatePackageID != "" {
+			notice += styleMuted.Render("  use: " + d.AlternatePackageID)
+		}
+		lines = append(lines, notice)
+		if d.Message != "" {
+			lines = append(lines, styleMuted.Render(truncate(d.Message, w)))
+		}
+	}
+	return lines
 }
 
 func (s *versionPicker) Render() string {
@@ -107,8 +481,8 @@ func (s *versionPicker) Render() string {
 		start = s.cursor - maxVisible + 1
 	}
 	end := start + maxVisible
-	if end > len(versions) {
-		end = len(versions)
+	if end > len(s.rows) {
+		end = len(s.rows)
 	}
 
 	// Look up package-level info for deprecation notice and source.
@@ -138,10 +512,21 @@ func (s *versionPicker) Render() string {
 	lines = append(lines,
 		styleBorder.Render(strings.Repeat("─", w-6)),
 	)
+	if line := s.renderFilter(w - 6); line != "" {
+		lines = append(lines, line)
+	}
+	if len(s.rows) == 0 && len(versions) > 0 {
+		lines = append(lines, styleMuted.Render("No versions match"))
+	}
 
 	for i := start; i < end; i++ {
-		v := versions[i]
+		row := s.rows[i]
 		selected := i == s.cursor
+		if row.Header {
+			lines = append(lines, s.renderGroupHeader(row, selected, w-6))
+			continue
+		}
+		v := versions[row.Index]
 		compat := versionCompatible(v, s.targets)
 		isPre := v.SemVer.IsPreRelease()
 		isVulnerable := len(v.Vulnerabilities) > 0
@@ -162,7 +547,7 @@ func (s *versionPicker) Render() string {
 		prefix := "  "
 		if selected {
 			style = styleAccentBold
-			prefix = "▶ "
+			prefix = glyphs.Cursor
 		} else {
 			switch {
 			case isVulnerable:
@@ -183,6 +568,9 @@ func (s *versionPicker) Render() string {
 		if isPre {
 			extras += styleMuted.Render(" pre")
 		}
+		if s.cached[strings.ToLower(v.SemVer.String())] {
+			extras += styleCyan.Render(" ●")
+		}
 		if selected {
 			if compat {
 				extras += styleGreen.Render(" ✓")
@@ -196,6 +584,9 @@ func (s *versionPicker) Render() string {
 			verURL := "https://www.nuget.org/packages/" + s.pkgName + "/" + v.SemVer.String()
 			verStr = hyperlink(verURL, verStr)
 		}
+		if len(s.rows) > len(versions) {
+			prefix += "  " // indent under the group headers
+		}
 		verText := style.Render(prefix) + verStr + extras
 
 		ago := timeAgo(v.Published)
@@ -212,12 +603,33 @@ func (s *versionPicker) Render() string {
 		lines = append(lines, verText)
 	}
 
+	if s.loadingAll > 0 {
+		lines = append(lines, styleMuted.Render(fmt.Sprintf("Loading %d older versions...", s.loadingAll)))
+	}
+
+	if v := s.selectedVersion(); v != nil {
+		lines = append(lines, s.renderVersionDetails(*v, w-6)...)
+		version := v.SemVer.String()
+		switch {
+		case s.impactLoading == version:
+			lines = append(lines, "", styleMuted.Render("Estimating restore impact…"))
+		case s.impact[version] != nil:
+			impact := s.impact[version]
+			lines = append(lines, "", styleSubtle.Render("Restore: ")+styleText.Render(truncate(impact.Summary(), w-15)))
+		}
+	}
+
 	lines = append(lines, "")
 	legend := styleGreen.Render("■") + " compat  " +
 		styleYellow.Render("■") + " pre  " +
 		styleRed.Render("■") + " incompat  " +
 		styleRed.Render("▲") + " vuln"
-	lines = append(lines, styleMuted.Render(legend))
+	cachedLegend := styleCyan.Render("●") + " cached"
+	if lipgloss.Width(legend+"  "+cachedLegend) <= w-6 {
+		lines = append(lines, styleMuted.Render(legend+"  "+cachedLegend))
+	} else {
+		lines = append(lines, styleMuted.Render(legend), styleMuted.Render(cachedLegend))
+	}
 
 	box := styleOverlay.
 		Width(w).
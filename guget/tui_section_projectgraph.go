@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// openProjectGraph shows the project references around the selected
+// project, or every project when "All Projects" is selected.
+func (m *App) openProjectGraph() {
+	m.ctx.StatusLine = ""
+	m.projectGraph = projectGraphOverlay{
+		sectionBase: sectionBase{app: m, baseWidth: 80, minWidth: 48, maxMargin: 4, active: true},
+		graph:       buildProjectGraph(m.ctx.ParsedProjects),
+	}
+	m.projectGraph.setCenter(m.selectedProject())
+}
+
+// setCenter rebuilds the rows around p and moves the cursor to the first
+// project row.
+func (s *projectGraphOverlay) setCenter(p *ParsedProject) {
+	s.center = p
+	s.rows = nil
+	if p == nil {
+		s.rows = append(s.rows, projectGraphRow{header: "Projects"})
+		for _, proj := range s.app.ctx.ParsedProjects {
+			s.rows = append(s.rows, projectGraphRow{
+				path:    proj.FilePath,
+				project: proj,
+				note:    fmt.Sprintf("%d reference(s) · %d dependent(s)", len(proj.ProjectRefs), len(s.graph.Dependents(proj))),
+			})
+		}
+	} else {
+		s.rows = append(s.rows, projectGraphRow{header: "References"})
+		s.addReferences(p, 0, map[string]bool{projectPathKey(p.FilePath): true}, map[string]bool{})
+		s.rows = append(s.rows, projectGraphRow{header: "Referenced by"})
+		s.addReferencedBy(p, 0, map[string]bool{projectPathKey(p.FilePath): true}, map[string]bool{})
+	}
+	s.cursor = 0
+	s.move(1)
+}
+
+// addReferences appends the projects p references, each followed by its own
+// references. A project already expanded elsewhere is listed once more
+// without its subtree.
+func (s *projectGraphOverlay) addReferences(p *ParsedProject, depth int, ancestors, expanded map[string]bool) {
+	for _, ref := range p.ProjectRefs {
+		row := projectGraphRow{path: ref, project: s.graph.Project(ref), depth: depth}
+		key := projectPathKey(ref)
+		switch {
+		case ancestors[key]:
+			row.note = "cycle"
+		case expanded[key]:
+			row.note = "see above"
+		case row.project == nil:
+			row.note = "not loaded"
+		}
+		s.rows = append(s.rows, row)
+		if row.note == "" {
+			expanded[key] = true
+			ancestors[key] = true
+			s.addReferences(row.project, depth+1, ancestors, expanded)
+			delete(ancestors, key)
+		}
+	}
+	if depth == 0 && len(p.ProjectRefs) == 0 {
+		s.rows = append(s.rows, projectGraphRow{note: "no project references"})
+	}
+}
+
+// addReferencedBy appends the projects that reference p, each followed by
+// the projects that reference it in turn.
+func (s *projectGraphOverlay) addReferencedBy(p *ParsedProject, depth int, ancestors, expanded map[string]bool) {
+	deps := s.graph.ReferencedBy(p.FilePath)
+	for _, dep := range deps {
+		row := projectGraphRow{path: dep.FilePath, project: dep, depth: depth}
+		key := projectPathKey(dep.FilePath)
+		switch {
+		case ancestors[key]:
+			row.note = "cycle"
+		case expanded[key]:
+			row.note = "see above"
+		}
+		s.rows = append(s.rows, row)
+		if row.note == "" {
+			expanded[key] = true
+			ancestors[key] = true
+			s.addReferencedBy(dep, depth+1, ancestors, expanded)
+			delete(ancestors, key)
+		}
+	}
+	if depth == 0 && len(deps) == 0 {
+		s.rows = append(s.rows, projectGraphRow{note: "no loaded project references it"})
+	}
+}
+
+// move steps the cursor by delta to the next project row, staying put when
+// there is none in that direction.
+func (s *projectGraphOverlay) move(delta int) {
+	for i := s.cursor + delta; i >= 0 && i < len(s.rows); i += delta {
+		if s.rows[i].path != "" {
+			s.cursor = i
+			return
+		}
+	}
+}
+
+func (s *projectGraphOverlay) selected() *projectGraphRow {
+	if s.cursor < len(s.rows) && s.rows[s.cursor].path != "" {
+		return &s.rows[s.cursor]
+	}
+	return nil
+}
+
+// selectProjectItem moves the projects panel to p. Reports false when p is
+// not listed there, e.g. hidden by the starred-only filter.
+func (m *App) selectProjectItem(p *ParsedProject) bool {
+	for i, item := range m.projects.items {
+		if item.project != p {
+			continue
+		}
+		if i != m.projects.cursor {
+			m.projects.cursor = i
+			m.clampProjectOffset()
+			m.packages.cursor = 0
+			m.packages.scroll = 0
+			m.rebuildPackageRows()
+			m.refreshDetail()
+		}
+		return true
+	}
+	return false
+}
+
+func (s *projectGraphOverlay) FooterKeys() []kv {
+	keys := []kv{{"↑↓", "nav"}, {"enter", "show references"}, {"s", "select project"}}
+	if len(s.history) > 0 {
+		keys = append(keys, kv{"backspace", "back"})
+	}
+	return append(keys, kv{"esc", "close"})
+}
+
+func (s *projectGraphOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q", "G":
+		s.closeOverlay()
+	case "up", "k":
+		s.move(-1)
+	case "down", "j":
+		s.move(1)
+	case "enter":
+		if row := s.selected(); row != nil && row.project != nil && row.project != s.center {
+			s.history = append(s.history, s.center)
+			s.setCenter(row.project)
+		}
+	case "backspace":
+		if n := len(s.history); n > 0 {
+			prev := s.center
+			s.setCenter(s.history[n-1])
+			s.history = s.history[:n-1]
+			for i, row := range s.rows {
+				if prev != nil && row.project == prev {
+					s.cursor = i
+					break
+				}
+			}
+		}
+	case "s":
+		p := s.center
+		if row := s.selected(); row != nil {
+			p = row.project
+		}
+		if p == nil {
+			return nil
+		}
+		if !s.app.selectProjectItem(p) {
+			return s.app.setStatus("▲ "+p.FileName+" is not in the project list", true)
+		}
+		s.closeOverlay()
+		s.app.focus = focusPackages
+	}
+	return nil
+}
+
+func (s *projectGraphOverlay) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	title := "Project references"
+	var lines []string
+	if s.center != nil {
+		title += " · " + s.center.FileName
+	}
+	lines = append(lines,
+		styleAccentBold.Render(title),
+		styleBorder.Render(strings.Repeat("─", inner)),
+	)
+	if s.center != nil {
+		n := len(s.graph.Dependents(s.center))
+		summary := "No other loaded project picks up its package changes"
+		if n > 0 {
+			summary = fmt.Sprintf("Package changes here reach %d dependent project(s)", n)
+		}
+		lines = append(lines, styleSubtle.Render(truncate(summary, inner)), "")
+	}
+
+	maxRows := max(1, s.app.overlayHeight()-10)
+	start := 0
+	if s.cursor >= maxRows {
+		start = s.cursor - maxRows + 1
+	}
+	end := min(len(s.rows), start+maxRows)
+	for i := start; i < end; i++ {
+		lines = append(lines, s.renderRow(s.rows[i], i == s.cursor, inner))
+	}
+	if end < len(s.rows) {
+		lines = append(lines, styleMuted.Render(fmt.Sprintf("  … %d more", len(s.rows)-end)))
+	}
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
+
+func (s *projectGraphOverlay) renderRow(row projectGraphRow, selected bool, inner int) string {
+	if row.header != "" {
+		return styleSubtleBold.Render(row.header)
+	}
+	if row.path == "" {
+		return "  " + styleMuted.Render(row.note)
+	}
+	prefix := "  "
+	nameStyle := styleText
+	if selected {
+		prefix = styleAccentBold.Render(glyphs.Cursor)
+		nameStyle = styleAccentBold
+	}
+	if row.project == nil || row.project.LoadErr != nil {
+		nameStyle = styleMuted
+	}
+	indent := strings.Repeat("  ", row.depth)
+	name := filepath.Base(row.path)
+	note := ""
+	if row.note != "" {
+		note = "  " + styleMuted.Render(row.note)
+	}
+	return prefix + indent + nameStyle.Render(truncate(name, max(8, inner-4-len(indent)-len(row.note)))) + note
+}
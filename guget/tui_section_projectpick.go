@@ -27,6 +27,9 @@ func (m *App) openProjectPicker(pkgName, version string) {
 
 	items := make([]projectPickItem, 0, len(allProjects))
 	for _, p := range allProjects {
+		if p.LoadErr != nil || p.Legacy {
+			continue
+		}
 		item := projectPickItem{project: p}
 		for ref := range p.Packages {
 			if strings.EqualFold(ref.Name, pkgName) {
@@ -142,12 +145,16 @@ func (s *projectPicker) confirmSelection() bubble_tea.Cmd {
 			source: s.app.search.fetchedSource,
 		}
 	}
-	var cmds []bubble_tea.Cmd
-	for _, proj := range selected {
-		target := defaultAddTarget(proj)
-		cmds = append(cmds, s.app.addPackageToLocation(s.pkgName, s.version, proj, target))
-	}
-	return bubble_tea.Batch(cmds...)
+	pkgName, version := s.pkgName, s.version
+	title := fmt.Sprintf("Add %s %s to %d projects?", pkgName, version, len(selected))
+	return s.app.guardBulk("add", len(selected), title, func() bubble_tea.Cmd {
+		var cmds []bubble_tea.Cmd
+		for _, proj := range selected {
+			target := defaultAddTarget(proj)
+			cmds = append(cmds, s.app.addPackageToLocation(pkgName, version, proj, target))
+		}
+		return bubble_tea.Batch(cmds...)
+	})
 }
 
 // defaultAddTarget picks the best AddTarget for a project when adding a
@@ -223,7 +230,7 @@ func (s *projectPicker) Render() string {
 
 		cursor := "  "
 		if selected {
-			cursor = styleAccent.Render("▶ ")
+			cursor = styleAccent.Render(glyphs.Cursor)
 			if it.selectable() {
 				nameStyle = styleAccentBold
 			}
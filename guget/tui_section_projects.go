@@ -1,7 +1,9 @@
 package main
 
 import (
+	"fmt"
 	"strings"
+	"time"
 )
 
 func (m *App) renderProjectPanel(w int) string {
@@ -29,21 +31,61 @@ func (m *App) renderProjectPanel(w int) string {
 		title := item.Title()
 		desc := item.Description()
 
-		title = truncate(title, innerW-3)
-		desc = truncate(desc, innerW-5)
+		broken := item.project != nil && item.project.LoadErr != nil
+		if lag, ok := m.projectLag(item.project); ok && lag > 0 && !broken {
+			desc += " · " + formatLag(lag)
+		}
+
+		// Projects being restored spin until their restore finishes.
+		spin, spinW := "", 0
+		if m.restoreLive.projectRunning(item.project) {
+			spin, spinW = m.ctx.Spinner.View(), 2
+		}
+
+		star := ""
+		if m.projectStarred(item.project) {
+			star = " " + styleYellow.Render("★")
+			title = truncate(title, innerW-5-spinW)
+		} else {
+			title = truncate(title, innerW-3-spinW)
+		}
+		// Projects whose transitive dependencies the last V scan flagged.
+		flag := ""
+		if n := m.transitiveVulnCount(item.project); n > 0 && !broken {
+			flag = fmt.Sprintf(" · %d vulnerable transitive", n)
+		}
+		desc = truncate(desc, innerW-5-len(flag))
 
-		if selected {
-			lines = append(lines, " "+styleAccentBold.Render(title))
-			lines = append(lines, "   "+styleSubtle.Render(desc))
+		if broken {
+			titleStyle := styleRed
+			if selected {
+				titleStyle = styleRedBold
+			}
+			lines = append(lines, " "+spin+titleStyle.Render(title)+star)
+			lines = append(lines, "   "+styleRed.Render(desc))
+		} else if selected {
+			lines = append(lines, " "+spin+styleAccentBold.Render(title)+star)
+			lines = append(lines, "   "+styleSubtle.Render(desc)+styleRed.Render(flag))
 		} else {
-			lines = append(lines, " "+styleText.Render(title))
-			lines = append(lines, "   "+styleMuted.Render(desc))
+			lines = append(lines, " "+spin+styleText.Render(title)+star)
+			lines = append(lines, "   "+styleMuted.Render(desc)+styleRed.Render(flag))
 		}
 		if i < end-1 {
-			lines = append(lines, "")
+			// The gap before the first project of a repository or solution
+			// folder names it.
+			if folder := projectItemFolder(m.projects.items[i+1]); folder != "" && folder != projectItemFolder(item) {
+				lines = append(lines, " "+styleMuted.Render(truncate("▸ "+folder, innerW-2)))
+			} else {
+				lines = append(lines, "")
+			}
 		}
 	}
 
+	if m.stars.only && len(m.projects.items) == 1 {
+		lines = append(lines, "")
+		lines = append(lines, " "+styleMuted.Render(truncate("No starred projects", innerW-2)))
+	}
+
 	content := strings.Join(lines, "\n")
 
 	s := stylePanelNoPad
@@ -52,3 +94,46 @@ func (m *App) renderProjectPanel(w int) string {
 	}
 	return renderToPanel(s, w, m.bodyOuterHeight(), content)
 }
+
+func projectItemFolder(item projectItem) string {
+	if item.project == nil {
+		return ""
+	}
+	p := item.project
+	switch {
+	case p.Repo == "":
+		return p.SolutionFolder
+	case p.SolutionFolder == "":
+		return p.Repo
+	default:
+		return p.Repo + " / " + p.SolutionFolder
+	}
+}
+
+// projectLag sums the release lag of every package in p whose metadata has
+// loaded — the project's total "libyears" behind. nil aggregates all
+// projects. ok is false until at least one package contributes.
+func (m *App) projectLag(p *ParsedProject) (total time.Duration, ok bool) {
+	projects := []*ParsedProject{p}
+	if p == nil {
+		projects = m.ctx.ParsedProjects
+	}
+	for _, proj := range projects {
+		for ref := range proj.Packages {
+			res, found := m.ctx.Results[ref.Name]
+			if !found || res.pkg == nil {
+				continue
+			}
+			if lag, known := res.pkg.ReleaseLag(ref.Version); known {
+				total += lag
+				ok = true
+			}
+		}
+	}
+	return total, ok
+}
+
+// formatLag renders a lag in years with one decimal, e.g. "2.4y behind".
+func formatLag(d time.Duration) string {
+	return fmt.Sprintf("%.1fy behind", d.Hours()/24/365)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// openReadme fetches the README embedded in the selected package's latest
+// version. Private feeds rarely serve READMEs, so nuget.org is asked next.
+func (m *App) openReadme() bubble_tea.Cmd {
+	if m.packages.cursor >= len(m.packages.rows) {
+		return nil
+	}
+	row := m.packages.rows[m.packages.cursor]
+	if row.info == nil {
+		return nil
+	}
+	m.ctx.StatusLine = ""
+
+	version := row.info.LatestVersion
+	var services []*NugetService
+	for _, s := range m.ctx.NugetServices {
+		if strings.EqualFold(s.SourceName(), row.source) {
+			services = append(services, s)
+			break
+		}
+	}
+	scopes := m.ctx.SourceScopes
+	fallback := !strings.EqualFold(row.source, "nuget.org")
+
+	m.readme.pkgID = row.info.ID
+	m.readme.version = version
+	m.readme.source = ""
+	m.readme.body = ""
+	m.readme.err = nil
+	m.readme.loading = true
+	m.readme.active = true
+	m.readme.refreshView()
+
+	pkgID := row.info.ID
+	return func() bubble_tea.Msg {
+		if fallback {
+			if svc := enrichmentService(scopes); svc != nil {
+				services = append(services, svc)
+			}
+		}
+		var firstErr error
+		for _, svc := range services {
+			body, err := svc.FetchReadme(pkgID, version)
+			if err != nil {
+				logDebug("README for %s %s from %s: %v", pkgID, version, svc.SourceName(), err)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if strings.TrimSpace(body) != "" {
+				return readmeReadyMsg{pkgID: pkgID, version: version, source: svc.SourceName(), body: body}
+			}
+		}
+		return readmeReadyMsg{pkgID: pkgID, version: version, err: firstErr}
+	}
+}
+
+// finish shows a fetched README, unless the overlay has since moved on to
+// another package.
+func (s *readmeOverlay) finish(msg readmeReadyMsg) {
+	if msg.pkgID != s.pkgID || msg.version != s.version {
+		return
+	}
+	s.loading = false
+	s.body, s.source, s.err = msg.body, msg.source, msg.err
+	if s.active {
+		s.refreshView()
+	}
+}
+
+func (s *readmeOverlay) FooterKeys() []kv {
+	return []kv{{"↑↓", "scroll"}, {"esc", "close"}}
+}
+
+func (s *readmeOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+		s.refreshView()
+	case "]":
+		s.Resize(4)
+		s.refreshView()
+	case "esc", "M", "q":
+		s.closeOverlay()
+	default:
+		var cmd bubble_tea.Cmd
+		s.vp, cmd = s.vp.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+func (s *readmeOverlay) refreshView() {
+	w := s.Width()
+	innerW := w - 6 // border (2) + padding (2*2)
+
+	title := styleAccentBold.Render(s.pkgID) + " " + styleMuted.Render(s.version+" — README")
+	if s.source != "" {
+		title += "  " + styleMuted.Render("from "+s.source)
+	}
+	lines := []string{truncateStyled(title, innerW), styleBorder.Render(strings.Repeat("─", innerW))}
+	switch {
+	case s.loading:
+		lines = append(lines, s.app.ctx.Spinner.View()+styleAccent.Render("Fetching README..."))
+	case s.err != nil && s.body == "":
+		lines = append(lines, styleRed.Render(wordWrap("Could not fetch the README: "+s.err.Error(), innerW)))
+	case strings.TrimSpace(s.body) == "":
+		lines = append(lines, styleMuted.Render(wordWrap("This package doesn't embed a README. n shows its release notes, and the detail panel links its project site.", innerW)))
+	default:
+		lines = append(lines, renderMarkdown(s.body, innerW))
+	}
+
+	maxH := s.app.overlayHeight() - 6
+	if maxH < 8 {
+		maxH = 8
+	}
+
+	s.vp.SetWidth(w - 4)
+	s.vp.SetHeight(maxH)
+	s.vp.SetContent(strings.Join(lines, "\n"))
+	s.vp.GotoTop()
+}
+
+func (s *readmeOverlay) Render() string {
+	box := styleOverlay.
+		Width(s.Width()).
+		Render(s.vp.View())
+
+	return s.centerOverlay(box)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	bubbles_viewport "charm.land/bubbles/v2/viewport"
@@ -12,6 +13,9 @@ import (
 
 const releaseListWidth = 22 // width of the left release/version list panel
 
+// maxChangeVersions caps how many versions the Changes tab fetches notes for.
+const maxChangeVersions = 30
+
 func newReleaseNotesOverlay(m *App, title string) releaseNotesOverlay {
 	rn := releaseNotesOverlay{
 		sectionBase: sectionBase{app: m, basePct: 100, minWidth: 60, maxMargin: 0, active: true},
@@ -125,6 +129,25 @@ func (m *App) openReleaseNotes() bubble_tea.Cmd {
 		})
 	}
 
+	// Changes: the notes for every version an update to the latest compatible
+	// version would cross, so they can be read in one place before updating.
+	if rn.nsSvc != nil && row.latestCompatible != nil && row.latestCompatible.SemVer.IsNewerThan(row.effectiveVersion()) {
+		between := row.info.VersionsBetween(row.effectiveVersion(), row.latestCompatible.SemVer)
+		if len(between) > maxChangeVersions {
+			rn.chOmitted = len(between) - maxChangeVersions
+			between = between[:maxChangeVersions]
+		}
+		rn.chVersions = between
+		rn.chFrom = row.effectiveVersion().String()
+		rn.chTo = row.latestCompatible.SemVer.String()
+		rn.chLoading = true
+		versions := make([]string, len(between))
+		for i, v := range between {
+			versions[i] = v.SemVer.String()
+		}
+		cmds = append(cmds, fetchChangeNotesCmd(rn.nsSvc, rn.nsPkgID, versions))
+	}
+
 	if len(cmds) == 0 {
 		rn.ghErr = fmt.Errorf("no release notes available")
 		rn.nsErr = fmt.Errorf("no release notes available")
@@ -136,6 +159,9 @@ func (m *App) openReleaseNotes() bubble_tea.Cmd {
 	} else {
 		rn.activeTab = tabNuSpec
 	}
+	if len(rn.chVersions) > 0 {
+		rn.activeTab = tabChanges
+	}
 
 	m.releaseNotes = rn
 	return bubble_tea.Batch(cmds...)
@@ -156,6 +182,31 @@ func fetchNuspecVersionNotesCmd(svc *NugetService, pkgID, version string) bubble
 	}
 }
 
+// fetchChangeNotesCmd fetches the nuspec <releaseNotes> of several versions
+// in parallel.
+func fetchChangeNotesCmd(svc *NugetService, pkgID string, versions []string) bubble_tea.Cmd {
+	return func() bubble_tea.Msg {
+		notes := make(map[string]string, len(versions))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, 4)
+		for _, ver := range versions {
+			wg.Add(1)
+			go func(ver string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				n := ExtractNuspecReleaseNotes(svc.FetchNuspec(pkgID, ver))
+				mu.Lock()
+				notes[ver] = n
+				mu.Unlock()
+			}(ver)
+		}
+		wg.Wait()
+		return changeNotesReadyMsg{notes: notes}
+	}
+}
+
 func (s *releaseNotesOverlay) fetchReleaseNotesCmd(rel GitHubRelease) bubble_tea.Cmd {
 	return func() bubble_tea.Msg {
 		return releaseNotesReadyMsg{body: rel.Body, htmlURL: rel.HTMLURL}
@@ -164,7 +215,7 @@ func (s *releaseNotesOverlay) fetchReleaseNotesCmd(rel GitHubRelease) bubble_tea
 
 func (s *releaseNotesOverlay) FooterKeys() []kv {
 	return []kv{
-		{"1/2", "tab"},
+		{"1-3", "tab"},
 		{"tab", "focus"},
 		{"↑↓", "nav/scroll"},
 		{"esc", "close"},
@@ -196,6 +247,12 @@ func (s *releaseNotesOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 			s.updateViewportContent()
 		}
 		return nil
+	case "3":
+		if len(s.chVersions) > 0 {
+			s.activeTab = tabChanges
+			s.updateViewportContent()
+		}
+		return nil
 	case "tab", "shift+tab":
 		s.focusRight = !s.focusRight
 		return nil
@@ -249,6 +306,15 @@ func (s *releaseNotesOverlay) moveCursor(delta int) bubble_tea.Cmd {
 		if s.nsSvc != nil {
 			return fetchNuspecVersionNotesCmd(s.nsSvc, s.nsPkgID, ver)
 		}
+	case tabChanges:
+		next := s.chCursor + delta
+		if next < 0 || next >= len(s.chVersions) {
+			return nil
+		}
+		s.chCursor = next
+		if next < len(s.chOffsets) {
+			s.vp.SetYOffset(s.chOffsets[next])
+		}
 	}
 	return nil
 }
@@ -259,6 +325,8 @@ func (s *releaseNotesOverlay) isLoading() bool {
 		return s.ghLoading
 	case tabNuSpec:
 		return s.nsLoading
+	case tabChanges:
+		return s.chLoading
 	}
 	return false
 }
@@ -307,6 +375,8 @@ func (s *releaseNotesOverlay) buildContent() string {
 		return s.buildGitHubContent()
 	case tabNuSpec:
 		return s.buildNuSpecContent()
+	case tabChanges:
+		return s.buildChangesContent()
 	}
 	return ""
 }
@@ -341,12 +411,10 @@ func (s *releaseNotesOverlay) buildGitHubContent() string {
 	sb.WriteString("\n")
 	sb.WriteString(styleBorder.Render(strings.Repeat("─", s.vp.Width())) + "\n")
 
-	body := s.ghNotes
-	if body == "" {
-		body = styleMuted.Render("(no release notes)")
-	}
-	if s.vp.Width() > 0 {
-		body = lipgloss.NewStyle().Width(s.vp.Width()).Render(body)
+	body := styleMuted.Render("(no release notes)")
+	if s.ghNotes != "" {
+		// GitHub release bodies are markdown.
+		body = renderMarkdown(s.ghNotes, s.vp.Width())
 	}
 	sb.WriteString(body)
 	return sb.String()
@@ -377,6 +445,48 @@ func (s *releaseNotesOverlay) buildNuSpecContent() string {
 	return sb.String()
 }
 
+// buildChangesContent lists the notes of every version between the installed
+// and target versions, newest first. Versions without nuspec notes fall back
+// to the matching GitHub release.
+func (s *releaseNotesOverlay) buildChangesContent() string {
+	var lines []string
+	header := styleAccentBold.Render(s.chFrom+" → "+s.chTo) + "  " +
+		styleMuted.Render(fmt.Sprintf("%d version(s)", len(s.chVersions)+s.chOmitted))
+	lines = append(lines, header)
+	if s.chOmitted > 0 {
+		lines = append(lines, styleYellow.Render(fmt.Sprintf("Showing the newest %d; %d older version(s) omitted", len(s.chVersions), s.chOmitted)))
+	}
+	lines = append(lines, styleBorder.Render(strings.Repeat("─", s.vp.Width())))
+
+	s.chOffsets = s.chOffsets[:0]
+	for _, v := range s.chVersions {
+		ver := v.SemVer.String()
+		s.chOffsets = append(s.chOffsets, len(lines))
+		heading := styleTextBold.Render(ver)
+		if !v.Published.IsZero() {
+			heading += "  " + styleMuted.Render(v.Published.Format("2006-01-02"))
+		}
+
+		body := s.nsNotesCache[ver]
+		if body == "" {
+			if rel := matchGitHubRelease(s.ghReleases, ver); rel != nil && rel.Body != "" {
+				body = rel.Body
+				heading += "  " + hyperlink(rel.HTMLURL, styleSubtle.Render("GitHub"))
+			}
+		}
+		lines = append(lines, heading)
+		if body == "" {
+			body = styleMuted.Render("(no release notes for this version)")
+		}
+		if s.vp.Width() > 0 {
+			body = lipgloss.NewStyle().Width(s.vp.Width()).Render(body)
+		}
+		lines = append(lines, strings.Split(body, "\n")...)
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (s *releaseNotesOverlay) tabLabel(tab releaseNotesTab) string {
 	switch tab {
 	case tabReleases:
@@ -397,6 +507,12 @@ func (s *releaseNotesOverlay) tabLabel(tab releaseNotesTab) string {
 			return label + " ✗"
 		}
 		return label
+	case tabChanges:
+		label := "Changes"
+		if s.chLoading {
+			return label + " " + s.app.ctx.Spinner.View()
+		}
+		return label
 	}
 	return ""
 }
@@ -418,16 +534,20 @@ func (s *releaseNotesOverlay) Render() string {
 	title := styleAccentBold.Render(s.title)
 
 	// ── Tab bar ──
-	ghLabel := s.tabLabel(tabReleases)
-	nsLabel := s.tabLabel(tabNuSpec)
-	if s.activeTab == tabReleases {
-		ghLabel = styleMuted.Render("[1] ") + styleAccentBold.Render(ghLabel)
-		nsLabel = styleMuted.Render("[2] " + nsLabel)
-	} else {
-		ghLabel = styleMuted.Render("[1] " + ghLabel)
-		nsLabel = styleMuted.Render("[2] ") + styleAccentBold.Render(nsLabel)
+	tabs := []releaseNotesTab{tabReleases, tabNuSpec}
+	if len(s.chVersions) > 0 {
+		tabs = append(tabs, tabChanges)
+	}
+	var tabLabels []string
+	for _, tab := range tabs {
+		key := fmt.Sprintf("[%d] ", int(tab)+1)
+		if tab == s.activeTab {
+			tabLabels = append(tabLabels, styleMuted.Render(key)+styleAccentBold.Render(s.tabLabel(tab)))
+		} else {
+			tabLabels = append(tabLabels, styleMuted.Render(key+s.tabLabel(tab)))
+		}
 	}
-	tabBar := ghLabel + styleBorder.Render(" │ ") + nsLabel
+	tabBar := strings.Join(tabLabels, styleBorder.Render(" │ "))
 
 	titleDivider := styleBorder.Render(strings.Repeat("─", innerW))
 
@@ -465,7 +585,7 @@ func (s *releaseNotesOverlay) Render() string {
 		for i, rel := range s.ghReleases {
 			tag := truncate(rel.TagName, maxTagW)
 			if i == s.ghCursor {
-				allLeft = append(allLeft, styleAccent.Render("▶ "+tag))
+				allLeft = append(allLeft, styleAccent.Render(glyphs.Cursor+tag))
 			} else {
 				allLeft = append(allLeft, styleMuted.Render("  "+tag))
 			}
@@ -477,7 +597,16 @@ func (s *releaseNotesOverlay) Render() string {
 		for i, ver := range s.nsVersions {
 			tag := truncate(ver, maxTagW)
 			if i == s.nsCursor {
-				allLeft = append(allLeft, styleAccent.Render("▶ "+tag))
+				allLeft = append(allLeft, styleAccent.Render(glyphs.Cursor+tag))
+			} else {
+				allLeft = append(allLeft, styleMuted.Render("  "+tag))
+			}
+		}
+	case tabChanges:
+		for i, v := range s.chVersions {
+			tag := truncate(v.SemVer.String(), maxTagW)
+			if i == s.chCursor {
+				allLeft = append(allLeft, styleAccent.Render(glyphs.Cursor+tag))
 			} else {
 				allLeft = append(allLeft, styleMuted.Render("  "+tag))
 			}
@@ -486,10 +615,13 @@ func (s *releaseNotesOverlay) Render() string {
 
 	// Scroll window: keep cursor visible
 	cursor := 0
-	if s.activeTab == tabReleases {
+	switch s.activeTab {
+	case tabReleases:
 		cursor = s.ghCursor
-	} else {
+	case tabNuSpec:
 		cursor = s.nsCursor
+	case tabChanges:
+		cursor = s.chCursor
 	}
 	scrollStart := 0
 	if cursor >= bodyH {
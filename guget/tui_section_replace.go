@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// openReplaceSearch opens the search overlay to pick the package that will
+// replace oldName, already searching for its successor when it was renamed.
+func (m *App) openReplaceSearch(oldName string) bubble_tea.Cmd {
+	focus := m.openSearch()
+	if !m.search.active {
+		return focus
+	}
+	m.search.replaceFor = oldName
+	m.search.input.Placeholder = "Type the replacement package..."
+	if to := m.ctx.Renames.To(oldName); to != "" {
+		q := to
+		m.search.input.SetValue(q)
+		m.search.input.CursorEnd()
+		m.search.lastQuery = q
+		m.search.loading = true
+		return bubble_tea.Batch(focus, m.search.doSearchCmd(q))
+	}
+	return focus
+}
+
+// openReplacePreview shows what replacing oldName with info would change.
+// From the All Projects view it covers every project; otherwise it starts
+// with the selected project, and a toggles to all of them.
+func (m *App) openReplacePreview(oldName string, info *PackageInfo, source string) {
+	m.replace = replacePreview{
+		sectionBase: sectionBase{app: m, baseWidth: 90, minWidth: 60, maxMargin: 4, active: true},
+		oldName:     oldName,
+		info:        info,
+		source:      source,
+		allProjects: m.selectedProject() == nil,
+	}
+	m.replace.replan()
+	m.ctx.StatusLine = ""
+}
+
+func (s *replacePreview) replan() {
+	scope := s.app.ctx.ParsedProjects
+	if !s.allProjects {
+		if p := s.app.selectedProject(); p != nil {
+			scope = []*ParsedProject{p}
+		}
+	}
+	s.plan = planReplacement(s.app.allProjects(), scope, s.oldName, s.info, s.source)
+	s.scroll = 0
+}
+
+func (s *replacePreview) FooterKeys() []kv {
+	keys := []kv{{"enter/y", "replace"}}
+	if s.app.selectedProject() != nil {
+		keys = append(keys, kv{"a", "all projects"})
+	}
+	return append(keys, kv{"↑↓", "scroll"}, kv{"esc", "cancel"})
+}
+
+func (s *replacePreview) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "n", "q":
+		s.closeOverlay()
+	case "up", "k":
+		if s.scroll > 0 {
+			s.scroll--
+		}
+	case "down", "j":
+		if s.scroll < len(s.plan.Rows)-1 {
+			s.scroll++
+		}
+	case "a":
+		if s.app.selectedProject() != nil {
+			s.allProjects = !s.allProjects
+			s.replan()
+		}
+	case "enter", "y":
+		if cmd := s.app.readOnlySessionStatus(); cmd != nil {
+			return cmd
+		}
+		if len(s.plan.Ops) == 0 {
+			return s.app.setStatus("✗ Nothing to replace: no project can take "+s.plan.New, true)
+		}
+		n := 0
+		for _, row := range s.plan.Rows {
+			if row.NewVersion != "" {
+				n++
+			}
+		}
+		plan := s.plan
+		s.closeOverlay()
+		return s.app.guardBulk("replace", n, fmt.Sprintf("Replace %s with %s in %d projects?", plan.Old, plan.New, n), func() bubble_tea.Cmd {
+			return func() bubble_tea.Msg {
+				return replaceDoneMsg{plan: plan, err: applyReplacePlan(plan)}
+			}
+		})
+	}
+	return nil
+}
+
+func (s *replacePreview) Render() string {
+	w := s.Width()
+	innerW := w - 6 // border (2) + padding (2*2)
+
+	scope := "this project"
+	if s.allProjects {
+		scope = "all projects"
+	}
+	lines := []string{
+		styleAccentBold.Render("Replace ") + styleTextBold.Render(s.oldName) +
+			styleAccentBold.Render(" with ") + styleTextBold.Render(s.plan.New) +
+			styleMuted.Render("  ("+scope+")"),
+		styleBorder.Render(strings.Repeat("─", innerW)),
+	}
+
+	if len(s.plan.Rows) == 0 {
+		lines = append(lines, styleMuted.Render("No editable project references "+s.oldName))
+	}
+	maxVisible := max(s.app.overlayHeight()-14, 3)
+	end := min(s.scroll+maxVisible, len(s.plan.Rows))
+	for _, row := range s.plan.Rows[s.scroll:end] {
+		lines = append(lines, styleTextBold.Render(truncate(row.Project.FileName, innerW)))
+		change := "  " + styleRed.Render("− "+s.oldName+" "+row.OldVersion)
+		if row.NewVersion != "" {
+			change += "   " + styleGreen.Render("+ "+s.plan.New+" "+row.NewVersion)
+		}
+		lines = append(lines, change)
+		if row.DefinedIn != row.Project.FilePath {
+			lines = append(lines, styleMuted.Render("  in "+truncate(filepath.Base(row.DefinedIn), innerW-5)+" (shared)"))
+		}
+		if row.Note != "" {
+			style := styleMuted
+			if row.NewVersion == "" {
+				style = styleYellow
+			}
+			lines = append(lines, style.Render(wordWrap("  "+row.Note, innerW)))
+		}
+	}
+	if end < len(s.plan.Rows) {
+		lines = append(lines, styleMuted.Render(fmt.Sprintf("… %d more", len(s.plan.Rows)-end)))
+	}
+
+	lines = append(lines, styleBorder.Render(strings.Repeat("─", innerW)))
+	files := s.plan.Files()
+	if len(files) > 0 {
+		lines = append(lines, styleMuted.Render(fmt.Sprintf("%d file(s) written together; if one fails, none are changed.", len(files))))
+	}
+	lines = append(lines, styleMuted.Render(wordWrap("Code that uses "+s.oldName+" will still need updating by hand.", innerW)))
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
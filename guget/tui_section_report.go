@@ -0,0 +1,99 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+var reportFormatLabels = map[string]string{
+	"json":     "full status of every package reference",
+	"sarif":    "findings for GitHub code scanning",
+	"markdown": "summary tables for a PR or wiki",
+}
+
+func (m *App) openReportExport() bubble_tea.Cmd {
+	if m.ctx.Loading {
+		return m.setStatus("▲ Wait for packages to finish loading before exporting a report", true)
+	}
+	m.reportExport = reportExport{
+		sectionBase: sectionBase{app: m, baseWidth: 56, minWidth: 40, maxMargin: 4, active: true},
+	}
+	return nil
+}
+
+func (s *reportExport) FooterKeys() []kv {
+	return []kv{{"↑↓", "nav"}, {"enter", "export"}, {"esc", "cancel"}}
+}
+
+func (s *reportExport) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q", "X":
+		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(validReportFormats)-1 {
+			s.cursor++
+		}
+	case "enter":
+		format := validReportFormats[s.cursor]
+		s.closeOverlay()
+		return s.app.exportReport(format)
+	}
+	return nil
+}
+
+// exportReport writes the report for the workspace as loaded, using the
+// metadata already fetched rather than contacting the sources again.
+func (m *App) exportReport(format string) bubble_tea.Cmd {
+	rep := buildReport(m.projectDir, m.ctx.ParsedProjects, m.ctx.Results, m.ctx.Rules, m.ctx.Prerelease, time.Now())
+	path, err := saveReport(m.projectDir, "", format, rep)
+	if err != nil {
+		logError("Writing report: %v", err)
+		return m.setStatus("✗ Writing report: "+err.Error(), true)
+	}
+	rel, relErr := filepath.Rel(m.projectDir, path)
+	if relErr != nil {
+		rel = path
+	}
+	logInfo("Wrote %s report to %s", format, path)
+	return m.setStatus("✓ Report written to "+filepath.ToSlash(rel), false)
+}
+
+func (s *reportExport) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	lines := []string{
+		styleAccentBold.Render("Export report"),
+		styleBorder.Render(strings.Repeat("─", inner)),
+	}
+	for i, format := range validReportFormats {
+		prefix := "  "
+		nameStyle := styleText
+		if i == s.cursor {
+			prefix = styleAccentBold.Render(glyphs.Cursor)
+			nameStyle = styleAccentBold
+		}
+		lines = append(lines,
+			prefix+nameStyle.Render(format)+"  "+styleMuted.Render(truncate(reportFormatLabels[format], inner-len(format)-4)),
+		)
+	}
+	lines = append(lines, "",
+		styleMuted.Render(wordWrap("Written to "+reportDir+"/. From a script, use guget report --format <format>.", inner)),
+	)
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
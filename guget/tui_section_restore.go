@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// setRestoreReport replaces the restore summary with the latest run.
+func (m *App) setRestoreReport(outcomes []restoreOutcome, elapsed time.Duration) {
+	r := restoreReport{
+		sectionBase: sectionBase{app: m, baseWidth: 96, minWidth: 50, maxMargin: 4},
+		outcomes:    outcomes,
+		elapsed:     elapsed,
+	}
+	for i, o := range outcomes {
+		r.rows = append(r.rows, restoreReportRow{outcome: i, message: -1})
+		for j := range o.Messages {
+			r.rows = append(r.rows, restoreReportRow{outcome: i, message: j})
+		}
+	}
+	m.restoreReport = r
+}
+
+// openRestoreReport shows the summary of the last restore. Reports false
+// when no restore has run this session.
+func (m *App) openRestoreReport() bool {
+	if len(m.restoreReport.outcomes) == 0 {
+		return false
+	}
+	m.ctx.StatusLine = ""
+	m.restoreReport.active = true
+	return true
+}
+
+// messageCount is the number of NuGet warnings and errors across targets.
+func (s *restoreReport) messageCount() int {
+	n := 0
+	for _, o := range s.outcomes {
+		n += len(o.Messages)
+	}
+	return n
+}
+
+func (s *restoreReport) message(row restoreReportRow) *restoreMessage {
+	if row.message < 0 {
+		return nil
+	}
+	return &s.outcomes[row.outcome].Messages[row.message]
+}
+
+// formatElapsed renders a duration for status lines, e.g. 850ms or 12.4s.
+func formatElapsed(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// jumpToRestoreMessage selects the project a restore target or message is
+// about and, when pkg is set, that package's row.
+func (m *App) jumpToRestoreMessage(project, pkg string) bubble_tea.Cmd {
+	var proj *ParsedProject
+	key := projectPathKey(project)
+	for _, p := range m.ctx.ParsedProjects {
+		if projectPathKey(p.FilePath) == key {
+			proj = p
+			break
+		}
+	}
+	if proj == nil || !m.selectProjectItem(proj) {
+		if pkg == "" {
+			return m.setStatus("▲ "+filepath.Base(project)+" is not in the project list", true)
+		}
+		m.selectProjectItem(nil) // All Projects
+	}
+	if pkg == "" {
+		m.focus = focusProjects
+		return nil
+	}
+	m.focus = focusPackages
+	if !m.selectPackageRow(pkg) {
+		return m.setStatus("▲ "+pkg+" is not a direct reference here (transitive?)", true)
+	}
+	return nil
+}
+
+// jumpToLocalProject selects the workspace project that builds pkg.
+func (m *App) jumpToLocalProject(pkg string) bubble_tea.Cmd {
+	p := localPackageProject(m.ctx.ParsedProjects, pkg)
+	if p == nil {
+		return m.setStatus(pkg+" isn't built by a project in this workspace", false)
+	}
+	if !m.selectProjectItem(p) {
+		return m.setStatus("▲ "+p.FileName+" is not in the project list", true)
+	}
+	m.focus = focusProjects
+	return nil
+}
+
+// selectPackageRow moves the package cursor to name, clearing the package
+// filter if it hides it. Reports false when no row has that name.
+func (m *App) selectPackageRow(name string) bool {
+	find := func() int {
+		for i, row := range m.packages.rows {
+			if strings.EqualFold(row.ref.Name, name) {
+				return i
+			}
+		}
+		return -1
+	}
+	i := find()
+	if i < 0 && m.packageFiltered() {
+		m.clearPackageFilter()
+		i = find()
+	}
+	if i < 0 {
+		return false
+	}
+	m.packages.cursor = i
+	m.clampOffset()
+	m.refreshDetail()
+	return true
+}
+
+func (s *restoreReport) FooterKeys() []kv {
+	return []kv{{"↑↓", "nav"}, {"enter", "go to package"}, {"esc", "close"}}
+}
+
+func (s *restoreReport) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q", "D":
+		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.rows)-1 {
+			s.cursor++
+		}
+	case "enter":
+		if s.cursor >= len(s.rows) {
+			return nil
+		}
+		row := s.rows[s.cursor]
+		project, pkg := s.outcomes[row.outcome].Target, ""
+		if msg := s.message(row); msg != nil {
+			project, pkg = msg.Project, msg.Package
+		}
+		s.closeOverlay()
+		return s.app.jumpToRestoreMessage(project, pkg)
+	}
+	return nil
+}
+
+func (s *restoreReport) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	failed := 0
+	for _, o := range s.outcomes {
+		if o.Err != nil {
+			failed++
+		}
+	}
+	title := fmt.Sprintf("Restore · %d target(s) in %s", len(s.outcomes), formatElapsed(s.elapsed))
+	titleStyle := styleAccentBold
+	if failed > 0 {
+		title += fmt.Sprintf(" · %d failed", failed)
+		titleStyle = styleRedBold
+	}
+	lines := []string{
+		titleStyle.Render(title),
+		styleBorder.Render(strings.Repeat("─", inner)),
+	}
+
+	maxRows := max(1, s.app.overlayHeight()-12)
+	start := 0
+	if s.cursor >= maxRows {
+		start = s.cursor - maxRows + 1
+	}
+	end := min(len(s.rows), start+maxRows)
+	for i := start; i < end; i++ {
+		lines = append(lines, s.renderRow(s.rows[i], i == s.cursor, inner))
+	}
+	if end < len(s.rows) {
+		lines = append(lines, styleMuted.Render(fmt.Sprintf("  … %d more", len(s.rows)-end)))
+	}
+
+	// The selected message in full, since rows are cut to one line.
+	lines = append(lines, "")
+	if s.cursor < len(s.rows) {
+		if msg := s.message(s.rows[s.cursor]); msg != nil {
+			lines = append(lines, styleSubtle.Render(wordWrap(msg.Code+": "+msg.Text, inner)))
+		} else if o := s.outcomes[s.rows[s.cursor].outcome]; o.Err != nil && len(o.Messages) == 0 {
+			lines = append(lines, styleMuted.Render("No NuGet messages in the output; the full output is in the log (l)."))
+		}
+	}
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
+
+func (s *restoreReport) renderRow(row restoreReportRow, selected bool, inner int) string {
+	prefix := "  "
+	if selected {
+		prefix = styleAccentBold.Render(glyphs.Cursor)
+	}
+	o := s.outcomes[row.outcome]
+	msg := s.message(row)
+	if msg == nil {
+		icon, nameStyle := styleGreen.Render("✓"), styleText
+		if o.Err != nil {
+			icon, nameStyle = styleRed.Render("✗"), styleRed
+		}
+		if selected {
+			nameStyle = nameStyle.Bold(true)
+		}
+		warnings, errs := 0, 0
+		for _, m := range o.Messages {
+			if m.Severity == "error" {
+				errs++
+			} else {
+				warnings++
+			}
+		}
+		counts := ""
+		if errs > 0 {
+			counts += "  " + styleRed.Render(fmt.Sprintf("%d error(s)", errs))
+		}
+		if warnings > 0 {
+			counts += "  " + styleYellow.Render(fmt.Sprintf("%d warning(s)", warnings))
+		}
+		return prefix + icon + " " + nameStyle.Render(truncate(filepath.Base(o.Target), inner/2)) +
+			"  " + styleMuted.Render(formatElapsed(o.Elapsed)) + counts
+	}
+
+	codeStyle := styleYellow
+	if msg.Severity == "error" {
+		codeStyle = styleRed
+	}
+	line := prefix + "    " + codeStyle.Render(msg.Code) + " "
+	used := 2 + 4 + len(msg.Code) + 1
+	if msg.Package != "" {
+		line += styleAccent.Render(msg.Package) + " "
+		used += len(msg.Package) + 1
+	}
+	if filepath.Base(msg.Project) != filepath.Base(o.Target) {
+		tag := "(" + filepath.Base(msg.Project) + ") "
+		line += styleSubtle.Render(tag)
+		used += len(tag)
+	}
+	return line + styleMuted.Render(truncate(msg.Text, max(8, inner-used)))
+}
+
+// restoreWarningCodes returns the NuGet codes the last restore logged for a
+// package in project, or in any project when project is nil.
+func (m *App) restoreWarningCodes(project *ParsedProject, name string) []string {
+	key := strings.ToLower(name)
+	if project != nil {
+		return project.RestoreWarnings[key]
+	}
+	var codes []string
+	for _, p := range m.ctx.ParsedProjects {
+		for _, c := range p.RestoreWarnings[key] {
+			if !slices.Contains(codes, c) {
+				codes = append(codes, c)
+			}
+		}
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// restoreWarningBadge is the row badge for codes: the first code, and how
+// many more there are.
+func restoreWarningBadge(codes []string) string {
+	switch len(codes) {
+	case 0:
+		return ""
+	case 1:
+		return codes[0]
+	default:
+		return fmt.Sprintf("%s+%d", codes[0], len(codes)-1)
+	}
+}
+
+// refreshRestoreWarnings rereads the warnings the restore just wrote to each
+// project's assets file.
+func (m *App) refreshRestoreWarnings() {
+	for _, p := range m.ctx.ParsedProjects {
+		if p.LoadErr != nil || p.Legacy || !isProjectFile(p.FilePath) {
+			continue
+		}
+		p.RestoreWarnings = readProjectAssets(filepath.Dir(p.FilePath)).warnings()
+	}
+	m.rebuildPackageRows()
+	m.refreshDetail()
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// restoreOutputLines is how much live output the progress overlay keeps.
+const restoreOutputLines = 500
+
+// startRestore restores targets in the background, restoreJobs at a time,
+// streaming each target's state and output to the progress overlay.
+func (m *App) startRestore(targets []string) bubble_tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.restoreLive = restoreProgress{
+		sectionBase: sectionBase{app: m, basePct: 80, minWidth: 56, maxMargin: 4, active: true},
+		run:         m.restoreLive.run + 1,
+		cancel:      cancel,
+		targets:     targets,
+		started:     time.Now(),
+		running:     NewSet[string](),
+		finished:    make(map[string]error),
+	}
+	m.ctx.StatusLine = ""
+	run, send := m.restoreLive.run, m.send
+	notify := func(msg bubble_tea.Msg) {
+		if send != nil {
+			send(msg)
+		}
+	}
+	return func() bubble_tea.Msg {
+		defer cancel()
+		start := time.Now()
+		outcomes, err := runRestores(targets, restoreJobs, func(target string) restoreOutcome {
+			notify(restoreTargetMsg{run: run, target: target})
+			o := dotnetRestore(ctx, target, func(line string) {
+				notify(restoreLineMsg{run: run, target: target, line: line})
+			})
+			notify(restoreTargetMsg{run: run, target: target, done: true, err: o.Err})
+			return o
+		})
+		return restoreResultMsg{err: err, outcomes: outcomes, elapsed: time.Since(start)}
+	}
+}
+
+func (s *restoreProgress) addLine(msg restoreLineMsg) {
+	if msg.run != s.run || strings.TrimSpace(msg.line) == "" {
+		return
+	}
+	line := msg.line
+	if len(s.targets) > 1 {
+		line = "[" + strings.TrimSuffix(filepath.Base(msg.target), filepath.Ext(msg.target)) + "] " + line
+	}
+	s.lines = append(s.lines, line)
+	if len(s.lines) > restoreOutputLines {
+		s.lines = s.lines[len(s.lines)-restoreOutputLines:]
+	}
+}
+
+func (s *restoreProgress) updateTarget(msg restoreTargetMsg) {
+	if msg.run != s.run {
+		return
+	}
+	if msg.done {
+		s.running.Remove(msg.target)
+		s.finished[msg.target] = msg.err
+	} else {
+		s.running.Add(msg.target)
+	}
+}
+
+// projectRunning reports whether p is being restored right now, on its own
+// or through the solution. "All Projects" (nil) spins while anything does.
+func (s *restoreProgress) projectRunning(p *ParsedProject) bool {
+	if s.running.Len() == 0 {
+		return false
+	}
+	if p == nil || s.running.Contains(p.FilePath) {
+		return true
+	}
+	for target := range s.running {
+		if !isProjectFile(target) {
+			return true // a solution restores all of its projects
+		}
+	}
+	return false
+}
+
+// finishRestoreProgress closes the progress overlay when the restore ends,
+// showing the restore report in its place when it was open and there is
+// something to read. Reports whether the restore was cancelled.
+func (m *App) finishRestoreProgress(outcomes []restoreOutcome) bool {
+	p := &m.restoreLive
+	p.cancel = nil
+	p.running = NewSet[string]()
+	cancelled, failed := false, false
+	for _, o := range outcomes {
+		cancelled = cancelled || errors.Is(o.Err, errRestoreCancelled)
+		failed = failed || o.Err != nil
+	}
+	if p.active {
+		p.closeOverlay()
+		if failed || m.restoreReport.messageCount() > 0 {
+			m.openRestoreReport()
+		}
+	}
+	return cancelled
+}
+
+func (s *restoreProgress) FooterKeys() []kv {
+	if s.cancel == nil {
+		return []kv{{"esc", "close"}}
+	}
+	return []kv{{"x", "cancel restore"}, {"esc", "hide"}}
+}
+
+func (s *restoreProgress) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q", "D":
+		s.closeOverlay()
+	case "x":
+		if s.cancel != nil {
+			s.cancel()
+			logInfo("Cancelling restore")
+			return s.app.setStatus("Cancelling restore…", false)
+		}
+	}
+	return nil
+}
+
+func (s *restoreProgress) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	done := len(s.finished)
+	title := fmt.Sprintf("Restoring · %d of %d done · %s", done, len(s.targets), formatElapsed(time.Since(s.started).Truncate(100*time.Millisecond)))
+	lines := []string{
+		styleAccentBold.Render(truncate(title, inner)),
+		styleBorder.Render(strings.Repeat("─", inner)),
+	}
+
+	// At most a third of the overlay lists targets; the rest is output.
+	height := max(8, s.app.overlayHeight()-6)
+	maxTargets := max(1, height/3)
+	shown := 0
+	for _, target := range s.targets {
+		if shown == maxTargets {
+			lines = append(lines, styleMuted.Render(fmt.Sprintf("  … %d more", len(s.targets)-shown)))
+			break
+		}
+		shown++
+		name := truncate(filepath.Base(target), inner-14)
+		err, finished := s.finished[target]
+		switch {
+		case s.running.Contains(target):
+			lines = append(lines, s.app.ctx.Spinner.View()+styleText.Render(name))
+		case finished && errors.Is(err, errRestoreCancelled):
+			lines = append(lines, styleYellow.Render("⊘ ")+styleMuted.Render(name+"  cancelled"))
+		case finished && err != nil:
+			lines = append(lines, styleRed.Render("✗ ")+styleText.Render(name)+styleRed.Render("  failed"))
+		case finished:
+			lines = append(lines, styleGreen.Render("✓ ")+styleMuted.Render(name))
+		default:
+			lines = append(lines, styleMuted.Render("· "+name+"  queued"))
+		}
+	}
+
+	lines = append(lines, styleBorder.Render(strings.Repeat("─", inner)))
+	room := max(1, height-len(lines))
+	output := s.lines[max(0, len(s.lines)-room):]
+	if len(output) == 0 {
+		lines = append(lines, styleMuted.Render("Waiting for dotnet restore output…"))
+	}
+	for _, line := range output {
+		lines = append(lines, styleSubtle.Render(truncate(strings.ReplaceAll(line, "\t", "    "), inner)))
+	}
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// openRetarget previews retargeting the selected project, starting with
+// the newest framework it could move to.
+func (m *App) openRetarget() bubble_tea.Cmd {
+	p := m.selectedProject()
+	if p == nil {
+		return m.setStatus("Select a project to preview a retarget", false)
+	}
+	if p.LoadErr != nil {
+		return m.setStatus("✗ "+p.FileName+" could not be parsed", true)
+	}
+	candidates := retargetCandidates(p.TargetFrameworks)
+	if len(candidates) == 0 {
+		return m.setStatus(p.FileName+" already targets the newest framework", false)
+	}
+	m.retarget.project = p
+	m.retarget.candidates = candidates
+	m.retarget.cursor = len(candidates) - 1
+	m.retarget.active = true
+	m.retarget.refreshView()
+	m.ctx.StatusLine = ""
+	return nil
+}
+
+func (s *retargetOverlay) target() TargetFramework {
+	return s.candidates[s.cursor]
+}
+
+func (s *retargetOverlay) FooterKeys() []kv {
+	return []kv{{"←→", "framework"}, {"↑↓", "scroll"}, {"w", "write"}, {"esc", "close"}}
+}
+
+func (s *retargetOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+		s.refreshView()
+	case "]":
+		s.Resize(4)
+		s.refreshView()
+	case "left", "h":
+		if s.cursor > 0 {
+			s.cursor--
+			s.refreshView()
+		}
+	case "right", "l":
+		if s.cursor < len(s.candidates)-1 {
+			s.cursor++
+			s.refreshView()
+		}
+	case "w":
+		if cmd := s.app.readOnlyProjectStatus(s.project); cmd != nil {
+			return cmd
+		}
+		s.closeOverlay()
+		return s.app.writeTargetFramework(s.project, s.target().String(), s.impacts)
+	case "esc", "t", "q":
+		s.closeOverlay()
+	default:
+		var cmd bubble_tea.Cmd
+		s.vp, cmd = s.vp.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// writeTargetFramework sets project's <TargetFramework>; the file watcher
+// reloads it afterwards.
+func (m *App) writeTargetFramework(project *ParsedProject, framework string, impacts []retargetImpact) bubble_tea.Cmd {
+	updates, blocked := 0, 0
+	for _, r := range impacts {
+		switch r.Verdict {
+		case retargetUpdate:
+			updates++
+		case retargetBlocked:
+			blocked++
+		}
+	}
+	logInfo("writeTargetFramework: %s → %s", project.FileName, framework)
+	file := project.FilePath
+	return func() bubble_tea.Msg {
+		err := SetTargetFramework(file, framework)
+		recordAction(ActionRecord{Action: "retarget", Package: filepath.Base(file), Framework: framework, Files: []string{file}}, err)
+		if err != nil {
+			logWarn("retarget of %s failed: %v", file, err)
+			return writeResultMsg{err: err}
+		}
+		switch {
+		case blocked > 0:
+			logWarn("%s now targets %s, but %d package(s) have no version that supports it", filepath.Base(file), framework, blocked)
+		case updates > 0:
+			logInfo("%s now targets %s; %d package(s) need an update to support it", filepath.Base(file), framework, updates)
+		}
+		return writeResultMsg{}
+	}
+}
+
+func (s *retargetOverlay) refreshView() {
+	m := s.app
+	w := s.Width()
+	innerW := w - 6 // border (2) + padding (2*2)
+	p := s.project
+	to := s.target()
+	s.impacts = analyzeRetarget(p, m.ctx.Results, to, m.ctx.Prerelease)
+
+	var from []string
+	for tf := range p.TargetFrameworks {
+		from = append(from, tf.String())
+	}
+	slices.Sort(from)
+	picker := ""
+	for i, c := range s.candidates {
+		if i == s.cursor {
+			picker += styleAccentBold.Render("‹"+c.String()+"›") + " "
+		} else {
+			picker += styleMuted.Render(c.String()) + " "
+		}
+	}
+	lines := []string{
+		styleAccentBold.Render("Retarget ") + styleTextBold.Render(p.FileName),
+		styleBorder.Render(strings.Repeat("─", innerW)),
+		truncateStyled(styleMuted.Render(strings.Join(from, ";")+" → ")+picker, innerW),
+		"",
+	}
+
+	counts := make(map[retargetVerdict]int)
+	for _, r := range s.impacts {
+		counts[r.Verdict]++
+	}
+	var summary []string
+	if n := counts[retargetOK]; n > 0 {
+		summary = append(summary, styleGreen.Render(fmt.Sprintf("%d compatible", n)))
+	}
+	if n := counts[retargetUpdate]; n > 0 {
+		summary = append(summary, styleYellow.Render(fmt.Sprintf("%d need an update", n)))
+	}
+	if n := counts[retargetBlocked]; n > 0 {
+		summary = append(summary, styleRed.Render(fmt.Sprintf("%d block the upgrade", n)))
+	}
+	if n := counts[retargetUnknown]; n > 0 {
+		summary = append(summary, styleMuted.Render(fmt.Sprintf("%d unknown", n)))
+	}
+	if len(summary) == 0 {
+		summary = append(summary, styleMuted.Render("no package references"))
+	}
+	lines = append(lines, strings.Join(summary, styleMuted.Render(" · ")), "")
+
+	for _, r := range s.impacts {
+		name := r.Package + " " + r.Installed.String()
+		var line string
+		switch r.Verdict {
+		case retargetBlocked:
+			line = styleRed.Render("✗ "+name) + styleMuted.Render("  no version supports "+to.String())
+		case retargetUpdate:
+			line = styleYellow.Render("↑ "+name+" → "+r.LatestNew.SemVer.String()) + styleMuted.Render("  installed version doesn't support "+to.String())
+		case retargetUnknown:
+			line = styleMuted.Render("? " + name + "  versions not loaded")
+		default:
+			line = styleGreen.Render("✓ ") + styleText.Render(name)
+			if r.gainsVersion() {
+				line += styleMuted.Render("  latest ") + styleCyan.Render(r.LatestNew.SemVer.String()) + styleMuted.Render(" becomes available")
+			}
+		}
+		lines = append(lines, truncateStyled(line, innerW))
+	}
+	lines = append(lines, "", styleMuted.Render(wordWrap("w writes the new <TargetFramework> to "+p.FileName+"; packages are left as they are. Multi-targeting projects and frameworks set in Directory.Build.props are edited by hand.", innerW)))
+
+	maxH := m.overlayHeight() - 6
+	if maxH < 8 {
+		maxH = 8
+	}
+	s.vp.SetWidth(w - 4)
+	s.vp.SetHeight(maxH)
+	s.vp.SetContent(strings.Join(lines, "\n"))
+	s.vp.GotoTop()
+}
+
+func (s *retargetOverlay) Render() string {
+	box := styleOverlay.
+		Width(s.Width()).
+		Render(s.vp.View())
+	return s.centerOverlay(box)
+}
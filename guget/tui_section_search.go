@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 	"time"
@@ -10,6 +11,9 @@ import (
 )
 
 func (m *App) openSearch() bubble_tea.Cmd {
+	if cmd := m.readOnlyProjectStatus(m.selectedProject()); cmd != nil {
+		return cmd
+	}
 	m.search = packageSearch{
 		sectionBase: sectionBase{app: m, baseWidth: 90, minWidth: 56, maxMargin: 4},
 		input:       m.search.input,
@@ -20,7 +24,77 @@ func (m *App) openSearch() bubble_tea.Cmd {
 	return m.search.input.Focus()
 }
 
+// openSiblingSearch opens the search overlay on packages from the same
+// owners or authors as row, e.g. every Serilog sink from Serilog.
+func (m *App) openSiblingSearch(row packageRow) bubble_tea.Cmd {
+	if row.info == nil {
+		return nil
+	}
+	focus := m.openSearch()
+	if !m.search.active {
+		return focus
+	}
+	m.search.siblingOf = row.info.ID
+	m.search.loading = true
+
+	// Try the package's own source first: owners are per feed.
+	services := append([]*NugetService(nil), m.ctx.NugetServices...)
+	sort.SliceStable(services, func(i, j int) bool {
+		return strings.EqualFold(services[i].SourceName(), row.source) && !strings.EqualFold(services[j].SourceName(), row.source)
+	})
+	info := row.info
+	return bubble_tea.Batch(focus, func() bubble_tea.Msg {
+		var owners []string
+		for _, svc := range services {
+			found, err := svc.Owners(info.ID)
+			if err != nil {
+				logDebug("[%s] owner lookup for %s failed: %v", svc.SourceName(), info.ID, err)
+				continue
+			}
+			if len(found) > 0 {
+				owners = found
+				break
+			}
+		}
+		return siblingQueriesMsg{id: info.ID, queries: info.SiblingQueries(owners)}
+	})
+}
+
+// applySiblingQueries fills in the owner/author queries once they arrive and
+// runs the first one.
+func (s *packageSearch) applySiblingQueries(msg siblingQueriesMsg) bubble_tea.Cmd {
+	if !s.active || !strings.EqualFold(s.siblingOf, msg.id) {
+		return nil
+	}
+	s.siblingQueries = msg.queries
+	if s.input.Value() != "" {
+		return nil // the user started typing their own query meanwhile
+	}
+	if len(msg.queries) == 0 {
+		s.loading = false
+		s.err = fmt.Errorf("no owner or author is known for %s", msg.id)
+		return nil
+	}
+	return s.runSiblingQuery(0)
+}
+
+func (s *packageSearch) runSiblingQuery(i int) bubble_tea.Cmd {
+	s.siblingQuery = i
+	q := s.siblingQueries[i]
+	s.input.SetValue(q)
+	s.input.CursorEnd()
+	s.debounceID++ // drop any search typed before this one
+	s.lastQuery = q
+	s.loading = true
+	s.err = nil
+	s.cursor = 0
+	return s.doSearchCmd(q)
+}
+
 func (s *packageSearch) FooterKeys() []kv {
+	if len(s.siblingQueries) > 1 {
+		return []kv{{"↑↓", "nav"}, {"tab", "owner/author"}, {"enter", "select"}, {"esc", "close"}}
+	}
 	return []kv{{"↑↓", "nav"}, {"enter", "select"}, {"esc", "close"}}
 }
 
@@ -37,6 +111,16 @@ func (s *packageSearch) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 		s.input.Blur()
 		return nil
 
+	case "tab", "shift+tab":
+		if n := len(s.siblingQueries); n > 1 {
+			step := 1
+			if msg.String() == "shift+tab" {
+				step = n - 1
+			}
+			return s.runSiblingQuery((s.siblingQuery + step) % n)
+		}
+		return nil
+
 	case "up", "ctrl+p":
 		if s.cursor > 0 {
 			s.cursor--
@@ -54,8 +138,11 @@ func (s *packageSearch) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 			return nil
 		}
 		selected := s.results[s.cursor]
+		if s.replaceFor != "" && strings.EqualFold(selected.ID, s.replaceFor) {
+			return nil
+		}
 		// Check if already installed in this project
-		if proj := s.app.selectedProject(); proj != nil {
+		if proj := s.app.selectedProject(); proj != nil && s.replaceFor == "" {
 			for ref := range proj.Packages {
 				if strings.EqualFold(ref.Name, selected.ID) {
 					s.closeOverlay()
@@ -107,6 +194,9 @@ func (s *packageSearch) debounceCmd(query string) bubble_tea.Cmd {
 func (s *packageSearch) doSearchCmd(query string) bubble_tea.Cmd {
 	services := s.app.ctx.NugetServices
 	sourceMapping := s.app.ctx.SourceMapping
+	if scope := s.app.selectedSourceScope(); scope != nil {
+		services, sourceMapping = scope.Services, scope.Mapping
+	}
 	return func() bubble_tea.Msg {
 		type sourceResult struct {
 			results []SearchResult
@@ -172,6 +262,9 @@ func (s *packageSearch) doSearchCmd(query string) bubble_tea.Cmd {
 
 func (s *packageSearch) fetchPackageCmd(id string) bubble_tea.Cmd {
 	services := FilterServices(s.app.ctx.NugetServices, s.app.ctx.SourceMapping, id)
+	if scope := s.app.selectedSourceScope(); scope != nil {
+		services = FilterServices(scope.Services, scope.Mapping, id)
+	}
 	return func() bubble_tea.Msg {
 		var lastErr error
 		for _, svc := range services {
@@ -193,6 +286,9 @@ func (s *packageSearch) Render() string {
 
 	// Title row
 	title := styleAccentBold.Render("Add Package")
+	if s.replaceFor != "" {
+		title = styleAccentBold.Render("Replace " + s.replaceFor + " with…")
+	}
 	proj := s.app.selectedProject()
 	projName := ""
 	if proj != nil {
@@ -203,6 +299,13 @@ func (s *packageSearch) Render() string {
 
 	// Text input
 	lines = append(lines, s.input.View())
+	if s.siblingOf != "" {
+		hint := "Packages from the publisher of " + s.siblingOf
+		if n := len(s.siblingQueries); n > 1 {
+			hint += fmt.Sprintf("  (%d/%d, tab for next)", s.siblingQuery+1, n)
+		}
+		lines = append(lines, styleMuted.Render(truncate(hint, innerW)))
+	}
 
 	// Divider
 	lines = append(lines,
@@ -273,7 +376,7 @@ func (s *packageSearch) Render() string {
 			prefix := "  "
 			idStyle := styleText
 			if selected {
-				prefix = styleAccent.Render("▶ ")
+				prefix = styleAccent.Render(glyphs.Cursor)
 				idStyle = styleAccentBold
 			}
 
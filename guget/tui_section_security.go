@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+func newSecurityUpdate(m *App, project *ParsedProject) securityUpdate {
+	return securityUpdate{
+		sectionBase: sectionBase{app: m, baseWidth: 64, minWidth: 44, maxMargin: 4, active: true},
+		project:     project,
+	}
+}
+
+func (m *App) openSecurityUpdate() bubble_tea.Cmd {
+	if cmd := m.readOnlyUpdateStatus(m.selectedProject()); cmd != nil {
+		return cmd
+	}
+	m.ctx.StatusLine = ""
+	m.security = newSecurityUpdate(m, m.selectedProject())
+	if len(m.security.candidates()) == 0 {
+		m.security.closeOverlay()
+		return m.setStatus("✓ No vulnerable packages in this view", false)
+	}
+	return nil
+}
+
+// candidates returns the vulnerable rows in the current view that meet the
+// severity threshold.
+func (s *securityUpdate) candidates() []packageRow {
+	var rows []packageRow
+	for _, row := range s.app.packages.rows {
+		if row.vulnerable && row.maxSeverity() >= int(s.threshold) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func (s *securityUpdate) FooterKeys() []kv {
+	return []kv{{"tab", "severity"}, {"enter/y", "apply"}, {"esc", "cancel"}}
+}
+
+func (s *securityUpdate) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "tab", "left", "right", "h", "l":
+		if s.threshold == severityAny {
+			s.threshold = severityHighPlus
+		} else {
+			s.threshold = severityAny
+		}
+	case "esc", "n", "q":
+		s.closeOverlay()
+	case "enter", "y":
+		s.closeOverlay()
+		n := 0
+		for _, row := range s.candidates() {
+			if row.minFixed != nil && row.heldBack(row.minFixed.SemVer) == "" {
+				n++
+			}
+		}
+		return s.app.guardBulk("update", n, fmt.Sprintf("Apply security fixes to %d packages?", n), s.apply)
+	}
+	return nil
+}
+
+// apply moves every candidate with a known fix to its minimum fixed
+// version. Locked references and those held back by a rule are left alone.
+// Writes run one after another
+// since several packages may live in the same file.
+func (s *securityUpdate) apply() bubble_tea.Cmd {
+	var cmds []bubble_tea.Cmd
+	updated, skipped := 0, 0
+	for _, row := range s.candidates() {
+		if row.minFixed == nil || row.heldBack(row.minFixed.SemVer) != "" {
+			skipped++
+			continue
+		}
+		if cmd := s.app.applyVersion(row.ref.Name, row.minFixed.SemVer.String(), s.project); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		updated++
+	}
+	logInfo("securityUpdate: %d package(s) updated, %d skipped", updated, skipped)
+	if updated == 0 {
+		return s.app.setStatus("✗ No vulnerable package has a compatible fix", true)
+	}
+	return bubble_tea.Sequence(cmds...)
+}
+
+func (s *securityUpdate) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	tab := func(label string, on bool) string {
+		if on {
+			return styleAccentBold.Render("[" + label + "]")
+		}
+		return styleMuted.Render(" " + label + " ")
+	}
+	lines := []string{
+		styleRedBold.Render("Security update"),
+		tab("all vulnerable", s.threshold == severityAny) + " " + tab("critical/high", s.threshold == severityHighPlus),
+		styleBorder.Render(strings.Repeat("─", inner)),
+	}
+
+	rows := s.candidates()
+	if len(rows) == 0 {
+		lines = append(lines, styleMuted.Render("No packages at this severity"))
+	}
+	pending := 0
+	for _, row := range rows {
+		sev := PackageVulnerability{Severity: IntOrString(row.maxSeverity())}.SeverityLabel()
+		var change string
+		switch {
+		case row.minFixed == nil:
+			change = styleMuted.Render("no fix")
+		case row.heldBack(row.minFixed.SemVer) != "":
+			change = styleMuted.Render(row.heldBack(row.minFixed.SemVer))
+		default:
+			change = styleText.Render(row.effectiveVersion().String()) + styleMuted.Render(" → ") + styleGreen.Render(row.minFixed.SemVer.String())
+			pending++
+		}
+		left := severityStyle(sev).Render(padRight(sev, 9)) + styleText.Render(row.ref.Name)
+		gap := inner - lipgloss.Width(left) - lipgloss.Width(change)
+		if gap < 1 {
+			left = severityStyle(sev).Render(padRight(sev, 9)) + styleText.Render(truncate(row.ref.Name, inner-9-lipgloss.Width(change)-1))
+			gap = inner - lipgloss.Width(left) - lipgloss.Width(change)
+		}
+		lines = append(lines, left+strings.Repeat(" ", max(gap, 1))+change)
+	}
+
+	scope := "this project"
+	if s.project == nil {
+		scope = "all projects"
+	}
+	lines = append(lines, "", styleMuted.Render(fmt.Sprintf("Update %d package(s) to their minimum fixed version in %s?", pending, scope)))
+
+	box := styleOverlayDanger.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
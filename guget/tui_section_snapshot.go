@@ -0,0 +1,54 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// openSnapshotDiff shows what changed between the newest saved snapshot and
+// the workspace as currently loaded.
+func (m *App) openSnapshotDiff() bubble_tea.Cmd {
+	path, err := latestSnapshotPath(m.projectDir)
+	if err != nil {
+		return m.setStatus("▲ No snapshots yet (run guget snapshot)", true)
+	}
+	from, err := loadSnapshot(path)
+	if err != nil {
+		return m.setStatus("▲ "+err.Error(), true)
+	}
+	m.ctx.StatusLine = ""
+	to := snapshotFromProjects(m.projectDir, m.ctx.ParsedProjects)
+	m.depTree = newDepTreeOverlay(m, "Changes since snapshot "+strings.TrimSuffix(filepath.Base(path), ".json"), false)
+	m.depTree.content = renderSnapshotDiff(diffSnapshots(from, to))
+	m.depTree.vp.SetContent(m.depTree.buildContent())
+	return nil
+}
+
+func renderSnapshotDiff(changes []SnapshotChange) string {
+	if len(changes) == 0 {
+		return styleMuted.Render("No dependency changes.")
+	}
+	var lines []string
+	project := ""
+	for _, c := range changes {
+		if c.Project != project {
+			if project != "" {
+				lines = append(lines, "")
+			}
+			project = c.Project
+			lines = append(lines, styleSubtleBold.Render(project))
+		}
+		switch {
+		case c.Old == "":
+			lines = append(lines, styleGreen.Render("  + ")+styleText.Render(c.Package)+" "+styleGreen.Render(c.New))
+		case c.New == "":
+			lines = append(lines, styleRed.Render("  - ")+styleText.Render(c.Package)+" "+styleRed.Render(c.Old))
+		default:
+			lines = append(lines, styleYellow.Render("  ~ ")+styleText.Render(c.Package)+" "+
+				styleMuted.Render(c.Old+" → ")+styleYellow.Render(c.New))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
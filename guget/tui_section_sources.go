@@ -1,16 +1,129 @@
 package main
 
 import (
+	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	bubble_tea "charm.land/bubbletea/v2"
 )
 
+func (s *sourcesOverlay) migrations() []sourceMigration {
+	return planSourceMigrations(s.app.ctx.Sources, s.app.ctx.NugetServices)
+}
+
+// selectedSourceScope is the source set of the selected project, or nil for
+// "All Projects" and .props files.
+func (m *App) selectedSourceScope() *sourceScope {
+	if p := m.selectedProject(); p != nil {
+		return m.ctx.SourceScopes.scopeFor(p.FilePath)
+	}
+	return nil
+}
+
+// serviceFor returns the service connected to src, or nil.
+func (m *App) serviceFor(src NugetSource) *NugetService {
+	for _, svc := range m.ctx.NugetServices {
+		if sameSource(NugetSource{Name: svc.sourceName, URL: svc.sourceURL}, src) {
+			return svc
+		}
+	}
+	return nil
+}
+
+// sourceFailureFor returns why src failed to initialise, or nil when it did
+// not.
+func (m *App) sourceFailureFor(src NugetSource) *sourceFailure {
+	for i, f := range m.ctx.SourceFailures {
+		if sameSource(f.Source, src) {
+			return &m.ctx.SourceFailures[i]
+		}
+	}
+	return nil
+}
+
+// retry connects again to the source under the cursor when it failed to
+// initialise.
+func (s *sourcesOverlay) retry() bubble_tea.Cmd {
+	if s.cursor >= len(s.app.ctx.Sources) || s.retrying != "" {
+		return nil
+	}
+	src := s.app.ctx.Sources[s.cursor]
+	if s.app.sourceFailureFor(src) == nil {
+		return s.app.setStatus("["+src.Name+"] is connected; only sources that failed to initialise can be retried", true)
+	}
+	s.retrying = src.Name
+	logInfo("Connecting to NuGet source [%s] again", src.Name)
+	return func() bubble_tea.Msg {
+		svc, err := NewNugetService(src)
+		return sourceRetriedMsg{source: src, svc: svc, err: err}
+	}
+}
+
+// finishSourceRetry adds a source that now initialises to every scope that
+// lists it and fetches the packages it may serve again.
+func (m *App) finishSourceRetry(msg sourceRetriedMsg) bubble_tea.Cmd {
+	m.sources.retrying = ""
+	failure := m.sourceFailureFor(msg.source)
+	if failure == nil {
+		return nil // the workspace was reloaded meanwhile
+	}
+	if msg.err != nil {
+		failure.Err = msg.err
+		logWarn("Failed to initialise NuGet source [%s]: %v", msg.source.Name, msg.err)
+		return m.setStatus(fmt.Sprintf("✗ [%s] still fails: %v", msg.source.Name, msg.err), true)
+	}
+	m.ctx.SourceFailures = slices.DeleteFunc(m.ctx.SourceFailures, func(f sourceFailure) bool { return sameSource(f.Source, msg.source) })
+	m.ctx.NugetServices = append(m.ctx.NugetServices, msg.svc)
+	DeduplicateADOUpstreams(m.ctx.NugetServices)
+	for i := range m.ctx.SourceScopes {
+		if m.ctx.SourceScopes[i].hasSource(msg.source) {
+			m.ctx.SourceScopes[i].Services = append(m.ctx.SourceScopes[i].Services, msg.svc)
+		}
+	}
+	logInfo("NuGet source [%s] initialised on retry", msg.source.Name)
+
+	var names []string
+	for name := range m.ctx.Results {
+		if slices.Contains(m.ctx.SourceScopes.servicesFor(name), msg.svc) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 || m.ctx.Loading || m.ctx.Reloading {
+		return m.setStatus(fmt.Sprintf("✓ Connected to [%s]", msg.source.Name), false)
+	}
+	sort.Strings(names)
+	m.refetchPackages(names, false)
+	return m.setStatus(fmt.Sprintf("✓ Connected to [%s]; reloading %d package(s) it serves", msg.source.Name, len(names)), false)
+}
+
 func (s *sourcesOverlay) FooterKeys() []kv {
-	return []kv{{"esc", "close"}}
+	if s.confirming {
+		return []kv{{"y", "update nuget.config"}, {"n/esc", "cancel"}}
+	}
+	keys := []kv{{"↑↓", "nav"}}
+	if s.cursor < len(s.app.ctx.Sources) && s.app.sourceFailureFor(s.app.ctx.Sources[s.cursor]) != nil {
+		keys = append(keys, kv{"r", "retry source"})
+	}
+	if len(s.migrations()) > 0 && !s.migrating {
+		keys = append(keys, kv{"m", "update moved URLs"})
+	}
+	return append(keys, kv{"esc", "close"})
 }
 
 func (s *sourcesOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	if s.confirming {
+		switch msg.String() {
+		case "y", "enter":
+			s.confirming = false
+			s.migrating = true
+			return migrateSourcesCmd(s.migrations())
+		case "n", "esc", "q":
+			s.confirming = false
+		}
+		return nil
+	}
 	switch msg.String() {
 	case "[":
 		s.Resize(-4)
@@ -18,10 +131,77 @@ func (s *sourcesOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
 		s.Resize(4)
 	case "esc", "s", "q":
 		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.app.ctx.Sources)-1 {
+			s.cursor++
+		}
+	case "r":
+		return s.retry()
+	case "m":
+		if cmd := s.app.readOnlySessionStatus(); cmd != nil {
+			return cmd
+		}
+		if len(s.migrations()) > 0 && !s.migrating {
+			s.confirming = true
+		}
 	}
 	return nil
 }
 
+// migrateSourcesCmd rewrites every migration's nuget.config entry, or none
+// of them if one fails.
+func migrateSourcesCmd(migrations []sourceMigration) bubble_tea.Cmd {
+	return func() bubble_tea.Msg {
+		if err := applySourceMigrations(migrations); err != nil {
+			return sourcesMigratedMsg{err: err}
+		}
+		return sourcesMigratedMsg{migrated: len(migrations)}
+	}
+}
+
+// healthLines shows how src has been answering: reachability and latency,
+// protocol, credentials and lookup failures, or why it failed to initialise.
+func (s *sourcesOverlay) healthLines(src NugetSource, width int) []string {
+	if f := s.app.sourceFailureFor(src); f != nil {
+		lines := []string{"    " + styleRed.Render(truncate(glyphs.Fail+" failed to initialise: "+f.Err.Error(), width))}
+		if s.retrying == src.Name {
+			return append(lines, "    "+styleMuted.Render("connecting…"))
+		}
+		return append(lines, "    "+styleMuted.Render("left out of lookups · r retries"))
+	}
+	svc := s.app.serviceFor(src)
+	if svc == nil {
+		return nil
+	}
+	st := svc.Stats()
+	reach, ok := st.reachability()
+	reach = truncate(reach, max(12, width/2))
+	reachStyled := styleGreen.Render(glyphs.Dot + " " + reach)
+	if !ok {
+		reachStyled = styleRed.Render(glyphs.Fail + " " + reach)
+	}
+	auth, authOK := st.authState(svc.CredentialOrigin())
+	authStyle := styleMuted
+	if !authOK {
+		authStyle = styleRed
+	}
+	lines := []string{"    " + reachStyled + styleMuted.Render(" · "+svc.Protocol()+" · ") + authStyle.Render(auth)}
+	if st.Lookups > 0 {
+		lookups := fmt.Sprintf("%d lookup(s)", st.Lookups)
+		if st.FailedLookups == 0 {
+			lines = append(lines, "    "+styleMuted.Render(lookups+", none failed"))
+		} else {
+			failed := fmt.Sprintf("%s, %d failed: %v", lookups, st.FailedLookups, st.LookupErr)
+			lines = append(lines, "    "+styleYellow.Render(truncate(failed, width)))
+		}
+	}
+	return lines
+}
+
 func (s *sourcesOverlay) Render() string {
 	w := s.Width()
 	innerW := w - 6 // border (2) + padding (2*2)
@@ -34,26 +214,119 @@ func (s *sourcesOverlay) Render() string {
 		styleBorder.Render(strings.Repeat("─", innerW)),
 	)
 
+	// With nested nuget.config files, say which chain the selected project uses.
+	scope := s.app.selectedSourceScope()
+	if len(s.app.ctx.SourceScopes) > 1 {
+		if p := s.app.selectedProject(); scope != nil && p != nil {
+			lines = append(lines, styleSubtleBold.Render(truncate("Source set for "+p.FileName, innerW)))
+			if len(scope.Configs) == 0 {
+				lines = append(lines, "  "+styleMuted.Render("no nuget.config; using nuget.org"))
+			}
+			for _, cfg := range scope.Configs {
+				lines = append(lines, "  "+styleMuted.Render(truncate(cfg, innerW-2)))
+			}
+		} else {
+			scope = nil
+			lines = append(lines, styleMuted.Render(wordWrap(fmt.Sprintf(
+				"Projects resolve %d different nuget.config chains. Select a project to see which sources it uses.",
+				len(s.app.ctx.SourceScopes)), innerW)))
+		}
+		lines = append(lines, "")
+	}
+
 	if len(s.app.ctx.Sources) == 0 {
 		lines = append(lines,
 			styleMuted.Render("No sources detected"),
 		)
 	} else {
-		for _, src := range s.app.ctx.Sources {
-			nameStyle := styleTextBold
-			name := nameStyle.Render(truncate(src.Name, innerW-18))
+		moved := make(map[string]sourceMigration)
+		for _, mg := range s.migrations() {
+			moved[mg.Name] = mg
+		}
+		for i, src := range s.app.ctx.Sources {
+			prefix, nameStyle := "  ", styleTextBold
+			if i == s.cursor {
+				prefix, nameStyle = styleAccentBold.Render(glyphs.Cursor), styleAccentBold
+			}
+			unused := scope != nil && !scope.hasSource(src)
+			if unused {
+				nameStyle = styleMuted
+			}
+			name := nameStyle.Render(truncate(src.Name, innerW-20))
+			if unused {
+				name += "  " + styleMuted.Render("other projects only")
+			}
 			auth := ""
 			if src.Username != "" {
 				auth = "  " + styleMuted.Render("🔒 "+src.Username)
 			}
-			lines = append(lines, name+auth)
+			lines = append(lines, prefix+name+auth)
 			lines = append(lines,
-				"  "+hyperlink(src.URL, styleSubtle.Render(truncate(src.URL, innerW-2))),
+				"    "+hyperlink(src.URL, styleSubtle.Render(truncate(src.URL, innerW-4))),
 			)
+			lines = append(lines, s.healthLines(src, innerW-4)...)
+			if mg, ok := moved[src.Name]; ok {
+				lines = append(lines,
+					"    "+styleYellow.Render(truncate("→ "+mg.To, innerW-4)),
+					"    "+styleMuted.Render(truncate(mg.Reason, innerW-4)),
+				)
+			}
 			lines = append(lines, "")
 		}
 	}
 
+	if conflicts := s.app.ctx.SourceConflicts; len(conflicts) > 0 {
+		lines = append(lines, styleYellowBold.Render("Conflicting definitions"))
+		lines = append(lines, styleBorder.Render(strings.Repeat("─", innerW)))
+		for _, c := range conflicts {
+			title := "same URL, different names"
+			if c.SameName {
+				title = "same name, different URLs"
+			}
+			lines = append(lines,
+				styleTextBold.Render(truncate(c.Kept.Name, innerW-30))+"  "+styleYellow.Render("⚠ "+title),
+				styleMuted.Render(wordWrap(c.Explain(), innerW)),
+				"",
+			)
+		}
+	}
+
+	folders := s.app.ctx.PackageFolders
+	if folders.Global != "" || len(folders.Fallback) > 0 {
+		lines = append(lines, styleAccentBold.Render("Package Folders"))
+		lines = append(lines, styleBorder.Render(strings.Repeat("─", innerW)))
+		if folders.Global != "" {
+			lines = append(lines, styleTextBold.Render("global"))
+			lines = append(lines, "  "+styleSubtle.Render(truncate(folders.Global, innerW-2)))
+		}
+		for _, f := range folders.Fallback {
+			lines = append(lines, styleTextBold.Render("fallback"))
+			lines = append(lines, "  "+styleSubtle.Render(truncate(f, innerW-2)))
+		}
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, styleAccentBold.Render(".NET SDKs"))
+	lines = append(lines, styleBorder.Render(strings.Repeat("─", innerW)))
+	if s.app.ctx.SDKErr != nil {
+		lines = append(lines, styleRed.Render(truncate(s.app.ctx.SDKErr.Error(), innerW)))
+	}
+	for _, sdk := range s.app.ctx.SDKs {
+		channel := fmt.Sprintf("%d.%d", sdk.Version.Major, sdk.Version.Minor)
+		ver := styleTextBold.Render(sdk.Version.String())
+		if sdk.Version.IsPreRelease() {
+			ver += " " + styleYellow.Render("preview")
+		}
+		lines = append(lines, ver+"  "+styleMuted.Render("channel "+channel))
+	}
+
+	switch {
+	case s.confirming:
+		lines = append(lines, "", styleYellowBold.Render("Rewrite the moved source URLs in nuget.config? ")+styleMuted.Render("y / n"))
+	case s.migrating:
+		lines = append(lines, "", styleMuted.Render("Updating nuget.config..."))
+	}
+
 	box := styleOverlay.
 		Width(w).
 		Render(strings.Join(lines, "\n"))
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// runTransitiveScanCmd asks dotnet for the vulnerable packages of each
+// project, transitive ones included, and traces each back to the direct
+// dependency that pulls it in.
+func runTransitiveScanCmd(projects []*ParsedProject) bubble_tea.Cmd {
+	return func() bubble_tea.Msg {
+		results := make(map[string]transitiveScan, len(projects))
+		for _, p := range projects {
+			results[p.FilePath] = scanTransitive(p)
+		}
+		return transitiveScanMsg{results: results}
+	}
+}
+
+func scanTransitive(p *ParsedProject) transitiveScan {
+	out, err := exec.Command("dotnet", "list", p.FilePath, "package", "--vulnerable", "--include-transitive", "--format", "json").Output()
+	parsed, parseErr := parseVulnerableList(out)
+	if parseErr != nil {
+		// dotnet reports problems such as a missing restore in the JSON
+		// itself, so its exit status only matters when that didn't parse.
+		if err != nil {
+			parseErr = fmt.Errorf("dotnet list: %w", err)
+		}
+		return transitiveScan{err: parseErr}
+	}
+	var scan transitiveScan
+	for _, vulns := range parsed {
+		scan.vulns = append(scan.vulns, vulns...)
+	}
+	if graph := readRestoreGraph(filepath.Dir(p.FilePath)); graph != nil {
+		for i := range scan.vulns {
+			scan.vulns[i].Path = graph.pathTo(scan.vulns[i].ID)
+		}
+	}
+	return scan
+}
+
+// openTransitiveScan scans every project for vulnerable transitive
+// dependencies (V).
+func (m *App) openTransitiveScan() bubble_tea.Cmd {
+	var projects []*ParsedProject
+	for _, p := range m.ctx.ParsedProjects {
+		if p.LoadErr == nil {
+			projects = append(projects, p)
+		}
+	}
+	if len(projects) == 0 {
+		return m.setStatus("▲ No projects to scan", true)
+	}
+	if cmd := m.sdkStatus(projects); cmd != nil {
+		return cmd
+	}
+	m.ctx.StatusLine = ""
+	m.transitive = transitiveOverlay{
+		sectionBase: sectionBase{app: m, basePct: 70, minWidth: 56, maxMargin: 4, active: true},
+		loading:     true,
+	}
+	return runTransitiveScanCmd(projects)
+}
+
+func (m *App) applyTransitiveScan(msg transitiveScanMsg) {
+	m.ctx.TransitiveVulns = msg.results
+	m.transitive.loading = false
+	m.transitive.findings = nil
+	m.transitive.failed = nil
+	for _, p := range m.ctx.ParsedProjects {
+		scan, ok := msg.results[p.FilePath]
+		if !ok {
+			continue
+		}
+		if scan.err != nil {
+			m.transitive.failed = append(m.transitive.failed, fmt.Sprintf("%s: %v", p.FileName, scan.err))
+		}
+		for _, v := range scan.vulns {
+			m.transitive.findings = append(m.transitive.findings, transitiveFinding{project: p, vuln: v})
+		}
+	}
+	m.transitive.cursor = 0
+	m.refreshDetail()
+}
+
+// transitiveVulnCount is how many vulnerable transitive packages the last
+// scan found in p; nil sums every project.
+func (m *App) transitiveVulnCount(p *ParsedProject) int {
+	if p != nil {
+		return len(m.ctx.TransitiveVulns[p.FilePath].vulns)
+	}
+	n := 0
+	for _, scan := range m.ctx.TransitiveVulns {
+		n += len(scan.vulns)
+	}
+	return n
+}
+
+// transitiveSuggestion names the direct update that clears v, as far as the
+// registration data of the direct package shows.
+func (m *App) transitiveSuggestion(v transitiveVuln) string {
+	if len(v.Path) < 2 {
+		return "restore the project to trace which direct package pulls it in"
+	}
+	direct := v.Path[0]
+	res := m.ctx.Results[direct.ID]
+	if res.pkg == nil {
+		for name, r := range m.ctx.Results {
+			if strings.EqualFold(name, direct.ID) {
+				res = r
+			}
+		}
+	}
+	if bump := transitiveBump(v.Path, res.pkg); bump != nil {
+		return fmt.Sprintf("update %s to %s (raises %s)", direct.ID, bump.SemVer.String(), v.Path[1].ID)
+	}
+	if res.pkg == nil {
+		return fmt.Sprintf("update %s, or reference %s directly at a fixed version", direct.ID, v.ID)
+	}
+	return fmt.Sprintf("no %s release raises %s yet; reference %s directly at a fixed version", direct.ID, v.Path[1].ID, v.ID)
+}
+
+// renderDetailTransitive lists the vulnerable packages the selected direct
+// dependency pulls into the selected project, from the last V scan.
+func (m *App) renderDetailTransitive(row packageRow, w int) string {
+	var found []transitiveVuln
+	sel := m.selectedProject()
+	for _, p := range m.ctx.ParsedProjects {
+		if sel != nil && sel != p {
+			continue
+		}
+		for _, v := range m.ctx.TransitiveVulns[p.FilePath].vulns {
+			if len(v.Path) > 1 && strings.EqualFold(v.Path[0].ID, row.ref.Name) {
+				found = append(found, v)
+			}
+		}
+	}
+	if len(found) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(styleRedBold.Render("Vulnerable transitive dependencies") + "\n")
+	seen := NewSet[string]()
+	for _, v := range found {
+		if key := v.ID + "/" + v.Version; !seen.Contains(key) {
+			seen.Add(key)
+			sev := v.SeverityLabel()
+			s.WriteString(severityStyle(sev).Render(padRight(sev, 9)) + styleText.Render(truncate(v.ID+" "+v.Version, w-9)) + "\n")
+		}
+	}
+	s.WriteString(styleMuted.Render(wordWrap(m.transitiveSuggestion(found[0]), w)) + "\n\n")
+	return s.String()
+}
+
+func (s *transitiveOverlay) FooterKeys() []kv {
+	return []kv{{"↑↓", "nav"}, {"enter", "go to direct package"}, {"esc", "close"}}
+}
+
+func (s *transitiveOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q", "V":
+		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.findings)-1 {
+			s.cursor++
+		}
+	case "enter":
+		if s.cursor < len(s.findings) && len(s.findings[s.cursor].vuln.Path) > 0 {
+			f := s.findings[s.cursor]
+			s.closeOverlay()
+			m := s.app
+			m.selectProjectByPath(f.project.FilePath)
+			m.rebuildPackageRows()
+			m.selectPackageByName(f.vuln.Path[0].ID)
+			m.focus = focusPackages
+			m.refreshDetail()
+		}
+	}
+	return nil
+}
+
+func (s *transitiveOverlay) Render() string {
+	w := s.Width()
+	inner := w - 6
+
+	var lines []string
+	switch {
+	case s.loading:
+		lines = append(lines, s.app.ctx.Spinner.View()+" "+styleAccent.Render("Scanning transitive dependencies with dotnet list…"))
+	case len(s.findings) == 0:
+		lines = append(lines,
+			styleGreen.Render("✓ No vulnerable transitive dependencies"),
+			styleBorder.Render(strings.Repeat("─", inner)),
+		)
+	default:
+		lines = append(lines,
+			styleRedBold.Render(fmt.Sprintf("%d vulnerable transitive package(s)", len(s.findings))),
+			styleBorder.Render(strings.Repeat("─", inner)),
+		)
+	}
+
+	// Each finding takes four lines: package, path, suggestion, gap.
+	maxItems := max(1, (s.app.overlayHeight()-10)/4)
+	start := 0
+	if s.cursor >= maxItems {
+		start = s.cursor - maxItems + 1
+	}
+	end := min(len(s.findings), start+maxItems)
+	for i := start; i < end; i++ {
+		f := s.findings[i]
+		prefix := "  "
+		nameStyle := styleText
+		if i == s.cursor {
+			prefix = styleAccentBold.Render(glyphs.Cursor)
+			nameStyle = styleAccentBold
+		}
+		sev := f.vuln.SeverityLabel()
+		title := severityStyle(sev).Render(padRight(sev, 9)) +
+			nameStyle.Render(f.vuln.ID+" "+f.vuln.Version) + "  " +
+			styleMuted.Render(f.project.FileName+" · "+f.vuln.Framework)
+		path := "path unknown"
+		if len(f.vuln.Path) > 0 {
+			parts := make([]string, len(f.vuln.Path))
+			for j, p := range f.vuln.Path {
+				parts[j] = p.String()
+			}
+			path = strings.Join(parts, " → ")
+		}
+		lines = append(lines,
+			prefix+truncateStyled(title, inner-2),
+			"    "+styleSubtle.Render(truncate(path, inner-4)),
+			"    "+styleYellow.Render(truncate(s.app.transitiveSuggestion(f.vuln), inner-4)),
+			"",
+		)
+	}
+	if end < len(s.findings) {
+		lines = append(lines, styleMuted.Render(fmt.Sprintf("  … %d more", len(s.findings)-end)), "")
+	}
+	for _, f := range s.failed {
+		lines = append(lines, styleRed.Render(truncate("✗ "+f, inner)))
+	}
+	if !s.loading {
+		lines = append(lines, styleMuted.Render(wordWrap("Direct references are flagged in the package list as usual; this scan covers the packages they pull in.", inner)))
+	}
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
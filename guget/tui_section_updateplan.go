@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	bubble_tea "charm.land/bubbletea/v2"
+)
+
+// openUpdatePlan previews updating targets across projects.
+func (m *App) openUpdatePlan(title string, projects []*ParsedProject, targets []updateTarget) bubble_tea.Cmd {
+	rows := buildUpdatePlan(projects, targets)
+	if len(rows) == 0 {
+		return m.setStatus("✓ Nothing to update", false)
+	}
+	m.updatePlan = updatePlanOverlay{
+		sectionBase: sectionBase{app: m, basePct: 85, minWidth: 60, maxMargin: 4, active: true},
+		title:       title,
+		rows:        rows,
+	}
+	m.ctx.StatusLine = ""
+	return nil
+}
+
+// updateAllInView plans moving every outdated package in the packages panel
+// to its latest compatible version.
+func (m *App) updateAllInView() bubble_tea.Cmd {
+	if cmd := m.readOnlyUpdateStatus(m.selectedProject()); cmd != nil {
+		return cmd
+	}
+	var targets []updateTarget
+	for _, row := range m.packages.rows {
+		if row.info == nil || row.latestCompatible == nil || !row.latestCompatible.SemVer.IsNewerThan(row.effectiveVersion()) {
+			continue
+		}
+		if row.rule.Pin || row.rule.NoBulk {
+			continue
+		}
+		targets = append(targets, updateTarget{Package: row.ref.Name, Version: row.latestCompatible.SemVer.String(), Info: row.info})
+	}
+	if len(targets) == 0 {
+		return m.setStatus("✓ Everything in view is up to date", false)
+	}
+	projects := m.ctx.ParsedProjects
+	title := "Update all outdated packages"
+	if p := m.selectedProject(); p != nil {
+		projects = []*ParsedProject{p}
+		title += " in " + p.FileName
+	}
+	return m.openUpdatePlan(title, projects, targets)
+}
+
+func (s *updatePlanOverlay) FooterKeys() []kv {
+	return []kv{{"↑↓", "nav"}, {"space", "toggle"}, {"a", "all/none"}, {"enter", "apply"}, {"esc", "cancel"}}
+}
+
+func (s *updatePlanOverlay) HandleKey(msg bubble_tea.KeyMsg) bubble_tea.Cmd {
+	switch msg.String() {
+	case "[":
+		s.Resize(-4)
+	case "]":
+		s.Resize(4)
+	case "esc", "q":
+		s.closeOverlay()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.rows)-1 {
+			s.cursor++
+		}
+	case "space":
+		toggleUpdateRow(s.rows, s.cursor)
+	case "a":
+		// Select everything unless everything is already selected.
+		on := false
+		for _, r := range s.rows {
+			if r.Skip == "" && !r.Selected {
+				on = true
+			}
+		}
+		for i := range s.rows {
+			if s.rows[i].Skip == "" {
+				s.rows[i].Selected = on
+			}
+		}
+	case "enter", "y":
+		edits := updateEdits(s.rows)
+		if len(edits) == 0 {
+			return s.app.setStatus("✗ No rows selected", true)
+		}
+		s.closeOverlay()
+		return s.app.guardBulk("update", len(edits), fmt.Sprintf("Apply %d updates?", len(edits)), func() bubble_tea.Cmd {
+			return s.app.applyUpdateEdits(edits)
+		})
+	}
+	return nil
+}
+
+// applyUpdateEdits writes each edit in turn; they run one after another
+// since several may touch the same file.
+func (m *App) applyUpdateEdits(edits []updatePlanRow) bubble_tea.Cmd {
+	var cmds []bubble_tea.Cmd
+	for _, e := range edits {
+		if cmd := m.applyVersion(e.Package, e.To, e.Project); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	logInfo("updatePlan: %d edit(s) applied", len(edits))
+	return bubble_tea.Sequence(cmds...)
+}
+
+func (s *updatePlanOverlay) Render() string {
+	w := s.Width()
+	innerW := w - 6 // border (2) + padding (2*2)
+
+	selected, files := 0, NewSet[string]()
+	for _, r := range s.rows {
+		if r.Selected {
+			selected++
+			files.Add(r.File)
+		}
+	}
+	lines := []string{
+		styleAccentBold.Render(s.title),
+		styleMuted.Render(fmt.Sprintf("%d of %d selected · %d file(s) will be modified", selected, len(s.rows), files.Len())),
+		styleBorder.Render(strings.Repeat("─", innerW)),
+	}
+
+	// Columns: cursor(2) + box(4) + package + project + change + file
+	colChange := 0
+	for _, r := range s.rows {
+		colChange = max(colChange, len(r.From)+len(r.written())+3)
+	}
+	colChange += 2
+	rest := max(innerW-6-colChange, 30)
+	colPkg := rest * 2 / 5
+	colProj := rest / 4
+	colFile := rest - colPkg - colProj
+
+	maxVisible := max(s.app.overlayHeight()-12, 5)
+	start := 0
+	if s.cursor >= maxVisible {
+		start = s.cursor - maxVisible + 1
+	}
+	end := min(start+maxVisible, len(s.rows))
+	for i := start; i < end; i++ {
+		r := s.rows[i]
+		prefix := "  "
+		nameStyle := styleText
+		if i == s.cursor {
+			prefix = styleAccent.Render(glyphs.Cursor)
+			nameStyle = styleAccentBold
+		}
+		box := styleMuted.Render("[ ] ")
+		if r.Selected {
+			box = styleGreen.Render("[x] ")
+		}
+		var change string
+		if r.Skip != "" {
+			change = styleMuted.Render(r.Skip)
+		} else {
+			change = styleSubtle.Render(r.From) + styleMuted.Render(" → ") + styleGreen.Render(r.written())
+		}
+		file := filepath.Base(r.File)
+		fileStyle := styleMuted
+		if r.File != r.Project.FilePath {
+			fileStyle = styleCyan // shared .props: one edit for every project importing it
+		}
+		line := prefix + box +
+			padRight(nameStyle.Render(truncate(r.Package, colPkg-1)), colPkg) +
+			padRight(styleSubtle.Render(truncate(r.Project.FileName, colProj-1)), colProj) +
+			padRight(change, colChange) +
+			fileStyle.Render(truncate(file, colFile))
+		lines = append(lines, line)
+		if r.LicenseTo != "" {
+			note := "license " + r.LicenseFrom + " → " + r.LicenseTo
+			lines = append(lines, strings.Repeat(" ", 6)+styleYellow.Render(truncate(note, innerW-6)))
+		}
+	}
+	if end < len(s.rows) {
+		lines = append(lines, styleMuted.Render(fmt.Sprintf("… %d more", len(s.rows)-end)))
+	}
+	if r := s.rows[s.cursor]; r.File != r.Project.FilePath {
+		lines = append(lines, "", styleMuted.Render(wordWrap(filepath.Base(r.File)+" is shared: toggling this row toggles every project it applies to.", innerW)))
+	}
+
+	box := styleOverlay.
+		Width(w).
+		Render(strings.Join(lines, "\n"))
+	return s.centerOverlay(box)
+}
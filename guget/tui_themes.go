@@ -209,7 +209,7 @@ var themes = map[string]Theme{
 // Call this before NewApp. If noColor is true, all color output is disabled.
 func initTheme(name string, noColor bool) {
 	if noColor {
-		hyperlinkEnabled = false
+		termCaps.Hyperlinks = false
 		// In lipgloss v2, color downsampling is handled by bubbletea.
 		// Setting all colors to NoColor effectively disables color output.
 		nc := lipgloss.NoColor{}
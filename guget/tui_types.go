@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	bubbles_textinpute "charm.land/bubbles/v2/textinput"
 	bubbles_viewport "charm.land/bubbles/v2/viewport"
+	bubble_tea "charm.land/bubbletea/v2"
 	lipgloss "charm.land/lipgloss/v2"
 )
 
@@ -63,6 +66,48 @@ func (s packageSortMode) next() packageSortMode {
 	return (s + 1) % 5
 }
 
+// packageView narrows the package list to one kind of package, for triage.
+type packageView int
+
+const (
+	viewAll        packageView = iota
+	viewVulnerable             // installed version has a known vulnerability
+	viewOutdated               // a newer version is available
+	viewDeprecated             // deprecated in the registry
+	viewSource                 // resolved from packagePanel.viewSource
+)
+
+func (v packageView) label(source string) string {
+	switch v {
+	case viewVulnerable:
+		return "vulnerable"
+	case viewOutdated:
+		return "outdated"
+	case viewDeprecated:
+		return "deprecated"
+	case viewSource:
+		return "source: " + source
+	default:
+		return "all"
+	}
+}
+
+// match reports whether row belongs in the view.
+func (v packageView) match(row packageRow, source string) bool {
+	switch v {
+	case viewVulnerable:
+		return row.vulnerable
+	case viewOutdated:
+		return row.updateAvailable()
+	case viewDeprecated:
+		return row.deprecated
+	case viewSource:
+		return strings.EqualFold(row.source, source)
+	default:
+		return true
+	}
+}
+
 func parseSortFlag(s string) (packageSortMode, bool) {
 	name, dir, _ := strings.Cut(s, ":")
 	mode := parseSortMode(name)
@@ -101,6 +146,28 @@ type packageReadyMsg struct {
 	result     nugetResult
 }
 
+// packageEnrichedMsg carries nuget.org's data for a package loaded from
+// another feed, looked up in the pass after the primary load.
+type packageEnrichedMsg struct {
+	generation int
+	name       string
+	nugetInfo  *PackageInfo
+}
+
+// packageAdvisoriesMsg carries the GitHub advisories for a package from
+// another feed that nuget.org has no data for.
+type packageAdvisoriesMsg struct {
+	generation int
+	name       string
+	vulns      []githubVulnerability
+}
+
+// enrichDoneMsg ends an enrichment pass over count packages.
+type enrichDoneMsg struct {
+	generation int
+	count      int
+}
+
 type reloadRequestedMsg struct {
 	reason    string
 	paths     []string
@@ -116,12 +183,35 @@ type workspaceReloadedMsg struct {
 
 type writeResultMsg struct {
 	err     error
-	written int // number of files written (0 = unknown / not an applyVersion call)
-	skipped int // number of locked refs skipped during scope=all update
+	apply   func() // model changes to make once the write succeeded
+	written int    // number of files written (0 = unknown / not an applyVersion call)
+	skipped int    // number of locked refs skipped during scope=all update
+}
+
+// alignBranchesMsg reports the branches alignOnBranches created.
+type alignBranchesMsg struct {
+	results []alignBranchResult
 }
 
 type restoreResultMsg struct {
-	err error
+	err      error
+	outcomes []restoreOutcome
+	elapsed  time.Duration
+}
+
+// restoreLineMsg is one line of dotnet restore output, as it is written.
+type restoreLineMsg struct {
+	run          int
+	target, line string
+}
+
+// restoreTargetMsg reports a restore target starting or, with done,
+// finishing.
+type restoreTargetMsg struct {
+	run    int
+	target string
+	done   bool
+	err    error
 }
 
 type resizeDebounceMsg struct {
@@ -139,6 +229,13 @@ type searchResultsMsg struct {
 	err     error
 }
 
+// siblingQueriesMsg carries the owner and author queries for a package the
+// search overlay was opened from.
+type siblingQueriesMsg struct {
+	id      string
+	queries []string
+}
+
 type packageFetchedMsg struct {
 	info   *PackageInfo
 	source string
@@ -154,6 +251,61 @@ type depTreeReadyMsg struct {
 	err     error
 }
 
+// transitiveScanMsg carries the V scan's results, keyed by project path.
+type transitiveScanMsg struct {
+	results map[string]transitiveScan
+}
+
+// transitiveScan is one project's vulnerable transitive packages, or the
+// reason dotnet could not list them.
+type transitiveScan struct {
+	vulns []transitiveVuln
+	err   error
+}
+
+type allVersionsMsg struct {
+	pkgName string
+	info    *PackageInfo
+	err     error
+}
+
+type restoreImpactMsg struct {
+	pkgName string
+	version string
+	impact  RestoreImpact
+}
+
+type advisoryReadyMsg struct {
+	url      string
+	advisory *GitHubAdvisory
+	err      error
+}
+
+type nugetLocalsMsg struct {
+	locals []NugetLocal
+	err    error
+}
+
+// sourcesMigratedMsg reports the nuget.config rewrites started from the
+// sources overlay.
+type sourcesMigratedMsg struct {
+	migrated int
+	err      error
+}
+
+// sourceRetriedMsg reports connecting again to a source that failed to
+// initialise.
+type sourceRetriedMsg struct {
+	source NugetSource
+	svc    *NugetService
+	err    error
+}
+
+type nugetLocalClearedMsg struct {
+	name string
+	err  error
+}
+
 type releaseListReadyMsg struct {
 	releases    []GitHubRelease
 	err         error
@@ -163,6 +315,22 @@ type releaseListReadyMsg struct {
 	nuspecVer   string // version the nuspec notes belong to
 }
 
+// publishCheckMsg carries the result of a publishCheck started for the
+// project detail view.
+type publishCheckMsg struct {
+	key   string
+	check publishCheck
+}
+
+// readmeReadyMsg carries a package README fetched for the README overlay.
+type readmeReadyMsg struct {
+	pkgID   string
+	version string
+	source  string // source that served it
+	body    string // "" when no source had one
+	err     error
+}
+
 type releaseNotesReadyMsg struct {
 	body    string
 	htmlURL string
@@ -177,6 +345,58 @@ type nuspecVersionListReadyMsg struct {
 	err      error
 }
 
+// browsePageMsg delivers one page of a feed browser listing.
+type browsePageMsg struct {
+	gen     int
+	results []SearchResult
+	total   int
+	err     error
+}
+
+// alternativesReadyMsg delivers the suggestions for a deprecated or
+// abandoned package.
+type alternativesReadyMsg struct {
+	id    string
+	items []packageAlternative
+	err   error
+}
+
+// replacementFetchedMsg delivers the full metadata of the package chosen to
+// replace another.
+type replacementFetchedMsg struct {
+	id     string
+	info   *PackageInfo
+	source string
+	err    error
+}
+
+// replaceDoneMsg reports the outcome of applying a replacement plan.
+type replaceDoneMsg struct {
+	plan replacePlan
+	err  error
+}
+
+// changeRevertedMsg reports the outcome of reverting a session change.
+type changeRevertedMsg struct {
+	entry journalEntry
+	err   error
+}
+
+// versionDeletedMsg reports the outcome of unlisting or deleting a package
+// version on its feed.
+type versionDeletedMsg struct {
+	pkgName string
+	version string
+	source  string
+	err     error
+}
+
+// changeNotesReadyMsg delivers the <releaseNotes> for every version in the
+// Changes tab, keyed by version.
+type changeNotesReadyMsg struct {
+	notes map[string]string
+}
+
 // nuspecVersionNotesReadyMsg delivers the <releaseNotes> for a single version.
 type nuspecVersionNotesReadyMsg struct {
 	version string
@@ -189,25 +409,35 @@ type nuspecVersionNotesReadyMsg struct {
 type projectPanel struct {
 	sectionBase // baseWidth=30, minWidth=10
 	cursor      int
-	scroll      int // list scroll offset
-	items       []projectItem
+	scroll      int           // list scroll offset
+	items       []projectItem // all, narrowed by the starred-only filter
+	all         []projectItem
 }
 
 type packagePanel struct {
-	cursor   int
-	scroll   int
-	rows     []packageRow
-	sortMode packageSortMode
-	sortDir  bool
+	cursor      int
+	scroll      int
+	rows        []packageRow
+	sortMode    packageSortMode
+	sortDir     bool
+	showLicense bool // License column, toggled with L
+
+	filter     bubbles_textinpute.Model // i: narrows rows by package name
+	filtering  bool                     // the filter input has the keyboard
+	view       packageView              // I: narrows rows by status or source
+	viewSource string                   // the source shown when view is viewSource
+	unfiltered int                      // rows before the filters, for their count
 }
 
 type detailPanel struct {
 	sectionBase // baseWidth=50, minWidth=10
 	vp          bubbles_viewport.Model
+	project     bool // showing the selected project rather than a package
 }
 
 type logPanel struct {
-	vp bubbles_viewport.Model
+	vp      bubbles_viewport.Model
+	colored []string // colorizeLogLine output, parallel to ctx.LogLines
 }
 
 // --- Overlay state types ---
@@ -226,6 +456,7 @@ type releaseNotesTab int
 const (
 	tabReleases releaseNotesTab = 0
 	tabNuSpec   releaseNotesTab = 1
+	tabChanges  releaseNotesTab = 2
 )
 
 type releaseNotesOverlay struct {
@@ -255,13 +486,26 @@ type releaseNotesOverlay struct {
 	nsPkgID      string            // package ID for nuspec fetches
 	nsNotesCache map[string]string // version → cached release notes (avoids re-fetching)
 
+	// Changes tab state: every version an update would cross
+	chLoading  bool
+	chVersions []PackageVersion // installed (exclusive) → target (inclusive), newest first
+	chFrom     string
+	chTo       string
+	chOmitted  int // older versions left out past maxChangeVersions
+	chCursor   int
+	chOffsets  []int // viewport line where each version's notes start
+
 	// Derived state: which tabs are available (set after fetches complete)
 	ghAvailable bool
 	nsAvailable bool
 }
 
 type sourcesOverlay struct {
-	sectionBase // baseWidth=90, minWidth=40, maxMargin=4
+	sectionBase      // baseWidth=90, minWidth=40, maxMargin=4
+	confirming  bool // "m" pressed; waiting for y/n before rewriting nuget.config
+	migrating   bool
+	cursor      int    // index into ctx.Sources
+	retrying    string // name of the failed source being connected again
 }
 
 type helpOverlay struct {
@@ -269,6 +513,136 @@ type helpOverlay struct {
 	vp          bubbles_viewport.Model
 }
 
+type diagnosticsOverlay struct {
+	sectionBase // basePct=70, minWidth=56, maxMargin=4
+	vp          bubbles_viewport.Model
+}
+
+// configInspector lists every nuget.config setting in effect for a directory.
+// readmeOverlay shows the README embedded in a package (M), rendered from
+// its markdown.
+type readmeOverlay struct {
+	sectionBase // basePct=80, minWidth=56, maxMargin=4
+	vp          bubbles_viewport.Model
+	pkgID       string
+	version     string
+	source      string
+	loading     bool
+	err         error
+	body        string // raw markdown; "" when the package has none
+}
+
+// retargetOverlay previews moving a project to another target framework:
+// which packages still fit, which need an update, and which block it.
+type retargetOverlay struct {
+	sectionBase // basePct=70, minWidth=56, maxMargin=4
+	vp          bubbles_viewport.Model
+	project     *ParsedProject
+	candidates  []TargetFramework
+	cursor      int
+	impacts     []retargetImpact // for candidates[cursor]
+}
+
+type configInspector struct {
+	sectionBase // basePct=70, minWidth=56, maxMargin=4
+	vp          bubbles_viewport.Model
+	dir         string
+	config      effectiveNugetConfig
+}
+
+// failureSummary lists package load failures grouped by source and cause,
+// shown once after the initial load if anything failed.
+type failureSummary struct {
+	sectionBase // baseWidth=76, minWidth=48, maxMargin=4
+	groups      []failureGroup
+	cursor      int
+}
+
+// restoreReport summarizes the last restore (D): how each project or
+// solution fared, how long it took, and the NuGet warnings and errors it
+// produced. It is kept after closing so it can be reopened.
+type restoreReport struct {
+	sectionBase // baseWidth=96, minWidth=50, maxMargin=4
+	outcomes    []restoreOutcome
+	elapsed     time.Duration
+	rows        []restoreReportRow
+	cursor      int
+}
+
+// restoreProgress follows the running restore (r/R) with each target's
+// state and the live dotnet output. It opens when a restore starts; D shows
+// it again while the restore runs.
+type restoreProgress struct {
+	sectionBase                    // basePct=80, minWidth=56, maxMargin=4
+	run         int                // bumped per restore; messages from older runs are dropped
+	cancel      context.CancelFunc // nil once the restore has finished
+	targets     []string
+	started     time.Time
+	running     Set[string]
+	finished    map[string]error
+	lines       []string // recent output, newest last
+}
+
+// restoreReportRow is a target line (message < 0) or one of its messages.
+type restoreReportRow struct {
+	outcome int
+	message int
+}
+
+// repoAlignOverlay lists the packages whose versions differ between the
+// repositories of a workspace (W), to align them on disk or on new
+// branches.
+type repoAlignOverlay struct {
+	sectionBase // basePct=80, minWidth=60, maxMargin=4
+	rows        []repoDivergence
+	targets     []string // version each row aligns to
+	selected    []bool
+	cursor      int
+}
+
+// projectGraphOverlay shows the <ProjectReference> graph around one
+// project (G), or every project with its reference counts when "All
+// Projects" is selected.
+type projectGraphOverlay struct {
+	sectionBase // baseWidth=80, minWidth=48, maxMargin=4
+	graph       *ProjectGraph
+	center      *ParsedProject   // nil lists every project
+	history     []*ParsedProject // centers to return to with backspace
+	rows        []projectGraphRow
+	cursor      int
+}
+
+// projectGraphRow is one line of the graph overlay: a section header, or a
+// project at some depth of a reference tree.
+type projectGraphRow struct {
+	header  string
+	path    string
+	project *ParsedProject // nil for projects outside the workspace
+	depth   int
+	note    string // e.g. "cycle", "see above"
+}
+
+// transitiveOverlay lists the vulnerable transitive packages found by the
+// V scan, each with its path from a direct dependency.
+type transitiveOverlay struct {
+	sectionBase // basePct=70, minWidth=56, maxMargin=4
+	loading     bool
+	findings    []transitiveFinding
+	failed      []string // projects dotnet could not list
+	cursor      int
+}
+
+type transitiveFinding struct {
+	project *ParsedProject
+	vuln    transitiveVuln
+}
+
+// reportExport picks the format for writing a report to .guget/reports.
+type reportExport struct {
+	sectionBase // baseWidth=56, minWidth=40, maxMargin=4
+	cursor      int
+}
+
 // --- Data display types ---
 
 type projectItem struct {
@@ -280,6 +654,9 @@ func (p projectItem) Title() string {
 	if p.project == nil {
 		return "◈ All Projects"
 	}
+	if p.project.LoadErr != nil {
+		return "✗ " + p.name
+	}
 	return "◦ " + p.name
 }
 
@@ -287,14 +664,27 @@ func (p projectItem) Description() string {
 	if p.project == nil {
 		return "Combined view"
 	}
+	if p.project.LoadErr != nil {
+		return "parse error"
+	}
 	var fws []string
 	for fw := range p.project.TargetFrameworks {
 		fws = append(fws, fw.String())
 	}
+	desc := fmt.Sprintf("%d packages", p.project.Packages.Len())
 	if len(fws) > 0 {
-		return strings.Join(fws, ", ")
+		desc = strings.Join(fws, ", ")
+	}
+	switch {
+	case p.project.PackagesConfig != "":
+		desc = "packages.config · " + desc
+	case p.project.Legacy:
+		desc = "legacy · " + desc
+	}
+	if n := len(p.project.Diagnostics); n > 0 {
+		desc += fmt.Sprintf(" · ⚠ %d", n)
 	}
-	return fmt.Sprintf("%d packages", p.project.Packages.Len())
+	return desc
 }
 
 type packageRow struct {
@@ -308,18 +698,93 @@ type packageRow struct {
 	latestStable     *PackageVersion
 	diverged         bool
 	oldest           SemVer
-	vulnerable       bool // installed version has ≥1 known vulnerability
-	deprecated       bool // package is deprecated in the registry
+	vulnerable       bool            // installed version has ≥1 known vulnerability
+	minFixed         *PackageVersion // smallest update clearing every advisory (vulnerable rows only)
+	deprecated       bool            // package is deprecated in the registry
+	renamedTo        string          // successor id when the package was renamed
+	rule             PackageRule     // update rule from settings
+	prerelease       bool            // latest versions include pre-releases (P)
+	restoreWarnings  []string        // NuGet codes the last restore logged for it, e.g. NU1701
 }
 
 // effectiveVersion returns the version used for status comparisons.
 // When diverged (All Projects view), use the oldest version so the icon
-// reflects the least-up-to-date project.
+// reflects the least-up-to-date project. Ranges and floating versions are
+// resolved against the feed.
 func (r packageRow) effectiveVersion() SemVer {
 	if r.diverged {
-		return r.oldest
+		return r.info.Resolve(r.oldest)
+	}
+	return r.installedVersion()
+}
+
+// installedVersion returns the version restore picks for the row's
+// reference: the declared version, or the best match for a range.
+func (r packageRow) installedVersion() SemVer {
+	return r.info.Resolve(r.ref.Version)
+}
+
+// heldBack returns why a bulk update to version to leaves the row alone:
+// "locked" for an exact [x] version, or what its rule from settings allows.
+// Returns "" when the row can be updated.
+func (r packageRow) heldBack(to SemVer) string {
+	if r.ref.Locked {
+		return "locked"
+	}
+	return r.rule.heldBack(to)
+}
+
+// license returns the license declared by the row's installed version.
+func (r packageRow) license() string {
+	if r.info == nil {
+		return ""
+	}
+	return r.info.License(r.effectiveVersion())
+}
+
+// licenseChange reports whether updating the row to its latest compatible
+// version would change the declared license.
+func (r packageRow) licenseChange() (from, to string, changed bool) {
+	if r.info == nil || r.latestCompatible == nil {
+		return "", "", false
+	}
+	return r.info.LicenseChange(r.effectiveVersion(), r.latestCompatible.SemVer)
+}
+
+// installedVulnerabilities returns the advisories affecting the row's
+// installed version.
+func (r packageRow) installedVulnerabilities() []PackageVulnerability {
+	if !r.vulnerable || r.info == nil {
+		return nil
+	}
+	for _, v := range r.info.Versions {
+		if v.SemVer.String() == r.installedVersion().String() {
+			return v.Vulnerabilities
+		}
+	}
+	return nil
+}
+
+// maxSeverity returns the highest advisory severity affecting the installed
+// version (0=low … 3=critical), or -1 when there are none.
+func (r packageRow) maxSeverity() int {
+	highest := -1
+	for _, v := range r.installedVulnerabilities() {
+		if int(v.Severity) > highest {
+			highest = int(v.Severity)
+		}
+	}
+	return highest
+}
+
+// updateAvailable reports whether the feed has a newer version than the
+// row's effective one, preferring the latest compatible release.
+func (r packageRow) updateAvailable() bool {
+	check := r.latestCompatible
+	if check == nil {
+		check = r.latestStable
 	}
-	return r.ref.Version
+	return check != nil && check.SemVer.IsNewerThan(r.effectiveVersion())
 }
 
 func (r packageRow) statusIcon() string {
@@ -332,12 +797,13 @@ func (r packageRow) statusIcon() string {
 	if r.err != nil {
 		return "✗"
 	}
-	ver := r.effectiveVersion()
-	check := r.latestCompatible
-	if check == nil {
-		check = r.latestStable
+	if r.rule.Pin {
+		return "⊘"
+	}
+	if r.renamedTo != "" {
+		return "→"
 	}
-	if check != nil && check.SemVer.IsNewerThan(ver) {
+	if r.updateAvailable() {
 		if r.latestStable != nil && r.latestCompatible != nil &&
 			r.latestStable.SemVer.IsNewerThan(r.latestCompatible.SemVer) {
 			return "⬆"
@@ -357,15 +823,19 @@ func (r packageRow) statusStyle() lipgloss.Style {
 	if r.vulnerable {
 		return styleRed
 	}
+	if isLoadTimeout(r.err) {
+		return styleYellow
+	}
 	if r.err != nil {
 		return styleRed
 	}
-	ver := r.effectiveVersion()
-	check := r.latestCompatible
-	if check == nil {
-		check = r.latestStable
+	if r.rule.Pin {
+		return styleMuted
+	}
+	if r.renamedTo != "" {
+		return styleYellow
 	}
-	if check != nil && check.SemVer.IsNewerThan(ver) {
+	if r.updateAvailable() {
 		if r.latestStable != nil && r.latestCompatible != nil &&
 			r.latestStable.SemVer.IsNewerThan(r.latestCompatible.SemVer) {
 			return stylePurple
@@ -384,15 +854,28 @@ type versionPicker struct {
 	sectionBase   // baseWidth=50, minWidth=40, maxMargin=4
 	pkgName       string
 	versions      []PackageVersion
-	cursor        int
+	rows          []versionListRow         // versions grouped by major; see rebuildRows
+	expanded      Set[int]                 // major versions whose group is open
+	cursor        int                      // index into rows
+	filter        bubbles_textinpute.Model // /: narrows the list by version
+	filtering     bool                     // the filter input has the keyboard
+	hidePre       bool                     // p: pre-releases are hidden
+	hideIncompat  bool                     // c: versions not supporting targets are hidden
 	targets       Set[TargetFramework]
 	addMode       bool
 	targetProject *ParsedProject
+	cached        map[string]bool // lowercase versions already in a local package folder
+	current       string          // installed version; empty in add mode
+	impact        map[string]*RestoreImpact
+	impactLoading string // version whose impact is being estimated
+	loadingAll    int    // older versions being fetched for a trimmed package (0 = none)
 }
 
+// selectedVersion returns the version under the cursor, or nil on a group
+// header.
 func (vp *versionPicker) selectedVersion() *PackageVersion {
-	if vp.cursor < len(vp.versions) {
-		return &vp.versions[vp.cursor]
+	if vp.cursor < len(vp.rows) && !vp.rows[vp.cursor].Header {
+		return &vp.versions[vp.rows[vp.cursor].Index]
 	}
 	return nil
 }
@@ -409,6 +892,60 @@ type packageSearch struct {
 	fetchingVersion bool
 	fetchedInfo     *PackageInfo
 	fetchedSource   string
+	replaceFor      string   // package the chosen result will replace
+	siblingOf       string   // package whose owners/authors are being searched
+	siblingQueries  []string // owner:/author: queries, cycled with tab
+	siblingQuery    int
+}
+
+// browseMode selects what the feed browser lists.
+type browseMode int
+
+const (
+	browseTop   browseMode = iota // most downloaded first
+	browseTag                     // packages carrying a tag
+	browseOwner                   // packages owned by an account
+)
+
+// feedBrowser is a read-only explorer for a whole package source, for
+// internal feeds without a web UI.
+type feedBrowser struct {
+	sectionBase // basePct=80, minWidth=60, maxMargin=4
+	input       bubbles_textinpute.Model
+	source      int // index into ctx.NugetServices
+	mode        browseMode
+	query       string // search query behind the listed results
+	results     []SearchResult
+	total       int
+	cursor      int
+	loading     bool
+	err         error
+	gen         int // bumped on every new listing so stale pages are dropped
+}
+
+// alternativesOverlay suggests packages to switch to and walks through
+// replacing the selected package with one of them.
+type alternativesOverlay struct {
+	sectionBase // baseWidth=84, minWidth=56, maxMargin=4
+	pkgID       string
+	reason      string // why switching is worth considering; "" if it isn't flagged
+	items       []packageAlternative
+	cursor      int
+	loading     bool
+	err         error
+	fetching    bool // loading the chosen package's versions
+}
+
+// replacePreview shows what replacing one package with another would change
+// and applies it as one transaction.
+type replacePreview struct {
+	sectionBase // baseWidth=90, minWidth=60, maxMargin=4
+	oldName     string
+	info        *PackageInfo
+	source      string
+	allProjects bool // replace in every project, not just the selected one
+	plan        replacePlan
+	scroll      int
 }
 
 type confirmRemove struct {
@@ -416,11 +953,102 @@ type confirmRemove struct {
 	pkgName     string
 }
 
+type advisoryOverlay struct {
+	sectionBase // baseWidth=70, minWidth=44, maxMargin=4
+	pkgName     string
+	version     string
+	vulns       []PackageVulnerability
+	cursor      int
+	details     map[string]*GitHubAdvisory // advisory URL → details
+	errs        map[string]error
+}
+
+// severityThreshold selects which vulnerable packages a security update
+// touches.
+type severityThreshold int
+
+const (
+	severityAny      severityThreshold = 0 // every vulnerable package
+	severityHighPlus severityThreshold = 2 // only high and critical advisories
+)
+
+type securityUpdate struct {
+	sectionBase // baseWidth=64, minWidth=44, maxMargin=4
+	threshold   severityThreshold
+	project     *ParsedProject // nil = All Projects view
+}
+
+type cacheOverlay struct {
+	sectionBase // baseWidth=72, minWidth=48, maxMargin=4
+	locals      []NugetLocal
+	cursor      int // len(locals) selects "all"
+	loading     bool
+	err         error
+	confirming  string // cache awaiting y/n before clearing
+	clearing    string // cache currently being cleared
+}
+
+// updatePlanOverlay previews a bulk update row by row and applies the rows
+// left selected.
+type updatePlanOverlay struct {
+	sectionBase // basePct=85, minWidth=60, maxMargin=4
+	title       string
+	rows        []updatePlanRow
+	cursor      int
+}
+
+// noteEditor edits the team note and tags on a package.
+type noteEditor struct {
+	sectionBase // baseWidth=64, minWidth=44, maxMargin=4
+	pkgName     string
+	note        bubbles_textinpute.Model
+	tags        bubbles_textinpute.Model
+	onTags      bool // the tags field has focus
+}
+
+// metadataFixOverlay prompts for the package metadata a packable project
+// is missing and adds what is filled in to the project file.
+type metadataFixOverlay struct {
+	sectionBase // baseWidth=72, minWidth=48, maxMargin=4
+	project     *ParsedProject
+	lints       []metadataLint
+	inputs      []bubbles_textinpute.Model
+	cursor      int
+}
+
+// sessionChanges lists every project file change made this session and
+// reverts them selectively.
+type sessionChanges struct {
+	sectionBase // baseWidth=76, minWidth=50, maxMargin=4
+	entries     []journalEntry
+	cursor      int
+}
+
+type confirmConflictFix struct {
+	sectionBase // baseWidth=60, minWidth=40, maxMargin=4
+	conflict    VersionConflict
+	project     *ParsedProject
+}
+
+// confirmTyped guards a bulk operation behind typing a phrase such as
+// "update 37", so a stray keypress can't rewrite a whole solution.
+type confirmTyped struct {
+	sectionBase // baseWidth=56, minWidth=40, maxMargin=4
+	title       string
+	phrase      string
+	input       bubbles_textinpute.Model
+	mismatch    bool
+	run         func() bubble_tea.Cmd
+}
+
 type confirmUpdate struct {
 	sectionBase // baseWidth=52, minWidth=40, maxMargin=4
 	pkgName     string
 	newVersion  string
 	project     *ParsedProject
+	pinned      bool   // installed version is pinned with [x.y.z]
+	licenseFrom string // set with licenseTo when the update changes the license
+	licenseTo   string
 }
 
 type locationPicker struct {
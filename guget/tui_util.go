@@ -191,17 +191,13 @@ func truncateStyled(s string, n int) string {
 	return result.String()
 }
 
-// hyperlinkEnabled controls whether OSC 8 escape codes are emitted.
-// Disabled when --no-color is active.
-var hyperlinkEnabled = true
-
-// hyperlink wraps text in an OSC 8 terminal hyperlink.
-// Unsupported terminals silently ignore the escape codes.
+// hyperlink wraps text in an OSC 8 terminal hyperlink when the terminal
+// supports them (see TerminalCaps); otherwise it returns text unchanged.
 func hyperlink(url, text string) string {
-	if !hyperlinkEnabled || url == "" {
+	if !termCaps.Hyperlinks || url == "" {
 		return text
 	}
-	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\ ↗"
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\" + glyphs.Link
 }
 
 // clampListScroll adjusts *scroll so that cursor is visible within a viewport
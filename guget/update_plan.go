@@ -0,0 +1,110 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// updateTarget is one package to move to Version in an update plan.
+type updateTarget struct {
+	Package string
+	Version string
+	Info    *PackageInfo // for license checks; may be nil
+}
+
+// updatePlanRow is one package in one project of an update plan.
+type updatePlanRow struct {
+	Package     string
+	Project     *ParsedProject
+	File        string // where the version is written
+	From        string
+	To          string
+	Skip        string // why the row can't be applied, e.g. "pinned"
+	LicenseFrom string // set with LicenseTo when the update changes the license
+	LicenseTo   string
+	Selected    bool
+}
+
+// written returns the version the update writes: To in From's range or
+// floating syntax.
+func (r updatePlanRow) written() string {
+	return ParseSemVer(r.From).Retarget(r.To)
+}
+
+// buildUpdatePlan lists every reference in projects that one of targets would
+// change, ordered by package then project. Pinned references are listed but
+// not selected.
+func buildUpdatePlan(projects []*ParsedProject, targets []updateTarget) []updatePlanRow {
+	var rows []updatePlanRow
+	for _, t := range targets {
+		to := ParseSemVer(t.Version)
+		for _, p := range projects {
+			if !p.canUpdatePackage(t.Package) || p.LoadErr != nil {
+				continue
+			}
+			ref, ok := findReference(p, t.Package)
+			if !ok {
+				continue
+			}
+			installed := t.Info.Resolve(ref.Version)
+			if installed.String() == to.String() {
+				continue
+			}
+			row := updatePlanRow{
+				Package:  ref.Name,
+				Project:  p,
+				File:     p.SourceFileForPackage(ref.Name),
+				From:     ref.Version.Declared(),
+				To:       t.Version,
+				Selected: true,
+			}
+			if ref.Locked {
+				row.Skip, row.Selected = "pinned", false
+			}
+			if t.Info != nil {
+				if from, lto, changed := t.Info.LicenseChange(installed, to); changed {
+					row.LicenseFrom, row.LicenseTo = from, lto
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !strings.EqualFold(rows[i].Package, rows[j].Package) {
+			return strings.ToLower(rows[i].Package) < strings.ToLower(rows[j].Package)
+		}
+		return rows[i].Project.FileName < rows[j].Project.FileName
+	})
+	return rows
+}
+
+// toggleUpdateRow flips row i and every other row writing the same package
+// to the same file: a shared .props edit can't apply to only some of the
+// projects that import it.
+func toggleUpdateRow(rows []updatePlanRow, i int) {
+	if rows[i].Skip != "" {
+		return
+	}
+	on := !rows[i].Selected
+	for j := range rows {
+		if rows[j].Skip == "" && rows[j].File == rows[i].File && strings.EqualFold(rows[j].Package, rows[i].Package) {
+			rows[j].Selected = on
+		}
+	}
+}
+
+// updateEdits returns one selected row per package and file: the writes that
+// carry out the plan.
+func updateEdits(rows []updatePlanRow) []updatePlanRow {
+	seen := NewSet[string]()
+	var edits []updatePlanRow
+	for _, r := range rows {
+		key := strings.ToLower(r.Package) + "\x00" + r.File
+		if !r.Selected || seen.Contains(key) {
+			continue
+		}
+		seen.Add(key)
+		edits = append(edits, r)
+	}
+	return edits
+}
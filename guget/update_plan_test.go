@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestBuildUpdatePlan(t *testing.T) {
+	project := func(name string, sources map[string]string, refs ...PackageReference) *ParsedProject {
+		p := &ParsedProject{
+			FileName:       name + ".csproj",
+			FilePath:       "/src/" + name + ".csproj",
+			Packages:       NewSet[PackageReference](),
+			PackageSources: sources,
+		}
+		for _, r := range refs {
+			p.Packages.Add(r)
+		}
+		return p
+	}
+	shared := map[string]string{"serilog": "/src/Directory.Build.props"}
+	api := project("Api", shared, PackageReference{Name: "Serilog", Version: ParseSemVer("3.0.0")})
+	web := project("Web", shared, PackageReference{Name: "Serilog", Version: ParseSemVer("3.0.0")})
+	worker := project("Worker", map[string]string{},
+		PackageReference{Name: "Serilog", Version: ParseSemVer("4.0.0")},
+		PackageReference{Name: "Polly", Version: ParseSemVer("7.0.0"), Locked: true})
+	legacy := project("Legacy", map[string]string{}, PackageReference{Name: "Serilog", Version: ParseSemVer("2.0.0")})
+	legacy.Legacy = true
+
+	rows := buildUpdatePlan([]*ParsedProject{worker, web, api, legacy}, []updateTarget{
+		{Package: "Serilog", Version: "4.0.0"},
+		{Package: "Polly", Version: "8.0.0"},
+	})
+	// Worker is already on 4.0.0 and Legacy is read-only.
+	if len(rows) != 3 || rows[0].Package != "Polly" || rows[1].Project != api || rows[2].Project != web {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if rows[0].Skip != "pinned" || rows[0].Selected {
+		t.Errorf("pinned reference should be listed but not selected: %+v", rows[0])
+	}
+
+	// Api and Web share Directory.Build.props: toggling one toggles both.
+	toggleUpdateRow(rows, 1)
+	if rows[1].Selected || rows[2].Selected {
+		t.Error("deselecting a shared-file row should deselect every row of that file")
+	}
+	if edits := updateEdits(rows); len(edits) != 0 {
+		t.Errorf("edits = %+v, want none", edits)
+	}
+	toggleUpdateRow(rows, 2)
+	if edits := updateEdits(rows); len(edits) != 1 || edits[0].File != "/src/Directory.Build.props" {
+		t.Errorf("edits = %+v, want one write to Directory.Build.props", edits)
+	}
+}
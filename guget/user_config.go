@@ -0,0 +1,493 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserConfig holds guget settings. They are read from config.json in the user
+// config directory (or the file named by GUGET_CONFIG), then from
+// .guget/config.json in the project, whose fields win except where
+// limitProjectLayer says otherwise. Every field is optional; missing fields
+// keep the value from the previous layer.
+type UserConfig struct {
+	// BulkConfirmThreshold is the number of packages or projects at which a
+	// bulk operation must be confirmed by typing e.g. "update 37". 0 turns
+	// typed confirmation off.
+	BulkConfirmThreshold int `json:"bulkConfirmThreshold"`
+	// DisableBulkWrites refuses every operation that writes to more than one
+	// package or project at once — useful on shared build machines.
+	DisableBulkWrites bool `json:"disableBulkWrites"`
+	// Theme is used when --theme is not given.
+	Theme string `json:"theme,omitempty"`
+	// SortBy is the initial sort order when --sort-by is not given, with
+	// its direction, e.g. "name:desc".
+	SortBy string `json:"sortBy,omitempty"`
+	// Verbosity is the log level when --verbose is not given.
+	Verbosity string `json:"verbosity,omitempty"`
+	// Prerelease counts pre-releases as --prerelease does.
+	Prerelease bool `json:"prerelease,omitempty"`
+	// ShowLogs opens the TUI with the log panel showing.
+	ShowLogs bool `json:"showLogs,omitempty"`
+	// ProjectsPanelOffset and DetailPanelOffset widen (or, negative, narrow)
+	// the projects and detail panels as [ / ] do, for project directories
+	// that have no layout of their own saved yet.
+	ProjectsPanelOffset int `json:"projectsPanelOffset,omitempty"`
+	DetailPanelOffset   int `json:"detailPanelOffset,omitempty"`
+	// Renames maps retired package ids to their successors, on top of the
+	// built-in list. An empty successor drops a built-in entry.
+	Renames map[string]string `json:"renames,omitempty"`
+	// DisableNugetOrg stops every call to nuget.org, as --no-nuget-org does,
+	// for air-gapped networks.
+	DisableNugetOrg bool `json:"disableNugetOrg,omitempty"`
+	// GitHubAdvisories cross-checks packages from other feeds that nuget.org
+	// has no data for with the GitHub Advisory Database, authenticating with
+	// GITHUB_TOKEN or GH_TOKEN.
+	GitHubAdvisories bool `json:"githubAdvisories,omitempty"`
+	// CredentialProviderTimeout bounds each credential provider call when
+	// --credential-timeout is not given, e.g. "60s" for a device-code sign-in.
+	CredentialProviderTimeout string `json:"credentialProviderTimeout,omitempty"`
+	// CredentialProviders pins the credential provider asked for a source,
+	// by source name: the provider's file name (with or without extension)
+	// or its path. Other sources still try every provider.
+	CredentialProviders map[string]string `json:"credentialProviders,omitempty"`
+	// RestoreParallelism is how many projects are restored at once when
+	// --restore-jobs is not given; 0 keeps the default of one per CPU, up
+	// to 4.
+	RestoreParallelism int `json:"restoreParallelism,omitempty"`
+	// FetchConcurrency is how many packages are looked up at once while
+	// loading when --concurrency is not given; 0 keeps the default of 16.
+	FetchConcurrency int `json:"fetchConcurrency,omitempty"`
+	// Include lists file name globs also loaded as projects when --include
+	// is not given, e.g. ["*.msbuildproj"].
+	Include []string `json:"include,omitempty"`
+	// Webhook is where guget daemon and guget report post their findings
+	// when --webhook is not given, and WebhookFormat the body to send:
+	// "json", "slack" or "teams".
+	Webhook       string `json:"webhook,omitempty"`
+	WebhookFormat string `json:"webhookFormat,omitempty"`
+	// Packages holds per-package update rules: pins, major-version limits,
+	// and exclusions from bulk updates.
+	Packages packageRules `json:"packages,omitempty"`
+}
+
+func defaultUserConfig() UserConfig {
+	return UserConfig{BulkConfirmThreshold: 10}
+}
+
+// userConfigPath returns GUGET_CONFIG when set, otherwise config.json next
+// to the saved layouts. Returns "" when neither is available.
+func userConfigPath(getenv func(string) string) string {
+	if p := getenv("GUGET_CONFIG"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "guget", "config.json")
+}
+
+// projectConfigPath is the settings file meant to be committed with the
+// repository so a team shares one configuration.
+func projectConfigPath(projectDir string) string {
+	return filepath.Join(projectDir, ".guget", "config.json")
+}
+
+// loadUserConfig reads the config at path over the defaults. A missing file
+// is not an error.
+func loadUserConfig(path string) (UserConfig, error) {
+	return loadConfigLayers(path)
+}
+
+// loadConfigLayers reads each path in turn over the defaults, so later files
+// override earlier ones field by field (renames, package rules, and provider
+// pins entry by entry). Missing
+// files and empty paths are skipped. On error the layers read so far are
+// returned.
+func loadConfigLayers(paths ...string) (UserConfig, error) {
+	cfg := defaultUserConfig()
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return cfg, err
+		}
+		next := cfg
+		next.Renames = maps.Clone(cfg.Renames)
+		next.Packages = maps.Clone(cfg.Packages)
+		next.CredentialProviders = maps.Clone(cfg.CredentialProviders)
+		if err := json.Unmarshal(data, &next); err != nil {
+			return cfg, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		cfg = next
+	}
+	return cfg, nil
+}
+
+// loadSettings returns the user settings overridden by the project's, for
+// the TUI and headless commands alike.
+func loadSettings(projectDir string) UserConfig {
+	userPath, projectPath := userConfigPath(os.Getenv), projectConfigPath(projectDir)
+	cfg, err := loadConfigLayers(userPath, projectPath)
+	if err != nil {
+		logWarn("Ignoring settings: %v", err)
+	}
+	user, _ := loadConfigLayers(userPath)
+	cfg = limitProjectLayer(user, cfg)
+	if cfg.Theme != "" && !slices.Contains(validThemeNames, cfg.Theme) {
+		logWarn("Ignoring unknown theme %q in settings", cfg.Theme)
+		cfg.Theme = ""
+	}
+	if cfg.Verbosity != "" && !slices.Contains(validVerbosities, cfg.Verbosity) {
+		logWarn("Ignoring unknown verbosity %q in settings", cfg.Verbosity)
+		cfg.Verbosity = ""
+	}
+	if _, err := cfg.credentialTimeout(); err != nil {
+		logWarn("Ignoring %v in settings", err)
+		cfg.CredentialProviderTimeout = ""
+	}
+	if cfg.RestoreParallelism < 0 {
+		logWarn("Ignoring restoreParallelism %d in settings: want 1 or more", cfg.RestoreParallelism)
+		cfg.RestoreParallelism = 0
+	}
+	if cfg.FetchConcurrency < 0 {
+		logWarn("Ignoring fetchConcurrency %d in settings: want 1 or more", cfg.FetchConcurrency)
+		cfg.FetchConcurrency = 0
+	}
+	if err := validateProjectIncludes(cfg.Include); err != nil {
+		logWarn("Ignoring include in settings: %v", err)
+		cfg.Include = nil
+	}
+	if err := validateWebhookURL(cfg.Webhook); err != nil {
+		logWarn("Ignoring %v in settings", err)
+		cfg.Webhook = ""
+	}
+	if cfg.WebhookFormat != "" && !slices.Contains(webhookFormats, cfg.WebhookFormat) {
+		logWarn("Ignoring unknown webhookFormat %q in settings", cfg.WebhookFormat)
+		cfg.WebhookFormat = ""
+	}
+	return cfg
+}
+
+// userOnlySettings are the settings limitProjectLayer ignores in a project's
+// .guget/config.json, so config import refuses them.
+var userOnlySettings = []string{"webhook", "webhookFormat", "githubAdvisories", "credentialProviders"}
+
+// limitProjectLayer takes back what the project's .guget/config.json, which
+// comes with whatever repository was cloned, must not decide: it can only
+// tighten the bulk-write guards a machine sets, can't turn nuget.org back
+// on, and can't choose where reports are posted, whether package ids go to
+// GitHub with the user's token, or which credential provider runs. user
+// holds the user-level settings alone; merged holds the user and project
+// settings together.
+func limitProjectLayer(user, merged UserConfig) UserConfig {
+	merged.DisableBulkWrites = merged.DisableBulkWrites || user.DisableBulkWrites
+	merged.DisableNugetOrg = merged.DisableNugetOrg || user.DisableNugetOrg
+	// A lower threshold asks sooner; 0 never asks, so it only wins when
+	// both layers say so.
+	if merged.BulkConfirmThreshold <= 0 || (user.BulkConfirmThreshold > 0 && user.BulkConfirmThreshold < merged.BulkConfirmThreshold) {
+		merged.BulkConfirmThreshold = user.BulkConfirmThreshold
+	}
+	if merged.Webhook != user.Webhook || merged.WebhookFormat != user.WebhookFormat {
+		logWarn("Ignoring webhook settings from the project: set them in the user settings")
+		merged.Webhook, merged.WebhookFormat = user.Webhook, user.WebhookFormat
+	}
+	if merged.GitHubAdvisories != user.GitHubAdvisories {
+		logWarn("Ignoring githubAdvisories from the project: set it in the user settings")
+		merged.GitHubAdvisories = user.GitHubAdvisories
+	}
+	if !maps.Equal(merged.CredentialProviders, user.CredentialProviders) {
+		logWarn("Ignoring credentialProviders from the project: set them in the user settings")
+		merged.CredentialProviders = user.CredentialProviders
+	}
+	return merged
+}
+
+// credentialTimeout parses CredentialProviderTimeout; 0 when unset.
+func (c UserConfig) credentialTimeout() (time.Duration, error) {
+	if c.CredentialProviderTimeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.CredentialProviderTimeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("credentialProviderTimeout %q: want a positive duration such as 60s", c.CredentialProviderTimeout)
+	}
+	return d, nil
+}
+
+// validate reports the first setting with a value guget can't use.
+func (c UserConfig) validate() error {
+	if c.Theme != "" && !slices.Contains(validThemeNames, c.Theme) {
+		return fmt.Errorf("unknown theme %q", c.Theme)
+	}
+	if c.Verbosity != "" && !slices.Contains(validVerbosities, c.Verbosity) {
+		return fmt.Errorf("unknown verbosity %q", c.Verbosity)
+	}
+	if _, err := c.credentialTimeout(); err != nil {
+		return err
+	}
+	if c.RestoreParallelism < 0 {
+		return fmt.Errorf("restoreParallelism %d: want 1 or more", c.RestoreParallelism)
+	}
+	if c.FetchConcurrency < 0 {
+		return fmt.Errorf("fetchConcurrency %d: want 1 or more", c.FetchConcurrency)
+	}
+	if err := validateProjectIncludes(c.Include); err != nil {
+		return err
+	}
+	if err := validateWebhookURL(c.Webhook); err != nil {
+		return err
+	}
+	if c.WebhookFormat != "" && !slices.Contains(webhookFormats, c.WebhookFormat) {
+		return fmt.Errorf("unknown webhookFormat %q", c.WebhookFormat)
+	}
+	return nil
+}
+
+// setConfigValue sets the setting key in the settings file at path to value,
+// or removes it when unset, keeping the file's other settings. The value is
+// parsed for the setting's type; lists take comma-separated items. Settings
+// holding maps are edited in the file itself.
+func setConfigValue(path, key, value string, unset bool) error {
+	field, ok := configField(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	raw := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+
+	if unset {
+		delete(raw, key)
+	} else {
+		var v any
+		switch field.Type.Kind() {
+		case reflect.String:
+			v = value
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s: want true or false, got %q", key, value)
+			}
+			v = b
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s: want a whole number, got %q", key, value)
+			}
+			v = n
+		case reflect.Slice:
+			items := []string{}
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			v = items
+		default:
+			return fmt.Errorf("%s can't be set from the command line; edit %s", key, path)
+		}
+		enc, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		raw[key] = enc
+	}
+
+	out, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return err
+	}
+	var cfg UserConfig
+	dec := json.NewDecoder(bytes.NewReader(out))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), 0644)
+}
+
+// configField finds the UserConfig field stored under the JSON name key.
+func configField(key string) (reflect.StructField, bool) {
+	t := reflect.TypeFor[UserConfig]()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name == key {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// exportConfig writes cfg as indented JSON, leaving out userOnlySettings:
+// the export is meant to be shared, where they'd be ignored, and the
+// webhook URL is a secret.
+func exportConfig(w io.Writer, cfg UserConfig) error {
+	cfg.Webhook, cfg.WebhookFormat = "", ""
+	cfg.GitHubAdvisories = false
+	cfg.CredentialProviders = nil
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// importConfig validates the settings file at src and writes it to dst,
+// creating dst's directory. Unknown fields are rejected so a typo doesn't
+// silently do nothing, and so are userOnlySettings.
+func importConfig(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	var cfg UserConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return fmt.Errorf("parsing %s: %w", src, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("parsing %s: %w", src, err)
+	}
+	for _, key := range userOnlySettings {
+		if _, ok := raw[key]; ok {
+			return fmt.Errorf("%s: %s can only be set in the user settings", src, key)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	// Copy the file as written rather than re-encoding it, so settings left
+	// out keep falling through to the user level.
+	return os.WriteFile(dst, data, 0644)
+}
+
+// runConfigCommand implements "guget config export|import|set|unset".
+// Export prints the effective settings (user overridden by project); import
+// copies a settings file into the project so it can be committed; set and
+// unset change one setting in the user settings file.
+func runConfigCommand(command string, operands []string, flags BuiltFlags, settings UserConfig) int {
+	root, err := filepath.Abs(flags.ProjectDir)
+	if err != nil {
+		logError("Couldn't get absolute path for project directory: %v", err)
+		return 1
+	}
+
+	switch command {
+	case "config export":
+		if flags.Output == "" {
+			if err := exportConfig(os.Stdout, settings); err != nil {
+				logError("%v", err)
+				return 1
+			}
+			return 0
+		}
+		f, err := os.Create(flags.Output)
+		if err != nil {
+			logError("%v", err)
+			return 1
+		}
+		err = exportConfig(f, settings)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			logError("Writing settings: %v", err)
+			return 1
+		}
+		if !flags.Quiet {
+			fmt.Printf("Exported settings to %s\n", flags.Output)
+		}
+
+	case "config import":
+		if flags.From == "" {
+			logError("guget config import needs --from <file>")
+			return 1
+		}
+		dst := flags.Output
+		if dst == "" {
+			dst = projectConfigPath(root)
+		}
+		if err := importConfig(flags.From, dst); err != nil {
+			logError("%v", err)
+			return 1
+		}
+		if flags.Quiet {
+			fmt.Println(dst)
+		} else {
+			fmt.Printf("Imported settings to %s\n", dst)
+		}
+
+	case "config set", "config unset":
+		if len(operands) < subcommandOperands[command] {
+			logError("usage: guget config set <setting> <value>, or guget config unset <setting>")
+			return 1
+		}
+		dst := flags.Output
+		if dst == "" {
+			dst = userConfigPath(os.Getenv)
+		}
+		if dst == "" {
+			logError("No user config directory; name a settings file with -out")
+			return 1
+		}
+		value := ""
+		if command == "config set" {
+			value = operands[1]
+		}
+		if err := setConfigValue(dst, operands[0], value, command == "config unset"); err != nil {
+			logError("%v", err)
+			return 1
+		}
+		if flags.Quiet {
+			fmt.Println(dst)
+		} else if command == "config set" {
+			fmt.Printf("Set %s in %s\n", operands[0], dst)
+		} else {
+			fmt.Printf("Unset %s in %s\n", operands[0], dst)
+		}
+
+	default:
+		logError("usage: guget config export|import|set|unset")
+		return 1
+	}
+	return 0
+}
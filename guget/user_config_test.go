@@ -0,0 +1,360 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadUserConfig_MissingFileUsesDefaults(t *testing.T) {
+	cfg, err := loadUserConfig(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, defaultUserConfig()) {
+		t.Errorf("cfg = %+v, want defaults", cfg)
+	}
+}
+
+func TestLoadUserConfig_OverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"disableBulkWrites": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadUserConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.DisableBulkWrites {
+		t.Error("expected disableBulkWrites to be read")
+	}
+	if cfg.BulkConfirmThreshold != defaultUserConfig().BulkConfirmThreshold {
+		t.Errorf("threshold = %d, want the default when omitted", cfg.BulkConfirmThreshold)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"bulkConfirmThreshold": 0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _ = loadUserConfig(path); cfg.BulkConfirmThreshold != 0 {
+		t.Errorf("threshold = %d, want an explicit 0 to be kept", cfg.BulkConfirmThreshold)
+	}
+}
+
+func TestLoadUserConfig_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{`), 0644)
+	cfg, err := loadUserConfig(path)
+	if err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+	if !reflect.DeepEqual(cfg, defaultUserConfig()) {
+		t.Errorf("cfg = %+v, want defaults on error", cfg)
+	}
+}
+
+func TestUserConfigPath_EnvOverride(t *testing.T) {
+	getenv := func(k string) string {
+		if k == "GUGET_CONFIG" {
+			return "/etc/guget.json"
+		}
+		return ""
+	}
+	if got := userConfigPath(getenv); got != "/etc/guget.json" {
+		t.Errorf("userConfigPath = %q", got)
+	}
+}
+
+func TestLoadConfigLayers_ProjectOverridesUser(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	project := filepath.Join(dir, "project.json")
+	os.WriteFile(user, []byte(`{"bulkConfirmThreshold": 3, "theme": "nord"}`), 0644)
+	os.WriteFile(project, []byte(`{"theme": "dracula"}`), 0644)
+
+	cfg, err := loadConfigLayers(user, filepath.Join(dir, "missing.json"), project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Theme != "dracula" {
+		t.Errorf("theme = %q, want the project value", cfg.Theme)
+	}
+	if cfg.BulkConfirmThreshold != 3 {
+		t.Errorf("threshold = %d, want the user value kept", cfg.BulkConfirmThreshold)
+	}
+}
+
+func TestLoadConfigLayers_RenamesMerge(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	project := filepath.Join(dir, "project.json")
+	os.WriteFile(user, []byte(`{"renames": {"Contoso.Old": "Contoso.New", "Contoso.Legacy": "Contoso.Core"}}`), 0644)
+	os.WriteFile(project, []byte(`{"renames": {"Contoso.Legacy": "Contoso.Next"}}`), 0644)
+
+	cfg, err := loadConfigLayers(user, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"Contoso.Old": "Contoso.New", "Contoso.Legacy": "Contoso.Next"}
+	if !reflect.DeepEqual(cfg.Renames, want) {
+		t.Errorf("renames = %v, want %v", cfg.Renames, want)
+	}
+}
+
+func TestImportConfig(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "team.json")
+	dst := projectConfigPath(filepath.Join(dir, "repo"))
+
+	os.WriteFile(src, []byte(`{"disableBulkWrites": true}`), 0644)
+	if err := importConfig(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadUserConfig(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.DisableBulkWrites {
+		t.Error("expected the imported setting to be written")
+	}
+
+	os.WriteFile(src, []byte(`{"disableBulkWrite": true}`), 0644)
+	if err := importConfig(src, dst); err == nil {
+		t.Error("expected an unknown field to be rejected")
+	}
+	os.WriteFile(src, []byte(`{"theme": "neon"}`), 0644)
+	if err := importConfig(src, dst); err == nil {
+		t.Error("expected an unknown theme to be rejected")
+	}
+	os.WriteFile(src, []byte(`{"credentialProviderTimeout": "a minute"}`), 0644)
+	if err := importConfig(src, dst); err == nil {
+		t.Error("expected an invalid credential provider timeout to be rejected")
+	}
+	os.WriteFile(src, []byte(`{"restoreParallelism": -2}`), 0644)
+	if err := importConfig(src, dst); err == nil {
+		t.Error("expected a negative restore parallelism to be rejected")
+	}
+	os.WriteFile(src, []byte(`{"include": ["src/*.proj"]}`), 0644)
+	if err := importConfig(src, dst); err == nil {
+		t.Error("expected an include pattern with a path to be rejected")
+	}
+	os.WriteFile(src, []byte(`{"webhook": "hooks.example.com/x"}`), 0644)
+	if err := importConfig(src, dst); err == nil {
+		t.Error("expected a webhook that is not an http URL to be rejected")
+	}
+	os.WriteFile(src, []byte(`{"webhookFormat": "discord"}`), 0644)
+	if err := importConfig(src, dst); err == nil {
+		t.Error("expected an unknown webhook format to be rejected")
+	}
+	os.WriteFile(src, []byte(`{"verbosity": "loud"}`), 0644)
+	if err := importConfig(src, dst); err == nil {
+		t.Error("expected an unknown verbosity to be rejected")
+	}
+	for _, body := range []string{
+		`{"webhook": "https://hooks.example.com/x"}`,
+		`{"githubAdvisories": true}`,
+		`{"credentialProviders": {"corp": "nuget-plugin-corp"}}`,
+	} {
+		os.WriteFile(src, []byte(body), 0644)
+		if err := importConfig(src, dst); err == nil || !strings.Contains(err.Error(), "user settings") {
+			t.Errorf("import %s: err = %v, want a user-only setting rejected", body, err)
+		}
+	}
+}
+
+func TestExportConfig_LeavesOutUserOnlySettings(t *testing.T) {
+	cfg := UserConfig{
+		Theme:               "nord",
+		Webhook:             "https://hooks.example.com/team",
+		WebhookFormat:       "slack",
+		GitHubAdvisories:    true,
+		CredentialProviders: map[string]string{"corp": "/opt/providers/corp"},
+	}
+	var out strings.Builder
+	if err := exportConfig(&out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range userOnlySettings {
+		if strings.Contains(out.String(), `"`+key+`"`) {
+			t.Errorf("export includes %s:\n%s", key, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), `"theme": "nord"`) {
+		t.Errorf("export lost other settings:\n%s", out.String())
+	}
+}
+
+func TestSetConfigValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guget", "config.json")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte(`{"renames": {"Contoso.Old": "Contoso.New"}}`), 0644)
+
+	for _, kv := range [][2]string{
+		{"theme", "nord"},
+		{"sortBy", "name:desc"},
+		{"prerelease", "true"},
+		{"showLogs", "true"},
+		{"projectsPanelOffset", "-8"},
+		{"include", "*.msbuildproj, *.sqlproj"},
+	} {
+		if err := setConfigValue(path, kv[0], kv[1], false); err != nil {
+			t.Fatalf("set %s: %v", kv[0], err)
+		}
+	}
+	cfg, err := loadUserConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Theme != "nord" || cfg.SortBy != "name:desc" || !cfg.Prerelease || !cfg.ShowLogs ||
+		cfg.ProjectsPanelOffset != -8 || !reflect.DeepEqual(cfg.Include, []string{"*.msbuildproj", "*.sqlproj"}) {
+		t.Errorf("settings after set = %+v", cfg)
+	}
+	if cfg.Renames["Contoso.Old"] != "Contoso.New" {
+		t.Error("set dropped a setting it didn't touch")
+	}
+
+	if err := setConfigValue(path, "theme", "", true); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _ := loadUserConfig(path); cfg.Theme != "" {
+		t.Errorf("theme after unset = %q", cfg.Theme)
+	}
+
+	for _, bad := range [][2]string{
+		{"colour", "red"},             // unknown setting
+		{"theme", "neon"},             // invalid value
+		{"showLogs", "sometimes"},     // not a bool
+		{"renames", "Contoso.A=B"},    // a map
+		{"restoreParallelism", "two"}, // not a number
+	} {
+		if err := setConfigValue(path, bad[0], bad[1], false); err == nil {
+			t.Errorf("set %s %q: expected an error", bad[0], bad[1])
+		}
+	}
+}
+
+func TestLoadConfigLayers_CredentialProviders(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	project := filepath.Join(dir, "project.json")
+	os.WriteFile(user, []byte(`{"credentialProviderTimeout": "90s", "credentialProviders": {"corp": "CredentialProvider.Microsoft", "tools": "nuget-plugin-tools"}}`), 0644)
+	os.WriteFile(project, []byte(`{"credentialProviders": {"tools": "nuget-plugin-artifactory"}}`), 0644)
+
+	cfg, err := loadConfigLayers(user, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"corp": "CredentialProvider.Microsoft", "tools": "nuget-plugin-artifactory"}
+	if !reflect.DeepEqual(cfg.CredentialProviders, want) {
+		t.Errorf("credentialProviders = %v, want %v", cfg.CredentialProviders, want)
+	}
+	if d, err := cfg.credentialTimeout(); err != nil || d != 90*time.Second {
+		t.Errorf("credentialTimeout() = %v, %v; want 90s", d, err)
+	}
+}
+
+func TestLoadSettings_ProjectCannotRelaxUserSettings(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	t.Setenv("GUGET_CONFIG", user)
+	os.WriteFile(user, []byte(`{
+  "disableBulkWrites": true,
+  "bulkConfirmThreshold": 20,
+  "webhook": "https://hooks.example.com/team",
+  "credentialProviders": {"corp": "/opt/providers/corp-provider"}
+}`), 0644)
+	repo := filepath.Join(dir, "repo")
+	mustWriteFile(t, projectConfigPath(repo), `{
+  "disableBulkWrites": false,
+  "bulkConfirmThreshold": 1000,
+  "webhook": "https://attacker.example.com/collect",
+  "webhookFormat": "slack",
+  "credentialProviders": {"corp": "./tools/x", "other": "nuget-plugin-other"},
+  "theme": "nord"
+}`)
+
+	cfg := loadSettings(repo)
+	t.Run("disableBulkWrites", func(t *testing.T) {
+		if !cfg.DisableBulkWrites {
+			t.Error("the project must not turn off the user's lock")
+		}
+	})
+	t.Run("bulkConfirmThreshold", func(t *testing.T) {
+		if cfg.BulkConfirmThreshold != 20 {
+			t.Errorf("threshold = %d, want the user's lower 20", cfg.BulkConfirmThreshold)
+		}
+	})
+	t.Run("webhook", func(t *testing.T) {
+		if cfg.Webhook != "https://hooks.example.com/team" || cfg.WebhookFormat != "" {
+			t.Errorf("webhook = %q (%q), want the user's", cfg.Webhook, cfg.WebhookFormat)
+		}
+	})
+	t.Run("credentialProviders", func(t *testing.T) {
+		if want := map[string]string{"corp": "/opt/providers/corp-provider"}; !reflect.DeepEqual(cfg.CredentialProviders, want) {
+			t.Errorf("credentialProviders = %v, want %v", cfg.CredentialProviders, want)
+		}
+	})
+	if cfg.Theme != "nord" {
+		t.Errorf("theme = %q, want other project settings still applied", cfg.Theme)
+	}
+}
+
+func TestLoadSettings_ProjectCanTightenBulkWrites(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GUGET_CONFIG", filepath.Join(dir, "missing.json"))
+	repo := filepath.Join(dir, "repo")
+	mustWriteFile(t, projectConfigPath(repo), `{"disableBulkWrites": true, "bulkConfirmThreshold": 3}`)
+
+	cfg := loadSettings(repo)
+	if !cfg.DisableBulkWrites || cfg.BulkConfirmThreshold != 3 {
+		t.Errorf("disableBulkWrites = %v, threshold = %d; want the project's stricter values", cfg.DisableBulkWrites, cfg.BulkConfirmThreshold)
+	}
+}
+
+func TestLimitProjectLayer_BulkConfirmThreshold(t *testing.T) {
+	tests := []struct {
+		name          string
+		user, project int
+		want          int
+	}{
+		{"project higher", 10, 1000, 10},
+		{"project lower", 10, 3, 3},
+		{"project off", 10, 0, 10},
+		{"user off", 0, 25, 25},
+		{"both off", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := limitProjectLayer(UserConfig{BulkConfirmThreshold: tt.user}, UserConfig{BulkConfirmThreshold: tt.project})
+			if got.BulkConfirmThreshold != tt.want {
+				t.Errorf("threshold = %d, want %d", got.BulkConfirmThreshold, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSettings_ProjectCannotEnableNugetOrg(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	t.Setenv("GUGET_CONFIG", user)
+	os.WriteFile(user, []byte(`{"disableNugetOrg": true}`), 0644)
+	repo := filepath.Join(dir, "repo")
+	mustWriteFile(t, projectConfigPath(repo), `{"disableNugetOrg": false}`)
+
+	if cfg := loadSettings(repo); !cfg.DisableNugetOrg {
+		t.Error("the project must not turn nuget.org back on")
+	}
+}
+
+func TestLoadSettings_ProjectCannotEnableGitHubAdvisories(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GUGET_CONFIG", filepath.Join(dir, "missing.json"))
+	repo := filepath.Join(dir, "repo")
+	mustWriteFile(t, projectConfigPath(repo), `{"githubAdvisories": true}`)
+
+	if cfg := loadSettings(repo); cfg.GitHubAdvisories {
+		t.Error("the project must not send package ids to GitHub for the user")
+	}
+}
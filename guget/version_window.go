@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// versionWindowMax is the version count above which a package's list is
+	// trimmed before it is kept for the session.
+	versionWindowMax = 100
+	// versionWindowStable is how many of the newest stable versions survive
+	// trimming.
+	versionWindowStable = 40
+)
+
+// versionUse is one project's view of a package: what it has installed and
+// what it targets. The window keeps every version these need to compute
+// status, fixes, and lag.
+type versionUse struct {
+	installed SemVer
+	targets   Set[TargetFramework]
+}
+
+// trimVersions shrinks p.Versions to a bounded window once it exceeds
+// versionWindowMax: the newest versionWindowStable stable versions, the newest
+// pre-release, each installed version, and each project's latest compatible
+// (stable and pre-release), latest that rule allows, and minimum fixed
+// version. The number dropped is recorded in p.TrimmedVersions so the full
+// list can be fetched again on demand.
+func (p *PackageInfo) trimVersions(rule PackageRule, uses []versionUse) {
+	if len(p.Versions) <= versionWindowMax {
+		return
+	}
+	keep := NewSet[string]()
+	for _, u := range uses {
+		keep.Add(u.installed.String())
+		keep.Add(p.Resolve(u.installed).String())
+		if v := p.LatestStableForFramework(u.targets); v != nil {
+			keep.Add(v.SemVer.String())
+		}
+		if v := p.LatestForFramework(u.targets, true); v != nil {
+			keep.Add(v.SemVer.String())
+		}
+		// A major-version rule judges the package by its newest release in
+		// that major, which may be far down the list.
+		if v := rule.latestAllowed(p, u.targets, false); v != nil {
+			keep.Add(v.SemVer.String())
+		}
+		if v := rule.latestAllowed(p, u.targets, true); v != nil {
+			keep.Add(v.SemVer.String())
+		}
+		if v := p.MinimumFixedVersion(u.installed, u.targets); v != nil {
+			keep.Add(v.SemVer.String())
+		}
+	}
+
+	kept := make([]PackageVersion, 0, versionWindowStable+len(uses)*5+1)
+	stable, pre := 0, false
+	for _, v := range p.Versions {
+		isPre := v.SemVer.IsPreRelease()
+		switch {
+		case !isPre && stable < versionWindowStable:
+			stable++
+		case isPre && !pre:
+			pre = true
+		case keep.Contains(v.SemVer.String()):
+		default:
+			continue
+		}
+		kept = append(kept, v)
+	}
+	p.TrimmedVersions += len(p.Versions) - len(kept)
+	p.Versions = kept
+}
+
+// hasVersions reports whether every version in want is in p.Versions.
+// Untrimmed packages always report true: a missing version there is a real
+// status (e.g. unlisted), not a trimming gap.
+func (p *PackageInfo) hasVersions(want []SemVer) bool {
+	if p.TrimmedVersions == 0 {
+		return true
+	}
+	have := NewSet[string]()
+	for _, v := range p.Versions {
+		have.Add(v.SemVer.String())
+	}
+	for _, v := range want {
+		if !have.Contains(v.String()) {
+			return false
+		}
+	}
+	return true
+}
+
+// refetchAllVersions reloads pkgName from the source it was first found on,
+// returning the untrimmed version list. With enrich, vulnerabilities are
+// merged from nuget.org as after the initial load; a package nuget.org
+// doesn't have is cross-checked with the GitHub Advisory Database when a
+// token is set.
+func refetchAllVersions(services []*NugetService, source, pkgName string, enrich bool) (*PackageInfo, error) {
+	var svc, nugetOrg *NugetService
+	for _, s := range services {
+		if strings.EqualFold(s.SourceName(), source) {
+			svc = s
+		}
+		if strings.EqualFold(s.SourceName(), "nuget.org") {
+			nugetOrg = s
+		}
+	}
+	if svc == nil {
+		return nil, fmt.Errorf("source %q is no longer configured", source)
+	}
+	info, err := svc.SearchExact(pkgName)
+	if err != nil {
+		return nil, err
+	}
+	if svc == nugetOrg {
+		return info, nil
+	}
+	if enrich && nugetOrg != nil {
+		if nugetInfo, err := nugetOrg.SearchExact(pkgName); err == nil {
+			enrichFromNugetOrg(info, nugetInfo)
+			return info, nil
+		}
+	}
+	if githubAdvisoryToken != "" {
+		if vulns, err := FetchGitHubVulnerabilities(pkgName); err == nil {
+			applyGitHubAdvisories(info, vulns)
+		}
+	}
+	return info, nil
+}
+
+// versionListRow is one line of a version list grouped by major version:
+// a group header, or the version at Index.
+type versionListRow struct {
+	Header bool
+	Major  int
+	Index  int // the version; for a header, the newest in its group
+	Count  int // versions in the group (headers only)
+}
+
+// groupVersionsByMajor lays the versions show accepts (all of them when
+// show is nil) out under one header per major version, in list order,
+// listing only the versions of the majors in expanded. A list with a single
+// major version is returned flat.
+func groupVersionsByMajor(versions []PackageVersion, expanded Set[int], show func(PackageVersion) bool) []versionListRow {
+	if show == nil {
+		show = func(PackageVersion) bool { return true }
+	}
+	counts := make(map[int]int)
+	var majors []int
+	for _, v := range versions {
+		if !show(v) {
+			continue
+		}
+		if counts[v.SemVer.Major] == 0 {
+			majors = append(majors, v.SemVer.Major)
+		}
+		counts[v.SemVer.Major]++
+	}
+	grouped := len(majors) > 1
+	var rows []versionListRow
+	for _, major := range majors {
+		if grouped {
+			first := slices.IndexFunc(versions, func(v PackageVersion) bool { return v.SemVer.Major == major && show(v) })
+			rows = append(rows, versionListRow{Header: true, Major: major, Index: first, Count: counts[major]})
+			if !expanded.Contains(major) {
+				continue
+			}
+		}
+		for i, v := range versions {
+			if v.SemVer.Major == major && show(v) {
+				rows = append(rows, versionListRow{Major: major, Index: i})
+			}
+		}
+	}
+	return rows
+}
+
+// versionFilterMatches reports whether version matches the version picker's
+// filter: as a prefix ("6.0" finds 6.0.x but not 16.0.0), or anywhere for a
+// query that doesn't start with a digit, such as "rc" or "preview".
+func versionFilterMatches(version, query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+	version = strings.ToLower(version)
+	if query[0] >= '0' && query[0] <= '9' {
+		return strings.HasPrefix(version, query)
+	}
+	return strings.Contains(version, query)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func manyVersions(n int) []PackageVersion {
+	// Newest first: 1.(n-1).0 … 1.0.0, with a pre-release on top.
+	versions := []PackageVersion{{SemVer: ParseSemVer(fmt.Sprintf("1.%d.0-beta", n))}}
+	for i := n - 1; i >= 0; i-- {
+		versions = append(versions, PackageVersion{SemVer: ParseSemVer(fmt.Sprintf("1.%d.0", i))})
+	}
+	return versions
+}
+
+func TestTrimVersions_SmallListUntouched(t *testing.T) {
+	p := &PackageInfo{Versions: manyVersions(versionWindowMax - 1)}
+	p.trimVersions(PackageRule{}, nil)
+	if p.TrimmedVersions != 0 || len(p.Versions) != versionWindowMax {
+		t.Errorf("got %d versions, %d trimmed; want untouched", len(p.Versions), p.TrimmedVersions)
+	}
+}
+
+func TestTrimVersions_KeepsWindow(t *testing.T) {
+	const total = 1000
+	p := &PackageInfo{Versions: manyVersions(total)}
+	installed := ParseSemVer("1.3.0")
+	// 1.3.0 and 1.4.0 are vulnerable, so 1.5.0 is the smallest fix.
+	for i := range p.Versions {
+		if s := p.Versions[i].SemVer.String(); s == "1.3.0" || s == "1.4.0" {
+			p.Versions[i].Vulnerabilities = []PackageVulnerability{{Severity: 2}}
+		}
+	}
+
+	p.trimVersions(PackageRule{}, []versionUse{{installed: installed}})
+
+	kept := NewSet[string]()
+	for _, v := range p.Versions {
+		kept.Add(v.SemVer.String())
+	}
+	for _, want := range []string{"1.1000.0-beta", "1.999.0", fmt.Sprintf("1.%d.0", total-versionWindowStable), "1.3.0", "1.5.0"} {
+		if !kept.Contains(want) {
+			t.Errorf("expected %s to be kept", want)
+		}
+	}
+	if kept.Contains("1.500.0") {
+		t.Error("expected versions outside the window to be dropped")
+	}
+	if got, want := len(p.Versions)+p.TrimmedVersions, total+1; got != want {
+		t.Errorf("kept+trimmed = %d, want %d", got, want)
+	}
+	for i := 1; i < len(p.Versions); i++ {
+		if p.Versions[i].SemVer.IsNewerThan(p.Versions[i-1].SemVer) {
+			t.Fatal("expected newest-first order to be preserved")
+		}
+	}
+}
+
+func TestTrimVersions_KeepsLatestAllowedByMajorRule(t *testing.T) {
+	// 7.0.0 … 7.199.0 on top of a 6.x line, with a 6.x pre-release.
+	var versions []PackageVersion
+	for i := 199; i >= 0; i-- {
+		versions = append(versions, PackageVersion{SemVer: ParseSemVer(fmt.Sprintf("7.%d.0", i))})
+	}
+	versions = append(versions,
+		PackageVersion{SemVer: ParseSemVer("6.9.0-rc.1")},
+		PackageVersion{SemVer: ParseSemVer("6.8.0")},
+		PackageVersion{SemVer: ParseSemVer("6.1.0")},
+	)
+	p := &PackageInfo{Versions: versions}
+	six := 6
+
+	p.trimVersions(PackageRule{Major: &six}, []versionUse{{installed: ParseSemVer("6.1.0")}})
+
+	kept := NewSet[string]()
+	for _, v := range p.Versions {
+		kept.Add(v.SemVer.String())
+	}
+	for _, want := range []string{"6.8.0", "6.9.0-rc.1", "6.1.0"} {
+		if !kept.Contains(want) {
+			t.Errorf("expected %s, the rule's latest or installed, to be kept", want)
+		}
+	}
+}
+
+func TestHasVersions(t *testing.T) {
+	p := &PackageInfo{Versions: manyVersions(3)}
+	missing := []SemVer{ParseSemVer("9.9.9")}
+	if !p.hasVersions(missing) {
+		t.Error("an untrimmed package should always report true")
+	}
+	p.TrimmedVersions = 10
+	if p.hasVersions(missing) {
+		t.Error("expected a trimmed package to report the missing version")
+	}
+	if !p.hasVersions([]SemVer{ParseSemVer("1.2.0")}) {
+		t.Error("expected a kept version to be found")
+	}
+}
+
+func TestGroupVersionsByMajor(t *testing.T) {
+	var versions []PackageVersion
+	for _, v := range []string{"8.0.1", "8.0.0", "7.0.2", "7.0.1", "7.0.0", "6.0.0"} {
+		versions = append(versions, PackageVersion{SemVer: ParseSemVer(v)})
+	}
+	expanded := NewSet[int]()
+	expanded.Add(7)
+	var got []string
+	for _, r := range groupVersionsByMajor(versions, expanded, nil) {
+		if r.Header {
+			got = append(got, fmt.Sprintf("%d.x(%d) newest %s", r.Major, r.Count, versions[r.Index].SemVer))
+		} else {
+			got = append(got, versions[r.Index].SemVer.String())
+		}
+	}
+	want := []string{"8.x(2) newest 8.0.1", "7.x(3) newest 7.0.2", "7.0.2", "7.0.1", "7.0.0", "6.x(1) newest 6.0.0"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+
+	if rows := groupVersionsByMajor(versions[2:5], NewSet[int](), nil); len(rows) != 3 || rows[0].Header {
+		t.Errorf("a single major should list flat, got %+v", rows)
+	}
+}
+
+func TestVersionFilterMatches(t *testing.T) {
+	tests := []struct {
+		version, query string
+		want           bool
+	}{
+		{"6.0.36", "6.0", true},
+		{"16.0.1", "6.0", false},
+		{"8.0.0-rc.2", "RC", true},
+		{"8.0.0", "preview", false},
+		{"8.0.0", " ", true},
+	}
+	for _, tt := range tests {
+		if got := versionFilterMatches(tt.version, tt.query); got != tt.want {
+			t.Errorf("versionFilterMatches(%q, %q) = %v, want %v", tt.version, tt.query, got, tt.want)
+		}
+	}
+
+	var versions []PackageVersion
+	for _, v := range []string{"7.0.0", "6.1.0", "6.0.1", "5.0.0"} {
+		versions = append(versions, PackageVersion{SemVer: ParseSemVer(v)})
+	}
+	expanded := NewSet[int]()
+	expanded.Add(6)
+	rows := groupVersionsByMajor(versions, expanded, func(v PackageVersion) bool {
+		return versionFilterMatches(v.SemVer.String(), "6.0")
+	})
+	if len(rows) != 1 || versions[rows[0].Index].SemVer.String() != "6.0.1" {
+		t.Errorf("filtered rows = %+v, want only 6.0.1, ungrouped", rows)
+	}
+}
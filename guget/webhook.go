@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// webhookFormats are the bodies --webhook can be sent: guget's own JSON
+// with the whole report, or a chat message for a Slack or Microsoft Teams
+// incoming webhook.
+var webhookFormats = []string{"json", "slack", "teams"}
+
+// webhookPayload is the "json" body POSTed to --webhook.
+type webhookPayload struct {
+	Event   string        `json:"event"` // always "guget.report"
+	Summary reportSummary `json:"summary"`
+	Changes reportChanges `json:"changes"`
+	Report  packageReport `json:"report"`
+}
+
+// reportChanges is what a report found that the previous one did not.
+type reportChanges struct {
+	Advisories []newAdvisory    `json:"newAdvisories"`
+	Majors     []newMajorUpdate `json:"newMajorUpdates"`
+}
+
+// newAdvisory is an advisory against an installed version, with the
+// projects it newly affects.
+type newAdvisory struct {
+	Package   string   `json:"package"`
+	Installed string   `json:"installed"`
+	Severity  string   `json:"severity"`
+	URL       string   `json:"url"`
+	Projects  []string `json:"projects"`
+}
+
+// newMajorUpdate is a compatible version a major version ahead of the
+// installed one, with the projects that newly have it available.
+type newMajorUpdate struct {
+	Package   string   `json:"package"`
+	Installed string   `json:"installed"`
+	Latest    string   `json:"latest"`
+	Projects  []string `json:"projects"`
+}
+
+func (c reportChanges) empty() bool { return len(c.Advisories) == 0 && len(c.Majors) == 0 }
+
+// majorUpdate reports whether st can move to a newer major version.
+func (st packageStatus) majorUpdate() bool {
+	return st.Outdated && ParseSemVer(st.LatestCompatible).Major > ParseSemVer(st.Installed).Major
+}
+
+// diffReports lists the advisories and major updates in cur that prev did
+// not have. With no previous report, everything in cur is new.
+func diffReports(prev *packageReport, cur packageReport) reportChanges {
+	type advisoryKey struct{ project, pkg, installed, url string }
+	type majorKey struct {
+		project, pkg string
+		major        int
+	}
+	seenAdvisories := NewSet[advisoryKey]()
+	seenMajors := NewSet[majorKey]()
+	if prev != nil {
+		for _, st := range prev.Packages {
+			pkg := strings.ToLower(st.Package)
+			for _, a := range st.Advisories {
+				seenAdvisories.Add(advisoryKey{st.ProjectPath, pkg, st.Installed, a.URL})
+			}
+			if st.majorUpdate() {
+				seenMajors.Add(majorKey{st.ProjectPath, pkg, ParseSemVer(st.LatestCompatible).Major})
+			}
+		}
+	}
+
+	var changes reportChanges
+	for _, st := range cur.Packages {
+		pkg := strings.ToLower(st.Package)
+		for _, a := range st.Advisories {
+			if seenAdvisories.Contains(advisoryKey{st.ProjectPath, pkg, st.Installed, a.URL}) {
+				continue
+			}
+			i := slices.IndexFunc(changes.Advisories, func(n newAdvisory) bool {
+				return strings.EqualFold(n.Package, st.Package) && n.Installed == st.Installed && n.URL == a.URL
+			})
+			if i < 0 {
+				changes.Advisories = append(changes.Advisories, newAdvisory{Package: st.Package, Installed: st.Installed, Severity: a.Severity, URL: a.URL})
+				i = len(changes.Advisories) - 1
+			}
+			changes.Advisories[i].Projects = append(changes.Advisories[i].Projects, st.Project)
+		}
+		if st.majorUpdate() && !seenMajors.Contains(majorKey{st.ProjectPath, pkg, ParseSemVer(st.LatestCompatible).Major}) {
+			i := slices.IndexFunc(changes.Majors, func(n newMajorUpdate) bool {
+				return strings.EqualFold(n.Package, st.Package) && n.Installed == st.Installed && n.Latest == st.LatestCompatible
+			})
+			if i < 0 {
+				changes.Majors = append(changes.Majors, newMajorUpdate{Package: st.Package, Installed: st.Installed, Latest: st.LatestCompatible})
+				i = len(changes.Majors) - 1
+			}
+			changes.Majors[i].Projects = append(changes.Majors[i].Projects, st.Project)
+		}
+	}
+	severities := []string{"critical", "high", "moderate", "low"}
+	slices.SortStableFunc(changes.Advisories, func(a, b newAdvisory) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(severities, a.Severity), slices.Index(severities, b.Severity)),
+			cmp.Compare(strings.ToLower(a.Package), strings.ToLower(b.Package)),
+		)
+	})
+	slices.SortStableFunc(changes.Majors, func(a, b newMajorUpdate) int {
+		return cmp.Compare(strings.ToLower(a.Package), strings.ToLower(b.Package))
+	})
+	return changes
+}
+
+// chatMessageLines caps how many advisories and updates a chat message
+// lists; the rest are counted.
+const chatMessageLines = 20
+
+// chatMessage words changes for a chat webhook. bold and link apply the
+// chat's own markup, and lines are joined with sep.
+func chatMessage(root string, changes reportChanges, bold func(string) string, link func(text, url string) string, sep string) string {
+	var parts []string
+	if n := len(changes.Advisories); n > 0 {
+		parts = append(parts, plural(n, "new advisory", "new advisories"))
+	}
+	if n := len(changes.Majors); n > 0 {
+		parts = append(parts, plural(n, "new major version", "new major versions"))
+	}
+	lines := []string{bold("guget") + ": " + strings.Join(parts, " and ") + " in " + root}
+
+	shown := 0
+	for _, a := range changes.Advisories {
+		if shown == chatMessageLines {
+			break
+		}
+		shown++
+		lines = append(lines, fmt.Sprintf("• %s %s %s (%s) — %s",
+			bold(a.Severity), a.Package, a.Installed, strings.Join(a.Projects, ", "), link("advisory", a.URL)))
+	}
+	for _, u := range changes.Majors {
+		if shown == chatMessageLines {
+			break
+		}
+		shown++
+		lines = append(lines, fmt.Sprintf("• %s %s → %s (%s)", u.Package, u.Installed, bold(u.Latest), strings.Join(u.Projects, ", ")))
+	}
+	if rest := len(changes.Advisories) + len(changes.Majors) - shown; rest > 0 {
+		lines = append(lines, fmt.Sprintf("… and %d more in the report", rest))
+	}
+	return strings.Join(lines, sep)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// webhookBody builds the body to send in format. ok is false for a chat
+// format when nothing changed, so a quiet run posts nothing.
+func webhookBody(format string, rep packageReport, changes reportChanges) (body any, ok bool) {
+	switch format {
+	case "slack":
+		if changes.empty() {
+			return nil, false
+		}
+		text := chatMessage(rep.Root, changes,
+			func(s string) string { return "*" + s + "*" },
+			func(text, url string) string { return "<" + url + "|" + text + ">" },
+			"\n")
+		return map[string]string{"text": text}, true
+	case "teams":
+		if changes.empty() {
+			return nil, false
+		}
+		text := chatMessage(rep.Root, changes,
+			func(s string) string { return "**" + s + "**" },
+			func(text, url string) string { return "[" + text + "](" + url + ")" },
+			"\n\n")
+		return map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  "guget dependency report",
+			"text":     text,
+		}, true
+	}
+	return webhookPayload{Event: "guget.report", Summary: summarizeReport(rep), Changes: changes, Report: rep}, true
+}
+
+// notifyWebhook posts rep to target in format, with what changed since
+// prev (everything, when prev is nil).
+func notifyWebhook(target, format string, prev *packageReport, rep packageReport) error {
+	body, ok := webhookBody(format, rep, diffReports(prev, rep))
+	if !ok {
+		logDebug("Nothing new since the last report; webhook not called")
+		return nil
+	}
+	return postWebhook(target, body)
+}
+
+// announcedReportPath names the file keeping the last report a webhook was
+// told about, next to the report at output: report.json becomes
+// report.announced.json. Returns "" when output is.
+func announcedReportPath(output string) string {
+	if output == "" {
+		return ""
+	}
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".announced.json"
+}
+
+// announceReport posts rep to target like notifyWebhook and, once the post
+// went through, keeps rep at baseline (when set) for the next run to compare
+// with. A failed post leaves the baseline alone, so its findings are sent
+// again next time even across a restart.
+func announceReport(target, format, baseline string, prev *packageReport, rep packageReport) error {
+	if err := notifyWebhook(target, format, prev, rep); err != nil {
+		return err
+	}
+	if baseline != "" {
+		if _, err := saveReport("", baseline, "json", rep); err != nil {
+			logWarn("Saving the webhook baseline: %v", err)
+		}
+	}
+	return nil
+}
+
+// postWebhook sends body to target as JSON. Any 2xx answer counts as
+// delivered.
+func postWebhook(target string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "guget/"+version)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if text := strings.TrimSpace(string(msg)); text != "" {
+			return fmt.Errorf("webhook returned HTTP %d: %s", resp.StatusCode, text)
+		}
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// validateWebhookURL accepts "" (no webhook) and absolute http(s) URLs.
+func validateWebhookURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook must be an http or https URL, got %q", raw)
+	}
+	return nil
+}
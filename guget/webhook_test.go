@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffReports(t *testing.T) {
+	rep := reportTestFixture(t)
+	rep.Packages = append(rep.Packages, packageStatus{
+		Project: "A.csproj", ProjectPath: "/src/A/A.csproj", Package: "Serilog",
+		Installed: "2.10.0", LatestCompatible: "3.1.1", Outdated: true,
+	}, packageStatus{
+		Project: "B.csproj", ProjectPath: "/src/B/B.csproj", Package: "Serilog",
+		Installed: "2.10.0", LatestCompatible: "3.1.1", Outdated: true,
+	})
+
+	first := diffReports(nil, rep)
+	if len(first.Advisories) != 1 || first.Advisories[0].Severity != "critical" {
+		t.Errorf("advisories = %+v, want the critical one", first.Advisories)
+	}
+	// Old|Lib 1.0.0 → 1.1.0 is not a major update.
+	if len(first.Majors) != 1 || first.Majors[0].Package != "Serilog" || strings.Join(first.Majors[0].Projects, ",") != "A.csproj,B.csproj" {
+		t.Errorf("majors = %+v, want Serilog in both projects", first.Majors)
+	}
+
+	if again := diffReports(&rep, rep); !again.empty() {
+		t.Errorf("nothing changed, got %+v", again)
+	}
+
+	next := rep
+	next.Packages = append([]packageStatus(nil), rep.Packages...)
+	next.Packages[len(next.Packages)-1].LatestCompatible = "4.0.0"
+	changes := diffReports(&rep, next)
+	if len(changes.Advisories) != 0 || len(changes.Majors) != 1 || changes.Majors[0].Latest != "4.0.0" {
+		t.Errorf("changes = %+v, want only the move to 4.0.0", changes)
+	}
+}
+
+func TestWebhookBody_Chat(t *testing.T) {
+	rep := reportTestFixture(t)
+	changes := diffReports(nil, rep)
+
+	body, ok := webhookBody("slack", rep, changes)
+	text := body.(map[string]string)["text"]
+	if !ok || !strings.Contains(text, "1 new advisory") || !strings.Contains(text, "*critical* Old|Lib 1.0.0 (A.csproj) — <https://github.com/advisories/GHSA-1|advisory>") {
+		t.Errorf("slack text:\n%s", text)
+	}
+	body, _ = webhookBody("teams", rep, changes)
+	card := body.(map[string]string)
+	if card["@type"] != "MessageCard" || !strings.Contains(card["text"], "[advisory](https://github.com/advisories/GHSA-1)") {
+		t.Errorf("teams card = %v", card)
+	}
+
+	if _, ok := webhookBody("slack", rep, reportChanges{}); ok {
+		t.Error("a chat message was built with nothing new")
+	}
+	if _, ok := webhookBody("json", rep, reportChanges{}); !ok {
+		t.Error("the json body should always be sent")
+	}
+}
+
+func TestPostWebhook(t *testing.T) {
+	var got webhookPayload
+	var contentType string
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&got)
+		if status != http.StatusNoContent {
+			http.Error(w, "bad token", status)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	rep := reportTestFixture(t)
+	if err := notifyWebhook(srv.URL, "json", nil, rep); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "application/json" || got.Event != "guget.report" || got.Summary.Vulnerable != 1 || len(got.Changes.Advisories) != 1 || len(got.Report.Packages) != 2 {
+		t.Errorf("webhook got %s %+v", contentType, got)
+	}
+
+	status = http.StatusUnauthorized
+	if err := notifyWebhook(srv.URL, "json", nil, rep); err == nil || !strings.Contains(err.Error(), "401: bad token") {
+		t.Errorf("err = %v, want the webhook's own reason", err)
+	}
+}
+
+func TestAnnounceReport_FailedPostSurvivesRestart(t *testing.T) {
+	var posts []webhookPayload
+	failing := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var got webhookPayload
+		json.NewDecoder(r.Body).Decode(&got)
+		posts = append(posts, got)
+	}))
+	defer srv.Close()
+
+	output := filepath.Join(t.TempDir(), "report.json")
+	baseline := announcedReportPath(output)
+	rep := reportTestFixture(t)
+
+	// A run writes its report, then the post fails.
+	if _, err := saveReport("", output, "json", rep); err != nil {
+		t.Fatal(err)
+	}
+	if err := announceReport(srv.URL, "json", baseline, previousReport(baseline), rep); err == nil {
+		t.Fatal("want the failed post reported")
+	}
+
+	// After a restart the report at --output must not count as announced.
+	failing = false
+	prev := previousReport(baseline)
+	if prev != nil {
+		t.Fatalf("baseline = %+v after a failed post, want none", prev)
+	}
+	if err := announceReport(srv.URL, "json", baseline, prev, rep); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || len(posts[0].Changes.Advisories) != 1 {
+		t.Fatalf("posts = %+v, want the unannounced advisory", posts)
+	}
+
+	// Once announced, the next restart compares with it.
+	prev = previousReport(baseline)
+	if prev == nil {
+		t.Fatal("no baseline after a successful post")
+	}
+	if err := announceReport(srv.URL, "json", baseline, prev, rep); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 2 || len(posts[1].Changes.Advisories) != 0 {
+		t.Errorf("second post = %+v, want no new advisories", posts[len(posts)-1].Changes)
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	for raw, ok := range map[string]bool{
+		"":                            true,
+		"https://hooks.example.com/x": true,
+		"http://localhost:8080/hook":  true,
+		"ftp://example.com/x":         false,
+		"hooks.example.com/x":         false,
+		"https://":                    false,
+	} {
+		if err := validateWebhookURL(raw); (err == nil) != ok {
+			t.Errorf("validateWebhookURL(%q) = %v", raw, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A workspace file lists repository roots to load together, so one session
+// can manage dependencies across several repositories:
+//
+//	{
+//	  "repos": [
+//	    { "path": "../payments" },
+//	    { "path": "../identity", "name": "auth" }
+//	  ]
+//	}
+//
+// Paths are relative to the file. Each repository is loaded as if guget were
+// pointed at it — its own solution when it has exactly one, otherwise every
+// project under it — and its projects are grouped under its name.
+const workspaceFileName = "guget-workspace.json"
+
+type workspaceFile struct {
+	Repos []workspaceRepo `json:"repos"`
+}
+
+type workspaceRepo struct {
+	Path string `json:"path"`
+	Name string `json:"name,omitempty"` // defaults to the directory name
+}
+
+// isWorkspaceFile reports whether path names a workspace file:
+// guget-workspace.json, or any file ending in .guget-workspace.json.
+func isWorkspaceFile(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return name == workspaceFileName || strings.HasSuffix(name, "."+workspaceFileName)
+}
+
+// parseWorkspaceFile reads a workspace file and returns its repositories
+// with absolute paths and unique names. Repositories that do not exist are
+// skipped with a warning.
+func parseWorkspaceFile(path string) ([]workspaceRepo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var wf workspaceFile
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&wf); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Base(path), err)
+	}
+
+	dir := filepath.Dir(path)
+	var repos []workspaceRepo
+	names := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, r := range wf.Repos {
+		if strings.TrimSpace(r.Path) == "" {
+			continue
+		}
+		p := filepath.FromSlash(r.Path)
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		p = filepath.Clean(p)
+		if info, err := os.Stat(p); err != nil || !info.IsDir() {
+			logWarn("Workspace %s lists %s, which is not a directory", filepath.Base(path), r.Path)
+			continue
+		}
+		if seen[projectPathKey(p)] {
+			continue
+		}
+		seen[projectPathKey(p)] = true
+
+		name := strings.TrimSpace(r.Name)
+		if name == "" {
+			name = filepath.Base(p)
+		}
+		for base, n := name, 2; names[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s (%d)", base, n)
+		}
+		names[strings.ToLower(name)] = true
+		repos = append(repos, workspaceRepo{Path: p, Name: name})
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("%s lists no repository that exists", filepath.Base(path))
+	}
+	return repos, nil
+}
+
+// findRootWorkspace returns the only workspace file directly in dir, or ""
+// when there is none or more than one to choose from.
+func findRootWorkspace(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var found []string
+	for _, e := range entries {
+		if !e.IsDir() && isWorkspaceFile(e.Name()) {
+			found = append(found, filepath.Join(dir, e.Name()))
+		}
+	}
+	if len(found) > 1 {
+		logInfo("Found %d workspace files in %s; pass --project <file> to pick one", len(found), dir)
+		return ""
+	}
+	if len(found) == 1 {
+		return found[0]
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDiscoverProjects_Workspace(t *testing.T) {
+	root := writeSolutionTree(t,
+		"payments/src/App/App.csproj", "payments/Tool.fsproj", "payments/samples/Demo/Demo.csproj",
+		"identity/Auth/Auth.csproj",
+	)
+	os.WriteFile(filepath.Join(root, "payments", "Payments.sln"), []byte(testSln), 0644)
+	ws := filepath.Join(root, "work", "guget-workspace.json")
+	mustWriteFile(t, ws, `{"repos": [
+		{"path": "../payments"},
+		{"path": "../identity", "name": "auth"},
+		{"path": "../missing"},
+		{"path": "../payments"}
+	]}`)
+
+	d, err := DiscoverProjects(filepath.Join(root, "work"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Workspace != ws || d.Solution != "" {
+		t.Errorf("Workspace = %q, Solution = %q; want the workspace file and no solution", d.Workspace, d.Solution)
+	}
+	app := filepath.Join(root, "payments", "src", "App", "App.csproj")
+	auth := filepath.Join(root, "identity", "Auth", "Auth.csproj")
+	want := []string{app, filepath.Join(root, "payments", "Tool.fsproj"), auth}
+	if !slices.Equal(d.Files, want) {
+		t.Fatalf("Files = %v, want %v", d.Files, want)
+	}
+	if d.Repos[app] != "payments" || d.Folders[app] != "src" {
+		t.Errorf("App: repo %q, folder %q; want payments, src", d.Repos[app], d.Folders[app])
+	}
+	if d.Repos[auth] != "auth" {
+		t.Errorf("Auth repo = %q, want auth", d.Repos[auth])
+	}
+	wantRoots := []string{ws, filepath.Join(root, "payments"), filepath.Join(root, "identity")}
+	if !slices.Equal(d.Roots, wantRoots) {
+		t.Errorf("Roots = %v, want %v", d.Roots, wantRoots)
+	}
+
+	if dir, file := splitSolutionArg(ws); dir != filepath.Dir(ws) || file != ws {
+		t.Errorf("splitSolutionArg(%q) = %q, %q", ws, dir, file)
+	}
+}
+
+func TestParseWorkspaceFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"empty":   `{"repos": []}`,
+		"missing": `{"repos": [{"path": "nope"}]}`,
+		"unknown": `{"repositories": [{"path": "."}]}`,
+		"invalid": `{"repos": [`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name+".guget-workspace.json")
+			mustWriteFile(t, path, body)
+			if _, err := parseWorkspaceFile(path); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseWorkspaceFile_UniqueNames(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"a/core", "b/core"} {
+		os.MkdirAll(filepath.Join(root, filepath.FromSlash(d)), 0755)
+	}
+	path := filepath.Join(root, "team.guget-workspace.json")
+	mustWriteFile(t, path, `{"repos": [{"path": "a/core"}, {"path": "b/core"}]}`)
+
+	repos, err := parseWorkspaceFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 2 || repos[0].Name != "core" || repos[1].Name != "core (2)" {
+		t.Errorf("repos = %+v, want core and core (2)", repos)
+	}
+}
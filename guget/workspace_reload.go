@@ -1,25 +1,37 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 )
 
 type workspaceSnapshot struct {
 	ProjectDir     string
+	Solution       string   // the .sln/.slnx the projects came from, if any
+	Roots          []string // paths to watch for changes
 	ParsedProjects []*ParsedProject
 	PropsProjects  []*ParsedProject
 	Sources        []NugetSource
-	SourceMapping  *PackageSourceMapping
+	SourceMapping  *PackageSourceMapping // the workspace root's
+	Scopes         sourceScopes          // per-project source sets, root first
+	PackageFolders PackageFolders
+	Conflicts      []SourceConflict
 	NugetServices  []*NugetService
+	SourceFailures []sourceFailure // sources left out because they could not be reached
+	SDKs           []DotnetSDK     // installed .NET SDKs, newest first
+	SDKErr         error           // why SDKs could not be listed
 }
 
-func loadWorkspace(projectDir string) (*workspaceSnapshot, error) {
+// loadWorkspace parses the projects under projectDir, or only those listed in
+// solution when it is set, and connects to their NuGet sources.
+func loadWorkspace(projectDir, solution string) (*workspaceSnapshot, error) {
 	fullProjectPath, err := filepath.Abs(projectDir)
 	if err != nil {
 		return nil, fmt.Errorf("getting absolute project directory: %w", err)
@@ -27,46 +39,60 @@ func loadWorkspace(projectDir string) (*workspaceSnapshot, error) {
 
 	logInfo("Scanning workspace: %s", fullProjectPath)
 
-	projectFiles, err := FindProjectFiles(fullProjectPath)
+	discovery, err := DiscoverProjects(fullProjectPath, solution)
 	if err != nil {
 		return nil, fmt.Errorf("finding projects: %w", err)
 	}
+	projectFiles := discovery.Files
 	logInfo("Found %d project(s)", len(projectFiles))
 
+	if len(projectFiles) == 0 {
+		return nil, fmt.Errorf("no project files (%s) found in: %s", projectFileKinds(), fullProjectPath)
+	}
+
 	var parsedProjects []*ParsedProject
 	for _, file := range projectFiles {
 		project, err := ParseCsproj(file)
 		if err != nil {
-			logWarn("Skipping unparseable project %s: %v", file, err)
-			continue
+			// Keep the project in the list with its error rather than
+			// dropping it, so one bad file doesn't hide itself or the rest.
+			logWarn("Could not parse project %s: %v", file, err)
+			project = newBrokenProject(file, err)
 		}
+		project.SolutionFolder = discovery.Folders[file]
+		project.Repo = discovery.Repos[file]
 		parsedProjects = append(parsedProjects, project)
 	}
 
-	if len(parsedProjects) == 0 {
-		return nil, fmt.Errorf("no parseable .csproj, .fsproj, or .vbproj files found in: %s", fullProjectPath)
+	sdks, sdkErr := detectSDKs()
+	if sdkErr != nil {
+		logWarn("Could not list .NET SDKs: %v", sdkErr)
+	} else {
+		logDebug("Found %d .NET SDK(s), newest %s", len(sdks), sdks[0].Version)
+		checkProjectSDKs(sdks, parsedProjects)
 	}
 
 	propsProjects := collectPropsProjects(parsedProjects)
 	logInfo("Found %d .props file(s) with packages", len(propsProjects))
 
 	detected := DetectSources(fullProjectPath)
-	sources := detected.Sources
 	sourceMapping := detected.Mapping
-	logInfo("Detected %d NuGet source(s)", len(sources))
+	logInfo("Detected %d NuGet source(s)", len(detected.Sources))
 	if sourceMapping.IsConfigured() {
 		logInfo("Package source mapping configured with %d source(s)", len(sourceMapping.Entries))
 	}
+	logDebug("Global packages folder: %s (%d fallback folder(s))", detected.Folders.Global, len(detected.Folders.Fallback))
 
-	var nugetServices []*NugetService
-	for _, src := range sources {
-		svc, err := NewNugetService(src)
-		if err != nil {
-			logWarn("Failed to initialise NuGet source [%s]: %v", src.Name, err)
-			continue
-		}
-		nugetServices = append(nugetServices, svc)
+	scopes := buildSourceScopes(detected, parsedProjects, DetectSources)
+	if len(scopes) > 1 {
+		logInfo("Projects resolve %d different nuget.config chains; sources are chosen per project", len(scopes))
 	}
+	conflicts := scopes.conflicts()
+	for _, c := range conflicts {
+		logWarn("Conflicting NuGet sources: %s", c.Explain())
+	}
+
+	nugetServices, sourceFailures := scopes.connect()
 	if len(nugetServices) == 0 {
 		return nil, fmt.Errorf("no reachable NuGet sources found")
 	}
@@ -74,11 +100,19 @@ func loadWorkspace(projectDir string) (*workspaceSnapshot, error) {
 
 	return &workspaceSnapshot{
 		ProjectDir:     fullProjectPath,
+		Solution:       discovery.Solution,
+		Roots:          discovery.Roots,
 		ParsedProjects: parsedProjects,
 		PropsProjects:  propsProjects,
-		Sources:        sources,
+		Sources:        scopes.sources(),
 		SourceMapping:  sourceMapping,
+		Scopes:         scopes,
+		PackageFolders: detected.Folders,
+		Conflicts:      conflicts,
 		NugetServices:  nugetServices,
+		SourceFailures: sourceFailures,
+		SDKs:           sdks,
+		SDKErr:         sdkErr,
 	}, nil
 }
 
@@ -170,9 +204,18 @@ func planPackageReload(snapshot *workspaceSnapshot, current map[string]nugetResu
 	next := make(map[string]nugetResult, len(names))
 	toFetch := make([]string, 0, len(names))
 
+	installed := make(map[string][]SemVer)
+	for _, p := range snapshot.ParsedProjects {
+		for ref := range p.Packages {
+			installed[ref.Name] = append(installed[ref.Name], ref.Version)
+		}
+	}
+
 	for _, name := range names {
 		if !invalidateAll {
-			if res, ok := current[name]; ok && res.pkg != nil && res.err == nil {
+			// A trimmed result is only reusable while it still holds every
+			// installed version (an edit may have moved to a dropped one).
+			if res, ok := current[name]; ok && res.pkg != nil && res.err == nil && res.pkg.hasVersions(installed[name]) {
 				next[name] = res
 				continue
 			}
@@ -183,38 +226,87 @@ func planPackageReload(snapshot *workspaceSnapshot, current map[string]nugetResu
 	return next, toFetch
 }
 
-func fetchPackageMetadataAsync(send func(tea.Msg), generation int, nugetServices []*NugetService, sourceMapping *PackageSourceMapping, packageNames []string) {
+// loadTimeoutError marks a package whose metadata did not arrive before the
+// load deadline. It is retryable: the request may simply have been slow.
+type loadTimeoutError struct {
+	after time.Duration
+}
+
+func (e *loadTimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.after)
+}
+
+func isLoadTimeout(err error) bool {
+	var timeout *loadTimeoutError
+	return errors.As(err, &timeout)
+}
+
+// fetchPackageMetadataAsync loads every package in the background and sends
+// one packageReadyMsg per name. With a non-zero deadline, names still loading
+// when it expires are reported as timed out and their late results dropped,
+// so a hung feed can't hold the loading screen open. Each package is looked
+// up in the sources of the projects that reference it, at most
+// fetchConcurrency at a time. fresh skips the on-disk HTTP cache's TTL.
+func fetchPackageMetadataAsync(send func(tea.Msg), generation int, scopes sourceScopes, packageNames []string, deadline time.Duration, fresh bool) {
 	if send == nil || len(packageNames) == 0 {
 		return
 	}
 
-	go func() {
-		var nugetOrgSvc *NugetService
-		for _, svc := range nugetServices {
-			if strings.EqualFold(svc.SourceName(), "nuget.org") {
-				nugetOrgSvc = svc
-				break
-			}
+	var mu sync.Mutex
+	delivered := NewSet[string]()
+	deliver := func(name string, result nugetResult) {
+		mu.Lock()
+		if delivered.Contains(name) {
+			mu.Unlock()
+			return
 		}
-		if nugetOrgSvc == nil {
-			svc, err := NewNugetService(NugetSource{Name: "nuget.org", URL: defaultNugetSource})
-			if err == nil {
-				nugetOrgSvc = svc
-			}
+		delivered.Add(name)
+		mu.Unlock()
+		send(packageReadyMsg{generation: generation, name: name, result: result})
+	}
+
+	go func() {
+		var expired chan struct{} // closed at the deadline; nil without one
+		if deadline > 0 {
+			expired = make(chan struct{})
+			timer := time.AfterFunc(deadline, func() {
+				close(expired)
+				for _, name := range packageNames {
+					deliver(name, nugetResult{
+						source: serviceNames(scopes.servicesFor(name)),
+						err:    &loadTimeoutError{after: deadline},
+					})
+				}
+			})
+			defer timer.Stop()
 		}
 
 		var wg sync.WaitGroup
+		sem := make(chan struct{}, fetchConcurrency)
+	queue:
 		for _, name := range packageNames {
+			// Names still queued at the deadline have been reported as
+			// timed out; don't start their lookups.
+			select {
+			case sem <- struct{}{}:
+			case <-expired:
+				break queue
+			}
 			wg.Add(1)
 			go func(name string) {
-				defer wg.Done()
+				defer func() { <-sem; wg.Done() }()
+
+				lookup := (*NugetService).SearchExact
+				if fresh {
+					lookup = (*NugetService).RefreshExact
+				}
 
 				var info *PackageInfo
 				var sourceName string
 				var lastErr error
-				eligibleServices := FilterServices(nugetServices, sourceMapping, name)
+				eligibleServices := scopes.servicesFor(name)
 				for _, svc := range eligibleServices {
-					info, lastErr = svc.SearchExact(name)
+					info, lastErr = lookup(svc, name)
 					if lastErr == nil {
 						sourceName = svc.SourceName()
 						break
@@ -222,20 +314,124 @@ func fetchPackageMetadataAsync(send func(tea.Msg), generation int, nugetServices
 					logDebug("Source [%s] failed for %s: %v", svc.SourceName(), name, lastErr)
 				}
 
-				if info != nil && !strings.EqualFold(sourceName, "nuget.org") && nugetOrgSvc != nil {
-					if nugetInfo, err := nugetOrgSvc.SearchExact(name); err == nil {
-						info.NugetOrgURL = "https://www.nuget.org/packages/" + nugetInfo.ID
-						enrichFromNugetOrg(info, nugetInfo)
-					}
+				if lastErr != nil && len(eligibleServices) > 0 {
+					// Remember which source gave the final answer so failures
+					// can be grouped by source.
+					sourceName = eligibleServices[len(eligibleServices)-1].SourceName()
 				}
-
-				send(packageReadyMsg{
-					generation: generation,
-					name:       name,
-					result:     nugetResult{pkg: info, source: sourceName, err: lastErr},
-				})
+				deliver(name, nugetResult{pkg: info, source: sourceName, err: lastErr})
 			}(name)
 		}
 		wg.Wait()
 	}()
 }
+
+// fetchConcurrency caps the packages looked up at once by the primary load,
+// so solutions with hundreds of packages don't trip a feed's rate limit.
+// Set from --concurrency or the fetchConcurrency setting via
+// setFetchConcurrency.
+var fetchConcurrency = 16
+
+func setFetchConcurrency(n int) {
+	if n > 0 {
+		fetchConcurrency = n
+	}
+}
+
+// enrichConcurrency caps the nuget.org lookups of the enrichment pass, so it
+// trickles in behind the primary load instead of doubling its requests.
+const enrichConcurrency = 4
+
+// enrichmentService returns the configured nuget.org source, or a default one
+// when the workspace doesn't list it. Returns nil if neither is usable or
+// nuget.org is disabled.
+func enrichmentService(scopes sourceScopes) *NugetService {
+	if nugetOrgDisabled {
+		return nil
+	}
+	for _, svc := range scopes.services() {
+		if strings.EqualFold(svc.SourceName(), "nuget.org") {
+			return svc
+		}
+	}
+	svc, err := NewNugetService(NugetSource{Name: "nuget.org", URL: defaultNugetSource})
+	if err != nil {
+		return nil
+	}
+	return svc
+}
+
+// enrichmentCandidates lists the loaded packages that came from a feed other
+// than nuget.org and have not been enriched from it yet, sorted.
+func enrichmentCandidates(results map[string]nugetResult) []string {
+	var names []string
+	for name, res := range results {
+		if res.pkg != nil && !res.enriched && !strings.EqualFold(res.source, "nuget.org") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fetchEnrichment looks each of names up on nuget.org, at most
+// enrichConcurrency at a time, and calls found for every package it has.
+// found is never called concurrently. Returns, once every lookup finished,
+// the names nuget.org had no data for, sorted.
+func fetchEnrichment(scopes sourceScopes, names []string, fresh bool, found func(name string, nugetInfo *PackageInfo)) []string {
+	svc := enrichmentService(scopes)
+	if svc == nil || len(names) == 0 {
+		return names
+	}
+	lookup := (*NugetService).SearchExact
+	if fresh {
+		lookup = (*NugetService).RefreshExact
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var missing []string
+	sem := make(chan struct{}, enrichConcurrency)
+	for _, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer func() { <-sem; wg.Done() }()
+			nugetInfo, err := lookup(svc, name)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logDebug("nuget.org enrichment for %s: %v", name, err)
+				missing = append(missing, name)
+				return
+			}
+			found(name, nugetInfo)
+		}(name)
+	}
+	wg.Wait()
+	sort.Strings(missing)
+	return missing
+}
+
+// fetchEnrichmentAsync runs fetchEnrichment in the background (when
+// enrich), sending a packageEnrichedMsg per package found on nuget.org. The
+// rest are cross-checked with the GitHub Advisory Database, sending a
+// packageAdvisoriesMsg per package with advisories, and an enrichDoneMsg
+// ends the pass.
+func fetchEnrichmentAsync(send func(tea.Msg), generation int, scopes sourceScopes, names []string, fresh, enrich bool) {
+	if send == nil || len(names) == 0 {
+		return
+	}
+	go func() {
+		missing := names
+		if enrich {
+			missing = fetchEnrichment(scopes, names, fresh, func(name string, nugetInfo *PackageInfo) {
+				send(packageEnrichedMsg{generation: generation, name: name, nugetInfo: nugetInfo})
+			})
+		}
+		fetchGitHubAdvisories(missing, func(name string, vulns []githubVulnerability) {
+			send(packageAdvisoriesMsg{generation: generation, name: name, vulns: vulns})
+		})
+		send(enrichDoneMsg{generation: generation, count: len(names)})
+	}()
+}
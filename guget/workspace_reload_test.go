@@ -1,14 +1,70 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 )
 
+func TestLoadWorkspace_KeepsMalformedProject(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"resources":[{"@id":%q,"@type":"RegistrationsBaseUrl/3.6.0"}]}`, srv.URL+"/reg/")
+	}))
+	defer srv.Close()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "nuget.config"), `<configuration>
+  <packageSources>
+    <clear />
+    <add key="local" value="`+srv.URL+`/index.json" />
+  </packageSources>
+</configuration>`)
+	mustWriteFile(t, filepath.Join(root, "Good", "Good.csproj"), `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+</Project>`)
+	mustWriteFile(t, filepath.Join(root, "Broken", "Broken.csproj"), `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Polly" Version="8.5.2">
+  </ItemGroup>
+</Project>`)
+
+	snapshot, err := loadWorkspace(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]*ParsedProject)
+	for _, p := range snapshot.ParsedProjects {
+		byName[p.FileName] = p
+	}
+	if len(byName) != 2 {
+		t.Fatalf("expected both projects to load, got %v", byName)
+	}
+	if good := byName["Good.csproj"]; good.LoadErr != nil || good.Packages.Len() != 1 {
+		t.Errorf("healthy project: LoadErr = %v, %d package(s)", good.LoadErr, good.Packages.Len())
+	}
+	if broken := byName["Broken.csproj"]; broken == nil || broken.LoadErr == nil {
+		t.Errorf("expected the malformed project to be kept with its parse error, got %+v", broken)
+	}
+}
+
 func TestPlanPackageReload_ReusesCachedResults(t *testing.T) {
 	snapshot := &workspaceSnapshot{
 		ParsedProjects: []*ParsedProject{
@@ -178,6 +234,100 @@ func TestHandleWorkspaceReloaded_IgnoresStaleGeneration(t *testing.T) {
 	}
 }
 
+func TestUpdatePackageRows_TriageViewPicksUpLoadedPackages(t *testing.T) {
+	vuln := []PackageVulnerability{{AdvisoryURL: "https://github.com/advisories/GHSA-test", Severity: 2}}
+	app := &App{
+		ctx: &AppContext{
+			ParsedProjects:  []*ParsedProject{testProjectWithPackages("ProjectA.csproj", "Polly", "Serilog")},
+			Results:         map[string]nugetResult{},
+			PendingPackages: NewSet[string](),
+		},
+	}
+	app.ctx.PendingPackages.Add("Polly")
+	app.ctx.PendingPackages.Add("Serilog")
+	app.packages.view = viewVulnerable
+	app.rebuildPackageRows()
+	if len(app.packages.rows) != 0 {
+		t.Fatalf("expected no rows while loading, got %d", len(app.packages.rows))
+	}
+
+	app.ctx.PendingPackages.Remove("Polly")
+	app.ctx.Results["Polly"] = nugetResult{pkg: &PackageInfo{ID: "Polly", Versions: []PackageVersion{
+		{SemVer: ParseSemVer("1.0.0"), Vulnerabilities: vuln},
+	}}}
+	app.updatePackageRows("Polly", false)
+	if len(app.packages.rows) != 1 || app.packages.rows[0].ref.Name != "Polly" {
+		t.Fatalf("expected the vulnerable package to join the view, got %+v", app.packages.rows)
+	}
+
+	app.ctx.Results["Polly"] = nugetResult{pkg: &PackageInfo{ID: "Polly", Versions: []PackageVersion{{SemVer: ParseSemVer("1.0.0")}}}}
+	app.updatePackageRows("Polly", false)
+	if len(app.packages.rows) != 0 {
+		t.Fatalf("expected the package to leave the view once no longer vulnerable, got %d rows", len(app.packages.rows))
+	}
+}
+
+func TestUpdatePackageRows_UpdatesInPlaceUntilResort(t *testing.T) {
+	app := &App{
+		ctx: &AppContext{
+			ParsedProjects:  []*ParsedProject{testProjectWithPackages("ProjectA.csproj", "Newtonsoft.Json", "Polly", "Serilog")},
+			Results:         map[string]nugetResult{},
+			PendingPackages: NewSet[string](),
+		},
+	}
+	app.packages.sortDir = true
+	for _, name := range []string{"Newtonsoft.Json", "Polly", "Serilog"} {
+		app.ctx.PendingPackages.Add(name)
+	}
+	app.rebuildPackageRows()
+	names := func() []string {
+		var out []string
+		for _, row := range app.packages.rows {
+			out = append(out, row.ref.Name)
+		}
+		return out
+	}
+	before := names()
+	app.packages.cursor = slices.Index(before, "Polly")
+
+	// Serilog turns out to be outdated, which the status sort would move;
+	// while other packages are still loading only its row changes.
+	app.ctx.PendingPackages.Remove("Serilog")
+	app.ctx.Results["Serilog"] = nugetResult{pkg: &PackageInfo{ID: "Serilog", Versions: []PackageVersion{
+		{SemVer: ParseSemVer("2.0.0")}, {SemVer: ParseSemVer("1.0.0")},
+	}}}
+	app.updatePackageRows("Serilog", false)
+	if got := names(); !slices.Equal(got, before) {
+		t.Fatalf("expected rows to keep their order before the resort, got %v, want %v", got, before)
+	}
+	for _, row := range app.packages.rows {
+		switch {
+		case row.ref.Name == "Serilog" && (row.loading || row.latestStable == nil || row.latestStable.SemVer.String() != "2.0.0"):
+			t.Fatalf("expected the Serilog row updated in place, got %+v", row)
+		case row.ref.Name != "Serilog" && !row.loading:
+			t.Fatalf("expected %s to still be loading", row.ref.Name)
+		}
+	}
+
+	for _, name := range []string{"Newtonsoft.Json", "Polly"} {
+		app.ctx.PendingPackages.Remove(name)
+		app.ctx.Results[name] = nugetResult{pkg: &PackageInfo{ID: name, Versions: []PackageVersion{{SemVer: ParseSemVer("1.0.0")}}}}
+	}
+	app.updatePackageRows("Newtonsoft.Json", false)
+	app.updatePackageRows("Polly", true)
+	sorted := names()
+	if slices.Equal(sorted, before) {
+		t.Fatalf("expected the resort to move the outdated package, got %v", sorted)
+	}
+	app.rebuildPackageRows()
+	if rebuilt := names(); !slices.Equal(sorted, rebuilt) {
+		t.Fatalf("expected the resort to match a full rebuild, got %v, want %v", sorted, rebuilt)
+	}
+	if app.packages.rows[app.packages.cursor].ref.Name != "Polly" {
+		t.Fatalf("expected the cursor to follow Polly, got %s", app.packages.rows[app.packages.cursor].ref.Name)
+	}
+}
+
 func testProjectWithPackages(path string, packages ...string) *ParsedProject {
 	project := &ParsedProject{
 		FileName:         filepath.Base(path),
@@ -202,3 +352,198 @@ func mustWriteFile(t *testing.T, path, content string) {
 		t.Fatalf("WriteFile(%s): %v", path, err)
 	}
 }
+
+func TestFetchPackageMetadataAsync_DeadlineReportsTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	svc := &NugetService{
+		sourceURL:  srv.URL + "/index.json",
+		sourceName: "nuget.org",
+		client:     srv.Client(),
+		searchBase: srv.URL + "/search",
+		regBase:    srv.URL + "/registration/",
+	}
+
+	msgs := make(chan tea.Msg, 8)
+	fetchPackageMetadataAsync(func(msg tea.Msg) { msgs <- msg }, 3, sourceScopes{{Services: []*NugetService{svc}}}, []string{"A", "B"}, 50*time.Millisecond, false)
+
+	got := map[string]bool{}
+	for len(got) < 2 {
+		select {
+		case msg := <-msgs:
+			ready := msg.(packageReadyMsg)
+			if ready.generation != 3 {
+				t.Fatalf("expected generation 3, got %d", ready.generation)
+			}
+			if !isLoadTimeout(ready.result.err) {
+				t.Fatalf("expected a load timeout for %s, got %v", ready.name, ready.result.err)
+			}
+			got[ready.name] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for deadline results, got %v", got)
+		}
+	}
+
+	// Late answers from the feed must not produce a second result.
+	release <- struct{}{}
+	select {
+	case msg := <-msgs:
+		t.Fatalf("expected late result to be dropped, got %+v", msg)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestEnrichmentCandidates(t *testing.T) {
+	results := map[string]nugetResult{
+		"Private.Lib":     {pkg: &PackageInfo{}, source: "internal"},
+		"Newtonsoft.Json": {pkg: &PackageInfo{}, source: "nuget.org"},
+		"Already.Checked": {pkg: &PackageInfo{}, source: "internal", enriched: true},
+		"Failed":          {source: "internal", err: errors.New("401")},
+		"Another.Lib":     {pkg: &PackageInfo{}, source: "internal"},
+	}
+	got := fmt.Sprint(enrichmentCandidates(results))
+	if got != "[Another.Lib Private.Lib]" {
+		t.Errorf("candidates = %s", got)
+	}
+}
+
+func TestFetchEnrichment_CapsConcurrentLookups(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak, total := 0, 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		total++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	svc := &NugetService{
+		sourceURL:  srv.URL + "/index.json",
+		sourceName: "nuget.org",
+		client:     srv.Client(),
+		searchBase: srv.URL + "/search",
+		regBase:    srv.URL + "/registration/",
+	}
+	names := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J"}
+	fetchEnrichment(sourceScopes{{Services: []*NugetService{svc}}}, names, false, func(name string, _ *PackageInfo) {
+		t.Errorf("unexpected enrichment for %s", name)
+	})
+
+	if total < len(names) {
+		t.Errorf("expected a lookup per package, got %d requests", total)
+	}
+	if peak > enrichConcurrency {
+		t.Errorf("peak concurrent lookups = %d, want at most %d", peak, enrichConcurrency)
+	}
+}
+
+func TestFetchPackageMetadata_CapsConcurrentLookups(t *testing.T) {
+	defer func(n int) { fetchConcurrency = n }(fetchConcurrency)
+	setFetchConcurrency(3)
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	svc := &NugetService{
+		sourceURL:  srv.URL + "/index.json",
+		sourceName: "contoso",
+		client:     srv.Client(),
+		searchBase: srv.URL + "/search",
+		regBase:    srv.URL + "/registration/",
+	}
+	names := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J"}
+	msgs := make(chan tea.Msg, len(names))
+	fetchPackageMetadataAsync(func(msg tea.Msg) { msgs <- msg }, 0, sourceScopes{{Services: []*NugetService{svc}}}, names, 0, false)
+	for range names {
+		select {
+		case <-msgs:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for results")
+		}
+	}
+	if peak > 3 {
+		t.Errorf("peak concurrent lookups = %d, want at most 3", peak)
+	}
+}
+
+func TestFetchPackageMetadata_RetriesRateLimited(t *testing.T) {
+	var mu sync.Mutex
+	limited := 2
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if limited > 0 {
+			limited--
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	svc := &NugetService{
+		sourceURL:  srv.URL + "/index.json",
+		sourceName: "contoso",
+		client:     srv.Client(),
+		searchBase: srv.URL + "/search",
+		regBase:    srv.URL + "/registration/",
+	}
+	msgs := make(chan tea.Msg, 1)
+	fetchPackageMetadataAsync(func(msg tea.Msg) { msgs <- msg }, 0, sourceScopes{{Services: []*NugetService{svc}}}, []string{"A"}, 0, false)
+	select {
+	case msg := <-msgs:
+		var status *httpStatusError
+		if err := msg.(packageReadyMsg).result.err; errors.As(err, &status) && status.Code == http.StatusTooManyRequests {
+			t.Errorf("expected the 429s to be retried, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the result")
+	}
+	if limited != 0 {
+		t.Errorf("%d rate-limited responses left unused", limited)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if got := retryDelay(1, "7", now); got != 7*time.Second {
+		t.Errorf("Retry-After seconds: got %s, want 7s", got)
+	}
+	if got := retryDelay(1, now.Add(3*time.Second).Format(http.TimeFormat), now); got != 3*time.Second {
+		t.Errorf("Retry-After date: got %s, want 3s", got)
+	}
+	if got := retryDelay(1, "3600", now); got != maxRetryDelay {
+		t.Errorf("long Retry-After: got %s, want the %s cap", got, maxRetryDelay)
+	}
+	for attempt, base := range map[int]time.Duration{1: retryBackoff, 3: 4 * retryBackoff} {
+		if got := retryDelay(attempt, "soon", now); got < base || got > base*3/2 {
+			t.Errorf("attempt %d backoff = %s, want %s plus up to 50%%", attempt, got, base)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Project and props files are edited in place rather than re-serialised, so
+// a change touches only the bytes it must: attribute order, quoting,
+// whitespace, comments and self-closing style stay as the author wrote
+// them. scanXML locates elements by byte offset for those edits.
+
+// xmlAttr is an attribute of a scanned start tag.
+type xmlAttr struct {
+	Name       string
+	Value      string // raw text between the quotes, entities not expanded
+	ValueStart int
+	ValueEnd   int
+	Quote      byte
+}
+
+// xmlElement is an element found by scanXML, with offsets into the text.
+type xmlElement struct {
+	Name        string // local name, without any prefix
+	Start       int    // the '<' of the start tag
+	TagEnd      int    // just past the start tag's '>'
+	CloseStart  int    // the '<' of the end tag; TagEnd when self-closing
+	End         int    // just past the end tag; TagEnd when self-closing
+	SelfClosing bool
+	Attrs       []xmlAttr
+	Parent      int // index of the enclosing element, -1 for the root
+}
+
+// attr returns the attribute called name, ignoring case, or nil.
+func (e *xmlElement) attr(name string) *xmlAttr {
+	for i := range e.Attrs {
+		if strings.EqualFold(e.Attrs[i].Name, name) {
+			return &e.Attrs[i]
+		}
+	}
+	return nil
+}
+
+// scanXML lists the elements of text in document order. Comments, CDATA,
+// processing instructions and DOCTYPEs are skipped, so commented-out
+// elements are never edited.
+func scanXML(text string) ([]xmlElement, error) {
+	var elems []xmlElement
+	var open []int // indexes of unclosed elements
+	skipTo := func(i int, end string) (int, error) {
+		j := strings.Index(text[i:], end)
+		if j < 0 {
+			return 0, fmt.Errorf("unterminated %q at offset %d", text[i:min(len(text), i+9)], i)
+		}
+		return i + j + len(end), nil
+	}
+	for i := 0; i < len(text); {
+		lt := strings.IndexByte(text[i:], '<')
+		if lt < 0 {
+			break
+		}
+		i += lt
+		var err error
+		switch {
+		case strings.HasPrefix(text[i:], "<!--"):
+			i, err = skipTo(i+4, "-->")
+		case strings.HasPrefix(text[i:], "<![CDATA["):
+			i, err = skipTo(i+9, "]]>")
+		case strings.HasPrefix(text[i:], "<?"):
+			i, err = skipTo(i+2, "?>")
+		case strings.HasPrefix(text[i:], "<!"):
+			i, err = skipTo(i+2, ">")
+		case strings.HasPrefix(text[i:], "</"):
+			start := i
+			if i, err = skipTo(i+2, ">"); err != nil {
+				break
+			}
+			name := localName(strings.TrimSpace(text[start+2 : i-1]))
+			k := len(open) - 1
+			for k >= 0 && !strings.EqualFold(elems[open[k]].Name, name) {
+				k--
+			}
+			if k < 0 {
+				return nil, fmt.Errorf("unexpected </%s> at offset %d", name, start)
+			}
+			e := &elems[open[k]]
+			e.CloseStart, e.End = start, i
+			open = open[:k]
+		default:
+			var e xmlElement
+			if e, err = scanStartTag(text, i); err != nil {
+				break
+			}
+			e.Parent = -1
+			if len(open) > 0 {
+				e.Parent = open[len(open)-1]
+			}
+			elems = append(elems, e)
+			if !e.SelfClosing {
+				open = append(open, len(elems)-1)
+			}
+			i = e.TagEnd
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(open) > 0 {
+		return nil, fmt.Errorf("<%s> is never closed", elems[open[len(open)-1]].Name)
+	}
+	return elems, nil
+}
+
+// scanStartTag reads the start tag whose '<' is at text[start].
+func scanStartTag(text string, start int) (xmlElement, error) {
+	e := xmlElement{Start: start}
+	i := start + 1
+	for i < len(text) && !isXMLSpace(text[i]) && text[i] != '>' && text[i] != '/' {
+		i++
+	}
+	e.Name = localName(text[start+1 : i])
+	for i < len(text) {
+		for i < len(text) && isXMLSpace(text[i]) {
+			i++
+		}
+		switch {
+		case i >= len(text):
+		case text[i] == '>':
+			e.TagEnd, e.CloseStart, e.End = i+1, i+1, i+1
+			return e, nil
+		case strings.HasPrefix(text[i:], "/>"):
+			e.TagEnd, e.CloseStart, e.End = i+2, i+2, i+2
+			e.SelfClosing = true
+			return e, nil
+		default:
+			nameStart := i
+			for i < len(text) && !isXMLSpace(text[i]) && text[i] != '=' && text[i] != '>' && text[i] != '/' {
+				i++
+			}
+			a := xmlAttr{Name: text[nameStart:i]}
+			for i < len(text) && isXMLSpace(text[i]) {
+				i++
+			}
+			if i >= len(text) || text[i] != '=' {
+				return e, fmt.Errorf("attribute %s of <%s> has no value", a.Name, e.Name)
+			}
+			i++
+			for i < len(text) && isXMLSpace(text[i]) {
+				i++
+			}
+			if i >= len(text) || (text[i] != '"' && text[i] != '\'') {
+				return e, fmt.Errorf("attribute %s of <%s> is not quoted", a.Name, e.Name)
+			}
+			a.Quote = text[i]
+			a.ValueStart = i + 1
+			end := strings.IndexByte(text[a.ValueStart:], a.Quote)
+			if end < 0 {
+				return e, fmt.Errorf("attribute %s of <%s> is not closed", a.Name, e.Name)
+			}
+			a.ValueEnd = a.ValueStart + end
+			a.Value = text[a.ValueStart:a.ValueEnd]
+			e.Attrs = append(e.Attrs, a)
+			i = a.ValueEnd + 1
+		}
+	}
+	return e, fmt.Errorf("unterminated <%s> at offset %d", e.Name, start)
+}
+
+func localName(name string) string {
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func isXMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// textEdit replaces text[Start:End] with Text.
+type textEdit struct {
+	Start, End int
+	Text       string
+}
+
+// applyEdits applies non-overlapping edits to text.
+func applyEdits(text string, edits []textEdit) string {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Start > edits[j].Start })
+	for _, e := range edits {
+		text = text[:e.Start] + e.Text + text[e.End:]
+	}
+	return text
+}
+
+// lineStart returns the offset of the start of the line holding off.
+func lineStart(text string, off int) int {
+	return strings.LastIndexByte(text[:off], '\n') + 1
+}
+
+// lineEnd returns the offset just past the newline ending the line holding
+// off, or len(text) on the last line.
+func lineEnd(text string, off int) int {
+	if i := strings.IndexByte(text[off:], '\n'); i >= 0 {
+		return off + i + 1
+	}
+	return len(text)
+}
+
+// leadingIndent returns the whitespace before off on its line, and whether
+// there is nothing else before it.
+func leadingIndent(text string, off int) (string, bool) {
+	prefix := text[lineStart(text, off):off]
+	return prefix, strings.TrimLeft(prefix, " \t") == ""
+}
+
+// aloneOnLines reports whether nothing but whitespace shares the lines of
+// text[start:end].
+func aloneOnLines(text string, start, end int) bool {
+	_, before := leadingIndent(text, start)
+	return before && strings.TrimSpace(text[end:lineEnd(text, end)]) == ""
+}
+
+// packageElementTags are the items that name a package and its version.
+var packageElementTags = []string{"PackageReference", "PackageVersion", "GlobalPackageReference"}
+
+// packageElements returns the indexes of the package items for pkgName,
+// matched by Include or Update as the parser reads them.
+func packageElements(elems []xmlElement, pkgName string) []int {
+	var out []int
+	for i := range elems {
+		e := &elems[i]
+		isPackage := false
+		for _, tag := range packageElementTags {
+			if strings.EqualFold(e.Name, tag) {
+				isPackage = true
+				break
+			}
+		}
+		if !isPackage {
+			continue
+		}
+		name := e.attr("Include")
+		if name == nil {
+			name = e.attr("Update")
+		}
+		if name != nil && strings.EqualFold(strings.TrimSpace(name.Value), pkgName) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// childElement returns the index of the first child of elems[parent]
+// called name, or -1.
+func childElement(elems []xmlElement, parent int, name string) int {
+	for i := parent + 1; i < len(elems) && elems[i].Start < elems[parent].End; i++ {
+		if elems[i].Parent == parent && strings.EqualFold(elems[i].Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// elementStyle is how a file writes its package items, copied when a new
+// one is added so it looks like its neighbours.
+type elementStyle struct {
+	quote        byte   // attribute quote character
+	versionFirst bool   // Version attribute before Include
+	closeSpace   string // whitespace before "/>"
+	versionChild bool   // version in a <Version> child element
+}
+
+// packageElementStyle returns the style of the first tag item in elems, or
+// the usual `<Tag Include="x" Version="y" />` when there is none.
+func packageElementStyle(text string, elems []xmlElement, tag string) elementStyle {
+	style := elementStyle{quote: '"', closeSpace: " "}
+	for i := range elems {
+		e := &elems[i]
+		if !strings.EqualFold(e.Name, tag) {
+			continue
+		}
+		include := e.attr("Include")
+		if include == nil {
+			continue
+		}
+		style.quote = include.Quote
+		if v := e.attr("Version"); v != nil {
+			style.versionFirst = v.ValueStart < include.ValueStart
+		} else if !e.SelfClosing && childElement(elems, i, "Version") >= 0 {
+			style.versionChild = true
+		}
+		if e.SelfClosing {
+			last := e.Attrs[len(e.Attrs)-1].ValueEnd + 1
+			if ws := text[last : e.TagEnd-2]; !strings.ContainsAny(ws, "\r\n") {
+				style.closeSpace = ws
+			}
+		}
+		break
+	}
+	return style
+}
+
+// render writes a new tag item for pkgName in this style. indent and unit
+// are used only when the version goes in a child element; nl ends its lines.
+func (s elementStyle) render(tag, pkgName, version, indent, unit, nl string) string {
+	q := string(s.quote)
+	include := `Include=` + q + pkgName + q
+	if version == "" {
+		return "<" + tag + " " + include + s.closeSpace + "/>"
+	}
+	if s.versionChild {
+		return "<" + tag + " " + include + ">" + nl +
+			indent + unit + "<Version>" + version + "</Version>" + nl +
+			indent + "</" + tag + ">"
+	}
+	attrs := include + ` Version=` + q + version + q
+	if s.versionFirst {
+		attrs = `Version=` + q + version + q + " " + include
+	}
+	return "<" + tag + " " + attrs + s.closeSpace + "/>"
+}